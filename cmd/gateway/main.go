@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -11,23 +12,48 @@ import (
 	"syscall"
 	"time"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/lalithlochan/nimbus/internal/ai"
+	"github.com/lalithlochan/nimbus/internal/analytics"
 	"github.com/lalithlochan/nimbus/internal/api"
+	"github.com/lalithlochan/nimbus/internal/archive"
+	"github.com/lalithlochan/nimbus/internal/backpressure"
+	"github.com/lalithlochan/nimbus/internal/budget"
 	"github.com/lalithlochan/nimbus/internal/circuitbreaker"
 	"github.com/lalithlochan/nimbus/internal/config"
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/db/mysql"
+	"github.com/lalithlochan/nimbus/internal/db/sqlite"
+	"github.com/lalithlochan/nimbus/internal/dlqexport"
+	"github.com/lalithlochan/nimbus/internal/emailcheck"
+	"github.com/lalithlochan/nimbus/internal/events"
+	"github.com/lalithlochan/nimbus/internal/frequency"
 	internalgrpc "github.com/lalithlochan/nimbus/internal/grpc"
+	"github.com/lalithlochan/nimbus/internal/imports"
+	"github.com/lalithlochan/nimbus/internal/jobs"
+	"github.com/lalithlochan/nimbus/internal/maintenance"
 	"github.com/lalithlochan/nimbus/internal/metrics"
+	"github.com/lalithlochan/nimbus/internal/mjml"
 	"github.com/lalithlochan/nimbus/internal/observ"
+	"github.com/lalithlochan/nimbus/internal/preferences"
 	"github.com/lalithlochan/nimbus/internal/rag"
 	"github.com/lalithlochan/nimbus/internal/redis"
+	"github.com/lalithlochan/nimbus/internal/reputation"
+	"github.com/lalithlochan/nimbus/internal/secretbox"
+	"github.com/lalithlochan/nimbus/internal/sesidentity"
+	"github.com/lalithlochan/nimbus/internal/shortlink"
+	"github.com/lalithlochan/nimbus/internal/sns"
 	"github.com/lalithlochan/nimbus/internal/sqs"
+	"github.com/lalithlochan/nimbus/internal/tlsserve"
 	"github.com/lalithlochan/nimbus/internal/worker"
+	"github.com/lalithlochan/nimbus/pkg/sender"
 	notificationv1 "github.com/lalithlochan/nimbus/proto/notification/v1"
 )
 
@@ -59,6 +85,35 @@ func run() error {
 		zap.String("deployment_test-2026-01-28", "true"),
 	)
 
+	// Per-tenant series are labelled per this policy before anything is recorded
+	if err := metrics.SetTenantLabels(metrics.TenantLabelConfig{
+		Mode:      cfg.MetricsTenantLabels,
+		Buckets:   cfg.MetricsTenantBuckets,
+		Allowlist: cfg.MetricsTenantAllowlist,
+	}); err != nil {
+		return fmt.Errorf("failed to configure metrics tenant labels: %w", err)
+	}
+
+	// Metrics backends: Prometheus is scraped from /metrics, DogStatsD is pushed
+	var sinks []metrics.Sink
+	if cfg.MetricsBackendEnabled("prometheus") {
+		sinks = append(sinks, metrics.Prometheus())
+	}
+	if cfg.MetricsBackendEnabled("dogstatsd") {
+		statsd, err := metrics.NewDogStatsD(metrics.DogStatsDConfig{
+			Addr:   cfg.DogStatsDAddr,
+			Prefix: cfg.DogStatsDPrefix,
+			Tags:   cfg.DogStatsDTags,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure dogstatsd: %w", err)
+		}
+		defer func() { _ = statsd.Close() }()
+		sinks = append(sinks, statsd)
+		logger.Info("dogstatsd metrics enabled", zap.String("addr", cfg.DogStatsDAddr))
+	}
+	metrics.SetSinks(sinks...)
+
 	// Initialize database connection
 	ctx := context.Background()
 	dbConfig := db.Config{
@@ -68,22 +123,51 @@ func run() error {
 		Password: cfg.DBPassword,
 		Database: cfg.DBName,
 		SSLMode:  cfg.DBSSLMode,
-	}
 
-	database, err := db.New(ctx, dbConfig, logger)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		SlowQueryThreshold: time.Duration(cfg.DBSlowQueryMS) * time.Millisecond,
 	}
-	defer database.Close()
 
-	logger.Info("database connection established",
-		zap.String("host", cfg.DBHost),
-		zap.Int("port", cfg.DBPort),
-		zap.String("database", cfg.DBName),
-	)
+	// database stays nil on MySQL and SQLite; only the Postgres-only features (the AI
+	// knowledge base) need the pool itself.
+	var database *db.DB
+	var repo db.Store
+	switch cfg.DBDriver {
+	case config.DBDriverMySQL:
+		mysqlDB, err := mysql.New(ctx, dbConfig, logger)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer mysqlDB.Close()
+		repo = mysql.NewRepository(mysqlDB, logger)
+	case config.DBDriverSQLite:
+		sqliteDB, err := sqlite.New(ctx, cfg.DBPath, logger)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer sqliteDB.Close()
+		repo = sqlite.NewRepository(sqliteDB, logger)
+	default:
+		database, err = db.New(ctx, dbConfig, logger)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer database.Close()
+		repo = db.NewRepository(database, logger)
+	}
 
-	// Initialize repository
-	repo := db.NewRepository(database, logger)
+	if cfg.DBDriver == config.DBDriverSQLite {
+		logger.Info("database opened",
+			zap.String("driver", cfg.DBDriver),
+			zap.String("path", cfg.DBPath),
+		)
+	} else {
+		logger.Info("database connection established",
+			zap.String("driver", cfg.DBDriver),
+			zap.String("host", cfg.DBHost),
+			zap.Int("port", cfg.DBPort),
+			zap.String("database", cfg.DBName),
+		)
+	}
 
 	// Initialize Redis for idempotency and rate limiting
 	redisConfig := redis.Config{
@@ -93,52 +177,112 @@ func run() error {
 		DB:       cfg.RedisDB,
 	}
 
-	redisClient, err := redis.New(ctx, redisConfig, logger)
-	if err != nil {
-		logger.Warn("redis unavailable, idempotency disabled",
-			zap.Error(err),
-			zap.String("host", cfg.RedisHost),
-		)
+	var redisClient *redis.Client
+	if cfg.DemoMode {
+		logger.Info("demo mode, redis skipped: idempotency and rate limiting disabled")
+	} else {
+		redisClient, err = redis.New(ctx, redisConfig, logger)
+		if err != nil {
+			logger.Warn("redis unavailable, idempotency disabled and rate limits degraded",
+				zap.Error(err),
+				zap.String("host", cfg.RedisHost),
+			)
+		}
+	}
+
+	if redisClient != nil && cfg.ChaosEnabled && cfg.ChaosRedisErrorPercent > 0 {
+		redisClient.InjectFaults(cfg.ChaosRedisErrorPercent)
+		logger.Warn("chaos: failing redis commands", zap.Float64("percent", cfg.ChaosRedisErrorPercent))
+	}
+
+	// The limiters are built even if Redis didn't answer at startup: they
+	// then run degraded, on the in-memory fallback or failing open, as they
+	// would during an outage.
+	var rateLimiter, globalLimiter *redis.RateLimiter
+	routeLimiters := map[string]*redis.RateLimiter{}
+	if !cfg.DemoMode {
+		rateLimiter = redis.NewRateLimiter(redisClient, logger, redis.RateLimitConfig{
+			Name:            "tenant",
+			Limit:           cfg.RateLimitPerTenant,       // requests
+			Window:          1 * time.Minute,              // per minute per tenant
+			Burst:           cfg.RateLimitBurst,           // unless the tenant has an override
+			FallbackPercent: cfg.RateLimitFallbackPercent, // enforced in memory while Redis is down
+		})
+		if cfg.RateLimitGlobalRPS > 0 {
+			globalLimiter = redis.NewRateLimiter(redisClient, logger, redis.RateLimitConfig{
+				Name:            "global",
+				Limit:           cfg.RateLimitGlobalRPS,
+				Window:          1 * time.Second,
+				FallbackPercent: cfg.RateLimitFallbackPercent,
+			})
+		}
+		for path, limit := range cfg.RateLimitRoutes {
+			routeLimiters[path] = redis.NewRateLimiter(redisClient, logger, redis.RateLimitConfig{
+				Name:            "route:" + path,
+				Limit:           limit,
+				Window:          1 * time.Minute,
+				FallbackPercent: cfg.RateLimitFallbackPercent,
+			})
+		}
 	}
 
 	var idempotencyService *redis.IdempotencyService
-	var rateLimiter *redis.RateLimiter
 	if redisClient != nil {
 		idempotencyService = redis.NewIdempotencyService(redisClient, logger)
-		rateLimiter = redis.NewRateLimiter(redisClient, logger, redis.RateLimitConfig{
-			Limit:  100,             // 100 requests
-			Window: 1 * time.Minute, // per minute per tenant
-		})
 		defer redisClient.Close()
+
+		// Tenant settings, rate limit overrides and channel settings are
+		// read on every request and send; serve them from Redis.
+		if cfg.ConfigCacheTTL > 0 {
+			repo = redis.NewConfigCache(repo, redisClient, time.Duration(cfg.ConfigCacheTTL)*time.Second, logger)
+		}
 	}
 
-	// Initialize SQS producer
-	var producer *sqs.Producer
-	if cfg.SQSQueueURL != "" {
+	// Initialize the queue producer: the in-process queue in demo mode, the
+	// SNS fan-out topic when configured, otherwise the single SQS queue.
+	// Assign only on success so a failed constructor doesn't leave a typed
+	// nil in the interface.
+	var producer api.Enqueuer
+	var demoQueue *sqs.MemoryQueue
+	if cfg.DemoMode {
+		demoQueue = sqs.NewMemoryQueue(1000, "email", "sms", "webhook", "slack")
+		producer = demoQueue
+	} else if cfg.SNSFanoutTopicARN != "" {
+		publisher, err := sns.NewPublisher(ctx, cfg.SNSFanoutTopicARN, awsconfig.WithRegion(cfg.SNSRegion))
+		if err != nil {
+			logger.Warn("sns fan-out publisher unavailable, events will not be enqueued",
+				zap.Error(err),
+			)
+		} else {
+			producer = publisher
+			logger.Info("sns fan-out publisher initialized",
+				zap.String("topic_arn", cfg.SNSFanoutTopicARN),
+			)
+		}
+	} else if cfg.SQSQueueURL != "" {
 		sqsCfg := sqs.Config{
 			Region:   cfg.SQSRegion,
 			QueueURL: cfg.SQSQueueURL,
 			DLQURL:   cfg.SQSDLQURL,
 		}
-		producer, err = sqs.NewProducer(ctx, sqsCfg, logger)
+		sqsProducer, err := sqs.NewProducer(ctx, sqsCfg, logger)
 		if err != nil {
 			logger.Warn("sqs producer unavailable, events will not be enqueued",
 				zap.Error(err),
 			)
-		}
-		// Guard the deferred Close: if NewProducer failed, producer is nil and
-		// calling Close() on it would panic during shutdown.
-		if producer != nil {
-			defer producer.Close()
+		} else {
+			producer = sqsProducer
+			defer sqsProducer.Close()
 		}
 	}
 
 	sesCfg := worker.SESConfig{
-		Region:    cfg.AWSRegion,
-		FromEmail: cfg.SESFromEmail,
+		Region:           cfg.AWSRegion,
+		FromEmail:        cfg.SESFromEmail,
+		ConfigurationSet: cfg.SESConfigurationSet,
 	}
 
-	sender, err := worker.NewSESSender(ctx, sesCfg, logger)
+	sesSender, err := worker.NewSESSender(ctx, sesCfg, logger)
 	if err != nil {
 		return fmt.Errorf("failed to create SES email sender: %w", err)
 	}
@@ -159,7 +303,43 @@ func run() error {
 		DefaultTimeout: time.Duration(cfg.WebhookTimeout) * time.Second,
 	})
 
-	// Wrap each sender with a circuit breaker for resilience.
+	// Tenant webhook header sets are sealed at rest with this key; without
+	// it tenants can still set a user_agent, but not headers.
+	var headersBox *secretbox.Box
+	if cfg.WebhookHeadersKey != "" {
+		headersBox, err = secretbox.New(cfg.WebhookHeadersKey)
+		if err != nil {
+			logger.Fatal("invalid webhook headers key", zap.Error(err))
+		}
+	}
+	webhookSender.SetTenantHeaders(repo, headersBox)
+	sesSender.SetTenantIdentities(repo)
+
+	// Tenant email identities are checked against SES when they are saved.
+	emailIdentities, err := sesidentity.New(ctx, cfg.AWSRegion)
+	if err != nil {
+		return fmt.Errorf("failed to create SES identity checker: %w", err)
+	}
+
+	// Fault injection sits under the metrics and breakers, standing in for
+	// a misbehaving provider.
+	chaosSender := func(s worker.Sender) worker.Sender { return s }
+	if cfg.ChaosEnabled {
+		chaosCfg := worker.ChaosConfig{
+			ErrorPercent:   cfg.ChaosSendErrorPercent,
+			Latency:        time.Duration(cfg.ChaosSendLatency) * time.Millisecond,
+			LatencyPercent: cfg.ChaosSendLatencyPercent,
+		}
+		chaosSender = func(s worker.Sender) worker.Sender { return worker.NewChaosSender(s, chaosCfg) }
+		logger.Warn("chaos: injecting send faults",
+			zap.Float64("error_percent", chaosCfg.ErrorPercent),
+			zap.Duration("latency", chaosCfg.Latency),
+			zap.Float64("latency_percent", chaosCfg.LatencyPercent),
+		)
+	}
+
+	// Wrap each sender with a circuit breaker for resilience, and each
+	// provider inside it with latency metrics (nimbus_sender_duration_seconds).
 	// When a downstream service (SES/SNS/webhook) starts failing,
 	// the circuit opens and fails fast instead of hammering a dead service.
 	sesBreaker := circuitbreaker.New(circuitbreaker.Config{
@@ -167,7 +347,7 @@ func run() error {
 		MaxFailures:     5,
 		RecoveryTimeout: 30 * time.Second,
 	}, logger)
-	protectedEmail := circuitbreaker.NewProtectedSender(sender, sesBreaker, logger)
+	var protectedEmail circuitbreaker.Sender = circuitbreaker.NewProtectedSender(worker.NewMetricsSender(chaosSender(sesSender), worker.ProviderSES), sesBreaker, logger)
 
 	var protectedSNS circuitbreaker.Sender
 	var snsBreaker *circuitbreaker.CircuitBreaker
@@ -177,7 +357,7 @@ func run() error {
 			MaxFailures:     5,
 			RecoveryTimeout: 30 * time.Second,
 		}, logger)
-		protectedSNS = circuitbreaker.NewProtectedSender(snsSender, snsBreaker, logger)
+		protectedSNS = circuitbreaker.NewProtectedSender(worker.NewMetricsSender(chaosSender(snsSender), worker.ProviderSNS), snsBreaker, logger)
 	}
 
 	webhookBreaker := circuitbreaker.New(circuitbreaker.Config{
@@ -185,22 +365,229 @@ func run() error {
 		MaxFailures:     5,
 		RecoveryTimeout: 30 * time.Second,
 	}, logger)
-	protectedWebhook := circuitbreaker.NewProtectedSender(webhookSender, webhookBreaker, logger)
+	protectedWebhook := circuitbreaker.NewProtectedSender(worker.NewMetricsSender(chaosSender(webhookSender), worker.ProviderWebhook), webhookBreaker, logger)
+
+	var protectedSlack circuitbreaker.Sender
+	var slackBreaker *circuitbreaker.CircuitBreaker
+	if cfg.SlackWebhookURL != "" || cfg.SlackBotToken != "" {
+		slackSender := worker.NewSlackSender(logger, worker.SlackConfig{
+			WebhookURL: cfg.SlackWebhookURL,
+			BotToken:   cfg.SlackBotToken,
+		})
+		slackBreaker = circuitbreaker.New(circuitbreaker.Config{
+			Name:            "slack",
+			MaxFailures:     5,
+			RecoveryTimeout: 30 * time.Second,
+		}, logger)
+		protectedSlack = circuitbreaker.NewProtectedSender(worker.NewMetricsSender(chaosSender(slackSender), worker.ProviderSlack), slackBreaker, logger)
+	}
+
+	// Provider canary: a share of one channel's sends goes to its provider in
+	// CANARY_REGION, behind its own breaker, and is rolled back to the
+	// baseline automatically if it fails noticeably more often.
+	if cfg.CanaryChannel != "" {
+		canaryCfg := worker.CanaryConfig{
+			Channel:         cfg.CanaryChannel,
+			Percent:         cfg.CanaryPercent,
+			MaxFailureDelta: cfg.CanaryMaxFailureDelta,
+		}
+		canaryBreaker := circuitbreaker.New(circuitbreaker.Config{
+			Name:            cfg.CanaryChannel + "-canary",
+			MaxFailures:     5,
+			RecoveryTimeout: 30 * time.Second,
+		}, logger)
+		canaried := false
+		switch {
+		case cfg.CanaryChannel == "email":
+			canary, err := worker.NewSESSender(ctx, worker.SESConfig{Region: cfg.CanaryRegion, FromEmail: cfg.SESFromEmail, ConfigurationSet: cfg.SESConfigurationSet}, logger)
+			if err != nil {
+				logger.Warn("canary sender unavailable, canary disabled", zap.Error(err))
+				break
+			}
+			canary.SetTenantIdentities(repo)
+			protectedCanary := circuitbreaker.NewProtectedSender(worker.NewMetricsSender(canary, worker.ProviderSES+"-canary"), canaryBreaker, logger)
+			protectedEmail = worker.NewCanarySender(protectedEmail, protectedCanary, canaryCfg, logger)
+			canaried = true
+		case cfg.CanaryChannel == "sms" && protectedSNS != nil:
+			canary, err := worker.NewSNSSender(ctx, worker.SNSConfig{Region: cfg.CanaryRegion}, logger)
+			if err != nil {
+				logger.Warn("canary sender unavailable, canary disabled", zap.Error(err))
+				break
+			}
+			protectedCanary := circuitbreaker.NewProtectedSender(worker.NewMetricsSender(canary, worker.ProviderSNS+"-canary"), canaryBreaker, logger)
+			protectedSNS = worker.NewCanarySender(protectedSNS, protectedCanary, canaryCfg, logger)
+			canaried = true
+		}
+		if canaried {
+			logger.Info("provider canary enabled",
+				zap.String("channel", cfg.CanaryChannel),
+				zap.String("region", cfg.CanaryRegion),
+				zap.Float64("percent", cfg.CanaryPercent),
+			)
+		}
+	}
+
+	// Shadow sending: a sample of emails is also sent through SES in
+	// SHADOW_SES_REGION, to the mailbox simulator, so its latency and
+	// acceptance can be compared with the primary's on real traffic.
+	var shadow *worker.ShadowSender
+	if cfg.ShadowSESRegion != "" {
+		shadowSES, err := worker.NewSESSender(ctx, worker.SESConfig{Region: cfg.ShadowSESRegion, FromEmail: cfg.SESFromEmail}, logger)
+		if err != nil {
+			logger.Warn("shadow sender unavailable, shadow sending disabled", zap.Error(err))
+		} else {
+			shadow = worker.NewShadowSender(
+				protectedEmail,
+				worker.NewSESSimulatorSender(worker.NewMetricsSender(shadowSES, worker.ProviderSES+"-shadow")),
+				worker.ShadowConfig{Percent: cfg.ShadowPercent},
+				logger,
+			)
+			protectedEmail = shadow
+			logger.Info("shadow sending enabled",
+				zap.String("region", cfg.ShadowSESRegion),
+				zap.Float64("percent", cfg.ShadowPercent),
+			)
+		}
+	}
+
+	// Sender plugins come first, so a plugin claiming a built-in channel
+	// replaces its sender. Each gets metrics and a breaker like the others.
+	var senders []worker.Sender
+	for name, settings := range cfg.SenderPlugins {
+		plugin, pluginSender, err := sender.Open(name, settings, logger)
+		if err != nil {
+			logger.Fatal("failed to enable sender plugin", zap.Error(err))
+		}
+		pluginBreaker := circuitbreaker.New(circuitbreaker.Config{
+			Name:            name,
+			MaxFailures:     5,
+			RecoveryTimeout: 30 * time.Second,
+		}, logger)
+		senders = append(senders, circuitbreaker.NewProtectedSender(worker.NewMetricsSender(chaosSender(pluginSender), name), pluginBreaker, logger))
+		api.RegisterChannels(plugin.Channels...)
+		logger.Info("sender plugin enabled", zap.String("plugin", name), zap.Strings("channels", plugin.Channels))
+	}
+
+	pluginCreds := insecure.NewCredentials()
+	if cfg.PluginTLS {
+		pluginCreds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	for name, addr := range cfg.PluginEndpoints {
+		pluginSender, err := worker.DialPluginSender(ctx, name, addr, pluginCreds, logger)
+		if err != nil {
+			logger.Fatal("failed to connect sender plugin", zap.Error(err))
+		}
+		defer pluginSender.Close()
+		pluginBreaker := circuitbreaker.New(circuitbreaker.Config{
+			Name:            name,
+			MaxFailures:     5,
+			RecoveryTimeout: 30 * time.Second,
+		}, logger)
+		senders = append(senders, circuitbreaker.NewProtectedSender(worker.NewMetricsSender(chaosSender(pluginSender), name), pluginBreaker, logger))
+		api.RegisterChannels(pluginSender.Channels()...)
+	}
 
 	// Create multi-sender that routes to appropriate channel handler
-	var multiSender worker.Sender
+	senders = append(senders, protectedEmail)
 	if protectedSNS != nil {
-		multiSender = worker.NewMultiSender(logger, protectedEmail, protectedSNS, protectedWebhook)
-	} else {
-		multiSender = worker.NewMultiSender(logger, protectedEmail, protectedWebhook)
+		senders = append(senders, protectedSNS)
 	}
+	if protectedSlack != nil {
+		senders = append(senders, protectedSlack)
+	}
+	var multiSender worker.Sender = worker.NewMultiSender(logger, append(senders, protectedWebhook)...)
 
 	logger.Info("initialized multi-channel notification system",
 		zap.Bool("email_enabled", true),
 		zap.Bool("sms_enabled", snsSender != nil),
 		zap.Bool("webhook_enabled", true),
+		zap.Bool("slack_enabled", protectedSlack != nil),
 	)
 
+	// In demo mode every channel, webhooks included, is only logged, so a
+	// demo never makes outbound requests.
+	if cfg.DemoMode {
+		multiSender = worker.NewMultiSender(logger, worker.NewMetricsSender(worker.NewLogSender(logger), worker.ProviderLog))
+		logger.Warn("demo mode enabled, notifications will be logged instead of sent")
+	}
+
+	// In sandbox mode nothing leaves the building: every channel is routed to
+	// the capture sender, which writes to the captured_deliveries test inbox.
+	if cfg.SandboxMode {
+		multiSender = worker.NewMultiSender(logger, worker.NewMetricsSender(worker.NewCaptureSender(repo, logger), worker.ProviderCapture))
+		logger.Warn("sandbox mode enabled, deliveries will be captured instead of sent")
+	}
+
+	// Email payloads may reference a published template by template_id; the
+	// compiled HTML is expanded here so the providers (and the sandbox inbox)
+	// see a fully rendered message.
+	multiSender = worker.NewTemplateSender(multiSender, repo, logger)
+
+	// Webhook bodies marked "templated" have their {{variables}} filled in
+	// from template_data, metadata and the notification itself.
+	multiSender = worker.NewWebhookTemplateSender(multiSender, logger)
+
+	// SMS picks up the tenant's sender ID / origination number / message type
+	// from its channel settings unless the payload sets them.
+	multiSender = worker.NewSMSSettingsSender(multiSender, repo, logger)
+
+	// Sends to addresses that hard-bounced or that a provider rejected are
+	// skipped; the tenant sees them under /invalid-recipients.
+	multiSender = worker.NewRecipientHygieneSender(multiSender, repo, logger)
+
+	// Payloads with "recipient_ref": "user" are addressed from the user's
+	// recipient record. It wraps the hygiene check so that sees the address.
+	multiSender = worker.NewRecipientSender(multiSender, repo, logger)
+
+	// Long URLs in SMS bodies are swapped for short /r/{code} links, served
+	// by the redirect route below.
+	if cfg.ShortLinkBaseURL != "" {
+		shortener := shortlink.New(repo, shortlink.Config{
+			BaseURL:       cfg.ShortLinkBaseURL,
+			TenantDomains: cfg.ShortLinkDomains,
+		}, logger)
+		multiSender = worker.NewLinkShortenerSender(multiSender, shortener, logger)
+		logger.Info("SMS link shortening enabled",
+			zap.String("base_url", cfg.ShortLinkBaseURL),
+			zap.Int("custom_domains", len(cfg.ShortLinkDomains)),
+		)
+	}
+
+	// Lifecycle events (optional — only if EVENTBRIDGE_BUS_NAME is set).
+	// Assign the interface only on success so a nil *EventBridge never
+	// ends up behind a non-nil Emitter.
+	var lifecycle events.Emitter
+	var eventBridge *events.EventBridge
+	if cfg.EventBridgeBusName != "" {
+		eventBridge, err = events.NewEventBridge(ctx, events.Config{
+			Region:  cfg.EventBridgeRegion,
+			BusName: cfg.EventBridgeBusName,
+		}, logger)
+		if err != nil {
+			logger.Warn("eventbridge unavailable, lifecycle events disabled", zap.Error(err))
+			eventBridge = nil
+		} else {
+			lifecycle = eventBridge
+			logger.Info("lifecycle events enabled",
+				zap.String("bus", cfg.EventBridgeBusName),
+			)
+		}
+	}
+
+	// Analytics export (optional — only if CLICKHOUSE_URL is set). It gets
+	// the same lifecycle events as EventBridge, plus provider delivery events.
+	var warehouse *analytics.ClickHouse
+	if cfg.ClickHouseURL != "" {
+		warehouse = analytics.NewClickHouse(analytics.Config{
+			URL:      cfg.ClickHouseURL,
+			Database: cfg.ClickHouseDatabase,
+			User:     cfg.ClickHouseUser,
+			Password: cfg.ClickHousePassword,
+		}, logger)
+		lifecycle = events.Multi(lifecycle, warehouse)
+		logger.Info("analytics export enabled")
+	}
+
 	// Initialize AI client (optional — only if OPENAI_API_KEY is set)
 	var aiClient *ai.Client
 	var aiHandler *ai.Handler
@@ -214,6 +601,7 @@ func run() error {
 			logger.Warn("AI features disabled", zap.Error(aiErr))
 		} else {
 			composeService := ai.NewComposeService(aiClient, repo, logger)
+			composeService.SetEvents(lifecycle)
 			aiHandler = ai.NewHandler(composeService, logger)
 
 			// Wrap the multi-sender with AI enrichment so template-based
@@ -226,11 +614,94 @@ func run() error {
 		}
 	}
 
-	w := worker.New(repo, multiSender, worker.Config{
-		PollInterval: 5 * time.Second,
+	// Delivery archival (optional — only if ARCHIVE_S3_BUCKET is set). It
+	// wraps the whole chain so it sees the message exactly as sent.
+	if cfg.ArchiveS3Bucket != "" {
+		archiveStore, err := archive.NewS3(ctx, archive.Config{
+			Region: cfg.ArchiveS3Region,
+			Bucket: cfg.ArchiveS3Bucket,
+			Prefix: cfg.ArchiveS3Prefix,
+		})
+		if err != nil {
+			logger.Warn("delivery archive unavailable, sent messages will not be archived", zap.Error(err))
+		} else {
+			multiSender = worker.NewArchiveSender(multiSender, archiveStore, logger)
+			logger.Info("delivery archival enabled",
+				zap.String("bucket", cfg.ArchiveS3Bucket),
+				zap.String("prefix", cfg.ArchiveS3Prefix),
+			)
+		}
+	}
+
+	// Maintenance mode is shared by the HTTP middleware, the gRPC interceptor,
+	// and the worker, so flipping it pauses every write path at once.
+	maintenanceMode := maintenance.New(cfg.MaintenanceMode, time.Duration(cfg.MaintenanceRetryAfter)*time.Second, logger)
+
+	// In SQS mode the worker long-polls the single queue for new
+	// notifications (in fan-out mode the channel queues below do), and the
+	// database poll becomes a slow sweep for retries, deferred and scheduled
+	// sends, and anything a queue dropped. Without a consumer it keeps
+	// polling as usual.
+	pollInterval := 5 * time.Second
+	var queueConsumer *sqs.Consumer
+	if cfg.WorkerMode == config.WorkerModeSQS {
+		if cfg.SNSFanoutTopicARN == "" {
+			consumer, err := sqs.NewConsumer(ctx, sqs.Config{Region: cfg.SQSRegion, QueueURL: cfg.SQSQueueURL}, logger)
+			if err != nil {
+				logger.Warn("sqs consumer unavailable, relying on DB-poll delivery", zap.Error(err))
+			} else {
+				queueConsumer = consumer
+				defer consumer.Close()
+			}
+		}
+		if queueConsumer != nil || len(cfg.ChannelQueueURLs) > 0 {
+			pollInterval = time.Duration(cfg.WorkerSweepInterval) * time.Second
+		}
+	}
+
+	// Heartbeats go to Redis, when we have it, so every replica's loop is
+	// visible in one place; /readyz reads this replica's directly.
+	hostname, _ := os.Hostname()
+	workerCfg := worker.Config{
+		PollInterval: pollInterval,
 		BatchSize:    10,
+		MaxBatchSize: cfg.WorkerMaxBatchSize,
 		MaxRetries:   5,
-	}, logger)
+		StuckTimeout: time.Duration(cfg.WorkerStuckTimeout) * time.Second,
+		GroupWindow:  time.Duration(cfg.NotificationGroupWindow) * time.Second,
+		Instance:     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		Paused:       maintenanceMode.Enabled,
+		Pricing: worker.Pricing{
+			SESPerEmail:    cfg.SESCostPerEmail,
+			SNSPerSegment:  cfg.SMSCostPerSegment,
+			WebhookPerCall: cfg.WebhookCostPerCall,
+		},
+		Events: lifecycle,
+		// A slow sweep is still a live loop.
+		HeartbeatStaleAfter: max(2*time.Minute, 2*pollInterval),
+	}
+	// Send limits checked before each send, first to defer wins. Warm-up
+	// counts the sends it lets through, so it goes last.
+	throttles := []func(context.Context, *db.Notification) time.Duration{
+		budget.NewHold(repo, logger).Delay,
+	}
+	if cfg.ReputationGuardEnabled {
+		throttles = append(throttles, reputation.NewThrottle(repo, cfg.ReputationThrottlePerMinute, logger).Delay)
+	}
+	if len(cfg.EmailWarmupSchedule) > 0 {
+		throttles = append(throttles, reputation.NewWarmup(repo, cfg.EmailWarmupSchedule, logger).Delay)
+	}
+	workerCfg.Throttle = reputation.Combine(throttles...)
+	workerCfg.FrequencyCap = frequency.New(repo, logger).Check
+	workerCfg.Preferences = preferences.New(repo, logger).Check
+	var heartbeats *redis.HeartbeatStore
+	if redisClient != nil {
+		heartbeats = redis.NewHeartbeatStore(redisClient, 2*time.Minute)
+		workerCfg.PublishHeartbeat = func(ctx context.Context, hb worker.Heartbeat) error {
+			return heartbeats.Publish(ctx, hb.Instance, hb)
+		}
+	}
+	w := worker.New(repo, multiSender, workerCfg, logger)
 
 	workerCtx, workerCancel := context.WithCancel(context.Background())
 	defer workerCancel()
@@ -239,6 +710,97 @@ func run() error {
 
 	logger.Info("background worker started")
 
+	// Dedicated channel workers, one per queue subscribed to the fan-out
+	// topic. They only speed delivery up; the poll loop above still
+	// covers anything a queue drops.
+	for channel, queueURL := range cfg.ChannelQueueURLs {
+		consumer, err := sqs.NewConsumer(ctx, sqs.Config{Region: cfg.SQSRegion, QueueURL: queueURL}, logger)
+		if err != nil {
+			logger.Warn("channel queue consumer unavailable, relying on DB-poll delivery",
+				zap.Error(err),
+				zap.String("channel", channel),
+			)
+			continue
+		}
+		defer consumer.Close()
+		go w.ConsumeQueue(workerCtx, channel, consumer)
+	}
+	if queueConsumer != nil {
+		go w.ConsumeQueue(workerCtx, "", queueConsumer)
+		logger.Info("sqs worker mode: consuming the notification queue",
+			zap.Duration("sweep_interval", pollInterval),
+		)
+	}
+	if demoQueue != nil {
+		for _, channel := range []string{"email", "sms", "webhook", "slack"} {
+			go w.ConsumeQueue(workerCtx, channel, demoQueue.Channel(channel))
+		}
+	}
+
+	// Every replica exports the queue gauges itself, so they don't go
+	// stale on replicas that lose the job lock.
+	if cfg.QueueMetricsInterval > 0 {
+		gauges := backpressure.NewGauges(repo, time.Duration(cfg.QueueMetricsInterval)*time.Second, logger)
+		go gauges.Run(workerCtx)
+	}
+
+	// ── Background Jobs ──────────────────────────────────────────────────────
+	// Periodic maintenance runs on one runner. Each job locks its job_runs
+	// row before running, so with several replicas a run happens once.
+	jobRunner := jobs.NewRunner(repo, workerCfg.Instance, logger)
+	mustRegister := func(job jobs.Job) {
+		if err := jobRunner.Register(job); err != nil {
+			logger.Fatal("failed to register job", zap.String("job", job.Name), zap.Error(err))
+		}
+	}
+	mustRegister(jobs.Job{Name: "stuck-reaper", Interval: time.Minute, Run: w.ReapStuck})
+	if cfg.NotificationRetentionDays > 0 {
+		mustRegister(jobs.Job{
+			Name:     "notification-retention",
+			Interval: time.Hour,
+			Timeout:  10 * time.Minute,
+			Run:      jobs.NotificationRetention(repo, time.Duration(cfg.NotificationRetentionDays)*24*time.Hour, logger),
+		})
+	}
+	if cfg.DLQPurgeAfterDays > 0 {
+		mustRegister(jobs.Job{
+			Name:     "dlq-purge",
+			Interval: time.Hour,
+			Timeout:  10 * time.Minute,
+			Run:      jobs.DeadLetterPurge(repo, time.Duration(cfg.DLQPurgeAfterDays)*24*time.Hour, logger),
+		})
+	}
+
+	if cfg.ReputationGuardEnabled {
+		mustRegister(jobs.Job{
+			Name:     "email-reputation",
+			Interval: 5 * time.Minute,
+			Run: reputation.Guard(repo, reputation.Thresholds{
+				MinSent:           int64(cfg.ReputationMinSent),
+				BounceThrottle:    cfg.ReputationBounceThrottle,
+				BouncePause:       cfg.ReputationBouncePause,
+				ComplaintThrottle: cfg.ReputationComplaintThrottle,
+				ComplaintPause:    cfg.ReputationComplaintPause,
+			}, logger),
+		})
+	}
+
+	mustRegister(jobs.Job{
+		Name:     "dlq-auto-retry",
+		Interval: 5 * time.Minute,
+		Run:      jobs.DeadLetterAutoRetry(repo, logger),
+	})
+
+	mustRegister(jobs.Job{
+		Name:     "tenant-budgets",
+		Interval: 5 * time.Minute,
+		Run:      budget.Check(repo, logger),
+	})
+
+	go jobRunner.Start(workerCtx)
+
+	logger.Info("background jobs started", zap.Int("jobs", len(jobRunner.Jobs())))
+
 	// ── gRPC Server ──────────────────────────────────────────────────────────
 	// We start gRPC on a separate port (9090) alongside HTTP (8080).
 	//
@@ -254,12 +816,15 @@ func run() error {
 		// Order matters: auth runs first, then any future tracing/logging interceptors.
 		googlegrpc.ChainUnaryInterceptor(
 			internalgrpc.AuthInterceptor(cfg.GRPCAuthTokens, logger),
+			internalgrpc.MaintenanceInterceptor(maintenanceMode),
 		),
 		googlegrpc.ChainStreamInterceptor(
 			internalgrpc.StreamAuthInterceptor(cfg.GRPCAuthTokens, logger),
 		),
 	)
-	notificationv1.RegisterNotificationServiceServer(grpcServer, internalgrpc.NewServer(repo, logger))
+	grpcService := internalgrpc.NewServer(repo, logger)
+	grpcService.SetEvents(lifecycle)
+	notificationv1.RegisterNotificationServiceServer(grpcServer, grpcService)
 
 	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
 	if err != nil {
@@ -286,6 +851,14 @@ func run() error {
 		ragPipeline := rag.NewPipeline(embedder, store, reranker, guard, aiClient, logger)
 		ragHandler = rag.NewHandler(ragPipeline, logger)
 		logger.Info("RAG pipeline enabled (pgvector + hybrid search)")
+	} else if cfg.AIEnabled {
+		logger.Warn("RAG pipeline disabled: the knowledge base needs Postgres (pgvector)",
+			zap.String("driver", cfg.DBDriver))
+	}
+
+	trustedProxies, err := api.NewTrustedProxies(cfg.TrustedProxyCIDRs)
+	if err != nil {
+		logger.Fatal("invalid trusted proxies", zap.Error(err))
 	}
 
 	// Setup router
@@ -293,49 +866,166 @@ func run() error {
 
 	// Middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(api.RequestLoggerMiddleware(logger))
+	r.Use(trustedProxies.Middleware)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(30 * time.Second))
 	r.Use(metrics.Middleware)
 
-	// Custom logging middleware
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	// CORS, for dashboards calling the API from the browser. Preflights are
+	// answered before auth and rate limiting.
+	r.Use(api.CORSMiddleware(api.CORSConfig{
+		AllowedOrigins: cfg.CORSAllowedOrigins,
+		AllowedMethods: cfg.CORSAllowedMethods,
+		AllowedHeaders: cfg.CORSAllowedHeaders,
+		MaxAge:         cfg.CORSMaxAge,
+	}))
 
-			next.ServeHTTP(ww, r)
-
-			logger.Info("request completed",
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
-				zap.Int("status", ww.Status()),
-				zap.Duration("duration_ms", time.Since(start)),
-				zap.String("request_id", middleware.GetReqID(r.Context())),
-			)
-		})
-	})
+	// Access log
+	r.Use(api.AccessLogMiddleware(logger, api.AccessLogConfig{
+		SkipPaths:  cfg.AccessLogSkipPaths,
+		SampleRate: cfg.AccessLogSampleRate,
+	}))
 
 	// API routes
 	var handler *api.Handler
 	if idempotencyService != nil && producer != nil {
 		handler = api.NewHandlerWithSQS(logger, repo, idempotencyService, producer)
+	} else if producer != nil {
+		handler = api.NewHandlerWithSQS(logger, repo, nil, producer)
 	} else if idempotencyService != nil {
 		handler = api.NewHandlerWithIdempotency(logger, repo, idempotencyService)
 	} else {
 		handler = api.NewHandler(logger, repo)
 	}
+	handler.SetChannelSettings(repo)
+	handler.SetEvents(lifecycle)
+	handler.SetPreflight(repo)
+	handler.SetCategories(repo)
+	// Tenants can opt in to mandatory Idempotency-Key headers on create.
+	tenantSettings := api.NewTenantSettingsHandler(logger, repo)
+	handler.SetIdempotencyPolicy(tenantSettings)
+	handler.SetSMSPolicy(api.SMSPolicy{
+		MaxSegments:    cfg.SMSMaxSegments,
+		CostPerSegment: cfg.SMSCostPerSegment,
+	})
+	if cfg.EmailValidationMode != config.EmailValidationOff {
+		handler.SetEmailPolicy(api.EmailPolicy{
+			Checker: emailcheck.New(emailcheck.Config{
+				LookupMX:        cfg.EmailMXLookup,
+				ExtraDisposable: cfg.EmailDisposableDomains,
+			}, logger),
+			Enforce: cfg.EmailValidationMode == config.EmailValidationEnforce,
+		})
+	}
+	if cfg.BackpressureDue > 0 || cfg.BackpressureDeadLetters > 0 {
+		handler.SetBackpressure(api.BackpressurePolicy{
+			Guard: backpressure.NewGuard(repo, backpressure.Thresholds{
+				Due:         int64(cfg.BackpressureDue),
+				DeadLetters: int64(cfg.BackpressureDeadLetters),
+			}, cfg.BackpressureExemptTags, time.Duration(cfg.BackpressureRetryAfter)*time.Second, logger),
+			Status: cfg.BackpressureStatus,
+		})
+		logger.Info("create backpressure enabled",
+			zap.Int("due_threshold", cfg.BackpressureDue),
+			zap.Int("dlq_threshold", cfg.BackpressureDeadLetters),
+		)
+	}
+	var templateCompiler api.TemplateCompiler
+	if cfg.MJMLEnabled {
+		templateCompiler = mjml.NewClient(mjml.Config{
+			BaseURL:   cfg.MJMLAPIURL,
+			AppID:     cfg.MJMLAppID,
+			SecretKey: cfg.MJMLSecretKey,
+		}, logger)
+		logger.Info("MJML template compilation enabled")
+	}
+	templateHandler := api.NewTemplateHandler(logger, repo, templateCompiler)
+	if aiHandler != nil {
+		templateHandler.SetEnricher(aiClient)
+	}
+
+	// Bulk imports. Uploads always work; referencing a file by S3 key needs
+	// IMPORT_S3_BUCKET.
+	importHandler := api.NewImportHandler(logger, handler, repo)
+	if cfg.ImportS3Bucket != "" {
+		importSource, err := imports.NewS3(ctx, imports.S3Config{
+			Region: cfg.ImportS3Region,
+			Bucket: cfg.ImportS3Bucket,
+		})
+		if err != nil {
+			logger.Warn("import bucket unavailable, only uploaded imports are accepted", zap.Error(err))
+		} else {
+			importHandler.SetSource(importSource)
+			logger.Info("S3 imports enabled", zap.String("bucket", cfg.ImportS3Bucket))
+		}
+	}
+	tenantRateLimits := api.NewTenantRateLimitHandler(logger, repo)
+	rateLimitExemptions, err := api.NewRateLimitExemptions(cfg.RateLimitExemptCIDRs, cfg.RateLimitExemptTokens)
+	if err != nil {
+		logger.Fatal("invalid rate limit exemptions", zap.Error(err))
+	}
+	if !rateLimitExemptions.Empty() {
+		logger.Info("rate limit exemptions enabled",
+			zap.Strings("cidrs", cfg.RateLimitExemptCIDRs),
+			zap.Int("tokens", len(cfg.RateLimitExemptTokens)),
+		)
+	}
+
+	// DLQ exports to S3 for tenants' own post-mortems.
+	if cfg.DLQExportS3Bucket != "" {
+		exporter, err := dlqexport.NewS3(ctx, dlqexport.Config{
+			Region: cfg.DLQExportS3Region,
+			Bucket: cfg.DLQExportS3Bucket,
+			Prefix: cfg.DLQExportS3Prefix,
+		})
+		if err != nil {
+			logger.Warn("DLQ export bucket unavailable, exports disabled", zap.Error(err))
+		} else {
+			handler.SetDLQExporter(exporter)
+			logger.Info("DLQ exports enabled", zap.String("bucket", cfg.DLQExportS3Bucket))
+		}
+	}
+
+	// Tenant IP allowlists and payload masking apply to keys wherever they
+	// authenticate: /v2 always, /v1 with V1_AUTH_REQUIRED.
+	ipAllowlists := api.NewIPAllowlistHandler(logger, repo)
+	handler.SetMaskMode(api.MaskMode(cfg.APIPayloadMasking))
+
 	r.Route("/v1", func(r chi.Router) {
-		// Apply rate limiting to API routes
-		r.Use(api.RateLimitMiddleware(rateLimiter, logger, api.TenantKeyFunc))
+		// Apply rate limiting to API routes. Order matters: the global ceiling
+		// sheds load before we spend a Redis round-trip per tenant, and the
+		// stricter per-route limits run last so their headers win. Internal
+		// traffic only skips the tenant and route limits.
+		// With V1_AUTH_REQUIRED, auth runs before the tenant limits so they
+		// key on the caller's tenant rather than a header it chose.
+		r.Use(api.RateLimitMiddleware(globalLimiter, logger, api.GlobalKeyFunc))
+		tenantKey := api.TenantKeyFunc
+		if cfg.V1AuthRequired {
+			r.Use(api.V1AuthMiddleware(cfg.APIAuthTokens, cfg.APITokenRoles, repo, logger))
+			r.Use(ipAllowlists.V1Middleware)
+			tenantKey = api.AuthTenantKeyFunc
+		}
+		r.Use(api.RateLimitExemptMiddleware(rateLimitExemptions))
+		r.Use(tenantRateLimits.Middleware(rateLimiter, tenantKey))
+		r.Use(api.RouteRateLimitMiddleware(routeLimiters, logger, tenantKey))
+		r.Use(api.MaintenanceMiddleware(maintenanceMode))
 
 		r.Post("/notifications", handler.CreateNotification)
+		r.Post("/notifications/validate", handler.ValidateNotification)
 		r.Get("/notifications", handler.ListNotifications)
 		r.Get("/notifications/{id}", handler.GetNotification)
+		r.Get("/notifications/{id}/timeline", handler.GetNotificationTimeline)
+		r.Get("/notifications/by-provider-id/{id}", handler.GetNotificationByProviderID)
+		r.Patch("/notifications/{id}", handler.EditNotification)
+		r.Post("/notifications/{id}/cancel", handler.CancelNotification)
+		r.Patch("/notifications/status", handler.BatchUpdateNotificationStatus)
 		r.Patch("/notifications/{id}/status", handler.UpdateNotificationStatus)
 
 		// Dead Letter Queue routes
 		r.Get("/dlq", handler.ListDeadLetterQueue)
+		r.Post("/dlq/retry", handler.RetryDeadLetterQueue)
+		r.Post("/dlq/export", handler.ExportDeadLetterQueue)
 		r.Get("/dlq/{id}", handler.GetDeadLetterItem)
 		r.Post("/dlq/{id}/retry", handler.RetryDeadLetterItem)
 		r.Post("/dlq/{id}/discard", handler.DiscardDeadLetterItem)
@@ -350,19 +1040,163 @@ func run() error {
 		if ragHandler != nil {
 			r.Post("/ai/ask", ragHandler.HandleAsk)
 		}
+
+		// Per-tenant channel settings (e.g. SMS origination identity)
+		channelSettings := api.NewChannelSettingsHandler(logger, repo)
+		channelSettings.SetSecretBox(headersBox)
+		channelSettings.SetIdentityChecker(emailIdentities)
+		r.Get("/tenants/{tenant_id}/channels/{channel}/settings", channelSettings.GetSettings)
+		r.Put("/tenants/{tenant_id}/channels/{channel}/settings", channelSettings.PutSettings)
+
+		// Tenant-wide API settings (e.g. mandatory idempotency keys)
+		r.Get("/tenants/{tenant_id}/settings", tenantSettings.GetSettings)
+		r.Put("/tenants/{tenant_id}/settings", tenantSettings.PutSettings)
+
+		// Per-tenant daily sends and estimated cost, for chargeback
+		usage := api.NewUsageHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/usage", usage.GetUsage)
+
+		// Monthly spend/volume budget with 80%/100% alerts
+		budgets := api.NewBudgetHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/budget", budgets.GetBudget)
+		r.Put("/tenants/{tenant_id}/budget", budgets.PutBudget)
+		r.Delete("/tenants/{tenant_id}/budget", budgets.DeleteBudget)
+
+		// Tenant notification categories and the per-category breakdown
+		categories := api.NewCategoryHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/categories", categories.ListCategories)
+		r.Put("/tenants/{tenant_id}/categories/{category}", categories.PutCategory)
+		r.Delete("/tenants/{tenant_id}/categories/{category}", categories.DeleteCategory)
+		r.Get("/tenants/{tenant_id}/usage/categories", categories.GetStats)
+
+		// Per-user frequency caps by channel and category, enforced by the worker
+		frequencyCaps := api.NewFrequencyCapHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/frequency-caps", frequencyCaps.ListCaps)
+		r.Put("/tenants/{tenant_id}/frequency-caps/{channel}", frequencyCaps.PutCap)
+		r.Delete("/tenants/{tenant_id}/frequency-caps/{channel}", frequencyCaps.DeleteCap)
+
+		// Automatic DLQ retries by reason code, run by the dlq-auto-retry job
+		dlqRetryPolicies := api.NewDLQRetryPolicyHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/dlq/retry-policies", dlqRetryPolicies.ListPolicies)
+		r.Put("/tenants/{tenant_id}/dlq/retry-policies/{reason}", dlqRetryPolicies.PutPolicy)
+		r.Delete("/tenants/{tenant_id}/dlq/retry-policies/{reason}", dlqRetryPolicies.DeletePolicy)
+
+		// Reusable recipients, referenced from payloads as "recipient_ref": "user"
+		recipients := api.NewRecipientHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/recipients/{user_id}", recipients.GetRecipient)
+		r.Put("/tenants/{tenant_id}/recipients/{user_id}", recipients.PutRecipient)
+		r.Delete("/tenants/{tenant_id}/recipients/{user_id}", recipients.DeleteRecipient)
+
+		// Users' category opt-outs, enforced by the worker
+		preferenceHandler := api.NewPreferenceHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/recipients/{user_id}/preferences", preferenceHandler.GetPreferences)
+		r.Put("/tenants/{tenant_id}/recipients/{user_id}/preferences", preferenceHandler.PutPreferences)
+
+		// Hard-bounced and provider-rejected recipients the worker skips
+		invalidRecipients := api.NewInvalidRecipientHandler(logger, repo)
+		r.Get("/tenants/{tenant_id}/invalid-recipients", invalidRecipients.ListInvalidRecipients)
+		r.Delete("/tenants/{tenant_id}/invalid-recipients", invalidRecipients.ReinstateRecipient)
+
+		// Email templates: MJML is compiled to HTML on publish. Without an
+		// MJML API configured, drafts can be created but not published.
+		r.Post("/templates", templateHandler.CreateTemplate)
+		r.Get("/templates/{id}", templateHandler.GetTemplate)
+		r.Post("/templates/{id}/publish", templateHandler.PublishTemplate)
+		r.Post("/templates/{id}/preview", templateHandler.PreviewTemplate)
+
+		// Bulk import from CSV/JSONL; rows become notifications in the background
+		r.Post("/imports", importHandler.CreateImport)
+		r.Get("/imports/{id}", importHandler.GetImport)
+
+		// Sandbox test inbox: lets integration tests read back captured deliveries
+		if cfg.SandboxMode {
+			inbox := api.NewTestInboxHandler(logger, repo)
+			r.Get("/test/deliveries", inbox.ListDeliveries)
+			r.Delete("/test/deliveries", inbox.ClearDeliveries)
+		}
 	})
 
+	// v2: same operations, but the tenant comes from the Bearer token and
+	// every response uses the data/meta/errors envelope. /v1 stays frozen.
+	v2 := api.NewV2Handler(handler)
+	v2.SetMaskMode(api.MaskMode(cfg.APIPayloadMasking))
+	r.Route("/v2", func(r chi.Router) {
+		// Auth runs first so the tenant limiter can key on the token's tenant.
+		r.Use(api.RateLimitMiddleware(globalLimiter, logger, api.GlobalKeyFunc))
+		r.Use(api.BearerAuthMiddlewareWithKeys(cfg.APIAuthTokens, repo, logger))
+		r.Use(ipAllowlists.Middleware)
+		r.Use(api.RoleMiddleware(cfg.APITokenRoles))
+		r.Use(api.RateLimitExemptMiddleware(rateLimitExemptions))
+		r.Use(tenantRateLimits.Middleware(rateLimiter, api.AuthTenantKeyFunc))
+		r.Use(api.MaintenanceMiddleware(maintenanceMode))
+
+		read := r.With(api.RequireScope(api.ScopeRead))
+		write := r.With(api.RequireScope(api.ScopeWrite))
+
+		write.Post("/notifications", v2.CreateNotification)
+		read.Get("/notifications", v2.ListNotifications)
+		read.Get("/notifications/{id}", v2.GetNotification)
+
+		read.Get("/dlq", v2.ListDeadLetterQueue)
+		write.Post("/dlq/retry", v2.RetryDeadLetterQueue)
+		read.Get("/dlq/{id}", v2.GetDeadLetterItem)
+		write.Post("/dlq/{id}/retry", v2.RetryDeadLetterItem)
+		write.Post("/dlq/{id}/discard", v2.DiscardDeadLetterItem)
+
+		keys := api.NewAPIKeyHandler(logger, repo)
+		r.Route("/api-keys", func(r chi.Router) {
+			r.Use(api.RequireScope(api.ScopeKeys))
+			r.Post("/", keys.CreateKey)
+			r.Get("/", keys.ListKeys)
+			r.Post("/{id}/roll", keys.RollKey)
+			r.Post("/{id}/revoke", keys.RevokeKey)
+		})
+
+		r.Route("/ip-allowlist", func(r chi.Router) {
+			r.Use(api.RequireScope(api.ScopeKeys))
+			r.Get("/", ipAllowlists.GetAllowlist)
+			r.Put("/", ipAllowlists.PutAllowlist)
+		})
+	})
+
+	// Short link redirects. Recipients click these from SMS, so they are
+	// unauthenticated and only subject to the global ceiling.
+	if cfg.ShortLinkBaseURL != "" {
+		links := api.NewShortLinkHandler(logger, repo, cfg.ShortLinkDomains)
+		r.With(api.RateLimitMiddleware(globalLimiter, logger, api.GlobalKeyFunc)).
+			Get("/r/{code}", links.Redirect)
+	}
+
 	// Health check
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("OK"))
 	})
 
+	// Readiness: unlike /health, fails when the worker loop has stopped
+	// polling even though the process is alive.
+	r.Get("/readyz", func(rw http.ResponseWriter, r *http.Request) {
+		hb := w.Heartbeat()
+		status, code := "ready", http.StatusOK
+		if hb.Stale(time.Now(), w.StaleAfter()) {
+			status, code = "worker_stalled", http.StatusServiceUnavailable
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(code)
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"status": status,
+			"worker": hb,
+		})
+	})
+
 	// Circuit breaker status endpoint — shows real-time health of all downstream services
 	breakers := []*circuitbreaker.CircuitBreaker{sesBreaker, webhookBreaker}
 	if snsBreaker != nil {
 		breakers = append(breakers, snsBreaker)
 	}
+	if slackBreaker != nil {
+		breakers = append(breakers, slackBreaker)
+	}
 	r.Get("/v1/health/circuits", func(w http.ResponseWriter, r *http.Request) {
 		stats := make([]circuitbreaker.Stats, 0, len(breakers))
 		for _, b := range breakers {
@@ -374,8 +1208,14 @@ func run() error {
 		})
 	})
 
+	// Every /v1/admin route needs an operator token from ADMIN_AUTH_TOKENS;
+	// tenant tokens and API keys never reach them. Support operators may use
+	// the routes on admin, and only admins those on adminOnly.
+	admin := r.With(api.AdminAuthMiddleware(cfg.AdminAuthTokens, cfg.AdminTokenRoles, logger))
+	adminOnly := admin.With(api.RequireOperatorRole(api.OperatorRoleAdmin))
+
 	// Admin endpoint to reset a circuit breaker
-	r.Post("/v1/admin/circuits/{name}/reset", func(w http.ResponseWriter, r *http.Request) {
+	adminOnly.Post("/v1/admin/circuits/{name}/reset", func(w http.ResponseWriter, r *http.Request) {
 		name := chi.URLParam(r, "name")
 		for _, b := range breakers {
 			if b.Stats().Name == name {
@@ -394,8 +1234,88 @@ func run() error {
 		})
 	})
 
+	// Admin endpoints to inspect and flip maintenance mode. Registered outside
+	// the /v1 group so the maintenance middleware never blocks turning it off.
+	maintenanceHandler := api.NewMaintenanceHandler(maintenanceMode, logger)
+	admin.Get("/v1/admin/maintenance", maintenanceHandler.GetStatus)
+	adminOnly.Put("/v1/admin/maintenance", maintenanceHandler.SetStatus)
+
+	jobsHandler := api.NewJobsHandler(logger, repo)
+	admin.Get("/v1/admin/jobs", jobsHandler.ListJobs)
+
+	// System-wide snapshot for the ops dashboard. Without Redis only this
+	// replica's worker heartbeat is visible.
+	listHeartbeats := func(ctx context.Context) ([]json.RawMessage, error) {
+		hb, err := json.Marshal(w.Heartbeat())
+		return []json.RawMessage{hb}, err
+	}
+	if heartbeats != nil {
+		listHeartbeats = heartbeats.List
+	}
+	overview := api.NewOverviewHandler(logger, repo, breakers, listHeartbeats)
+	admin.Get("/v1/admin/overview", overview.GetOverview)
+
+	// Per-tenant delivery pause: the worker skips a paused tenant's pending
+	// rows until it is resumed.
+	tenantPauses := api.NewTenantPauseHandler(logger, repo)
+	admin.Get("/v1/admin/tenants/paused", tenantPauses.ListPauses)
+	adminOnly.Put("/v1/admin/tenants/{tenantID}/pause", tenantPauses.Pause)
+	adminOnly.Delete("/v1/admin/tenants/{tenantID}/pause", tenantPauses.Resume)
+
+	// Platform-wide channel kill switches: creates still succeed, the worker
+	// holds the channel's notifications until it is revived.
+	killSwitches := api.NewChannelKillSwitchHandler(logger, repo)
+	admin.Get("/v1/admin/channels/killed", killSwitches.ListKilled)
+	adminOnly.Put("/v1/admin/channels/{channel}/kill", killSwitches.Kill)
+	adminOnly.Delete("/v1/admin/channels/{channel}/kill", killSwitches.Revive)
+
+	// Bulk requeue of stuck or failed work back to pending.
+	requeue := api.NewRequeueHandler(logger, repo)
+	adminOnly.Post("/v1/admin/notifications/requeue", requeue.Requeue)
+
+	// Cross-tenant search by user for investigations; every lookup is audited.
+	userLookup := api.NewUserLookupHandler(logger, repo)
+	admin.Get("/v1/admin/users/{userID}/notifications", userLookup.SearchByUser)
+
+	// Throttles and pauses applied by the reputation guard.
+	sendLimits := api.NewSendLimitHandler(logger, repo)
+	admin.Get("/v1/admin/tenants/send-limits", sendLimits.ListLimits)
+	adminOnly.Delete("/v1/admin/tenants/{tenantID}/send-limits/{channel}", sendLimits.LiftLimit)
+
+	// Per-tenant API rate limit overrides: burst and Retry-After hint.
+	admin.Get("/v1/admin/tenants/rate-limits", tenantRateLimits.ListRateLimits)
+	adminOnly.Put("/v1/admin/tenants/{tenantID}/rate-limit", tenantRateLimits.PutRateLimit)
+	adminOnly.Delete("/v1/admin/tenants/{tenantID}/rate-limit", tenantRateLimits.DeleteRateLimit)
+
+	// SES bounce/complaint/delivery notifications, via an SNS HTTPS
+	// subscription. Outside /v1 so tenant rate limits and maintenance mode
+	// never make SNS drop events.
+	if cfg.DeliveryEventsToken != "" {
+		deliveryEvents := api.NewDeliveryEventsHandler(logger, repo, cfg.DeliveryEventsToken)
+		if warehouse != nil {
+			deliveryEvents.SetExporter(warehouse)
+		}
+		r.Post("/v1/providers/ses/events", deliveryEvents.ReceiveSESEvent)
+	}
+
+	// A tenant's first API key; later ones it can issue itself at /v2/api-keys.
+	adminKeys := api.NewAPIKeyHandler(logger, repo)
+	admin.Post("/v1/admin/tenants/{tenantID}/api-keys", adminKeys.IssueKey)
+
+	// Short-lived read-only tokens for support to see a tenant's API as the
+	// tenant does; issuing and using them is audited.
+	impersonation := api.NewImpersonationHandler(logger, repo)
+	admin.Post("/v1/admin/tenants/{tenantID}/impersonate", impersonation.Impersonate)
+
+	// Email warm-up progress, and ending it early for established senders.
+	warmups := api.NewEmailWarmupHandler(logger, repo, cfg.EmailWarmupSchedule)
+	admin.Get("/v1/admin/tenants/{tenantID}/warmup", warmups.GetWarmup)
+	adminOnly.Delete("/v1/admin/tenants/{tenantID}/warmup", warmups.EndWarmup)
+
 	// Prometheus metrics endpoint
-	r.Handle("/metrics", metrics.Handler())
+	if cfg.MetricsBackendEnabled("prometheus") {
+		r.Handle("/metrics", metrics.Handler())
+	}
 
 	// Setup HTTP server
 	srv := &http.Server{
@@ -406,12 +1326,52 @@ func run() error {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Native TLS: the main listener serves HTTPS (and HTTP/2), and an
+	// optional plain-HTTP listener redirects to it.
+	var redirectSrv *http.Server
+	tlsCfg := tlsserve.Config{
+		CertFile:         cfg.TLSCertFile,
+		KeyFile:          cfg.TLSKeyFile,
+		AutocertDomains:  cfg.TLSAutocertDomains,
+		AutocertCacheDir: cfg.TLSAutocertCacheDir,
+		AutocertEmail:    cfg.TLSAutocertEmail,
+		MinVersion:       cfg.TLSMinVersion,
+	}
+	if tlsCfg.Enabled() {
+		tlsConfig, redirect, err := tlsserve.New(tlsCfg, cfg.Port)
+		if err != nil {
+			return fmt.Errorf("configure TLS: %w", err)
+		}
+		srv.TLSConfig = tlsConfig
+		srv.Handler = tlsserve.HSTS(cfg.HSTSMaxAge)(r)
+		if cfg.TLSHTTPPort > 0 {
+			redirectSrv = &http.Server{
+				Addr:              fmt.Sprintf(":%d", cfg.TLSHTTPPort),
+				Handler:           redirect,
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+		}
+	}
+
 	// Start server in a goroutine
 	serverErrors := make(chan error, 1)
 	go func() {
+		if srv.TLSConfig != nil {
+			logger.Info("server listening with TLS", zap.String("addr", srv.Addr))
+			serverErrors <- srv.ListenAndServeTLS("", "")
+			return
+		}
 		logger.Info("server listening", zap.String("addr", srv.Addr))
 		serverErrors <- srv.ListenAndServe()
 	}()
+	if redirectSrv != nil {
+		go func() {
+			logger.Info("HTTPS redirect listening", zap.String("addr", redirectSrv.Addr))
+			if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverErrors <- err
+			}
+		}()
+	}
 
 	// Listen for shutdown signals
 	shutdown := make(chan os.Signal, 1)
@@ -435,12 +1395,55 @@ func run() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		if redirectSrv != nil {
+			_ = redirectSrv.Shutdown(ctx)
+		}
 		if err := srv.Shutdown(ctx); err != nil {
 			srv.Close()
 			return fmt.Errorf("graceful shutdown failed: %w", err)
 		}
 
 		logger.Info("server stopped gracefully")
+
+		// Drain the worker last: no new requests can arrive, so we stop
+		// claiming, let the in-flight batch finish sending and persisting its
+		// statuses, and only cancel the sends if that overruns the budget.
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), time.Duration(cfg.WorkerDrainTimeout)*time.Second)
+		defer drainCancel()
+
+		// Imports still running drain alongside the worker, so a slow import
+		// can't eat the worker's budget; whatever they don't finish is marked
+		// failed with the rows created so far.
+		importsClosed := make(chan error, 1)
+		go func() { importsClosed <- importHandler.Close(drainCtx) }()
+
+		if err := w.Shutdown(drainCtx); err != nil {
+			logger.Warn("worker drain timed out, cancelling in-flight sends", zap.Error(err))
+			workerCancel()
+		} else {
+			logger.Info("worker drained gracefully")
+		}
+		if err := <-importsClosed; err != nil {
+			logger.Warn("imports interrupted by shutdown", zap.Error(err))
+		}
+
+		if shadow != nil {
+			if err := shadow.Close(drainCtx); err != nil {
+				logger.Warn("shadow sends still in flight", zap.Error(err))
+			}
+		}
+
+		// Publish the events the drained sends produced.
+		if eventBridge != nil {
+			if err := eventBridge.Close(drainCtx); err != nil {
+				logger.Warn("lifecycle events not fully flushed", zap.Error(err))
+			}
+		}
+		if warehouse != nil {
+			if err := warehouse.Close(drainCtx); err != nil {
+				logger.Warn("analytics events not fully exported", zap.Error(err))
+			}
+		}
 	}
 
 	return nil
@@ -0,0 +1,350 @@
+// Command loadgen sends synthetic notification traffic to a Nimbus
+// environment at a fixed rate and reports the API's latency percentiles,
+// for capacity planning. Point it at a sandbox or staging environment:
+// every request it sends is a real notification.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type config struct {
+	targetURL    string
+	token        string
+	rps          float64
+	duration     time.Duration
+	concurrency  int
+	mix          []channelWeight
+	payloadBytes int
+	emailTo      string
+	smsTo        string
+	webhookURL   string
+}
+
+type channelWeight struct {
+	channel string
+	weight  int
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("sending %.1f req/s for %s to %s (mix %s, %d-byte payloads)",
+		cfg.rps, cfg.duration, cfg.targetURL, formatMix(cfg.mix), cfg.payloadBytes)
+
+	rep := run(ctx, cfg)
+	rep.print(os.Stdout)
+}
+
+func loadConfig() (config, error) {
+	cfg := config{
+		targetURL:    strings.TrimSuffix(os.Getenv("LOADGEN_TARGET_URL"), "/"),
+		token:        os.Getenv("LOADGEN_TOKEN"),
+		rps:          10,
+		duration:     time.Minute,
+		concurrency:  200,
+		mix:          []channelWeight{{channel: "email", weight: 1}},
+		payloadBytes: 256,
+		emailTo:      "success@simulator.amazonses.com",
+		smsTo:        "+14155550100",
+		webhookURL:   "https://example.com/nimbus-loadgen",
+	}
+	if cfg.targetURL == "" {
+		return cfg, fmt.Errorf("LOADGEN_TARGET_URL is required")
+	}
+	if cfg.token == "" {
+		return cfg, fmt.Errorf("LOADGEN_TOKEN is required")
+	}
+
+	if v := os.Getenv("LOADGEN_RPS"); v != "" {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil || rps <= 0 {
+			return cfg, fmt.Errorf("invalid LOADGEN_RPS: %q", v)
+		}
+		cfg.rps = rps
+	}
+	if v := os.Getenv("LOADGEN_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid LOADGEN_DURATION: %q", v)
+		}
+		cfg.duration = d
+	}
+	if v := os.Getenv("LOADGEN_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("invalid LOADGEN_CONCURRENCY: %q", v)
+		}
+		cfg.concurrency = n
+	}
+	if v := os.Getenv("LOADGEN_CHANNEL_MIX"); v != "" {
+		mix, err := parseMix(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOADGEN_CHANNEL_MIX: %q: %w", v, err)
+		}
+		cfg.mix = mix
+	}
+	if v := os.Getenv("LOADGEN_PAYLOAD_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("invalid LOADGEN_PAYLOAD_BYTES: %q", v)
+		}
+		cfg.payloadBytes = n
+	}
+	if v := os.Getenv("LOADGEN_EMAIL_TO"); v != "" {
+		cfg.emailTo = v
+	}
+	if v := os.Getenv("LOADGEN_SMS_TO"); v != "" {
+		cfg.smsTo = v
+	}
+	if v := os.Getenv("LOADGEN_WEBHOOK_URL"); v != "" {
+		cfg.webhookURL = v
+	}
+	return cfg, nil
+}
+
+// parseMix parses "email=80,sms=15,webhook=5" into relative weights.
+func parseMix(raw string) ([]channelWeight, error) {
+	var mix []channelWeight
+	for _, part := range strings.Split(raw, ",") {
+		channel, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected channel=weight, got %q", part)
+		}
+		if channel != "email" && channel != "sms" && channel != "webhook" {
+			return nil, fmt.Errorf("unknown channel %q", channel)
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s", weight, channel)
+		}
+		if w > 0 {
+			mix = append(mix, channelWeight{channel: channel, weight: w})
+		}
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("no channel has a positive weight")
+	}
+	return mix, nil
+}
+
+func formatMix(mix []channelWeight) string {
+	parts := make([]string, len(mix))
+	for i, cw := range mix {
+		parts[i] = fmt.Sprintf("%s=%d", cw.channel, cw.weight)
+	}
+	return strings.Join(parts, ",")
+}
+
+func pickChannel(mix []channelWeight) string {
+	total := 0
+	for _, cw := range mix {
+		total += cw.weight
+	}
+	n := rand.IntN(total)
+	for _, cw := range mix {
+		if n < cw.weight {
+			return cw.channel
+		}
+		n -= cw.weight
+	}
+	return mix[len(mix)-1].channel
+}
+
+// payload builds a request body for channel, padding the free-text part to
+// cfg.payloadBytes. SMS messages are not padded past one segment so they
+// stay under the API's segment cap.
+func payload(cfg config, channel string) map[string]any {
+	filler := strings.Repeat("x", cfg.payloadBytes)
+	switch channel {
+	case "sms":
+		return map[string]any{"phone_number": cfg.smsTo, "message": "loadgen " + filler[:min(len(filler), 150)]}
+	case "webhook":
+		return map[string]any{"url": cfg.webhookURL, "body": map[string]string{"event": "loadgen", "data": filler}}
+	default:
+		return map[string]any{"to": cfg.emailTo, "subject": "Nimbus load test", "body": filler}
+	}
+}
+
+// sample is the result of one request.
+type sample struct {
+	channel string
+	status  int // 0 when the request failed without a response
+	latency time.Duration
+}
+
+// run sends requests open-loop at cfg.rps until cfg.duration has passed or
+// ctx is cancelled. A request that would exceed cfg.concurrency in flight
+// is not sent and counts as dropped, so a slow target shows up in the
+// report instead of silently lowering the rate.
+func run(ctx context.Context, cfg config) *report {
+	client := &http.Client{Timeout: 30 * time.Second}
+	runID := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	rep := &report{started: time.Now()}
+	slots := make(chan struct{}, cfg.concurrency)
+	var wg sync.WaitGroup
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.rps))
+	defer ticker.Stop()
+
+	for seq := 0; ; seq++ {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			rep.elapsed = time.Since(rep.started)
+			return rep
+		case <-ticker.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			rep.drop()
+			continue
+		}
+
+		wg.Add(1)
+		go func(seq int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			rep.add(send(client, cfg, fmt.Sprintf("loadgen-%s-%d", runID, seq)))
+		}(seq)
+	}
+}
+
+func send(client *http.Client, cfg config, idempotencyKey string) sample {
+	channel := pickChannel(cfg.mix)
+	body, _ := json.Marshal(map[string]any{
+		"user_id": "00000000-0000-0000-0000-000000000000",
+		"channel": channel,
+		"payload": payload(cfg, channel),
+		"tags":    []string{"loadgen"},
+	})
+
+	// Not tied to the run's context: in-flight requests finish after the
+	// run ends so their latency is still measured.
+	req, err := http.NewRequest(http.MethodPost, cfg.targetURL+"/v2/notifications", bytes.NewReader(body))
+	if err != nil {
+		return sample{channel: channel}
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.token)
+	req.Header.Set("Content-Type", "application/json")
+	// A unique key per request, so identical synthetic payloads aren't
+	// deduplicated by the content-hash idempotency check.
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		return sample{channel: channel, latency: latency}
+	}
+	resp.Body.Close()
+	return sample{channel: channel, status: resp.StatusCode, latency: latency}
+}
+
+type report struct {
+	mu      sync.Mutex
+	started time.Time
+	elapsed time.Duration
+	samples []sample
+	dropped int
+}
+
+func (r *report) add(s sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples = append(r.samples, s)
+}
+
+func (r *report) drop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped++
+}
+
+func (r *report) print(w io.Writer) {
+	accepted := 0
+	statuses := map[string]int{}
+	byChannel := map[string][]time.Duration{}
+	var all []time.Duration
+	for _, s := range r.samples {
+		status := "error"
+		if s.status != 0 {
+			status = strconv.Itoa(s.status)
+		}
+		statuses[status]++
+		if s.status >= 200 && s.status < 300 {
+			accepted++
+		}
+		all = append(all, s.latency)
+		byChannel[s.channel] = append(byChannel[s.channel], s.latency)
+	}
+
+	fmt.Fprintf(w, "\nsent %d requests in %s (%.1f req/s), %d accepted, %d dropped by the client\n",
+		len(r.samples), r.elapsed.Round(time.Millisecond), float64(len(r.samples))/r.elapsed.Seconds(), accepted, r.dropped)
+
+	codes := make([]string, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  %-7s %d\n", code, statuses[code])
+	}
+
+	fmt.Fprintf(w, "\n%-8s %7s %9s %9s %9s %9s\n", "channel", "count", "p50", "p90", "p99", "max")
+	channels := make([]string, 0, len(byChannel))
+	for channel := range byChannel {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		printLatencies(w, channel, byChannel[channel])
+	}
+	if len(channels) > 1 {
+		printLatencies(w, "all", all)
+	}
+}
+
+func printLatencies(w io.Writer, name string, latencies []time.Duration) {
+	slices.Sort(latencies)
+	fmt.Fprintf(w, "%-8s %7d %9s %9s %9s %9s\n", name, len(latencies),
+		percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99),
+		latencies[len(latencies)-1].Round(time.Microsecond))
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank].Round(time.Microsecond)
+}
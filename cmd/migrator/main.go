@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
@@ -10,10 +11,19 @@ import (
 	"strings"
 	"time"
 
+	gomysql "github.com/go-sql-driver/mysql"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// migrationDB is what applyMigrations needs from the target database.
+type migrationDB interface {
+	ensureSchemaTable(ctx context.Context) error
+	isApplied(ctx context.Context, name string) (bool, error)
+	exec(ctx context.Context, script string) error
+	markApplied(ctx context.Context, name string) error
+}
+
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
 
@@ -22,40 +32,88 @@ func main() {
 		log.Fatal("DATABASE_URL is required")
 	}
 
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = "postgres"
+	}
+
 	migrationsDir := os.Getenv("MIGRATIONS_DIR")
 	if migrationsDir == "" {
 		migrationsDir = "/migrations"
+		if driver == "mysql" {
+			migrationsDir = "/migrations/mysql"
+		}
 	}
 
 	ctx := context.Background()
 
+	var target migrationDB
+	switch driver {
+	case "postgres":
+		pool, err := connectPostgres(ctx, databaseURL)
+		if err != nil {
+			log.Fatalf("connect to database: %v", err)
+		}
+		defer pool.Close()
+		target = pgMigrationDB{pool}
+	case "mysql":
+		conn, err := connectMySQL(databaseURL)
+		if err != nil {
+			log.Fatalf("connect to database: %v", err)
+		}
+		defer conn.Close()
+		target = mysqlMigrationDB{conn}
+	default:
+		log.Fatalf("invalid DB_DRIVER: %q (want postgres or mysql)", driver)
+	}
+
+	if err := target.ensureSchemaTable(ctx); err != nil {
+		log.Fatalf("ensure schema_migrations: %v", err)
+	}
+
+	applied, skipped, err := applyMigrations(ctx, target, migrationsDir)
+	if err != nil {
+		log.Fatalf("apply migrations: %v", err)
+	}
+
+	log.Printf("migrations complete (applied=%d, skipped=%d)", applied, skipped)
+}
+
+func connectPostgres(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
-		log.Fatalf("parse DATABASE_URL: %v", err)
+		return nil, fmt.Errorf("parse DATABASE_URL: %w", err)
 	}
 	cfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol // allow multi-statement migrations
 	cfg.ConnConfig.RuntimeParams["application_name"] = "nimbus-migrator"
 
-	pool, err := pgxpool.NewWithConfig(ctx, cfg)
-	if err != nil {
-		log.Fatalf("connect to database: %v", err)
-	}
-	defer pool.Close()
+	return pgxpool.NewWithConfig(ctx, cfg)
+}
 
-	if err := ensureSchemaTable(ctx, pool); err != nil {
-		log.Fatalf("ensure schema_migrations: %v", err)
+// connectMySQL opens databaseURL, a go-sql-driver DSN such as
+// user:pass@tcp(host:3306)/nimbus.
+func connectMySQL(databaseURL string) (*sql.DB, error) {
+	cfg, err := gomysql.ParseDSN(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse DATABASE_URL: %w", err)
 	}
+	cfg.MultiStatements = true // allow multi-statement migrations
+	cfg.ParseTime = true
 
-	applied, skipped, err := applyMigrations(ctx, pool, migrationsDir)
+	conn, err := sql.Open("mysql", cfg.FormatDSN())
 	if err != nil {
-		log.Fatalf("apply migrations: %v", err)
+		return nil, err
 	}
-
-	log.Printf("migrations complete (applied=%d, skipped=%d)", applied, skipped)
+	// One connection, so a failed script can't leave a half-applied
+	// migration visible to the next statement on another connection.
+	conn.SetMaxOpenConns(1)
+	return conn, nil
 }
 
-func ensureSchemaTable(ctx context.Context, pool *pgxpool.Pool) error {
-	_, err := pool.Exec(ctx, `
+type pgMigrationDB struct{ pool *pgxpool.Pool }
+
+func (m pgMigrationDB) ensureSchemaTable(ctx context.Context) error {
+	_, err := m.pool.Exec(ctx, `
         CREATE TABLE IF NOT EXISTS schema_migrations (
             name TEXT PRIMARY KEY,
             applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
@@ -64,7 +122,54 @@ func ensureSchemaTable(ctx context.Context, pool *pgxpool.Pool) error {
 	return err
 }
 
-func applyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir string) (int, int, error) {
+func (m pgMigrationDB) isApplied(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := m.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = $1)", name).Scan(&exists)
+	return exists, err
+}
+
+func (m pgMigrationDB) exec(ctx context.Context, script string) error {
+	_, err := m.pool.Exec(ctx, script)
+	return err
+}
+
+func (m pgMigrationDB) markApplied(ctx context.Context, name string) error {
+	_, err := m.pool.Exec(ctx, "INSERT INTO schema_migrations(name) VALUES($1) ON CONFLICT DO NOTHING", name)
+	return err
+}
+
+type mysqlMigrationDB struct{ conn *sql.DB }
+
+func (m mysqlMigrationDB) ensureSchemaTable(ctx context.Context) error {
+	_, err := m.conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            name VARCHAR(255) NOT NULL PRIMARY KEY,
+            applied_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+        )
+    `)
+	return err
+}
+
+func (m mysqlMigrationDB) isApplied(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := m.conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = ?)", name).Scan(&exists)
+	return exists, err
+}
+
+// exec runs a migration script. DDL commits implicitly on MySQL, so unlike
+// Postgres a script that fails partway leaves its earlier statements
+// applied; write MySQL migrations to be safe to re-run.
+func (m mysqlMigrationDB) exec(ctx context.Context, script string) error {
+	_, err := m.conn.ExecContext(ctx, script)
+	return err
+}
+
+func (m mysqlMigrationDB) markApplied(ctx context.Context, name string) error {
+	_, err := m.conn.ExecContext(ctx, "INSERT IGNORE INTO schema_migrations(name) VALUES(?)", name)
+	return err
+}
+
+func applyMigrations(ctx context.Context, target migrationDB, migrationsDir string) (int, int, error) {
 	entries, err := os.ReadDir(migrationsDir)
 	if err != nil {
 		return 0, 0, fmt.Errorf("read migrations dir %s: %w", migrationsDir, err)
@@ -82,7 +187,7 @@ func applyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir stri
 
 		name := entry.Name()
 
-		alreadyApplied, err := isApplied(ctx, pool, name)
+		alreadyApplied, err := target.isApplied(ctx, name)
 		if err != nil {
 			return applied, skipped, fmt.Errorf("check applied %s: %w", name, err)
 		}
@@ -100,11 +205,11 @@ func applyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir stri
 		log.Printf("applying %s", name)
 		start := time.Now()
 
-		if _, err := pool.Exec(ctx, string(contents)); err != nil {
+		if err := target.exec(ctx, string(contents)); err != nil {
 			return applied, skipped, fmt.Errorf("execute %s: %w", name, err)
 		}
 
-		if err := markApplied(ctx, pool, name); err != nil {
+		if err := target.markApplied(ctx, name); err != nil {
 			return applied, skipped, fmt.Errorf("mark applied %s: %w", name, err)
 		}
 
@@ -114,14 +219,3 @@ func applyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir stri
 
 	return applied, skipped, nil
 }
-
-func isApplied(ctx context.Context, pool *pgxpool.Pool, name string) (bool, error) {
-	var exists bool
-	err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = $1)", name).Scan(&exists)
-	return exists, err
-}
-
-func markApplied(ctx context.Context, pool *pgxpool.Pool, name string) error {
-	_, err := pool.Exec(ctx, "INSERT INTO schema_migrations(name) VALUES($1) ON CONFLICT DO NOTHING", name)
-	return err
-}
@@ -0,0 +1,145 @@
+// Command nimbusctl is the operator CLI for a running gateway. It calls the
+// /v1/admin endpoints of the gateway at NIMBUS_URL (default
+// http://localhost:8080), sending NIMBUS_TOKEN, an operator token from the
+// gateway's ADMIN_AUTH_TOKENS, as a Bearer token.
+//
+//	nimbusctl requeue -status processing -older-than 10m
+//	nimbusctl requeue -status failed -channel webhook -error "connection refused"
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `usage: nimbusctl <command> [flags]
+
+commands:
+  requeue   move stuck or failed notifications back to pending
+`
+
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	c := &client{
+		baseURL: strings.TrimSuffix(os.Getenv("NIMBUS_URL"), "/"),
+		token:   os.Getenv("NIMBUS_TOKEN"),
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+	if c.baseURL == "" {
+		c.baseURL = "http://localhost:8080"
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "requeue":
+		err = requeue(c, args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func requeue(c *client, args []string) error {
+	fs := flag.NewFlagSet("requeue", flag.ExitOnError)
+	status := fs.String("status", "", "status to requeue: processing or failed (required)")
+	olderThan := fs.Duration("older-than", 0, "only notifications last updated at least this long ago (at least 1m for processing)")
+	channel := fs.String("channel", "", "only this channel: email, sms or webhook")
+	tenant := fs.String("tenant", "", "only this tenant ID")
+	errContains := fs.String("error", "", "only notifications whose last error contains this text")
+	limit := fs.Int("limit", 0, "most notifications to requeue (server default 1000, max 10000)")
+	_ = fs.Parse(args)
+
+	if *status == "" {
+		fs.Usage()
+		return fmt.Errorf("-status is required")
+	}
+
+	body := map[string]any{
+		"status":         *status,
+		"channel":        *channel,
+		"tenant_id":      *tenant,
+		"error_contains": *errContains,
+		"limit":          *limit,
+	}
+	if *olderThan > 0 {
+		body["older_than"] = olderThan.String()
+	}
+
+	var resp struct {
+		Requeued int64 `json:"requeued"`
+		Limit    int   `json:"limit"`
+	}
+	if err := c.do(http.MethodPost, "/v1/admin/notifications/requeue", body, &resp); err != nil {
+		return err
+	}
+
+	fmt.Printf("requeued %d notifications\n", resp.Requeued)
+	if resp.Requeued == int64(resp.Limit) {
+		fmt.Println("hit the limit; run again to requeue more")
+	}
+	return nil
+}
+
+// do sends body as JSON and decodes a 2xx response into out. Any other
+// status is returned as an error carrying the problem detail.
+func (c *client) do(method, path string, body, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var problem struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal(data, &problem) == nil && problem.Title != "" {
+			return fmt.Errorf("%s %s: %d %s: %s", method, path, resp.StatusCode, problem.Title, problem.Detail)
+		}
+		return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,284 @@
+// Command seed fills a development or demo database with sample tenants,
+// API keys, templates and notifications. It connects the same way the
+// gateway does (DB_DRIVER, DB_HOST, ... or DB_PATH) and is safe to re-run:
+// tenant IDs are derived from their index, and anything already seeded is
+// left alone, so a second run only tops up to the requested size.
+//
+// Seeded notifications are created already sent or failed, addressed to the
+// SES mailbox simulator and example.com, so a running worker never picks
+// them up.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/api"
+	"github.com/lalithlochan/nimbus/internal/config"
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/db/mysql"
+	"github.com/lalithlochan/nimbus/internal/db/sqlite"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// seedNamespace derives seeded tenant IDs, so every run and every machine
+// seeds the same tenants.
+var seedNamespace = uuid.MustParse("6f1c2a0e-5d4b-4c3e-9a8f-7b6e5d4c3b2a")
+
+// seedTag marks seeded notifications, so re-runs can count them.
+const seedTag = "seed"
+
+// seedKeyName names the API key seeded for each tenant.
+const seedKeyName = "seed"
+
+var sampleTemplates = []struct {
+	name, subject, mjml string
+}{
+	{
+		name:    "welcome",
+		subject: "Welcome to {{.product}}",
+		mjml:    `<mjml><mj-body><mj-section><mj-column><mj-text>Hi {{.name}}, welcome aboard.</mj-text></mj-column></mj-section></mj-body></mjml>`,
+	},
+	{
+		name:    "password-reset",
+		subject: "Reset your password",
+		mjml:    `<mjml><mj-body><mj-section><mj-column><mj-text>Use this link to reset your password: {{.link}}</mj-text></mj-column></mj-section></mj-body></mjml>`,
+	},
+	{
+		name:    "receipt",
+		subject: "Your receipt for order {{.order_id}}",
+		mjml:    `<mjml><mj-body><mj-section><mj-column><mj-text>Thanks for your order. Total: {{.total}}</mj-text></mj-column></mj-section></mj-body></mjml>`,
+	},
+}
+
+func main() {
+	tenants := flag.Int("tenants", 3, "number of tenants to seed")
+	notifications := flag.Int("notifications", 25, "sample notifications per tenant")
+	withKeys := flag.Bool("api-keys", true, "seed an API key per tenant")
+	withTemplates := flag.Bool("templates", true, "seed sample email templates per tenant")
+	flag.Parse()
+
+	if *tenants < 1 {
+		log.Fatalf("invalid -tenants: %d (want at least 1)", *tenants)
+	}
+	if *notifications < 0 {
+		log.Fatalf("invalid -notifications: %d", *notifications)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.DemoMode {
+		log.Fatal("DEMO_MODE keeps its database in the gateway's memory; seed a DB_DRIVER=sqlite file instead")
+	}
+
+	// The repositories log every insert at info; keep the output to ours.
+	logger, err := observ.NewLogger(cfg.Env, "warn")
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
+	defer logger.Sync() //nolint:errcheck
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, closeStore, err := openStore(ctx, cfg, logger)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer closeStore()
+
+	for i := 1; i <= *tenants; i++ {
+		tenantID := uuid.NewSHA1(seedNamespace, []byte(fmt.Sprintf("tenant-%d", i)))
+		tctx := db.WithTenant(ctx, tenantID)
+		log.Printf("tenant %d: %s", i, tenantID)
+
+		if *withKeys {
+			if err := seedAPIKey(tctx, store, tenantID); err != nil {
+				log.Fatalf("tenant %s: %v", tenantID, err)
+			}
+		}
+		if *withTemplates {
+			if err := seedTemplates(tctx, store, tenantID); err != nil {
+				log.Fatalf("tenant %s: %v", tenantID, err)
+			}
+		}
+		if err := seedNotifications(tctx, store, tenantID, *notifications); err != nil {
+			log.Fatalf("tenant %s: %v", tenantID, err)
+		}
+	}
+
+	log.Printf("seeded %d tenants", *tenants)
+}
+
+// openStore connects to the database the gateway would use.
+func openStore(ctx context.Context, cfg *config.Config, logger *zap.Logger) (db.Store, func(), error) {
+	switch cfg.DBDriver {
+	case config.DBDriverSQLite:
+		database, err := sqlite.New(ctx, cfg.DBPath, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlite.NewRepository(database, logger), database.Close, nil
+	}
+
+	dbConfig := db.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		Database: cfg.DBName,
+		SSLMode:  cfg.DBSSLMode,
+
+		SlowQueryThreshold: time.Duration(cfg.DBSlowQueryMS) * time.Millisecond,
+	}
+	if cfg.DBDriver == config.DBDriverMySQL {
+		database, err := mysql.New(ctx, dbConfig, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mysql.NewRepository(database, logger), database.Close, nil
+	}
+
+	database, err := db.New(ctx, dbConfig, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db.NewRepository(database, logger), database.Close, nil
+}
+
+// seedAPIKey creates the tenant's seed key unless an active one exists. The
+// plaintext is only known when the key is created, so it is printed then;
+// revoke the key to have the next run issue a new one.
+func seedAPIKey(ctx context.Context, store db.Store, tenantID uuid.UUID) error {
+	keys, err := store.ListAPIKeys(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("list api keys: %w", err)
+	}
+	now := time.Now()
+	for _, k := range keys {
+		if k.Name == seedKeyName && k.Active(now) {
+			log.Printf("  api key %s exists", k.Prefix)
+			return nil
+		}
+	}
+
+	key := &db.APIKey{
+		TenantID: tenantID,
+		Name:     seedKeyName,
+		Scopes:   []string{api.ScopeRead, api.ScopeWrite, api.ScopeKeys},
+	}
+	plaintext, err := api.IssueAPIKey(key)
+	if err != nil {
+		return fmt.Errorf("issue api key: %w", err)
+	}
+	if err := store.CreateAPIKey(ctx, key); err != nil {
+		return fmt.Errorf("create api key: %w", err)
+	}
+	log.Printf("  api key created: %s", plaintext)
+	return nil
+}
+
+// seedTemplates creates the sample templates as drafts. Names are unique per
+// tenant, so one that exists is skipped.
+func seedTemplates(ctx context.Context, store db.Store, tenantID uuid.UUID) error {
+	created := 0
+	for _, t := range sampleTemplates {
+		err := store.CreateTemplate(ctx, &db.Template{
+			TenantID:   tenantID,
+			Name:       t.name,
+			Subject:    t.subject,
+			MJMLSource: t.mjml,
+		})
+		if errors.Is(err, db.ErrTemplateNameTaken) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("create template %s: %w", t.name, err)
+		}
+		created++
+	}
+	log.Printf("  templates: %d created, %d existed", created, len(sampleTemplates)-created)
+	return nil
+}
+
+// seedNotifications tops the tenant's seeded notifications up to n, cycling
+// through the channels and marking one in four failed.
+func seedNotifications(ctx context.Context, store db.Store, tenantID uuid.UUID, n int) error {
+	existing, err := store.ListNotificationsByTenant(ctx, tenantID, db.NotificationFilter{Tag: seedTag}, n, 0)
+	if err != nil {
+		return fmt.Errorf("list notifications: %w", err)
+	}
+
+	channels := []string{"email", "sms", "webhook"}
+	for i := len(existing); i < n; i++ {
+		channel := channels[i%len(channels)]
+		payload, err := samplePayload(channel, i)
+		if err != nil {
+			return err
+		}
+
+		notif := &db.Notification{
+			ID:            uuid.New(),
+			TenantID:      tenantID,
+			UserID:        uuid.NewSHA1(tenantID, []byte(fmt.Sprintf("user-%d", i%10))),
+			Channel:       channel,
+			Payload:       payload,
+			Status:        "sent",
+			Attempt:       1,
+			CorrelationID: observ.NewCorrelationID(),
+			Tags:          []string{seedTag, channel},
+		}
+		if i%4 == 3 {
+			notif.Status = "failed"
+			notif.Attempt = 3
+		}
+		if err := store.CreateNotification(ctx, notif); err != nil {
+			return fmt.Errorf("create notification: %w", err)
+		}
+	}
+
+	created := max(n-len(existing), 0)
+	log.Printf("  notifications: %d created, %d existed", created, len(existing))
+	return nil
+}
+
+func samplePayload(channel string, i int) (json.RawMessage, error) {
+	var payload any
+	switch channel {
+	case "email":
+		payload = map[string]string{
+			"to":      "success@simulator.amazonses.com",
+			"subject": fmt.Sprintf("Sample email #%d", i+1),
+			"body":    "This notification was created by cmd/seed.",
+		}
+	case "sms":
+		payload = map[string]string{
+			"phone_number": "+14155550100",
+			"message":      fmt.Sprintf("Sample SMS #%d from cmd/seed", i+1),
+		}
+	default:
+		payload = map[string]any{
+			"url":    "https://example.com/nimbus-seed",
+			"method": "POST",
+			"body":   map[string]int{"sample": i + 1},
+		}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s payload: %w", channel, err)
+	}
+	return b, nil
+}
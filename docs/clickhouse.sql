@@ -0,0 +1,40 @@
+-- ClickHouse schema for the analytics export (CLICKHOUSE_URL).
+-- Columns match the JSON rows internal/analytics inserts; unknown fields
+-- are skipped on insert, so new fields can be added here when needed.
+
+CREATE TABLE IF NOT EXISTS notification_events
+(
+    version             LowCardinality(String),
+    type                LowCardinality(String),
+    notification_id     UUID,
+    tenant_id           UUID,
+    user_id             String,
+    channel             LowCardinality(String),
+    category            LowCardinality(String),
+    status              LowCardinality(String),
+    attempt             UInt16,
+    correlation_id      String,
+    tags                Array(String),
+    provider            LowCardinality(String),
+    provider_message_id String,
+    error               String,
+    next_retry_at       Nullable(DateTime64(3, 'UTC')),
+    occurred_at         DateTime64(3, 'UTC')
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(occurred_at)
+ORDER BY (tenant_id, occurred_at, notification_id);
+
+CREATE TABLE IF NOT EXISTS delivery_events
+(
+    occurred_at         DateTime64(3, 'UTC'),
+    channel             LowCardinality(String),
+    provider            LowCardinality(String),
+    provider_message_id String,
+    type                LowCardinality(String),
+    bounce_type         LowCardinality(String),
+    recipient           String
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(occurred_at)
+ORDER BY (provider, occurred_at);
@@ -9,6 +9,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/events"
+	"github.com/lalithlochan/nimbus/internal/observ"
 )
 
 // ComposeService uses LLM function calling to turn natural language
@@ -17,13 +19,14 @@ type ComposeService struct {
 	client *Client
 	repo   ComposeRepository
 	logger *zap.Logger
+	events events.Emitter // optional; lifecycle events
 }
 
 // ComposeRepository is the subset of db operations compose needs.
 type ComposeRepository interface {
 	CreateNotification(ctx context.Context, notif *db.Notification) error
 	GetNotification(ctx context.Context, id uuid.UUID) (*db.Notification, error)
-	ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*db.Notification, error)
+	ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter db.NotificationFilter, limit, offset int) ([]*db.Notification, error)
 }
 
 // ComposeRequest is the incoming request to the AI compose endpoint.
@@ -48,24 +51,30 @@ func NewComposeService(client *Client, repo ComposeRepository, logger *zap.Logge
 	}
 }
 
+// SetEvents makes notifications the model creates emit a
+// notification.created lifecycle event.
+func (s *ComposeService) SetEvents(emitter events.Emitter) {
+	s.events = emitter
+}
+
 // nimbusTools defines what the LLM can call.
 var nimbusTools = []Tool{
 	{
 		Type: "function",
 		Function: ToolDefinition{
 			Name:        "create_notification",
-			Description: "Create and send a notification via email, SMS, or webhook through Nimbus.",
+			Description: "Create and send a notification via email, SMS, webhook, or Slack through Nimbus.",
 			Parameters: json.RawMessage(`{
 				"type": "object",
 				"properties": {
 					"channel": {
 						"type": "string",
-						"enum": ["email", "sms", "webhook"],
+						"enum": ["email", "sms", "webhook", "slack"],
 						"description": "Notification channel"
 					},
 					"to": {
 						"type": "string",
-						"description": "Recipient: email address, phone number, or webhook URL"
+						"description": "Recipient: email address, phone number, webhook URL, or Slack channel such as #alerts"
 					},
 					"subject": {
 						"type": "string",
@@ -116,12 +125,13 @@ var nimbusTools = []Tool{
 }
 
 const systemPrompt = `You are an AI assistant integrated into Nimbus, a multi-channel notification platform.
-You help users send notifications (email, SMS, webhook) and check their status using natural language.
+You help users send notifications (email, SMS, webhook, Slack) and check their status using natural language.
 
 When creating notifications:
 - For email: always include a subject and body
 - For SMS: include just the message body and phone number
 - For webhook: include the URL and JSON body
+- For Slack: include the channel, such as #alerts, and the message text
 
 Always confirm what you did after executing tools. Be concise.`
 
@@ -249,6 +259,12 @@ func (s *ComposeService) toolCreateNotification(
 			"body": args.Body,
 		})
 		payload = p
+	case db.ChannelSlack:
+		p, _ := json.Marshal(map[string]string{
+			"channel": args.To,
+			"text":    args.Body,
+		})
+		payload = p
 	default:
 		return "", nil, fmt.Errorf("invalid channel: %s", args.Channel)
 	}
@@ -263,12 +279,19 @@ func (s *ComposeService) toolCreateNotification(
 		Attempt:  0,
 	}
 
+	ctx = observ.With(ctx, s.logger,
+		zap.String(observ.FieldTenantID, tenantID.String()),
+		zap.String(observ.FieldNotificationID, notif.ID.String()),
+	)
 	if err := s.repo.CreateNotification(ctx, notif); err != nil {
 		return "", nil, fmt.Errorf("failed to create notification: %w", err)
 	}
 
-	s.logger.Info("AI created notification",
-		zap.String("id", notif.ID.String()),
+	if s.events != nil {
+		s.events.Emit(events.New(events.TypeCreated, notif))
+	}
+
+	observ.Logger(ctx, s.logger).Info("AI created notification",
 		zap.String("channel", args.Channel),
 		zap.String("to", args.To),
 	)
@@ -296,7 +319,7 @@ func (s *ComposeService) toolListNotifications(
 		args.Limit = 10
 	}
 
-	notifications, err := s.repo.ListNotificationsByTenant(ctx, tenantID, args.Limit, 0)
+	notifications, err := s.repo.ListNotificationsByTenant(ctx, tenantID, db.NotificationFilter{}, args.Limit, 0)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to list notifications: %w", err)
 	}
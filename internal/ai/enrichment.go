@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -70,20 +72,7 @@ func (e *EnrichmentSender) Send(ctx context.Context, notif *db.Notification) err
 		zap.String("template", tp.Template),
 	)
 
-	// Build prompt from template + context
-	contextStr := ""
-	for k, v := range tp.Context {
-		contextStr += fmt.Sprintf("- %s: %s\n", k, v)
-	}
-
-	systemPrompt := `You are a professional email content writer for a notification platform.
-Generate clear, concise, professional email body text. Return ONLY the email body, no subject line.
-Keep it under 200 words. Use a friendly but professional tone.`
-
-	userPrompt := fmt.Sprintf("Template: %s\nSubject: %s\nContext:\n%s\nGenerate the email body.",
-		tp.Template, tp.Subject, contextStr)
-
-	body, err := e.client.GenerateText(ctx, systemPrompt, userPrompt)
+	body, err := e.client.GenerateEmailBody(ctx, tp.Template, tp.Subject, tp.Context)
 	if err != nil {
 		e.logger.Error("AI content generation failed, sending without enrichment",
 			zap.String("id", notif.ID.String()),
@@ -109,6 +98,31 @@ Keep it under 200 words. Use a friendly but professional tone.`
 	return e.inner.Send(ctx, notif)
 }
 
+// GenerateEmailBody writes the body of an email called template with the
+// given subject, using vars as context. It is what EnrichmentSender sends,
+// and what template previews show when enrichment is requested.
+func (c *Client) GenerateEmailBody(ctx context.Context, template, subject string, vars map[string]string) (string, error) {
+	// Sorted so the same request always builds the same prompt.
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var contextStr strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&contextStr, "- %s: %s\n", k, vars[k])
+	}
+
+	systemPrompt := `You are a professional email content writer for a notification platform.
+Generate clear, concise, professional email body text. Return ONLY the email body, no subject line.
+Keep it under 200 words. Use a friendly but professional tone.`
+
+	userPrompt := fmt.Sprintf("Template: %s\nSubject: %s\nContext:\n%s\nGenerate the email body.",
+		template, subject, contextStr.String())
+
+	return c.GenerateText(ctx, systemPrompt, userPrompt)
+}
+
 // SupportsChannel delegates to the inner sender.
 func (e *EnrichmentSender) SupportsChannel(channel string) bool {
 	return e.inner.SupportsChannel(channel)
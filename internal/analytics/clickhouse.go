@@ -0,0 +1,235 @@
+// Package analytics streams notification lifecycle and provider delivery
+// events to a ClickHouse warehouse in batches, so reporting queries run
+// there instead of against the operational Postgres.
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/events"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Tables rows are inserted into. docs/clickhouse.sql has their schema.
+const (
+	TableNotificationEvents = "notification_events"
+	TableDeliveryEvents     = "delivery_events"
+)
+
+// Export outcomes, the "outcome" label of nimbus_analytics_exports_total.
+// exported and failed count inserts, dropped counts rows.
+const (
+	outcomeExported = "exported"
+	outcomeDropped  = "dropped"
+	outcomeFailed   = "failed"
+)
+
+// Config configures the ClickHouse exporter.
+type Config struct {
+	// URL is the ClickHouse HTTP interface, e.g. https://ch.internal:8443.
+	URL      string
+	Database string
+	User     string
+	Password string
+	// BatchSize is how many rows are buffered before an insert; smaller
+	// batches are flushed every FlushInterval.
+	BatchSize     int
+	FlushInterval time.Duration
+	// BufferSize bounds the rows waiting to be exported. Rows are dropped
+	// once it is full rather than slow down sends or webhooks.
+	BufferSize int
+}
+
+type row struct {
+	table string
+	data  any
+}
+
+// ClickHouse exports rows through the ClickHouse HTTP interface with
+// INSERT ... FORMAT JSONEachRow, one insert per table per flush. It
+// implements events.Emitter for lifecycle events.
+type ClickHouse struct {
+	cfg    Config
+	client *http.Client
+	logger *zap.Logger
+
+	queue     chan row
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClickHouse creates the exporter and starts its flush loop. Call Close
+// on shutdown to export what is buffered.
+func NewClickHouse(cfg Config, logger *zap.Logger) *ClickHouse {
+	if cfg.Database == "" {
+		cfg.Database = "default"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	c := &ClickHouse{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+		logger: logger,
+		queue:  make(chan row, cfg.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// deliveryEventRow is a provider delivery event as stored in ClickHouse.
+// Lifecycle events are stored as their events.Detail.
+type deliveryEventRow struct {
+	OccurredAt        time.Time `json:"occurred_at"`
+	Channel           string    `json:"channel"`
+	Provider          string    `json:"provider"`
+	ProviderMessageID string    `json:"provider_message_id"`
+	Type              string    `json:"type"`
+	BounceType        string    `json:"bounce_type"`
+	Recipient         string    `json:"recipient"`
+}
+
+// Emit queues a lifecycle event for export.
+func (c *ClickHouse) Emit(detail events.Detail) {
+	c.enqueue(row{table: TableNotificationEvents, data: detail})
+}
+
+// ExportDeliveryEvents queues provider delivery events for export.
+func (c *ClickHouse) ExportDeliveryEvents(evts []*db.DeliveryEvent) {
+	for _, e := range evts {
+		c.enqueue(row{table: TableDeliveryEvents, data: deliveryEventRow{
+			OccurredAt:        e.OccurredAt,
+			Channel:           e.Channel,
+			Provider:          e.Provider,
+			ProviderMessageID: e.ProviderMessageID,
+			Type:              e.Type,
+			BounceType:        e.BounceType,
+			Recipient:         e.Recipient,
+		}})
+	}
+}
+
+func (c *ClickHouse) enqueue(r row) {
+	select {
+	case c.queue <- r:
+	default:
+		metrics.RecordAnalyticsExport(r.table, outcomeDropped)
+	}
+}
+
+// Close stops accepting rows and exports what is buffered, giving up when
+// ctx is done. Nothing may be emitted after Close.
+func (c *ClickHouse) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() { close(c.queue) })
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *ClickHouse) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[string][]any)
+	n := 0
+	flush := func() {
+		for table, rows := range pending {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			c.export(ctx, table, rows)
+			cancel()
+		}
+		clear(pending)
+		n = 0
+	}
+
+	for {
+		select {
+		case r, ok := <-c.queue:
+			if !ok {
+				flush()
+				return
+			}
+			pending[r.table] = append(pending[r.table], r.data)
+			if n++; n >= c.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// export inserts rows into table. A failed batch is dropped, not retried:
+// the warehouse is for analytics, and Postgres remains the record.
+func (c *ClickHouse) export(ctx context.Context, table string, rows []any) {
+	if err := c.insert(ctx, table, rows); err != nil {
+		c.logger.Warn("failed to export analytics rows",
+			zap.Error(err),
+			zap.String("table", table),
+			zap.Int("rows", len(rows)),
+		)
+		metrics.RecordAnalyticsExport(table, outcomeFailed)
+		return
+	}
+	metrics.RecordAnalyticsExport(table, outcomeExported)
+}
+
+func (c *ClickHouse) insert(ctx context.Context, table string, rows []any) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("encode row: %w", err)
+		}
+	}
+
+	q := url.Values{}
+	q.Set("database", c.cfg.Database)
+	q.Set("query", fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", table))
+	// Unknown fields are skipped, so adding a field to Detail never breaks
+	// an older table; timestamps arrive as RFC 3339.
+	q.Set("input_format_skip_unknown_fields", "1")
+	q.Set("date_time_input_format", "best_effort")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL+"/?"+q.Encode(), &body)
+	if err != nil {
+		return fmt.Errorf("build insert request: %w", err)
+	}
+	if c.cfg.User != "" {
+		req.Header.Set("X-ClickHouse-User", c.cfg.User)
+		req.Header.Set("X-ClickHouse-Key", c.cfg.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("insert into %s: %w", table, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("insert into %s: status %d: %s", table, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
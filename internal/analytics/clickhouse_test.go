@@ -0,0 +1,125 @@
+package analytics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/events"
+)
+
+type insert struct {
+	query string
+	rows  []map[string]any
+}
+
+func newTestServer(t *testing.T, status int) (*httptest.Server, func() []insert) {
+	t.Helper()
+	var mu sync.Mutex
+	var inserts []insert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-ClickHouse-User") != "nimbus" || r.Header.Get("X-ClickHouse-Key") != "secret" {
+			t.Errorf("expected credentials headers, got %v", r.Header)
+		}
+		if got := r.URL.Query().Get("database"); got != "analytics" {
+			t.Errorf("expected database analytics, got %q", got)
+		}
+		in := insert{query: r.URL.Query().Get("query")}
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			var row map[string]any
+			if err := json.Unmarshal(sc.Bytes(), &row); err != nil {
+				t.Errorf("expected one JSON object per line, got %q", sc.Text())
+			}
+			in.rows = append(in.rows, row)
+		}
+		mu.Lock()
+		inserts = append(inserts, in)
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []insert {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]insert(nil), inserts...)
+	}
+}
+
+func newTestExporter(url string, batch int) *ClickHouse {
+	return NewClickHouse(Config{
+		URL:           url,
+		Database:      "analytics",
+		User:          "nimbus",
+		Password:      "secret",
+		BatchSize:     batch,
+		FlushInterval: time.Hour,
+	}, zap.NewNop())
+}
+
+func TestClickHouse_FlushesPerTableOnClose(t *testing.T) {
+	srv, inserts := newTestServer(t, http.StatusOK)
+	c := newTestExporter(srv.URL, 100)
+
+	c.Emit(events.Detail{Type: events.TypeSent, NotificationID: "n-1", Channel: "email"})
+	c.Emit(events.Detail{Type: events.TypeFailed, NotificationID: "n-2", Channel: "sms"})
+	c.ExportDeliveryEvents([]*db.DeliveryEvent{
+		{Provider: "ses", Type: db.DeliveryEventBounce, BounceType: "permanent", Recipient: "a@example.com"},
+	})
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("expected clean close, got %v", err)
+	}
+
+	got := map[string]int{}
+	for _, in := range inserts() {
+		got[in.query] += len(in.rows)
+	}
+	if got["INSERT INTO notification_events FORMAT JSONEachRow"] != 2 {
+		t.Errorf("expected 2 lifecycle rows, got %v", got)
+	}
+	if got["INSERT INTO delivery_events FORMAT JSONEachRow"] != 1 {
+		t.Errorf("expected 1 delivery row, got %v", got)
+	}
+}
+
+func TestClickHouse_FlushesFullBatch(t *testing.T) {
+	srv, inserts := newTestServer(t, http.StatusOK)
+	c := newTestExporter(srv.URL, 2)
+	defer c.Close(context.Background())
+
+	c.Emit(events.Detail{NotificationID: "n-1"})
+	c.Emit(events.Detail{NotificationID: "n-2"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(inserts()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := inserts()
+	if len(got) != 1 || len(got[0].rows) != 2 {
+		t.Fatalf("expected one insert of 2 rows before close, got %+v", got)
+	}
+	if got[0].rows[0]["notification_id"] != "n-1" {
+		t.Errorf("expected rows in emit order, got %v", got[0].rows)
+	}
+}
+
+func TestClickHouse_FailedInsertIsDropped(t *testing.T) {
+	srv, inserts := newTestServer(t, http.StatusInternalServerError)
+	c := newTestExporter(srv.URL, 100)
+
+	c.Emit(events.Detail{NotificationID: "n-1"})
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("expected close to succeed despite a failed insert, got %v", err)
+	}
+	if len(inserts()) != 1 {
+		t.Errorf("expected a single attempt, got %d", len(inserts()))
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// AccessLogConfig tunes AccessLogMiddleware.
+type AccessLogConfig struct {
+	// SkipPaths are never logged. Probes and scrapes hit these every few
+	// seconds and drown out real traffic.
+	SkipPaths []string
+
+	// SampleRate is the fraction of 2xx responses logged, from 0 to 1.
+	// Everything else is always logged. 1 logs every request.
+	SampleRate float64
+}
+
+// AccessLogMiddleware logs one "request completed" line per request through
+// the request-scoped logger. Sampled lines carry sample_rate so counts
+// derived from logs can be scaled back up.
+func AccessLogMiddleware(logger *zap.Logger, cfg AccessLogConfig) func(http.Handler) http.Handler {
+	skip := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := skip[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", status),
+				zap.Int("bytes", ww.BytesWritten()),
+				zap.String("user_agent", r.UserAgent()),
+				zap.Duration("duration_ms", time.Since(start)),
+			}
+			if status >= 200 && status < 300 && cfg.SampleRate < 1 {
+				if rand.Float64() >= cfg.SampleRate {
+					return
+				}
+				fields = append(fields, zap.Float64("sample_rate", cfg.SampleRate))
+			}
+
+			observ.Logger(r.Context(), logger).Info("request completed", fields...)
+		})
+	}
+}
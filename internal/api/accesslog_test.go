@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		status     int
+		sampleRate float64
+		expectLog  bool
+	}{
+		{"logs a normal request", "/v1/notifications", http.StatusOK, 1, true},
+		{"skips health", "/health", http.StatusOK, 1, false},
+		{"skips metrics", "/metrics", http.StatusOK, 1, false},
+		{"drops sampled-out 200", "/v1/notifications", http.StatusOK, 0, false},
+		{"keeps errors when sampling", "/v1/notifications", http.StatusInternalServerError, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			mw := AccessLogMiddleware(zap.New(core), AccessLogConfig{
+				SkipPaths:  []string{"/health", "/metrics"},
+				SampleRate: tt.sampleRate,
+			})
+			handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("hello"))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("User-Agent", "nimbus-test/1.0")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got := logs.Len(); got != map[bool]int{true: 1, false: 0}[tt.expectLog] {
+				t.Fatalf("expected log=%v, got %d entries", tt.expectLog, got)
+			}
+			if !tt.expectLog {
+				return
+			}
+			fields := logs.All()[0].ContextMap()
+			if fields["bytes"] != int64(5) || fields["user_agent"] != "nimbus-test/1.0" {
+				t.Errorf("expected bytes and user agent, got %v", fields)
+			}
+		})
+	}
+}
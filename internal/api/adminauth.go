@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+const contextKeyOperator contextKey = "operator"
+
+// OperatorRole is what an operator's ADMIN_AUTH_TOKENS token may do on
+// /v1/admin.
+type OperatorRole string
+
+const (
+	// OperatorRoleAdmin may use every admin endpoint. Tokens without an
+	// explicit role get it.
+	OperatorRoleAdmin OperatorRole = "admin"
+	// OperatorRoleSupport may read admin endpoints, search users and
+	// impersonate tenants, but can't change platform or tenant state, and
+	// may only grant the read scope.
+	OperatorRoleSupport OperatorRole = "support"
+)
+
+// Operator is the person or system an admin request authenticated as.
+type Operator struct {
+	Name string
+	Role OperatorRole
+}
+
+// OperatorFromContext returns the operator AdminAuthMiddleware
+// authenticated.
+func OperatorFromContext(ctx context.Context) (Operator, bool) {
+	op, ok := ctx.Value(contextKeyOperator).(Operator)
+	return op, ok
+}
+
+// operatorCanGrant reports whether the request's operator may give a new
+// API key scope.
+func operatorCanGrant(ctx context.Context, scope string) bool {
+	op, ok := OperatorFromContext(ctx)
+	if !ok {
+		return false
+	}
+	return op.Role == OperatorRoleAdmin || scope == ScopeRead
+}
+
+// AdminAuthMiddleware authenticates /v1/admin requests with the operator
+// tokens in tokens (token → operator name), which are separate from tenant
+// tokens and API keys: no tenant credential reaches the admin API. roles
+// sets a token's OperatorRole; tokens missing from it are admins. With no
+// tokens configured every admin request is refused. The operator is put in
+// the request context, and in its log fields, for handlers that audit.
+func AdminAuthMiddleware(tokens map[string]string, roles map[string]string, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(tokens) == 0 {
+				writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "Admin API disabled", "no operator tokens are configured; set ADMIN_AUTH_TOKENS")
+				return
+			}
+			token, found := bearerToken(r)
+			if !found {
+				writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "Missing bearer token", "send Authorization: Bearer <operator token>")
+				return
+			}
+			name, ok := tokens[token]
+			if !ok {
+				logger.Warn("admin auth: invalid token",
+					zap.String("token_prefix", tokenPrefix(token)),
+					zap.String("path", r.URL.Path),
+					zap.String("remote_addr", r.RemoteAddr),
+				)
+				writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "Invalid bearer token", "")
+				return
+			}
+
+			op := Operator{Name: name, Role: OperatorRoleAdmin}
+			if role := roles[token]; role != "" {
+				op.Role = OperatorRole(role)
+			}
+			ctx := context.WithValue(r.Context(), contextKeyOperator, op)
+			ctx = observ.With(ctx, logger, zap.String("operator", op.Name))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireOperatorRole refuses admin requests from operators without role.
+// It must run after AdminAuthMiddleware.
+func RequireOperatorRole(role OperatorRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if op, ok := OperatorFromContext(r.Context()); !ok || op.Role != role {
+				writeProblem(w, http.StatusForbidden, errTypeForbidden, "Operator role required", "this endpoint needs the "+string(role)+" operator role")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	tokens := map[string]string{"ops-token": "jane@example.com", "support-token": "sam@example.com"}
+	roles := map[string]string{"support-token": string(OperatorRoleSupport)}
+
+	var got Operator
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = OperatorFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	newRouter := func(tokens map[string]string) http.Handler {
+		r := chi.NewRouter()
+		admin := r.With(AdminAuthMiddleware(tokens, roles, zap.NewNop()))
+		admin.Get("/v1/admin/jobs", ok)
+		admin.With(RequireOperatorRole(OperatorRoleAdmin)).Put("/v1/admin/maintenance", ok)
+		return r
+	}
+
+	tests := []struct {
+		name           string
+		tokens         map[string]string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+		expectedName   string
+	}{
+		{"no token", tokens, http.MethodGet, "/v1/admin/jobs", "", http.StatusUnauthorized, ""},
+		{"tenant token", tokens, http.MethodGet, "/v1/admin/jobs", "dev-token-nimbus", http.StatusUnauthorized, ""},
+		{"admin reads", tokens, http.MethodGet, "/v1/admin/jobs", "ops-token", http.StatusOK, "jane@example.com"},
+		{"admin writes", tokens, http.MethodPut, "/v1/admin/maintenance", "ops-token", http.StatusOK, "jane@example.com"},
+		{"support reads", tokens, http.MethodGet, "/v1/admin/jobs", "support-token", http.StatusOK, "sam@example.com"},
+		{"support can't write", tokens, http.MethodPut, "/v1/admin/maintenance", "support-token", http.StatusForbidden, ""},
+		{"none configured", nil, http.MethodGet, "/v1/admin/jobs", "ops-token", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = Operator{}
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			newRouter(tt.tokens).ServeHTTP(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if got.Name != tt.expectedName {
+				t.Errorf("expected operator %q, got %q", tt.expectedName, got.Name)
+			}
+		})
+	}
+}
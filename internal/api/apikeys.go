@@ -0,0 +1,412 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// API key scopes. Reads need ScopeRead, creating notifications and acting
+// on the DLQ need ScopeWrite, and managing keys needs ScopeKeys.
+// ScopeImpersonation marks a support engineer's short-lived key from
+// POST /v1/admin/tenants/{tenantID}/impersonate; it grants nothing itself,
+// can't be requested for other keys, and gets every use audited.
+const (
+	ScopeRead          = "read"
+	ScopeWrite         = "write"
+	ScopeKeys          = "keys"
+	ScopeImpersonation = "impersonation"
+)
+
+// allScopes is what static API_AUTH_TOKENS are granted.
+var allScopes = []string{ScopeRead, ScopeWrite, ScopeKeys}
+
+const (
+	apiKeyPrefix        = "nmb_"
+	apiKeyPrefixBytes   = 4
+	apiKeySecretBytes   = 32
+	maxAPIKeyNameLength = 128
+	maxRollGracePeriod  = 7 * 24 * time.Hour
+)
+
+// APIKeyStore is what authentication needs to resolve database-backed keys.
+type APIKeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*db.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+// APIKeyRepository defines API key management operations.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *db.APIKey) error
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*db.APIKey, error)
+	ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*db.APIKey, error)
+	RollAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, replacement *db.APIKey) error
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+// APIKeyRequest is the body of POST /v2/api-keys.
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RollAPIKeyRequest is the optional body of POST /v2/api-keys/{id}/roll.
+type RollAPIKeyRequest struct {
+	// How long the old key keeps working, so clients can switch over.
+	// Zero expires it immediately.
+	GracePeriodSeconds int `json:"grace_period_seconds"`
+}
+
+// IssuedAPIKey is returned when a key is created or rolled. Key is the only
+// time the plaintext is ever shown.
+type IssuedAPIKey struct {
+	*db.APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyHandler manages a tenant's /v2 API keys, so keys can be rotated
+// without editing API_AUTH_TOKENS or the database by hand.
+type APIKeyHandler struct {
+	repo   APIKeyRepository
+	logger *zap.Logger
+}
+
+// NewAPIKeyHandler creates a handler for API key management.
+func NewAPIKeyHandler(logger *zap.Logger, repo APIKeyRepository) *APIKeyHandler {
+	return &APIKeyHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateKey handles POST /v2/api-keys
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	var req APIKeyRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeMalformedJSON, Message: err.Error()})
+		return
+	}
+
+	scopes, errs := validateAPIKeyRequest(&req, func(scope string) bool { return HasScope(r.Context(), scope) }, time.Now())
+	if len(errs) > 0 {
+		writeV2Error(w, r, http.StatusBadRequest, errs...)
+		return
+	}
+
+	key := &db.APIKey{
+		TenantID:  tenantID,
+		Name:      req.Name,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	plaintext, err := IssueAPIKey(key)
+	if err != nil {
+		h.logger.Error("failed to generate api key", zap.Error(err))
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to create api key"})
+		return
+	}
+
+	if err := h.repo.CreateAPIKey(r.Context(), key); err != nil {
+		h.logger.Error("failed to create api key",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to create api key"})
+		return
+	}
+
+	h.logger.Info("api key created",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("api_key_id", key.ID.String()),
+		zap.String("prefix", key.Prefix),
+	)
+
+	writeV2(w, http.StatusCreated, Envelope{Data: IssuedAPIKey{APIKey: key, Key: plaintext}, Meta: newMeta(r)})
+}
+
+// IssueKey handles POST /v1/admin/tenants/{tenantID}/api-keys. It issues a
+// tenant its first key, which /v2/api-keys can't since it needs one to
+// authenticate. Admin operators may grant any scope, support operators only
+// read. It must run after AdminAuthMiddleware.
+func (h *APIKeyHandler) IssueKey(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req APIKeyRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	scopes, errs := validateAPIKeyRequest(&req, func(scope string) bool { return operatorCanGrant(r.Context(), scope) }, time.Now())
+	if len(errs) > 0 {
+		details := make([]string, len(errs))
+		for i, e := range errs {
+			details[i] = e.Message
+		}
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid API key", strings.Join(details, "; "))
+		return
+	}
+
+	key := &db.APIKey{
+		TenantID:  tenantID,
+		Name:      req.Name,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	plaintext, err := IssueAPIKey(key)
+	if err != nil {
+		h.logger.Error("failed to generate api key", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeInternalError, "Failed to create API key", "")
+		return
+	}
+	if err := h.repo.CreateAPIKey(r.Context(), key); err != nil {
+		h.logger.Error("failed to create api key",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to create API key", "")
+		return
+	}
+
+	op, _ := OperatorFromContext(r.Context())
+	h.logger.Info("api key issued by admin",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("operator", op.Name),
+		zap.Strings("scopes", scopes),
+		zap.String("api_key_id", key.ID.String()),
+		zap.String("prefix", key.Prefix),
+	)
+
+	writeJSON(w, http.StatusCreated, IssuedAPIKey{APIKey: key, Key: plaintext})
+}
+
+// ListKeys handles GET /v2/api-keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	keys, err := h.repo.ListAPIKeys(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to list api keys",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to list api keys"})
+		return
+	}
+	if keys == nil {
+		keys = []*db.APIKey{}
+	}
+
+	writeV2(w, http.StatusOK, Envelope{Data: keys, Meta: newMeta(r)})
+}
+
+// RollKey handles POST /v2/api-keys/{id}/roll. It issues a replacement with
+// the same name, scopes and expiry, and lets the old key live on for the
+// grace period.
+func (h *APIKeyHandler) RollKey(w http.ResponseWriter, r *http.Request) {
+	old, ok := h.ownedKey(w, r)
+	if !ok {
+		return
+	}
+
+	// The body is optional; an empty one means no grace period.
+	var req RollAPIKeyRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeMalformedJSON, Message: err.Error()})
+		return
+	}
+	grace := time.Duration(req.GracePeriodSeconds) * time.Second
+	if grace < 0 || grace > maxRollGracePeriod {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: fmt.Sprintf("grace_period_seconds must be between 0 and %d", int(maxRollGracePeriod.Seconds())),
+			Field:   "grace_period_seconds",
+		})
+		return
+	}
+
+	now := time.Now()
+	if !old.Active(now) || old.ReplacedBy != nil {
+		writeV2Error(w, r, http.StatusConflict, APIError{
+			Code:    ErrCodeAlreadyProcessed,
+			Message: "api key is already revoked, expired or rolled",
+		})
+		return
+	}
+
+	replacement := &db.APIKey{
+		TenantID:  old.TenantID,
+		Name:      old.Name,
+		Scopes:    old.Scopes,
+		ExpiresAt: old.ExpiresAt,
+	}
+	plaintext, err := IssueAPIKey(replacement)
+	if err != nil {
+		h.logger.Error("failed to generate api key", zap.Error(err))
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to roll api key"})
+		return
+	}
+
+	if err := h.repo.RollAPIKey(r.Context(), old.ID, now.Add(grace), replacement); err != nil {
+		h.logger.Error("failed to roll api key",
+			zap.Error(err),
+			zap.String("api_key_id", old.ID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to roll api key"})
+		return
+	}
+
+	writeV2(w, http.StatusCreated, Envelope{Data: IssuedAPIKey{APIKey: replacement, Key: plaintext}, Meta: newMeta(r)})
+}
+
+// RevokeKey handles POST /v2/api-keys/{id}/revoke. Revocation is immediate.
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.ownedKey(w, r)
+	if !ok {
+		return
+	}
+	if key.RevokedAt != nil {
+		writeV2Error(w, r, http.StatusConflict, APIError{
+			Code:    ErrCodeAlreadyProcessed,
+			Message: "api key is already revoked",
+		})
+		return
+	}
+
+	if err := h.repo.RevokeAPIKey(r.Context(), key.ID); err != nil {
+		h.logger.Error("failed to revoke api key",
+			zap.Error(err),
+			zap.String("api_key_id", key.ID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to revoke api key"})
+		return
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	writeV2(w, http.StatusOK, Envelope{Data: key, Meta: newMeta(r)})
+}
+
+// ownedKey loads the {id} key and checks it belongs to the authenticated
+// tenant, writing the error response itself when it doesn't.
+func (h *APIKeyHandler) ownedKey(w http.ResponseWriter, r *http.Request) (*db.APIKey, bool) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: "id must be a valid UUID", Field: "id"})
+		return nil, false
+	}
+
+	key, err := h.repo.GetAPIKey(r.Context(), id)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		h.logger.Error("failed to get api key",
+			zap.Error(err),
+			zap.String("api_key_id", id.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to get api key"})
+		return nil, false
+	}
+	if err != nil || key.TenantID != tenantID {
+		writeV2Error(w, r, http.StatusNotFound, APIError{Code: ErrCodeNotFound, Message: "api key not found"})
+		return nil, false
+	}
+	return key, true
+}
+
+// validateAPIKeyRequest checks every field and returns the de-duplicated
+// scopes. canGrant limits the scopes to those the caller may give.
+func validateAPIKeyRequest(req *APIKeyRequest, canGrant func(scope string) bool, now time.Time) ([]string, []APIError) {
+	var errs []APIError
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		errs = append(errs, APIError{Code: ErrCodeMissingField, Message: "name is required", Field: "name"})
+	} else if len(req.Name) > maxAPIKeyNameLength {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: fmt.Sprintf("name must be at most %d characters", maxAPIKeyNameLength), Field: "name"})
+	}
+
+	var scopes []string
+	seen := map[string]bool{}
+	for _, s := range req.Scopes {
+		switch {
+		case s != ScopeRead && s != ScopeWrite && s != ScopeKeys:
+			errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: fmt.Sprintf("unknown scope %q", s), Field: "scopes"})
+		case !canGrant(s):
+			errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: fmt.Sprintf("cannot grant scope %q you do not hold", s), Field: "scopes"})
+		case !seen[s]:
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	if len(req.Scopes) == 0 {
+		errs = append(errs, APIError{Code: ErrCodeMissingField, Message: "at least one scope is required", Field: "scopes"})
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: "expires_at must be in the future", Field: "expires_at"})
+	}
+
+	return scopes, errs
+}
+
+// IssueAPIKey generates a new key, fills in key.Prefix and key.KeyHash, and
+// returns the plaintext. Keys look like nmb_1a2b3c4d_<64 hex chars>.
+func IssueAPIKey(key *db.APIKey) (string, error) {
+	prefix := make([]byte, apiKeyPrefixBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	key.Prefix = apiKeyPrefix + hex.EncodeToString(prefix)
+	plaintext := key.Prefix + "_" + hex.EncodeToString(secret)
+	key.KeyHash = hashAPIKey(plaintext)
+	return plaintext, nil
+}
+
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
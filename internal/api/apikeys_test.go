@@ -0,0 +1,352 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockAPIKeyRepo struct {
+	keys   map[uuid.UUID]*db.APIKey
+	getErr error // returned by GetAPIKey when set
+}
+
+func newMockAPIKeyRepo() *mockAPIKeyRepo {
+	return &mockAPIKeyRepo{keys: map[uuid.UUID]*db.APIKey{}}
+}
+
+func (m *mockAPIKeyRepo) CreateAPIKey(ctx context.Context, key *db.APIKey) error {
+	key.ID = uuid.New()
+	key.CreatedAt = time.Now()
+	stored := *key
+	m.keys[key.ID] = &stored
+	return nil
+}
+
+func (m *mockAPIKeyRepo) GetAPIKey(ctx context.Context, id uuid.UUID) (*db.APIKey, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if k, ok := m.keys[id]; ok {
+		cp := *k
+		return &cp, nil
+	}
+	return nil, fmt.Errorf("api key %w", db.ErrNotFound)
+}
+
+func (m *mockAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*db.APIKey, error) {
+	for _, k := range m.keys {
+		if k.KeyHash == keyHash {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, errors.New("api key not found")
+}
+
+func (m *mockAPIKeyRepo) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*db.APIKey, error) {
+	var out []*db.APIKey
+	for _, k := range m.keys {
+		if k.TenantID == tenantID {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAPIKeyRepo) RollAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, replacement *db.APIKey) error {
+	if err := m.CreateAPIKey(ctx, replacement); err != nil {
+		return err
+	}
+	m.keys[oldID].ReplacedBy = &replacement.ID
+	m.keys[oldID].ExpiresAt = &oldExpiresAt
+	return nil
+}
+
+func (m *mockAPIKeyRepo) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	m.keys[id].RevokedAt = &now
+	return nil
+}
+
+func (m *mockAPIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	m.keys[id].LastUsedAt = &now
+	return nil
+}
+
+// newAPIKeyRouter mounts /v2 the way cmd/gateway does: static token-a for
+// tenant A, plus database-backed keys.
+func newAPIKeyRouter(keys *mockAPIKeyRepo) http.Handler {
+	v2 := NewV2Handler(NewHandler(zap.NewNop(), NewMockRepository()))
+	h := NewAPIKeyHandler(zap.NewNop(), keys)
+
+	r := chi.NewRouter()
+	r.Use(BearerAuthMiddlewareWithKeys(map[string]string{"token-a": v2TenantA}, keys, zap.NewNop()))
+	r.Use(RoleMiddleware(nil))
+	r.With(RequireScope(ScopeWrite)).Post("/v2/notifications", v2.CreateNotification)
+	r.With(RequireScope(ScopeRead)).Get("/v2/notifications", v2.ListNotifications)
+	r.Route("/v2/api-keys", func(r chi.Router) {
+		r.Use(RequireScope(ScopeKeys))
+		r.Post("/", h.CreateKey)
+		r.Get("/", h.ListKeys)
+		r.Post("/{id}/roll", h.RollKey)
+		r.Post("/{id}/revoke", h.RevokeKey)
+	})
+	return r
+}
+
+func issueTestKey(t *testing.T, router http.Handler, token string, body any) IssuedAPIKey {
+	t.Helper()
+	rec, env := doV2(t, router, http.MethodPost, "/v2/api-keys/", token, body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data, _ := json.Marshal(env.Data)
+	issued := IssuedAPIKey{APIKey: &db.APIKey{}}
+	if err := json.Unmarshal(data, &issued); err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	return issued
+}
+
+func TestAPIKeys_Lifecycle(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	router := newAPIKeyRouter(keys)
+
+	issued := issueTestKey(t, router, "token-a", map[string]any{
+		"name":   "ci",
+		"scopes": []string{"read", "write", "keys"},
+	})
+	if len(issued.Key) < len(issued.Prefix) || issued.Key[:len(issued.Prefix)] != issued.Prefix {
+		t.Fatalf("expected key %q to start with prefix %q", issued.Key, issued.Prefix)
+	}
+	if keys.keys[issued.ID].KeyHash == issued.Key {
+		t.Fatal("plaintext key must not be stored")
+	}
+
+	rec, _ := doV2(t, router, http.MethodGet, "/v2/notifications", issued.Key, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("new key: expected 200, got %d", rec.Code)
+	}
+	if keys.keys[issued.ID].LastUsedAt == nil {
+		t.Error("expected last_used_at to be recorded")
+	}
+
+	// Roll with a grace period: both keys work until the old one expires.
+	rec, env := doV2(t, router, http.MethodPost, "/v2/api-keys/"+issued.ID.String()+"/roll", issued.Key,
+		map[string]int{"grace_period_seconds": 3600})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("roll: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data, _ := json.Marshal(env.Data)
+	rolled := IssuedAPIKey{APIKey: &db.APIKey{}}
+	_ = json.Unmarshal(data, &rolled)
+	if rolled.Key == issued.Key || rolled.Name != "ci" || len(rolled.Scopes) != 3 {
+		t.Fatalf("expected a new key with the same name and scopes, got %+v", rolled.APIKey)
+	}
+	for _, key := range []string{issued.Key, rolled.Key} {
+		if rec, _ := doV2(t, router, http.MethodGet, "/v2/notifications", key, nil); rec.Code != http.StatusOK {
+			t.Errorf("during grace period: expected 200, got %d", rec.Code)
+		}
+	}
+
+	// Rolling the same key twice is a conflict.
+	rec, _ = doV2(t, router, http.MethodPost, "/v2/api-keys/"+issued.ID.String()+"/roll", rolled.Key, nil)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("second roll: expected 409, got %d", rec.Code)
+	}
+
+	rec, _ = doV2(t, router, http.MethodPost, "/v2/api-keys/"+rolled.ID.String()+"/revoke", "token-a", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revoke: expected 200, got %d", rec.Code)
+	}
+	if rec, _ := doV2(t, router, http.MethodGet, "/v2/notifications", rolled.Key, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_ExpiredKeyIsRejected(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	router := newAPIKeyRouter(keys)
+
+	issued := issueTestKey(t, router, "token-a", map[string]any{"name": "short", "scopes": []string{"read"}})
+	past := time.Now().Add(-time.Minute)
+	keys.keys[issued.ID].ExpiresAt = &past
+
+	if rec, _ := doV2(t, router, http.MethodGet, "/v2/notifications", issued.Key, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for expired key, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_Scopes(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	router := newAPIKeyRouter(keys)
+	readOnly := issueTestKey(t, router, "token-a", map[string]any{"name": "support", "scopes": []string{"read"}})
+
+	rec, _ := doV2(t, router, http.MethodGet, "/v2/notifications", readOnly.Key, nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("read: expected 200, got %d", rec.Code)
+	}
+
+	rec, env := doV2(t, router, http.MethodPost, "/v2/notifications", readOnly.Key, map[string]any{
+		"user_id": "00000000-0000-0000-0000-000000000002",
+		"channel": "email",
+		"payload": map[string]string{"to": "user@example.com"},
+	})
+	if rec.Code != http.StatusForbidden || env.Errors[0].Code != ErrCodeForbidden {
+		t.Errorf("write with read key: expected 403 forbidden, got %d %+v", rec.Code, env.Errors)
+	}
+
+	rec, _ = doV2(t, router, http.MethodGet, "/v2/api-keys/", readOnly.Key, nil)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("key management with read key: expected 403, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_CreateValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  map[string]any
+		field string
+	}{
+		{"missing name", map[string]any{"scopes": []string{"read"}}, "name"},
+		{"no scopes", map[string]any{"name": "ci"}, "scopes"},
+		{"unknown scope", map[string]any{"name": "ci", "scopes": []string{"admin"}}, "scopes"},
+		{"expiry in the past", map[string]any{"name": "ci", "scopes": []string{"read"}, "expires_at": "2020-01-01T00:00:00Z"}, "expires_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := newMockAPIKeyRepo()
+			rec, env := doV2(t, newAPIKeyRouter(keys), http.MethodPost, "/v2/api-keys/", "token-a", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", rec.Code)
+			}
+			if len(env.Errors) == 0 || env.Errors[0].Field != tt.field {
+				t.Errorf("expected an error on %s, got %+v", tt.field, env.Errors)
+			}
+			if len(keys.keys) != 0 {
+				t.Error("expected no key to be created")
+			}
+		})
+	}
+}
+
+func TestAPIKeys_CannotEscalateScopes(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	router := newAPIKeyRouter(keys)
+	manager := issueTestKey(t, router, "token-a", map[string]any{"name": "rotator", "scopes": []string{"read", "keys"}})
+
+	rec, env := doV2(t, router, http.MethodPost, "/v2/api-keys/", manager.Key, map[string]any{
+		"name":   "escalated",
+		"scopes": []string{"write"},
+	})
+	if rec.Code != http.StatusBadRequest || env.Errors[0].Field != "scopes" {
+		t.Errorf("expected 400 on scopes, got %d %+v", rec.Code, env.Errors)
+	}
+}
+
+func TestAPIKeys_OtherTenantIsNotFound(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	router := newAPIKeyRouter(keys)
+	other := &db.APIKey{TenantID: uuid.MustParse(v2TenantB), Name: "b", Scopes: []string{"read"}}
+	_ = keys.CreateAPIKey(context.Background(), other)
+
+	rec, _ := doV2(t, router, http.MethodPost, "/v2/api-keys/"+other.ID.String()+"/revoke", "token-a", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+	if keys.keys[other.ID].RevokedAt != nil {
+		t.Error("expected the other tenant's key to be untouched")
+	}
+}
+
+func TestAPIKeys_LookupErrors(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	router := newAPIKeyRouter(keys)
+
+	rec, _ := doV2(t, router, http.MethodPost, "/v2/api-keys/"+uuid.NewString()+"/revoke", "token-a", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown key, got %d", rec.Code)
+	}
+
+	keys.getErr = errors.New("connection refused")
+	rec, _ = doV2(t, router, http.MethodPost, "/v2/api-keys/"+uuid.NewString()+"/revoke", "token-a", nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the lookup fails, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_AdminIssueKey(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	h := NewAPIKeyHandler(zap.NewNop(), keys)
+	r := chi.NewRouter()
+	r.With(AdminAuthMiddleware(
+		map[string]string{"ops-token": "jane@example.com", "support-token": "sam@example.com"},
+		map[string]string{"support-token": string(OperatorRoleSupport)},
+		zap.NewNop(),
+	)).Post("/v1/admin/tenants/{tenantID}/api-keys", h.IssueKey)
+	issue := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/tenants/"+v2TenantB+"/api-keys", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := issue("ops-token", `{"name":"bootstrap","scopes":["read","write","keys"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	issued := IssuedAPIKey{APIKey: &db.APIKey{}}
+	if err := json.Unmarshal(rec.Body.Bytes(), &issued); err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	if issued.TenantID.String() != v2TenantB || len(issued.Scopes) != 3 || issued.Key == "" {
+		t.Errorf("expected a full key for tenant B, got %+v", issued.APIKey)
+	}
+
+	// The new key authenticates as its tenant.
+	if rec, _ := doV2(t, newAPIKeyRouter(keys), http.MethodGet, "/v2/api-keys/", issued.Key, nil); rec.Code != http.StatusOK {
+		t.Errorf("expected the issued key to work, got %d", rec.Code)
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		body           string
+		expectedStatus int
+	}{
+		{"unknown scope", "ops-token", `{"name":"bad","scopes":["admin"]}`, http.StatusBadRequest},
+		{"impersonation scope", "ops-token", `{"name":"bad","scopes":["read","impersonation"]}`, http.StatusBadRequest},
+		{"support grants read", "support-token", `{"name":"dashboard","scopes":["read"]}`, http.StatusCreated},
+		{"support grants write", "support-token", `{"name":"bad","scopes":["read","write"]}`, http.StatusBadRequest},
+		{"tenant key", issued.Key, `{"name":"bad","scopes":["read"]}`, http.StatusUnauthorized},
+		{"no token", "", `{"name":"bad","scopes":["read"]}`, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := issue(tt.token, tt.body); rec.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if len(keys.keys) != 2 {
+		t.Errorf("expected only the bootstrap and dashboard keys, got %d", len(keys.keys))
+	}
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+type contextKey string
+
+const (
+	contextKeyTenantID contextKey = "tenant_id"
+	contextKeyRole     contextKey = "role"
+	contextKeyScopes   contextKey = "scopes"
+	// contextKeyImpersonation holds the ID of the impersonation key a
+	// support engineer is using.
+	contextKeyImpersonation contextKey = "impersonation"
+)
+
+// Role is what an API token may do within its tenant.
+type Role string
+
+const (
+	// RoleFull can read and write everything in the tenant. Tokens without
+	// an explicit role get it.
+	RoleFull Role = "full"
+	// RoleReadOnly can list and fetch, but not create or retry, and sees
+	// payloads masked. Meant for support staff.
+	RoleReadOnly Role = "readonly"
+)
+
+// RoleFromContext returns the caller's role, RoleFull if none was set.
+func RoleFromContext(ctx context.Context) Role {
+	if v, ok := ctx.Value(contextKeyRole).(Role); ok {
+		return v
+	}
+	return RoleFull
+}
+
+// TenantIDFromContext returns the tenant that BearerAuthMiddleware resolved
+// from the request's API token. v2 handlers must use this and never a
+// tenant_id from the body or query string (IDOR, OWASP API1).
+func TenantIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	v, ok := ctx.Value(contextKeyTenantID).(uuid.UUID)
+	return v, ok
+}
+
+// BearerAuthMiddleware maps "Authorization: Bearer <token>" to a tenant and
+// injects it into the request context, where it also scopes the request's
+// database connections (db.WithTenant). It is the REST twin of the gRPC
+// AuthInterceptor and uses the same token → tenant_id map shape.
+//
+// Failures are written as v2 envelopes. /v1 uses V1AuthMiddleware, which
+// also checks the tenant_id the request names.
+func BearerAuthMiddleware(validTokens map[string]string, logger *zap.Logger) func(http.Handler) http.Handler {
+	return BearerAuthMiddlewareWithKeys(validTokens, nil, logger)
+}
+
+// BearerAuthMiddlewareWithKeys is BearerAuthMiddleware that also accepts
+// database-backed API keys (nmb_...) from keys. Static tokens get every
+// scope; API keys get their own, and a key without write or keys scopes is
+// treated as read-only.
+func BearerAuthMiddlewareWithKeys(validTokens map[string]string, keys APIKeyStore, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := bearerToken(r)
+			if !found {
+				writeV2Error(w, r, http.StatusUnauthorized, APIError{
+					Code:    ErrCodeUnauthorized,
+					Message: "missing bearer token",
+				})
+				return
+			}
+
+			if ctx, ok := authenticateToken(r.Context(), token, validTokens, keys, logger); ok {
+				r = r.WithContext(ctx)
+				auditImpersonation(r, logger)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger.Warn("REST auth: invalid token",
+				zap.String("token_prefix", tokenPrefix(token)),
+			)
+			writeV2Error(w, r, http.StatusUnauthorized, APIError{
+				Code:    ErrCodeUnauthorized,
+				Message: "invalid bearer token",
+			})
+		})
+	}
+}
+
+// authenticateToken resolves a static token or API key to its tenant and
+// returns ctx carrying the tenant and the token's scopes.
+func authenticateToken(ctx context.Context, token string, validTokens map[string]string, keys APIKeyStore, logger *zap.Logger) (context.Context, bool) {
+	if tenant, ok := validTokens[token]; ok {
+		if tenantID, err := uuid.Parse(tenant); err == nil {
+			ctx = context.WithValue(ctx, contextKeyTenantID, tenantID)
+			ctx = db.WithTenant(ctx, tenantID)
+			ctx = context.WithValue(ctx, contextKeyScopes, allScopes)
+			ctx = observ.With(ctx, logger, zap.String(observ.FieldTenantID, tenantID.String()))
+			return ctx, true
+		}
+	}
+
+	if keys == nil || !strings.HasPrefix(token, apiKeyPrefix) {
+		return ctx, false
+	}
+	key, err := keys.GetAPIKeyByHash(ctx, hashAPIKey(token))
+	if err != nil || !key.Active(time.Now()) {
+		return ctx, false
+	}
+	if err := keys.TouchAPIKey(ctx, key.ID); err != nil {
+		logger.Warn("failed to record api key use",
+			zap.Error(err),
+			zap.String("api_key_id", key.ID.String()),
+		)
+	}
+	ctx = context.WithValue(ctx, contextKeyTenantID, key.TenantID)
+	ctx = db.WithTenant(ctx, key.TenantID)
+	ctx = context.WithValue(ctx, contextKeyScopes, key.Scopes)
+	ctx = observ.With(ctx, logger, zap.String(observ.FieldTenantID, key.TenantID.String()))
+	if !slices.Contains(key.Scopes, ScopeWrite) && !slices.Contains(key.Scopes, ScopeKeys) {
+		ctx = context.WithValue(ctx, contextKeyRole, RoleReadOnly)
+	}
+	if slices.Contains(key.Scopes, ScopeImpersonation) {
+		ctx = context.WithValue(ctx, contextKeyImpersonation, key.ID)
+		ctx = observ.With(ctx, logger, zap.String("impersonation_key_id", key.ID.String()))
+	}
+	return ctx, true
+}
+
+// ScopesFromContext returns the scopes granted to the caller's token.
+func ScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(contextKeyScopes).([]string)
+	return scopes
+}
+
+// HasScope reports whether the caller's token holds scope.
+func HasScope(ctx context.Context, scope string) bool {
+	return slices.Contains(ScopesFromContext(ctx), scope)
+}
+
+// RequireScope refuses requests whose token lacks scope. It must run after
+// BearerAuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasScope(r.Context(), scope) {
+				writeV2Error(w, r, http.StatusForbidden, APIError{
+					Code:    ErrCodeForbidden,
+					Message: "this token lacks the " + scope + " scope",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RoleMiddleware resolves the caller's role from its bearer token and
+// refuses anything but reads from read-only callers. It must run after
+// BearerAuthMiddleware; tokens missing from tokenRoles get RoleFull, unless
+// authentication already set a role (API keys carry their own).
+func RoleMiddleware(tokenRoles map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			role, set := ctx.Value(contextKeyRole).(Role)
+			if !set {
+				role = RoleFull
+				if token, ok := bearerToken(r); ok && tokenRoles[token] != "" {
+					role = Role(tokenRoles[token])
+				}
+				if role == RoleReadOnly {
+					ctx = context.WithValue(ctx, contextKeyScopes, []string{ScopeRead})
+				}
+			}
+
+			if role == RoleReadOnly && !isReadMethod(r.Method) {
+				writeV2Error(w, r, http.StatusForbidden, APIError{
+					Code:    ErrCodeForbidden,
+					Message: "this token is read-only",
+				})
+				return
+			}
+
+			ctx = context.WithValue(ctx, contextKeyRole, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token = strings.TrimSpace(token)
+	return token, found && token != ""
+}
+
+// tokenPrefix shortens a rejected token to what is safe to log.
+func tokenPrefix(token string) string {
+	if len(token) > 8 {
+		return token[:8] + "..."
+	}
+	return token
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// AuthTenantKeyFunc keys rate limits by the authenticated tenant. It must run
+// after BearerAuthMiddleware; unauthenticated requests are not limited here.
+func AuthTenantKeyFunc(r *http.Request) string {
+	if tenantID, ok := TenantIDFromContext(r.Context()); ok {
+		return "tenant:" + tenantID.String()
+	}
+	return ""
+}
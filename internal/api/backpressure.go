@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/metrics"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+const (
+	errTypeOverloaded  = "overloaded"
+	errTitleOverloaded = "Delivery backlog too large"
+)
+
+// Backpressure decides whether create should refuse a notification while
+// the delivery pipeline is backed up. *backpressure.Guard implements it.
+type Backpressure interface {
+	Shed(ctx context.Context, tags []string) (reason string, shed bool)
+	RetryAfter() time.Duration
+}
+
+// BackpressurePolicy configures load shedding on create. Status is the
+// response code for a shed request, 429 or 503. A nil Guard disables it.
+type BackpressurePolicy struct {
+	Guard  Backpressure
+	Status int
+}
+
+// SetBackpressure makes create refuse low-priority notifications, with a
+// Retry-After, while the backlog is over its thresholds.
+func (h *Handler) SetBackpressure(policy BackpressurePolicy) {
+	h.backpressure = policy
+}
+
+// shed reports whether a notification with tags should be refused, and if
+// so sets Retry-After on w and returns the status and reason to respond
+// with. It runs after validation, so a bad request still gets its 400.
+func (h *Handler) shed(ctx context.Context, w http.ResponseWriter, channel string, tags []string) (status int, reason string, shed bool) {
+	if h.backpressure.Guard == nil {
+		return 0, "", false
+	}
+	reason, shed = h.backpressure.Guard.Shed(ctx, tags)
+	if !shed {
+		return 0, "", false
+	}
+
+	metrics.RecordNotificationShed(channel)
+	observ.Logger(ctx, h.logger).Debug("create shed under backpressure",
+		zap.String(logFieldChannel, channel),
+		zap.String("reason", reason),
+	)
+	w.Header().Set("Retry-After", strconv.Itoa(int(h.backpressure.Guard.RetryAfter().Seconds())))
+	status = h.backpressure.Status
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	return status, reason, true
+}
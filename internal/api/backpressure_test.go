@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type stubBackpressure struct{}
+
+func (stubBackpressure) Shed(ctx context.Context, tags []string) (string, bool) {
+	if slices.Contains(tags, "critical") {
+		return "", false
+	}
+	return "5000 notifications due to send, over the limit of 1000", true
+}
+
+func (stubBackpressure) RetryAfter() time.Duration {
+	return 45 * time.Second
+}
+
+func TestCreateNotification_Backpressure(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		tags           []string
+		expectedStatus int
+	}{
+		{"shed with 503 by default", 0, nil, http.StatusServiceUnavailable},
+		{"shed with 429", http.StatusTooManyRequests, []string{"billing"}, http.StatusTooManyRequests},
+		{"critical accepted", http.StatusTooManyRequests, []string{"critical"}, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(zap.NewNop(), NewMockRepository())
+			handler.SetBackpressure(BackpressurePolicy{Guard: stubBackpressure{}, Status: tt.status})
+
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: "00000000-0000-0000-0000-000000000001",
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "email",
+				Payload:  json.RawMessage(`{"to":"user@example.com"}`),
+				Tags:     tt.tags,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated && rec.Header().Get("Retry-After") != "45" {
+				t.Errorf("expected Retry-After 45, got %q", rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
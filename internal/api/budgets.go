@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const maxBudgetBytes = 4 << 10
+
+// BudgetRepository reads and writes tenant budgets.
+type BudgetRepository interface {
+	GetTenantBudget(ctx context.Context, tenantID uuid.UUID) (*db.TenantBudget, error)
+	UpsertTenantBudget(ctx context.Context, b *db.TenantBudget) error
+	DeleteTenantBudget(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// BudgetHandler lets a tenant set a monthly spend and/or volume budget.
+// The budget job alerts at 80% and 100% of it and, with block_at_limit,
+// the worker holds non-critical sends once it is used up.
+type BudgetHandler struct {
+	repo   BudgetRepository
+	logger *zap.Logger
+}
+
+// NewBudgetHandler creates a handler for tenant budgets.
+func NewBudgetHandler(logger *zap.Logger, repo BudgetRepository) *BudgetHandler {
+	return &BudgetHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type budgetRequest struct {
+	MonthlyCost   *float64 `json:"monthly_cost"`
+	MonthlyVolume *int64   `json:"monthly_volume"`
+	AlertEmail    string   `json:"alert_email"`
+	BlockAtLimit  bool     `json:"block_at_limit"`
+}
+
+// GetBudget handles GET /v1/tenants/{tenant_id}/budget
+func (h *BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	budget, err := h.repo.GetTenantBudget(r.Context(), tenantID)
+	if errors.Is(err, db.ErrNoTenantBudget) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No budget", "the tenant has no budget set")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get tenant budget", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get budget", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, budget)
+}
+
+// PutBudget handles PUT /v1/tenants/{tenant_id}/budget. The body replaces
+// the budget and re-arms this month's alerts.
+func (h *BudgetHandler) PutBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req budgetRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBudgetBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	if detail := validateBudget(&req); detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid budget", detail)
+		return
+	}
+
+	budget := &db.TenantBudget{
+		TenantID:      tenantID,
+		MonthlyCost:   req.MonthlyCost,
+		MonthlyVolume: req.MonthlyVolume,
+		AlertEmail:    req.AlertEmail,
+		BlockAtLimit:  req.BlockAtLimit,
+	}
+	if err := h.repo.UpsertTenantBudget(r.Context(), budget); err != nil {
+		h.logger.Error("failed to save tenant budget", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save budget", "")
+		return
+	}
+
+	h.logger.Info("tenant budget updated", zap.String(logFieldTenantID, tenantID.String()))
+	writeJSON(w, http.StatusOK, budget)
+}
+
+// DeleteBudget handles DELETE /v1/tenants/{tenant_id}/budget, which also
+// releases any sends held by it.
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.DeleteTenantBudget(r.Context(), tenantID)
+	if errors.Is(err, db.ErrNoTenantBudget) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No budget", "the tenant has no budget set")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to delete tenant budget", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to delete budget", "")
+		return
+	}
+
+	h.logger.Info("tenant budget removed", zap.String(logFieldTenantID, tenantID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateBudget(req *budgetRequest) string {
+	if req.MonthlyCost == nil && req.MonthlyVolume == nil {
+		return "set monthly_cost, monthly_volume or both"
+	}
+	if req.MonthlyCost != nil && *req.MonthlyCost <= 0 {
+		return "monthly_cost must be positive"
+	}
+	if req.MonthlyVolume != nil && *req.MonthlyVolume <= 0 {
+		return "monthly_volume must be positive"
+	}
+	if _, err := mail.ParseAddress(req.AlertEmail); err != nil {
+		return "alert_email must be a valid email address"
+	}
+	return ""
+}
+
+// tenantIDPathParam parses the {tenant_id} segment of tenant-facing /v1
+// routes; admin routes use tenantIDParam.
+func tenantIDPathParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "tenant_id"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTenant, errDetailInvalidTenant)
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
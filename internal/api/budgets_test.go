@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockBudgetRepo struct {
+	budgets map[uuid.UUID]*db.TenantBudget
+}
+
+func (m *mockBudgetRepo) GetTenantBudget(ctx context.Context, tenantID uuid.UUID) (*db.TenantBudget, error) {
+	b, ok := m.budgets[tenantID]
+	if !ok {
+		return nil, db.ErrNoTenantBudget
+	}
+	return b, nil
+}
+
+func (m *mockBudgetRepo) UpsertTenantBudget(ctx context.Context, b *db.TenantBudget) error {
+	m.budgets[b.TenantID] = b
+	return nil
+}
+
+func (m *mockBudgetRepo) DeleteTenantBudget(ctx context.Context, tenantID uuid.UUID) error {
+	if _, ok := m.budgets[tenantID]; !ok {
+		return db.ErrNoTenantBudget
+	}
+	delete(m.budgets, tenantID)
+	return nil
+}
+
+func budgetHTTPRequest(method, tenantID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/budget", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPutBudget(t *testing.T) {
+	handler := NewBudgetHandler(zap.NewNop(), &mockBudgetRepo{budgets: map[uuid.UUID]*db.TenantBudget{}})
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		tenantID       string
+		body           string
+		expectedStatus int
+	}{
+		{"invalid tenant", "nope", `{"monthly_cost": 10, "alert_email": "ops@acme.com"}`, http.StatusBadRequest},
+		{"no limit", tenantID, `{"alert_email": "ops@acme.com"}`, http.StatusBadRequest},
+		{"zero cost", tenantID, `{"monthly_cost": 0, "alert_email": "ops@acme.com"}`, http.StatusBadRequest},
+		{"bad email", tenantID, `{"monthly_volume": 1000, "alert_email": "ops"}`, http.StatusBadRequest},
+		{"unknown field", tenantID, `{"monthly_volume": 1000, "alert_email": "ops@acme.com", "currency": "EUR"}`, http.StatusBadRequest},
+		{"valid", tenantID, `{"monthly_cost": 50, "monthly_volume": 100000, "alert_email": "ops@acme.com", "block_at_limit": true}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.PutBudget(rec, budgetHTTPRequest(http.MethodPut, tt.tenantID, tt.body))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetBudget(rec, budgetHTTPRequest(http.MethodGet, tenantID, ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"block_at_limit":true`) {
+		t.Errorf("expected the saved budget, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeleteBudget(rec, budgetHTTPRequest(http.MethodDelete, tenantID, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.GetBudget(rec, budgetHTTPRequest(http.MethodGet, tenantID, ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+const (
+	maxCategoryBytes       = 2 << 10
+	maxCategoryDescription = 500
+	// quietHoursLayout is the "HH:MM" format of quiet hours.
+	quietHoursLayout = "15:04"
+)
+
+// CategoryRepository reads and writes tenants' category taxonomies.
+type CategoryRepository interface {
+	ListTenantNotificationCategories(ctx context.Context, tenantID uuid.UUID) ([]*db.NotificationCategory, error)
+	UpsertNotificationCategory(ctx context.Context, c *db.NotificationCategory) error
+	DeleteNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) error
+	ListCategoryStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*db.CategoryStats, error)
+}
+
+// errCategoriesUnavailable is returned by checkCategory when the tenant's
+// taxonomy can't be read.
+var errCategoriesUnavailable = errors.New("notification categories unavailable")
+
+// CategoryLookup finds a tenant's notification category.
+type CategoryLookup interface {
+	GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*db.NotificationCategory, error)
+}
+
+// SetCategories makes create check a notification's category against its
+// tenant's taxonomy. Without it any well-formed category is accepted.
+func (h *Handler) SetCategories(repo CategoryLookup) {
+	h.categories = repo
+}
+
+// checkCategory rejects a category the tenant hasn't defined.
+func (h *Handler) checkCategory(ctx context.Context, tenantID uuid.UUID, category string) error {
+	if h.categories == nil || category == "" {
+		return nil
+	}
+	_, err := h.categories.GetNotificationCategory(ctx, tenantID, category)
+	if errors.Is(err, db.ErrNoNotificationCategory) {
+		return fmt.Errorf("category %q is not one of the tenant's categories", category)
+	}
+	if err != nil {
+		observ.Logger(ctx, h.logger).Error("failed to get notification category", zap.Error(err))
+		return errCategoriesUnavailable
+	}
+	return nil
+}
+
+// CategoryHandler manages a tenant's notification categories, such as
+// billing, security and marketing. Notifications may only carry a category
+// the tenant has defined; users opt out of categories, and a category's
+// quiet hours hold its notifications overnight. A mandatory category, such
+// as security alerts, can't be opted out of and has no quiet hours.
+type CategoryHandler struct {
+	repo   CategoryRepository
+	logger *zap.Logger
+}
+
+// NewCategoryHandler creates a handler for notification categories.
+func NewCategoryHandler(logger *zap.Logger, repo CategoryRepository) *CategoryHandler {
+	return &CategoryHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type categoryRequest struct {
+	QuietHours  *db.QuietHours `json:"quiet_hours"`
+	Description string         `json:"description"`
+	Mandatory   bool           `json:"mandatory"`
+}
+
+type categoryStatsResponse struct {
+	From string              `json:"from"`
+	To   string              `json:"to"`
+	Data []*db.CategoryStats `json:"data"`
+}
+
+// ListCategories handles GET /v1/tenants/{tenant_id}/categories
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	categories, err := h.repo.ListTenantNotificationCategories(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to list notification categories", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list categories", "")
+		return
+	}
+	if categories == nil {
+		categories = []*db.NotificationCategory{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": categories})
+}
+
+// PutCategory handles PUT /v1/tenants/{tenant_id}/categories/{category}
+// {"description": "Product news", "quiet_hours": {"start": "21:00", "end": "08:00"}}
+//
+// Making a category mandatory overrides users' existing opt-outs of it.
+func (h *CategoryHandler) PutCategory(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	name, ok := categoryParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req categoryRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCategoryBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+	if detail := validateCategoryRequest(&req); detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid category", detail)
+		return
+	}
+
+	category := &db.NotificationCategory{
+		TenantID:    tenantID,
+		Name:        name,
+		Description: req.Description,
+		QuietHours:  req.QuietHours,
+		Mandatory:   req.Mandatory,
+	}
+	if err := h.repo.UpsertNotificationCategory(r.Context(), category); err != nil {
+		h.logger.Error("failed to save notification category", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save category", "")
+		return
+	}
+
+	h.logger.Info("notification category updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("category", name),
+		zap.Bool("mandatory", req.Mandatory),
+	)
+	writeJSON(w, http.StatusOK, category)
+}
+
+// DeleteCategory handles DELETE /v1/tenants/{tenant_id}/categories/{category}.
+// Users' opt-outs of it go with it; notifications already created keep it.
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	name, ok := categoryParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.DeleteNotificationCategory(r.Context(), tenantID, name)
+	if errors.Is(err, db.ErrNoNotificationCategory) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No such category", "the tenant has no category "+name)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to delete notification category", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to delete category", "")
+		return
+	}
+
+	h.logger.Info("notification category removed",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("category", name),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStats handles GET /v1/tenants/{tenant_id}/usage/categories?from=YYYY-MM-DD&to=YYYY-MM-DD:
+// the tenant's notifications created in the range, counted by category,
+// channel and status. Bounds are inclusive UTC days, as for usage.
+func (h *CategoryHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := usageRange(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.repo.ListCategoryStats(r.Context(), tenantID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		h.logger.Error("failed to list category stats", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get category stats", "")
+		return
+	}
+	if stats == nil {
+		stats = []*db.CategoryStats{}
+	}
+
+	writeJSON(w, http.StatusOK, categoryStatsResponse{
+		From: from.Format(usageDateLayout),
+		To:   to.Format(usageDateLayout),
+		Data: stats,
+	})
+}
+
+// categoryParam reads and validates the {category} path segment.
+func categoryParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := chi.URLParam(r, "category")
+	if name == "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid category", "category is required")
+		return "", false
+	}
+	if err := validateCategory(name); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid category", err.Error())
+		return "", false
+	}
+	return name, true
+}
+
+func validateCategoryRequest(req *categoryRequest) string {
+	if len(req.Description) > maxCategoryDescription {
+		return "description must be at most 500 characters"
+	}
+	if q := req.QuietHours; q != nil {
+		if req.Mandatory {
+			return "a mandatory category can't have quiet_hours: its notifications always go out at once"
+		}
+		if _, err := time.Parse(quietHoursLayout, q.Start); err != nil {
+			return `quiet_hours.start must be a time in "HH:MM" format`
+		}
+		if _, err := time.Parse(quietHoursLayout, q.End); err != nil {
+			return `quiet_hours.end must be a time in "HH:MM" format`
+		}
+		if q.Start == q.End {
+			return "quiet_hours.start and quiet_hours.end must differ"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockCategoryRepo struct {
+	categories map[string]*db.NotificationCategory
+	optOuts    []*db.CategoryOptOut
+	statsFrom  time.Time
+	statsTo    time.Time
+}
+
+func newMockCategoryRepo() *mockCategoryRepo {
+	return &mockCategoryRepo{categories: map[string]*db.NotificationCategory{}}
+}
+
+func (m *mockCategoryRepo) ListTenantNotificationCategories(ctx context.Context, tenantID uuid.UUID) ([]*db.NotificationCategory, error) {
+	var out []*db.NotificationCategory
+	for _, c := range m.categories {
+		if c.TenantID == tenantID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockCategoryRepo) GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*db.NotificationCategory, error) {
+	c, ok := m.categories[tenantID.String()+name]
+	if !ok {
+		return nil, db.ErrNoNotificationCategory
+	}
+	return c, nil
+}
+
+func (m *mockCategoryRepo) UpsertNotificationCategory(ctx context.Context, c *db.NotificationCategory) error {
+	m.categories[c.TenantID.String()+c.Name] = c
+	return nil
+}
+
+func (m *mockCategoryRepo) DeleteNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) error {
+	if _, ok := m.categories[tenantID.String()+name]; !ok {
+		return db.ErrNoNotificationCategory
+	}
+	delete(m.categories, tenantID.String()+name)
+	return nil
+}
+
+func (m *mockCategoryRepo) ListCategoryStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*db.CategoryStats, error) {
+	m.statsFrom, m.statsTo = from, to
+	return []*db.CategoryStats{{Category: "billing", Channel: "email", Status: db.StatusSent, Count: 7}}, nil
+}
+
+func (m *mockCategoryRepo) ListCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID) ([]*db.CategoryOptOut, error) {
+	return m.optOuts, nil
+}
+
+func (m *mockCategoryRepo) ReplaceCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID, optOuts []*db.CategoryOptOut) error {
+	m.optOuts = optOuts
+	return nil
+}
+
+func categoryHTTPRequest(method, tenantID, category, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/categories/"+category, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	rctx.URLParams.Add("category", category)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPutCategory(t *testing.T) {
+	handler := NewCategoryHandler(zap.NewNop(), newMockCategoryRepo())
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		category       string
+		body           string
+		expectedStatus int
+	}{
+		{"malformed name", "bad!name", `{}`, http.StatusBadRequest},
+		{"malformed quiet hours", "marketing", `{"quiet_hours": {"start": "9pm", "end": "08:00"}}`, http.StatusBadRequest},
+		{"empty quiet window", "marketing", `{"quiet_hours": {"start": "08:00", "end": "08:00"}}`, http.StatusBadRequest},
+		{"description too long", "marketing", `{"description": "` + strings.Repeat("x", 501) + `"}`, http.StatusBadRequest},
+		{"unknown field", "marketing", `{"priority": "high"}`, http.StatusBadRequest},
+		{"mandatory with quiet hours", "security", `{"mandatory": true, "quiet_hours": {"start": "21:00", "end": "08:00"}}`, http.StatusBadRequest},
+		{"valid mandatory", "security", `{"description": "Sign-ins and password changes", "mandatory": true}`, http.StatusOK},
+		{"valid without quiet hours", "billing", `{"description": "Invoices and receipts"}`, http.StatusOK},
+		{"valid", "marketing", `{"description": "Product news", "quiet_hours": {"start": "21:00", "end": "08:00"}}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.PutCategory(rec, categoryHTTPRequest(http.MethodPut, tenantID, tt.category, tt.body))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ListCategories(rec, categoryHTTPRequest(http.MethodGet, tenantID, "", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"quiet_hours":{"start":"21:00","end":"08:00"}`) || !strings.Contains(rec.Body.String(), `"mandatory":true`) {
+		t.Errorf("expected the saved categories, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeleteCategory(rec, categoryHTTPRequest(http.MethodDelete, tenantID, "marketing", ""))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeleteCategory(rec, categoryHTTPRequest(http.MethodDelete, tenantID, "marketing", ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestGetCategoryStats(t *testing.T) {
+	repo := newMockCategoryRepo()
+	handler := NewCategoryHandler(zap.NewNop(), repo)
+
+	req := categoryHTTPRequest(http.MethodGet, uuid.New().String(), "", "")
+	req.URL.RawQuery = "from=2026-03-01&to=2026-03-31"
+	rec := httptest.NewRecorder()
+	handler.GetStats(rec, req)
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"category":"billing"`) {
+		t.Fatalf("expected the stats, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// The to day is inclusive.
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC); !repo.statsTo.Equal(want) {
+		t.Errorf("expected stats up to %v, got %v", want, repo.statsTo)
+	}
+}
+
+func TestCreateNotification_Category(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	categories := newMockCategoryRepo()
+	categories.categories[tenantID.String()+"billing"] = &db.NotificationCategory{TenantID: tenantID, Name: "billing"}
+
+	tests := []struct {
+		name           string
+		category       string
+		expectedStatus int
+	}{
+		{"none", "", http.StatusCreated},
+		{"defined", "billing", http.StatusCreated},
+		{"not defined", "promo", http.StatusBadRequest},
+		{"malformed", "bad category", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			handler.SetCategories(categories)
+
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: tenantID.String(),
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "email",
+				Category: tt.category,
+				Payload:  json.RawMessage(`{"to":"user@example.com"}`),
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				if mockRepo.createCalled {
+					t.Error("expected no notification to be created")
+				}
+				return
+			}
+
+			var resp NotificationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if stored := mockRepo.notifications[resp.ID]; stored == nil || stored.Category != tt.category {
+				t.Errorf("expected category %q to be stored, got %+v", tt.category, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// ChannelKillSwitchRepository reads and writes channel kill switches.
+type ChannelKillSwitchRepository interface {
+	KillChannel(ctx context.Context, k *db.ChannelKillSwitch) error
+	ReviveChannel(ctx context.Context, channel string) error
+	ListChannelKillSwitches(ctx context.Context) ([]*db.ChannelKillSwitch, error)
+}
+
+// ChannelKillSwitchHandler serves the admin endpoints that disable a channel
+// platform-wide, e.g. SMS during an SNS billing incident. Creates on a killed
+// channel still succeed; the worker parks them as 'held' until revived.
+type ChannelKillSwitchHandler struct {
+	repo   ChannelKillSwitchRepository
+	logger *zap.Logger
+}
+
+// NewChannelKillSwitchHandler creates the admin handler for channel kill
+// switches.
+func NewChannelKillSwitchHandler(logger *zap.Logger, repo ChannelKillSwitchRepository) *ChannelKillSwitchHandler {
+	return &ChannelKillSwitchHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListKilled handles GET /v1/admin/channels/killed
+func (h *ChannelKillSwitchHandler) ListKilled(w http.ResponseWriter, r *http.Request) {
+	switches, err := h.repo.ListChannelKillSwitches(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list channel kill switches", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list killed channels", "")
+		return
+	}
+	if switches == nil {
+		switches = []*db.ChannelKillSwitch{}
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"channels": switches,
+	})
+}
+
+// Kill handles PUT /v1/admin/channels/{channel}/kill {"reason": "..."}
+func (h *ChannelKillSwitchHandler) Kill(w http.ResponseWriter, r *http.Request) {
+	channel, ok := channelParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+			return
+		}
+	}
+	if len(req.Reason) > maxPauseReasonLength {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid reason", "reason must be at most 500 characters")
+		return
+	}
+
+	k := &db.ChannelKillSwitch{Channel: channel, Reason: req.Reason}
+	if err := h.repo.KillChannel(r.Context(), k); err != nil {
+		h.logger.Error("failed to kill channel", zap.Error(err), zap.String(logFieldChannel, channel))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to kill channel", "")
+		return
+	}
+
+	h.logger.Warn("channel killed",
+		zap.String(logFieldChannel, channel),
+		zap.String("reason", req.Reason),
+	)
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(k)
+}
+
+// Revive handles DELETE /v1/admin/channels/{channel}/kill
+func (h *ChannelKillSwitchHandler) Revive(w http.ResponseWriter, r *http.Request) {
+	channel, ok := channelParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.ReviveChannel(r.Context(), channel)
+	if errors.Is(err, db.ErrChannelNotKilled) {
+		writeProblem(w, http.StatusNotFound, "not_found", "Channel not killed", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to revive channel", zap.Error(err), zap.String(logFieldChannel, channel))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to revive channel", "")
+		return
+	}
+
+	h.logger.Info("channel revived", zap.String(logFieldChannel, channel))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func channelParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	channel := chi.URLParam(r, "channel")
+	if !isValidChannel(channel) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidChannel, errDetailInvalidChannel)
+		return "", false
+	}
+	return channel, true
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockKillSwitchRepo struct {
+	switches map[string]*db.ChannelKillSwitch
+	err      error
+}
+
+func (m *mockKillSwitchRepo) KillChannel(ctx context.Context, k *db.ChannelKillSwitch) error {
+	if m.err != nil {
+		return m.err
+	}
+	k.KilledAt = time.Now()
+	m.switches[k.Channel] = k
+	return nil
+}
+
+func (m *mockKillSwitchRepo) ReviveChannel(ctx context.Context, channel string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.switches[channel]; !ok {
+		return db.ErrChannelNotKilled
+	}
+	delete(m.switches, channel)
+	return nil
+}
+
+func (m *mockKillSwitchRepo) ListChannelKillSwitches(ctx context.Context) ([]*db.ChannelKillSwitch, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var out []*db.ChannelKillSwitch
+	for _, k := range m.switches {
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func killSwitchRequest(method, channel, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/admin/channels/"+channel+"/kill", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("channel", channel)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestChannelKillSwitch(t *testing.T) {
+	repo := &mockKillSwitchRepo{switches: map[string]*db.ChannelKillSwitch{}}
+	handler := NewChannelKillSwitchHandler(zap.NewNop(), repo)
+
+	rec := httptest.NewRecorder()
+	handler.Kill(rec, killSwitchRequest(http.MethodPut, "sms", `{"reason":"SNS billing incident"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from kill, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if k := repo.switches["sms"]; k == nil || k.Reason != "SNS billing incident" {
+		t.Fatalf("expected sms killed with reason, got %+v", k)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ListKilled(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/channels/killed", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"channel":"sms"`) {
+		t.Errorf("expected sms listed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.Revive(rec, killSwitchRequest(http.MethodDelete, "sms", ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 from revive, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.Revive(rec, killSwitchRequest(http.MethodDelete, "sms", ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 reviving a live channel, got %d", rec.Code)
+	}
+}
+
+func TestChannelKillSwitch_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		channel        string
+		body           string
+		repoErr        error
+		expectedStatus int
+	}{
+		{"unknown channel", "pigeon", `{}`, nil, http.StatusBadRequest},
+		{"malformed body", "email", `{`, nil, http.StatusBadRequest},
+		{"repository error", "email", `{}`, errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockKillSwitchRepo{switches: map[string]*db.ChannelKillSwitch{}, err: tt.repoErr}
+			rec := httptest.NewRecorder()
+			NewChannelKillSwitchHandler(zap.NewNop(), repo).Kill(rec, killSwitchRequest(http.MethodPut, tt.channel, tt.body))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/phone"
+	"github.com/lalithlochan/nimbus/internal/secretbox"
+	"github.com/lalithlochan/nimbus/internal/sesidentity"
+)
+
+const (
+	maxSettingsBytes   = 16 << 10
+	maxSenderIDLength  = 11
+	maxE164Digits      = 15
+	minShortCodeDigits = 5
+	maxShortCodeDigits = 6
+	maxAllowedDomains  = 50
+	maxHostnameLength  = 253
+	maxWebhookHeaders  = 20
+	maxHeaderValueLen  = 4096
+	maxUserAgentLength = 256
+	maxConfigSetLength = 64
+
+	// maskedHeaderValue stands in for stored header values in responses. A
+	// PUT that sends it back keeps the stored value.
+	maskedHeaderValue = "***"
+)
+
+// reservedWebhookHeaders are set by the sender or the transport and can't be
+// overridden per tenant. User-Agent has its own user_agent setting.
+var reservedWebhookHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Content-Type":      true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"User-Agent":        true,
+}
+
+// ChannelSettingsRepository reads and writes per-tenant channel settings.
+type ChannelSettingsRepository interface {
+	GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error)
+	UpsertChannelSettings(ctx context.Context, settings *db.TenantChannelSettings) error
+}
+
+// EmailIdentityChecker confirms that SES can send with a tenant's email
+// settings. Errors wrapping sesidentity.ErrNotReady describe what the
+// tenant still has to set up; other errors mean SES couldn't be asked.
+type EmailIdentityChecker interface {
+	Check(ctx context.Context, s *db.EmailSettings) error
+}
+
+// ChannelSettingsHandler exposes a tenant's per-channel delivery settings,
+// such as the SMS sender ID, which the worker applies at send time.
+type ChannelSettingsHandler struct {
+	repo       ChannelSettingsRepository
+	box        *secretbox.Box
+	identities EmailIdentityChecker
+	logger     *zap.Logger
+}
+
+// NewChannelSettingsHandler creates a handler for tenant channel settings.
+func NewChannelSettingsHandler(logger *zap.Logger, repo ChannelSettingsRepository) *ChannelSettingsHandler {
+	return &ChannelSettingsHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// SetSecretBox enables webhook header sets, whose values are sealed with box
+// before they are stored. Without it, settings with headers are rejected.
+func (h *ChannelSettingsHandler) SetSecretBox(box *secretbox.Box) {
+	h.box = box
+}
+
+// SetIdentityChecker checks email settings against SES before they are
+// saved, so an unverified domain is rejected at setup rather than failing
+// every send. Without it, email settings are only checked for shape.
+func (h *ChannelSettingsHandler) SetIdentityChecker(c EmailIdentityChecker) {
+	h.identities = c
+}
+
+// GetSettings handles GET /v1/tenants/{tenant_id}/channels/{channel}/settings
+func (h *ChannelSettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, channel, ok := parseSettingsPath(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.repo.GetChannelSettings(r.Context(), tenantID, channel)
+	if err != nil {
+		h.logger.Error("failed to get channel settings",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+			zap.String(logFieldChannel, channel),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get channel settings", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, maskChannelSettings(settings))
+}
+
+// PutSettings handles PUT /v1/tenants/{tenant_id}/channels/{channel}/settings.
+// The body replaces the channel's settings wholesale.
+func (h *ChannelSettingsHandler) PutSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, channel, ok := parseSettingsPath(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSettingsBytes))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	normalized, err := validateChannelSettings(channel, body)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid settings", err.Error())
+		return
+	}
+
+	if channel == channelWebhook {
+		normalized, err = h.sealWebhookHeaders(r.Context(), tenantID, normalized)
+		if errors.Is(err, errSettingsUnavailable) {
+			writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save channel settings", "")
+			return
+		}
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid settings", err.Error())
+			return
+		}
+	}
+
+	if channel == channelEmail && h.identities != nil {
+		var s db.EmailSettings
+		_ = json.Unmarshal(normalized, &s)
+		err := h.identities.Check(r.Context(), &s)
+		if errors.Is(err, sesidentity.ErrNotReady) {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid settings", err.Error())
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to check email identity",
+				zap.Error(err),
+				zap.String(logFieldTenantID, tenantID.String()),
+			)
+			writeProblem(w, http.StatusBadGateway, errTypeInternalError, "Failed to check email identity with SES", "")
+			return
+		}
+	}
+
+	settings := &db.TenantChannelSettings{
+		TenantID: tenantID,
+		Channel:  channel,
+		Settings: normalized,
+	}
+	if err := h.repo.UpsertChannelSettings(r.Context(), settings); err != nil {
+		h.logger.Error("failed to save channel settings",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+			zap.String(logFieldChannel, channel),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save channel settings", "")
+		return
+	}
+
+	h.logger.Info("channel settings updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String(logFieldChannel, channel),
+	)
+
+	writeJSON(w, http.StatusOK, maskChannelSettings(settings))
+}
+
+// errSettingsUnavailable means the stored settings a PUT builds on couldn't
+// be read.
+var errSettingsUnavailable = errors.New("channel settings unavailable")
+
+// sealWebhookHeaders seals the header values in a validated webhook settings
+// document. A value of "***" keeps the value already stored for that header,
+// so clients can round-trip a GET response without knowing the secrets.
+func (h *ChannelSettingsHandler) sealWebhookHeaders(ctx context.Context, tenantID uuid.UUID, normalized json.RawMessage) (json.RawMessage, error) {
+	var s db.WebhookSettings
+	if err := json.Unmarshal(normalized, &s); err != nil {
+		return nil, err
+	}
+	if len(s.Headers) == 0 {
+		return normalized, nil
+	}
+	if h.box == nil {
+		return nil, errors.New("custom webhook headers are not enabled on this server")
+	}
+
+	var stored *db.WebhookSettings
+	for name, value := range s.Headers {
+		if value != maskedHeaderValue {
+			sealed, err := h.box.Seal(value)
+			if err != nil {
+				return nil, fmt.Errorf("seal header %q: %w", name, err)
+			}
+			s.Headers[name] = sealed
+			continue
+		}
+		if stored == nil {
+			record, err := h.repo.GetChannelSettings(ctx, tenantID, channelWebhook)
+			if err != nil {
+				h.logger.Error("failed to load webhook settings",
+					zap.Error(err),
+					zap.String(logFieldTenantID, tenantID.String()),
+				)
+				return nil, errSettingsUnavailable
+			}
+			stored = &db.WebhookSettings{}
+			_ = json.Unmarshal(record.Settings, stored)
+		}
+		prev, ok := stored.Headers[name]
+		if !ok {
+			return nil, fmt.Errorf("header %q has no stored value to keep", name)
+		}
+		s.Headers[name] = prev
+	}
+	return json.Marshal(s)
+}
+
+// maskChannelSettings hides stored webhook header values from responses.
+func maskChannelSettings(settings *db.TenantChannelSettings) *db.TenantChannelSettings {
+	if settings.Channel != channelWebhook {
+		return settings
+	}
+	var s db.WebhookSettings
+	if err := json.Unmarshal(settings.Settings, &s); err != nil || len(s.Headers) == 0 {
+		return settings
+	}
+	for name := range s.Headers {
+		s.Headers[name] = maskedHeaderValue
+	}
+	masked := *settings
+	masked.Settings, _ = json.Marshal(s)
+	return &masked
+}
+
+// validateChannelSettings checks a settings document against its channel's
+// shape and returns it re-encoded in canonical form.
+func validateChannelSettings(channel string, body []byte) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	switch channel {
+	case channelSMS:
+		var s db.SMSSettings
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+		if err := validateSMSSettings(&s); err != nil {
+			return nil, err
+		}
+		return json.Marshal(s)
+	case channelWebhook:
+		var s db.WebhookSettings
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+		if err := validateWebhookSettings(&s); err != nil {
+			return nil, err
+		}
+		return json.Marshal(s)
+	case channelEmail:
+		var s db.EmailSettings
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+		if err := validateEmailSettings(&s); err != nil {
+			return nil, err
+		}
+		return json.Marshal(s)
+	default:
+		var s struct{}
+		if err := dec.Decode(&s); err != nil {
+			return nil, fmt.Errorf("the %s channel has no configurable settings", channel)
+		}
+		return json.RawMessage(`{}`), nil
+	}
+}
+
+func validateSMSSettings(s *db.SMSSettings) error {
+	if s.SenderID != "" && !isValidSenderID(s.SenderID) {
+		return errors.New("sender_id must be 1-11 letters and digits, with at least one letter")
+	}
+	if s.OriginationNumber != "" && !isValidOriginationNumber(s.OriginationNumber) {
+		return errors.New("origination_number must be an E.164 number (+14155552671) or a 5-6 digit short code")
+	}
+	s.DefaultCountry = strings.ToUpper(s.DefaultCountry)
+	if s.DefaultCountry != "" && !phone.ValidCountry(s.DefaultCountry) {
+		return errors.New("default_country must be an ISO 3166-1 alpha-2 country code, e.g. US or GB")
+	}
+	s.SMSType = strings.ToLower(s.SMSType)
+	switch s.SMSType {
+	case "", db.SMSTypeTransactional, db.SMSTypePromotional:
+	default:
+		return errors.New("sms_type must be " + db.SMSTypeTransactional + " or " + db.SMSTypePromotional)
+	}
+	return nil
+}
+
+// validateEmailSettings lower-cases the domains in s. Whether SES will send
+// with them is the identity checker's job.
+func validateEmailSettings(s *db.EmailSettings) error {
+	if s.FromAddress != "" {
+		addr, err := mail.ParseAddress(s.FromAddress)
+		if err != nil || addr.Name != "" || addr.Address != s.FromAddress {
+			return errors.New("from_address must be a bare email address, e.g. notify@acme.com")
+		}
+		local, domain, _ := strings.Cut(addr.Address, "@")
+		domain = strings.ToLower(domain)
+		if !isValidHostname(domain) {
+			return fmt.Errorf("from_address domain %q must be a hostname", domain)
+		}
+		s.FromAddress = local + "@" + domain
+	}
+	s.MailFromDomain = strings.ToLower(s.MailFromDomain)
+	if s.MailFromDomain != "" {
+		if s.FromAddress == "" {
+			return errors.New("mail_from_domain needs a from_address on the same domain")
+		}
+		_, domain, _ := strings.Cut(s.FromAddress, "@")
+		if !isValidHostname(s.MailFromDomain) || !strings.HasSuffix(s.MailFromDomain, "."+domain) {
+			return fmt.Errorf("mail_from_domain must be a subdomain of %s, e.g. bounce.%s", domain, domain)
+		}
+	}
+	if s.ConfigurationSet != "" && !isValidConfigSetName(s.ConfigurationSet) {
+		return fmt.Errorf("configuration_set must be 1-%d letters, digits, hyphens and underscores", maxConfigSetLength)
+	}
+	return nil
+}
+
+// validateWebhookSettings lower-cases and de-duplicates allowed_domains, so
+// matching at create time is a plain comparison.
+func validateWebhookSettings(s *db.WebhookSettings) error {
+	if len(s.AllowedDomains) > maxAllowedDomains {
+		return fmt.Errorf("at most %d allowed_domains are allowed", maxAllowedDomains)
+	}
+	seen := make(map[string]bool, len(s.AllowedDomains))
+	domains := make([]string, 0, len(s.AllowedDomains))
+	for _, d := range s.AllowedDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if !isValidHostname(strings.TrimPrefix(d, "*.")) {
+			return fmt.Errorf("allowed_domains entry %q must be a hostname, optionally prefixed with *.", d)
+		}
+		if !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+	s.AllowedDomains = domains
+
+	if len(s.UserAgent) > maxUserAgentLength || strings.ContainsAny(s.UserAgent, "\r\n") {
+		return fmt.Errorf("user_agent must be at most %d characters on one line", maxUserAgentLength)
+	}
+	if len(s.Headers) > maxWebhookHeaders {
+		return fmt.Errorf("at most %d headers are allowed", maxWebhookHeaders)
+	}
+	headers := make(map[string]string, len(s.Headers))
+	for name, value := range s.Headers {
+		if !isValidHeaderName(name) {
+			return fmt.Errorf("header name %q is not a valid HTTP header name", name)
+		}
+		name = http.CanonicalHeaderKey(name)
+		if reservedWebhookHeaders[name] || strings.HasPrefix(name, "X-Nimbus-") {
+			return fmt.Errorf("header %q is set by Nimbus and can't be overridden", name)
+		}
+		if value == "" || len(value) > maxHeaderValueLen || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %q must have a value of at most %d characters on one line", name, maxHeaderValueLen)
+		}
+		if _, dup := headers[name]; dup {
+			return fmt.Errorf("header %q is set more than once", name)
+		}
+		headers[name] = value
+	}
+	s.Headers = headers
+	if len(headers) == 0 {
+		s.Headers = nil
+	}
+	return nil
+}
+
+// isValidHeaderName accepts RFC 9110 tokens.
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isValidHostname accepts dotted DNS names of letters, digits and hyphens.
+// Labels may not start or end with a hyphen.
+func isValidHostname(host string) bool {
+	if host == "" || len(host) > maxHostnameLength || !strings.Contains(host, ".") {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isValidConfigSetName applies the SES naming rules for configuration sets.
+func isValidConfigSetName(name string) bool {
+	if len(name) > maxConfigSetLength {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidSenderID applies the carrier rules for alphanumeric sender IDs: at
+// most 11 characters, and not all digits (that would look like a number).
+func isValidSenderID(id string) bool {
+	if len(id) == 0 || len(id) > maxSenderIDLength {
+		return false
+	}
+	hasLetter := false
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			hasLetter = true
+		case c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+func isValidOriginationNumber(n string) bool {
+	digits, e164 := strings.CutPrefix(n, "+")
+	if !allDigits(digits) {
+		return false
+	}
+	if e164 {
+		return len(digits) >= 2 && len(digits) <= maxE164Digits && digits[0] != '0'
+	}
+	return len(digits) >= minShortCodeDigits && len(digits) <= maxShortCodeDigits
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseSettingsPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, string, bool) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "tenant_id"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTenant, errDetailInvalidTenant)
+		return uuid.Nil, "", false
+	}
+
+	channel := chi.URLParam(r, "channel")
+	if !isValidChannel(channel) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidChannel, errDetailInvalidChannel)
+		return uuid.Nil, "", false
+	}
+
+	return tenantID, channel, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
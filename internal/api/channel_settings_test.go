@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/secretbox"
+	"github.com/lalithlochan/nimbus/internal/sesidentity"
+)
+
+type mockSettingsRepo struct {
+	saved map[string]*db.TenantChannelSettings
+}
+
+func (m *mockSettingsRepo) GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error) {
+	if s, ok := m.saved[tenantID.String()+"/"+channel]; ok {
+		return s, nil
+	}
+	return &db.TenantChannelSettings{TenantID: tenantID, Channel: channel, Settings: json.RawMessage(`{}`)}, nil
+}
+
+func (m *mockSettingsRepo) UpsertChannelSettings(ctx context.Context, s *db.TenantChannelSettings) error {
+	m.saved[s.TenantID.String()+"/"+s.Channel] = s
+	return nil
+}
+
+func TestPutChannelSettings(t *testing.T) {
+	tests := []struct {
+		name           string
+		channel        string
+		body           string
+		expectedStatus int
+		expected       string
+	}{
+		{"valid sms settings", "sms", `{"sender_id":"ACME","origination_number":"+15550001111","sms_type":"Transactional"}`, http.StatusOK,
+			`{"sender_id":"ACME","origination_number":"+15550001111","sms_type":"transactional"}`},
+		{"short code", "sms", `{"origination_number":"12345"}`, http.StatusOK, `{"origination_number":"12345"}`},
+		{"sender id too long", "sms", `{"sender_id":"ACMECORPORATION"}`, http.StatusBadRequest, ""},
+		{"numeric sender id", "sms", `{"sender_id":"12345"}`, http.StatusBadRequest, ""},
+		{"bad origination number", "sms", `{"origination_number":"555-1234"}`, http.StatusBadRequest, ""},
+		{"bad sms type", "sms", `{"sms_type":"urgent"}`, http.StatusBadRequest, ""},
+		{"default country", "sms", `{"default_country":"gb"}`, http.StatusOK, `{"default_country":"GB"}`},
+		{"unknown default country", "sms", `{"default_country":"ZZ"}`, http.StatusBadRequest, ""},
+		{"unknown field", "sms", `{"from":"ACME"}`, http.StatusBadRequest, ""},
+		{"webhook allowlist", "webhook", `{"allowed_domains":["Hooks.Acme.com","*.example.org","hooks.acme.com"]}`, http.StatusOK,
+			`{"allowed_domains":["hooks.acme.com","*.example.org"]}`},
+		{"webhook allowlist with url", "webhook", `{"allowed_domains":["https://hooks.acme.com"]}`, http.StatusBadRequest, ""},
+		{"webhook allowlist bare label", "webhook", `{"allowed_domains":["localhost"]}`, http.StatusBadRequest, ""},
+		{"webhook user agent", "webhook", `{"user_agent":"AcmeBot/2.0"}`, http.StatusOK, `{"user_agent":"AcmeBot/2.0"}`},
+		{"webhook headers without a key", "webhook", `{"headers":{"Authorization":"Bearer x"}}`, http.StatusBadRequest, ""},
+		{"webhook reserved header", "webhook", `{"headers":{"x-nimbus-tenant-id":"x"}}`, http.StatusBadRequest, ""},
+		{"webhook bad header name", "webhook", `{"headers":{"X Auth":"x"}}`, http.StatusBadRequest, ""},
+		{"empty settings for email", "email", `{}`, http.StatusOK, `{}`},
+		{"email unknown field", "email", `{"sender_id":"ACME"}`, http.StatusBadRequest, ""},
+		{"email identity", "email", `{"from_address":"Notify@Acme.COM","mail_from_domain":"Bounce.acme.com","configuration_set":"acme-prod"}`, http.StatusOK,
+			`{"from_address":"Notify@acme.com","mail_from_domain":"bounce.acme.com","configuration_set":"acme-prod"}`},
+		{"email display name", "email", `{"from_address":"Acme <notify@acme.com>"}`, http.StatusBadRequest, ""},
+		{"mail from outside the domain", "email", `{"from_address":"notify@acme.com","mail_from_domain":"bounce.other.com"}`, http.StatusBadRequest, ""},
+		{"mail from without an address", "email", `{"mail_from_domain":"bounce.acme.com"}`, http.StatusBadRequest, ""},
+		{"bad configuration set", "email", `{"configuration_set":"acme prod"}`, http.StatusBadRequest, ""},
+		{"unknown channel", "fax", `{}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSettingsRepo{saved: map[string]*db.TenantChannelSettings{}}
+			h := NewChannelSettingsHandler(zap.NewNop(), repo)
+			r := chi.NewRouter()
+			r.Put("/v1/tenants/{tenant_id}/channels/{channel}/settings", h.PutSettings)
+
+			tenantID := uuid.New()
+			req := httptest.NewRequest(http.MethodPut,
+				"/v1/tenants/"+tenantID.String()+"/channels/"+tt.channel+"/settings",
+				bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if len(repo.saved) != 0 {
+					t.Error("expected nothing to be saved")
+				}
+				return
+			}
+			saved := repo.saved[tenantID.String()+"/"+tt.channel]
+			if saved == nil {
+				t.Fatal("expected settings to be saved")
+			}
+			if string(saved.Settings) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, saved.Settings)
+			}
+		})
+	}
+}
+
+func TestGetChannelSettings_Unconfigured(t *testing.T) {
+	h := NewChannelSettingsHandler(zap.NewNop(), &mockSettingsRepo{saved: map[string]*db.TenantChannelSettings{}})
+	r := chi.NewRouter()
+	r.Get("/v1/tenants/{tenant_id}/channels/{channel}/settings", h.GetSettings)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/"+uuid.New().String()+"/channels/sms/settings", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got db.TenantChannelSettings
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if string(got.Settings) != `{}` {
+		t.Errorf("expected empty settings, got %s", got.Settings)
+	}
+}
+
+func TestPutChannelSettings_WebhookHeaders(t *testing.T) {
+	box, err := secretbox.New("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatalf("secretbox: %v", err)
+	}
+	repo := &mockSettingsRepo{saved: map[string]*db.TenantChannelSettings{}}
+	h := NewChannelSettingsHandler(zap.NewNop(), repo)
+	h.SetSecretBox(box)
+	r := chi.NewRouter()
+	r.Get("/v1/tenants/{tenant_id}/channels/{channel}/settings", h.GetSettings)
+	r.Put("/v1/tenants/{tenant_id}/channels/{channel}/settings", h.PutSettings)
+
+	tenantID := uuid.New()
+	path := "/v1/tenants/" + tenantID.String() + "/channels/webhook/settings"
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, bytes.NewBufferString(body)))
+		return rec
+	}
+	storedHeaders := func() map[string]string {
+		var s db.WebhookSettings
+		_ = json.Unmarshal(repo.saved[tenantID.String()+"/webhook"].Settings, &s)
+		return s.Headers
+	}
+
+	rec := put(`{"headers":{"authorization":"Bearer s3cret","X-Team":"payments"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte("s3cret")) {
+		t.Errorf("expected header values to be masked, got %s", rec.Body.String())
+	}
+	stored := storedHeaders()
+	auth := stored["Authorization"]
+	if plain, err := box.Open(auth); err != nil || plain != "Bearer s3cret" {
+		t.Fatalf("expected a sealed Authorization header, got %q (%q, %v)", auth, plain, err)
+	}
+
+	// Round-tripping the masked value keeps the stored secret.
+	rec = put(`{"headers":{"Authorization":"***","X-Team":"billing"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored = storedHeaders()
+	if stored["Authorization"] != auth {
+		t.Error("expected the masked header to keep its stored value")
+	}
+	if plain, _ := box.Open(stored["X-Team"]); plain != "billing" {
+		t.Errorf("expected X-Team to be replaced, got %q", plain)
+	}
+
+	if rec := put(`{"headers":{"X-Other":"***"}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a masked header with no stored value, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var got db.TenantChannelSettings
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if string(got.Settings) != `{"headers":{"Authorization":"***","X-Team":"***"}}` {
+		t.Errorf("expected masked headers, got %s", got.Settings)
+	}
+}
+
+type stubIdentityChecker struct {
+	err     error
+	checked *db.EmailSettings
+}
+
+func (c *stubIdentityChecker) Check(ctx context.Context, s *db.EmailSettings) error {
+	c.checked = s
+	return c.err
+}
+
+func TestPutChannelSettings_EmailIdentityCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"ready", nil, http.StatusOK},
+		{"not ready", fmt.Errorf("%w: DKIM for acme.com is Pending", sesidentity.ErrNotReady), http.StatusBadRequest},
+		{"ses unavailable", errors.New("throttled"), http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSettingsRepo{saved: map[string]*db.TenantChannelSettings{}}
+			checker := &stubIdentityChecker{err: tt.err}
+			h := NewChannelSettingsHandler(zap.NewNop(), repo)
+			h.SetIdentityChecker(checker)
+			r := chi.NewRouter()
+			r.Put("/v1/tenants/{tenant_id}/channels/{channel}/settings", h.PutSettings)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut,
+				"/v1/tenants/"+uuid.New().String()+"/channels/email/settings",
+				bytes.NewBufferString(`{"from_address":"notify@acme.com","mail_from_domain":"bounce.acme.com"}`)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if checker.checked == nil || checker.checked.MailFromDomain != "bounce.acme.com" {
+				t.Errorf("expected the settings to be checked, got %+v", checker.checked)
+			}
+			if wantSaved := tt.expectedStatus == http.StatusOK; (len(repo.saved) != 0) != wantSaved {
+				t.Errorf("expected saved to be %v", wantSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies are the load balancers and reverse proxies in front of the
+// gateway, whose X-Forwarded-For the gateway believes. The zero value trusts
+// nothing, so the client address is always the connection's peer.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// NewTrustedProxies parses cidrs (CIDRs or bare addresses).
+func NewTrustedProxies(cidrs []string) (*TrustedProxies, error) {
+	prefixes, err := parseAllowlist(cidrs)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxy: %w", err)
+	}
+	return &TrustedProxies{prefixes: prefixes}, nil
+}
+
+// Middleware sets r.RemoteAddr to the client address, which clientIP and
+// handler logs then use. It replaces chi's middleware.RealIP, which believes
+// X-Forwarded-For and X-Real-IP from anyone.
+func (p *TrustedProxies) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := p.clientIP(r); ok {
+			r.RemoteAddr = ip.String()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP is the connection's peer unless that is a trusted proxy. Then it
+// is the rightmost X-Forwarded-For hop that isn't a trusted proxy: every hop
+// to its right was added by our own proxies, while anything to its left came
+// from the client and may be forged. If every hop is a trusted proxy the
+// leftmost one is used. ok is false when the peer or the hop found can't be
+// parsed, in which case r.RemoteAddr is left alone.
+func (p *TrustedProxies) clientIP(r *http.Request) (netip.Addr, bool) {
+	peer, ok := peerIP(r.RemoteAddr)
+	if !ok || p == nil || !allowlistContains(p.prefixes, peer) {
+		return peer, ok
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	ip := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		addr, ok := peerIP(hop)
+		if !ok {
+			return netip.Addr{}, false
+		}
+		ip = addr
+		if !allowlistContains(p.prefixes, addr) {
+			break
+		}
+	}
+	return ip, true
+}
+
+// clientIP parses r.RemoteAddr, which is "ip:port" from the listener or a
+// bare IP once TrustedProxies.Middleware has resolved the client.
+func clientIP(r *http.Request) (netip.Addr, bool) {
+	return peerIP(r.RemoteAddr)
+}
+
+// peerIP parses an address with or without a port.
+func peerIP(host string) (netip.Addr, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxies_Middleware(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"10.0.0.0/8", "2001:db8::1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    *TrustedProxies
+		remoteAddr string
+		xff        []string
+		want       string
+	}{
+		{"direct client", proxies, "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"untrusted peer's header ignored", proxies, "203.0.113.7:5000", []string{"10.0.0.1"}, "203.0.113.7"},
+		{"no trusted proxies", &TrustedProxies{}, "10.0.0.2:5000", []string{"198.51.100.1"}, "10.0.0.2"},
+		{"client behind proxy", proxies, "10.0.0.2:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"forged hops left of the client", proxies, "10.0.0.2:5000", []string{"10.9.9.9, 192.0.2.1, 198.51.100.1"}, "198.51.100.1"},
+		{"chain of proxies", proxies, "10.0.0.2:5000", []string{"198.51.100.1, 10.0.0.3", "10.0.0.4"}, "198.51.100.1"},
+		{"ipv6 proxy", proxies, "[2001:db8::1]:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"only proxies", proxies, "10.0.0.2:5000", []string{"10.0.0.3, 10.0.0.4"}, "10.0.0.3"},
+		{"no header", proxies, "10.0.0.2:5000", nil, "10.0.0.2"},
+		{"unparsable hop", proxies, "10.0.0.2:5000", []string{"198.51.100.1, garbage"}, "10.0.0.2:5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := tt.proxies.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			req.Header.Set("X-Real-IP", "192.0.2.99")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected client %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := NewTrustedProxies([]string{"not-a-cidr"}); err == nil {
+		t.Error("expected an error for an invalid cidr")
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// corsExposedHeaders are the response headers a browser script may read.
+// Without them a dashboard couldn't revalidate with ETags, honour
+// Retry-After or show the correlation ID of a failed request.
+var corsExposedHeaders = strings.Join([]string{
+	headerETag,
+	headerReplay,
+	observ.CorrelationIDHeader,
+	"Location",
+	"Retry-After",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"RateLimit-Policy",
+}, ", ")
+
+// CORSConfig tunes CORSMiddleware.
+type CORSConfig struct {
+	// AllowedOrigins are the exact origins ("https://dash.acme.com") allowed
+	// to call the API from a browser. "*" allows any origin. Empty disables
+	// CORS.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// MaxAge is how long, in seconds, a browser may cache a preflight
+	// response. 0 leaves it to the browser.
+	MaxAge int
+}
+
+// CORSMiddleware lets browser-based dashboards call the API directly.
+// Preflight requests from an allowed origin are answered here with 204, so
+// they never reach auth or rate limiting. Requests from other origins are
+// passed through without CORS headers and the browser blocks the response.
+// Credentials (cookies) are never allowed; callers authenticate with
+// headers, which AllowedHeaders must then list.
+func CORSMiddleware(cfg CORSConfig) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	anyOrigin := false
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+		}
+		allowed[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		if len(allowed) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			// Responses differ by origin even when this one is refused, so
+			// shared caches must not serve one origin's response to another.
+			h.Add("Vary", "Origin")
+			if !anyOrigin && !allowed[strings.ToLower(origin)] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				if cfg.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func corsTestHandler(cfg CORSConfig) http.Handler {
+	return CORSMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+}
+
+var testCORSConfig = CORSConfig{
+	AllowedOrigins: []string{"https://dash.acme.com"},
+	AllowedMethods: []string{"GET", "POST"},
+	AllowedHeaders: []string{"Authorization", "Content-Type", "Idempotency-Key", "X-Tenant-ID"},
+	MaxAge:         600,
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/v1/notifications", nil)
+	req.Header.Set("Origin", "https://dash.acme.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "idempotency-key, content-type")
+	rec := httptest.NewRecorder()
+	corsTestHandler(testCORSConfig).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://dash.acme.com" {
+		t.Errorf("unexpected Allow-Origin %q", h.Get("Access-Control-Allow-Origin"))
+	}
+	if h.Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Errorf("unexpected Allow-Methods %q", h.Get("Access-Control-Allow-Methods"))
+	}
+	if !strings.Contains(h.Get("Access-Control-Allow-Headers"), "Idempotency-Key") ||
+		!strings.Contains(h.Get("Access-Control-Allow-Headers"), "X-Tenant-ID") {
+		t.Errorf("unexpected Allow-Headers %q", h.Get("Access-Control-Allow-Headers"))
+	}
+	if h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("unexpected Max-Age %q", h.Get("Access-Control-Max-Age"))
+	}
+	if h.Get("Access-Control-Allow-Credentials") != "" {
+		t.Error("credentials must never be allowed")
+	}
+}
+
+func TestCORSMiddleware_ActualRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+	req.Header.Set("Origin", "https://DASH.acme.com")
+	rec := httptest.NewRecorder()
+	corsTestHandler(testCORSConfig).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("expected the request to reach the handler, got %d", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://DASH.acme.com" {
+		t.Errorf("expected the origin to be echoed, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if !strings.Contains(rec.Header().Get("Access-Control-Expose-Headers"), "X-Correlation-ID") {
+		t.Errorf("expected exposed headers, got %q", rec.Header().Get("Access-Control-Expose-Headers"))
+	}
+	if rec.Header().Get("Vary") != "Origin" {
+		t.Errorf("expected Vary: Origin, got %q", rec.Header().Get("Vary"))
+	}
+}
+
+func TestCORSMiddleware_Refused(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    CORSConfig
+		origin string
+	}{
+		{"unlisted origin", testCORSConfig, "https://evil.example"},
+		{"no origin", testCORSConfig, ""},
+		{"disabled", CORSConfig{}, "https://dash.acme.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/v1/notifications", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			rec := httptest.NewRecorder()
+			corsTestHandler(tt.cfg).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusTeapot {
+				t.Errorf("expected the request to pass through, got %d", rec.Code)
+			}
+			if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+				t.Errorf("expected no CORS headers, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_AnyOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec := httptest.NewRecorder()
+	corsTestHandler(CORSConfig{AllowedOrigins: []string{"*"}}).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("expected a wildcard Allow-Origin, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// maxDeliveryEventBody bounds an SNS message. SNS itself caps them at 256KB.
+const maxDeliveryEventBody = 256 << 10
+
+// providerSES is the provider name the SES sender records on notifications
+// (worker.ProviderSES), which events are matched against.
+const providerSES = "ses"
+
+// DeliveryEventRepository stores provider-reported delivery events, and
+// annotates the notifications they are about.
+type DeliveryEventRepository interface {
+	InsertDeliveryEvents(ctx context.Context, events []*db.DeliveryEvent) (int64, error)
+	AnnotateByProviderMessageID(ctx context.Context, providerMessageID string, a *db.NotificationAnnotation) (bool, error)
+}
+
+// DeliveryEventExporter receives stored delivery events for export, e.g.
+// to the analytics warehouse. It must not block.
+type DeliveryEventExporter interface {
+	ExportDeliveryEvents(events []*db.DeliveryEvent)
+}
+
+// DeliveryEventsHandler receives delivery, bounce, complaint and
+// engagement notifications from providers. Bounces and complaints feed the
+// reputation guard.
+type DeliveryEventsHandler struct {
+	repo     DeliveryEventRepository
+	exporter DeliveryEventExporter // optional
+	token    string
+	logger   *zap.Logger
+}
+
+// NewDeliveryEventsHandler creates the handler. token is the shared secret
+// callers must pass as ?token=; SNS can't send custom headers, so it lives
+// in the subscription URL.
+func NewDeliveryEventsHandler(logger *zap.Logger, repo DeliveryEventRepository, token string) *DeliveryEventsHandler {
+	return &DeliveryEventsHandler{
+		repo:   repo,
+		token:  token,
+		logger: logger,
+	}
+}
+
+// SetExporter makes the handler pass every stored batch of events to
+// exporter as well.
+func (h *DeliveryEventsHandler) SetExporter(exporter DeliveryEventExporter) {
+	h.exporter = exporter
+}
+
+// snsEnvelope is the outer message SNS POSTs to an HTTPS subscription.
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	MessageID    string `json:"MessageId"`
+	TopicArn     string `json:"TopicArn"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// sesNotification is an SES bounce, complaint or delivery notification, as
+// published to the identity's SNS topic, or an event from a configuration
+// set's SNS event destination. The two formats share their fields, except
+// that events name their type in eventType.
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Mail             struct {
+		MessageID   string              `json:"messageId"`
+		Timestamp   time.Time           `json:"timestamp"`
+		Destination []string            `json:"destination"`
+		Tags        map[string][]string `json:"tags"`
+	} `json:"mail"`
+	Bounce *struct {
+		BounceType        string         `json:"bounceType"`
+		BounceSubType     string         `json:"bounceSubType"`
+		BouncedRecipients []sesRecipient `json:"bouncedRecipients"`
+		Timestamp         time.Time      `json:"timestamp"`
+	} `json:"bounce"`
+	Complaint *struct {
+		ComplainedRecipients  []sesRecipient `json:"complainedRecipients"`
+		ComplaintFeedbackType string         `json:"complaintFeedbackType"`
+		Timestamp             time.Time      `json:"timestamp"`
+	} `json:"complaint"`
+	Delivery *struct {
+		Recipients           []string  `json:"recipients"`
+		SMTPResponse         string    `json:"smtpResponse"`
+		ProcessingTimeMillis int64     `json:"processingTimeMillis"`
+		Timestamp            time.Time `json:"timestamp"`
+	} `json:"delivery"`
+	Open *struct {
+		IPAddress string    `json:"ipAddress"`
+		UserAgent string    `json:"userAgent"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"open"`
+	Click *struct {
+		Link      string              `json:"link"`
+		LinkTags  map[string][]string `json:"linkTags"`
+		IPAddress string              `json:"ipAddress"`
+		UserAgent string              `json:"userAgent"`
+		Timestamp time.Time           `json:"timestamp"`
+	} `json:"click"`
+	Failure *struct {
+		ErrorMessage string `json:"errorMessage"`
+		TemplateName string `json:"templateName"`
+	} `json:"failure"`
+}
+
+// kind is the notification or event type, e.g. "Bounce" or "Open".
+func (n *sesNotification) kind() string {
+	if n.NotificationType != "" {
+		return n.NotificationType
+	}
+	return n.EventType
+}
+
+type sesRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	Status         string `json:"status,omitempty"`
+	DiagnosticCode string `json:"diagnosticCode,omitempty"`
+}
+
+// ReceiveSESEvent handles POST /v1/providers/ses/events?token=...
+//
+// Subscription confirmations are logged rather than followed: fetching a
+// URL from an unauthenticated body is an SSRF vector, so an operator
+// confirms the subscription by hand. Notification types we don't track are
+// acknowledged and dropped, or SNS would redeliver them forever.
+func (h *DeliveryEventsHandler) ReceiveSESEvent(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(h.token)) != 1 {
+		writeProblem(w, http.StatusUnauthorized, "unauthorized", "Invalid token", "")
+		return
+	}
+
+	var env snsEnvelope
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeliveryEventBody)).Decode(&env); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	switch env.Type {
+	case "SubscriptionConfirmation":
+		h.logger.Warn("SNS subscription awaiting confirmation; open SubscribeURL to confirm",
+			zap.String("topic_arn", env.TopicArn),
+			zap.String("subscribe_url", env.SubscribeURL),
+		)
+		w.WriteHeader(http.StatusOK)
+		return
+	case "Notification":
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var n sesNotification
+	if err := json.Unmarshal([]byte(env.Message), &n); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Malformed SES notification", err.Error())
+		return
+	}
+	if n.Mail.MessageID == "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Malformed SES notification", "mail.messageId is required")
+		return
+	}
+
+	events := sesDeliveryEvents(&n)
+	if len(events) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	stored, err := h.repo.InsertDeliveryEvents(r.Context(), events)
+	if err != nil {
+		h.logger.Error("failed to store delivery events",
+			zap.Error(err),
+			zap.String("provider_message_id", n.Mail.MessageID),
+		)
+		// 5xx so SNS retries the delivery.
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to store delivery events", "")
+		return
+	}
+	for _, e := range events {
+		metrics.RecordDeliveryEvent(e.Provider, e.Type)
+	}
+	if h.exporter != nil {
+		h.exporter.ExportDeliveryEvents(events)
+	}
+	h.annotate(r.Context(), &n, events[0].Type)
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]int64{
+		"stored": stored,
+	})
+}
+
+// annotate records the provider's detail for an SES event on the
+// notification it is about. The events are already stored, so a failure
+// is only logged: failing the request would make SNS redeliver them.
+func (h *DeliveryEventsHandler) annotate(ctx context.Context, n *sesNotification, kind string) {
+	data, err := json.Marshal(sesAnnotation(n))
+	if err != nil {
+		h.logger.Warn("failed to marshal SES annotation", zap.Error(err))
+		return
+	}
+	found, err := h.repo.AnnotateByProviderMessageID(ctx, n.Mail.MessageID, &db.NotificationAnnotation{
+		Provider: providerSES,
+		Kind:     kind,
+		Data:     data,
+	})
+	if err != nil {
+		h.logger.Warn("failed to annotate notification with SES event",
+			zap.Error(err),
+			zap.String("provider_message_id", n.Mail.MessageID),
+		)
+		return
+	}
+	if !found {
+		h.logger.Debug("SES event for an unknown message, not annotated",
+			zap.String("provider_message_id", n.Mail.MessageID),
+		)
+	}
+}
+
+// sesAnnotation is the detail of an SES event kept on the notification's
+// timeline: per-recipient status and diagnostics, which the flattened
+// delivery events don't carry, and the message tags.
+func sesAnnotation(n *sesNotification) map[string]any {
+	data := map[string]any{}
+	if len(n.Mail.Tags) > 0 {
+		data["message_tags"] = n.Mail.Tags
+	}
+	switch {
+	case n.Bounce != nil:
+		data["bounce_type"] = n.Bounce.BounceType
+		data["bounce_sub_type"] = n.Bounce.BounceSubType
+		data["recipients"] = n.Bounce.BouncedRecipients
+	case n.Complaint != nil:
+		if n.Complaint.ComplaintFeedbackType != "" {
+			data["feedback_type"] = n.Complaint.ComplaintFeedbackType
+		}
+		data["recipients"] = n.Complaint.ComplainedRecipients
+	case n.Delivery != nil:
+		data["smtp_response"] = n.Delivery.SMTPResponse
+		data["processing_time_ms"] = n.Delivery.ProcessingTimeMillis
+		data["recipients"] = n.Delivery.Recipients
+	case n.Open != nil:
+		data["ip_address"] = n.Open.IPAddress
+		data["user_agent"] = n.Open.UserAgent
+	case n.Click != nil:
+		data["link"] = n.Click.Link
+		if len(n.Click.LinkTags) > 0 {
+			data["link_tags"] = n.Click.LinkTags
+		}
+		data["ip_address"] = n.Click.IPAddress
+		data["user_agent"] = n.Click.UserAgent
+	case n.Failure != nil:
+		data["error_message"] = n.Failure.ErrorMessage
+		data["template_name"] = n.Failure.TemplateName
+	}
+	return data
+}
+
+// sesDeliveryEvents flattens an SES notification into one event per
+// recipient. Engagement events don't name the recipient, so they are
+// attributed to the message's destination.
+func sesDeliveryEvents(n *sesNotification) []*db.DeliveryEvent {
+	var (
+		eventType, bounceType string
+		occurredAt            time.Time
+		recipients            []string
+	)
+
+	switch kind := n.kind(); {
+	case kind == "Bounce" && n.Bounce != nil:
+		eventType, bounceType, occurredAt = db.DeliveryEventBounce, strings.ToLower(n.Bounce.BounceType), n.Bounce.Timestamp
+		for _, r := range n.Bounce.BouncedRecipients {
+			recipients = append(recipients, r.EmailAddress)
+		}
+	case kind == "Complaint" && n.Complaint != nil:
+		eventType, occurredAt = db.DeliveryEventComplaint, n.Complaint.Timestamp
+		for _, r := range n.Complaint.ComplainedRecipients {
+			recipients = append(recipients, r.EmailAddress)
+		}
+	case kind == "Delivery" && n.Delivery != nil:
+		eventType, occurredAt, recipients = db.DeliveryEventDelivery, n.Delivery.Timestamp, n.Delivery.Recipients
+	case kind == "Open" && n.Open != nil:
+		eventType, occurredAt, recipients = db.DeliveryEventOpen, n.Open.Timestamp, n.Mail.Destination
+	case kind == "Click" && n.Click != nil:
+		eventType, occurredAt, recipients = db.DeliveryEventClick, n.Click.Timestamp, n.Mail.Destination
+	case kind == "Rendering Failure" && n.Failure != nil:
+		// Rendering failures carry no time of their own; the send's is
+		// when it failed.
+		eventType, occurredAt, recipients = db.DeliveryEventRenderingFailure, n.Mail.Timestamp, n.Mail.Destination
+	default:
+		return nil
+	}
+
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	if len(recipients) == 0 {
+		recipients = []string{""}
+	}
+
+	events := make([]*db.DeliveryEvent, 0, len(recipients))
+	for _, recipient := range recipients {
+		events = append(events, &db.DeliveryEvent{
+			OccurredAt:        occurredAt,
+			Channel:           db.ChannelEmail,
+			Provider:          providerSES,
+			ProviderMessageID: n.Mail.MessageID,
+			Type:              eventType,
+			BounceType:        bounceType,
+			Recipient:         strings.ToLower(recipient),
+		})
+	}
+	return events
+}
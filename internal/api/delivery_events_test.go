@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockDeliveryEventRepo struct {
+	events      []*db.DeliveryEvent
+	annotations map[string]*db.NotificationAnnotation // by provider message ID
+	err         error
+}
+
+func (m *mockDeliveryEventRepo) InsertDeliveryEvents(ctx context.Context, events []*db.DeliveryEvent) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.events = append(m.events, events...)
+	return int64(len(events)), nil
+}
+
+func (m *mockDeliveryEventRepo) AnnotateByProviderMessageID(ctx context.Context, providerMessageID string, a *db.NotificationAnnotation) (bool, error) {
+	if m.annotations == nil {
+		m.annotations = map[string]*db.NotificationAnnotation{}
+	}
+	m.annotations[providerMessageID] = a
+	return true, nil
+}
+
+// snsBody wraps an SES notification in an SNS envelope.
+func snsBody(t *testing.T, snsType, message string) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{
+		"Type":         snsType,
+		"MessageId":    "sns-1",
+		"TopicArn":     "arn:aws:sns:us-east-1:123456789012:ses-events",
+		"Message":      message,
+		"SubscribeURL": "https://sns.us-east-1.amazonaws.com/confirm",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestReceiveSESEvent_Bounce(t *testing.T) {
+	repo := &mockDeliveryEventRepo{}
+	handler := NewDeliveryEventsHandler(zap.NewNop(), repo, "s3cret")
+
+	message := `{"notificationType":"Bounce","mail":{"messageId":"0100018c-abc","tags":{"correlation_id":["req-1"]}},
+		"bounce":{"bounceType":"Permanent","bounceSubType":"General","timestamp":"2024-01-01T00:00:00Z",
+		"bouncedRecipients":[{"emailAddress":"A@example.com","diagnosticCode":"smtp; 550 5.1.1 user unknown"},{"emailAddress":"b@example.com"}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/providers/ses/events?token=s3cret",
+		strings.NewReader(snsBody(t, "Notification", message)))
+	rec := httptest.NewRecorder()
+	handler.ReceiveSESEvent(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.events) != 2 {
+		t.Fatalf("expected one event per recipient, got %d", len(repo.events))
+	}
+	e := repo.events[0]
+	if e.Type != db.DeliveryEventBounce || e.BounceType != db.BounceTypePermanent ||
+		e.ProviderMessageID != "0100018c-abc" || e.Provider != "ses" || e.Recipient != "a@example.com" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+
+	a := repo.annotations["0100018c-abc"]
+	if a == nil || a.Provider != "ses" || a.Kind != db.DeliveryEventBounce {
+		t.Fatalf("expected the bounce annotated, got %+v", a)
+	}
+	for _, want := range []string{`"bounce_sub_type":"General"`, `"diagnosticCode":"smtp; 550 5.1.1 user unknown"`, `"message_tags":{"correlation_id":["req-1"]}`} {
+		if !strings.Contains(string(a.Data), want) {
+			t.Errorf("expected %s in the annotation, got %s", want, a.Data)
+		}
+	}
+}
+
+func TestReceiveSESEvent_ConfigurationSetEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		expected   string
+		annotation string
+	}{
+		{"open", `{"eventType":"Open","mail":{"messageId":"m-1","destination":["A@example.com"]},
+			"open":{"ipAddress":"192.0.2.1","userAgent":"Mozilla/5.0","timestamp":"2024-01-01T00:00:00Z"}}`,
+			db.DeliveryEventOpen, `"ip_address":"192.0.2.1"`},
+		{"click", `{"eventType":"Click","mail":{"messageId":"m-1","destination":["a@example.com"]},
+			"click":{"link":"https://acme.com/offer","linkTags":{"campaign":["spring"]},"timestamp":"2024-01-01T00:00:00Z"}}`,
+			db.DeliveryEventClick, `"link":"https://acme.com/offer"`},
+		{"rendering failure", `{"eventType":"Rendering Failure","mail":{"messageId":"m-1","timestamp":"2024-01-01T00:00:00Z","destination":["a@example.com"]},
+			"failure":{"errorMessage":"Attribute 'name' is not present","templateName":"welcome"}}`,
+			db.DeliveryEventRenderingFailure, `"template_name":"welcome"`},
+		{"delivery", `{"eventType":"Delivery","mail":{"messageId":"m-1"},
+			"delivery":{"recipients":["a@example.com"],"timestamp":"2024-01-01T00:00:00Z"}}`,
+			db.DeliveryEventDelivery, `"recipients":["a@example.com"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockDeliveryEventRepo{}
+			req := httptest.NewRequest(http.MethodPost, "/v1/providers/ses/events?token=s3cret",
+				strings.NewReader(snsBody(t, "Notification", tt.message)))
+			rec := httptest.NewRecorder()
+			NewDeliveryEventsHandler(zap.NewNop(), repo, "s3cret").ReceiveSESEvent(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if len(repo.events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(repo.events))
+			}
+			e := repo.events[0]
+			if e.Type != tt.expected || e.Recipient != "a@example.com" || e.OccurredAt.Year() != 2024 {
+				t.Errorf("unexpected event: %+v", e)
+			}
+			a := repo.annotations["m-1"]
+			if a == nil || a.Kind != tt.expected || !strings.Contains(string(a.Data), tt.annotation) {
+				t.Errorf("expected %s in a %s annotation, got %+v", tt.annotation, tt.expected, a)
+			}
+		})
+	}
+}
+
+func TestReceiveSESEvent_Errors(t *testing.T) {
+	complaint := `{"notificationType":"Complaint","mail":{"messageId":"m-1"},
+		"complaint":{"complainedRecipients":[{"emailAddress":"c@example.com"}]}}`
+
+	tests := []struct {
+		name           string
+		token          string
+		body           string
+		repoErr        error
+		expectedStatus int
+		expectedStored int
+	}{
+		{"wrong token", "nope", snsBody(t, "Notification", complaint), nil, http.StatusUnauthorized, 0},
+		{"malformed envelope", "s3cret", `{`, nil, http.StatusBadRequest, 0},
+		{"malformed message", "s3cret", snsBody(t, "Notification", `{`), nil, http.StatusBadRequest, 0},
+		{"subscription confirmation", "s3cret", snsBody(t, "SubscriptionConfirmation", ""), nil, http.StatusOK, 0},
+		{"untracked type", "s3cret", snsBody(t, "Notification", `{"notificationType":"Received","mail":{"messageId":"m-1"}}`), nil, http.StatusOK, 0},
+		{"complaint", "s3cret", snsBody(t, "Notification", complaint), nil, http.StatusOK, 1},
+		{"repository error", "s3cret", snsBody(t, "Notification", complaint), errors.New("db down"), http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockDeliveryEventRepo{err: tt.repoErr}
+			req := httptest.NewRequest(http.MethodPost, "/v1/providers/ses/events?token="+tt.token, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			NewDeliveryEventsHandler(zap.NewNop(), repo, "s3cret").ReceiveSESEvent(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if len(repo.events) != tt.expectedStored {
+				t.Errorf("expected %d events stored, got %d", tt.expectedStored, len(repo.events))
+			}
+		})
+	}
+}
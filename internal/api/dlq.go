@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/dlqexport"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+const (
+	defaultDLQRetryLimit = 100
+	maxDLQRetryLimit     = 1000
+	maxDLQExportLimit    = 10000
+	dlqExportPageSize    = 1000
+)
+
+// DLQExporter writes DLQ items somewhere a tenant can download them.
+// *dlqexport.S3 implements it.
+type DLQExporter interface {
+	Export(ctx context.Context, tenantID uuid.UUID, items []*db.DeadLetterNotification) (*dlqexport.Export, error)
+}
+
+// SetDLQExporter enables POST /v1/dlq/export.
+func (h *Handler) SetDLQExporter(exporter DLQExporter) {
+	h.dlqExporter = exporter
+}
+
+// dlqRetryResult is the body of a bulk DLQ retry response.
+type dlqRetryResult struct {
+	Retried         int         `json:"retried"`
+	Failed          int         `json:"failed"`
+	NotificationIDs []uuid.UUID `json:"notification_ids"`
+}
+
+// parseDeadLetterFilter reads ?reason= and ?status=. On failure it returns
+// the problem title and detail.
+func parseDeadLetterFilter(r *http.Request) (f db.DeadLetterFilter, title, detail string) {
+	f = db.DeadLetterFilter{
+		Reason: r.URL.Query().Get("reason"),
+		Status: r.URL.Query().Get("status"),
+	}
+	if f.Reason != "" && !db.ValidDLQReason(f.Reason) {
+		return f, errTitleInvalidReason, errDetailInvalidReason
+	}
+	switch f.Status {
+	case "", db.DLQStatusPending, db.DLQStatusRetried, db.DLQStatusDiscarded:
+	default:
+		return f, "Invalid status", "status must be one of pending, retried, discarded"
+	}
+	return f, "", ""
+}
+
+// parseDLQRetryLimit reads ?limit= for bulk retries, which allow a larger
+// batch than list pagination.
+func parseDLQRetryLimit(r *http.Request) int {
+	if l, err := strconv.Atoi(r.URL.Query().Get(queryParamLimit)); err == nil && l > 0 && l <= maxDLQRetryLimit {
+		return l
+	}
+	return defaultDLQRetryLimit
+}
+
+// retryDeadLetters retries up to limit of a tenant's pending DLQ items that
+// match filter. Items are retried one at a time, so one failure doesn't
+// stop the rest; err is only set when the list itself can't be loaded.
+func (h *Handler) retryDeadLetters(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter, limit int) (dlqRetryResult, error) {
+	filter.Status = db.DLQStatusPending
+	items, err := h.repo.ListDeadLetterByTenant(ctx, tenantID, filter, limit, 0)
+	if err != nil {
+		return dlqRetryResult{}, err
+	}
+
+	result := dlqRetryResult{NotificationIDs: []uuid.UUID{}}
+	for _, item := range items {
+		notif, err := h.repo.RetryDeadLetter(ctx, item.ID)
+		if err != nil {
+			observ.Logger(ctx, h.logger).Warn("failed to retry dead letter item",
+				zap.Error(err),
+				zap.String("id", item.ID.String()),
+			)
+			result.Failed++
+			continue
+		}
+		result.Retried++
+		result.NotificationIDs = append(result.NotificationIDs, notif.ID)
+	}
+
+	observ.Logger(ctx, h.logger).Info("dead letter queue retried",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("reason", filter.Reason),
+		zap.Int("retried", result.Retried),
+		zap.Int("failed", result.Failed),
+	)
+	return result, nil
+}
+
+// RetryDeadLetterQueue handles POST /v1/dlq/retry?tenant_id=xxx&reason=timeout&limit=100
+func (h *Handler) RetryDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(r.URL.Query().Get("tenant_id"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid tenant_id", "tenant_id must be a valid UUID")
+		return
+	}
+
+	filter, title, detail := parseDeadLetterFilter(r)
+	if title != "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", title, detail)
+		return
+	}
+
+	result, err := h.retryDeadLetters(r.Context(), tenantID, filter, parseDLQRetryLimit(r))
+	if err != nil {
+		h.logger.Error("failed to list dead letter queue for retry",
+			zap.Error(err),
+			zap.String("tenant_id", tenantID.String()),
+		)
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retry dead letter queue", "")
+		return
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// ExportDeadLetterQueue handles POST /v1/dlq/export?tenant_id=xxx&reason=timeout&status=pending&limit=10000.
+// It writes the matching items, newest first, to S3 as JSON Lines and
+// returns a presigned download URL.
+func (h *Handler) ExportDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
+	if h.dlqExporter == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "export_unavailable", "DLQ export not configured", "set DLQ_EXPORT_S3_BUCKET to enable exports")
+		return
+	}
+
+	tenantID, err := uuid.Parse(r.URL.Query().Get("tenant_id"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid tenant_id", "tenant_id must be a valid UUID")
+		return
+	}
+
+	filter, title, detail := parseDeadLetterFilter(r)
+	if title != "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", title, detail)
+		return
+	}
+
+	limit := maxDLQExportLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get(queryParamLimit)); err == nil && l > 0 && l < maxDLQExportLimit {
+		limit = l
+	}
+
+	var items []*db.DeadLetterNotification
+	for len(items) < limit {
+		page, err := h.repo.ListDeadLetterByTenant(r.Context(), tenantID, filter, min(dlqExportPageSize, limit-len(items)), len(items))
+		if err != nil {
+			h.logger.Error("failed to list dead letter queue for export",
+				zap.Error(err),
+				zap.String("tenant_id", tenantID.String()),
+			)
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to export dead letter queue", "")
+			return
+		}
+		items = append(items, page...)
+		if len(page) < dlqExportPageSize {
+			break
+		}
+	}
+
+	export, err := h.dlqExporter.Export(r.Context(), tenantID, items)
+	if err != nil {
+		h.logger.Error("failed to export dead letter queue",
+			zap.Error(err),
+			zap.String("tenant_id", tenantID.String()),
+		)
+		h.writeError(w, http.StatusBadGateway, "export_failed", "Failed to write DLQ export", "")
+		return
+	}
+
+	h.logger.Info("dead letter queue exported",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("key", export.Key),
+		zap.Int("count", export.Count),
+	)
+	writeJSON(w, http.StatusOK, export)
+}
+
+// RetryDeadLetterQueue handles POST /v2/dlq/retry?reason=timeout&limit=100.
+func (v *V2Handler) RetryDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	filter, _, detail := parseDeadLetterFilter(r)
+	if detail != "" {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: detail})
+		return
+	}
+
+	result, err := v.h.retryDeadLetters(r.Context(), tenantID, filter, parseDLQRetryLimit(r))
+	if err != nil {
+		observ.Logger(r.Context(), v.h.logger).Error("failed to list dead letter queue for retry", zap.Error(err))
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to retry dead letter queue"})
+		return
+	}
+
+	writeV2(w, http.StatusOK, Envelope{Data: result, Meta: newMeta(r)})
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	maxDLQRetryPolicyBytes = 1 << 10
+	// minDLQRetryInterval keeps a policy from replaying the DLQ faster than
+	// the retry job runs.
+	minDLQRetryInterval = 5 * time.Minute
+	maxDLQRetryInterval = 7 * 24 * time.Hour
+	maxDLQRetries       = 10
+)
+
+// DLQRetryPolicyRepository reads and writes tenants' DLQ retry policies.
+type DLQRetryPolicyRepository interface {
+	ListDLQRetryPolicies(ctx context.Context, tenantID uuid.UUID) ([]*db.DLQRetryPolicy, error)
+	UpsertDLQRetryPolicy(ctx context.Context, p *db.DLQRetryPolicy) error
+	DeleteDLQRetryPolicy(ctx context.Context, tenantID uuid.UUID, reason string) error
+}
+
+// DLQRetryPolicyHandler lets a tenant have DLQ entries with a given reason
+// code retried automatically, e.g. provider_outage every 2 hours up to 3
+// times. The dlq-auto-retry job applies the policies.
+type DLQRetryPolicyHandler struct {
+	repo   DLQRetryPolicyRepository
+	logger *zap.Logger
+}
+
+// NewDLQRetryPolicyHandler creates a handler for DLQ retry policies.
+func NewDLQRetryPolicyHandler(logger *zap.Logger, repo DLQRetryPolicyRepository) *DLQRetryPolicyHandler {
+	return &DLQRetryPolicyHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type dlqRetryPolicyRequest struct {
+	IntervalSeconds int `json:"interval_seconds"`
+	MaxRetries      int `json:"max_retries"`
+}
+
+// ListPolicies handles GET /v1/tenants/{tenant_id}/dlq/retry-policies
+func (h *DLQRetryPolicyHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	policies, err := h.repo.ListDLQRetryPolicies(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to list dlq retry policies", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list retry policies", "")
+		return
+	}
+	if policies == nil {
+		policies = []*db.DLQRetryPolicy{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": policies})
+}
+
+// PutPolicy handles PUT /v1/tenants/{tenant_id}/dlq/retry-policies/{reason}
+// {"interval_seconds": 7200, "max_retries": 3}
+func (h *DLQRetryPolicyHandler) PutPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	reason, ok := dlqReasonPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req dlqRetryPolicyRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDLQRetryPolicyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	if detail := validateDLQRetryPolicy(&req); detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid retry policy", detail)
+		return
+	}
+
+	policy := &db.DLQRetryPolicy{
+		TenantID:        tenantID,
+		Reason:          reason,
+		IntervalSeconds: req.IntervalSeconds,
+		MaxRetries:      req.MaxRetries,
+	}
+	if err := h.repo.UpsertDLQRetryPolicy(r.Context(), policy); err != nil {
+		h.logger.Error("failed to save dlq retry policy", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save retry policy", "")
+		return
+	}
+
+	h.logger.Info("dlq retry policy updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("reason", reason),
+		zap.Int("interval_seconds", policy.IntervalSeconds),
+		zap.Int("max_retries", policy.MaxRetries),
+	)
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// DeletePolicy handles DELETE /v1/tenants/{tenant_id}/dlq/retry-policies/{reason}
+func (h *DLQRetryPolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	reason, ok := dlqReasonPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.DeleteDLQRetryPolicy(r.Context(), tenantID, reason)
+	if errors.Is(err, db.ErrNoDLQRetryPolicy) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No retry policy", "the tenant has no retry policy for "+reason)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to delete dlq retry policy", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to delete retry policy", "")
+		return
+	}
+
+	h.logger.Info("dlq retry policy removed",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("reason", reason),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateDLQRetryPolicy(req *dlqRetryPolicyRequest) string {
+	interval := time.Duration(req.IntervalSeconds) * time.Second
+	if interval < minDLQRetryInterval || interval > maxDLQRetryInterval {
+		return fmt.Sprintf("interval_seconds must be between %d and %d",
+			int(minDLQRetryInterval.Seconds()), int(maxDLQRetryInterval.Seconds()))
+	}
+	if req.MaxRetries < 1 || req.MaxRetries > maxDLQRetries {
+		return fmt.Sprintf("max_retries must be between 1 and %d", maxDLQRetries)
+	}
+	return ""
+}
+
+// dlqReasonPathParam parses the {reason} segment as a DLQ reason code.
+func dlqReasonPathParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	reason := chi.URLParam(r, "reason")
+	if !db.ValidDLQReason(reason) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidReason, errDetailInvalidReason)
+		return "", false
+	}
+	return reason, true
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockDLQRetryPolicyRepo struct {
+	policies map[string]*db.DLQRetryPolicy
+}
+
+func (m *mockDLQRetryPolicyRepo) ListDLQRetryPolicies(ctx context.Context, tenantID uuid.UUID) ([]*db.DLQRetryPolicy, error) {
+	var out []*db.DLQRetryPolicy
+	for _, p := range m.policies {
+		if p.TenantID == tenantID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockDLQRetryPolicyRepo) UpsertDLQRetryPolicy(ctx context.Context, p *db.DLQRetryPolicy) error {
+	m.policies[p.TenantID.String()+p.Reason] = p
+	return nil
+}
+
+func (m *mockDLQRetryPolicyRepo) DeleteDLQRetryPolicy(ctx context.Context, tenantID uuid.UUID, reason string) error {
+	if _, ok := m.policies[tenantID.String()+reason]; !ok {
+		return db.ErrNoDLQRetryPolicy
+	}
+	delete(m.policies, tenantID.String()+reason)
+	return nil
+}
+
+func dlqRetryPolicyHTTPRequest(method, tenantID, reason, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/dlq/retry-policies/"+reason, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	rctx.URLParams.Add("reason", reason)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPutDLQRetryPolicy(t *testing.T) {
+	handler := NewDLQRetryPolicyHandler(zap.NewNop(), &mockDLQRetryPolicyRepo{policies: map[string]*db.DLQRetryPolicy{}})
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		reason         string
+		body           string
+		expectedStatus int
+	}{
+		{"unknown reason", "bad_luck", `{"interval_seconds": 7200, "max_retries": 3}`, http.StatusBadRequest},
+		{"interval too short", db.DLQReasonProviderOutage, `{"interval_seconds": 60, "max_retries": 3}`, http.StatusBadRequest},
+		{"no retries", db.DLQReasonProviderOutage, `{"interval_seconds": 7200, "max_retries": 0}`, http.StatusBadRequest},
+		{"too many retries", db.DLQReasonProviderOutage, `{"interval_seconds": 7200, "max_retries": 50}`, http.StatusBadRequest},
+		{"unknown field", db.DLQReasonProviderOutage, `{"interval_seconds": 7200, "max_retries": 3, "backoff": 2}`, http.StatusBadRequest},
+		{"valid", db.DLQReasonProviderOutage, `{"interval_seconds": 7200, "max_retries": 3}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.PutPolicy(rec, dlqRetryPolicyHTTPRequest(http.MethodPut, tenantID, tt.reason, tt.body))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ListPolicies(rec, dlqRetryPolicyHTTPRequest(http.MethodGet, tenantID, "", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"reason":"provider_outage"`) {
+		t.Errorf("expected the saved policy, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeletePolicy(rec, dlqRetryPolicyHTTPRequest(http.MethodDelete, tenantID, db.DLQReasonProviderOutage, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeletePolicy(rec, dlqRetryPolicyHTTPRequest(http.MethodDelete, tenantID, db.DLQReasonProviderOutage, ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", rec.Code)
+	}
+}
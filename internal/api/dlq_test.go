@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/dlqexport"
+)
+
+func newDLQRepo(tenantID uuid.UUID) *MockRepository {
+	repo := NewMockRepository()
+	repo.deadLetters = []*db.DeadLetterNotification{
+		{ID: uuid.New(), TenantID: tenantID, Reason: db.DLQReasonTimeout, Status: db.DLQStatusPending},
+		{ID: uuid.New(), TenantID: tenantID, Reason: db.DLQReasonTimeout, Status: db.DLQStatusDiscarded},
+		{ID: uuid.New(), TenantID: tenantID, Reason: db.DLQReasonInvalidRecipient, Status: db.DLQStatusPending},
+		{ID: uuid.New(), TenantID: uuid.New(), Reason: db.DLQReasonTimeout, Status: db.DLQStatusPending},
+	}
+	return repo
+}
+
+func TestListDeadLetterQueue_Filter(t *testing.T) {
+	tenantID := uuid.New()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{"no filter", "", http.StatusOK, 3},
+		{"by reason", "&reason=timeout", http.StatusOK, 2},
+		{"by reason and status", "&reason=timeout&status=pending", http.StatusOK, 1},
+		{"unknown reason", "&reason=bad_luck", http.StatusBadRequest, 0},
+		{"unknown status", "&status=stuck", http.StatusBadRequest, 0},
+		{"sorted by status", "&sort=status&order=asc", http.StatusOK, 3},
+		{"unknown sort", "&sort=reason", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(zap.NewNop(), newDLQRepo(tenantID))
+			req := httptest.NewRequest(http.MethodGet, "/v1/dlq?tenant_id="+tenantID.String()+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.ListDeadLetterQueue(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Count int `json:"count"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Count != tt.wantCount {
+				t.Errorf("expected %d items, got %d", tt.wantCount, resp.Count)
+			}
+		})
+	}
+}
+
+func TestRetryDeadLetterQueue(t *testing.T) {
+	tenantID := uuid.New()
+	repo := newDLQRepo(tenantID)
+	handler := NewHandler(zap.NewNop(), repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/dlq/retry?tenant_id="+tenantID.String()+"&reason=timeout", nil)
+	rec := httptest.NewRecorder()
+	handler.RetryDeadLetterQueue(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result dlqRetryResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Retried != 1 || result.Failed != 0 || len(result.NotificationIDs) != 1 {
+		t.Errorf("expected one retried item, got %+v", result)
+	}
+
+	// Only the tenant's pending timeout item moves; the discarded one, the
+	// other reason, and the other tenant's item are untouched.
+	wantStatus := []string{db.DLQStatusRetried, db.DLQStatusDiscarded, db.DLQStatusPending, db.DLQStatusPending}
+	for i, item := range repo.deadLetters {
+		if item.Status != wantStatus[i] {
+			t.Errorf("item %d: expected status %s, got %s", i, wantStatus[i], item.Status)
+		}
+	}
+}
+
+func TestRetryDeadLetterQueue_InvalidReason(t *testing.T) {
+	handler := NewHandler(zap.NewNop(), NewMockRepository())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/dlq/retry?tenant_id="+uuid.New().String()+"&reason=bad_luck", nil)
+	rec := httptest.NewRecorder()
+	handler.RetryDeadLetterQueue(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+type fakeDLQExporter struct {
+	items []*db.DeadLetterNotification
+	err   error
+}
+
+func (f *fakeDLQExporter) Export(ctx context.Context, tenantID uuid.UUID, items []*db.DeadLetterNotification) (*dlqexport.Export, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.items = items
+	return &dlqexport.Export{Key: "dlq-exports/" + tenantID.String() + "/x.jsonl", URL: "https://signed", Count: len(items)}, nil
+}
+
+func TestExportDeadLetterQueue(t *testing.T) {
+	tenantID := uuid.New()
+	query := "/v1/dlq/export?tenant_id=" + tenantID.String() + "&reason=timeout"
+
+	t.Run("not configured", func(t *testing.T) {
+		handler := NewHandler(zap.NewNop(), newDLQRepo(tenantID))
+		rec := httptest.NewRecorder()
+		handler.ExportDeadLetterQueue(rec, httptest.NewRequest(http.MethodPost, query, nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected 503, got %d", rec.Code)
+		}
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		exporter := &fakeDLQExporter{}
+		handler := NewHandler(zap.NewNop(), newDLQRepo(tenantID))
+		handler.SetDLQExporter(exporter)
+
+		rec := httptest.NewRecorder()
+		handler.ExportDeadLetterQueue(rec, httptest.NewRequest(http.MethodPost, query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if len(exporter.items) != 2 {
+			t.Errorf("expected the tenant's 2 timeout items exported, got %d", len(exporter.items))
+		}
+		var export dlqexport.Export
+		if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil || export.URL != "https://signed" || export.Count != 2 {
+			t.Errorf("unexpected response %s (err %v)", rec.Body.String(), err)
+		}
+	})
+
+	t.Run("upload fails", func(t *testing.T) {
+		handler := NewHandler(zap.NewNop(), newDLQRepo(tenantID))
+		handler.SetDLQExporter(&fakeDLQExporter{err: errors.New("AccessDenied")})
+
+		rec := httptest.NewRecorder()
+		handler.ExportDeadLetterQueue(rec, httptest.NewRequest(http.MethodPost, query, nil))
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected 502, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lalithlochan/nimbus/internal/emailcheck"
+)
+
+// EmailChecker judges an email recipient. *emailcheck.Checker implements it.
+type EmailChecker interface {
+	Check(ctx context.Context, address string) emailcheck.Verdict
+}
+
+// EmailPolicy configures recipient validation for email notifications. With
+// Enforce unset, bad recipients are accepted and only flagged in the
+// response; with it set, they are rejected. A nil Checker disables both.
+type EmailPolicy struct {
+	Checker EmailChecker
+	Enforce bool
+}
+
+// SetEmailPolicy configures email recipient validation at create time.
+func (h *Handler) SetEmailPolicy(policy EmailPolicy) {
+	h.emailPolicy = policy
+}
+
+// checkEmailRecipient validates an email payload's "to" address. It returns
+// nil for other channels, payloads without a string "to", or when no checker
+// is configured, and an error only when the policy enforces and the verdict
+// is invalid.
+func (h *Handler) checkEmailRecipient(ctx context.Context, channel string, payload json.RawMessage) (*emailcheck.Verdict, error) {
+	if h.emailPolicy.Checker == nil || channel != channelEmail || len(payload) == 0 {
+		return nil, nil
+	}
+
+	var p struct {
+		To string `json:"to"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.To == "" {
+		return nil, nil
+	}
+
+	verdict := h.emailPolicy.Checker.Check(ctx, p.To)
+	if !verdict.Valid && h.emailPolicy.Enforce {
+		return &verdict, fmt.Errorf("recipient %q rejected: %s", p.To, verdict.Reason)
+	}
+	return &verdict, nil
+}
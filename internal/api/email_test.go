@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/emailcheck"
+)
+
+func TestCreateNotification_EmailVerdict(t *testing.T) {
+	tests := []struct {
+		name           string
+		to             string
+		enforce        bool
+		expectedStatus int
+		expectedValid  bool
+		expectedReason string
+	}{
+		{"valid recipient", "user@example.com", true, http.StatusCreated, true, ""},
+		{"disposable warns", "user@mailinator.com", false, http.StatusCreated, false, emailcheck.ReasonDisposable},
+		{"disposable enforced", "user@mailinator.com", true, http.StatusBadRequest, false, ""},
+		{"bad syntax warns", "not-an-email", false, http.StatusCreated, false, emailcheck.ReasonInvalidSyntax},
+		{"bad syntax enforced", "not-an-email", true, http.StatusBadRequest, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			handler.SetEmailPolicy(EmailPolicy{
+				Checker: emailcheck.New(emailcheck.Config{}, zap.NewNop()),
+				Enforce: tt.enforce,
+			})
+
+			payload, _ := json.Marshal(map[string]string{"to": tt.to, "subject": "Hi", "body": "Hello"})
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: "00000000-0000-0000-0000-000000000001",
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "email",
+				Payload:  payload,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				if mockRepo.createCalled {
+					t.Error("expected no notification to be created")
+				}
+				return
+			}
+
+			var resp NotificationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Email == nil {
+				t.Fatal("expected an email verdict in the response")
+			}
+			if resp.Email.Valid != tt.expectedValid || resp.Email.Reason != tt.expectedReason {
+				t.Errorf("expected valid=%v reason=%q, got %+v", tt.expectedValid, tt.expectedReason, *resp.Email)
+			}
+		})
+	}
+}
+
+func TestCreateNotification_NoEmailVerdictWithoutPolicy(t *testing.T) {
+	handler := NewHandler(zap.NewNop(), NewMockRepository())
+	body, _ := json.Marshal(NotificationRequest{
+		TenantID: "00000000-0000-0000-0000-000000000001",
+		UserID:   "00000000-0000-0000-0000-000000000002",
+		Channel:  "email",
+		Payload:  json.RawMessage(`{"to":"user@mailinator.com"}`),
+	})
+	rec := httptest.NewRecorder()
+	handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	var resp NotificationResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Email != nil {
+		t.Errorf("expected no email verdict, got %+v", *resp.Email)
+	}
+}
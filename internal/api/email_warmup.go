@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// EmailWarmupRepository reads and ends tenant email warm-ups.
+type EmailWarmupRepository interface {
+	GetEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*db.EmailWarmup, error)
+	EndEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*db.EmailWarmup, error)
+}
+
+// EmailWarmupHandler serves the admin endpoints for a tenant's email
+// warm-up: where it is on the schedule, and ending it early for tenants
+// that bring an established sending reputation with them.
+type EmailWarmupHandler struct {
+	repo     EmailWarmupRepository
+	schedule []int
+	logger   *zap.Logger
+}
+
+// NewEmailWarmupHandler creates the admin handler for email warm-ups.
+// schedule is the configured EMAIL_WARMUP_SCHEDULE.
+func NewEmailWarmupHandler(logger *zap.Logger, repo EmailWarmupRepository, schedule []int) *EmailWarmupHandler {
+	return &EmailWarmupHandler{
+		repo:     repo,
+		schedule: schedule,
+		logger:   logger,
+	}
+}
+
+// emailWarmupResponse adds the tenant's place on the schedule. Day is
+// 1-based; DailyCap is nil once the tenant is no longer capped.
+type emailWarmupResponse struct {
+	*db.EmailWarmup
+	Day      int  `json:"day"`
+	DailyCap *int `json:"daily_cap"`
+}
+
+// GetWarmup handles GET /v1/admin/tenants/{tenantID}/warmup
+func (h *EmailWarmupHandler) GetWarmup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	warmup, err := h.repo.GetEmailWarmup(r.Context(), tenantID)
+	if errors.Is(err, db.ErrNoEmailWarmup) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No email warm-up", "the tenant has not sent email yet")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get email warm-up", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get email warm-up", "")
+		return
+	}
+
+	h.writeWarmup(w, warmup)
+}
+
+// EndWarmup handles DELETE /v1/admin/tenants/{tenantID}/warmup. It works
+// before the tenant's first email too, so it never starts warming up.
+func (h *EmailWarmupHandler) EndWarmup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	warmup, err := h.repo.EndEmailWarmup(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to end email warm-up", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to end email warm-up", "")
+		return
+	}
+
+	h.logger.Info("email warm-up ended", zap.String(logFieldTenantID, tenantID.String()))
+	h.writeWarmup(w, warmup)
+}
+
+func (h *EmailWarmupHandler) writeWarmup(w http.ResponseWriter, warmup *db.EmailWarmup) {
+	now := time.Now().UTC()
+	started := warmup.StartedAt.UTC()
+	day := int(now.Truncate(24*time.Hour).Sub(started.Truncate(24*time.Hour))/(24*time.Hour)) + 1
+
+	resp := emailWarmupResponse{EmailWarmup: warmup, Day: day}
+	if warmup.EndedAt == nil && day <= len(h.schedule) {
+		resp.DailyCap = &h.schedule[day-1]
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockEmailWarmupRepo struct {
+	warmups map[uuid.UUID]*db.EmailWarmup
+}
+
+func (m *mockEmailWarmupRepo) GetEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*db.EmailWarmup, error) {
+	w, ok := m.warmups[tenantID]
+	if !ok {
+		return nil, db.ErrNoEmailWarmup
+	}
+	return w, nil
+}
+
+func (m *mockEmailWarmupRepo) EndEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*db.EmailWarmup, error) {
+	now := time.Now()
+	w, ok := m.warmups[tenantID]
+	if !ok {
+		w = &db.EmailWarmup{TenantID: tenantID, StartedAt: now}
+		m.warmups[tenantID] = w
+	}
+	w.EndedAt = &now
+	return w, nil
+}
+
+func warmupRequest(method, tenantID string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/admin/tenants/"+tenantID+"/warmup", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestEmailWarmup(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	repo := &mockEmailWarmupRepo{warmups: map[uuid.UUID]*db.EmailWarmup{
+		tenantID: {TenantID: tenantID, StartedAt: time.Now().Add(-24 * time.Hour), SentToday: 40},
+	}}
+	handler := NewEmailWarmupHandler(zap.NewNop(), repo, []int{50, 100, 500})
+
+	rec := httptest.NewRecorder()
+	handler.GetWarmup(rec, warmupRequest(http.MethodGet, tenantID.String()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Day       int  `json:"day"`
+		DailyCap  *int `json:"daily_cap"`
+		SentToday int  `json:"sent_today"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Day != 2 || resp.DailyCap == nil || *resp.DailyCap != 100 || resp.SentToday != 40 {
+		t.Errorf("expected day 2 with a cap of 100, got %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.EndWarmup(rec, warmupRequest(http.MethodDelete, tenantID.String()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from end, got %d", rec.Code)
+	}
+	resp.DailyCap = nil
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.DailyCap != nil {
+		t.Errorf("expected no cap after ending warm-up, got %d", *resp.DailyCap)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.GetWarmup(rec, warmupRequest(http.MethodGet, uuid.New().String()))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a tenant that never sent email, got %d", rec.Code)
+	}
+}
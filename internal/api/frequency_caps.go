@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	maxFrequencyCapBytes = 1 << 10
+	// maxFrequencyCapWindow bounds how far back the worker counts a user's
+	// sends.
+	maxFrequencyCapWindow = 30 * 24 * time.Hour
+)
+
+// FrequencyCapRepository reads and writes tenants' frequency caps.
+type FrequencyCapRepository interface {
+	ListTenantFrequencyCaps(ctx context.Context, tenantID uuid.UUID) ([]*db.FrequencyCap, error)
+	UpsertFrequencyCap(ctx context.Context, c *db.FrequencyCap) error
+	DeleteFrequencyCap(ctx context.Context, tenantID uuid.UUID, channel, category string) error
+	GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*db.NotificationCategory, error)
+}
+
+// FrequencyCapHandler lets a tenant cap how many notifications one user
+// gets on a channel within a window, e.g. 3 SMS an hour, or 1 marketing
+// email a day with ?category=marketing. The worker defers or drops the
+// excess; critical notifications are never capped.
+type FrequencyCapHandler struct {
+	repo   FrequencyCapRepository
+	logger *zap.Logger
+}
+
+// NewFrequencyCapHandler creates a handler for frequency caps.
+func NewFrequencyCapHandler(logger *zap.Logger, repo FrequencyCapRepository) *FrequencyCapHandler {
+	return &FrequencyCapHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type frequencyCapRequest struct {
+	MaxCount      int    `json:"max_count"`
+	WindowSeconds int    `json:"window_seconds"`
+	Action        string `json:"action"`
+}
+
+// ListCaps handles GET /v1/tenants/{tenant_id}/frequency-caps
+func (h *FrequencyCapHandler) ListCaps(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	caps, err := h.repo.ListTenantFrequencyCaps(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to list frequency caps", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list frequency caps", "")
+		return
+	}
+	if caps == nil {
+		caps = []*db.FrequencyCap{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": caps})
+}
+
+// PutCap handles PUT /v1/tenants/{tenant_id}/frequency-caps/{channel}[?category=name]
+// {"max_count": 3, "window_seconds": 3600, "action": "defer"}
+func (h *FrequencyCapHandler) PutCap(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	channel, ok := channelParam(w, r)
+	if !ok {
+		return
+	}
+	category, ok := h.categoryQueryParam(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	var req frequencyCapRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFrequencyCapBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	if req.Action == "" {
+		req.Action = db.FrequencyCapDefer
+	}
+	if detail := validateFrequencyCap(&req); detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid frequency cap", detail)
+		return
+	}
+
+	limit := &db.FrequencyCap{
+		TenantID:      tenantID,
+		Channel:       channel,
+		Category:      category,
+		MaxCount:      req.MaxCount,
+		WindowSeconds: req.WindowSeconds,
+		Action:        req.Action,
+	}
+	if err := h.repo.UpsertFrequencyCap(r.Context(), limit); err != nil {
+		h.logger.Error("failed to save frequency cap", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save frequency cap", "")
+		return
+	}
+
+	h.logger.Info("frequency cap updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String(logFieldChannel, channel),
+		zap.String("category", category),
+		zap.Int("max_count", limit.MaxCount),
+		zap.Int("window_seconds", limit.WindowSeconds),
+		zap.String("action", limit.Action),
+	)
+	writeJSON(w, http.StatusOK, limit)
+}
+
+// DeleteCap handles DELETE /v1/tenants/{tenant_id}/frequency-caps/{channel}[?category=name]
+func (h *FrequencyCapHandler) DeleteCap(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+	channel, ok := channelParam(w, r)
+	if !ok {
+		return
+	}
+	category := r.URL.Query().Get("category")
+	if err := validateCategory(category); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid category", err.Error())
+		return
+	}
+
+	err := h.repo.DeleteFrequencyCap(r.Context(), tenantID, channel, category)
+	if errors.Is(err, db.ErrNoFrequencyCap) {
+		detail := "the tenant has no frequency cap on " + channel
+		if category != "" {
+			detail += " for " + category
+		}
+		writeProblem(w, http.StatusNotFound, "not_found", "No frequency cap", detail)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to delete frequency cap", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to delete frequency cap", "")
+		return
+	}
+
+	h.logger.Info("frequency cap removed",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String(logFieldChannel, channel),
+		zap.String("category", category),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// categoryQueryParam reads the optional ?category= a cap applies to, which
+// must be one of the tenant's categories. Without it the cap covers the
+// whole channel.
+func (h *FrequencyCapHandler) categoryQueryParam(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID) (string, bool) {
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		return "", true
+	}
+	if err := validateCategory(category); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid category", err.Error())
+		return "", false
+	}
+	_, err := h.repo.GetNotificationCategory(r.Context(), tenantID, category)
+	if errors.Is(err, db.ErrNoNotificationCategory) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid category", "the tenant has no category "+category)
+		return "", false
+	}
+	if err != nil {
+		h.logger.Error("failed to get notification category", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save frequency cap", "")
+		return "", false
+	}
+	return category, true
+}
+
+func validateFrequencyCap(req *frequencyCapRequest) string {
+	if req.MaxCount < 1 {
+		return "max_count must be at least 1"
+	}
+	maxWindow := int(maxFrequencyCapWindow / time.Second)
+	if req.WindowSeconds < 1 || req.WindowSeconds > maxWindow {
+		return fmt.Sprintf("window_seconds must be between 1 and %d", maxWindow)
+	}
+	if req.Action != db.FrequencyCapDefer && req.Action != db.FrequencyCapDrop {
+		return `action must be "defer" or "drop"`
+	}
+	return ""
+}
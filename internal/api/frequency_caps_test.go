@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockFrequencyCapRepo struct {
+	caps       map[string]*db.FrequencyCap
+	categories map[string]bool
+}
+
+func (m *mockFrequencyCapRepo) ListTenantFrequencyCaps(ctx context.Context, tenantID uuid.UUID) ([]*db.FrequencyCap, error) {
+	var out []*db.FrequencyCap
+	for _, c := range m.caps {
+		if c.TenantID == tenantID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockFrequencyCapRepo) UpsertFrequencyCap(ctx context.Context, c *db.FrequencyCap) error {
+	m.caps[c.TenantID.String()+c.Channel+c.Category] = c
+	return nil
+}
+
+func (m *mockFrequencyCapRepo) DeleteFrequencyCap(ctx context.Context, tenantID uuid.UUID, channel, category string) error {
+	if _, ok := m.caps[tenantID.String()+channel+category]; !ok {
+		return db.ErrNoFrequencyCap
+	}
+	delete(m.caps, tenantID.String()+channel+category)
+	return nil
+}
+
+func (m *mockFrequencyCapRepo) GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*db.NotificationCategory, error) {
+	if !m.categories[name] {
+		return nil, db.ErrNoNotificationCategory
+	}
+	return &db.NotificationCategory{TenantID: tenantID, Name: name}, nil
+}
+
+func frequencyCapHTTPRequest(method, tenantID, channel, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/frequency-caps/"+channel, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	rctx.URLParams.Add("channel", channel)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPutFrequencyCap(t *testing.T) {
+	handler := NewFrequencyCapHandler(zap.NewNop(), &mockFrequencyCapRepo{caps: map[string]*db.FrequencyCap{}})
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		channel        string
+		body           string
+		expectedStatus int
+	}{
+		{"unknown channel", "pigeon", `{"max_count": 3, "window_seconds": 3600}`, http.StatusBadRequest},
+		{"no count", "sms", `{"max_count": 0, "window_seconds": 3600}`, http.StatusBadRequest},
+		{"no window", "sms", `{"max_count": 3}`, http.StatusBadRequest},
+		{"window too long", "sms", `{"max_count": 3, "window_seconds": 99999999}`, http.StatusBadRequest},
+		{"unknown action", "sms", `{"max_count": 3, "window_seconds": 3600, "action": "queue"}`, http.StatusBadRequest},
+		{"unknown field", "sms", `{"max_count": 3, "window_seconds": 3600, "priority": "high"}`, http.StatusBadRequest},
+		{"valid drop", "email", `{"max_count": 5, "window_seconds": 86400, "action": "drop"}`, http.StatusOK},
+		{"valid", "sms", `{"max_count": 3, "window_seconds": 3600}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.PutCap(rec, frequencyCapHTTPRequest(http.MethodPut, tenantID, tt.channel, tt.body))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ListCaps(rec, frequencyCapHTTPRequest(http.MethodGet, tenantID, "", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"channel":"sms"`) || !strings.Contains(rec.Body.String(), `"action":"defer"`) {
+		t.Errorf("expected the saved caps with defer as the default action, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeleteCap(rec, frequencyCapHTTPRequest(http.MethodDelete, tenantID, "sms", ""))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DeleteCap(rec, frequencyCapHTTPRequest(http.MethodDelete, tenantID, "sms", ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestFrequencyCap_Category(t *testing.T) {
+	repo := &mockFrequencyCapRepo{caps: map[string]*db.FrequencyCap{}, categories: map[string]bool{"marketing": true}}
+	handler := NewFrequencyCapHandler(zap.NewNop(), repo)
+	tenantID := uuid.New().String()
+	body := `{"max_count": 1, "window_seconds": 86400, "action": "drop"}`
+
+	put := func(category string) *httptest.ResponseRecorder {
+		req := frequencyCapHTTPRequest(http.MethodPut, tenantID, "email", body)
+		req.URL.RawQuery = "category=" + category
+		rec := httptest.NewRecorder()
+		handler.PutCap(rec, req)
+		return rec
+	}
+
+	if rec := put("billing"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a category the tenant hasn't defined, got %d", rec.Code)
+	}
+	if rec := put("bad category"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed category, got %d", rec.Code)
+	}
+	rec := put("marketing")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"category":"marketing"`) {
+		t.Fatalf("expected the marketing cap, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The channel-wide cap is a different cap.
+	rec = httptest.NewRecorder()
+	handler.DeleteCap(rec, frequencyCapHTTPRequest(http.MethodDelete, tenantID, "email", ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the channel-wide cap, got %d", rec.Code)
+	}
+
+	req := frequencyCapHTTPRequest(http.MethodDelete, tenantID, "email", "")
+	req.URL.RawQuery = "category=marketing"
+	rec = httptest.NewRecorder()
+	handler.DeleteCap(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+}
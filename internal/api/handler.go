@@ -6,17 +6,22 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/emailcheck"
+	"github.com/lalithlochan/nimbus/internal/events"
+	"github.com/lalithlochan/nimbus/internal/observ"
 	"github.com/lalithlochan/nimbus/internal/redis"
-	"github.com/lalithlochan/nimbus/internal/sqs"
 )
 
 const (
@@ -27,12 +32,19 @@ const (
 
 const (
 	contentTypeJSON = "application/json"
+	// no-cache still lets clients store the response, but forces an
+	// If-None-Match revalidation before reuse.
+	cacheControlRevalidate = "private, no-cache"
 )
 
 const (
 	headerIdempotencyKey = "Idempotency-Key"
 	headerReplay         = "X-Idempotency-Replayed"
 	headerContentType    = "Content-Type"
+	headerETag           = "ETag"
+	headerIfNoneMatch    = "If-None-Match"
+	headerIfMatch        = "If-Match"
+	headerCacheControl   = "Cache-Control"
 	replayHeaderValue    = "true"
 	logFieldTenantID     = "tenant_id"
 	logFieldChannel      = "channel"
@@ -56,15 +68,28 @@ const (
 	errTitleInvalidUser     = "Invalid user_id"
 	errTitleRequestInFlight = "Request is already being processed"
 	errTitleInternalError   = "Internal server error"
+	errTitleInvalidCorrID   = "Invalid correlation ID"
+	errTitleInvalidMetadata = "Invalid metadata"
+	errTitleInvalidTags     = "Invalid tags"
+	errTitleInvalidSLA      = "Invalid sla_seconds"
+	errTitleInvalidGroupKey = "Invalid group_key"
+	errTitleInvalidCategory = "Invalid category"
+	errTitleInvalidReason   = "Invalid reason"
+	errTitleMessageTooLong  = "Message too long"
+	errTitleInvalidPhone    = "Invalid phone number"
+	errTitleInvalidEmail    = "Invalid email recipient"
+	errTitleWebhookDenied   = "Webhook destination not allowed"
 )
 
 const (
-	errDetailInvalidChannel  = "channel must be " + channelEmail + ", " + channelSMS + ", or " + channelWebhook
+	errDetailInvalidChannel  = "channel must be " + channelEmail + ", " + channelSMS + ", " + channelWebhook + ", or " + channelSlack
 	errDetailInvalidPayload  = "payload must be valid JSON"
 	errDetailMissingFields   = "tenant_id, user_id, and channel are required"
 	errDetailRequestInFlight = "another request with this idempotency key is in progress"
 	errDetailInvalidTenant   = "tenant_id must be a valid UUID"
 	errDetailInvalidUser     = "user_id must be a valid UUID"
+	errDetailInvalidCorrID   = observ.CorrelationIDHeader + " must be at most 128 characters of [A-Za-z0-9-_.:]"
+	errDetailInvalidReason   = "reason must be one of invalid_recipient, provider_outage, timeout, payload_error, unknown"
 )
 
 const (
@@ -73,19 +98,51 @@ const (
 	defaultPageOffset = 0
 	queryParamLimit   = "limit"
 	queryParamOffset  = "offset"
+	queryParamTag     = "tag"
 	channelEmail      = "email"
 	channelSMS        = "sms"
 	channelWebhook    = "webhook"
+	channelSlack      = "slack"
 )
 
+// maxStatusBatch caps PATCH /v1/notifications/status so one request stays a
+// single bounded UPDATE, and maxStatusBatchBytes caps its body before it is
+// decoded: a full batch with short error messages fits with room to spare.
+const (
+	maxStatusBatch      = 1000
+	maxStatusBatchBytes = 1 << 20
+)
+
+// updatableStatuses are the statuses a caller may set through the status
+// endpoints.
+var updatableStatuses = map[string]bool{
+	db.StatusPending:    true,
+	db.StatusProcessing: true,
+	db.StatusSent:       true,
+	db.StatusFailed:     true,
+}
+
 // NotificationRepository defines notification database operations.
 type NotificationRepository interface {
 	CreateNotification(ctx context.Context, notif *db.Notification) error
 	GetNotification(ctx context.Context, id uuid.UUID) (*db.Notification, error)
-	ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*db.Notification, error)
+	GetNotificationForTenant(ctx context.Context, tenantID, id uuid.UUID) (*db.Notification, error)
+	GetNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*db.Notification, error)
+	GetNotificationByProviderMessageIDForTenant(ctx context.Context, tenantID uuid.UUID, provider, providerMessageID string) (*db.Notification, error)
+	ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter db.NotificationFilter, limit, offset int) ([]*db.Notification, error)
+	CountNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter db.NotificationFilter) (int64, error)
 	UpdateNotificationStatus(ctx context.Context, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error
-	ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*db.DeadLetterNotification, error)
+	UpdateNotificationStatusForTenant(ctx context.Context, tenantID, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error
+	UpdateNotificationStatuses(ctx context.Context, updates []db.StatusUpdate) ([]uuid.UUID, error)
+	UpdateNotificationStatusesForTenant(ctx context.Context, tenantID uuid.UUID, updates []db.StatusUpdate) ([]uuid.UUID, error)
+	EditPendingNotification(ctx context.Context, id uuid.UUID, edit db.NotificationEdit) (*db.Notification, error)
+	CancelPendingNotification(ctx context.Context, id uuid.UUID) error
+	ListNotificationEvents(ctx context.Context, notificationID uuid.UUID) ([]*db.NotificationEvent, error)
+	ListNotificationAnnotations(ctx context.Context, notificationID uuid.UUID) ([]*db.NotificationAnnotation, error)
+	ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter, limit, offset int) ([]*db.DeadLetterNotification, error)
+	CountDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter) (int64, error)
 	GetDeadLetter(ctx context.Context, id uuid.UUID) (*db.DeadLetterNotification, error)
+	GetDeadLetterForTenant(ctx context.Context, tenantID, id uuid.UUID) (*db.DeadLetterNotification, error)
 	RetryDeadLetter(ctx context.Context, id uuid.UUID) (*db.Notification, error)
 	DiscardDeadLetter(ctx context.Context, id uuid.UUID) error
 }
@@ -96,11 +153,44 @@ type NotificationRequest struct {
 	UserID   string          `json:"user_id"`
 	Channel  string          `json:"channel"`
 	Payload  json.RawMessage `json:"payload"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+	// SLASeconds is an optional delivery deadline, in seconds from now.
+	SLASeconds *int `json:"sla_seconds,omitempty"`
+	// GroupKey collapses this notification into an earlier delivery with
+	// the same key to the same user and channel (see
+	// db.Notification.GroupKey).
+	GroupKey string `json:"group_key,omitempty"`
+	// Category is one of the tenant's categories (see
+	// db.Notification.Category), such as billing or marketing.
+	Category string `json:"category,omitempty"`
+	// SendAt schedules the notification: the worker won't send it before
+	// this time (it is stored as next_retry_at). A past time sends it at
+	// once.
+	SendAt *time.Time `json:"send_at,omitempty"`
 }
 
-// NotificationResponse is returned after creating a notification.
+// NotificationResponse is returned after creating a notification: the
+// notification as GET /v1/notifications/{id} returns it, links to it and
+// its timeline, and the create-time checks of its recipient. An idempotent
+// replay whose notification can't be read carries only the ID and links.
 type NotificationResponse struct {
-	ID string `json:"id"`
+	*db.Notification
+	ID    string              `json:"id"`
+	Links *NotificationLinks  `json:"links,omitempty"`
+	SMS   *SMSEstimate        `json:"sms,omitempty"`
+	Email *emailcheck.Verdict `json:"email,omitempty"`
+}
+
+// NotificationLinks are the URLs of a created notification's resources.
+type NotificationLinks struct {
+	Self     string `json:"self"`
+	Timeline string `json:"timeline"`
+}
+
+func notificationLinks(id string) *NotificationLinks {
+	self := "/v1/notifications/" + id
+	return &NotificationLinks{Self: self, Timeline: self + "/timeline"}
 }
 
 // ErrorResponse represents an error in problem+json format.
@@ -115,16 +205,41 @@ type ErrorResponse struct {
 type Handler struct {
 	repo        NotificationRepository    // 16 bytes (interface = 2 pointers)
 	idempotency *redis.IdempotencyService // 8 bytes
-	producer    *sqs.Producer             // 8 bytes
+	producer    Enqueuer                  // 16 bytes
 	logger      *zap.Logger               // 8 bytes
+	smsPolicy   SMSPolicy
+	emailPolicy EmailPolicy
+	settings    ChannelSettingsRepository // optional; tenant defaults for validation
+	events      events.Emitter            // optional; lifecycle events
+	preflight   PreflightRepository       // optional; delivery controls for validate
+	categories  CategoryLookup            // optional; tenant taxonomies for category
+	dlqExporter DLQExporter               // optional; POST /v1/dlq/export
+
+	backpressure BackpressurePolicy // optional; load shedding on create
+
+	idempotencyPolicy IdempotencyPolicy // optional; tenants that require Idempotency-Key
+
+	maskMode MaskMode // payloads as read-only keys see them
+}
+
+// pluginChannels are the channels served by enabled sender plugins,
+// accepted alongside the built-in ones.
+var pluginChannels = map[string]bool{}
+
+// RegisterChannels makes the API accept notifications on channels served
+// by a sender plugin. Call it at startup, before serving.
+func RegisterChannels(channels ...string) {
+	for _, channel := range channels {
+		pluginChannels[channel] = true
+	}
 }
 
 func isValidChannel(channel string) bool {
 	switch channel {
-	case channelEmail, channelSMS, channelWebhook:
+	case channelEmail, channelSMS, channelWebhook, channelSlack:
 		return true
 	default:
-		return false
+		return pluginChannels[channel]
 	}
 }
 
@@ -148,8 +263,14 @@ func NewHandlerWithIdempotency(logger *zap.Logger, repo NotificationRepository,
 	}
 }
 
+// Enqueuer announces a newly created notification to queue consumers:
+// *sqs.Producer directly, or *sns.Publisher in fan-out mode.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, notif *db.Notification) (string, error)
+}
+
 // NewHandlerWithSQS creates a handler with SQS producer support.
-func NewHandlerWithSQS(logger *zap.Logger, repo NotificationRepository, idempotency *redis.IdempotencyService, producer *sqs.Producer) *Handler {
+func NewHandlerWithSQS(logger *zap.Logger, repo NotificationRepository, idempotency *redis.IdempotencyService, producer Enqueuer) *Handler {
 	return &Handler{
 		logger:      logger,
 		repo:        repo,
@@ -158,13 +279,41 @@ func NewHandlerWithSQS(logger *zap.Logger, repo NotificationRepository, idempote
 	}
 }
 
+// SetEvents makes create emit a notification.created lifecycle event.
+func (h *Handler) SetEvents(emitter events.Emitter) {
+	h.events = emitter
+}
+
 // generateContentHash creates a SHA256 hash from the notification request content.
 func generateContentHash(req NotificationRequest) string {
 	content := req.TenantID + contentHashSeparator + req.UserID + contentHashSeparator + req.Channel + contentHashSeparator + string(req.Payload)
+	// Only folded in when present, so keys for requests without them are
+	// unchanged from before metadata/tags existed.
+	if len(req.Metadata) > 0 || len(req.Tags) > 0 {
+		content += contentHashSeparator + string(req.Metadata) + contentHashSeparator + strings.Join(req.Tags, ",")
+	}
+	if req.SendAt != nil {
+		content += contentHashSeparator + req.SendAt.UTC().Format(time.RFC3339Nano)
+	}
+	if req.Category != "" {
+		content += contentHashSeparator + req.Category
+	}
 	hash := sha256.Sum256([]byte(content))
 	return autoIdempotencyPrefix + hex.EncodeToString(hash[:contentHashBytes])
 }
 
+// correlationIDFromRequest accepts the caller's correlation ID so their trace
+// continues through us, or starts one here. It is stored on the row and
+// follows the notification into SQS, provider requests and worker logs.
+// ok is false when the caller sent an ID we refuse to propagate.
+func correlationIDFromRequest(r *http.Request) (id string, ok bool) {
+	id = r.Header.Get(observ.CorrelationIDHeader)
+	if id == "" {
+		return observ.NewCorrelationID(), true
+	}
+	return id, observ.ValidCorrelationID(id)
+}
+
 // CreateNotification handles POST /v1/notifications.
 func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -172,6 +321,12 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 	idempotencyKey := r.Header.Get(headerIdempotencyKey)
 	clientProvidedKey := idempotencyKey != ""
 
+	correlationID, ok := correlationIDFromRequest(r)
+	if !ok {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidCorrID, errDetailInvalidCorrID)
+		return
+	}
+
 	var req NotificationRequest
 
 	dec := json.NewDecoder(r.Body)
@@ -197,11 +352,49 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateMetadata(req.Metadata); err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidMetadata, err.Error())
+		return
+	}
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTags, err.Error())
+		return
+	}
+
+	if err := validateSLA(req.SLASeconds); err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidSLA, err.Error())
+		return
+	}
+
+	if err := validateGroupKey(req.GroupKey); err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidGroupKey, err.Error())
+		return
+	}
+
+	if err := validateCategory(req.Category); err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidCategory, err.Error())
+		return
+	}
+
+	smsEstimate, err := h.estimateSMS(req.Channel, req.Payload)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMessageTooLong, err.Error())
+		return
+	}
+
 	tenantID, err := uuid.Parse(req.TenantID)
 	if err != nil {
 		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTenant, errDetailInvalidTenant)
 		return
 	}
+	ctx = observ.With(ctx, h.logger, zap.String(observ.FieldTenantID, req.TenantID))
+
+	if !clientProvidedKey && h.requiresIdempotencyKey(ctx, tenantID) {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleIdempotencyKeyRequired, errDetailIdempotencyKeyRequired)
+		return
+	}
 
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
@@ -209,17 +402,55 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Normalized before hashing so "+1 415..." and "+1415..." dedupe.
+	req.Payload, err = h.normalizeSMSRecipient(ctx, tenantID, req.Channel, req.Payload)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidPhone, err.Error())
+		return
+	}
+
+	emailVerdict, err := h.checkEmailRecipient(ctx, req.Channel, req.Payload)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidEmail, err.Error())
+		return
+	}
+
+	if err := h.checkWebhookDestination(ctx, tenantID, req.Channel, req.Payload); err != nil {
+		if errors.Is(err, errWebhookSettingsUnavailable) {
+			h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, errTitleCreateFailed, "")
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleWebhookDenied, err.Error())
+		return
+	}
+
+	if err := h.checkCategory(ctx, tenantID, req.Category); err != nil {
+		if errors.Is(err, errCategoriesUnavailable) {
+			h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, errTitleCreateFailed, "")
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidCategory, err.Error())
+		return
+	}
+
+	if status, reason, shed := h.shed(ctx, w, req.Channel, tags); shed {
+		h.writeError(w, status, errTypeOverloaded, errTitleOverloaded, reason)
+		return
+	}
+
 	if idempotencyKey == "" && h.idempotency != nil {
 		idempotencyKey = generateContentHash(req)
-		h.logger.Debug("auto-generated idempotency key",
+		observ.Logger(ctx, h.logger).Debug("auto-generated idempotency key",
 			zap.String(logFieldIdempotency, idempotencyKey),
-			zap.String(logFieldTenantID, req.TenantID),
 			zap.String(logFieldChannel, req.Channel),
 		)
 	}
 
+	var reservation string
 	if idempotencyKey != "" && h.idempotency != nil {
-		cachedResult, err := h.idempotency.CheckOrReserve(ctx, req.TenantID, idempotencyKey)
+		var cachedResult *redis.IdempotencyResult
+		var err error
+		cachedResult, reservation, err = h.idempotency.CheckOrReserve(ctx, req.TenantID, idempotencyKey)
 		if err != nil {
 			if errors.Is(err, redis.ErrDuplicateRequest) {
 				h.writeError(w, http.StatusConflict, errTypeDuplicateRequest,
@@ -227,14 +458,19 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 					errDetailRequestInFlight)
 				return
 			}
-			h.logger.Warn("idempotency check failed",
+			observ.Logger(ctx, h.logger).Warn("idempotency check failed",
 				zap.Error(err),
-				zap.String(logFieldTenantID, req.TenantID),
 				zap.String(logFieldIdempotency, idempotencyKey),
 			)
 		} else if cachedResult != nil {
-			resp := NotificationResponse{ID: cachedResult.NotificationID}
+			resp := NotificationResponse{ID: cachedResult.NotificationID, Links: notificationLinks(cachedResult.NotificationID)}
+			if id, err := uuid.Parse(cachedResult.NotificationID); err == nil {
+				if notif, err := h.getNotification(ctx, id); err == nil {
+					resp.Notification = notif
+				}
+			}
 			w.Header().Set(headerContentType, contentTypeJSON)
+			w.Header().Set("Location", resp.Links.Self)
 			w.Header().Set(headerReplay, replayHeaderValue)
 			w.WriteHeader(cachedResult.StatusCode)
 			_ = json.NewEncoder(w).Encode(resp)
@@ -243,40 +479,78 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 	}
 
 	notif := &db.Notification{
-		ID:       uuid.New(),
-		TenantID: tenantID,
-		UserID:   userID,
-		Channel:  req.Channel,
-		Payload:  req.Payload,
-		Status:   db.StatusPending,
-		Attempt:  initialAttempt,
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		UserID:        userID,
+		Channel:       req.Channel,
+		Payload:       req.Payload,
+		Status:        db.StatusPending,
+		Attempt:       initialAttempt,
+		CorrelationID: correlationID,
+		Metadata:      req.Metadata,
+		Tags:          tags,
+		SLASeconds:    req.SLASeconds,
+		GroupKey:      req.GroupKey,
+		Category:      req.Category,
+		NextRetryAt:   req.SendAt,
+	}
+
+	if err := h.persistNotification(ctx, notif, req.TenantID, idempotencyKey, reservation, clientProvidedKey); err != nil {
+		h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, errTitleCreateFailed, "")
+		return
+	}
+
+	resp := NotificationResponse{
+		Notification: notif,
+		ID:           notif.ID.String(),
+		Links:        notificationLinks(notif.ID.String()),
+		SMS:          smsEstimate,
+		Email:        emailVerdict,
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", resp.Links.Self)
+	w.Header().Set(observ.CorrelationIDHeader, correlationID)
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// persistNotification writes notif, records the idempotency result under
+// tenantKey and best-effort enqueues it to SQS. It is shared by the v1 and v2
+// create handlers; the only error it returns is the database write failing,
+// in which case the idempotency reservation, if the request holds one, has
+// already been released.
+func (h *Handler) persistNotification(ctx context.Context, notif *db.Notification, tenantKey, idempotencyKey, reservation string, clientProvidedKey bool) error {
+	ctx = observ.With(ctx, h.logger,
+		zap.String(observ.FieldNotificationID, notif.ID.String()),
+		zap.String(observ.FieldCorrelationID, notif.CorrelationID),
+	)
+	logger := observ.Logger(ctx, h.logger)
+
 	if err := h.repo.CreateNotification(ctx, notif); err != nil {
-		h.logger.Error("failed to create notification",
+		logger.Error("failed to create notification",
 			zap.Error(err),
-			zap.String("tenant_id", req.TenantID),
-			zap.String("channel", req.Channel),
+			zap.String("channel", notif.Channel),
 		)
 		// The request failed AFTER we reserved the idempotency key. Release the
 		// reservation so a retry isn't rejected with 409 for the next 5 minutes.
 		// (Release is a no-op if a result was already stored, so it's safe here.)
-		if idempotencyKey != "" && h.idempotency != nil {
-			if relErr := h.idempotency.Release(ctx, req.TenantID, idempotencyKey); relErr != nil {
-				h.logger.Warn("failed to release idempotency reservation",
+		if reservation != "" && h.idempotency != nil {
+			if relErr := h.idempotency.Release(ctx, tenantKey, idempotencyKey, reservation); relErr != nil {
+				logger.Warn("failed to release idempotency reservation",
 					zap.Error(relErr),
 					zap.String("idempotency_key", idempotencyKey),
 				)
 			}
 		}
-		h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, errTitleCreateFailed, "")
-		return
+		return err
+	}
+	if h.events != nil {
+		h.events.Emit(events.New(events.TypeCreated, notif))
 	}
 
-	h.logger.Info("notification created",
-		zap.String("id", notif.ID.String()),
-		zap.String("tenant_id", req.TenantID),
-		zap.String("channel", req.Channel),
+	logger.Info("notification created",
+		zap.String("channel", notif.Channel),
 	)
 
 	if idempotencyKey != "" && h.idempotency != nil {
@@ -288,8 +562,8 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		if clientProvidedKey {
 			ttl = redis.IdempotencyTTLExact
 		}
-		if err := h.idempotency.Store(ctx, req.TenantID, idempotencyKey, result, ttl); err != nil {
-			h.logger.Warn("failed to store idempotency result",
+		if err := h.idempotency.Store(ctx, tenantKey, idempotencyKey, result, ttl); err != nil {
+			logger.Warn("failed to store idempotency result",
 				zap.Error(err),
 				zap.String("idempotency_key", idempotencyKey),
 			)
@@ -302,27 +576,26 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 	// momentarily unavailable we log and still return 201 — the notification will
 	// be delivered by the DB-poll path. Failing the request here would be wrong:
 	// the client would retry, but the original is already durably queued.
-	if h.producer != nil {
+	// Scheduled notifications are left to the DB poll, which picks them up
+	// once they are due.
+	if h.producer != nil && !scheduled(notif) {
 		if msgID, err := h.producer.Enqueue(ctx, notif); err != nil {
-			h.logger.Warn("sqs enqueue failed; relying on DB-poll delivery",
+			logger.Warn("sqs enqueue failed; relying on DB-poll delivery",
 				zap.Error(err),
-				zap.String("notification_id", notif.ID.String()),
 			)
 		} else {
-			h.logger.Info("notification enqueued to sqs",
-				zap.String("notification_id", notif.ID.String()),
+			logger.Info("notification enqueued to sqs",
 				zap.String("sqs_message_id", msgID),
 			)
 		}
 	}
 
-	resp := NotificationResponse{
-		ID: notif.ID.String(),
-	}
+	return nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(resp)
+// scheduled reports whether notif is to be sent later rather than now.
+func scheduled(notif *db.Notification) bool {
+	return notif.NextRetryAt != nil && notif.NextRetryAt.After(time.Now())
 }
 
 // GetNotification handles GET /v1/notifications/{id}
@@ -339,13 +612,19 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch from database
-	notif, err := h.repo.GetNotification(ctx, notifID)
+	notif, err := h.getNotification(ctx, notifID)
 	if err != nil {
-		h.logger.Error("failed to get notification",
-			zap.Error(err),
-			zap.String("id", idStr),
-		)
-		h.writeError(w, http.StatusNotFound, "not_found", "Notification not found", "")
+		h.writeLookupError(w, err, "Notification not found", "Failed to get notification", zap.String("id", idStr))
+		return
+	}
+
+	// Pollers re-fetch status often; let them revalidate with If-None-Match
+	// and skip the body when nothing changed.
+	etag := notificationETag(notif)
+	w.Header().Set(headerETag, etag)
+	w.Header().Set(headerCacheControl, cacheControlRevalidate)
+	if etagMatches(r.Header.Get(headerIfNoneMatch), etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -357,10 +636,125 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(notif)
+	_ = json.NewEncoder(w).Encode(maskNotification(r, notif, h.maskMode))
 }
 
-// ListNotifications handles GET /v1/notifications?tenant_id=xxx&limit=20&offset=0
+// GetNotificationTimeline handles GET /v1/notifications/{id}/timeline, the
+// notification's state transitions in the order they happened.
+func (h *Handler) GetNotificationTimeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	notifID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid notification ID", "ID must be a valid UUID")
+		return
+	}
+
+	// Resolve the notification first so another tenant's ID is a 404, not
+	// an empty timeline.
+	notif, err := h.getNotification(ctx, notifID)
+	if err != nil {
+		h.writeLookupError(w, err, "Notification not found", "Failed to get notification", zap.String("id", idStr))
+		return
+	}
+
+	timeline, err := h.repo.ListNotificationEvents(ctx, notifID)
+	if err != nil {
+		h.logger.Error("failed to list notification events",
+			zap.Error(err),
+			zap.String("id", idStr),
+		)
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to load timeline", "")
+		return
+	}
+	if timeline == nil {
+		timeline = []*db.NotificationEvent{}
+	}
+
+	annotations, err := h.repo.ListNotificationAnnotations(ctx, notifID)
+	if err != nil {
+		h.logger.Error("failed to list notification annotations",
+			zap.Error(err),
+			zap.String("id", idStr),
+		)
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to load timeline", "")
+		return
+	}
+	if annotations == nil {
+		annotations = []*db.NotificationAnnotation{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"notification_id": notif.ID,
+		"status":          notif.Status,
+		"data":            timeline,
+		"count":           len(timeline),
+		"annotations":     annotations,
+	})
+}
+
+// GetNotificationByProviderID handles
+// GET /v1/notifications/by-provider-id/{id}?provider=ses, mapping a
+// provider's message ID (from a bounce webhook or a support ticket) back to
+// the notification. provider is optional and narrows the match.
+func (h *Handler) GetNotificationByProviderID(w http.ResponseWriter, r *http.Request) {
+	messageID := chi.URLParam(r, "id")
+	if messageID == "" || len(messageID) > 256 {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid provider message ID",
+			"provider message ID must be 1-256 characters")
+		return
+	}
+	provider := r.URL.Query().Get("provider")
+
+	notif, err := h.getNotificationByProviderMessageID(r.Context(), provider, messageID)
+	if err != nil {
+		h.writeLookupError(w, err, "Notification not found", "Failed to get notification",
+			zap.String("provider", provider),
+			zap.String("provider_message_id", messageID),
+		)
+		return
+	}
+
+	w.Header().Set(headerETag, notificationETag(notif))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(maskNotification(r, notif, h.maskMode))
+}
+
+// notificationETag derives a weak validator from the row's version. The
+// updated_at trigger bumps the timestamp on every UPDATE; status and attempt
+// are folded in as well so two writes within the same microsecond still
+// produce different tags.
+func notificationETag(n *db.Notification) string {
+	version := n.ID.String() + contentHashSeparator +
+		strconv.FormatInt(n.UpdatedAt.UnixMicro(), 10) + contentHashSeparator +
+		n.Status + contentHashSeparator + strconv.Itoa(n.Attempt)
+	sum := sha256.Sum256([]byte(version))
+	return `W/"` + hex.EncodeToString(sum[:contentHashBytes]) + `"`
+}
+
+// etagMatches implements the weak comparison If-None-Match uses: the header
+// may list several tags or "*", and the W/ prefix is ignored on both sides.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ListNotifications handles GET /v1/notifications?tenant_id=xxx&limit=20&offset=0&tag=yyy&sort=status&order=asc&include_total=true
 func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -393,8 +787,20 @@ func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Fetch from database
-	notifications, err := h.repo.ListNotificationsByTenant(ctx, tenantID, limit, offset)
+	filter := db.NotificationFilter{Tag: r.URL.Query().Get(queryParamTag)}
+	if filter.Tag != "" && !isValidTag(filter.Tag) {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid tag", "tag must be 1-64 characters of [A-Za-z0-9-_.:]")
+		return
+	}
+	listSort, param, detail := parseListSort(r)
+	if detail != "" {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid "+param, detail)
+		return
+	}
+	filter.Sort = listSort
+
+	// Fetch one row past the page to learn whether there is another
+	notifications, err := h.repo.ListNotificationsByTenant(ctx, tenantID, filter, limit+1, offset)
 	if err != nil {
 		h.logger.Error("failed to list notifications",
 			zap.Error(err),
@@ -403,6 +809,30 @@ func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list notifications", "")
 		return
 	}
+	notifications, hasMore := trimPage(notifications, limit)
+	for i, n := range notifications {
+		notifications[i] = maskNotification(r, n, h.maskMode)
+	}
+
+	resp := map[string]interface{}{
+		"data":     notifications,
+		"limit":    limit,
+		"offset":   offset,
+		"count":    len(notifications),
+		"has_more": hasMore,
+	}
+	if includeTotal(r) {
+		total, err := h.repo.CountNotificationsByTenant(ctx, tenantID, filter)
+		if err != nil {
+			h.logger.Error("failed to count notifications",
+				zap.Error(err),
+				zap.String("tenant_id", tenantIDStr),
+			)
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to count notifications", "")
+			return
+		}
+		resp["total"] = total
+	}
 
 	h.logger.Info("notifications listed",
 		zap.String("tenant_id", tenantIDStr),
@@ -413,12 +843,7 @@ func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"data":   notifications,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(notifications),
-	})
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // UpdateNotificationStatus handles PATCH /v1/notifications/{id}/status
@@ -446,14 +871,7 @@ func (h *Handler) UpdateNotificationStatus(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Validate status
-	validStatuses := map[string]bool{
-		db.StatusPending:    true,
-		db.StatusProcessing: true,
-		db.StatusSent:       true,
-		db.StatusFailed:     true,
-	}
-
-	if !validStatuses[req.Status] {
+	if !updatableStatuses[req.Status] {
 		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid status",
 			"status must be one of: pending, processing, sent, failed")
 		return
@@ -467,7 +885,11 @@ func (h *Handler) UpdateNotificationStatus(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Update in database
-	err = h.repo.UpdateNotificationStatus(ctx, notifID, req.Status, req.Attempt, req.Error, nil)
+	err = h.updateNotificationStatus(ctx, notifID, req.Status, req.Attempt, req.Error)
+	if errors.Is(err, db.ErrNotFound) {
+		h.writeError(w, http.StatusNotFound, errTypeNotFound, "Notification not found", "")
+		return
+	}
 	if err != nil {
 		h.logger.Error("failed to update notification status",
 			zap.Error(err),
@@ -492,7 +914,288 @@ func (h *Handler) UpdateNotificationStatus(w http.ResponseWriter, r *http.Reques
 	})
 }
 
-// ListDeadLetterQueue handles GET /v1/dlq?tenant_id=xxx&limit=20&offset=0
+// EditNotification handles PATCH /v1/notifications/{id}, which fixes the
+// payload or send time of a notification before it is dispatched. Callers
+// must send the ETag from GET /v1/notifications/{id} as If-Match, so an edit
+// based on a stale read fails with 412 instead of overwriting a newer change.
+// Only pending notifications can be edited; every edit is recorded in
+// notification_edits.
+func (h *Handler) EditNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	notifID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid notification ID", "ID must be a valid UUID")
+		return
+	}
+
+	ifMatch := r.Header.Get(headerIfMatch)
+	if ifMatch == "" {
+		h.writeError(w, http.StatusPreconditionRequired, "precondition_required", "If-Match required",
+			"send the ETag from GET /v1/notifications/{id} as If-Match")
+		return
+	}
+
+	var req struct {
+		Payload json.RawMessage `json:"payload,omitempty"`
+		SendAt  json.RawMessage `json:"send_at,omitempty"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+	if req.Payload == nil && req.SendAt == nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMissingFields, "payload or send_at is required")
+		return
+	}
+
+	notif, err := h.getNotification(ctx, notifID)
+	if err != nil {
+		h.writeLookupError(w, err, "Notification not found", "Failed to get notification", zap.String("id", idStr))
+		return
+	}
+	ctx = observ.With(ctx, h.logger,
+		zap.String(observ.FieldNotificationID, idStr),
+		zap.String(observ.FieldTenantID, notif.TenantID.String()),
+	)
+
+	if !etagMatches(ifMatch, notificationETag(notif)) {
+		h.writeError(w, http.StatusPreconditionFailed, "precondition_failed", "Notification changed",
+			"the notification was modified since it was read; fetch it again and retry")
+		return
+	}
+	if notif.Status != db.StatusPending {
+		h.writeError(w, http.StatusConflict, "not_editable", "Notification not editable",
+			"only pending notifications can be edited, this one is "+notif.Status)
+		return
+	}
+
+	edit := db.NotificationEdit{
+		UpdatedAt: notif.UpdatedAt,
+		SendAt:    notif.NextRetryAt,
+		RequestID: middleware.GetReqID(ctx),
+		TenantID:  notif.TenantID,
+	}
+
+	if req.SendAt != nil {
+		edit.SendAt = nil
+		if string(req.SendAt) != "null" {
+			var sendAt time.Time
+			if err := json.Unmarshal(req.SendAt, &sendAt); err != nil {
+				h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid send_at",
+					"send_at must be an RFC 3339 timestamp or null")
+				return
+			}
+			edit.SendAt = &sendAt
+		}
+	}
+
+	// A new payload goes through the same checks as one sent to create.
+	if req.Payload != nil {
+		if !json.Valid(req.Payload) {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidPayload, errDetailInvalidPayload)
+			return
+		}
+		if _, err := h.estimateSMS(notif.Channel, req.Payload); err != nil {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMessageTooLong, err.Error())
+			return
+		}
+		payload, err := h.normalizeSMSRecipient(ctx, notif.TenantID, notif.Channel, req.Payload)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidPhone, err.Error())
+			return
+		}
+		if _, err := h.checkEmailRecipient(ctx, notif.Channel, payload); err != nil {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidEmail, err.Error())
+			return
+		}
+		if err := h.checkWebhookDestination(ctx, notif.TenantID, notif.Channel, payload); err != nil {
+			if errors.Is(err, errWebhookSettingsUnavailable) {
+				h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to update notification", "")
+				return
+			}
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleWebhookDenied, err.Error())
+			return
+		}
+		edit.Payload = payload
+	}
+
+	updated, err := h.repo.EditPendingNotification(ctx, notifID, edit)
+	if errors.Is(err, db.ErrNotificationNotEditable) {
+		// Claimed by the worker or edited by someone else since the read.
+		h.writeError(w, http.StatusPreconditionFailed, "precondition_failed", "Notification changed",
+			"the notification was modified or picked up for sending; fetch it again")
+		return
+	}
+	if err != nil {
+		observ.Logger(ctx, h.logger).Error("failed to edit notification", zap.Error(err))
+		h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to update notification", "")
+		return
+	}
+
+	w.Header().Set(headerETag, notificationETag(updated))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(updated)
+}
+
+// CancelNotification handles POST /v1/notifications/{id}/cancel, which
+// stops a notification that hasn't been sent, typically one scheduled with
+// send_at. It ends 'failed' with the error "cancelled". Once the worker has
+// picked a notification up it can no longer be cancelled: 409.
+func (h *Handler) CancelNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	notifID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid notification ID", "ID must be a valid UUID")
+		return
+	}
+
+	// Resolved first so another tenant's notification is a 404.
+	notif, err := h.getNotification(ctx, notifID)
+	if err != nil {
+		h.writeLookupError(w, err, "Notification not found", "Failed to get notification", zap.String("id", idStr))
+		return
+	}
+	ctx = observ.With(ctx, h.logger,
+		zap.String(observ.FieldNotificationID, idStr),
+		zap.String(observ.FieldTenantID, notif.TenantID.String()),
+	)
+
+	err = h.repo.CancelPendingNotification(ctx, notifID)
+	if errors.Is(err, db.ErrNotificationNotCancellable) {
+		h.writeError(w, http.StatusConflict, "not_cancellable", "Notification not cancellable",
+			"only pending or held notifications can be cancelled; it may already be sending or sent")
+		return
+	}
+	if err != nil {
+		observ.Logger(ctx, h.logger).Error("failed to cancel notification", zap.Error(err))
+		h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to cancel notification", "")
+		return
+	}
+	observ.Logger(ctx, h.logger).Info("notification cancelled")
+
+	cancelled, err := h.getNotification(ctx, notifID)
+	if err != nil {
+		// Cancelled all the same; report what we know.
+		errMsg := db.CancelledError
+		notif.Status, notif.ErrorMessage, notif.NextRetryAt = db.StatusFailed, &errMsg, nil
+		cancelled = notif
+	}
+
+	w.Header().Set(headerETag, notificationETag(cancelled))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(cancelled)
+}
+
+// statusUpdateRequest is one entry of PATCH /v1/notifications/status.
+type statusUpdateRequest struct {
+	Error   *string `json:"error,omitempty"`
+	ID      string  `json:"id"`
+	Status  string  `json:"status"`
+	Attempt int     `json:"attempt"`
+}
+
+// BatchUpdateNotificationStatus handles PATCH /v1/notifications/status, for
+// callers such as an external delivery processor that report outcomes for
+// many notifications at once. The batch is validated as a whole, so one bad
+// entry rejects the request before anything is written; IDs that don't exist,
+// or belong to another tenant, are reported in not_found rather than failing
+// the batch.
+func (h *Handler) BatchUpdateNotificationStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req struct {
+		Updates []statusUpdateRequest `json:"updates"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStatusBatchBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, errTypeInvalidRequest, "Request body too large",
+				fmt.Sprintf("body must be at most %d KB", maxStatusBatchBytes>>10))
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	if len(req.Updates) == 0 || len(req.Updates) > maxStatusBatch {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid batch size",
+			fmt.Sprintf("updates must contain between 1 and %d entries", maxStatusBatch))
+		return
+	}
+
+	updates := make([]db.StatusUpdate, len(req.Updates))
+	seen := make(map[uuid.UUID]bool, len(req.Updates))
+	for i, u := range req.Updates {
+		id, err := uuid.Parse(u.ID)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid notification ID",
+				fmt.Sprintf("updates[%d].id must be a valid UUID", i))
+			return
+		}
+		if seen[id] {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Duplicate notification ID",
+				fmt.Sprintf("updates[%d].id appears more than once", i))
+			return
+		}
+		seen[id] = true
+
+		if !updatableStatuses[u.Status] {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid status",
+				fmt.Sprintf("updates[%d].status must be one of: pending, processing, sent, failed", i))
+			return
+		}
+		if u.Attempt < 0 {
+			h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid attempt",
+				fmt.Sprintf("updates[%d].attempt must be >= 0", i))
+			return
+		}
+
+		updates[i] = db.StatusUpdate{ID: id, Status: u.Status, Attempt: u.Attempt, Error: u.Error}
+	}
+
+	updated, err := h.updateNotificationStatuses(ctx, updates)
+	if err != nil {
+		h.logger.Error("failed to batch update notification status",
+			zap.Error(err),
+			zap.Int("count", len(updates)),
+		)
+		h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to update notifications", "")
+		return
+	}
+
+	matched := make(map[uuid.UUID]bool, len(updated))
+	for _, id := range updated {
+		matched[id] = true
+	}
+	notFound := []string{}
+	for _, u := range updates {
+		if !matched[u.ID] {
+			notFound = append(notFound, u.ID.String())
+		}
+	}
+
+	h.logger.Info("notification statuses updated",
+		zap.Int("requested", len(updates)),
+		zap.Int("updated", len(updated)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"updated":   len(updated),
+		"not_found": notFound,
+	})
+}
+
+// ListDeadLetterQueue handles GET /v1/dlq?tenant_id=xxx&reason=timeout&status=pending&limit=20&offset=0&sort=updated_at&include_total=true
 func (h *Handler) ListDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -525,8 +1228,20 @@ func (h *Handler) ListDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Fetch from database
-	dlqItems, err := h.repo.ListDeadLetterByTenant(ctx, tenantID, limit, offset)
+	filter, title, detail := parseDeadLetterFilter(r)
+	if title != "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", title, detail)
+		return
+	}
+	listSort, param, detail := parseListSort(r)
+	if detail != "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid "+param, detail)
+		return
+	}
+	filter.Sort = listSort
+
+	// Fetch one row past the page to learn whether there is another
+	dlqItems, err := h.repo.ListDeadLetterByTenant(ctx, tenantID, filter, limit+1, offset)
 	if err != nil {
 		h.logger.Error("failed to list dead letter queue",
 			zap.Error(err),
@@ -535,6 +1250,30 @@ func (h *Handler) ListDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list dead letter queue", "")
 		return
 	}
+	dlqItems, hasMore := trimPage(dlqItems, limit)
+	for i, item := range dlqItems {
+		dlqItems[i] = maskDeadLetter(r, item, h.maskMode)
+	}
+
+	resp := map[string]interface{}{
+		"data":     dlqItems,
+		"limit":    limit,
+		"offset":   offset,
+		"count":    len(dlqItems),
+		"has_more": hasMore,
+	}
+	if includeTotal(r) {
+		total, err := h.repo.CountDeadLetterByTenant(ctx, tenantID, filter)
+		if err != nil {
+			h.logger.Error("failed to count dead letter queue",
+				zap.Error(err),
+				zap.String("tenant_id", tenantIDStr),
+			)
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to count dead letter queue", "")
+			return
+		}
+		resp["total"] = total
+	}
 
 	h.logger.Info("dead letter queue listed",
 		zap.String("tenant_id", tenantIDStr),
@@ -543,12 +1282,7 @@ func (h *Handler) ListDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"data":   dlqItems,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(dlqItems),
-	})
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // GetDeadLetterItem handles GET /v1/dlq/{id}
@@ -562,19 +1296,15 @@ func (h *Handler) GetDeadLetterItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dlqItem, err := h.repo.GetDeadLetter(ctx, dlqID)
+	dlqItem, err := h.getDeadLetter(ctx, dlqID)
 	if err != nil {
-		h.logger.Error("failed to get dead letter item",
-			zap.Error(err),
-			zap.String("id", idStr),
-		)
-		h.writeError(w, http.StatusNotFound, "not_found", "Dead letter item not found", "")
+		h.writeLookupError(w, err, "Dead letter item not found", "Failed to get dead letter item", zap.String("id", idStr))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(dlqItem)
+	_ = json.NewEncoder(w).Encode(maskDeadLetter(r, dlqItem, h.maskMode))
 }
 
 // RetryDeadLetterItem handles POST /v1/dlq/{id}/retry
@@ -588,6 +1318,10 @@ func (h *Handler) RetryDeadLetterItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.ownsDeadLetter(ctx, w, dlqID) {
+		return
+	}
+
 	// Retry creates a new notification from the DLQ item
 	newNotif, err := h.repo.RetryDeadLetter(ctx, dlqID)
 	if err != nil {
@@ -624,6 +1358,10 @@ func (h *Handler) DiscardDeadLetterItem(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.ownsDeadLetter(ctx, w, dlqID) {
+		return
+	}
+
 	err = h.repo.DiscardDeadLetter(ctx, dlqID)
 	if err != nil {
 		h.logger.Error("failed to discard dead letter item",
@@ -646,7 +1384,85 @@ func (h *Handler) DiscardDeadLetterItem(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// getNotification loads a notification, scoped to the authenticated tenant
+// when the request has one so another tenant's row is simply not found.
+// Unauthenticated /v1 callers keep the unscoped lookup.
+func (h *Handler) getNotification(ctx context.Context, id uuid.UUID) (*db.Notification, error) {
+	if tenantID, ok := TenantIDFromContext(ctx); ok {
+		return h.repo.GetNotificationForTenant(ctx, tenantID, id)
+	}
+	return h.repo.GetNotification(ctx, id)
+}
+
+// getNotificationByProviderMessageID is getNotification for a provider
+// message ID.
+func (h *Handler) getNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*db.Notification, error) {
+	if tenantID, ok := TenantIDFromContext(ctx); ok {
+		return h.repo.GetNotificationByProviderMessageIDForTenant(ctx, tenantID, provider, providerMessageID)
+	}
+	return h.repo.GetNotificationByProviderMessageID(ctx, provider, providerMessageID)
+}
+
+// updateNotificationStatus updates a notification's status, scoped like
+// getNotification; another tenant's notification is not found.
+func (h *Handler) updateNotificationStatus(ctx context.Context, id uuid.UUID, status string, attempt int, errorMsg *string) error {
+	if tenantID, ok := TenantIDFromContext(ctx); ok {
+		return h.repo.UpdateNotificationStatusForTenant(ctx, tenantID, id, status, attempt, errorMsg, nil)
+	}
+	return h.repo.UpdateNotificationStatus(ctx, id, status, attempt, errorMsg, nil)
+}
+
+// updateNotificationStatuses is updateNotificationStatus for a batch;
+// another tenant's IDs are left out of the result.
+func (h *Handler) updateNotificationStatuses(ctx context.Context, updates []db.StatusUpdate) ([]uuid.UUID, error) {
+	if tenantID, ok := TenantIDFromContext(ctx); ok {
+		return h.repo.UpdateNotificationStatusesForTenant(ctx, tenantID, updates)
+	}
+	return h.repo.UpdateNotificationStatuses(ctx, updates)
+}
+
+// getDeadLetter is getNotification for DLQ items.
+func (h *Handler) getDeadLetter(ctx context.Context, id uuid.UUID) (*db.DeadLetterNotification, error) {
+	if tenantID, ok := TenantIDFromContext(ctx); ok {
+		return h.repo.GetDeadLetterForTenant(ctx, tenantID, id)
+	}
+	return h.repo.GetDeadLetter(ctx, id)
+}
+
+// ownsDeadLetter checks, for an authenticated caller, that the DLQ item
+// belongs to its tenant before it is retried or discarded, writing a 404
+// when it doesn't.
+func (h *Handler) ownsDeadLetter(ctx context.Context, w http.ResponseWriter, id uuid.UUID) bool {
+	tenantID, ok := TenantIDFromContext(ctx)
+	if !ok {
+		return true
+	}
+	if _, err := h.repo.GetDeadLetterForTenant(ctx, tenantID, id); err != nil {
+		h.writeLookupError(w, err, "Dead letter item not found", "Failed to get dead letter item", zap.String("id", id.String()))
+		return false
+	}
+	return true
+}
+
+// writeLookupError answers a failed single-row lookup: 404 with notFound
+// when the row doesn't exist or isn't the caller's, 500 with failed when the
+// query itself failed, so an outage isn't reported as a missing row.
+func (h *Handler) writeLookupError(w http.ResponseWriter, err error, notFound, failed string, fields ...zap.Field) {
+	if errors.Is(err, db.ErrNotFound) {
+		h.writeError(w, http.StatusNotFound, "not_found", notFound, "")
+		return
+	}
+	h.logger.Error("lookup failed", append(fields, zap.Error(err))...)
+	h.writeError(w, http.StatusInternalServerError, errTypeDatabaseError, failed, "")
+}
+
 func (h *Handler) writeError(w http.ResponseWriter, status int, errType, title, detail string) {
+	writeProblem(w, status, errType, title, detail)
+}
+
+// writeProblem writes an ErrorResponse in problem+json format. Handlers that
+// don't hang off *Handler use it directly.
+func writeProblem(w http.ResponseWriter, status int, errType, title, detail string) {
 	w.Header().Set("Content-Type", "application/problem+json")
 	w.WriteHeader(status)
 
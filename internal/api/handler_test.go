@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,18 +23,24 @@ import (
 // Common test errors
 var (
 	ErrDatabaseError        = errors.New("database error")
-	ErrNotificationNotFound = errors.New("notification not found")
+	ErrNotificationNotFound = fmt.Errorf("notification %w", db.ErrNotFound)
 )
 
 // MockRepository is a fake database for testing
 type MockRepository struct {
 	notifications map[string]*db.Notification
+	events        map[string][]*db.NotificationEvent
+	annotations   map[string][]*db.NotificationAnnotation
 
 	createCalled bool
 	getCalled    bool
 	listCalled   bool
 	updateCalled bool
 
+	lastFilter db.NotificationFilter
+
+	deadLetters []*db.DeadLetterNotification
+
 	shouldFail bool
 }
 
@@ -39,6 +48,8 @@ type MockRepository struct {
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
 		notifications: make(map[string]*db.Notification),
+		events:        make(map[string][]*db.NotificationEvent),
+		annotations:   make(map[string][]*db.NotificationAnnotation),
 	}
 }
 
@@ -49,6 +60,8 @@ func (m *MockRepository) CreateNotification(ctx context.Context, notif *db.Notif
 		return ErrDatabaseError
 	}
 
+	notif.CreatedAt = time.Now()
+	notif.UpdatedAt = notif.CreatedAt
 	m.notifications[notif.ID.String()] = notif
 	return nil
 }
@@ -68,21 +81,78 @@ func (m *MockRepository) GetNotification(ctx context.Context, id uuid.UUID) (*db
 	return notif, nil
 }
 
-func (m *MockRepository) ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*db.Notification, error) {
+func (m *MockRepository) GetNotificationForTenant(ctx context.Context, tenantID, id uuid.UUID) (*db.Notification, error) {
+	notif, err := m.GetNotification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notif.TenantID != tenantID {
+		return nil, ErrNotificationNotFound
+	}
+	return notif, nil
+}
+
+func (m *MockRepository) GetNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*db.Notification, error) {
+	m.getCalled = true
+
+	if m.shouldFail {
+		return nil, ErrDatabaseError
+	}
+
+	for _, notif := range m.notifications {
+		if notif.ProviderMessageID == providerMessageID && (provider == "" || notif.Provider == provider) {
+			return notif, nil
+		}
+	}
+
+	return nil, ErrNotificationNotFound
+}
+
+func (m *MockRepository) GetNotificationByProviderMessageIDForTenant(ctx context.Context, tenantID uuid.UUID, provider, providerMessageID string) (*db.Notification, error) {
+	notif, err := m.GetNotificationByProviderMessageID(ctx, provider, providerMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if notif.TenantID != tenantID {
+		return nil, ErrNotificationNotFound
+	}
+	return notif, nil
+}
+
+func (m *MockRepository) ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter db.NotificationFilter, limit, offset int) ([]*db.Notification, error) {
 	m.listCalled = true
+	m.lastFilter = filter
 
 	if m.shouldFail {
 		return nil, ErrDatabaseError
 	}
 
+	result := m.filterNotifications(tenantID, filter)
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockRepository) CountNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter db.NotificationFilter) (int64, error) {
+	if m.shouldFail {
+		return 0, ErrDatabaseError
+	}
+	return int64(len(m.filterNotifications(tenantID, filter))), nil
+}
+
+func (m *MockRepository) filterNotifications(tenantID uuid.UUID, filter db.NotificationFilter) []*db.Notification {
 	var result []*db.Notification
 	for _, notif := range m.notifications {
-		if notif.TenantID == tenantID {
+		if notif.TenantID == tenantID && (filter.Tag == "" || slices.Contains(notif.Tags, filter.Tag)) {
 			result = append(result, notif)
 		}
 	}
-
-	return result, nil
+	return result
 }
 
 func (m *MockRepository) UpdateNotificationStatus(ctx context.Context, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error {
@@ -105,25 +175,153 @@ func (m *MockRepository) UpdateNotificationStatus(ctx context.Context, id uuid.U
 	return nil
 }
 
+func (m *MockRepository) UpdateNotificationStatusForTenant(ctx context.Context, tenantID, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error {
+	if notif, exists := m.notifications[id.String()]; exists && notif.TenantID != tenantID {
+		m.updateCalled = true
+		return ErrNotificationNotFound
+	}
+	return m.UpdateNotificationStatus(ctx, id, status, attempt, errorMsg, nextRetryAt)
+}
+
+func (m *MockRepository) UpdateNotificationStatuses(ctx context.Context, updates []db.StatusUpdate) ([]uuid.UUID, error) {
+	m.updateCalled = true
+
+	if m.shouldFail {
+		return nil, ErrDatabaseError
+	}
+
+	var updated []uuid.UUID
+	for _, u := range updates {
+		notif, exists := m.notifications[u.ID.String()]
+		if !exists {
+			continue
+		}
+		notif.Status = u.Status
+		notif.Attempt = u.Attempt
+		notif.ErrorMessage = u.Error
+		updated = append(updated, u.ID)
+	}
+
+	return updated, nil
+}
+
+func (m *MockRepository) UpdateNotificationStatusesForTenant(ctx context.Context, tenantID uuid.UUID, updates []db.StatusUpdate) ([]uuid.UUID, error) {
+	own := make([]db.StatusUpdate, 0, len(updates))
+	for _, u := range updates {
+		if notif, exists := m.notifications[u.ID.String()]; exists && notif.TenantID == tenantID {
+			own = append(own, u)
+		}
+	}
+	return m.UpdateNotificationStatuses(ctx, own)
+}
+
+func (m *MockRepository) EditPendingNotification(ctx context.Context, id uuid.UUID, edit db.NotificationEdit) (*db.Notification, error) {
+	m.updateCalled = true
+
+	if m.shouldFail {
+		return nil, ErrDatabaseError
+	}
+
+	notif, exists := m.notifications[id.String()]
+	if !exists || notif.TenantID != edit.TenantID || notif.Status != db.StatusPending || !notif.UpdatedAt.Equal(edit.UpdatedAt) {
+		return nil, db.ErrNotificationNotEditable
+	}
+
+	if edit.Payload != nil {
+		notif.Payload = edit.Payload
+	}
+	notif.NextRetryAt = edit.SendAt
+	notif.UpdatedAt = notif.UpdatedAt.Add(time.Millisecond)
+
+	return notif, nil
+}
+
+func (m *MockRepository) CancelPendingNotification(ctx context.Context, id uuid.UUID) error {
+	m.updateCalled = true
+
+	if m.shouldFail {
+		return ErrDatabaseError
+	}
+
+	notif, exists := m.notifications[id.String()]
+	if !exists || (notif.Status != db.StatusPending && notif.Status != db.StatusHeld) {
+		return db.ErrNotificationNotCancellable
+	}
+	errMsg := db.CancelledError
+	notif.Status, notif.ErrorMessage, notif.NextRetryAt = db.StatusFailed, &errMsg, nil
+	return nil
+}
+
+func (m *MockRepository) ListNotificationEvents(ctx context.Context, notificationID uuid.UUID) ([]*db.NotificationEvent, error) {
+	if m.shouldFail {
+		return nil, ErrDatabaseError
+	}
+	return m.events[notificationID.String()], nil
+}
+
+func (m *MockRepository) ListNotificationAnnotations(ctx context.Context, notificationID uuid.UUID) ([]*db.NotificationAnnotation, error) {
+	if m.shouldFail {
+		return nil, ErrDatabaseError
+	}
+	return m.annotations[notificationID.String()], nil
+}
+
 // DLQ mock methods for interface compliance
-func (m *MockRepository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*db.DeadLetterNotification, error) {
+func (m *MockRepository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter, limit, offset int) ([]*db.DeadLetterNotification, error) {
 	if m.shouldFail {
 		return nil, ErrDatabaseError
 	}
-	return []*db.DeadLetterNotification{}, nil
+	items := []*db.DeadLetterNotification{}
+	for _, item := range m.deadLetters {
+		if item.TenantID != tenantID ||
+			(filter.Reason != "" && item.Reason != filter.Reason) ||
+			(filter.Status != "" && item.Status != filter.Status) {
+			continue
+		}
+		items = append(items, item)
+	}
+	if offset >= len(items) {
+		return []*db.DeadLetterNotification{}, nil
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (m *MockRepository) CountDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter) (int64, error) {
+	items, err := m.ListDeadLetterByTenant(ctx, tenantID, filter, len(m.deadLetters), 0)
+	return int64(len(items)), err
 }
 
 func (m *MockRepository) GetDeadLetter(ctx context.Context, id uuid.UUID) (*db.DeadLetterNotification, error) {
 	if m.shouldFail {
 		return nil, ErrDatabaseError
 	}
-	return nil, errors.New("not found")
+	return nil, db.ErrNotFound
+}
+
+func (m *MockRepository) GetDeadLetterForTenant(ctx context.Context, tenantID, id uuid.UUID) (*db.DeadLetterNotification, error) {
+	item, err := m.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.TenantID != tenantID {
+		return nil, db.ErrNotFound
+	}
+	return item, nil
 }
 
 func (m *MockRepository) RetryDeadLetter(ctx context.Context, id uuid.UUID) (*db.Notification, error) {
 	if m.shouldFail {
 		return nil, ErrDatabaseError
 	}
+	for _, item := range m.deadLetters {
+		if item.ID == id {
+			item.Status = db.DLQStatusRetried
+		}
+	}
 	return &db.Notification{ID: uuid.New()}, nil
 }
 
@@ -163,6 +361,16 @@ func TestCreateNotification(t *testing.T) {
 				if err != nil {
 					t.Errorf("expected valid UUID, got: %s", resp.ID)
 				}
+				if resp.Notification == nil || resp.Status != "pending" || resp.Channel != "email" || resp.CreatedAt.IsZero() {
+					t.Errorf("expected the created notification in the response, got %+v", resp.Notification)
+				}
+				self := "/v1/notifications/" + resp.ID
+				if resp.Links == nil || resp.Links.Self != self || resp.Links.Timeline != self+"/timeline" {
+					t.Errorf("unexpected links %+v", resp.Links)
+				}
+				if rec.Header().Get("Location") != self {
+					t.Errorf("expected Location %s, got %q", self, rec.Header().Get("Location"))
+				}
 			},
 		},
 		{
@@ -305,6 +513,69 @@ func TestCreateNotification(t *testing.T) {
 	}
 }
 
+func TestCreateNotification_CorrelationID(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{"generated when absent", "", http.StatusCreated},
+		{"accepted from caller", "checkout-svc:req_42", http.StatusCreated},
+		{"rejected when invalid", "bad id\r\nX-Evil: 1", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: "00000000-0000-0000-0000-000000000001",
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "email",
+				Payload:  json.RawMessage(`{"to":"user@example.com"}`),
+			})
+			req := httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set("X-Correlation-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.CreateNotification(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				if mockRepo.createCalled {
+					t.Error("expected no notification to be created")
+				}
+				return
+			}
+
+			var resp NotificationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			stored := mockRepo.notifications[resp.ID]
+			if stored == nil {
+				t.Fatal("expected notification to be stored")
+			}
+
+			got := rec.Header().Get("X-Correlation-ID")
+			if got == "" {
+				t.Fatal("expected X-Correlation-ID on response")
+			}
+			if tt.header != "" && got != tt.header {
+				t.Errorf("expected caller's correlation ID %q, got %q", tt.header, got)
+			}
+			if stored.CorrelationID != got {
+				t.Errorf("stored correlation ID %q does not match response %q", stored.CorrelationID, got)
+			}
+		})
+	}
+}
+
 // TestGetNotification tests the GetNotification handler
 func TestGetNotification(t *testing.T) {
 	tests := []struct {
@@ -369,6 +640,24 @@ func TestGetNotification(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "database error is not a 404",
+			notificationID: "99999999-9999-9999-9999-999999999999",
+			setupMock: func(m *MockRepository) {
+				m.shouldFail = true
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var errResp ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&errResp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+
+				if errResp.Type != errTypeDatabaseError {
+					t.Errorf("expected type %q, got %q", errTypeDatabaseError, errResp.Type)
+				}
+			},
+		},
 		{
 			name:           "invalid UUID format",
 			notificationID: "not-a-valid-uuid",
@@ -425,6 +714,176 @@ func TestGetNotification(t *testing.T) {
 	}
 }
 
+func TestGetNotification_ScopedToAuthenticatedTenant(t *testing.T) {
+	mockRepo := NewMockRepository()
+	id := uuid.New()
+	owner := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	mockRepo.notifications[id.String()] = &db.Notification{ID: id, TenantID: owner, Channel: "email", Status: db.StatusPending}
+	handler := NewHandler(zap.NewNop(), mockRepo)
+
+	tests := []struct {
+		name           string
+		tenantID       *uuid.UUID
+		expectedStatus int
+	}{
+		{"unauthenticated", nil, http.StatusOK},
+		{"owner", &owner, http.StatusOK},
+		{"other tenant", ptrUUID(uuid.New()), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id.String())
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			if tt.tenantID != nil {
+				ctx = context.WithValue(ctx, contextKeyTenantID, *tt.tenantID)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/v1/notifications/"+id.String(), nil).WithContext(ctx)
+
+			rec := httptest.NewRecorder()
+			handler.GetNotification(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func ptrUUID(id uuid.UUID) *uuid.UUID { return &id }
+
+func TestGetNotificationTimeline(t *testing.T) {
+	mockRepo := NewMockRepository()
+	id := uuid.New()
+	owner := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	errMsg := "SES throttled"
+	mockRepo.notifications[id.String()] = &db.Notification{ID: id, TenantID: owner, Channel: "email", Status: db.StatusSent, Attempt: 2}
+	mockRepo.events[id.String()] = []*db.NotificationEvent{
+		{ID: 1, NotificationID: id, ToStatus: db.StatusPending, Actor: db.ActorTenant},
+		{ID: 2, NotificationID: id, FromStatus: db.StatusPending, ToStatus: db.StatusProcessing, Actor: db.ActorSystem},
+		{ID: 3, NotificationID: id, FromStatus: db.StatusProcessing, ToStatus: db.StatusPending, Attempt: 1, Error: &errMsg, Actor: db.ActorSystem},
+		{ID: 4, NotificationID: id, FromStatus: db.StatusPending, ToStatus: db.StatusProcessing, Attempt: 1, Actor: db.ActorSystem},
+		{ID: 5, NotificationID: id, FromStatus: db.StatusProcessing, ToStatus: db.StatusSent, Attempt: 2, Actor: db.ActorSystem},
+	}
+	mockRepo.annotations[id.String()] = []*db.NotificationAnnotation{
+		{ID: 1, NotificationID: id, Provider: "ses", Kind: db.AnnotationKindSend, Data: json.RawMessage(`{"message_tags":{"correlation_id":"req-1"}}`)},
+		{ID: 2, NotificationID: id, Provider: "ses", Kind: db.DeliveryEventDelivery, Data: json.RawMessage(`{"smtp_response":"250 ok"}`)},
+	}
+	handler := NewHandler(zap.NewNop(), mockRepo)
+
+	get := func(idStr string, tenantID *uuid.UUID) *httptest.ResponseRecorder {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", idStr)
+		ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+		if tenantID != nil {
+			ctx = context.WithValue(ctx, contextKeyTenantID, *tenantID)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/v1/notifications/"+idStr+"/timeline", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		handler.GetNotificationTimeline(rec, req)
+		return rec
+	}
+
+	rec := get(id.String(), &owner)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Status      string                       `json:"status"`
+		Data        []*db.NotificationEvent      `json:"data"`
+		Count       int                          `json:"count"`
+		Annotations []*db.NotificationAnnotation `json:"annotations"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != db.StatusSent || resp.Count != 5 || len(resp.Data) != 5 {
+		t.Fatalf("unexpected timeline %+v", resp)
+	}
+	if resp.Data[0].FromStatus != "" || resp.Data[2].Error == nil || *resp.Data[2].Error != errMsg {
+		t.Errorf("expected creation event first and the failed attempt's error, got %+v", resp.Data)
+	}
+	if len(resp.Annotations) != 2 || resp.Annotations[1].Kind != db.DeliveryEventDelivery ||
+		string(resp.Annotations[1].Data) != `{"smtp_response":"250 ok"}` {
+		t.Errorf("unexpected annotations %+v", resp.Annotations)
+	}
+
+	if rec := get(id.String(), ptrUUID(uuid.New())); rec.Code != http.StatusNotFound {
+		t.Errorf("expected another tenant's timeline to be 404, got %d", rec.Code)
+	}
+	if rec := get("not-a-uuid", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad ID, got %d", rec.Code)
+	}
+
+	other := uuid.New()
+	mockRepo.notifications[other.String()] = &db.Notification{ID: other, TenantID: owner, Status: db.StatusPending}
+	rec = get(other.String(), nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"data":[]`) || !strings.Contains(rec.Body.String(), `"annotations":[]`) {
+		t.Errorf("expected an empty list for a notification without events, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetNotification_ETag(t *testing.T) {
+	id := uuid.MustParse("a1b2c3d4-e5f6-4a5b-8c9d-0e1f2a3b4c5d")
+	mockRepo := NewMockRepository()
+	mockRepo.notifications[id.String()] = &db.Notification{
+		ID:        id,
+		TenantID:  uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Channel:   "email",
+		Status:    "pending",
+		UpdatedAt: time.Now(),
+	}
+	handler := NewHandler(zap.NewNop(), mockRepo)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/notifications/"+id.String(), nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rec := httptest.NewRecorder()
+		handler.GetNotification(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", first.Code, etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"exact match", etag, http.StatusNotModified},
+		{"strong form of weak tag", strings.TrimPrefix(etag, "W/"), http.StatusNotModified},
+		{"one of several", `"stale", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"stale tag", `W/"stale"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(tt.ifNoneMatch)
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("expected empty body on 304, got %q", rec.Body.String())
+			}
+		})
+	}
+
+	// A status change bumps updated_at, so the old tag no longer matches.
+	mockRepo.notifications[id.String()].Status = "sent"
+	mockRepo.notifications[id.String()].UpdatedAt = time.Now().Add(time.Second)
+	if rec := get(etag); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 after update, got %d", rec.Code)
+	}
+}
+
 // TestListNotifications tests the ListNotifications handler
 func TestListNotifications(t *testing.T) {
 	tests := []struct {
@@ -482,6 +941,12 @@ func TestListNotifications(t *testing.T) {
 				if len(notifications) != 3 {
 					t.Errorf("expected 3 notifications, got %d", len(notifications))
 				}
+				if resp["has_more"] != false {
+					t.Errorf("expected has_more false on the last page, got %v", resp["has_more"])
+				}
+				if _, ok := resp["total"]; ok {
+					t.Error("expected no total without include_total")
+				}
 
 				// Verify metadata
 				if resp["limit"] != float64(20) {
@@ -494,7 +959,7 @@ func TestListNotifications(t *testing.T) {
 		},
 		{
 			name:        "pagination with limit and offset",
-			queryParams: "tenant_id=00000000-0000-0000-0000-000000000001&limit=2&offset=1",
+			queryParams: "tenant_id=00000000-0000-0000-0000-000000000001&limit=2&offset=1&include_total=true",
 			setupMock: func(m *MockRepository) {
 				tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
 				userID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
@@ -518,14 +983,18 @@ func TestListNotifications(t *testing.T) {
 					t.Fatalf("failed to decode response: %v", err)
 				}
 
-				// Our mock doesn't implement actual pagination,
-				// but we can verify the metadata is set correctly
 				if resp["limit"] != float64(2) {
 					t.Errorf("expected limit 2, got %v", resp["limit"])
 				}
 				if resp["offset"] != float64(1) {
 					t.Errorf("expected offset 1, got %v", resp["offset"])
 				}
+				if resp["count"] != float64(2) || resp["has_more"] != true {
+					t.Errorf("expected a full page with more after it, got count %v has_more %v", resp["count"], resp["has_more"])
+				}
+				if resp["total"] != float64(5) {
+					t.Errorf("expected total 5, got %v", resp["total"])
+				}
 			},
 		},
 		{
@@ -656,6 +1125,38 @@ func TestListNotifications(t *testing.T) {
 	}
 }
 
+func TestListNotifications_Sort(t *testing.T) {
+	tenantID := "00000000-0000-0000-0000-000000000001"
+	tests := []struct {
+		name           string
+		query          string
+		expectedSort   db.ListSort
+		expectedStatus int
+	}{
+		{"default newest first", "", db.ListSort{}, http.StatusOK},
+		{"status ascending", "&sort=status&order=asc", db.ListSort{Field: db.SortByStatus, Asc: true}, http.StatusOK},
+		{"updated_at descending", "&sort=updated_at&order=desc", db.ListSort{Field: db.SortByUpdatedAt}, http.StatusOK},
+		{"unknown field", "&sort=payload", db.ListSort{}, http.StatusBadRequest},
+		{"unknown order", "&sort=status&order=up", db.ListSort{}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			rec := httptest.NewRecorder()
+			handler.ListNotifications(rec, httptest.NewRequest(http.MethodGet, "/v1/notifications?tenant_id="+tenantID+tt.query, nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if mockRepo.lastFilter.Sort != tt.expectedSort {
+				t.Errorf("expected sort %+v to reach the repository, got %+v", tt.expectedSort, mockRepo.lastFilter.Sort)
+			}
+		})
+	}
+}
+
 // TestUpdateNotificationStatus tests the UpdateNotificationStatus handler
 func TestUpdateNotificationStatus(t *testing.T) {
 	tests := []struct {
@@ -663,6 +1164,7 @@ func TestUpdateNotificationStatus(t *testing.T) {
 		notificationID string
 		requestBody    interface{}
 		setupMock      func(*MockRepository)
+		tenantID       uuid.UUID // authenticated tenant, if any
 		expectedStatus int
 		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
 	}{
@@ -889,6 +1391,39 @@ func TestUpdateNotificationStatus(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "notification not found",
+			notificationID: "323e4567-e89b-12d3-a456-426614174000",
+			requestBody:    `{"status":"sent","attempt":1}`,
+			setupMock:      func(m *MockRepository) {},
+			expectedStatus: http.StatusNotFound,
+			checkResponse:  func(*testing.T, *httptest.ResponseRecorder) {},
+		},
+		{
+			name:           "another tenant's notification",
+			notificationID: "123e4567-e89b-12d3-a456-426614174000",
+			requestBody:    `{"status":"sent","attempt":1}`,
+			setupMock: func(m *MockRepository) {
+				id := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+				m.notifications[id.String()] = &db.Notification{
+					ID:       id,
+					TenantID: uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+					Channel:  "email",
+					Status:   db.StatusPending,
+				}
+			},
+			tenantID:       uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var errResp ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&errResp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if errResp.Title != "Notification not found" {
+					t.Errorf("expected title 'Notification not found', got '%s'", errResp.Title)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -915,7 +1450,11 @@ func TestUpdateNotificationStatus(t *testing.T) {
 
 			rctx := chi.NewRouteContext()
 			rctx.URLParams.Add("id", tt.notificationID)
-			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.tenantID != uuid.Nil {
+				ctx = context.WithValue(ctx, contextKeyTenantID, tt.tenantID)
+			}
+			req = req.WithContext(ctx)
 
 			rec := httptest.NewRecorder()
 
@@ -928,9 +1467,445 @@ func TestUpdateNotificationStatus(t *testing.T) {
 
 			tt.checkResponse(t, rec)
 
+			if tt.tenantID != uuid.Nil && tt.expectedStatus == http.StatusNotFound {
+				if n := mockRepo.notifications[tt.notificationID]; n != nil && n.Status != db.StatusPending {
+					t.Errorf("expected another tenant's notification untouched, got status %s", n.Status)
+				}
+			}
+
 			if tt.expectedStatus == http.StatusOK && !mockRepo.updateCalled {
 				t.Error("expected UpdateNotificationStatus to be called on repository")
 			}
 		})
 	}
 }
+
+func TestBatchUpdateNotificationStatus(t *testing.T) {
+	existing := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	missing := uuid.MustParse("223e4567-e89b-12d3-a456-426614174000")
+	owner := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		shouldFail     bool
+		tenantID       uuid.UUID // authenticated tenant, if any
+		expectedStatus int
+		expectUpdated  int
+		expectNotFound []string
+	}{
+		{
+			name:           "updates existing and reports missing",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"failed","attempt":2,"error":"bounced"},{"id":"` + missing.String() + `","status":"sent","attempt":1}]}`,
+			expectedStatus: http.StatusOK,
+			expectUpdated:  1,
+			expectNotFound: []string{missing.String()},
+		},
+		{
+			name:           "owning tenant",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"failed","attempt":2,"error":"bounced"}]}`,
+			tenantID:       owner,
+			expectedStatus: http.StatusOK,
+			expectUpdated:  1,
+			expectNotFound: []string{},
+		},
+		{
+			name:           "another tenant's notification is not found",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"failed","attempt":2,"error":"bounced"}]}`,
+			tenantID:       uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+			expectedStatus: http.StatusOK,
+			expectUpdated:  0,
+			expectNotFound: []string{existing.String()},
+		},
+		{
+			name:           "empty batch",
+			requestBody:    `{"updates":[]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid status rejects the whole batch",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"sent","attempt":1},{"id":"` + missing.String() + `","status":"dead_lettered","attempt":1}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "duplicate id",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"sent","attempt":1},{"id":"` + existing.String() + `","status":"failed","attempt":1}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid id",
+			requestBody:    `{"updates":[{"id":"nope","status":"sent","attempt":1}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative attempt",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"sent","attempt":-1}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "body too large",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"failed","attempt":1,"error":"` + strings.Repeat("x", maxStatusBatchBytes) + `"}]}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "database error",
+			requestBody:    `{"updates":[{"id":"` + existing.String() + `","status":"sent","attempt":1}]}`,
+			shouldFail:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			mockRepo.shouldFail = tt.shouldFail
+			mockRepo.notifications[existing.String()] = &db.Notification{ID: existing, TenantID: owner, Status: db.StatusProcessing}
+			handler := NewHandler(zap.NewNop(), mockRepo)
+
+			req := httptest.NewRequest(http.MethodPatch, "/v1/notifications/status", strings.NewReader(tt.requestBody))
+			if tt.tenantID != uuid.Nil {
+				req = req.WithContext(context.WithValue(req.Context(), contextKeyTenantID, tt.tenantID))
+			}
+			rec := httptest.NewRecorder()
+			handler.BatchUpdateNotificationStatus(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if mockRepo.notifications[existing.String()].Status != db.StatusProcessing {
+					t.Error("expected no writes for a rejected batch")
+				}
+				return
+			}
+
+			var resp struct {
+				Updated  int      `json:"updated"`
+				NotFound []string `json:"not_found"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Updated != tt.expectUpdated || !slices.Equal(resp.NotFound, tt.expectNotFound) {
+				t.Errorf("expected updated=%d not_found=%v, got %d %v", tt.expectUpdated, tt.expectNotFound, resp.Updated, resp.NotFound)
+			}
+			got := mockRepo.notifications[existing.String()]
+			if tt.expectUpdated == 0 {
+				if got.Status != db.StatusProcessing {
+					t.Errorf("expected existing notification untouched, got status=%s", got.Status)
+				}
+				return
+			}
+			if got.Status != db.StatusFailed || got.Attempt != 2 {
+				t.Errorf("expected existing notification updated, got status=%s attempt=%d", got.Status, got.Attempt)
+			}
+		})
+	}
+}
+
+func TestEditNotification(t *testing.T) {
+	id := uuid.MustParse("a1b2c3d4-e5f6-4a5b-8c9d-0e1f2a3b4c5d")
+	sendAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		status         string
+		ifMatch        func(etag string) string
+		body           string
+		tenantID       uuid.UUID // authenticated tenant, if any
+		expectedStatus int
+		check          func(*testing.T, *db.Notification)
+	}{
+		{
+			name:           "fixes payload",
+			status:         db.StatusPending,
+			ifMatch:        func(etag string) string { return etag },
+			body:           `{"payload":{"to":"user@example.com","subject":"Fixed","body":"Hi"}}`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, n *db.Notification) {
+				if !strings.Contains(string(n.Payload), "Fixed") {
+					t.Errorf("expected payload updated, got %s", n.Payload)
+				}
+			},
+		},
+		{
+			name:           "reschedules",
+			status:         db.StatusPending,
+			ifMatch:        func(etag string) string { return etag },
+			body:           `{"send_at":"2030-01-01T09:00:00Z"}`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, n *db.Notification) {
+				if n.NextRetryAt == nil || !n.NextRetryAt.Equal(sendAt) {
+					t.Errorf("expected next_retry_at %s, got %v", sendAt, n.NextRetryAt)
+				}
+			},
+		},
+		{
+			name:           "missing If-Match",
+			status:         db.StatusPending,
+			ifMatch:        func(string) string { return "" },
+			body:           `{"send_at":null}`,
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "stale If-Match",
+			status:         db.StatusPending,
+			ifMatch:        func(string) string { return `W/"stale"` },
+			body:           `{"send_at":null}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "already sent",
+			status:         db.StatusSent,
+			ifMatch:        func(etag string) string { return etag },
+			body:           `{"send_at":null}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "nothing to change",
+			status:         db.StatusPending,
+			ifMatch:        func(etag string) string { return etag },
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid send_at",
+			status:         db.StatusPending,
+			ifMatch:        func(etag string) string { return etag },
+			body:           `{"send_at":"tomorrow"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "owning tenant",
+			status:         db.StatusPending,
+			ifMatch:        func(etag string) string { return etag },
+			body:           `{"send_at":null}`,
+			tenantID:       uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			expectedStatus: http.StatusOK,
+			check:          func(*testing.T, *db.Notification) {},
+		},
+		{
+			name:           "another tenant's notification",
+			status:         db.StatusPending,
+			ifMatch:        func(string) string { return "*" },
+			body:           `{"payload":{"to":"attacker@example.com","subject":"Hi","body":"Hi"}}`,
+			tenantID:       uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			notif := &db.Notification{
+				ID:        id,
+				TenantID:  uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+				Channel:   "email",
+				Payload:   json.RawMessage(`{"to":"user@example.com","subject":"Typo","body":"Hi"}`),
+				Status:    tt.status,
+				UpdatedAt: time.Now(),
+			}
+			mockRepo.notifications[id.String()] = notif
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			etag := notificationETag(notif)
+
+			req := httptest.NewRequest(http.MethodPatch, "/v1/notifications/"+id.String(), strings.NewReader(tt.body))
+			if v := tt.ifMatch(etag); v != "" {
+				req.Header.Set("If-Match", v)
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id.String())
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.tenantID != uuid.Nil {
+				ctx = context.WithValue(ctx, contextKeyTenantID, tt.tenantID)
+			}
+			req = req.WithContext(ctx)
+
+			rec := httptest.NewRecorder()
+			handler.EditNotification(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if mockRepo.updateCalled {
+					t.Error("expected no write for a rejected edit")
+				}
+				return
+			}
+			if rec.Header().Get("ETag") == etag {
+				t.Error("expected a new ETag after the edit")
+			}
+			tt.check(t, mockRepo.notifications[id.String()])
+		})
+	}
+}
+
+type recordingEnqueuer struct{ enqueued int }
+
+func (e *recordingEnqueuer) Enqueue(ctx context.Context, notif *db.Notification) (string, error) {
+	e.enqueued++
+	return "msg-1", nil
+}
+
+func TestCreateNotification_SendAt(t *testing.T) {
+	tests := []struct {
+		name         string
+		sendAt       time.Time
+		wantEnqueued int
+	}{
+		{"future is left to the poller", time.Now().Add(time.Hour).UTC().Truncate(time.Second), 0},
+		{"past is enqueued", time.Now().Add(-time.Hour).UTC().Truncate(time.Second), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			producer := &recordingEnqueuer{}
+			handler := NewHandlerWithSQS(zap.NewNop(), mockRepo, nil, producer)
+
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: "00000000-0000-0000-0000-000000000001",
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "email",
+				Payload:  json.RawMessage(`{"to":"user@example.com"}`),
+				SendAt:   &tt.sendAt,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var resp NotificationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			stored := mockRepo.notifications[resp.ID]
+			if stored == nil || stored.NextRetryAt == nil || !stored.NextRetryAt.Equal(tt.sendAt) {
+				t.Fatalf("expected send_at stored as next_retry_at, got %+v", stored)
+			}
+			if producer.enqueued != tt.wantEnqueued {
+				t.Errorf("expected %d enqueued, got %d", tt.wantEnqueued, producer.enqueued)
+			}
+		})
+	}
+}
+
+func TestCancelNotification(t *testing.T) {
+	id := uuid.MustParse("a1b2c3d4-e5f6-4a5b-8c9d-0e1f2a3b4c5d")
+	owner := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	sendAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		status         string
+		tenantID       uuid.UUID
+		idStr          string
+		expectedStatus int
+	}{
+		{"scheduled", db.StatusPending, owner, id.String(), http.StatusOK},
+		{"held", db.StatusHeld, owner, id.String(), http.StatusOK},
+		{"already sending", db.StatusProcessing, owner, id.String(), http.StatusConflict},
+		{"already sent", db.StatusSent, owner, id.String(), http.StatusConflict},
+		{"another tenant's", db.StatusPending, uuid.New(), id.String(), http.StatusNotFound},
+		{"bad id", db.StatusPending, owner, "not-a-uuid", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			mockRepo.notifications[id.String()] = &db.Notification{
+				ID:          id,
+				TenantID:    owner,
+				Channel:     "email",
+				Status:      tt.status,
+				NextRetryAt: &sendAt,
+			}
+			handler := NewHandler(zap.NewNop(), mockRepo)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.idStr)
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, contextKeyTenantID, tt.tenantID)
+			req := httptest.NewRequest(http.MethodPost, "/v1/notifications/"+tt.idStr+"/cancel", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			handler.CancelNotification(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var resp db.Notification
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Status != db.StatusFailed || resp.ErrorMessage == nil || *resp.ErrorMessage != db.CancelledError || resp.NextRetryAt != nil {
+				t.Errorf("expected the notification cancelled, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestGetNotificationByProviderID(t *testing.T) {
+	id := uuid.MustParse("a1b2c3d4-e5f6-4a5b-8c9d-0e1f2a3b4c5d")
+
+	tests := []struct {
+		name           string
+		messageID      string
+		query          string
+		tenantID       string // authenticated tenant, if any
+		expectedStatus int
+	}{
+		{"found", "0100018c-abc", "", "", http.StatusOK},
+		{"found with provider", "0100018c-abc", "?provider=ses", "", http.StatusOK},
+		{"wrong provider", "0100018c-abc", "?provider=sns", "", http.StatusNotFound},
+		{"unknown id", "nope", "", "", http.StatusNotFound},
+		{"id too long", strings.Repeat("x", 257), "", "", http.StatusBadRequest},
+		{"owning tenant", "0100018c-abc", "", "00000000-0000-0000-0000-000000000001", http.StatusOK},
+		{"another tenant's notification", "0100018c-abc", "", "00000000-0000-0000-0000-000000000002", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			mockRepo.notifications[id.String()] = &db.Notification{
+				ID:                id,
+				TenantID:          uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+				Channel:           "email",
+				Status:            db.StatusSent,
+				Provider:          "ses",
+				ProviderMessageID: "0100018c-abc",
+			}
+			handler := NewHandler(zap.NewNop(), mockRepo)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/notifications/by-provider-id/"+tt.messageID+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.messageID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.tenantID != "" {
+				ctx = context.WithValue(ctx, contextKeyTenantID, uuid.MustParse(tt.tenantID))
+			}
+			req = req.WithContext(ctx)
+
+			rec := httptest.NewRecorder()
+			handler.GetNotificationByProviderID(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var got db.Notification
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.ID != id || got.ProviderMessageID != "0100018c-abc" {
+				t.Errorf("expected notification %s with provider message id, got %+v", id, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	maxImpersonationBytes        = 4 << 10
+	defaultImpersonationTTL      = 15 * time.Minute
+	minImpersonationTTL          = time.Minute
+	maxImpersonationTTL          = time.Hour
+	maxImpersonationReasonLength = 500
+)
+
+// ImpersonationRepository stores the keys behind impersonation tokens.
+type ImpersonationRepository interface {
+	CreateAPIKey(ctx context.Context, key *db.APIKey) error
+}
+
+// ImpersonationRequest is the body of
+// POST /v1/admin/tenants/{tenantID}/impersonate.
+type ImpersonationRequest struct {
+	// Why, such as a ticket reference. It goes to the audit log with the
+	// operator who asked.
+	Reason     string `json:"reason"`
+	TTLSeconds int    `json:"ttl_seconds"`
+}
+
+// ImpersonationHandler issues support engineers short-lived tokens that see
+// a tenant's API exactly as the tenant's own read-only keys do, so a
+// tenant's report can be reproduced without asking them for a key. Tokens
+// are ordinary API keys with the read and impersonation scopes: they can't
+// write, manage keys or reach another tenant, they show up in the tenant's
+// own key list, and the tenant can revoke them. Issuing one, and every
+// request made with one, is written to the audit log. The token is issued
+// to the operator AdminAuthMiddleware authenticated, never to a name the
+// caller supplies.
+type ImpersonationHandler struct {
+	repo   ImpersonationRepository
+	logger *zap.Logger
+	audit  *zap.Logger
+}
+
+// NewImpersonationHandler creates the admin impersonation token handler.
+func NewImpersonationHandler(logger *zap.Logger, repo ImpersonationRepository) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		repo:   repo,
+		logger: logger,
+		audit:  logger.Named("audit"),
+	}
+}
+
+// Impersonate handles POST /v1/admin/tenants/{tenantID}/impersonate
+// {"reason": "SUP-812 missing SMS", "ttl_seconds": 900}
+func (h *ImpersonationHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	op, ok := OperatorFromContext(r.Context())
+	if !ok {
+		writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "No authenticated operator", "")
+		return
+	}
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req ImpersonationRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImpersonationBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+	ttl, detail := validateImpersonationRequest(&req)
+	if detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid impersonation request", detail)
+		return
+	}
+
+	expiresAt := time.Now().Add(ttl).UTC()
+	key := &db.APIKey{
+		TenantID:  tenantID,
+		Name:      "impersonation by " + op.Name,
+		Scopes:    []string{ScopeRead, ScopeImpersonation},
+		ExpiresAt: &expiresAt,
+	}
+	plaintext, err := IssueAPIKey(key)
+	if err != nil {
+		h.logger.Error("failed to generate api key", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeInternalError, "Failed to issue impersonation token", "")
+		return
+	}
+	err = h.repo.CreateAPIKey(r.Context(), key)
+
+	h.audit.Warn("tenant impersonation token issued",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("operator", op.Name),
+		zap.String("reason", req.Reason),
+		zap.String("api_key_id", key.ID.String()),
+		zap.String("prefix", key.Prefix),
+		zap.Time("expires_at", expiresAt),
+		zap.String("request_id", middleware.GetReqID(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.Bool("failed", err != nil),
+	)
+
+	if err != nil {
+		h.logger.Error("failed to create impersonation key",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to issue impersonation token", "")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, IssuedAPIKey{APIKey: key, Key: plaintext})
+}
+
+// validateImpersonationRequest trims and checks req, and returns the token's
+// lifetime.
+func validateImpersonationRequest(req *ImpersonationRequest) (time.Duration, string) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > maxImpersonationReasonLength {
+		return 0, fmt.Sprintf("reason is required and must be at most %d characters; it is recorded in the audit log", maxImpersonationReasonLength)
+	}
+
+	ttl := defaultImpersonationTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl < minImpersonationTTL || ttl > maxImpersonationTTL {
+		return 0, fmt.Sprintf("ttl_seconds must be between %d and %d", int(minImpersonationTTL.Seconds()), int(maxImpersonationTTL.Seconds()))
+	}
+	return ttl, ""
+}
+
+// auditImpersonation writes a request made with an impersonation token to
+// the audit log. Authentication calls it for every request, including ones
+// it goes on to refuse.
+func auditImpersonation(r *http.Request, logger *zap.Logger) {
+	keyID, ok := r.Context().Value(contextKeyImpersonation).(uuid.UUID)
+	if !ok {
+		return
+	}
+	tenantID, _ := TenantIDFromContext(r.Context())
+	logger.Named("audit").Warn("impersonated request",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("api_key_id", keyID.String()),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("request_id", middleware.GetReqID(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+}
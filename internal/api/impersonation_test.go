@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// impersonate calls the handler as the support operator jane@example.com,
+// or with no operator token when token is empty.
+func impersonate(t *testing.T, h *ImpersonationHandler, tenantID, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	r.With(AdminAuthMiddleware(
+		map[string]string{"support-token": "jane@example.com"},
+		map[string]string{"support-token": string(OperatorRoleSupport)},
+		zap.NewNop(),
+	)).Post("/v1/admin/tenants/{tenantID}/impersonate", h.Impersonate)
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/tenants/"+tenantID+"/impersonate", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImpersonate(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	rec := impersonate(t, NewImpersonationHandler(logger, keys), v2TenantB, "support-token",
+		`{"reason": "SUP-812 missing SMS", "ttl_seconds": 600}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	issued := IssuedAPIKey{APIKey: &db.APIKey{}}
+	if err := json.Unmarshal(rec.Body.Bytes(), &issued); err != nil {
+		t.Fatalf("failed to decode token: %v", err)
+	}
+	if issued.TenantID.String() != v2TenantB || issued.Key == "" || issued.Name != "impersonation by jane@example.com" {
+		t.Errorf("unexpected token %+v", issued.APIKey)
+	}
+	if issued.ExpiresAt == nil || time.Until(*issued.ExpiresAt) > 10*time.Minute {
+		t.Errorf("expected the token to expire within 10 minutes, got %v", issued.ExpiresAt)
+	}
+
+	audit := auditLogs(logs).All()
+	if len(audit) != 1 {
+		t.Fatalf("expected one audit entry, got %d", len(audit))
+	}
+	if fields := audit[0].ContextMap(); fields["operator"] != "jane@example.com" || fields["reason"] != "SUP-812 missing SMS" || fields["tenant_id"] != v2TenantB {
+		t.Errorf("unexpected audit fields: %v", fields)
+	}
+
+	// The token reads as the tenant, can't write or manage keys, and every
+	// request it makes is audited.
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	v1 := V1AuthMiddleware(nil, nil, keys, logger)(next)
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"read", http.MethodGet, "/v1/notifications?tenant_id=" + v2TenantB, "", http.StatusOK},
+		{"write", http.MethodPost, "/v1/notifications", `{"tenant_id":"` + v2TenantB + `"}`, http.StatusForbidden},
+		{"other tenant", http.MethodGet, "/v1/notifications?tenant_id=" + v2TenantA, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Authorization", "Bearer "+issued.Key)
+			rec := httptest.NewRecorder()
+			v1.ServeHTTP(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if rec, _ := doV2(t, newAPIKeyRouter(keys), http.MethodGet, "/v2/api-keys/", issued.Key, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("key management: expected 403, got %d", rec.Code)
+	}
+
+	requests := logs.FilterMessage("impersonated request").All()
+	if len(requests) != len(tests) {
+		t.Fatalf("expected %d audited requests, got %d", len(tests), len(requests))
+	}
+	if fields := requests[1].ContextMap(); fields["method"] != http.MethodPost || fields["api_key_id"] != issued.ID.String() {
+		t.Errorf("unexpected audit fields: %v", fields)
+	}
+}
+
+func TestImpersonate_Validation(t *testing.T) {
+	tests := map[string]struct {
+		token          string
+		body           string
+		expectedStatus int
+	}{
+		"no operator":           {"", `{"reason": "SUP-812"}`, http.StatusUnauthorized},
+		"missing reason":        {"support-token", `{"reason": "  "}`, http.StatusBadRequest},
+		"ttl too long":          {"support-token", `{"reason": "SUP-812", "ttl_seconds": 7200}`, http.StatusBadRequest},
+		"ttl too short":         {"support-token", `{"reason": "SUP-812", "ttl_seconds": 5}`, http.StatusBadRequest},
+		"scopes requested":      {"support-token", `{"reason": "SUP-812", "scopes": ["write"]}`, http.StatusBadRequest},
+		"requester in the body": {"support-token", `{"requested_by": "someone@else.com", "reason": "SUP-812"}`, http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys := newMockAPIKeyRepo()
+			core, logs := observer.New(zapcore.InfoLevel)
+			rec := impersonate(t, NewImpersonationHandler(zap.New(core), keys), v2TenantB, tt.token, tt.body)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if len(keys.keys) != 0 || auditLogs(logs).Len() != 0 {
+				t.Error("expected no token to be issued or audited")
+			}
+		})
+	}
+}
+
+func TestAPIKeys_CannotRequestImpersonationScope(t *testing.T) {
+	rec, env := doV2(t, newAPIKeyRouter(newMockAPIKeyRepo()), http.MethodPost, "/v2/api-keys/", "token-a", map[string]any{
+		"name":   "sneaky",
+		"scopes": []string{"read", "impersonation"},
+	})
+	if rec.Code != http.StatusBadRequest || env.Errors[0].Field != "scopes" {
+		t.Errorf("expected 400 on scopes, got %d %+v", rec.Code, env.Errors)
+	}
+}
@@ -0,0 +1,531 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/imports"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+const (
+	// maxImportUpload caps a multipart upload, file and fields together.
+	// Bigger lists go through S3.
+	maxImportUpload = 20 << 20
+	// maxImportRows caps one import from either source.
+	maxImportRows = 100000
+	// maxImportRowErrors caps the stored error report; failed_rows keeps
+	// the full count.
+	maxImportRowErrors = 1000
+	// importProgressEvery is how many rows pass between progress writes.
+	importProgressEvery = 500
+	// importTagPrefix tags every notification an import creates, so
+	// GET /v1/notifications?tag=import:<id> lists them.
+	importTagPrefix = "import:"
+)
+
+const errTitleInvalidImport = "Invalid import"
+
+// ImportRepository stores bulk import jobs.
+type ImportRepository interface {
+	CreateImport(ctx context.Context, imp *db.NotificationImport) error
+	GetImport(ctx context.Context, id uuid.UUID) (*db.NotificationImport, error)
+	UpdateImport(ctx context.Context, imp *db.NotificationImport) error
+}
+
+// ImportSource opens import files stored outside the request, by key.
+// *imports.S3 implements it.
+type ImportSource interface {
+	Bucket() string
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// ImportRequest is the JSON body of POST /v1/imports for a file already in
+// the import bucket. Uploads send the same fields as multipart form values,
+// with the file in "file".
+type ImportRequest struct {
+	TenantID string          `json:"tenant_id"`
+	Channel  string          `json:"channel"`
+	Format   string          `json:"format"`
+	S3Key    string          `json:"s3_key"`
+	Payload  json.RawMessage `json:"payload"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+}
+
+// ImportHandler serves bulk imports: a CSV or JSONL file of recipients is
+// accepted, and its rows become notifications in the background, each
+// validated exactly as POST /v1/notifications would. Clients poll
+// GET /v1/imports/{id} for progress and the per-row error report.
+type ImportHandler struct {
+	notifications *Handler
+	repo          ImportRepository
+	source        ImportSource // nil when no import bucket is configured
+	logger        *zap.Logger
+
+	// ctx outlives the request that started an import and is cancelled by
+	// Close once the drain budget runs out.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewImportHandler creates the import handler. Rows are created through
+// notifications, so they get its validation, idempotency-free persistence
+// and enqueueing.
+func NewImportHandler(logger *zap.Logger, notifications *Handler, repo ImportRepository) *ImportHandler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ImportHandler{
+		notifications: notifications,
+		repo:          repo,
+		logger:        logger,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// SetSource lets imports reference files in an S3 bucket by key.
+func (h *ImportHandler) SetSource(source ImportSource) {
+	h.source = source
+}
+
+// Close waits for running imports to finish. When ctx expires first they
+// are cancelled and marked failed with the rows processed so far.
+func (h *ImportHandler) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		h.cancel()
+		return nil
+	case <-ctx.Done():
+		h.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// importJob is a validated import waiting to run.
+type importJob struct {
+	imp      *db.NotificationImport
+	open     func(ctx context.Context) (io.ReadCloser, error)
+	payload  json.RawMessage
+	metadata json.RawMessage
+	tags     []string
+}
+
+// CreateImport handles POST /v1/imports. It validates the request, records
+// a pending import and returns 202 with it; the rows are processed after
+// the response is sent.
+func (h *ImportHandler) CreateImport(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headerContentType))
+
+	var (
+		job           *importJob
+		title, detail string
+	)
+	if mediaType == "multipart/form-data" {
+		job, title, detail = h.uploadJob(w, r)
+	} else {
+		job, title, detail = h.s3Job(r)
+	}
+	if title != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, title, detail)
+		return
+	}
+	// V1AuthMiddleware can't see the tenant_id of a multipart upload.
+	if tenantID, ok := TenantIDFromContext(r.Context()); ok && job.imp.TenantID != tenantID {
+		writeProblem(w, http.StatusForbidden, errTypeForbidden, errTitleTenantMismatch, errDetailTenantMismatch)
+		return
+	}
+
+	ctx := db.WithTenant(r.Context(), job.imp.TenantID)
+	if err := h.repo.CreateImport(ctx, job.imp); err != nil {
+		h.logger.Error("failed to create import", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to create import", "")
+		return
+	}
+
+	h.logger.Info("import accepted",
+		zap.String("import_id", job.imp.ID.String()),
+		zap.String(logFieldTenantID, job.imp.TenantID.String()),
+		zap.String(logFieldChannel, job.imp.Channel),
+		zap.String("source", job.imp.Source),
+	)
+
+	// Respond with the pending record before the job can change it.
+	resp := *job.imp
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.run(job)
+	}()
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.Header().Set("Location", "/v1/imports/"+resp.ID.String())
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(&resp)
+}
+
+// GetImport handles GET /v1/imports/{id}.
+func (h *ImportHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid import ID", "id must be a valid UUID")
+		return
+	}
+
+	imp, err := h.repo.GetImport(r.Context(), id)
+	if errors.Is(err, db.ErrImportNotFound) {
+		writeProblem(w, http.StatusNotFound, errTypeNotFound, "Import not found", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get import", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get import", "")
+		return
+	}
+	if tenantID, ok := TenantIDFromContext(r.Context()); ok && imp.TenantID != tenantID {
+		writeProblem(w, http.StatusNotFound, errTypeNotFound, "Import not found", "")
+		return
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(imp)
+}
+
+// uploadJob builds a job from a multipart upload. The file is read into
+// memory, since the request body is gone by the time the job runs.
+func (h *ImportHandler) uploadJob(w http.ResponseWriter, r *http.Request) (*importJob, string, string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportUpload)
+	if err := r.ParseMultipartForm(maxImportUpload); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, errTitleInvalidImport, fmt.Sprintf("upload must be at most %d MB; put larger files in S3", maxImportUpload>>20)
+		}
+		return nil, "Malformed multipart body", err.Error()
+	}
+	defer r.MultipartForm.RemoveAll() //nolint:errcheck
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, errTitleMissingFields, "file is required"
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "Malformed multipart body", err.Error()
+	}
+
+	req := ImportRequest{
+		TenantID: r.FormValue("tenant_id"),
+		Channel:  r.FormValue("channel"),
+		Format:   r.FormValue("format"),
+		Payload:  json.RawMessage(r.FormValue("payload")),
+		Metadata: json.RawMessage(r.FormValue("metadata")),
+	}
+	if tags := r.FormValue("tags"); tags != "" {
+		req.Tags = strings.Split(tags, ",")
+	}
+	if req.Format == "" {
+		req.Format = formatFromName(header.Filename)
+	}
+
+	job, title, detail := h.newJob(req)
+	if title != "" {
+		return nil, title, detail
+	}
+	job.imp.Source = "upload:" + path.Base(header.Filename)
+	job.open = func(context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return job, "", ""
+}
+
+// s3Job builds a job from a JSON body naming a file in the import bucket.
+// Keys must sit under the tenant's own prefix, "<tenant_id>/", so one
+// tenant can't import another's list.
+func (h *ImportHandler) s3Job(r *http.Request) (*importJob, string, string) {
+	var req ImportRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return nil, errTitleMalformedJSON, err.Error()
+	}
+
+	if h.source == nil {
+		return nil, errTitleInvalidImport, "S3 imports are not configured; upload the file as multipart/form-data"
+	}
+	if req.S3Key == "" {
+		return nil, errTitleMissingFields, "s3_key is required"
+	}
+	if req.Format == "" {
+		req.Format = formatFromName(req.S3Key)
+	}
+
+	job, title, detail := h.newJob(req)
+	if title != "" {
+		return nil, title, detail
+	}
+	if !strings.HasPrefix(req.S3Key, job.imp.TenantID.String()+"/") {
+		return nil, errTitleInvalidImport, "s3_key must start with the tenant ID followed by /"
+	}
+	job.imp.Source = "s3://" + h.source.Bucket() + "/" + req.S3Key
+	job.open = func(ctx context.Context) (io.ReadCloser, error) {
+		return h.source.Open(ctx, req.S3Key)
+	}
+	return job, "", ""
+}
+
+// newJob validates the fields both sources share.
+func (h *ImportHandler) newJob(req ImportRequest) (*importJob, string, string) {
+	if req.TenantID == "" || req.Channel == "" || len(req.Payload) == 0 {
+		return nil, errTitleMissingFields, "tenant_id, channel, and payload are required"
+	}
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		return nil, errTitleInvalidTenant, errDetailInvalidTenant
+	}
+	if !isValidChannel(req.Channel) {
+		return nil, errTitleInvalidChannel, errDetailInvalidChannel
+	}
+	if !imports.ValidFormat(req.Format) {
+		return nil, errTitleInvalidImport, "format must be " + imports.FormatCSV + " or " + imports.FormatJSONL
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(req.Payload, &fields); err != nil {
+		return nil, errTitleInvalidPayload, "payload must be a JSON object"
+	}
+	if len(req.Metadata) == 0 {
+		req.Metadata = nil
+	}
+	if err := validateMetadata(req.Metadata); err != nil {
+		return nil, errTitleInvalidMetadata, err.Error()
+	}
+
+	id := uuid.New()
+	tags, err := normalizeTags(append(req.Tags, importTagPrefix+id.String()))
+	if err != nil {
+		return nil, errTitleInvalidTags, fmt.Sprintf("%v (one tag is added for the import)", err)
+	}
+
+	return &importJob{
+		imp: &db.NotificationImport{
+			ID:        id,
+			TenantID:  tenantID,
+			Channel:   req.Channel,
+			Format:    req.Format,
+			Status:    db.ImportStatusPending,
+			RowErrors: []db.ImportRowError{},
+		},
+		payload:  req.Payload,
+		metadata: req.Metadata,
+		tags:     tags,
+	}, "", ""
+}
+
+// formatFromName guesses an import's format from its file name.
+func formatFromName(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return imports.FormatCSV
+	case ".jsonl", ".ndjson":
+		return imports.FormatJSONL
+	default:
+		return ""
+	}
+}
+
+// run processes job's rows and records the outcome. A bad row is added to
+// the error report and skipped; only an unreadable file, or shutdown, fails
+// the import.
+func (h *ImportHandler) run(job *importJob) {
+	imp := job.imp
+	ctx := db.WithTenant(h.ctx, imp.TenantID)
+	ctx = observ.With(ctx, h.logger,
+		zap.String("import_id", imp.ID.String()),
+		zap.String(observ.FieldTenantID, imp.TenantID.String()),
+	)
+	logger := observ.Logger(ctx, h.logger)
+
+	imp.Status = db.ImportStatusProcessing
+	h.save(ctx, imp)
+
+	err := h.process(ctx, job)
+	finished := time.Now()
+	imp.FinishedAt = &finished
+	if err != nil {
+		msg := err.Error()
+		if ctx.Err() != nil {
+			msg = "interrupted by gateway shutdown"
+		}
+		imp.Status = db.ImportStatusFailed
+		imp.Error = &msg
+		logger.Warn("import failed", zap.Error(err), zap.Int("total_rows", imp.TotalRows))
+	} else {
+		imp.Status = db.ImportStatusCompleted
+		logger.Info("import completed",
+			zap.Int("total_rows", imp.TotalRows),
+			zap.Int("created_rows", imp.CreatedRows),
+			zap.Int("failed_rows", imp.FailedRows),
+		)
+	}
+	// The final status must land even when shutdown cancelled the run.
+	h.save(context.WithoutCancel(ctx), imp)
+}
+
+func (h *ImportHandler) process(ctx context.Context, job *importJob) error {
+	imp := job.imp
+	body, err := job.open(ctx)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer body.Close()
+
+	reader, err := imports.NewReader(body, imp.Format)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		var rowErr *imports.RowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return err
+		}
+
+		imp.TotalRows++
+		if imp.TotalRows > maxImportRows {
+			imp.TotalRows--
+			return fmt.Errorf("file has more than %d rows; split it into several imports", maxImportRows)
+		}
+
+		if rowErr != nil {
+			h.rowFailed(imp, rowErr.Line, rowErr.Err)
+		} else if err := h.createRow(ctx, job, row); err != nil {
+			h.rowFailed(imp, row.Line, err)
+		} else {
+			imp.CreatedRows++
+		}
+
+		if imp.TotalRows%importProgressEvery == 0 {
+			h.save(ctx, imp)
+		}
+	}
+}
+
+// createRow turns one row into a notification, applying the same checks as
+// POST /v1/notifications. The returned error is the row's report entry.
+func (h *ImportHandler) createRow(ctx context.Context, job *importJob, row *imports.Row) error {
+	imp := job.imp
+	userID, err := uuid.Parse(row.UserID)
+	if err != nil {
+		return errors.New(errDetailInvalidUser)
+	}
+	if row.Recipient == "" {
+		return errors.New("recipient is required")
+	}
+
+	payload, err := imports.Render(job.payload, row.Vars)
+	if err != nil {
+		return err
+	}
+	payload, err = withRecipient(imp.Channel, payload, row.Recipient)
+	if err != nil {
+		return err
+	}
+
+	n := h.notifications
+	if _, err := n.estimateSMS(imp.Channel, payload); err != nil {
+		return err
+	}
+	payload, err = n.normalizeSMSRecipient(ctx, imp.TenantID, imp.Channel, payload)
+	if err != nil {
+		return err
+	}
+	if _, err := n.checkEmailRecipient(ctx, imp.Channel, payload); err != nil {
+		return err
+	}
+	if err := n.checkWebhookDestination(ctx, imp.TenantID, imp.Channel, payload); err != nil {
+		return err
+	}
+
+	notif := &db.Notification{
+		ID:            uuid.New(),
+		TenantID:      imp.TenantID,
+		UserID:        userID,
+		Channel:       imp.Channel,
+		Payload:       payload,
+		Status:        db.StatusPending,
+		Attempt:       initialAttempt,
+		CorrelationID: observ.NewCorrelationID(),
+		Metadata:      job.metadata,
+		Tags:          job.tags,
+	}
+	if err := n.persistNotification(ctx, notif, imp.TenantID.String(), "", "", false); err != nil {
+		return errors.New("failed to create notification")
+	}
+	return nil
+}
+
+// recipientFields is the payload field each channel addresses.
+var recipientFields = map[string]string{
+	channelEmail:   "to",
+	channelSMS:     "phone_number",
+	channelWebhook: "url",
+	channelSlack:   "channel",
+}
+
+// withRecipient sets the row's recipient into payload, overriding any
+// recipient in the template.
+func withRecipient(channel string, payload json.RawMessage, recipient string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, errors.New("payload must be a JSON object")
+	}
+	fields[recipientFields[channel]], _ = json.Marshal(recipient)
+	return json.Marshal(fields)
+}
+
+func (h *ImportHandler) rowFailed(imp *db.NotificationImport, line int, err error) {
+	imp.FailedRows++
+	if len(imp.RowErrors) < maxImportRowErrors {
+		imp.RowErrors = append(imp.RowErrors, db.ImportRowError{Line: line, Error: err.Error()})
+	}
+}
+
+// save records imp's progress. A failed write is logged and the import
+// carries on: the next write, or the final one, catches up.
+func (h *ImportHandler) save(ctx context.Context, imp *db.NotificationImport) {
+	if err := h.repo.UpdateImport(ctx, imp); err != nil {
+		observ.Logger(ctx, h.logger).Error("failed to save import progress", zap.Error(err))
+	}
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const importTenant = "00000000-0000-0000-0000-000000000001"
+
+// mockImportRepo keeps the latest saved copy of each import; the job
+// goroutine writes it while the test reads.
+type mockImportRepo struct {
+	mu      sync.Mutex
+	imports map[uuid.UUID]db.NotificationImport
+}
+
+func newMockImportRepo() *mockImportRepo {
+	return &mockImportRepo{imports: make(map[uuid.UUID]db.NotificationImport)}
+}
+
+func (m *mockImportRepo) CreateImport(ctx context.Context, imp *db.NotificationImport) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.imports[imp.ID] = *imp
+	return nil
+}
+
+func (m *mockImportRepo) GetImport(ctx context.Context, id uuid.UUID) (*db.NotificationImport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	imp, ok := m.imports[id]
+	if !ok {
+		return nil, db.ErrImportNotFound
+	}
+	return &imp, nil
+}
+
+func (m *mockImportRepo) UpdateImport(ctx context.Context, imp *db.NotificationImport) error {
+	return m.CreateImport(ctx, imp)
+}
+
+type mockImportSource struct {
+	files map[string]string
+}
+
+func (m *mockImportSource) Bucket() string { return "imports-bucket" }
+
+func (m *mockImportSource) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m.files[key]
+	if !ok {
+		return nil, errors.New("status 404: NoSuchKey")
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func uploadRequest(t *testing.T, fields map[string]string, filename, file string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	if filename != "" {
+		fw, err := mw.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(fw, file)
+	}
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/imports", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// runImport posts req, waits for the job and returns the saved import.
+func runImport(t *testing.T, h *ImportHandler, repo *mockImportRepo, req *http.Request) db.NotificationImport {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.CreateImport(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var accepted db.NotificationImport
+	if err := json.Unmarshal(rec.Body.Bytes(), &accepted); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if accepted.Status != db.ImportStatusPending || rec.Header().Get("Location") != "/v1/imports/"+accepted.ID.String() {
+		t.Errorf("unexpected accepted import: %+v, Location %q", accepted, rec.Header().Get("Location"))
+	}
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	imp, err := repo.GetImport(context.Background(), accepted.ID)
+	if err != nil {
+		t.Fatalf("get import: %v", err)
+	}
+	return *imp
+}
+
+func TestCreateImport_Upload(t *testing.T) {
+	notifications := NewMockRepository()
+	repo := newMockImportRepo()
+	h := NewImportHandler(zap.NewNop(), NewHandler(zap.NewNop(), notifications), repo)
+
+	user := uuid.New().String()
+	file := "user_id,recipient,first_name\n" +
+		user + ",ada@example.com,Ada\n" +
+		"not-a-uuid,grace@example.com,Grace\n" +
+		user + ",\"\",Alan\n" +
+		user + ",linus@example.com\n"
+
+	imp := runImport(t, h, repo, uploadRequest(t, map[string]string{
+		"tenant_id": importTenant,
+		"channel":   "email",
+		"payload":   `{"subject":"Hi {{first_name}}","body":"Welcome, {{first_name}}."}`,
+		"tags":      "onboarding",
+	}, "list.csv", file))
+
+	if imp.Status != db.ImportStatusCompleted || imp.FinishedAt == nil {
+		t.Errorf("expected a completed import, got %+v", imp)
+	}
+	if imp.Source != "upload:list.csv" || imp.Format != "csv" {
+		t.Errorf("unexpected source/format: %q %q", imp.Source, imp.Format)
+	}
+	if imp.TotalRows != 4 || imp.CreatedRows != 1 || imp.FailedRows != 3 {
+		t.Errorf("unexpected counts: total %d created %d failed %d", imp.TotalRows, imp.CreatedRows, imp.FailedRows)
+	}
+	wantLines := []int{3, 4, 5}
+	if len(imp.RowErrors) != len(wantLines) {
+		t.Fatalf("expected %d row errors, got %+v", len(wantLines), imp.RowErrors)
+	}
+	for i, line := range wantLines {
+		if imp.RowErrors[i].Line != line {
+			t.Errorf("row error %d: expected line %d, got %+v", i, line, imp.RowErrors[i])
+		}
+	}
+
+	if len(notifications.notifications) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notifications.notifications))
+	}
+	for _, n := range notifications.notifications {
+		var payload map[string]string
+		_ = json.Unmarshal(n.Payload, &payload)
+		if payload["to"] != "ada@example.com" || payload["subject"] != "Hi Ada" || payload["body"] != "Welcome, Ada." {
+			t.Errorf("unexpected payload: %s", n.Payload)
+		}
+		if n.Status != db.StatusPending || n.TenantID.String() != importTenant || n.UserID.String() != user {
+			t.Errorf("unexpected notification: %+v", n)
+		}
+		if len(n.Tags) != 2 || n.Tags[0] != "onboarding" || n.Tags[1] != importTagPrefix+imp.ID.String() {
+			t.Errorf("unexpected tags: %v", n.Tags)
+		}
+	}
+}
+
+func TestCreateImport_S3(t *testing.T) {
+	notifications := NewMockRepository()
+	repo := newMockImportRepo()
+	h := NewImportHandler(zap.NewNop(), NewHandler(zap.NewNop(), notifications), repo)
+	key := importTenant + "/lists/march.jsonl"
+	h.SetSource(&mockImportSource{files: map[string]string{
+		key: `{"user_id":"` + uuid.New().String() + `","recipient":"+14155550100","code":4821}` + "\n" +
+			`{"user_id":"` + uuid.New().String() + `","recipient":"+14155550101","code":1337}` + "\n",
+	}})
+
+	body := `{"tenant_id":"` + importTenant + `","channel":"sms","s3_key":"` + key + `","payload":{"message":"Your code is {{code}}"}}`
+	imp := runImport(t, h, repo, httptest.NewRequest(http.MethodPost, "/v1/imports", strings.NewReader(body)))
+
+	if imp.Status != db.ImportStatusCompleted || imp.CreatedRows != 2 || imp.FailedRows != 0 {
+		t.Errorf("unexpected import: %+v", imp)
+	}
+	if imp.Source != "s3://imports-bucket/"+key || imp.Format != "jsonl" {
+		t.Errorf("unexpected source/format: %q %q", imp.Source, imp.Format)
+	}
+	for _, n := range notifications.notifications {
+		if !strings.Contains(string(n.Payload), `"phone_number":"+1415555010`) || !strings.Contains(string(n.Payload), "Your code is ") {
+			t.Errorf("unexpected payload: %s", n.Payload)
+		}
+	}
+}
+
+func TestCreateImport_UnreadableFileFails(t *testing.T) {
+	repo := newMockImportRepo()
+	h := NewImportHandler(zap.NewNop(), NewHandler(zap.NewNop(), NewMockRepository()), repo)
+	h.SetSource(&mockImportSource{})
+
+	body := `{"tenant_id":"` + importTenant + `","channel":"email","s3_key":"` + importTenant + `/missing.csv","payload":{"subject":"Hi"}}`
+	imp := runImport(t, h, repo, httptest.NewRequest(http.MethodPost, "/v1/imports", strings.NewReader(body)))
+
+	if imp.Status != db.ImportStatusFailed || imp.Error == nil || !strings.Contains(*imp.Error, "NoSuchKey") {
+		t.Errorf("expected a failed import with the S3 error, got %+v", imp)
+	}
+}
+
+func TestCreateImport_Validation(t *testing.T) {
+	payload := `"payload":{"subject":"Hi"}`
+	tests := []struct {
+		name   string
+		body   string
+		source bool
+	}{
+		{name: "malformed", body: `{`, source: true},
+		{name: "s3 not configured", body: `{"tenant_id":"` + importTenant + `","channel":"email","s3_key":"` + importTenant + `/a.csv",` + payload + `}`},
+		{name: "missing key", body: `{"tenant_id":"` + importTenant + `","channel":"email",` + payload + `}`, source: true},
+		{name: "missing payload", body: `{"tenant_id":"` + importTenant + `","channel":"email","s3_key":"` + importTenant + `/a.csv"}`, source: true},
+		{name: "bad tenant", body: `{"tenant_id":"nope","channel":"email","s3_key":"nope/a.csv",` + payload + `}`, source: true},
+		{name: "bad channel", body: `{"tenant_id":"` + importTenant + `","channel":"pigeon","s3_key":"` + importTenant + `/a.csv",` + payload + `}`, source: true},
+		{name: "unknown format", body: `{"tenant_id":"` + importTenant + `","channel":"email","s3_key":"` + importTenant + `/a.xlsx",` + payload + `}`, source: true},
+		{name: "payload not an object", body: `{"tenant_id":"` + importTenant + `","channel":"email","s3_key":"` + importTenant + `/a.csv","payload":"hi"}`, source: true},
+		{name: "other tenant's key", body: `{"tenant_id":"` + importTenant + `","channel":"email","s3_key":"00000000-0000-0000-0000-000000000002/a.csv",` + payload + `}`, source: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockImportRepo()
+			h := NewImportHandler(zap.NewNop(), NewHandler(zap.NewNop(), NewMockRepository()), repo)
+			if tt.source {
+				h.SetSource(&mockImportSource{})
+			}
+
+			rec := httptest.NewRecorder()
+			h.CreateImport(rec, httptest.NewRequest(http.MethodPost, "/v1/imports", strings.NewReader(tt.body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if len(repo.imports) != 0 {
+				t.Error("expected no import to be created")
+			}
+		})
+	}
+}
+
+func TestCreateImport_UploadWithoutFile(t *testing.T) {
+	h := NewImportHandler(zap.NewNop(), NewHandler(zap.NewNop(), NewMockRepository()), newMockImportRepo())
+	rec := httptest.NewRecorder()
+	h.CreateImport(rec, uploadRequest(t, map[string]string{
+		"tenant_id": importTenant,
+		"channel":   "email",
+		"payload":   `{"subject":"Hi"}`,
+	}, "", ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetImport(t *testing.T) {
+	repo := newMockImportRepo()
+	imp := &db.NotificationImport{ID: uuid.New(), TenantID: uuid.MustParse(importTenant), Status: db.ImportStatusProcessing}
+	_ = repo.CreateImport(context.Background(), imp)
+	h := NewImportHandler(zap.NewNop(), NewHandler(zap.NewNop(), NewMockRepository()), repo)
+
+	get := func(ctx context.Context, id string) *httptest.ResponseRecorder {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req := httptest.NewRequest(http.MethodGet, "/v1/imports/"+id, nil)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rec := httptest.NewRecorder()
+		h.GetImport(rec, req)
+		return rec
+	}
+
+	rec := get(context.Background(), imp.ID.String())
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"processing"`) {
+		t.Errorf("expected the import, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get(context.Background(), uuid.New().String()); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown import, got %d", rec.Code)
+	}
+	if rec := get(context.Background(), "nope"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad ID, got %d", rec.Code)
+	}
+	other := context.WithValue(context.Background(), contextKeyTenantID, uuid.New())
+	if rec := get(other, imp.ID.String()); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another tenant's import, got %d", rec.Code)
+	}
+}
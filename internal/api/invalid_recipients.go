@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// InvalidRecipientRepository reads and clears tenants' invalidated
+// recipients.
+type InvalidRecipientRepository interface {
+	ListInvalidRecipients(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]*db.InvalidRecipient, error)
+	ReinstateRecipient(ctx context.Context, tenantID uuid.UUID, channel, recipient string) error
+}
+
+// InvalidRecipientHandler reports the addresses the worker has stopped
+// sending to for a tenant: hard bounces and numbers or addresses the
+// provider rejected outright. A tenant can reinstate one once it's fixed.
+type InvalidRecipientHandler struct {
+	repo   InvalidRecipientRepository
+	logger *zap.Logger
+}
+
+// NewInvalidRecipientHandler creates a handler for the invalid recipient
+// report.
+func NewInvalidRecipientHandler(logger *zap.Logger, repo InvalidRecipientRepository) *InvalidRecipientHandler {
+	return &InvalidRecipientHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListInvalidRecipients handles GET /v1/tenants/{tenant_id}/invalid-recipients?channel=email&limit=20&offset=0
+func (h *InvalidRecipientHandler) ListInvalidRecipients(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	channel := r.URL.Query().Get("channel")
+	if channel != "" && !isValidChannel(channel) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidChannel, errDetailInvalidChannel)
+		return
+	}
+
+	limit := defaultPageLimit
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get(queryParamLimit)); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get(queryParamOffset)); err == nil && o >= 0 {
+		offset = o
+	}
+
+	recipients, err := h.repo.ListInvalidRecipients(r.Context(), tenantID, channel, limit, offset)
+	if err != nil {
+		h.logger.Error("failed to list invalid recipients", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list invalid recipients", "")
+		return
+	}
+	if recipients == nil {
+		recipients = []*db.InvalidRecipient{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":   recipients,
+		"limit":  limit,
+		"offset": offset,
+		"count":  len(recipients),
+	})
+}
+
+// ReinstateRecipient handles DELETE /v1/tenants/{tenant_id}/invalid-recipients?channel=email&recipient=a@example.com
+//
+// The recipient is a query parameter rather than a path segment because
+// email addresses and phone numbers don't survive path routing cleanly.
+func (h *InvalidRecipientHandler) ReinstateRecipient(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	channel := r.URL.Query().Get("channel")
+	if !isValidChannel(channel) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidChannel, errDetailInvalidChannel)
+		return
+	}
+	recipient := db.NormalizeRecipient(channel, r.URL.Query().Get("recipient"))
+	if recipient == "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Missing recipient", "the recipient query parameter is required")
+		return
+	}
+
+	err := h.repo.ReinstateRecipient(r.Context(), tenantID, channel, recipient)
+	if errors.Is(err, db.ErrNoInvalidRecipient) {
+		writeProblem(w, http.StatusNotFound, "not_found", "Recipient not invalidated", recipient+" is not on the tenant's invalid recipient list")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to reinstate recipient", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to reinstate recipient", "")
+		return
+	}
+
+	h.logger.Info("recipient reinstated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("channel", channel),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockInvalidRecipientRepo struct {
+	recipients []*db.InvalidRecipient
+}
+
+func (m *mockInvalidRecipientRepo) ListInvalidRecipients(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]*db.InvalidRecipient, error) {
+	var out []*db.InvalidRecipient
+	for _, ir := range m.recipients {
+		if ir.TenantID == tenantID && (channel == "" || ir.Channel == channel) {
+			out = append(out, ir)
+		}
+	}
+	if offset >= len(out) {
+		return nil, nil
+	}
+	out = out[offset:]
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockInvalidRecipientRepo) ReinstateRecipient(ctx context.Context, tenantID uuid.UUID, channel, recipient string) error {
+	for i, ir := range m.recipients {
+		if ir.TenantID == tenantID && ir.Channel == channel && ir.Recipient == recipient {
+			m.recipients = append(m.recipients[:i], m.recipients[i+1:]...)
+			return nil
+		}
+	}
+	return db.ErrNoInvalidRecipient
+}
+
+func invalidRecipientsRequest(method, tenantID string, query url.Values) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/invalid-recipients?"+query.Encode(), nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestListInvalidRecipients(t *testing.T) {
+	tenantID := uuid.New()
+	repo := &mockInvalidRecipientRepo{recipients: []*db.InvalidRecipient{
+		{TenantID: tenantID, Channel: db.ChannelEmail, Recipient: "bounced@example.com", Reason: db.InvalidRecipientHardBounce},
+		{TenantID: tenantID, Channel: db.ChannelSMS, Recipient: "+15550000000", Reason: db.InvalidRecipientRejected},
+		{TenantID: uuid.New(), Channel: db.ChannelEmail, Recipient: "other@example.com", Reason: db.InvalidRecipientHardBounce},
+	}}
+	handler := NewInvalidRecipientHandler(zap.NewNop(), repo)
+
+	tests := []struct {
+		name           string
+		query          url.Values
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"all channels", url.Values{}, http.StatusOK, 2},
+		{"one channel", url.Values{"channel": {db.ChannelEmail}}, http.StatusOK, 1},
+		{"unknown channel", url.Values{"channel": {"pigeon"}}, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ListInvalidRecipients(rec, invalidRecipientsRequest(http.MethodGet, tenantID.String(), tt.query))
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var body struct {
+				Data []*db.InvalidRecipient `json:"data"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(body.Data) != tt.expectedCount {
+				t.Errorf("expected %d recipients, got %d", tt.expectedCount, len(body.Data))
+			}
+		})
+	}
+}
+
+func TestReinstateRecipient(t *testing.T) {
+	tenantID := uuid.New()
+	repo := &mockInvalidRecipientRepo{recipients: []*db.InvalidRecipient{
+		{TenantID: tenantID, Channel: db.ChannelEmail, Recipient: "bounced@example.com", Reason: db.InvalidRecipientHardBounce},
+	}}
+	handler := NewInvalidRecipientHandler(zap.NewNop(), repo)
+
+	tests := []struct {
+		name           string
+		query          url.Values
+		expectedStatus int
+	}{
+		{"missing recipient", url.Values{"channel": {db.ChannelEmail}}, http.StatusBadRequest},
+		{"missing channel", url.Values{"recipient": {"bounced@example.com"}}, http.StatusBadRequest},
+		{"reinstated, case-insensitive", url.Values{"channel": {db.ChannelEmail}, "recipient": {"Bounced@Example.com"}}, http.StatusNoContent},
+		{"already reinstated", url.Values{"channel": {db.ChannelEmail}, "recipient": {"bounced@example.com"}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ReinstateRecipient(rec, invalidRecipientsRequest(http.MethodDelete, tenantID.String(), tt.query))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	maxAllowlistEntries = 100
+	allowlistCacheTTL   = 30 * time.Second
+)
+
+// IPAllowlistRepository reads and writes tenant source IP allowlists.
+type IPAllowlistRepository interface {
+	GetIPAllowlist(ctx context.Context, tenantID uuid.UUID) (*db.TenantIPAllowlist, error)
+	UpsertIPAllowlist(ctx context.Context, a *db.TenantIPAllowlist) error
+}
+
+// IPAllowlistRequest is the body of PUT /v2/ip-allowlist.
+type IPAllowlistRequest struct {
+	CIDRs []string `json:"cidrs"`
+}
+
+// IPAllowlistHandler lets tenants restrict which source addresses may use
+// their credentials, and enforces it through Middleware. Allowlists are
+// cached for allowlistCacheTTL; a PUT takes effect immediately on this
+// instance and within the TTL on others.
+type IPAllowlistHandler struct {
+	repo   IPAllowlistRepository
+	logger *zap.Logger
+	audit  *zap.Logger
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedAllowlist
+}
+
+type cachedAllowlist struct {
+	prefixes []netip.Prefix
+	loadedAt time.Time
+}
+
+// NewIPAllowlistHandler creates a handler for tenant IP allowlists.
+func NewIPAllowlistHandler(logger *zap.Logger, repo IPAllowlistRepository) *IPAllowlistHandler {
+	return &IPAllowlistHandler{
+		repo:   repo,
+		logger: logger,
+		audit:  logger.Named("audit"),
+		cache:  make(map[uuid.UUID]cachedAllowlist),
+	}
+}
+
+// Middleware rejects requests from addresses outside the authenticated
+// tenant's allowlist with 403 ip_not_allowed, and writes an audit log
+// entry for each rejection. It must run after BearerAuthMiddleware. The
+// client address is r.RemoteAddr, so behind a proxy it relies on
+// TrustedProxies.Middleware having run.
+func (h *IPAllowlistHandler) Middleware(next http.Handler) http.Handler {
+	return h.enforce(next, func(w http.ResponseWriter, r *http.Request, status int) {
+		if status == http.StatusForbidden {
+			writeV2Error(w, r, status, APIError{
+				Code:    ErrCodeIPNotAllowed,
+				Message: fmt.Sprintf("requests from %s are not allowed for this tenant", r.RemoteAddr),
+			})
+			return
+		}
+		writeV2Error(w, r, status, APIError{Code: ErrCodeInternal, Message: "failed to check ip allowlist"})
+	})
+}
+
+// V1Middleware is Middleware for /v1, where errors are problem+json. It
+// must run after V1AuthMiddleware.
+func (h *IPAllowlistHandler) V1Middleware(next http.Handler) http.Handler {
+	return h.enforce(next, func(w http.ResponseWriter, r *http.Request, status int) {
+		if status == http.StatusForbidden {
+			writeProblem(w, status, string(ErrCodeIPNotAllowed), "Source IP not allowed",
+				fmt.Sprintf("requests from %s are not allowed for this tenant", r.RemoteAddr))
+			return
+		}
+		writeProblem(w, status, errTypeInternalError, "Failed to check IP allowlist", "")
+	})
+}
+
+// enforce checks r against its tenant's allowlist and calls reject with
+// 403 or 500 instead of next when the request can't go on.
+func (h *IPAllowlistHandler) enforce(next http.Handler, reject func(w http.ResponseWriter, r *http.Request, status int)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, ok := TenantIDFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		prefixes, err := h.prefixes(r.Context(), tenantID)
+		if err != nil {
+			h.logger.Error("failed to load ip allowlist",
+				zap.Error(err),
+				zap.String(logFieldTenantID, tenantID.String()),
+			)
+			reject(w, r, http.StatusInternalServerError)
+			return
+		}
+		if len(prefixes) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip, ok := clientIP(r)
+		if !ok || !allowlistContains(prefixes, ip) {
+			token, _ := bearerToken(r)
+			if len(token) > 8 {
+				token = token[:8] + "..."
+			}
+			h.audit.Warn("api request rejected: source ip not allowed",
+				zap.String(logFieldTenantID, tenantID.String()),
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.String("token_prefix", token),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+			)
+			reject(w, r, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetAllowlist handles GET /v2/ip-allowlist
+func (h *IPAllowlistHandler) GetAllowlist(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	allowlist, err := h.repo.GetIPAllowlist(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to get ip allowlist",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to get ip allowlist"})
+		return
+	}
+
+	writeV2(w, http.StatusOK, Envelope{Data: allowlist, Meta: newMeta(r)})
+}
+
+// PutAllowlist handles PUT /v2/ip-allowlist. The body replaces the list;
+// an empty list lifts the restriction. A list that would exclude the
+// caller's own address is refused, so a tenant can't lock itself out.
+func (h *IPAllowlistHandler) PutAllowlist(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	var req IPAllowlistRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeMalformedJSON, Message: err.Error()})
+		return
+	}
+
+	prefixes, err := parseAllowlist(req.CIDRs)
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: err.Error(), Field: "cidrs"})
+		return
+	}
+	if len(prefixes) > 0 {
+		if ip, ok := clientIP(r); !ok || !allowlistContains(prefixes, ip) {
+			writeV2Error(w, r, http.StatusBadRequest, APIError{
+				Code:    ErrCodeInvalidField,
+				Message: fmt.Sprintf("cidrs must include your current address %s, or you would be locked out", r.RemoteAddr),
+				Field:   "cidrs",
+			})
+			return
+		}
+	}
+
+	allowlist := &db.TenantIPAllowlist{TenantID: tenantID, CIDRs: make([]string, len(prefixes))}
+	for i, p := range prefixes {
+		allowlist.CIDRs[i] = p.String()
+	}
+	if err := h.repo.UpsertIPAllowlist(r.Context(), allowlist); err != nil {
+		h.logger.Error("failed to save ip allowlist",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to save ip allowlist"})
+		return
+	}
+
+	h.mu.Lock()
+	h.cache[tenantID] = cachedAllowlist{prefixes: prefixes, loadedAt: time.Now()}
+	h.mu.Unlock()
+
+	h.audit.Info("ip allowlist updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.Strings("cidrs", allowlist.CIDRs),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeV2(w, http.StatusOK, Envelope{Data: allowlist, Meta: newMeta(r)})
+}
+
+// prefixes returns the tenant's allowlist, from cache when fresh.
+func (h *IPAllowlistHandler) prefixes(ctx context.Context, tenantID uuid.UUID) ([]netip.Prefix, error) {
+	h.mu.Lock()
+	cached, ok := h.cache[tenantID]
+	h.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < allowlistCacheTTL {
+		return cached.prefixes, nil
+	}
+
+	allowlist, err := h.repo.GetIPAllowlist(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	// Stored entries were validated on write; skip any that no longer parse
+	// rather than failing every request.
+	var prefixes []netip.Prefix
+	for _, c := range allowlist.CIDRs {
+		if p, err := netip.ParsePrefix(c); err == nil {
+			prefixes = append(prefixes, p)
+		}
+	}
+
+	h.mu.Lock()
+	h.cache[tenantID] = cachedAllowlist{prefixes: prefixes, loadedAt: time.Now()}
+	h.mu.Unlock()
+	return prefixes, nil
+}
+
+// parseAllowlist accepts CIDRs and bare addresses (as /32 or /128) and
+// returns them masked and de-duplicated.
+func parseAllowlist(entries []string) ([]netip.Prefix, error) {
+	if len(entries) > maxAllowlistEntries {
+		return nil, fmt.Errorf("at most %d cidrs are allowed", maxAllowlistEntries)
+	}
+	seen := make(map[netip.Prefix]bool, len(entries))
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, e := range entries {
+		p, err := netip.ParsePrefix(e)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(e)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR", e)
+			}
+			addr = addr.Unmap()
+			p = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		p = p.Masked()
+		if !seen[p] {
+			seen[p] = true
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes, nil
+}
+
+func allowlistContains(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
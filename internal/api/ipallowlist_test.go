@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockAllowlistRepo struct {
+	saved map[uuid.UUID]*db.TenantIPAllowlist
+}
+
+func (m *mockAllowlistRepo) GetIPAllowlist(ctx context.Context, tenantID uuid.UUID) (*db.TenantIPAllowlist, error) {
+	if a, ok := m.saved[tenantID]; ok {
+		return a, nil
+	}
+	return &db.TenantIPAllowlist{TenantID: tenantID, CIDRs: []string{}}, nil
+}
+
+func (m *mockAllowlistRepo) UpsertIPAllowlist(ctx context.Context, a *db.TenantIPAllowlist) error {
+	m.saved[a.TenantID] = a
+	return nil
+}
+
+func newAllowlistRouter(repo *mockAllowlistRepo) http.Handler {
+	allowlists := NewIPAllowlistHandler(zap.NewNop(), repo)
+	v2 := NewV2Handler(NewHandler(zap.NewNop(), NewMockRepository()))
+
+	r := chi.NewRouter()
+	r.Use(BearerAuthMiddleware(map[string]string{"token-a": v2TenantA, "token-b": v2TenantB}, zap.NewNop()))
+	r.Use(allowlists.Middleware)
+	r.Get("/v2/notifications", v2.ListNotifications)
+	r.Get("/v2/ip-allowlist", allowlists.GetAllowlist)
+	r.Put("/v2/ip-allowlist", allowlists.PutAllowlist)
+	return r
+}
+
+func doFrom(t *testing.T, h http.Handler, method, path, token, remoteAddr string, body any) (*httptest.ResponseRecorder, Envelope) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var env Envelope
+	_ = json.Unmarshal(rec.Body.Bytes(), &env)
+	return rec, env
+}
+
+func TestIPAllowlist_Enforced(t *testing.T) {
+	repo := &mockAllowlistRepo{saved: map[uuid.UUID]*db.TenantIPAllowlist{}}
+	router := newAllowlistRouter(repo)
+
+	// Unrestricted until a list is set.
+	rec, _ := doFrom(t, router, http.MethodGet, "/v2/notifications", "token-a", "198.51.100.7:4000", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("no allowlist: expected 200, got %d", rec.Code)
+	}
+
+	rec, env := doFrom(t, router, http.MethodPut, "/v2/ip-allowlist", "token-a", "203.0.113.10:4000",
+		IPAllowlistRequest{CIDRs: []string{"203.0.113.0/24", "2001:db8::1"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data, _ := json.Marshal(env.Data)
+	var saved db.TenantIPAllowlist
+	_ = json.Unmarshal(data, &saved)
+	if len(saved.CIDRs) != 2 || saved.CIDRs[1] != "2001:db8::1/128" {
+		t.Errorf("expected normalized cidrs, got %v", saved.CIDRs)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		remoteAddr string
+		expected   int
+	}{
+		{"inside range", "token-a", "203.0.113.99:5000", http.StatusOK},
+		{"bare ip after RealIP", "token-a", "203.0.113.99", http.StatusOK},
+		{"ipv6 host", "token-a", "[2001:db8::1]:443", http.StatusOK},
+		{"outside range", "token-a", "198.51.100.7:4000", http.StatusForbidden},
+		{"other tenant unaffected", "token-b", "198.51.100.7:4000", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, env := doFrom(t, router, http.MethodGet, "/v2/notifications", tt.token, tt.remoteAddr, nil)
+			if rec.Code != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, rec.Code)
+			}
+			if tt.expected == http.StatusForbidden && (len(env.Errors) != 1 || env.Errors[0].Code != ErrCodeIPNotAllowed) {
+				t.Errorf("expected ip_not_allowed, got %+v", env.Errors)
+			}
+		})
+	}
+}
+
+func TestIPAllowlist_PutValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		cidrs    []string
+		expected int
+	}{
+		{"lockout refused", []string{"10.0.0.0/8"}, http.StatusBadRequest},
+		{"not an address", []string{"example.com"}, http.StatusBadRequest},
+		{"host bits masked", []string{"203.0.113.10/24"}, http.StatusOK},
+		{"empty list lifts restriction", []string{}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockAllowlistRepo{saved: map[uuid.UUID]*db.TenantIPAllowlist{}}
+			rec, _ := doFrom(t, newAllowlistRouter(repo), http.MethodPut, "/v2/ip-allowlist", "token-a", "203.0.113.10:4000",
+				IPAllowlistRequest{CIDRs: tt.cidrs})
+			if rec.Code != tt.expected {
+				t.Fatalf("expected %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+			saved := repo.saved[uuid.MustParse(v2TenantA)]
+			if tt.expected != http.StatusOK {
+				if saved != nil {
+					t.Error("expected nothing to be saved")
+				}
+				return
+			}
+			if len(tt.cidrs) == 1 && saved.CIDRs[0] != "203.0.113.0/24" {
+				t.Errorf("expected masked prefix, got %v", saved.CIDRs)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// JobRunRepository reads background job run records.
+type JobRunRepository interface {
+	ListJobRuns(ctx context.Context) ([]*db.JobRun, error)
+}
+
+// JobsHandler serves the admin view of background jobs.
+type JobsHandler struct {
+	repo   JobRunRepository
+	logger *zap.Logger
+}
+
+// NewJobsHandler creates the admin handler for background job runs.
+func NewJobsHandler(logger *zap.Logger, repo JobRunRepository) *JobsHandler {
+	return &JobsHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListJobs handles GET /v1/admin/jobs. Jobs that have never run on any
+// replica have no record yet and are not listed.
+func (h *JobsHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.repo.ListJobRuns(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list job runs", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list jobs", "")
+		return
+	}
+	if runs == nil {
+		runs = []*db.JobRun{}
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"jobs": runs,
+	})
+}
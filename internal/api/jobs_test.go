@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockJobRunRepo struct {
+	runs []*db.JobRun
+	err  error
+}
+
+func (m *mockJobRunRepo) ListJobRuns(ctx context.Context) ([]*db.JobRun, error) {
+	return m.runs, m.err
+}
+
+func TestListJobs(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name           string
+		repo           *mockJobRunRepo
+		expectedStatus int
+		expectedJobs   int
+	}{
+		{"lists runs", &mockJobRunRepo{runs: []*db.JobRun{{Name: "stuck-reaper", LastSuccessAt: &now, RunCount: 3}}}, http.StatusOK, 1},
+		{"no runs yet", &mockJobRunRepo{}, http.StatusOK, 0},
+		{"repository error", &mockJobRunRepo{err: errors.New("db down")}, http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewJobsHandler(zap.NewNop(), tt.repo)
+			rec := httptest.NewRecorder()
+			handler.ListJobs(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/jobs", nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Jobs []db.JobRun `json:"jobs"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Jobs == nil || len(resp.Jobs) != tt.expectedJobs {
+				t.Errorf("expected %d jobs, got %v", tt.expectedJobs, resp.Jobs)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/maintenance"
+)
+
+// MaintenanceMiddleware refuses writes with 503 while maintenance mode is on.
+// Safe methods (GET, HEAD, OPTIONS) keep working so dashboards and status
+// polling stay up during a migration or provider incident.
+func MaintenanceMiddleware(mode *maintenance.Mode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mode == nil || !mode.Enabled() || isSafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(mode.RetryAfter().Seconds())))
+			writeProblem(w, http.StatusServiceUnavailable, "maintenance_mode",
+				"Service under maintenance",
+				"writes are temporarily disabled; reads are unaffected. Retry after the specified time.")
+		})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// MaintenanceHandler serves the admin endpoints that flip maintenance mode.
+type MaintenanceHandler struct {
+	mode   *maintenance.Mode
+	logger *zap.Logger
+}
+
+// NewMaintenanceHandler creates the admin handler for a maintenance switch.
+func NewMaintenanceHandler(mode *maintenance.Mode, logger *zap.Logger) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		mode:   mode,
+		logger: logger,
+	}
+}
+
+// GetStatus handles GET /v1/admin/maintenance
+func (h *MaintenanceHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(h.mode.Status())
+}
+
+// SetStatus handles PUT /v1/admin/maintenance {"enabled": true, "reason": "..."}
+func (h *MaintenanceHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled *bool  `json:"enabled"`
+		Reason  string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+	if req.Enabled == nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMissingFields, "enabled is required")
+		return
+	}
+
+	if *req.Enabled {
+		h.mode.Enable(req.Reason)
+	} else {
+		h.mode.Disable()
+	}
+
+	h.GetStatus(w, r)
+}
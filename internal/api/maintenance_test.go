@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/maintenance"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	mode := maintenance.New(true, 30*time.Second, zap.NewNop())
+	handler := MaintenanceMiddleware(mode)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		method         string
+		expectedStatus int
+	}{
+		{http.MethodGet, http.StatusOK},
+		{http.MethodHead, http.StatusOK},
+		{http.MethodPost, http.StatusServiceUnavailable},
+		{http.MethodPatch, http.StatusServiceUnavailable},
+		{http.MethodDelete, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/notifications", nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") != "30" {
+				t.Errorf("expected Retry-After 30, got %q", rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+
+	// Once disabled, writes flow again.
+	mode.Disable()
+	req := httptest.NewRequest(http.MethodPost, "/v1/notifications", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 after disabling maintenance, got %d", rec.Code)
+	}
+}
+
+func TestMaintenanceHandler_SetStatus(t *testing.T) {
+	mode := maintenance.New(false, 0, zap.NewNop())
+	h := NewMaintenanceHandler(mode, zap.NewNop())
+
+	body, _ := json.Marshal(map[string]interface{}{"enabled": true, "reason": "migration 005"})
+	req := httptest.NewRequest(http.MethodPut, "/v1/admin/maintenance", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	h.SetStatus(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var status maintenance.Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !status.Enabled || status.Reason != "migration 005" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if !mode.Enabled() {
+		t.Error("expected maintenance mode to be enabled")
+	}
+
+	// Missing "enabled" is rejected rather than treated as false.
+	req = httptest.NewRequest(http.MethodPut, "/v1/admin/maintenance", bytes.NewReader([]byte(`{"reason":"x"}`)))
+	rec = httptest.NewRecorder()
+	h.SetStatus(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for missing enabled, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// MaskMode is how payloads are hidden from read-only callers.
+type MaskMode string
+
+const (
+	// MaskModeMask keeps the payload's top-level keys, so support staff can
+	// tell an email from a templated one, but replaces every value.
+	MaskModeMask MaskMode = "mask"
+	// MaskModeOmit returns the payload as null.
+	MaskModeOmit MaskMode = "omit"
+)
+
+const maskedValue = "***"
+
+// SetMaskMode sets how payloads are hidden from read-only callers. The
+// default is MaskModeMask.
+func (v *V2Handler) SetMaskMode(mode MaskMode) {
+	v.maskMode = mode
+}
+
+// SetMaskMode sets how payloads are hidden from read-only keys and tokens
+// on /v1. The default is MaskModeMask.
+func (h *Handler) SetMaskMode(mode MaskMode) {
+	h.maskMode = mode
+}
+
+// maskNotification returns n as the caller may see it. Read-only callers
+// get a copy with the payload masked; status, metadata, tags and errors are
+// left intact. The original is never modified.
+func maskNotification(r *http.Request, n *db.Notification, mode MaskMode) *db.Notification {
+	if RoleFromContext(r.Context()) != RoleReadOnly {
+		return n
+	}
+	masked := *n
+	masked.Payload = maskPayload(n.Payload, mode)
+	return &masked
+}
+
+// maskDeadLetter is maskNotification for DLQ items.
+func maskDeadLetter(r *http.Request, item *db.DeadLetterNotification, mode MaskMode) *db.DeadLetterNotification {
+	if RoleFromContext(r.Context()) != RoleReadOnly {
+		return item
+	}
+	masked := *item
+	masked.Payload = maskPayload(item.Payload, mode)
+	return &masked
+}
+
+// maskPayload hides payload contents. Anything that isn't a JSON object is
+// omitted outright, since there are no keys worth keeping.
+func maskPayload(payload json.RawMessage, mode MaskMode) json.RawMessage {
+	if mode == MaskModeOmit || len(payload) == 0 {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil || fields == nil {
+		return nil
+	}
+	for k := range fields {
+		fields[k] = json.RawMessage(`"` + maskedValue + `"`)
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	maxTags          = 20
+	maxTagLength     = 64
+	maxMetadataBytes = 4096
+	maxSLASeconds    = 7 * 24 * 60 * 60
+	maxGroupKeyBytes = 128
+)
+
+// validateMetadata checks the free-form metadata is a JSON object of
+// reasonable size. It is stored as JSONB and returned verbatim, so we only
+// constrain its shape, not its keys.
+func validateMetadata(metadata json.RawMessage) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	if len(metadata) > maxMetadataBytes {
+		return fmt.Errorf("metadata must be at most %d bytes", maxMetadataBytes)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &obj); err != nil || obj == nil {
+		return fmt.Errorf("metadata must be a JSON object")
+	}
+	return nil
+}
+
+// validateSLA checks an optional delivery deadline is between one second
+// and a week.
+func validateSLA(slaSeconds *int) error {
+	if slaSeconds == nil {
+		return nil
+	}
+	if *slaSeconds < 1 || *slaSeconds > maxSLASeconds {
+		return fmt.Errorf("sla_seconds must be between 1 and %d", maxSLASeconds)
+	}
+	return nil
+}
+
+// validateGroupKey checks an optional group key is 1-128 characters of the
+// tag charset. It is compared exactly, so "Incident-42" and "incident-42"
+// are different groups.
+func validateGroupKey(key string) error {
+	if key == "" {
+		return nil
+	}
+	if len(key) > maxGroupKeyBytes || !isValidTagChars(key) {
+		return fmt.Errorf("group_key must be 1-%d characters of [A-Za-z0-9-_.:]", maxGroupKeyBytes)
+	}
+	return nil
+}
+
+// validateCategory checks a category name's shape. Whether the tenant has
+// defined it is checked separately.
+func validateCategory(name string) error {
+	if name == "" {
+		return nil
+	}
+	if len(name) > maxTagLength || !isValidTagChars(name) {
+		return fmt.Errorf("category must be 1-%d characters of [A-Za-z0-9-_.:]", maxTagLength)
+	}
+	return nil
+}
+
+// normalizeTags validates tags and drops duplicates, keeping first-seen
+// order. Tags are matched exactly by ?tag=, so they are kept to a small,
+// URL-safe charset.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxTags)
+	}
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !isValidTag(tag) {
+			return nil, fmt.Errorf("tag %q must be 1-%d characters of [A-Za-z0-9-_.:]", tag, maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out, nil
+}
+
+func isValidTag(tag string) bool {
+	return tag != "" && len(tag) <= maxTagLength && isValidTagChars(tag)
+}
+
+func isValidTagChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
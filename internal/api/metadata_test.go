@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		wantErr  bool
+	}{
+		{"absent", "", false},
+		{"object", `{"order_id":"ord_42","amount":19.99}`, false},
+		{"empty object", `{}`, false},
+		{"array", `["a"]`, true},
+		{"string", `"order"`, true},
+		{"null", `null`, true},
+		{"too large", `{"k":"` + strings.Repeat("x", maxMetadataBytes) + `"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMetadata(json.RawMessage(tt.metadata))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMetadata(%s) error = %v, wantErr %v", tt.metadata, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr bool
+	}{
+		{"none", nil, []string{}, false},
+		{"dedupes in order", []string{"b", "a", "b"}, []string{"b", "a"}, false},
+		{"allowed punctuation", []string{"order-confirmation", "campaign:spring_24.v2"}, []string{"order-confirmation", "campaign:spring_24.v2"}, false},
+		{"empty tag", []string{""}, nil, true},
+		{"space", []string{"order confirmation"}, nil, true},
+		{"too long", []string{strings.Repeat("a", maxTagLength+1)}, nil, true},
+		{"too many", make([]string, maxTags+1), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeTags(%v) error = %v, wantErr %v", tt.tags, err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("normalizeTags(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateNotification_StoresMetadataAndTags(t *testing.T) {
+	mockRepo := NewMockRepository()
+	handler := NewHandler(zap.NewNop(), mockRepo)
+
+	body, _ := json.Marshal(NotificationRequest{
+		TenantID: "00000000-0000-0000-0000-000000000001",
+		UserID:   "00000000-0000-0000-0000-000000000002",
+		Channel:  "email",
+		Payload:  json.RawMessage(`{"to":"user@example.com"}`),
+		Metadata: json.RawMessage(`{"order_id":"ord_42"}`),
+		Tags:     []string{"order-confirmation", "order-confirmation", "eu"},
+	})
+	rec := httptest.NewRecorder()
+	handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp NotificationResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+
+	stored := mockRepo.notifications[resp.ID]
+	if stored == nil {
+		t.Fatal("expected notification to be stored")
+	}
+	if string(stored.Metadata) != `{"order_id":"ord_42"}` {
+		t.Errorf("unexpected metadata %s", stored.Metadata)
+	}
+	if strings.Join(stored.Tags, ",") != "order-confirmation,eu" {
+		t.Errorf("expected deduplicated tags, got %v", stored.Tags)
+	}
+}
+
+func TestCreateNotification_SLA(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		slaSeconds int
+		wantStatus int
+	}{
+		{"within range", 300, http.StatusCreated},
+		{"zero", 0, http.StatusBadRequest},
+		{"over a week", maxSLASeconds + 1, http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+
+			sla := tt.slaSeconds
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID:   "00000000-0000-0000-0000-000000000001",
+				UserID:     "00000000-0000-0000-0000-000000000002",
+				Channel:    "email",
+				Payload:    json.RawMessage(`{"to":"user@example.com"}`),
+				SLASeconds: &sla,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var resp NotificationResponse
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+			stored := mockRepo.notifications[resp.ID]
+			if stored == nil || stored.SLASeconds == nil || *stored.SLASeconds != tt.slaSeconds {
+				t.Errorf("expected sla_seconds %d to be stored, got %+v", tt.slaSeconds, stored)
+			}
+		})
+	}
+}
+
+func TestValidateGroupKey(t *testing.T) {
+	for _, tt := range []struct {
+		key     string
+		wantErr bool
+	}{
+		{"", false},
+		{"incident:db-primary.42", false},
+		{strings.Repeat("a", maxGroupKeyBytes), false},
+		{strings.Repeat("a", maxGroupKeyBytes+1), true},
+		{"incident 42", true},
+		{"incident/42", true},
+	} {
+		if err := validateGroupKey(tt.key); (err != nil) != tt.wantErr {
+			t.Errorf("validateGroupKey(%q) = %v, wantErr %v", tt.key, err, tt.wantErr)
+		}
+	}
+}
+
+func TestListNotifications_TagFilter(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	mockRepo := NewMockRepository()
+	for _, tags := range [][]string{{"order-confirmation"}, {"marketing"}, nil} {
+		id := uuid.New()
+		mockRepo.notifications[id.String()] = &db.Notification{ID: id, TenantID: tenantID, Tags: tags}
+	}
+	handler := NewHandler(zap.NewNop(), mockRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/notifications?tenant_id="+tenantID.String()+"&tag=order-confirmation", nil)
+	rec := httptest.NewRecorder()
+	handler.ListNotifications(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if mockRepo.lastFilter.Tag != "order-confirmation" {
+		t.Errorf("expected tag filter to reach the repository, got %+v", mockRepo.lastFilter)
+	}
+	var resp struct {
+		Count int `json:"count"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Count != 1 {
+		t.Errorf("expected 1 tagged notification, got %d", resp.Count)
+	}
+
+	bad := httptest.NewRequest(http.MethodGet, "/v1/notifications?tenant_id="+tenantID.String()+"&tag=not%20valid", nil)
+	rec = httptest.NewRecorder()
+	handler.ListNotifications(rec, bad)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid tag, got %d", rec.Code)
+	}
+}
@@ -6,11 +6,25 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 
+	"github.com/lalithlochan/nimbus/internal/observ"
 	"github.com/lalithlochan/nimbus/internal/redis"
 )
 
+// RequestLoggerMiddleware stores a logger tagged with the request ID in the
+// request context, for handlers to pick up with observ.Logger. It must run
+// after middleware.RequestID.
+func RequestLoggerMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := observ.With(r.Context(), logger, zap.String(observ.FieldRequestID, middleware.GetReqID(r.Context())))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // RateLimitMiddleware creates an HTTP middleware that enforces rate limits.
 // The keyFunc extracts the rate limit key from the request (e.g., tenant ID, IP).
 func RateLimitMiddleware(limiter *redis.RateLimiter, logger *zap.Logger, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
@@ -27,29 +41,37 @@ func RateLimitMiddleware(limiter *redis.RateLimiter, logger *zap.Logger, keyFunc
 				return
 			}
 
-			result, err := limiter.Allow(r.Context(), key)
-			if err != nil {
-				logger.Warn("rate limit check failed", zap.Error(err))
+			if !enforceRateLimit(w, r, limiter, logger, key, nil) {
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RouteRateLimitMiddleware applies stricter limits to individual routes on
+// top of the per-tenant limit. limiters is keyed by exact request path
+// (e.g. "/v1/ai/compose"); paths without an entry pass straight through.
+// Each route gets its own bucket per key, so exhausting /v1/ai/compose does
+// not eat into the tenant's /v1/notifications budget. Requests marked by
+// RateLimitExemptMiddleware skip it.
+func RouteRateLimitMiddleware(limiters map[string]*redis.RateLimiter, logger *zap.Logger, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := limiters[r.URL.Path]
+			if limiter == nil || rateLimitExempt(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := keyFunc(r)
+			if key == "" {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Set rate limit headers
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Remaining+1))
-			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
-			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
-
-			if !result.Allowed {
-				retryAfter := time.Until(result.ResetAt).Seconds()
-				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
-				w.Header().Set("Content-Type", "application/problem+json")
-				w.WriteHeader(http.StatusTooManyRequests)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-					Type:   "rate_limit_exceeded",
-					Title:  "Too Many Requests",
-					Status: http.StatusTooManyRequests,
-					Detail: "Rate limit exceeded. Please retry after the specified time.",
-				})
+			if !enforceRateLimit(w, r, limiter, logger, "route:"+r.URL.Path+":"+key, nil) {
 				return
 			}
 
@@ -58,6 +80,81 @@ func RateLimitMiddleware(limiter *redis.RateLimiter, logger *zap.Logger, keyFunc
 	}
 }
 
+// rateLimitPolicy overrides the limiter's defaults for one key.
+type rateLimitPolicy struct {
+	burst      int
+	retryAfter time.Duration // 0 means until the window resets
+}
+
+// enforceRateLimit checks the limiter, sets the rate limit headers, and
+// writes a 429 if the request is over the limit. It returns false when the
+// request was rejected. A nil policy uses the limiter's defaults. Limiter
+// errors fail open.
+func enforceRateLimit(w http.ResponseWriter, r *http.Request, limiter *redis.RateLimiter, logger *zap.Logger, key string, policy *rateLimitPolicy) bool {
+	var result *redis.RateLimitResult
+	var err error
+	if policy != nil {
+		result, err = limiter.AllowBurst(r.Context(), key, policy.burst)
+	} else {
+		result, err = limiter.Allow(r.Context(), key)
+	}
+	if err != nil {
+		// The limiter logs when it starts and stops failing.
+		logger.Debug("rate limit check failed", zap.Error(err))
+		return true
+	}
+
+	setRateLimitHeaders(w.Header(), result)
+
+	if result.Allowed {
+		return true
+	}
+
+	// A tenant's retry-after hint can shorten the cool-down, never stretch
+	// it past the point the window has fully reset.
+	retryAfter := secondsUntil(result.ResetAt)
+	if policy != nil && policy.retryAfter > 0 {
+		retryAfter = min(retryAfter, int((policy.retryAfter+time.Second-1)/time.Second))
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{
+		Type:   "rate_limit_exceeded",
+		Title:  "Too Many Requests",
+		Status: http.StatusTooManyRequests,
+		Detail: "Rate limit exceeded. Please retry after the specified time.",
+	})
+	return false
+}
+
+// setRateLimitHeaders emits both the legacy X-RateLimit-* headers and the
+// IETF draft RateLimit-* set (draft-ietf-httpapi-ratelimit-headers).
+// The legacy Reset is a Unix timestamp; the draft Reset is delta-seconds.
+func setRateLimitHeaders(h http.Header, result *redis.RateLimitResult) {
+	limit := strconv.Itoa(result.Limit)
+	remaining := strconv.Itoa(result.Remaining)
+	reset := strconv.Itoa(secondsUntil(result.ResetAt))
+
+	h.Set("X-RateLimit-Limit", limit)
+	h.Set("X-RateLimit-Remaining", remaining)
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+	h.Set("RateLimit-Limit", limit)
+	h.Set("RateLimit-Remaining", remaining)
+	h.Set("RateLimit-Reset", reset)
+	h.Set("RateLimit-Policy", limit+";w="+strconv.Itoa(int(result.Window.Seconds())))
+}
+
+// secondsUntil rounds up so clients never retry a fraction of a second early.
+func secondsUntil(t time.Time) int {
+	d := time.Until(t)
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
 // TenantKeyFunc extracts tenant ID from the X-Tenant-ID header or query param.
 func TenantKeyFunc(r *http.Request) string {
 	if tenantID := r.Header.Get("X-Tenant-ID"); tenantID != "" {
@@ -69,6 +166,12 @@ func TenantKeyFunc(r *http.Request) string {
 	return ""
 }
 
+// GlobalKeyFunc puts every request in a single bucket, for a service-wide
+// ceiling that applies regardless of tenant.
+func GlobalKeyFunc(r *http.Request) string {
+	return "global"
+}
+
 // IPKeyFunc extracts the client IP for rate limiting.
 func IPKeyFunc(r *http.Request) string {
 	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
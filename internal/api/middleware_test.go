@@ -1,9 +1,17 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/redis"
 )
 
 func TestTenantKeyFunc(t *testing.T) {
@@ -89,3 +97,128 @@ func TestRateLimitMiddleware_NoLimiter(t *testing.T) {
 		t.Errorf("expected 200, got %d", rec.Code)
 	}
 }
+
+func newTestLimiter(t *testing.T, limit int, window time.Duration) *redis.RateLimiter {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, _ := strconv.Atoi(mr.Port())
+
+	client, err := redis.New(context.Background(), redis.Config{Host: mr.Host(), Port: port}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	return redis.NewRateLimiter(client, zap.NewNop(), redis.RateLimitConfig{
+		Limit:  limit,
+		Window: window,
+	})
+}
+
+func TestRateLimitMiddleware_Headers(t *testing.T) {
+	limiter := newTestLimiter(t, 3, time.Minute)
+	handler := RateLimitMiddleware(limiter, zap.NewNop(), TenantKeyFunc)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-123")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		// Limit is the configured ceiling, not Remaining+1.
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("request %d: expected X-RateLimit-Limit 3, got %q", i, got)
+		}
+		if got := rec.Header().Get("RateLimit-Limit"); got != "3" {
+			t.Errorf("request %d: expected RateLimit-Limit 3, got %q", i, got)
+		}
+		if got := rec.Header().Get("RateLimit-Policy"); got != "3;w=60" {
+			t.Errorf("request %d: expected RateLimit-Policy 3;w=60, got %q", i, got)
+		}
+
+		wantRemaining := strconv.Itoa(max(0, 2-i))
+		if got := rec.Header().Get("RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("request %d: expected RateLimit-Remaining %s, got %q", i, wantRemaining, got)
+		}
+
+		reset, err := strconv.Atoi(rec.Header().Get("RateLimit-Reset"))
+		if err != nil || reset <= 0 || reset > 60 {
+			t.Errorf("request %d: expected RateLimit-Reset in (0, 60], got %q", i, rec.Header().Get("RateLimit-Reset"))
+		}
+
+		if i < 3 && rec.Code != http.StatusOK {
+			t.Errorf("request %d: expected 200, got %d", i, rec.Code)
+		}
+		if i == 3 {
+			if rec.Code != http.StatusTooManyRequests {
+				t.Errorf("expected 429 once limit is exhausted, got %d", rec.Code)
+			}
+			if rec.Header().Get("Retry-After") == "" {
+				t.Error("expected Retry-After on 429")
+			}
+		}
+	}
+}
+
+func TestRouteRateLimitMiddleware(t *testing.T) {
+	limiters := map[string]*redis.RateLimiter{
+		"/v1/ai/compose": newTestLimiter(t, 1, time.Minute),
+	}
+	handler := RouteRateLimitMiddleware(limiters, zap.NewNop(), TenantKeyFunc)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	send := func(path string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-123")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("/v1/ai/compose"); code != http.StatusOK {
+		t.Fatalf("first compose request: expected 200, got %d", code)
+	}
+	if code := send("/v1/ai/compose"); code != http.StatusTooManyRequests {
+		t.Fatalf("second compose request: expected 429, got %d", code)
+	}
+	// Routes without a configured limit are unaffected.
+	for i := 0; i < 3; i++ {
+		if code := send("/v1/notifications"); code != http.StatusOK {
+			t.Fatalf("notifications request %d: expected 200, got %d", i, code)
+		}
+	}
+}
+
+func TestRateLimitMiddleware_GlobalCeiling(t *testing.T) {
+	limiter := newTestLimiter(t, 2, time.Second)
+	handler := RateLimitMiddleware(limiter, zap.NewNop(), GlobalKeyFunc)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	// Requests from different tenants share the one global bucket.
+	codes := make([]int, 0, 3)
+	for _, tenant := range []string{"tenant-a", "tenant-b", "tenant-c"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK {
+		t.Errorf("expected first two requests to pass, got %v", codes)
+	}
+	if codes[2] != http.StatusTooManyRequests {
+		t.Errorf("expected third request to hit the global ceiling, got %d", codes[2])
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/circuitbreaker"
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	defaultOverviewWindow = time.Hour
+	maxOverviewWindow     = 24 * time.Hour
+	overviewTopTenants    = 10
+)
+
+// OverviewRepository reads platform-wide queue state.
+type OverviewRepository interface {
+	GetQueueOverview(ctx context.Context, since time.Time, topTenants int) (*db.QueueOverview, error)
+}
+
+// HeartbeatSource returns the latest heartbeat of each live worker.
+type HeartbeatSource func(ctx context.Context) ([]json.RawMessage, error)
+
+// OverviewHandler serves a single system-wide snapshot for the ops
+// dashboard: queue depth, per-channel outcomes, circuit breakers, worker
+// heartbeats and the tenants failing most.
+type OverviewHandler struct {
+	repo       OverviewRepository
+	breakers   []*circuitbreaker.CircuitBreaker
+	heartbeats HeartbeatSource
+	logger     *zap.Logger
+}
+
+// NewOverviewHandler creates the admin overview handler.
+func NewOverviewHandler(logger *zap.Logger, repo OverviewRepository, breakers []*circuitbreaker.CircuitBreaker, heartbeats HeartbeatSource) *OverviewHandler {
+	return &OverviewHandler{
+		repo:       repo,
+		breakers:   breakers,
+		heartbeats: heartbeats,
+		logger:     logger,
+	}
+}
+
+type overviewResponse struct {
+	GeneratedAt   time.Time `json:"generated_at"`
+	WindowSeconds int64     `json:"window_seconds"`
+	*db.QueueOverview
+	CircuitBreakers []circuitbreaker.Stats `json:"circuit_breakers"`
+	Workers         []json.RawMessage      `json:"workers"`
+}
+
+// GetOverview handles GET /v1/admin/overview?window=1h. window bounds the
+// throughput, error rate and failing tenant figures; queue depth, breakers
+// and heartbeats are current. A heartbeat lookup failure is logged and
+// leaves workers null rather than failing the whole overview.
+func (h *OverviewHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	window := defaultOverviewWindow
+	if s := r.URL.Query().Get("window"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 || d > maxOverviewWindow {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid window", "window must be a duration between 1s and 24h, e.g. 15m")
+			return
+		}
+		window = d
+	}
+
+	now := time.Now().UTC()
+	overview, err := h.repo.GetQueueOverview(r.Context(), now.Add(-window), overviewTopTenants)
+	if err != nil {
+		h.logger.Error("failed to get queue overview", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get overview", "")
+		return
+	}
+
+	resp := overviewResponse{
+		GeneratedAt:     now,
+		WindowSeconds:   int64(window / time.Second),
+		QueueOverview:   overview,
+		CircuitBreakers: make([]circuitbreaker.Stats, 0, len(h.breakers)),
+	}
+	for _, b := range h.breakers {
+		resp.CircuitBreakers = append(resp.CircuitBreakers, b.Stats())
+	}
+	if h.heartbeats != nil {
+		if resp.Workers, err = h.heartbeats(r.Context()); err != nil {
+			h.logger.Warn("failed to list worker heartbeats", zap.Error(err))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/circuitbreaker"
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockOverviewRepo struct {
+	since time.Time
+}
+
+func (m *mockOverviewRepo) GetQueueOverview(ctx context.Context, since time.Time, topTenants int) (*db.QueueOverview, error) {
+	m.since = since
+	return &db.QueueOverview{
+		Due:               42,
+		Channels:          []*db.ChannelThroughput{{Channel: db.ChannelEmail, Sent: 90, Failed: 10, ErrorRate: 0.1}},
+		TopFailingTenants: []*db.TenantFailures{{TenantID: uuid.New(), Failed: 10, Finished: 20, ErrorRate: 0.5}},
+	}, nil
+}
+
+func TestGetOverview(t *testing.T) {
+	repo := &mockOverviewRepo{}
+	breaker := circuitbreaker.New(circuitbreaker.Config{Name: "ses-email"}, zap.NewNop())
+	heartbeats := func(ctx context.Context) ([]json.RawMessage, error) {
+		return []json.RawMessage{json.RawMessage(`{"instance":"host-1"}`)}, nil
+	}
+	handler := NewOverviewHandler(zap.NewNop(), repo, []*circuitbreaker.CircuitBreaker{breaker}, heartbeats)
+
+	rec := httptest.NewRecorder()
+	handler.GetOverview(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/overview?window=15m", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if d := time.Since(repo.since); d < 15*time.Minute || d > 16*time.Minute {
+		t.Errorf("expected a 15m window, got %s", d)
+	}
+
+	var resp struct {
+		Due             int64             `json:"due"`
+		WindowSeconds   int64             `json:"window_seconds"`
+		Channels        []json.RawMessage `json:"channels"`
+		CircuitBreakers []json.RawMessage `json:"circuit_breakers"`
+		Workers         []json.RawMessage `json:"workers"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Due != 42 || resp.WindowSeconds != 900 || len(resp.Channels) != 1 ||
+		len(resp.CircuitBreakers) != 1 || len(resp.Workers) != 1 {
+		t.Errorf("unexpected overview %+v", resp)
+	}
+
+	for _, window := range []string{"soon", "0s", "48h"} {
+		rec := httptest.NewRecorder()
+		handler.GetOverview(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/overview?window="+window, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("window %q: expected 400, got %d", window, rec.Code)
+		}
+	}
+
+	failing := NewOverviewHandler(zap.NewNop(), repo, nil, func(ctx context.Context) ([]json.RawMessage, error) {
+		return nil, errors.New("redis down")
+	})
+	rec = httptest.NewRecorder()
+	failing.GetOverview(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/overview", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected heartbeat errors not to fail the overview, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	queryParamIncludeTotal = "include_total"
+	queryParamSort         = "sort"
+	queryParamOrder        = "order"
+
+	errDetailInvalidSort  = "sort must be one of created_at, updated_at, status"
+	errDetailInvalidOrder = "order must be asc or desc"
+)
+
+// includeTotal reports whether a list request asked for ?include_total=true.
+// The total costs a COUNT over the whole filter, so it is opt-in.
+func includeTotal(r *http.Request) bool {
+	include, _ := strconv.ParseBool(r.URL.Query().Get(queryParamIncludeTotal))
+	return include
+}
+
+// trimPage cuts a page fetched with limit+1 rows back to limit. The extra
+// row, when present, is how a list knows there is another page without
+// counting.
+func trimPage[T any](items []T, limit int) (page []T, hasMore bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// parseListSort reads ?sort= and ?order= for the notification and DLQ lists.
+// Both are optional; the default is created_at, newest first. On failure it
+// returns the offending parameter and the problem detail.
+func parseListSort(r *http.Request) (s db.ListSort, param, detail string) {
+	s.Field = r.URL.Query().Get(queryParamSort)
+	if s.Field != "" && !db.ValidSortField(s.Field) {
+		return s, queryParamSort, errDetailInvalidSort
+	}
+	switch r.URL.Query().Get(queryParamOrder) {
+	case "", "desc":
+	case "asc":
+		s.Asc = true
+	default:
+		return s, queryParamOrder, errDetailInvalidOrder
+	}
+	return s, "", ""
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	maxPreferencesBytes = 16 << 10
+	maxOptOuts          = 100
+)
+
+// PreferenceRepository reads and writes users' category opt-outs.
+type PreferenceRepository interface {
+	ListTenantNotificationCategories(ctx context.Context, tenantID uuid.UUID) ([]*db.NotificationCategory, error)
+	ListCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID) ([]*db.CategoryOptOut, error)
+	ReplaceCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID, optOuts []*db.CategoryOptOut) error
+}
+
+// PreferenceHandler manages a user's notification preferences: the
+// categories they opted out of, on one channel or all of them. The worker
+// ends an opted-out notification 'failed' without sending it. Mandatory
+// categories can't be opted out of.
+type PreferenceHandler struct {
+	repo   PreferenceRepository
+	logger *zap.Logger
+}
+
+// NewPreferenceHandler creates a handler for user preferences.
+func NewPreferenceHandler(logger *zap.Logger, repo PreferenceRepository) *PreferenceHandler {
+	return &PreferenceHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type preferencesBody struct {
+	OptOuts []*db.CategoryOptOut `json:"opt_outs"`
+}
+
+// GetPreferences handles GET /v1/tenants/{tenant_id}/recipients/{user_id}/preferences
+func (h *PreferenceHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := recipientPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	optOuts, err := h.repo.ListCategoryOptOuts(r.Context(), tenantID, userID)
+	if err != nil {
+		h.logger.Error("failed to list category opt-outs", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get preferences", "")
+		return
+	}
+	if optOuts == nil {
+		optOuts = []*db.CategoryOptOut{}
+	}
+
+	writeJSON(w, http.StatusOK, preferencesBody{OptOuts: optOuts})
+}
+
+// PutPreferences handles PUT /v1/tenants/{tenant_id}/recipients/{user_id}/preferences
+// {"opt_outs": [{"category": "marketing"}, {"category": "billing", "channel": "sms"}]}
+//
+// The opt-outs are replaced as a whole. An opt-out without a channel
+// covers every channel.
+func (h *PreferenceHandler) PutPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := recipientPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req preferencesBody
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPreferencesBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	categories, err := h.repo.ListTenantNotificationCategories(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to list notification categories", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save preferences", "")
+		return
+	}
+	optOuts, detail := validateOptOuts(req.OptOuts, categories)
+	if detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid preferences", detail)
+		return
+	}
+
+	if err := h.repo.ReplaceCategoryOptOuts(r.Context(), tenantID, userID, optOuts); err != nil {
+		h.logger.Error("failed to save category opt-outs", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save preferences", "")
+		return
+	}
+
+	h.logger.Info("user preferences updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("user_id", userID.String()),
+		zap.Int("opt_outs", len(optOuts)),
+	)
+	writeJSON(w, http.StatusOK, preferencesBody{OptOuts: optOuts})
+}
+
+// validateOptOuts checks every opt-out names one of the tenant's optional
+// categories and a known channel, and drops duplicates.
+func validateOptOuts(optOuts []*db.CategoryOptOut, categories []*db.NotificationCategory) ([]*db.CategoryOptOut, string) {
+	if len(optOuts) > maxOptOuts {
+		return nil, fmt.Sprintf("at most %d opt_outs are allowed", maxOptOuts)
+	}
+	defined := make(map[string]*db.NotificationCategory, len(categories))
+	for _, c := range categories {
+		defined[c.Name] = c
+	}
+
+	seen := make(map[db.CategoryOptOut]bool, len(optOuts))
+	out := make([]*db.CategoryOptOut, 0, len(optOuts))
+	for _, o := range optOuts {
+		if o == nil || defined[o.Category] == nil {
+			return nil, "every opt-out must name one of the tenant's categories"
+		}
+		if defined[o.Category].Mandatory {
+			return nil, fmt.Sprintf("category %q is mandatory and can't be opted out of", o.Category)
+		}
+		if o.Channel != "" && !isValidChannel(o.Channel) {
+			return nil, fmt.Sprintf("opt-out channel %q is not a supported channel", o.Channel)
+		}
+		if !seen[*o] {
+			seen[*o] = true
+			out = append(out, o)
+		}
+	}
+	return out, ""
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func preferencesHTTPRequest(method, tenantID, userID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/recipients/"+userID+"/preferences", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	rctx.URLParams.Add("user_id", userID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPutPreferences(t *testing.T) {
+	tenantID, userID := uuid.New(), uuid.New().String()
+	repo := newMockCategoryRepo()
+	repo.categories[tenantID.String()+"marketing"] = &db.NotificationCategory{TenantID: tenantID, Name: "marketing"}
+	repo.categories[tenantID.String()+"billing"] = &db.NotificationCategory{TenantID: tenantID, Name: "billing"}
+	repo.categories[tenantID.String()+"security"] = &db.NotificationCategory{TenantID: tenantID, Name: "security", Mandatory: true}
+	handler := NewPreferenceHandler(zap.NewNop(), repo)
+
+	tooMany := `{"opt_outs": [` + strings.Repeat(`{"category": "marketing"},`, maxOptOuts) + `{"category": "billing"}]}`
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"unknown category", `{"opt_outs": [{"category": "promo"}]}`, http.StatusBadRequest},
+		{"mandatory category", `{"opt_outs": [{"category": "security"}]}`, http.StatusBadRequest},
+		{"unknown channel", `{"opt_outs": [{"category": "marketing", "channel": "pigeon"}]}`, http.StatusBadRequest},
+		{"too many", tooMany, http.StatusBadRequest},
+		{"unknown field", `{"opt_outs": [], "priority": "high"}`, http.StatusBadRequest},
+		{"valid", `{"opt_outs": [{"category": "marketing"}, {"category": "billing", "channel": "sms"}, {"category": "marketing"}]}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.PutPreferences(rec, preferencesHTTPRequest(http.MethodPut, tenantID.String(), userID, tt.body))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if len(repo.optOuts) != 2 {
+		t.Errorf("expected the duplicate opt-out to be dropped, got %d opt-outs", len(repo.optOuts))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetPreferences(rec, preferencesHTTPRequest(http.MethodGet, tenantID.String(), userID, ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `{"category":"billing","channel":"sms"}`) {
+		t.Errorf("expected the saved opt-outs, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/redis"
+)
+
+const (
+	// maxRateLimitBurst bounds a tenant's burst so an override can't lift
+	// the limit off entirely.
+	maxRateLimitBurst = 10000
+	// maxRetryAfterSeconds matches the one-minute tenant window; longer
+	// hints would be cut to the window reset anyway.
+	maxRetryAfterSeconds = 60
+	rateLimitCacheTTL    = 30 * time.Second
+)
+
+// TenantRateLimitRepository reads and writes tenant rate limit overrides.
+type TenantRateLimitRepository interface {
+	GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*db.TenantRateLimit, error)
+	UpsertTenantRateLimit(ctx context.Context, l *db.TenantRateLimit) error
+	DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error
+	ListTenantRateLimits(ctx context.Context) ([]*db.TenantRateLimit, error)
+}
+
+// TenantRateLimitHandler serves the admin endpoints for per-tenant rate
+// limit overrides, e.g. a bigger burst and a shorter Retry-After for an
+// enterprise tenant, and enforces them through Middleware. Overrides are
+// cached for rateLimitCacheTTL; a change takes effect immediately on this
+// instance and within the TTL on others.
+type TenantRateLimitHandler struct {
+	repo   TenantRateLimitRepository
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedRateLimit
+}
+
+type cachedRateLimit struct {
+	policy   *rateLimitPolicy // nil when the tenant has no override
+	loadedAt time.Time
+}
+
+// NewTenantRateLimitHandler creates the admin handler for tenant rate
+// limit overrides.
+func NewTenantRateLimitHandler(logger *zap.Logger, repo TenantRateLimitRepository) *TenantRateLimitHandler {
+	return &TenantRateLimitHandler{
+		repo:   repo,
+		logger: logger,
+		cache:  make(map[uuid.UUID]cachedRateLimit),
+	}
+}
+
+type rateLimitRequest struct {
+	Burst             int  `json:"burst"`
+	RetryAfterSeconds *int `json:"retry_after_seconds"`
+}
+
+// Middleware is RateLimitMiddleware for the per-tenant limiter, applying
+// the tenant's override when it has one. keyFunc must return "tenant:<id>"
+// keys, as TenantKeyFunc and AuthTenantKeyFunc do; other keys get the
+// limiter's defaults. Requests marked by RateLimitExemptMiddleware skip it.
+func (h *TenantRateLimitHandler) Middleware(limiter *redis.RateLimiter, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter == nil || rateLimitExempt(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var policy *rateLimitPolicy
+			if tenantID, err := uuid.Parse(strings.TrimPrefix(key, "tenant:")); err == nil {
+				policy = h.policy(r.Context(), tenantID)
+			}
+			if !enforceRateLimit(w, r, limiter, h.logger, key, policy) {
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ListRateLimits handles GET /v1/admin/tenants/rate-limits
+func (h *TenantRateLimitHandler) ListRateLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.repo.ListTenantRateLimits(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list tenant rate limits", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list rate limits", "")
+		return
+	}
+	if limits == nil {
+		limits = []*db.TenantRateLimit{}
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"limits": limits,
+	})
+}
+
+// PutRateLimit handles PUT /v1/admin/tenants/{tenantID}/rate-limit
+// {"burst": 50, "retry_after_seconds": 5}. The body replaces the override.
+func (h *TenantRateLimitHandler) PutRateLimit(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req rateLimitRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+	if detail := validateRateLimit(&req); detail != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid rate limit", detail)
+		return
+	}
+
+	limit := &db.TenantRateLimit{TenantID: tenantID, Burst: req.Burst, RetryAfterSeconds: req.RetryAfterSeconds}
+	if err := h.repo.UpsertTenantRateLimit(r.Context(), limit); err != nil {
+		h.logger.Error("failed to save tenant rate limit", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save rate limit", "")
+		return
+	}
+	h.store(tenantID, policyFor(limit))
+
+	h.logger.Info("tenant rate limit updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.Int("burst", limit.Burst),
+	)
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(limit)
+}
+
+// DeleteRateLimit handles DELETE /v1/admin/tenants/{tenantID}/rate-limit,
+// putting the tenant back on the defaults.
+func (h *TenantRateLimitHandler) DeleteRateLimit(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.DeleteTenantRateLimit(r.Context(), tenantID)
+	if errors.Is(err, db.ErrNoTenantRateLimit) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No rate limit override", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to delete tenant rate limit", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to delete rate limit", "")
+		return
+	}
+	h.store(tenantID, nil)
+
+	h.logger.Info("tenant rate limit removed", zap.String(logFieldTenantID, tenantID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// policy returns the tenant's override, from cache when fresh. Lookup
+// errors fall back to the defaults, like limiter errors do.
+func (h *TenantRateLimitHandler) policy(ctx context.Context, tenantID uuid.UUID) *rateLimitPolicy {
+	h.mu.Lock()
+	cached, ok := h.cache[tenantID]
+	h.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < rateLimitCacheTTL {
+		return cached.policy
+	}
+
+	limit, err := h.repo.GetTenantRateLimit(ctx, tenantID)
+	if errors.Is(err, db.ErrNoTenantRateLimit) {
+		h.store(tenantID, nil)
+		return nil
+	}
+	if err != nil {
+		h.logger.Warn("failed to load tenant rate limit, using defaults",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		return nil
+	}
+
+	policy := policyFor(limit)
+	h.store(tenantID, policy)
+	return policy
+}
+
+func (h *TenantRateLimitHandler) store(tenantID uuid.UUID, policy *rateLimitPolicy) {
+	h.mu.Lock()
+	h.cache[tenantID] = cachedRateLimit{policy: policy, loadedAt: time.Now()}
+	h.mu.Unlock()
+}
+
+func policyFor(l *db.TenantRateLimit) *rateLimitPolicy {
+	p := &rateLimitPolicy{burst: l.Burst}
+	if l.RetryAfterSeconds != nil {
+		p.retryAfter = time.Duration(*l.RetryAfterSeconds) * time.Second
+	}
+	return p
+}
+
+func validateRateLimit(req *rateLimitRequest) string {
+	if req.Burst < 0 || req.Burst > maxRateLimitBurst {
+		return "burst must be between 0 and 10000"
+	}
+	if req.RetryAfterSeconds != nil && (*req.RetryAfterSeconds < 1 || *req.RetryAfterSeconds > maxRetryAfterSeconds) {
+		return "retry_after_seconds must be between 1 and 60"
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockRateLimitRepo struct {
+	limits map[uuid.UUID]*db.TenantRateLimit
+	gets   int
+}
+
+func (m *mockRateLimitRepo) GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*db.TenantRateLimit, error) {
+	m.gets++
+	if l, ok := m.limits[tenantID]; ok {
+		return l, nil
+	}
+	return nil, db.ErrNoTenantRateLimit
+}
+
+func (m *mockRateLimitRepo) UpsertTenantRateLimit(ctx context.Context, l *db.TenantRateLimit) error {
+	m.limits[l.TenantID] = l
+	return nil
+}
+
+func (m *mockRateLimitRepo) DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error {
+	if _, ok := m.limits[tenantID]; !ok {
+		return db.ErrNoTenantRateLimit
+	}
+	delete(m.limits, tenantID)
+	return nil
+}
+
+func (m *mockRateLimitRepo) ListTenantRateLimits(ctx context.Context) ([]*db.TenantRateLimit, error) {
+	var limits []*db.TenantRateLimit
+	for _, l := range m.limits {
+		limits = append(limits, l)
+	}
+	return limits, nil
+}
+
+func rateLimitOverrideRequest(method, tenantID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/admin/tenants/"+tenantID+"/rate-limit", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTenantRateLimits_Admin(t *testing.T) {
+	repo := &mockRateLimitRepo{limits: map[uuid.UUID]*db.TenantRateLimit{}}
+	handler := NewTenantRateLimitHandler(zap.NewNop(), repo)
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		method         string
+		tenantID       string
+		body           string
+		expectedStatus int
+	}{
+		{"set", http.MethodPut, tenantID, `{"burst":50,"retry_after_seconds":5}`, http.StatusOK},
+		{"burst only", http.MethodPut, tenantID, `{"burst":10}`, http.StatusOK},
+		{"negative burst", http.MethodPut, tenantID, `{"burst":-1}`, http.StatusBadRequest},
+		{"retry after too long", http.MethodPut, tenantID, `{"retry_after_seconds":120}`, http.StatusBadRequest},
+		{"unknown field", http.MethodPut, tenantID, `{"limit":1000}`, http.StatusBadRequest},
+		{"bad tenant", http.MethodPut, "not-a-uuid", `{"burst":1}`, http.StatusBadRequest},
+		{"remove", http.MethodDelete, tenantID, "", http.StatusNoContent},
+		{"remove again", http.MethodDelete, tenantID, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := rateLimitOverrideRequest(tt.method, tt.tenantID, tt.body)
+			if tt.method == http.MethodPut {
+				handler.PutRateLimit(rec, req)
+			} else {
+				handler.DeleteRateLimit(rec, req)
+			}
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ListRateLimits(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/tenants/rate-limits", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"limits":[]`) {
+		t.Errorf("expected an empty list after removal, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTenantRateLimits_Middleware(t *testing.T) {
+	enterprise, standard := uuid.New(), uuid.New()
+	retryAfter := 5
+	repo := &mockRateLimitRepo{limits: map[uuid.UUID]*db.TenantRateLimit{
+		enterprise: {TenantID: enterprise, Burst: 2, RetryAfterSeconds: &retryAfter},
+	}}
+	rl := NewTenantRateLimitHandler(zap.NewNop(), repo)
+	handler := rl.Middleware(newTestLimiter(t, 2, time.Minute), TenantKeyFunc)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	send := func(tenantID uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+		req.Header.Set("X-Tenant-ID", tenantID.String())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 4; i++ {
+		if rec := send(enterprise); rec.Code != http.StatusOK {
+			t.Fatalf("enterprise request %d: expected 200 within limit plus burst, got %d", i, rec.Code)
+		}
+	}
+	rec := send(enterprise)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "5" {
+		t.Errorf("expected 429 with the tenant's Retry-After 5, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if got := rec.Header().Get("RateLimit-Limit"); got != "4" {
+		t.Errorf("expected RateLimit-Limit to include the burst, got %q", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		send(standard)
+	}
+	rec = send(standard)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "5" {
+		t.Errorf("expected the default limit and cool-down for a tenant without override, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Overrides, and their absence, are cached.
+	if repo.gets != 2 {
+		t.Errorf("expected one lookup per tenant, got %d", repo.gets)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+)
+
+const contextKeyRateLimitExempt contextKey = "rate_limit_exempt"
+
+// RateLimitExemptions lists internal traffic that skips the per-tenant and
+// per-route rate limits: source addresses (batch processors, the compose
+// service, probes inside the cluster) and bearer tokens held by internal
+// services. Exempt traffic still counts against the global ceiling, which
+// protects the service rather than sharing it between tenants.
+//
+// Tokens are kept only as hashes. The zero value exempts nothing.
+type RateLimitExemptions struct {
+	prefixes []netip.Prefix
+	tokens   map[string]bool // hashAPIKey of each token
+}
+
+// NewRateLimitExemptions parses cidrs (CIDRs or bare addresses) and tokens
+// (static tokens or nmb_ API keys).
+func NewRateLimitExemptions(cidrs, tokens []string) (*RateLimitExemptions, error) {
+	prefixes, err := parseAllowlist(cidrs)
+	if err != nil {
+		return nil, fmt.Errorf("rate limit exemption: %w", err)
+	}
+	e := &RateLimitExemptions{prefixes: prefixes, tokens: make(map[string]bool, len(tokens))}
+	for _, t := range tokens {
+		if t != "" {
+			e.tokens[hashAPIKey(t)] = true
+		}
+	}
+	return e, nil
+}
+
+// Empty reports whether nothing is exempt.
+func (e *RateLimitExemptions) Empty() bool {
+	return e == nil || (len(e.prefixes) == 0 && len(e.tokens) == 0)
+}
+
+// Exempt reports whether r comes from an exempt address or carries an
+// exempt bearer token. The address is r.RemoteAddr, so behind a proxy it
+// relies on TrustedProxies.Middleware having run; a forwarding header the
+// client set itself never makes a request exempt.
+func (e *RateLimitExemptions) Exempt(r *http.Request) bool {
+	if e.Empty() {
+		return false
+	}
+	if ip, ok := clientIP(r); ok && allowlistContains(e.prefixes, ip) {
+		return true
+	}
+	if token, ok := bearerToken(r); ok && e.tokens[hashAPIKey(token)] {
+		return true
+	}
+	return false
+}
+
+// RateLimitExemptMiddleware marks exempt requests so the tenant and route
+// limiters after it let them through. It must run before them.
+func RateLimitExemptMiddleware(exemptions *RateLimitExemptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exemptions.Exempt(r) {
+				r = r.WithContext(context.WithValue(r.Context(), contextKeyRateLimitExempt, true))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitExempt reports whether RateLimitExemptMiddleware marked the
+// request.
+func rateLimitExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(contextKeyRateLimitExempt).(bool)
+	return exempt
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestRateLimitExemptions_Exempt(t *testing.T) {
+	exemptions, err := NewRateLimitExemptions([]string{"10.0.0.0/8", "192.168.1.5"}, []string{"batch-token"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		want       bool
+	}{
+		{"internal cidr", "10.1.2.3:5000", "", true},
+		{"single address", "192.168.1.5", "", true},
+		{"exempt token", "203.0.113.7:5000", "batch-token", true},
+		{"external", "203.0.113.7:5000", "tenant-token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if got := exemptions.Exempt(req); got != tt.want {
+				t.Errorf("expected exempt %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := NewRateLimitExemptions([]string{"not-a-cidr"}, nil); err == nil {
+		t.Error("expected an error for an invalid cidr")
+	}
+	if !(&RateLimitExemptions{}).Empty() || exemptions.Exempt(httptest.NewRequest(http.MethodGet, "/", nil)) {
+		t.Error("expected requests outside the exemptions to be limited")
+	}
+}
+
+func TestRateLimitExemptions_ForgedForwardedFor(t *testing.T) {
+	exemptions, _ := NewRateLimitExemptions([]string{"10.0.0.0/8"}, nil)
+	proxies, _ := NewTrustedProxies([]string{"10.0.0.1"})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       bool
+	}{
+		{"external client claiming an internal address", "203.0.113.7:5000", "10.1.2.3", false},
+		{"forged hop behind the load balancer", "10.0.0.1:5000", "10.1.2.3, 203.0.113.7", false},
+		{"internal client behind the load balancer", "10.0.0.1:5000", "10.1.2.3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exempt bool
+			handler := proxies.Middleware(RateLimitExemptMiddleware(exemptions)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					exempt = rateLimitExempt(r.Context())
+				}),
+			))
+			req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.xff)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if exempt != tt.want {
+				t.Errorf("expected exempt %v, got %v", tt.want, exempt)
+			}
+		})
+	}
+}
+
+func TestRateLimitExemptMiddleware_SkipsTenantLimit(t *testing.T) {
+	exemptions, _ := NewRateLimitExemptions([]string{"10.0.0.0/8"}, nil)
+	rl := NewTenantRateLimitHandler(zap.NewNop(), &mockRateLimitRepo{limits: map[uuid.UUID]*db.TenantRateLimit{}})
+	handler := RateLimitExemptMiddleware(exemptions)(rl.Middleware(newTestLimiter(t, 1, time.Minute), TenantKeyFunc)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+	tenantID := uuid.New().String()
+
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Tenant-ID", tenantID)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := send("10.0.0.9:4000"); code != http.StatusOK {
+			t.Fatalf("internal request %d: expected 200, got %d", i, code)
+		}
+	}
+	send("203.0.113.7:4000")
+	if code := send("203.0.113.7:4000"); code != http.StatusTooManyRequests {
+		t.Errorf("expected external traffic for the same tenant limited, got %d", code)
+	}
+}
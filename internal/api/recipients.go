@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/phone"
+)
+
+const (
+	maxRecipientBytes   = 16 << 10
+	maxEmailLength      = 320
+	maxDeviceTokens     = 10
+	maxDeviceTokenBytes = 512
+)
+
+// localePattern is a BCP 47 language tag such as "en", "pt-BR" or
+// "zh-Hant-TW", checked for shape only.
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// RecipientRepository reads and writes tenants' recipient records.
+type RecipientRepository interface {
+	GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error)
+	UpsertRecipient(ctx context.Context, r *db.Recipient) error
+	DeleteRecipient(ctx context.Context, tenantID, userID uuid.UUID) error
+}
+
+// RecipientHandler manages reusable recipients: a tenant's contact details
+// for each of its users. Notifications that set "recipient_ref": "user" in
+// their payload are addressed from the record when they're sent.
+type RecipientHandler struct {
+	repo   RecipientRepository
+	logger *zap.Logger
+}
+
+// NewRecipientHandler creates a handler for recipient records.
+func NewRecipientHandler(logger *zap.Logger, repo RecipientRepository) *RecipientHandler {
+	return &RecipientHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type recipientRequest struct {
+	DeviceTokens []string `json:"device_tokens"`
+	Email        string   `json:"email"`
+	Phone        string   `json:"phone"`
+	Locale       string   `json:"locale"`
+	Timezone     string   `json:"timezone"`
+}
+
+// GetRecipient handles GET /v1/tenants/{tenant_id}/recipients/{user_id}
+func (h *RecipientHandler) GetRecipient(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := recipientPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	rc, err := h.repo.GetRecipient(r.Context(), tenantID, userID)
+	if errors.Is(err, db.ErrNoRecipient) {
+		writeProblem(w, http.StatusNotFound, "not_found", "Recipient not found", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get recipient", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get recipient", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rc)
+}
+
+// PutRecipient handles PUT /v1/tenants/{tenant_id}/recipients/{user_id}
+// {"email": "a@example.com", "phone": "+15551234567", "device_tokens": [], "locale": "en-US", "timezone": "America/New_York"}
+//
+// The record is replaced as a whole; fields left out are cleared.
+func (h *RecipientHandler) PutRecipient(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := recipientPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req recipientRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRecipientBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	rc, err := validateRecipientRequest(&req)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid recipient", err.Error())
+		return
+	}
+	rc.TenantID, rc.UserID = tenantID, userID
+
+	if err := h.repo.UpsertRecipient(r.Context(), rc); err != nil {
+		h.logger.Error("failed to save recipient", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save recipient", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rc)
+}
+
+// DeleteRecipient handles DELETE /v1/tenants/{tenant_id}/recipients/{user_id}
+func (h *RecipientHandler) DeleteRecipient(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := recipientPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.DeleteRecipient(r.Context(), tenantID, userID)
+	if errors.Is(err, db.ErrNoRecipient) {
+		writeProblem(w, http.StatusNotFound, "not_found", "Recipient not found", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to delete recipient", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to delete recipient", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateRecipientRequest checks req and returns the record to store, with
+// the phone number in E.164 and duplicate device tokens dropped.
+func validateRecipientRequest(req *recipientRequest) (*db.Recipient, error) {
+	rc := &db.Recipient{
+		Email:        req.Email,
+		Locale:       req.Locale,
+		Timezone:     req.Timezone,
+		DeviceTokens: []string{},
+	}
+
+	if req.Email != "" {
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil || addr.Address != req.Email || len(req.Email) > maxEmailLength {
+			return nil, errors.New("email must be a bare address such as a@example.com")
+		}
+	}
+	if req.Phone != "" {
+		normalized, err := phone.Normalize(req.Phone, "")
+		if err != nil {
+			return nil, fmt.Errorf("phone: %w", err)
+		}
+		rc.Phone = normalized
+	}
+
+	if len(req.DeviceTokens) > maxDeviceTokens {
+		return nil, fmt.Errorf("at most %d device_tokens are allowed", maxDeviceTokens)
+	}
+	seen := make(map[string]bool, len(req.DeviceTokens))
+	for _, token := range req.DeviceTokens {
+		if token == "" || len(token) > maxDeviceTokenBytes {
+			return nil, fmt.Errorf("device_tokens must be 1-%d characters", maxDeviceTokenBytes)
+		}
+		if !seen[token] {
+			seen[token] = true
+			rc.DeviceTokens = append(rc.DeviceTokens, token)
+		}
+	}
+
+	if req.Locale != "" && (len(req.Locale) > 35 || !localePattern.MatchString(req.Locale)) {
+		return nil, errors.New("locale must be a language tag such as en or pt-BR")
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "Local" {
+			return nil, errors.New("timezone must be an IANA time zone such as Europe/Berlin")
+		}
+	}
+
+	return rc, nil
+}
+
+// recipientPathParams parses the {tenant_id} and {user_id} segments.
+func recipientPathParams(w http.ResponseWriter, r *http.Request) (tenantID, userID uuid.UUID, ok bool) {
+	tenantID, ok = tenantIDPathParam(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidUser, errDetailInvalidUser)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tenantID, userID, true
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockRecipientRepo struct {
+	recipients map[string]*db.Recipient
+}
+
+func (m *mockRecipientRepo) GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error) {
+	rc, ok := m.recipients[tenantID.String()+userID.String()]
+	if !ok {
+		return nil, db.ErrNoRecipient
+	}
+	return rc, nil
+}
+
+func (m *mockRecipientRepo) UpsertRecipient(ctx context.Context, rc *db.Recipient) error {
+	m.recipients[rc.TenantID.String()+rc.UserID.String()] = rc
+	return nil
+}
+
+func (m *mockRecipientRepo) DeleteRecipient(ctx context.Context, tenantID, userID uuid.UUID) error {
+	if _, ok := m.recipients[tenantID.String()+userID.String()]; !ok {
+		return db.ErrNoRecipient
+	}
+	delete(m.recipients, tenantID.String()+userID.String())
+	return nil
+}
+
+func recipientHTTPRequest(method, tenantID, userID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/recipients/"+userID, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	rctx.URLParams.Add("user_id", userID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPutRecipient(t *testing.T) {
+	handler := NewRecipientHandler(zap.NewNop(), &mockRecipientRepo{recipients: map[string]*db.Recipient{}})
+	tenantID, userID := uuid.New().String(), uuid.New().String()
+
+	tests := []struct {
+		name           string
+		userID         string
+		body           string
+		expectedStatus int
+	}{
+		{"bad user id", "nope", `{"email":"a@example.com"}`, http.StatusBadRequest},
+		{"display name", userID, `{"email":"Ann <a@example.com>"}`, http.StatusBadRequest},
+		{"bad phone", userID, `{"phone":"12"}`, http.StatusBadRequest},
+		{"bad locale", userID, `{"locale":"english please"}`, http.StatusBadRequest},
+		{"bad timezone", userID, `{"timezone":"Mars/Olympus_Mons"}`, http.StatusBadRequest},
+		{"too many tokens", userID, `{"device_tokens":["1","2","3","4","5","6","7","8","9","10","11"]}`, http.StatusBadRequest},
+		{"unknown field", userID, `{"email":"a@example.com","fax":"555"}`, http.StatusBadRequest},
+		{"valid", userID, `{"email":"a@example.com","phone":"+1 415 555 0100","device_tokens":["tok","tok"],"locale":"pt-BR","timezone":"Europe/Berlin"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.PutRecipient(rec, recipientHTTPRequest(http.MethodPut, tenantID, tt.userID, tt.body))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetRecipient(rec, recipientHTTPRequest(http.MethodGet, tenantID, userID, ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got db.Recipient
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Phone != "+14155550100" {
+		t.Errorf("expected the phone in E.164, got %q", got.Phone)
+	}
+	if len(got.DeviceTokens) != 1 {
+		t.Errorf("expected duplicate device tokens dropped, got %v", got.DeviceTokens)
+	}
+}
+
+func TestDeleteRecipient(t *testing.T) {
+	tenantID, userID := uuid.New(), uuid.New()
+	repo := &mockRecipientRepo{recipients: map[string]*db.Recipient{
+		tenantID.String() + userID.String(): {TenantID: tenantID, UserID: userID, Email: "a@example.com"},
+	}}
+	handler := NewRecipientHandler(zap.NewNop(), repo)
+
+	for _, want := range []int{http.StatusNoContent, http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		handler.DeleteRecipient(rec, recipientHTTPRequest(http.MethodDelete, tenantID.String(), userID.String(), ""))
+		if rec.Code != want {
+			t.Errorf("expected status %d, got %d", want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	defaultRequeueLimit = 1000
+	maxRequeueLimit     = 10000
+	// minProcessingAge keeps a requeue from pulling rows out from under a
+	// worker that is still sending them.
+	minProcessingAge = time.Minute
+)
+
+// RequeueRepository moves notifications back to pending in bulk.
+type RequeueRepository interface {
+	RequeueNotifications(ctx context.Context, filter db.RequeueFilter) (int64, error)
+}
+
+// RequeueHandler serves the admin endpoint that puts stuck or failed work
+// back on the queue, e.g. rows left in 'processing' by a crashed replica or
+// webhooks that failed during a customer's outage.
+type RequeueHandler struct {
+	repo   RequeueRepository
+	logger *zap.Logger
+}
+
+// NewRequeueHandler creates the admin requeue handler.
+func NewRequeueHandler(logger *zap.Logger, repo RequeueRepository) *RequeueHandler {
+	return &RequeueHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type requeueRequest struct {
+	Status        string `json:"status"`
+	OlderThan     string `json:"older_than"`
+	Channel       string `json:"channel"`
+	TenantID      string `json:"tenant_id"`
+	ErrorContains string `json:"error_contains"`
+	Limit         int    `json:"limit"`
+}
+
+// Requeue handles POST /v1/admin/notifications/requeue
+// {"status": "processing", "older_than": "10m", "channel": "webhook",
+// "tenant_id": "...", "error_contains": "...", "limit": 1000}
+func (h *RequeueHandler) Requeue(w http.ResponseWriter, r *http.Request) {
+	var req requeueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	filter, title, detail := req.filter()
+	if title != "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, title, detail)
+		return
+	}
+
+	n, err := h.repo.RequeueNotifications(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to requeue notifications", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to requeue notifications", "")
+		return
+	}
+
+	h.logger.Warn("notifications requeued",
+		zap.Int64("requeued", n),
+		zap.String("status", filter.Status),
+		zap.Duration("older_than", filter.OlderThan),
+		zap.String(logFieldChannel, filter.Channel),
+		zap.String("error_contains", filter.ErrorContains),
+	)
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"requeued": n,
+		"limit":    filter.Limit,
+	})
+}
+
+// filter validates req. On failure it returns the problem title and detail.
+func (req requeueRequest) filter() (f db.RequeueFilter, title, detail string) {
+	f = db.RequeueFilter{
+		Status:        req.Status,
+		Channel:       req.Channel,
+		ErrorContains: req.ErrorContains,
+		Limit:         req.Limit,
+	}
+
+	if f.Status != "processing" && f.Status != "failed" {
+		return f, "Invalid status", "status must be processing or failed"
+	}
+	if req.OlderThan != "" {
+		d, err := time.ParseDuration(req.OlderThan)
+		if err != nil || d < 0 {
+			return f, "Invalid older_than", "older_than must be a non-negative duration such as 10m"
+		}
+		f.OlderThan = d
+	}
+	if f.Status == "processing" && f.OlderThan < minProcessingAge {
+		return f, "Invalid older_than", "older_than must be at least 1m for processing notifications"
+	}
+	if f.Channel != "" && !isValidChannel(f.Channel) {
+		return f, errTitleInvalidChannel, errDetailInvalidChannel
+	}
+	if req.TenantID != "" {
+		id, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			return f, errTitleInvalidTenant, errDetailInvalidTenant
+		}
+		f.TenantID = &id
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultRequeueLimit
+	}
+	if f.Limit < 0 || f.Limit > maxRequeueLimit {
+		return f, "Invalid limit", "limit must be between 1 and 10000"
+	}
+	return f, "", ""
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockRequeueRepo struct {
+	got *db.RequeueFilter
+	n   int64
+	err error
+}
+
+func (m *mockRequeueRepo) RequeueNotifications(ctx context.Context, f db.RequeueFilter) (int64, error) {
+	m.got = &f
+	return m.n, m.err
+}
+
+func requeueRequestWithBody(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/v1/admin/notifications/requeue", strings.NewReader(body))
+}
+
+func TestRequeue(t *testing.T) {
+	repo := &mockRequeueRepo{n: 42}
+	handler := NewRequeueHandler(zap.NewNop(), repo)
+
+	rec := httptest.NewRecorder()
+	handler.Requeue(rec, requeueRequestWithBody(
+		`{"status":"failed","channel":"webhook","tenant_id":"00000000-0000-0000-0000-000000000001","error_contains":"connection refused"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"requeued":42`) {
+		t.Errorf("expected requeued count in body, got %s", rec.Body.String())
+	}
+	f := repo.got
+	if f.Status != "failed" || f.Channel != "webhook" || f.ErrorContains != "connection refused" ||
+		f.TenantID == nil || f.TenantID.String() != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("unexpected filter: %+v", f)
+	}
+	if f.Limit != defaultRequeueLimit {
+		t.Errorf("expected default limit %d, got %d", defaultRequeueLimit, f.Limit)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.Requeue(rec, requeueRequestWithBody(`{"status":"processing","older_than":"10m","limit":50}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if repo.got.OlderThan != 10*time.Minute || repo.got.Limit != 50 || repo.got.TenantID != nil {
+		t.Errorf("unexpected filter: %+v", repo.got)
+	}
+}
+
+func TestRequeue_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed", body: `{`},
+		{name: "missing status", body: `{}`},
+		{name: "unsupported status", body: `{"status":"sent"}`},
+		{name: "processing without age", body: `{"status":"processing"}`},
+		{name: "processing too young", body: `{"status":"processing","older_than":"30s"}`},
+		{name: "bad duration", body: `{"status":"failed","older_than":"ten minutes"}`},
+		{name: "bad channel", body: `{"status":"failed","channel":"pigeon"}`},
+		{name: "bad tenant", body: `{"status":"failed","tenant_id":"nope"}`},
+		{name: "limit too high", body: `{"status":"failed","limit":10001}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRequeueRepo{}
+			rec := httptest.NewRecorder()
+			NewRequeueHandler(zap.NewNop(), repo).Requeue(rec, requeueRequestWithBody(tt.body))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if repo.got != nil {
+				t.Error("expected no requeue on invalid request")
+			}
+		})
+	}
+}
+
+func TestRequeue_RepoError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewRequeueHandler(zap.NewNop(), &mockRequeueRepo{err: errors.New("db down")}).
+		Requeue(rec, requeueRequestWithBody(`{"status":"failed"}`))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
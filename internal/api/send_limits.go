@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// SendLimitRepository reads and lifts tenant send limits.
+type SendLimitRepository interface {
+	ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error)
+	LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error
+}
+
+// SendLimitHandler serves the admin endpoints for the throttles and pauses
+// the reputation guard applies to tenants with high bounce or complaint
+// rates. The guard lifts throttles itself once a tenant recovers; pauses
+// stay until lifted here.
+type SendLimitHandler struct {
+	repo   SendLimitRepository
+	logger *zap.Logger
+}
+
+// NewSendLimitHandler creates the admin handler for tenant send limits.
+func NewSendLimitHandler(logger *zap.Logger, repo SendLimitRepository) *SendLimitHandler {
+	return &SendLimitHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListLimits handles GET /v1/admin/tenants/send-limits
+func (h *SendLimitHandler) ListLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.repo.ListTenantSendLimits(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list send limits", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list send limits", "")
+		return
+	}
+	if limits == nil {
+		limits = []*db.TenantSendLimit{}
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"limits": limits,
+	})
+}
+
+// LiftLimit handles DELETE /v1/admin/tenants/{tenantID}/send-limits/{channel}.
+// The guard judges the tenant afresh from this point, so bounces from
+// before the lift don't reapply the limit on its next run.
+func (h *SendLimitHandler) LiftLimit(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+	channel, ok := channelParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.LiftTenantSendLimit(r.Context(), tenantID, channel)
+	if errors.Is(err, db.ErrNoSendLimit) {
+		writeProblem(w, http.StatusNotFound, "not_found", "No send limit", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to lift send limit", zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+			zap.String(logFieldChannel, channel),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to lift send limit", "")
+		return
+	}
+
+	h.logger.Info("tenant send limit lifted",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String(logFieldChannel, channel),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
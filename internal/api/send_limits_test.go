@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockSendLimitRepo struct {
+	limits []*db.TenantSendLimit
+}
+
+func (m *mockSendLimitRepo) ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error) {
+	return m.limits, nil
+}
+
+func (m *mockSendLimitRepo) LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error {
+	for i, l := range m.limits {
+		if l.TenantID == tenantID && l.Channel == channel {
+			m.limits = append(m.limits[:i], m.limits[i+1:]...)
+			return nil
+		}
+	}
+	return db.ErrNoSendLimit
+}
+
+func sendLimitRequest(tenantID, channel string) *http.Request {
+	req := httptest.NewRequest(http.MethodDelete, "/v1/admin/tenants/"+tenantID+"/send-limits/"+channel, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", tenantID)
+	rctx.URLParams.Add("channel", channel)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestSendLimits(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	repo := &mockSendLimitRepo{limits: []*db.TenantSendLimit{
+		{TenantID: tenantID, Channel: db.ChannelEmail, State: db.SendLimitPaused, Reason: "hard bounce rate 12.00%"},
+	}}
+	handler := NewSendLimitHandler(zap.NewNop(), repo)
+
+	rec := httptest.NewRecorder()
+	handler.ListLimits(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/tenants/send-limits", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"state":"paused"`) {
+		t.Errorf("expected paused limit listed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		name           string
+		tenantID       string
+		channel        string
+		expectedStatus int
+	}{
+		{"invalid tenant", "nope", "email", http.StatusBadRequest},
+		{"invalid channel", tenantID.String(), "pigeon", http.StatusBadRequest},
+		{"lift", tenantID.String(), "email", http.StatusNoContent},
+		{"already lifted", tenantID.String(), "email", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.LiftLimit(rec, sendLimitRequest(tt.tenantID, tt.channel))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/shortlink"
+)
+
+// ShortLinkRepository resolves short links and records clicks.
+type ShortLinkRepository interface {
+	GetShortLink(ctx context.Context, code string) (*db.ShortLink, error)
+	RecordLinkClick(ctx context.Context, click *db.LinkClick) error
+}
+
+// ShortLinkHandler serves /r/{code} redirects for links shortened in SMS
+// bodies. It is unauthenticated: recipients click these from their phones.
+type ShortLinkHandler struct {
+	repo          ShortLinkRepository
+	domainTenants map[string]string // custom domain → tenant_id
+	logger        *zap.Logger
+}
+
+// NewShortLinkHandler creates the redirect handler. tenantDomains is the
+// same tenant_id → domain map the shortener uses.
+func NewShortLinkHandler(logger *zap.Logger, repo ShortLinkRepository, tenantDomains map[string]string) *ShortLinkHandler {
+	domainTenants := make(map[string]string, len(tenantDomains))
+	for tenant, domain := range tenantDomains {
+		domainTenants[strings.ToLower(domain)] = tenant
+	}
+	return &ShortLinkHandler{
+		repo:          repo,
+		domainTenants: domainTenants,
+		logger:        logger,
+	}
+}
+
+// Redirect handles GET /r/{code}
+func (h *ShortLinkHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !shortlink.ValidCode(code) {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+
+	link, err := h.repo.GetShortLink(ctx, code)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// On a tenant's custom domain, only that tenant's links resolve, so one
+	// tenant's domain can't be used to front another tenant's redirects.
+	if tenant, ok := h.domainTenants[requestHost(r)]; ok && tenant != link.TenantID.String() {
+		http.NotFound(w, r)
+		return
+	}
+
+	click := &db.LinkClick{
+		Code:           link.Code,
+		TenantID:       link.TenantID,
+		NotificationID: link.NotificationID,
+		UserAgent:      r.UserAgent(),
+		IPAddress:      r.RemoteAddr,
+	}
+	// A lost click event must not cost the recipient their redirect.
+	if err := h.repo.RecordLinkClick(ctx, click); err != nil {
+		h.logger.Error("failed to record link click",
+			zap.Error(err),
+			zap.String("code", code),
+		)
+	}
+
+	// no-store so every click comes back through here and is counted.
+	w.Header().Set(headerCacheControl, "no-store")
+	http.Redirect(w, r, link.URL, http.StatusFound)
+}
+
+// requestHost returns the lower-cased Host header without any port.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockShortLinkRepo struct {
+	links     map[string]*db.ShortLink
+	clicks    []*db.LinkClick
+	clickFail bool
+}
+
+func (m *mockShortLinkRepo) GetShortLink(ctx context.Context, code string) (*db.ShortLink, error) {
+	link, ok := m.links[code]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return link, nil
+}
+
+func (m *mockShortLinkRepo) RecordLinkClick(ctx context.Context, click *db.LinkClick) error {
+	if m.clickFail {
+		return errors.New("database error")
+	}
+	m.clicks = append(m.clicks, click)
+	return nil
+}
+
+func TestShortLinkRedirect(t *testing.T) {
+	tenantA := uuid.New()
+	tenantB := uuid.New()
+	link := &db.ShortLink{
+		Code:           "Ab3dEf7h",
+		TenantID:       tenantA,
+		NotificationID: uuid.New(),
+		URL:            "https://example.com/orders/123",
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		host           string
+		clickFail      bool
+		expectedStatus int
+		expectedClicks int
+	}{
+		{"redirects and records click", "/r/Ab3dEf7h", "nim.bz", false, http.StatusFound, 1},
+		{"own custom domain", "/r/Ab3dEf7h", "go.a.com:443", false, http.StatusFound, 1},
+		{"other tenant's custom domain", "/r/Ab3dEf7h", "go.b.com", false, http.StatusNotFound, 0},
+		{"unknown code", "/r/Zz9zZz9z", "nim.bz", false, http.StatusNotFound, 0},
+		{"malformed code", "/r/nope", "nim.bz", false, http.StatusNotFound, 0},
+		{"click store down still redirects", "/r/Ab3dEf7h", "nim.bz", true, http.StatusFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockShortLinkRepo{
+				links:     map[string]*db.ShortLink{link.Code: link},
+				clickFail: tt.clickFail,
+			}
+			h := NewShortLinkHandler(zap.NewNop(), repo, map[string]string{
+				tenantA.String(): "go.a.com",
+				tenantB.String(): "Go.B.com",
+			})
+			r := chi.NewRouter()
+			r.Get("/r/{code}", h.Redirect)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus == http.StatusFound && rec.Header().Get("Location") != link.URL {
+				t.Errorf("expected Location %s, got %s", link.URL, rec.Header().Get("Location"))
+			}
+			if len(repo.clicks) != tt.expectedClicks {
+				t.Errorf("expected %d clicks, got %d", tt.expectedClicks, len(repo.clicks))
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/phone"
+	"github.com/lalithlochan/nimbus/internal/sms"
+)
+
+// SMSPolicy bounds SMS size at create time and prices the estimate returned
+// to the client. The zero value disables both.
+type SMSPolicy struct {
+	MaxSegments    int     // Reject messages longer than this; 0 disables the cap
+	CostPerSegment float64 // Price of one segment; 0 omits estimated_cost
+}
+
+// SMSEstimate is returned on create for SMS notifications so callers see
+// what a message will cost before it is sent.
+type SMSEstimate struct {
+	sms.Estimate
+	EstimatedCost float64 `json:"estimated_cost,omitempty"`
+}
+
+// SetSMSPolicy configures the SMS segment cap and per-segment cost.
+func (h *Handler) SetSMSPolicy(policy SMSPolicy) {
+	h.smsPolicy = policy
+}
+
+// SetChannelSettings lets create look up tenant channel settings, such as
+// the default country used to read national phone numbers.
+func (h *Handler) SetChannelSettings(settings ChannelSettingsRepository) {
+	h.settings = settings
+}
+
+// normalizeSMSRecipient rewrites an SMS payload's phone_number to E.164, or
+// returns an error if it can't be a real number. Numbers without a country
+// code are read in the tenant's default_country. Payloads without a
+// phone_number are returned unchanged.
+func (h *Handler) normalizeSMSRecipient(ctx context.Context, tenantID uuid.UUID, channel string, payload json.RawMessage) (json.RawMessage, error) {
+	if channel != channelSMS || len(payload) == 0 {
+		return payload, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return payload, nil
+	}
+	raw, ok := fields["phone_number"]
+	if !ok {
+		return payload, nil
+	}
+	var number string
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return nil, errors.New("phone_number must be a string")
+	}
+
+	normalized, err := phone.Normalize(number, "")
+	if errors.Is(err, phone.ErrMissingCountry) && h.settings != nil {
+		normalized, err = phone.Normalize(number, h.defaultCountry(ctx, tenantID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("phone_number: %w", err)
+	}
+	if normalized == number {
+		return payload, nil
+	}
+
+	fields["phone_number"], _ = json.Marshal(normalized)
+	return json.Marshal(fields)
+}
+
+// defaultCountry returns the tenant's SMS default_country, or "" if it has
+// none or the lookup fails.
+func (h *Handler) defaultCountry(ctx context.Context, tenantID uuid.UUID) string {
+	record, err := h.settings.GetChannelSettings(ctx, tenantID, channelSMS)
+	if err != nil {
+		h.logger.Warn("failed to load sms settings for phone normalization",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		return ""
+	}
+	var settings db.SMSSettings
+	_ = json.Unmarshal(record.Settings, &settings)
+	return settings.DefaultCountry
+}
+
+// estimateSMS counts segments for an SMS payload's message. It returns nil
+// for other channels or payloads without a message, and an error when the
+// message exceeds the configured segment cap.
+//
+// Links are shortened at send time, so for messages containing URLs this is
+// an upper bound.
+func (h *Handler) estimateSMS(channel string, payload json.RawMessage) (*SMSEstimate, error) {
+	if channel != channelSMS || len(payload) == 0 {
+		return nil, nil
+	}
+
+	var p struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.Message == "" {
+		return nil, nil
+	}
+
+	estimate := sms.Count(p.Message)
+	if h.smsPolicy.MaxSegments > 0 && estimate.Segments > h.smsPolicy.MaxSegments {
+		return nil, fmt.Errorf("message is %d %s segments, the limit is %d",
+			estimate.Segments, estimate.Encoding, h.smsPolicy.MaxSegments)
+	}
+
+	return &SMSEstimate{
+		Estimate:      estimate,
+		EstimatedCost: float64(estimate.Segments) * h.smsPolicy.CostPerSegment,
+	}, nil
+}
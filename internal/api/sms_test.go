@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/sms"
+)
+
+func TestCreateNotification_SMSEstimate(t *testing.T) {
+	tests := []struct {
+		name           string
+		message        string
+		expectedStatus int
+		expectedEnc    sms.Encoding
+		expectedSegs   int
+	}{
+		{"single gsm7 segment", "Your code is 123456", http.StatusCreated, sms.EncodingGSM7, 1},
+		{"two ucs2 segments", strings.Repeat("ж", 100), http.StatusCreated, sms.EncodingUCS2, 2},
+		{"over the cap", strings.Repeat("a", 153*3+1), http.StatusBadRequest, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			handler.SetSMSPolicy(SMSPolicy{MaxSegments: 3, CostPerSegment: 0.0075})
+
+			payload, _ := json.Marshal(map[string]string{"phone_number": "+14155552671", "message": tt.message})
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: "00000000-0000-0000-0000-000000000001",
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "sms",
+				Payload:  payload,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				if mockRepo.createCalled {
+					t.Error("expected no notification to be created")
+				}
+				return
+			}
+
+			var resp NotificationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.SMS == nil {
+				t.Fatal("expected an sms estimate in the response")
+			}
+			if resp.SMS.Encoding != tt.expectedEnc || resp.SMS.Segments != tt.expectedSegs {
+				t.Errorf("expected %s x%d, got %+v", tt.expectedEnc, tt.expectedSegs, resp.SMS.Estimate)
+			}
+			if want := float64(tt.expectedSegs) * 0.0075; resp.SMS.EstimatedCost != want {
+				t.Errorf("expected cost %v, got %v", want, resp.SMS.EstimatedCost)
+			}
+		})
+	}
+}
+
+func TestCreateNotification_NoSMSEstimateForEmail(t *testing.T) {
+	handler := NewHandler(zap.NewNop(), NewMockRepository())
+	body, _ := json.Marshal(NotificationRequest{
+		TenantID: "00000000-0000-0000-0000-000000000001",
+		UserID:   "00000000-0000-0000-0000-000000000002",
+		Channel:  "email",
+		Payload:  json.RawMessage(`{"to":"user@example.com","message":"not an sms"}`),
+	})
+	rec := httptest.NewRecorder()
+	handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+	if strings.Contains(rec.Body.String(), `"sms"`) {
+		t.Errorf("expected no sms estimate, got %s", rec.Body.String())
+	}
+}
+
+func TestCreateNotification_PhoneNormalization(t *testing.T) {
+	tenantWithDefault := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	settings := &mockSettingsRepo{saved: map[string]*db.TenantChannelSettings{
+		tenantWithDefault.String() + "/sms": {
+			TenantID: tenantWithDefault,
+			Channel:  "sms",
+			Settings: json.RawMessage(`{"default_country":"GB"}`),
+		},
+	}}
+
+	tests := []struct {
+		name           string
+		tenantID       string
+		number         string
+		expectedStatus int
+		expectedNumber string
+	}{
+		{"e164 with separators", "00000000-0000-0000-0000-000000000009", "+1 (415) 555-2671", http.StatusCreated, "+14155552671"},
+		{"national number uses tenant default", tenantWithDefault.String(), "020 7946 0958", http.StatusCreated, "+442079460958"},
+		{"national number without default", "00000000-0000-0000-0000-000000000009", "020 7946 0958", http.StatusBadRequest, ""},
+		{"garbage", tenantWithDefault.String(), "call me", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			handler.SetChannelSettings(settings)
+
+			payload, _ := json.Marshal(map[string]string{"phone_number": tt.number, "message": "Hi"})
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: tt.tenantID,
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "sms",
+				Payload:  payload,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var resp NotificationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var stored struct {
+				PhoneNumber string `json:"phone_number"`
+			}
+			if err := json.Unmarshal(mockRepo.notifications[resp.ID].Payload, &stored); err != nil {
+				t.Fatalf("failed to decode stored payload: %v", err)
+			}
+			if stored.PhoneNumber != tt.expectedNumber {
+				t.Errorf("expected stored number %s, got %s", tt.expectedNumber, stored.PhoneNumber)
+			}
+		})
+	}
+}
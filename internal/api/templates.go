@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/mjml"
+	"github.com/lalithlochan/nimbus/internal/worker"
+)
+
+const (
+	maxTemplateNameLength  = 128
+	maxTemplateSourceBytes = 256 << 10
+)
+
+const (
+	errTypeNotFound            = "not_found"
+	errTypeConflict            = "conflict"
+	errTypeTemplateInvalid     = "template_invalid"
+	errTypeCompilerUnavailable = "compiler_unavailable"
+	errTypeCompilerError       = "compiler_error"
+	errTypeEnricherUnavailable = "enricher_unavailable"
+	errTypeEnricherError       = "enricher_error"
+)
+
+// TemplateRepository defines email template database operations.
+type TemplateRepository interface {
+	CreateTemplate(ctx context.Context, tmpl *db.Template) error
+	GetTemplate(ctx context.Context, id uuid.UUID) (*db.Template, error)
+	PublishTemplate(ctx context.Context, id uuid.UUID, html string) (*db.Template, error)
+}
+
+// TemplateCompiler turns MJML source into HTML. *mjml.Client implements it.
+type TemplateCompiler interface {
+	Compile(ctx context.Context, source string) (string, error)
+}
+
+// TemplateEnricher writes an email body with AI, as the worker's enrichment
+// does for payloads naming a "template". *ai.Client implements it.
+type TemplateEnricher interface {
+	GenerateEmailBody(ctx context.Context, template, subject string, vars map[string]string) (string, error)
+}
+
+// TemplateRequest is the body of POST /v1/templates.
+type TemplateRequest struct {
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+	Subject  string `json:"subject"`
+	MJML     string `json:"mjml"`
+	Text     string `json:"text,omitempty"`
+}
+
+// TemplateHandler manages MJML email templates. Templates are created as
+// drafts and compiled to HTML when published, so a broken layout is caught
+// by the author instead of at send time.
+type TemplateHandler struct {
+	repo     TemplateRepository
+	compiler TemplateCompiler // nil when no MJML API is configured
+	enricher TemplateEnricher // nil when AI is not configured
+	logger   *zap.Logger
+}
+
+// NewTemplateHandler creates a handler for email templates. compiler may be
+// nil, in which case publishing returns 503.
+func NewTemplateHandler(logger *zap.Logger, repo TemplateRepository, compiler TemplateCompiler) *TemplateHandler {
+	return &TemplateHandler{
+		repo:     repo,
+		compiler: compiler,
+		logger:   logger,
+	}
+}
+
+// SetEnricher lets previews ask for an AI-written body.
+func (h *TemplateHandler) SetEnricher(enricher TemplateEnricher) {
+	h.enricher = enricher
+}
+
+// CreateTemplate handles POST /v1/templates
+func (h *TemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req TemplateRequest
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTemplateSourceBytes+4096))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	if req.TenantID == "" || req.Name == "" || req.Subject == "" || strings.TrimSpace(req.MJML) == "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMissingFields, "tenant_id, name, subject, and mjml are required")
+		return
+	}
+
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTenant, errDetailInvalidTenant)
+		return
+	}
+
+	if len(req.Name) > maxTemplateNameLength {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid name", "name must be at most 128 characters")
+		return
+	}
+
+	if len(req.MJML) > maxTemplateSourceBytes {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid mjml", "mjml must be at most 256 KiB")
+		return
+	}
+
+	tmpl := &db.Template{
+		TenantID:   tenantID,
+		Name:       req.Name,
+		Subject:    req.Subject,
+		MJMLSource: req.MJML,
+		TextBody:   req.Text,
+	}
+
+	if err := h.repo.CreateTemplate(r.Context(), tmpl); err != nil {
+		if errors.Is(err, db.ErrTemplateNameTaken) {
+			writeProblem(w, http.StatusConflict, errTypeConflict, "Template name already exists", "")
+			return
+		}
+		h.logger.Error("failed to create template",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to create template", "")
+		return
+	}
+
+	h.logger.Info("template created",
+		zap.String("template_id", tmpl.ID.String()),
+		zap.String(logFieldTenantID, tenantID.String()),
+	)
+
+	writeTemplate(w, http.StatusCreated, tmpl)
+}
+
+// GetTemplate handles GET /v1/templates/{id}
+func (h *TemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := h.loadTemplate(r.Context(), w, id)
+	if !ok {
+		return
+	}
+
+	writeTemplate(w, http.StatusOK, tmpl)
+}
+
+// PublishTemplate handles POST /v1/templates/{id}/publish. It compiles the
+// MJML source and stores the HTML the worker will send. Invalid MJML is
+// rejected with 422 and nothing is stored.
+func (h *TemplateHandler) PublishTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	if h.compiler == nil {
+		writeProblem(w, http.StatusServiceUnavailable, errTypeCompilerUnavailable, "MJML compiler not configured", "set MJML_API_URL or MJML_APP_ID to enable publishing")
+		return
+	}
+
+	ctx := r.Context()
+
+	tmpl, ok := h.loadTemplate(ctx, w, id)
+	if !ok {
+		return
+	}
+
+	html, err := h.compiler.Compile(ctx, tmpl.MJMLSource)
+	if err != nil {
+		var compileErr *mjml.CompileError
+		if errors.As(err, &compileErr) {
+			writeProblem(w, http.StatusUnprocessableEntity, errTypeTemplateInvalid, "MJML failed to compile", strings.Join(compileErr.Messages, "; "))
+			return
+		}
+		h.logger.Error("mjml compilation failed",
+			zap.Error(err),
+			zap.String("template_id", id.String()),
+		)
+		writeProblem(w, http.StatusBadGateway, errTypeCompilerError, "MJML compiler unavailable", "")
+		return
+	}
+
+	published, err := h.repo.PublishTemplate(ctx, id, html)
+	if err != nil {
+		h.logger.Error("failed to publish template",
+			zap.Error(err),
+			zap.String("template_id", id.String()),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to publish template", "")
+		return
+	}
+
+	h.logger.Info("template published",
+		zap.String("template_id", id.String()),
+		zap.String(logFieldTenantID, published.TenantID.String()),
+		zap.Int("html_length", len(html)),
+	)
+
+	writeTemplate(w, http.StatusOK, published)
+}
+
+// TemplatePreviewRequest is the body of POST /v1/templates/{id}/preview.
+type TemplatePreviewRequest struct {
+	Variables map[string]string `json:"variables"`
+	// Enrich replaces the text body with an AI-written one, using the
+	// variables as context.
+	Enrich bool `json:"enrich,omitempty"`
+}
+
+// TemplatePreview is the rendered template returned by a preview.
+type TemplatePreview struct {
+	worker.RenderedTemplate
+	TemplateID string `json:"template_id"`
+	Status     string `json:"status"`
+	Enriched   bool   `json:"enriched"`
+}
+
+// PreviewTemplate handles POST /v1/templates/{id}/preview. It renders the
+// template with the given variables exactly as a send would, without
+// creating a notification. A published template renders its stored HTML; a
+// draft is compiled on the fly, so editors can preview before publishing.
+func (h *TemplateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var req TemplatePreviewRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+	if req.Enrich && h.enricher == nil {
+		writeProblem(w, http.StatusServiceUnavailable, errTypeEnricherUnavailable, "AI enrichment not configured", "set OPENAI_API_KEY to enable enrichment")
+		return
+	}
+
+	ctx := r.Context()
+
+	tmpl, ok := h.loadTemplate(ctx, w, id)
+	if !ok {
+		return
+	}
+
+	html := tmpl.HTML
+	var err error
+	if tmpl.Status != db.TemplateStatusPublished {
+		if h.compiler == nil {
+			writeProblem(w, http.StatusServiceUnavailable, errTypeCompilerUnavailable, "MJML compiler not configured", "drafts can't be previewed without MJML_API_URL or MJML_APP_ID")
+			return
+		}
+		html, err = h.compiler.Compile(ctx, tmpl.MJMLSource)
+		if err != nil {
+			var compileErr *mjml.CompileError
+			if errors.As(err, &compileErr) {
+				writeProblem(w, http.StatusUnprocessableEntity, errTypeTemplateInvalid, "MJML failed to compile", strings.Join(compileErr.Messages, "; "))
+				return
+			}
+			h.logger.Error("mjml compilation failed",
+				zap.Error(err),
+				zap.String("template_id", id.String()),
+			)
+			writeProblem(w, http.StatusBadGateway, errTypeCompilerError, "MJML compiler unavailable", "")
+			return
+		}
+	}
+
+	rendered, err := worker.RenderTemplate(tmpl.Subject, tmpl.TextBody, html, req.Variables)
+	if err != nil {
+		writeProblem(w, http.StatusUnprocessableEntity, errTypeTemplateInvalid, "Template failed to render", err.Error())
+		return
+	}
+
+	preview := TemplatePreview{
+		RenderedTemplate: *rendered,
+		TemplateID:       tmpl.ID.String(),
+		Status:           tmpl.Status,
+	}
+	if req.Enrich {
+		body, err := h.enricher.GenerateEmailBody(ctx, tmpl.Name, preview.Subject, req.Variables)
+		if err != nil {
+			h.logger.Error("template preview enrichment failed",
+				zap.Error(err),
+				zap.String("template_id", id.String()),
+			)
+			writeProblem(w, http.StatusBadGateway, errTypeEnricherError, "AI enrichment failed", "")
+			return
+		}
+		preview.Text = body
+		preview.Enriched = true
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(preview)
+}
+
+// loadTemplate loads a template, writing a 404 when it doesn't exist. For an
+// authenticated caller another tenant's template is treated the same way, as
+// the worker's template sender does, so IDs can't be probed across tenants.
+func (h *TemplateHandler) loadTemplate(ctx context.Context, w http.ResponseWriter, id uuid.UUID) (*db.Template, bool) {
+	tmpl, err := h.repo.GetTemplate(ctx, id)
+	if err != nil {
+		writeProblem(w, http.StatusNotFound, errTypeNotFound, "Template not found", "")
+		return nil, false
+	}
+	if tenantID, ok := TenantIDFromContext(ctx); ok && tmpl.TenantID != tenantID {
+		writeProblem(w, http.StatusNotFound, errTypeNotFound, "Template not found", "")
+		return nil, false
+	}
+	return tmpl, true
+}
+
+func parseTemplateID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid template ID", "ID must be a valid UUID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeTemplate(w http.ResponseWriter, status int, tmpl *db.Template) {
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(tmpl)
+}
@@ -0,0 +1,346 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/mjml"
+)
+
+type mockTemplateRepo struct {
+	templates map[uuid.UUID]*db.Template
+}
+
+func newMockTemplateRepo() *mockTemplateRepo {
+	return &mockTemplateRepo{templates: make(map[uuid.UUID]*db.Template)}
+}
+
+func (m *mockTemplateRepo) CreateTemplate(ctx context.Context, tmpl *db.Template) error {
+	for _, existing := range m.templates {
+		if existing.TenantID == tmpl.TenantID && existing.Name == tmpl.Name {
+			return db.ErrTemplateNameTaken
+		}
+	}
+	tmpl.ID = uuid.New()
+	tmpl.Status = db.TemplateStatusDraft
+	m.templates[tmpl.ID] = tmpl
+	return nil
+}
+
+func (m *mockTemplateRepo) GetTemplate(ctx context.Context, id uuid.UUID) (*db.Template, error) {
+	tmpl, ok := m.templates[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return tmpl, nil
+}
+
+func (m *mockTemplateRepo) PublishTemplate(ctx context.Context, id uuid.UUID, html string) (*db.Template, error) {
+	tmpl, ok := m.templates[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	now := time.Now()
+	tmpl.HTML = html
+	tmpl.Status = db.TemplateStatusPublished
+	tmpl.PublishedAt = &now
+	return tmpl, nil
+}
+
+type fakeCompiler struct {
+	html string
+	err  error
+}
+
+func (f *fakeCompiler) Compile(ctx context.Context, source string) (string, error) {
+	return f.html, f.err
+}
+
+type fakeEnricher struct {
+	gotVars map[string]string
+	err     error
+}
+
+func (f *fakeEnricher) GenerateEmailBody(ctx context.Context, template, subject string, vars map[string]string) (string, error) {
+	f.gotVars = vars
+	return "Generated for " + template + ": " + subject, f.err
+}
+
+func newTemplateRouter(repo *mockTemplateRepo, compiler TemplateCompiler) http.Handler {
+	h := NewTemplateHandler(zap.NewNop(), repo, compiler)
+	r := chi.NewRouter()
+	r.Post("/v1/templates", h.CreateTemplate)
+	r.Get("/v1/templates/{id}", h.GetTemplate)
+	r.Post("/v1/templates/{id}/publish", h.PublishTemplate)
+	r.Post("/v1/templates/{id}/preview", h.PreviewTemplate)
+	return r
+}
+
+func TestCreateTemplate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]string
+		expectedStatus int
+	}{
+		{
+			name: "valid draft",
+			body: map[string]string{
+				"tenant_id": "00000000-0000-0000-0000-000000000001",
+				"name":      "welcome",
+				"subject":   "Welcome!",
+				"mjml":      "<mjml><mj-body></mj-body></mjml>",
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "missing mjml",
+			body: map[string]string{
+				"tenant_id": "00000000-0000-0000-0000-000000000001",
+				"name":      "welcome",
+				"subject":   "Welcome!",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid tenant",
+			body: map[string]string{
+				"tenant_id": "nope",
+				"name":      "welcome",
+				"subject":   "Welcome!",
+				"mjml":      "<mjml></mjml>",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/v1/templates", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			newTemplateRouter(newMockTemplateRepo(), nil).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusCreated {
+				var tmpl db.Template
+				if err := json.Unmarshal(rec.Body.Bytes(), &tmpl); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if tmpl.Status != db.TemplateStatusDraft || tmpl.HTML != "" {
+					t.Errorf("expected an uncompiled draft, got status %q html %q", tmpl.Status, tmpl.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTemplate_DuplicateName(t *testing.T) {
+	repo := newMockTemplateRepo()
+	router := newTemplateRouter(repo, nil)
+	body := `{"tenant_id":"00000000-0000-0000-0000-000000000001","name":"welcome","subject":"Hi","mjml":"<mjml></mjml>"}`
+
+	for i, want := range []int{http.StatusCreated, http.StatusConflict} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/templates", bytes.NewBufferString(body)))
+		if rec.Code != want {
+			t.Fatalf("request %d: expected %d, got %d", i+1, want, rec.Code)
+		}
+	}
+}
+
+func TestPublishTemplate(t *testing.T) {
+	tests := []struct {
+		name           string
+		compiler       TemplateCompiler
+		expectedStatus int
+		expectedHTML   string
+	}{
+		{"compiles and stores html", &fakeCompiler{html: "<html>hi</html>"}, http.StatusOK, "<html>hi</html>"},
+		{"invalid mjml", &fakeCompiler{err: &mjml.CompileError{Messages: []string{"line 1: bad"}}}, http.StatusUnprocessableEntity, ""},
+		{"compiler down", &fakeCompiler{err: errors.New("connection refused")}, http.StatusBadGateway, ""},
+		{"no compiler configured", nil, http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockTemplateRepo()
+			tmpl := &db.Template{
+				TenantID:   uuid.New(),
+				Name:       "welcome",
+				Subject:    "Welcome!",
+				MJMLSource: "<mjml></mjml>",
+			}
+			_ = repo.CreateTemplate(context.Background(), tmpl)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/templates/"+tmpl.ID.String()+"/publish", nil)
+			newTemplateRouter(repo, tt.compiler).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tmpl.HTML != tt.expectedHTML {
+				t.Errorf("expected stored html %q, got %q", tt.expectedHTML, tmpl.HTML)
+			}
+			if tt.expectedStatus != http.StatusOK && tmpl.Status != db.TemplateStatusDraft {
+				t.Errorf("expected template to stay a draft, got %q", tmpl.Status)
+			}
+		})
+	}
+}
+
+func TestPublishTemplate_NotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/templates/"+uuid.New().String()+"/publish", nil)
+	newTemplateRouter(newMockTemplateRepo(), &fakeCompiler{html: "x"}).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestTemplates_OtherTenant(t *testing.T) {
+	repo := newMockTemplateRepo()
+	tmpl := &db.Template{
+		TenantID:   uuid.New(),
+		Name:       "welcome",
+		Subject:    "Welcome!",
+		MJMLSource: "<mjml></mjml>",
+	}
+	_ = repo.CreateTemplate(context.Background(), tmpl)
+	router := newTemplateRouter(repo, &fakeCompiler{html: "<html>hi</html>"})
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/v1/templates/" + tmpl.ID.String()},
+		{http.MethodPost, "/v1/templates/" + tmpl.ID.String() + "/publish"},
+		{http.MethodPost, "/v1/templates/" + tmpl.ID.String() + "/preview"},
+	}
+	for _, tt := range tests {
+		for _, tenantID := range []uuid.UUID{uuid.New(), tmpl.TenantID} {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(`{}`))
+			req = req.WithContext(context.WithValue(req.Context(), contextKeyTenantID, tenantID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			owner := tenantID == tmpl.TenantID
+			if !owner && rec.Code != http.StatusNotFound {
+				t.Errorf("%s %s: expected 404 for another tenant, got %d", tt.method, tt.path, rec.Code)
+			}
+			if owner && rec.Code != http.StatusOK {
+				t.Errorf("%s %s: expected 200 for the owner, got %d: %s", tt.method, tt.path, rec.Code, rec.Body.String())
+			}
+		}
+	}
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	repo := newMockTemplateRepo()
+	published := &db.Template{
+		TenantID:   uuid.New(),
+		Name:       "welcome",
+		Subject:    "Welcome to {{.product}}",
+		MJMLSource: "<mjml></mjml>",
+		TextBody:   "Hi {{.name}}",
+	}
+	_ = repo.CreateTemplate(context.Background(), published)
+	_, _ = repo.PublishTemplate(context.Background(), published.ID, "<p>Hi {{.name}}</p>")
+	draft := &db.Template{TenantID: published.TenantID, Name: "draft", Subject: "Draft for {{.name}}", MJMLSource: "<mjml></mjml>"}
+	_ = repo.CreateTemplate(context.Background(), draft)
+
+	preview := func(h http.Handler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/templates/"+id.String()+"/preview", bytes.NewBufferString(body)))
+		return rec
+	}
+	router := newTemplateRouter(repo, &fakeCompiler{html: "<p>Compiled for {{.name}}</p>"})
+
+	rec := preview(router, published.ID, `{"variables":{"product":"Nimbus","name":"<Ada>"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got TemplatePreview
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Subject != "Welcome to Nimbus" || got.Text != "Hi <Ada>" || got.HTML != "<p>Hi &lt;Ada&gt;</p>" || got.Status != db.TemplateStatusPublished || got.Enriched {
+		t.Errorf("unexpected preview: %+v", got)
+	}
+
+	rec = preview(router, draft.ID, `{"variables":{"name":"Ada"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a draft, got %d: %s", rec.Code, rec.Body.String())
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &got)
+	if got.HTML != "<p>Compiled for Ada</p>" || got.Subject != "Draft for Ada" {
+		t.Errorf("expected the draft compiled and rendered, got %+v", got)
+	}
+	if draft.Status != db.TemplateStatusDraft || draft.HTML != "" {
+		t.Error("expected a preview to leave the draft unchanged")
+	}
+
+	if rec := preview(router, published.ID, `{"variables":{"product":"Nimbus"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a missing variable, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := preview(router, uuid.New(), `{}`); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+	if rec := preview(newTemplateRouter(repo, nil), draft.ID, `{"variables":{"name":"Ada"}}`); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 previewing a draft without a compiler, got %d", rec.Code)
+	}
+}
+
+func TestPreviewTemplate_Enrich(t *testing.T) {
+	repo := newMockTemplateRepo()
+	tmpl := &db.Template{TenantID: uuid.New(), Name: "welcome", Subject: "Welcome, {{.name}}", MJMLSource: "<mjml></mjml>"}
+	_ = repo.CreateTemplate(context.Background(), tmpl)
+	_, _ = repo.PublishTemplate(context.Background(), tmpl.ID, "<p>hi</p>")
+	body := `{"variables":{"name":"Ada"},"enrich":true}`
+	url := "/v1/templates/" + tmpl.ID.String() + "/preview"
+
+	rec := httptest.NewRecorder()
+	newTemplateRouter(repo, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without an enricher, got %d", rec.Code)
+	}
+
+	enricher := &fakeEnricher{}
+	h := NewTemplateHandler(zap.NewNop(), repo, nil)
+	h.SetEnricher(enricher)
+	r := chi.NewRouter()
+	r.Post("/v1/templates/{id}/preview", h.PreviewTemplate)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got TemplatePreview
+	_ = json.Unmarshal(rec.Body.Bytes(), &got)
+	if !got.Enriched || got.Text != "Generated for welcome: Welcome, Ada" || enricher.gotVars["name"] != "Ada" {
+		t.Errorf("unexpected enriched preview: %+v", got)
+	}
+
+	enricher.err = errors.New("rate limited")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body)))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 when enrichment fails, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// maxPauseReasonLength bounds the free-text reason stored with a pause.
+const maxPauseReasonLength = 500
+
+// TenantPauseRepository reads and writes tenant delivery pauses.
+type TenantPauseRepository interface {
+	PauseTenant(ctx context.Context, p *db.TenantPause) error
+	ResumeTenant(ctx context.Context, tenantID uuid.UUID) error
+	ListTenantPauses(ctx context.Context) ([]*db.TenantPause, error)
+}
+
+// TenantPauseHandler serves the admin endpoints that pause and resume
+// delivery for a single tenant, e.g. during a customer-requested freeze or
+// while their content is under review. A paused tenant can still create
+// notifications; the worker just leaves them pending until resume.
+type TenantPauseHandler struct {
+	repo   TenantPauseRepository
+	logger *zap.Logger
+}
+
+// NewTenantPauseHandler creates the admin handler for tenant delivery pauses.
+func NewTenantPauseHandler(logger *zap.Logger, repo TenantPauseRepository) *TenantPauseHandler {
+	return &TenantPauseHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListPauses handles GET /v1/admin/tenants/paused
+func (h *TenantPauseHandler) ListPauses(w http.ResponseWriter, r *http.Request) {
+	pauses, err := h.repo.ListTenantPauses(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list tenant pauses", zap.Error(err))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list paused tenants", "")
+		return
+	}
+	if pauses == nil {
+		pauses = []*db.TenantPause{}
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenants": pauses,
+	})
+}
+
+// Pause handles PUT /v1/admin/tenants/{tenantID}/pause {"reason": "..."}
+func (h *TenantPauseHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+			return
+		}
+	}
+	if len(req.Reason) > maxPauseReasonLength {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid reason", "reason must be at most 500 characters")
+		return
+	}
+
+	pause := &db.TenantPause{TenantID: tenantID, Reason: req.Reason}
+	if err := h.repo.PauseTenant(r.Context(), pause); err != nil {
+		h.logger.Error("failed to pause tenant", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to pause tenant", "")
+		return
+	}
+
+	h.logger.Info("tenant delivery paused",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.String("reason", req.Reason),
+	)
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(pause)
+}
+
+// Resume handles DELETE /v1/admin/tenants/{tenantID}/pause
+func (h *TenantPauseHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.repo.ResumeTenant(r.Context(), tenantID)
+	if errors.Is(err, db.ErrTenantNotPaused) {
+		writeProblem(w, http.StatusNotFound, "not_found", "Tenant not paused", "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to resume tenant", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to resume tenant", "")
+		return
+	}
+
+	h.logger.Info("tenant delivery resumed", zap.String(logFieldTenantID, tenantID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func tenantIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTenant, errDetailInvalidTenant)
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockTenantPauseRepo struct {
+	pauses map[uuid.UUID]*db.TenantPause
+	err    error
+}
+
+func (m *mockTenantPauseRepo) PauseTenant(ctx context.Context, p *db.TenantPause) error {
+	if m.err != nil {
+		return m.err
+	}
+	p.PausedAt = time.Now()
+	m.pauses[p.TenantID] = p
+	return nil
+}
+
+func (m *mockTenantPauseRepo) ResumeTenant(ctx context.Context, tenantID uuid.UUID) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.pauses[tenantID]; !ok {
+		return db.ErrTenantNotPaused
+	}
+	delete(m.pauses, tenantID)
+	return nil
+}
+
+func (m *mockTenantPauseRepo) ListTenantPauses(ctx context.Context) ([]*db.TenantPause, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var out []*db.TenantPause
+	for _, p := range m.pauses {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func tenantPauseRequest(method, tenantID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/admin/tenants/"+tenantID+"/pause", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTenantPause(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	repo := &mockTenantPauseRepo{pauses: map[uuid.UUID]*db.TenantPause{}}
+	handler := NewTenantPauseHandler(zap.NewNop(), repo)
+
+	rec := httptest.NewRecorder()
+	handler.Pause(rec, tenantPauseRequest(http.MethodPut, tenantID.String(), `{"reason":"content review"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from pause, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if p := repo.pauses[tenantID]; p == nil || p.Reason != "content review" {
+		t.Fatalf("expected tenant paused with reason, got %+v", p)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ListPauses(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/tenants/paused", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tenantID.String()) {
+		t.Errorf("expected paused tenant listed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.Resume(rec, tenantPauseRequest(http.MethodDelete, tenantID.String(), ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 from resume, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.Resume(rec, tenantPauseRequest(http.MethodDelete, tenantID.String(), ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 resuming an unpaused tenant, got %d", rec.Code)
+	}
+}
+
+func TestTenantPause_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		tenantID       string
+		body           string
+		repoErr        error
+		expectedStatus int
+	}{
+		{"invalid tenant id", "nope", `{}`, nil, http.StatusBadRequest},
+		{"malformed body", "00000000-0000-0000-0000-000000000001", `{`, nil, http.StatusBadRequest},
+		{"reason too long", "00000000-0000-0000-0000-000000000001", `{"reason":"` + strings.Repeat("x", 501) + `"}`, nil, http.StatusBadRequest},
+		{"repository error", "00000000-0000-0000-0000-000000000001", `{}`, errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockTenantPauseRepo{pauses: map[uuid.UUID]*db.TenantPause{}, err: tt.repoErr}
+			rec := httptest.NewRecorder()
+			NewTenantPauseHandler(zap.NewNop(), repo).Pause(rec, tenantPauseRequest(http.MethodPut, tt.tenantID, tt.body))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	maxTenantSettingsBytes = 4 << 10
+	tenantSettingsCacheTTL = 30 * time.Second
+)
+
+const (
+	errTitleIdempotencyKeyRequired  = "Idempotency key required"
+	errDetailIdempotencyKeyRequired = "this tenant requires an " + headerIdempotencyKey + " header on every create"
+)
+
+// TenantSettingsRepository reads and writes tenant API settings.
+type TenantSettingsRepository interface {
+	GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*db.TenantSettings, error)
+	UpsertTenantSettings(ctx context.Context, s *db.TenantSettings) error
+}
+
+// IdempotencyPolicy tells create whether a tenant must send an
+// Idempotency-Key. *TenantSettingsHandler implements it.
+type IdempotencyPolicy interface {
+	RequireIdempotencyKey(ctx context.Context, tenantID uuid.UUID) bool
+}
+
+// TenantSettingsHandler exposes a tenant's API-wide settings and serves
+// them to create through IdempotencyPolicy. Settings are cached for
+// tenantSettingsCacheTTL; a change takes effect immediately on this
+// instance and within the TTL on others.
+type TenantSettingsHandler struct {
+	repo   TenantSettingsRepository
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedTenantSettings
+}
+
+type cachedTenantSettings struct {
+	settings *db.TenantSettings
+	loadedAt time.Time
+}
+
+// NewTenantSettingsHandler creates a handler for tenant API settings.
+func NewTenantSettingsHandler(logger *zap.Logger, repo TenantSettingsRepository) *TenantSettingsHandler {
+	return &TenantSettingsHandler{
+		repo:   repo,
+		logger: logger,
+		cache:  make(map[uuid.UUID]cachedTenantSettings),
+	}
+}
+
+type tenantSettingsRequest struct {
+	RequireIdempotencyKey bool `json:"require_idempotency_key"`
+}
+
+// GetSettings handles GET /v1/tenants/{tenant_id}/settings
+func (h *TenantSettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.repo.GetTenantSettings(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to get tenant settings", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get tenant settings", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// PutSettings handles PUT /v1/tenants/{tenant_id}/settings
+// {"require_idempotency_key": true}. The body replaces the settings.
+func (h *TenantSettingsHandler) PutSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req tenantSettingsRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTenantSettingsBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	settings := &db.TenantSettings{
+		TenantID:              tenantID,
+		RequireIdempotencyKey: req.RequireIdempotencyKey,
+	}
+	if err := h.repo.UpsertTenantSettings(r.Context(), settings); err != nil {
+		h.logger.Error("failed to save tenant settings", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to save tenant settings", "")
+		return
+	}
+	h.store(settings)
+
+	h.logger.Info("tenant settings updated",
+		zap.String(logFieldTenantID, tenantID.String()),
+		zap.Bool("require_idempotency_key", settings.RequireIdempotencyKey),
+	)
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// RequireIdempotencyKey reports whether the tenant has opted in to
+// mandatory idempotency keys. Lookup errors don't require one: failing
+// every create because a settings read failed would be worse than
+// accepting a retry-unsafe one.
+func (h *TenantSettingsHandler) RequireIdempotencyKey(ctx context.Context, tenantID uuid.UUID) bool {
+	h.mu.Lock()
+	cached, ok := h.cache[tenantID]
+	h.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < tenantSettingsCacheTTL {
+		return cached.settings.RequireIdempotencyKey
+	}
+
+	settings, err := h.repo.GetTenantSettings(ctx, tenantID)
+	if err != nil {
+		h.logger.Warn("failed to load tenant settings, not requiring an idempotency key",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		return false
+	}
+
+	h.store(settings)
+	return settings.RequireIdempotencyKey
+}
+
+func (h *TenantSettingsHandler) store(settings *db.TenantSettings) {
+	h.mu.Lock()
+	h.cache[settings.TenantID] = cachedTenantSettings{settings: settings, loadedAt: time.Now()}
+	h.mu.Unlock()
+}
+
+// SetIdempotencyPolicy makes create reject requests without an
+// Idempotency-Key header for tenants whose policy requires one.
+func (h *Handler) SetIdempotencyPolicy(policy IdempotencyPolicy) {
+	h.idempotencyPolicy = policy
+}
+
+// requiresIdempotencyKey reports whether tenantID must send an
+// Idempotency-Key.
+func (h *Handler) requiresIdempotencyKey(ctx context.Context, tenantID uuid.UUID) bool {
+	return h.idempotencyPolicy != nil && h.idempotencyPolicy.RequireIdempotencyKey(ctx, tenantID)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockTenantSettingsRepo struct {
+	settings map[uuid.UUID]*db.TenantSettings
+	gets     int
+}
+
+func (m *mockTenantSettingsRepo) GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*db.TenantSettings, error) {
+	m.gets++
+	if s, ok := m.settings[tenantID]; ok {
+		return s, nil
+	}
+	return &db.TenantSettings{TenantID: tenantID}, nil
+}
+
+func (m *mockTenantSettingsRepo) UpsertTenantSettings(ctx context.Context, s *db.TenantSettings) error {
+	m.settings[s.TenantID] = s
+	return nil
+}
+
+func tenantSettingsHTTPRequest(method, tenantID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/tenants/"+tenantID+"/settings", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTenantSettings(t *testing.T) {
+	repo := &mockTenantSettingsRepo{settings: map[uuid.UUID]*db.TenantSettings{}}
+	handler := NewTenantSettingsHandler(zap.NewNop(), repo)
+	tenantID := uuid.New()
+
+	rec := httptest.NewRecorder()
+	handler.GetSettings(rec, tenantSettingsHTTPRequest(http.MethodGet, tenantID.String(), ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"require_idempotency_key":false`) {
+		t.Errorf("expected default settings, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if handler.RequireIdempotencyKey(context.Background(), tenantID) {
+		t.Error("expected no key to be required by default")
+	}
+
+	for name, body := range map[string]string{
+		"malformed":     `{`,
+		"unknown field": `{"require_idempotency_key": true, "strict": true}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.PutSettings(rec, tenantSettingsHTTPRequest(http.MethodPut, tenantID.String(), body))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.PutSettings(rec, tenantSettingsHTTPRequest(http.MethodPut, tenantID.String(), `{"require_idempotency_key": true}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The write refreshes this instance's cache, so it applies right away.
+	gets := repo.gets
+	if !handler.RequireIdempotencyKey(context.Background(), tenantID) {
+		t.Error("expected a key to be required after the update")
+	}
+	if repo.gets != gets {
+		t.Error("expected the policy to be served from cache")
+	}
+}
+
+func TestCreateNotification_RequiredIdempotencyKey(t *testing.T) {
+	repo := &mockTenantSettingsRepo{settings: map[uuid.UUID]*db.TenantSettings{}}
+	strict := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	repo.settings[strict] = &db.TenantSettings{TenantID: strict, RequireIdempotencyKey: true}
+
+	handler := NewHandler(zap.NewNop(), NewMockRepository())
+	handler.SetIdempotencyPolicy(NewTenantSettingsHandler(zap.NewNop(), repo))
+
+	tests := []struct {
+		name           string
+		tenantID       string
+		key            string
+		expectedStatus int
+	}{
+		{"required and missing", strict.String(), "", http.StatusBadRequest},
+		{"required and sent", strict.String(), "order-42", http.StatusCreated},
+		{"not required", "00000000-0000-0000-0000-000000000003", "", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"tenant_id":"` + tt.tenantID + `","user_id":"00000000-0000-0000-0000-000000000002","channel":"email","payload":{"to":"a@example.com"}}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/notifications", strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set(headerIdempotencyKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestV2CreateNotification_RequiredIdempotencyKey(t *testing.T) {
+	repo := &mockTenantSettingsRepo{settings: map[uuid.UUID]*db.TenantSettings{}}
+	tenantID := uuid.MustParse(v2TenantA)
+	repo.settings[tenantID] = &db.TenantSettings{TenantID: tenantID, RequireIdempotencyKey: true}
+
+	h := NewHandler(zap.NewNop(), NewMockRepository())
+	h.SetIdempotencyPolicy(NewTenantSettingsHandler(zap.NewNop(), repo))
+	v2 := NewV2Handler(h)
+	r := chi.NewRouter()
+	r.Use(BearerAuthMiddleware(map[string]string{"token-a": v2TenantA}, zap.NewNop()))
+	r.Post("/v2/notifications", v2.CreateNotification)
+
+	rec, env := doV2(t, r, http.MethodPost, "/v2/notifications", "token-a",
+		`{"user_id":"00000000-0000-0000-0000-000000000002","channel":"email","payload":{"to":"a@example.com"}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if len(env.Errors) != 1 || env.Errors[0].Code != ErrCodeMissingField || env.Errors[0].Field != headerIdempotencyKey {
+		t.Errorf("expected a missing Idempotency-Key error, got %+v", env.Errors)
+	}
+}
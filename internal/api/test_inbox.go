@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// CapturedDeliveryRepository reads the sandbox test inbox.
+type CapturedDeliveryRepository interface {
+	ListCapturedDeliveries(ctx context.Context, tenantID uuid.UUID, channel string, limit int) ([]*db.CapturedDelivery, error)
+	ClearCapturedDeliveries(ctx context.Context, tenantID uuid.UUID) (int64, error)
+}
+
+// TestInboxHandler exposes captured deliveries to integration tests.
+// It is only mounted when the gateway runs in sandbox mode.
+type TestInboxHandler struct {
+	repo   CapturedDeliveryRepository
+	logger *zap.Logger
+}
+
+// NewTestInboxHandler creates a handler for the sandbox test inbox.
+func NewTestInboxHandler(logger *zap.Logger, repo CapturedDeliveryRepository) *TestInboxHandler {
+	return &TestInboxHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListDeliveries handles GET /v1/test/deliveries?tenant_id=xxx&channel=email&limit=20
+func (h *TestInboxHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.parseTenant(w, r)
+	if !ok {
+		return
+	}
+
+	channel := r.URL.Query().Get("channel")
+	if channel != "" && !isValidChannel(channel) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidChannel, errDetailInvalidChannel)
+		return
+	}
+
+	limit := defaultPageLimit
+	if limitStr := r.URL.Query().Get(queryParamLimit); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	deliveries, err := h.repo.ListCapturedDeliveries(r.Context(), tenantID, channel, limit)
+	if err != nil {
+		h.logger.Error("failed to list captured deliveries",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to list captured deliveries", "")
+		return
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  deliveries,
+		"limit": limit,
+		"count": len(deliveries),
+	})
+}
+
+// ClearDeliveries handles DELETE /v1/test/deliveries?tenant_id=xxx
+func (h *TestInboxHandler) ClearDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.parseTenant(w, r)
+	if !ok {
+		return
+	}
+
+	removed, err := h.repo.ClearCapturedDeliveries(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to clear captured deliveries",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to clear captured deliveries", "")
+		return
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID.String(),
+		"removed":   removed,
+	})
+}
+
+func (h *TestInboxHandler) parseTenant(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantIDStr := r.URL.Query().Get("tenant_id")
+	if tenantIDStr == "" {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Missing tenant_id", "tenant_id query parameter is required")
+		return uuid.Nil, false
+	}
+
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidTenant, errDetailInvalidTenant)
+		return uuid.Nil, false
+	}
+
+	return tenantID, true
+}
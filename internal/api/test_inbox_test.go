@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockCapturedRepo struct {
+	deliveries []*db.CapturedDelivery
+	shouldFail bool
+}
+
+func (m *mockCapturedRepo) ListCapturedDeliveries(ctx context.Context, tenantID uuid.UUID, channel string, limit int) ([]*db.CapturedDelivery, error) {
+	if m.shouldFail {
+		return nil, ErrDatabaseError
+	}
+	var result []*db.CapturedDelivery
+	for _, d := range m.deliveries {
+		if d.TenantID == tenantID && (channel == "" || d.Channel == channel) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCapturedRepo) ClearCapturedDeliveries(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	if m.shouldFail {
+		return 0, ErrDatabaseError
+	}
+	var kept []*db.CapturedDelivery
+	for _, d := range m.deliveries {
+		if d.TenantID != tenantID {
+			kept = append(kept, d)
+		}
+	}
+	removed := int64(len(m.deliveries) - len(kept))
+	m.deliveries = kept
+	return removed, nil
+}
+
+func TestTestInbox_ListDeliveries(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	repo := &mockCapturedRepo{deliveries: []*db.CapturedDelivery{
+		{ID: uuid.New(), TenantID: tenantID, Channel: "email", Recipient: "a@example.com"},
+		{ID: uuid.New(), TenantID: tenantID, Channel: "sms", Recipient: "+15551234567"},
+		{ID: uuid.New(), TenantID: uuid.New(), Channel: "email", Recipient: "other@example.com"},
+	}}
+	h := NewTestInboxHandler(zap.NewNop(), repo)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"all channels", "tenant_id=" + tenantID.String(), http.StatusOK, 2},
+		{"filter by channel", "tenant_id=" + tenantID.String() + "&channel=email", http.StatusOK, 1},
+		{"invalid channel", "tenant_id=" + tenantID.String() + "&channel=telegram", http.StatusBadRequest, 0},
+		{"missing tenant", "", http.StatusBadRequest, 0},
+		{"invalid tenant", "tenant_id=nope", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/test/deliveries?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			h.ListDeliveries(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data  []*db.CapturedDelivery `json:"data"`
+				Count int                    `json:"count"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Count != tt.expectedCount || len(resp.Data) != tt.expectedCount {
+				t.Errorf("expected %d deliveries, got %d", tt.expectedCount, resp.Count)
+			}
+		})
+	}
+}
+
+func TestTestInbox_ClearDeliveries(t *testing.T) {
+	tenantID := uuid.New()
+	otherTenant := uuid.New()
+	repo := &mockCapturedRepo{deliveries: []*db.CapturedDelivery{
+		{ID: uuid.New(), TenantID: tenantID, Channel: "email"},
+		{ID: uuid.New(), TenantID: otherTenant, Channel: "email"},
+	}}
+	h := NewTestInboxHandler(zap.NewNop(), repo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/test/deliveries?tenant_id="+tenantID.String(), nil)
+	rec := httptest.NewRecorder()
+
+	h.ClearDeliveries(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if len(repo.deliveries) != 1 || repo.deliveries[0].TenantID != otherTenant {
+		t.Errorf("expected only the other tenant's delivery to remain")
+	}
+}
+
+func TestTestInbox_DatabaseError(t *testing.T) {
+	h := NewTestInboxHandler(zap.NewNop(), &mockCapturedRepo{shouldFail: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/test/deliveries?tenant_id="+uuid.NewString(), nil)
+	rec := httptest.NewRecorder()
+
+	h.ListDeliveries(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	usageDateLayout     = "2006-01-02"
+	defaultUsageDays    = 30
+	maxUsageRangeInDays = 366
+)
+
+// UsageRepository reads tenants' aggregated daily usage.
+type UsageRepository interface {
+	ListTenantUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*db.DailyUsage, error)
+}
+
+// UsageHandler reports what a tenant sent and what it cost, per UTC day
+// and channel, for chargeback.
+type UsageHandler struct {
+	repo   UsageRepository
+	logger *zap.Logger
+}
+
+// NewUsageHandler creates a handler for tenant usage.
+func NewUsageHandler(logger *zap.Logger, repo UsageRepository) *UsageHandler {
+	return &UsageHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type usageDay struct {
+	Date string `json:"date"`
+	*db.DailyUsage
+}
+
+type usageTotal struct {
+	Sent int64   `json:"sent"`
+	Cost float64 `json:"cost"`
+}
+
+type usageResponse struct {
+	From      string                `json:"from"`
+	To        string                `json:"to"`
+	Days      []usageDay            `json:"days"`
+	Totals    map[string]usageTotal `json:"totals"` // by channel
+	TotalCost float64               `json:"total_cost"`
+}
+
+// GetUsage handles GET /v1/tenants/{tenant_id}/usage?from=YYYY-MM-DD&to=YYYY-MM-DD.
+// Both bounds are inclusive UTC days; the default is the last 30 days.
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantIDPathParam(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, ok := usageRange(w, r)
+	if !ok {
+		return
+	}
+
+	usage, err := h.repo.ListTenantUsage(r.Context(), tenantID, from, to)
+	if err != nil {
+		h.logger.Error("failed to list tenant usage", zap.Error(err), zap.String(logFieldTenantID, tenantID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to get usage", "")
+		return
+	}
+
+	resp := usageResponse{
+		From:   from.Format(usageDateLayout),
+		To:     to.Format(usageDateLayout),
+		Days:   make([]usageDay, 0, len(usage)),
+		Totals: make(map[string]usageTotal),
+	}
+	for _, u := range usage {
+		resp.Days = append(resp.Days, usageDay{Date: u.Day.Format(usageDateLayout), DailyUsage: u})
+		total := resp.Totals[u.Channel]
+		total.Sent += u.Sent
+		total.Cost += u.Cost
+		resp.Totals[u.Channel] = total
+		resp.TotalCost += u.Cost
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// usageRange parses the inclusive UTC days ?from=YYYY-MM-DD&to=YYYY-MM-DD,
+// defaulting to the last 30 days, and writes a 400 if they're invalid.
+func usageRange(w http.ResponseWriter, r *http.Request) (from, to time.Time, ok bool) {
+	var err error
+
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if s := r.URL.Query().Get("to"); s != "" {
+		if to, err = time.Parse(usageDateLayout, s); err != nil {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid date", "to must be a date in YYYY-MM-DD format")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	from = to.AddDate(0, 0, -(defaultUsageDays - 1))
+	if s := r.URL.Query().Get("from"); s != "" {
+		if from, err = time.Parse(usageDateLayout, s); err != nil {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid date", "from must be a date in YYYY-MM-DD format")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if from.After(to) {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid date range", "from must not be after to")
+		return time.Time{}, time.Time{}, false
+	}
+	if to.Sub(from) >= maxUsageRangeInDays*24*time.Hour {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid date range", "the range may cover at most 366 days")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockUsageRepo struct {
+	usage    []*db.DailyUsage
+	from, to time.Time
+}
+
+func (m *mockUsageRepo) ListTenantUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*db.DailyUsage, error) {
+	m.from, m.to = from, to
+	return m.usage, nil
+}
+
+func usageRequest(tenantID, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants/"+tenantID+"/usage"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenant_id", tenantID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestGetUsage(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockUsageRepo{usage: []*db.DailyUsage{
+		{Day: day, Channel: db.ChannelEmail, Sent: 100, Cost: 0.01},
+		{Day: day, Channel: db.ChannelSMS, Sent: 10, Cost: 0.15},
+		{Day: day.AddDate(0, 0, 1), Channel: db.ChannelEmail, Sent: 50, Cost: 0.005},
+	}}
+	handler := NewUsageHandler(zap.NewNop(), repo)
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		tenantID       string
+		query          string
+		expectedStatus int
+	}{
+		{"invalid tenant", "nope", "", http.StatusBadRequest},
+		{"invalid date", tenantID, "?from=03/01/2026", http.StatusBadRequest},
+		{"reversed range", tenantID, "?from=2026-03-02&to=2026-03-01", http.StatusBadRequest},
+		{"range too long", tenantID, "?from=2024-01-01&to=2026-03-01", http.StatusBadRequest},
+		{"default range", tenantID, "", http.StatusOK},
+		{"explicit range", tenantID, "?from=2026-03-01&to=2026-03-02", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.GetUsage(rec, usageRequest(tt.tenantID, tt.query))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if !repo.from.Equal(day) || !repo.to.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("expected the query range passed through, got %s to %s", repo.from, repo.to)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetUsage(rec, usageRequest(tenantID, "?from=2026-03-01&to=2026-03-02"))
+	var resp usageResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Days) != 3 || resp.Days[0].Date != "2026-03-01" {
+		t.Errorf("expected 3 dated rows, got %+v", resp.Days)
+	}
+	if email := resp.Totals[db.ChannelEmail]; email.Sent != 150 {
+		t.Errorf("expected 150 emails in total, got %d", email.Sent)
+	}
+	if resp.TotalCost < 0.1649 || resp.TotalCost > 0.1651 {
+		t.Errorf("expected total cost 0.165, got %v", resp.TotalCost)
+	}
+}
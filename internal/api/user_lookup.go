@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	defaultUserLookupLimit = 100
+	maxUserLookupLimit     = 1000
+	maxLookupReasonLength  = 500
+)
+
+// UserLookupRepository finds a user's notifications across tenants.
+type UserLookupRepository interface {
+	SearchNotificationsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*db.Notification, error)
+}
+
+// UserLookupHandler serves the admin search for a user's notifications
+// across every tenant, for incident response and abuse investigations.
+// Only operators authenticated by AdminAuthMiddleware may search. Each
+// lookup must state a reason and is written to the audit log with the
+// operator, whether or not it succeeds.
+type UserLookupHandler struct {
+	repo   UserLookupRepository
+	logger *zap.Logger
+	audit  *zap.Logger
+}
+
+// NewUserLookupHandler creates the admin cross-tenant user search handler.
+func NewUserLookupHandler(logger *zap.Logger, repo UserLookupRepository) *UserLookupHandler {
+	return &UserLookupHandler{
+		repo:   repo,
+		logger: logger,
+		audit:  logger.Named("audit"),
+	}
+}
+
+// SearchByUser handles
+// GET /v1/admin/users/{userID}/notifications?reason=...&limit=100
+func (h *UserLookupHandler) SearchByUser(w http.ResponseWriter, r *http.Request) {
+	op, ok := OperatorFromContext(r.Context())
+	if !ok {
+		writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "No authenticated operator", "")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleInvalidUser, errDetailInvalidUser)
+		return
+	}
+	reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+	if reason == "" || len(reason) > maxLookupReasonLength {
+		writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid reason",
+			"reason is required and must be at most 500 characters; it is recorded in the audit log")
+		return
+	}
+	limit := defaultUserLookupLimit
+	if raw := r.URL.Query().Get(queryParamLimit); raw != "" {
+		l, err := strconv.Atoi(raw)
+		if err != nil || l <= 0 || l > maxUserLookupLimit {
+			writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Invalid limit", "limit must be between 1 and 1000")
+			return
+		}
+		limit = l
+	}
+
+	notifications, err := h.repo.SearchNotificationsByUser(r.Context(), userID, limit)
+
+	var tenants []string
+	seen := make(map[uuid.UUID]bool)
+	for _, n := range notifications {
+		if !seen[n.TenantID] {
+			seen[n.TenantID] = true
+			tenants = append(tenants, n.TenantID.String())
+		}
+	}
+	h.audit.Warn("cross-tenant user lookup",
+		zap.String("operator", op.Name),
+		zap.String("user_id", userID.String()),
+		zap.String("reason", reason),
+		zap.String("request_id", middleware.GetReqID(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.Int("results", len(notifications)),
+		zap.Strings("tenants", tenants),
+		zap.Bool("failed", err != nil),
+	)
+
+	if err != nil {
+		h.logger.Error("failed to search notifications by user", zap.Error(err), zap.String("user_id", userID.String()))
+		writeProblem(w, http.StatusInternalServerError, errTypeDatabaseError, "Failed to search notifications", "")
+		return
+	}
+	if notifications == nil {
+		notifications = []*db.Notification{}
+	}
+	if tenants == nil {
+		tenants = []string{}
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userID,
+		"data":    notifications,
+		"tenants": tenants,
+		"count":   len(notifications),
+		"limit":   limit,
+	})
+}
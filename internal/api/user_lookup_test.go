@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockUserLookupRepo struct {
+	notifications []*db.Notification
+	err           error
+	gotLimit      int
+}
+
+func (m *mockUserLookupRepo) SearchNotificationsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*db.Notification, error) {
+	m.gotLimit = limit
+	return m.notifications, m.err
+}
+
+// userLookupRequest is a lookup by the support operator jane@example.com.
+func userLookupRequest(userID, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/users/"+userID+"/notifications?"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userID", userID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, contextKeyOperator, Operator{Name: "jane@example.com", Role: OperatorRoleSupport})
+	return req.WithContext(ctx)
+}
+
+func TestSearchByUser(t *testing.T) {
+	userID := uuid.New()
+	tenantA, tenantB := uuid.New(), uuid.New()
+	repo := &mockUserLookupRepo{notifications: []*db.Notification{
+		{ID: uuid.New(), TenantID: tenantA, UserID: userID},
+		{ID: uuid.New(), TenantID: tenantB, UserID: userID},
+		{ID: uuid.New(), TenantID: tenantA, UserID: userID},
+	}}
+	core, logs := observer.New(zapcore.InfoLevel)
+	handler := NewUserLookupHandler(zap.New(core), repo)
+
+	rec := httptest.NewRecorder()
+	handler.SearchByUser(rec, userLookupRequest(userID.String(), "reason=INC-42&limit=10"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Count   int      `json:"count"`
+		Tenants []string `json:"tenants"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Count != 3 || len(body.Tenants) != 2 || repo.gotLimit != 10 {
+		t.Errorf("unexpected response %+v, limit %d", body, repo.gotLimit)
+	}
+
+	audit := auditLogs(logs).All()
+	if len(audit) != 1 {
+		t.Fatalf("expected one audit entry, got %d", len(audit))
+	}
+	if fields := audit[0].ContextMap(); fields["reason"] != "INC-42" || fields["user_id"] != userID.String() || fields["operator"] != "jane@example.com" {
+		t.Errorf("unexpected audit fields: %v", fields)
+	}
+}
+
+func TestSearchByUser_AuditsFailures(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	handler := NewUserLookupHandler(zap.New(core), &mockUserLookupRepo{err: errors.New("db down")})
+
+	rec := httptest.NewRecorder()
+	handler.SearchByUser(rec, userLookupRequest(uuid.NewString(), "reason=abuse+report"))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if audit := auditLogs(logs).All(); len(audit) != 1 || audit[0].ContextMap()["failed"] != true {
+		t.Errorf("expected the failed lookup to be audited, got %v", audit)
+	}
+}
+
+func TestSearchByUser_RequiresOperator(t *testing.T) {
+	userID := uuid.NewString()
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/users/"+userID+"/notifications?reason=INC-42", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userID", userID)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	repo := &mockUserLookupRepo{}
+	rec := httptest.NewRecorder()
+	NewUserLookupHandler(zap.NewNop(), repo).SearchByUser(rec, req)
+	if rec.Code != http.StatusUnauthorized || repo.gotLimit != 0 {
+		t.Errorf("expected 401 without searching, got %d", rec.Code)
+	}
+}
+
+func TestSearchByUser_Validation(t *testing.T) {
+	tests := map[string]*http.Request{
+		"bad user id":    userLookupRequest("not-a-uuid", "reason=x"),
+		"missing reason": userLookupRequest(uuid.NewString(), ""),
+		"blank reason":   userLookupRequest(uuid.NewString(), "reason=+++"),
+		"limit too high": userLookupRequest(uuid.NewString(), "reason=x&limit=5000"),
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			rec := httptest.NewRecorder()
+			NewUserLookupHandler(zap.New(core), &mockUserLookupRepo{}).SearchByUser(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", rec.Code)
+			}
+			if auditLogs(logs).Len() != 0 {
+				t.Error("rejected requests never reach the repository and are not audited")
+			}
+		})
+	}
+}
+
+func auditLogs(logs *observer.ObservedLogs) *observer.ObservedLogs {
+	return logs.Filter(func(e observer.LoggedEntry) bool { return e.LoggerName == "audit" })
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	errTypeUnauthorized = "unauthorized"
+	errTypeForbidden    = "forbidden"
+
+	errTitleTenantMismatch  = "Tenant mismatch"
+	errDetailTenantMismatch = "tenant_id does not match the tenant of the API key"
+
+	// maxV1AuthBodyBytes bounds the JSON bodies V1AuthMiddleware reads to
+	// find their tenant_id. It is above every /v1 JSON body limit.
+	maxV1AuthBodyBytes = 8 << 20
+)
+
+// V1AuthMiddleware authenticates /v1 requests with the same bearer tokens
+// and API keys as /v2, and refuses any that name another tenant: a
+// tenant_id in the query string, in a JSON body, or in a
+// /tenants/{tenant_id}/ path. That only covers handlers that take
+// tenant_id from the request. Handlers that address a row by its own ID
+// (notifications, DLQ items, templates) never see a tenant_id here and must
+// scope the lookup to TenantIDFromContext themselves, as getNotification
+// does. Keys without the write scope, and tokenRoles' read-only tokens, may only
+// read, and see payloads masked. Errors are problem+json, like the rest of
+// /v1.
+func V1AuthMiddleware(validTokens map[string]string, tokenRoles map[string]string, keys APIKeyStore, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := bearerToken(r)
+			if !found {
+				writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "Missing bearer token", "send Authorization: Bearer <api key>")
+				return
+			}
+			ctx, ok := authenticateToken(r.Context(), token, validTokens, keys, logger)
+			if !ok {
+				logger.Warn("REST auth: invalid token",
+					zap.String("token_prefix", tokenPrefix(token)),
+				)
+				writeProblem(w, http.StatusUnauthorized, errTypeUnauthorized, "Invalid bearer token", "")
+				return
+			}
+			r = r.WithContext(ctx)
+			auditImpersonation(r, logger)
+
+			readOnly := !HasScope(ctx, ScopeWrite) || Role(tokenRoles[token]) == RoleReadOnly
+			if readOnly && !isReadMethod(r.Method) {
+				writeProblem(w, http.StatusForbidden, errTypeForbidden, "Read-only API key", "this key can't create or change anything")
+				return
+			}
+			if readOnly {
+				// Handlers mask payloads for read-only callers, as on /v2.
+				ctx = context.WithValue(ctx, contextKeyRole, RoleReadOnly)
+				r = r.WithContext(ctx)
+			}
+
+			tenantID, _ := TenantIDFromContext(ctx)
+			named, err := requestTenantIDs(w, r)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeProblem(w, http.StatusRequestEntityTooLarge, errTypeInvalidRequest, "Request body too large", "")
+					return
+				}
+				writeProblem(w, http.StatusBadRequest, errTypeInvalidRequest, "Malformed request body", err.Error())
+				return
+			}
+			for _, id := range named {
+				// Values that aren't UUIDs are left for the handler to reject.
+				if parsed, err := uuid.Parse(id); err == nil && parsed != tenantID {
+					logger.Warn("REST auth: tenant mismatch",
+						zap.String("tenant_id", tenantID.String()),
+						zap.String("requested_tenant_id", id),
+					)
+					writeProblem(w, http.StatusForbidden, errTypeForbidden, errTitleTenantMismatch, errDetailTenantMismatch)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestTenantIDs returns every tenant ID r names. A JSON body is read in
+// full and put back for the handler. Multipart uploads are left to their
+// handler, which checks the tenant itself.
+func requestTenantIDs(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	var ids []string
+	if id := r.URL.Query().Get("tenant_id"); id != "" {
+		ids = append(ids, id)
+	}
+	if _, rest, ok := strings.Cut(r.URL.Path, "/tenants/"); ok {
+		id, _, _ := strings.Cut(rest, "/")
+		ids = append(ids, id)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headerContentType))
+	if r.Body == nil || r.Body == http.NoBody || (mediaType != "" && !strings.HasSuffix(mediaType, "json")) {
+		return ids, nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxV1AuthBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var named struct {
+		TenantID *string `json:"tenant_id"`
+	}
+	// Bodies that aren't JSON objects carry no tenant_id; the handler
+	// rejects the malformed ones.
+	if json.Unmarshal(body, &named) == nil && named.TenantID != nil {
+		ids = append(ids, *named.TenantID)
+	}
+	return ids, nil
+}
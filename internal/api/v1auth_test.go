@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestV1AuthMiddleware(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	readKey := &db.APIKey{TenantID: uuid.MustParse(v2TenantA), Name: "support", Scopes: []string{ScopeRead}}
+	readPlain, _ := IssueAPIKey(readKey)
+	_ = keys.CreateAPIKey(context.Background(), readKey)
+
+	var gotBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := V1AuthMiddleware(
+		map[string]string{"token-a": v2TenantA, "support-a": v2TenantA},
+		map[string]string{"support-a": string(RoleReadOnly)},
+		keys, zap.NewNop(),
+	)(next)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		body           string
+		expectedStatus int
+	}{
+		{"missing token", http.MethodGet, "/v1/notifications?tenant_id=" + v2TenantA, "", "", http.StatusUnauthorized},
+		{"invalid token", http.MethodGet, "/v1/notifications?tenant_id=" + v2TenantA, "nope", "", http.StatusUnauthorized},
+		{"own tenant in query", http.MethodGet, "/v1/notifications?tenant_id=" + v2TenantA, "token-a", "", http.StatusOK},
+		{"other tenant in query", http.MethodGet, "/v1/dlq?tenant_id=" + v2TenantB, "token-a", "", http.StatusForbidden},
+		{"own tenant in body", http.MethodPost, "/v1/notifications", "token-a", `{"tenant_id":"` + v2TenantA + `"}`, http.StatusOK},
+		{"other tenant in body", http.MethodPost, "/v1/notifications", "token-a", `{"tenant_id":"` + v2TenantB + `"}`, http.StatusForbidden},
+		{"other tenant in path", http.MethodGet, "/v1/tenants/" + v2TenantB + "/usage", "token-a", "", http.StatusForbidden},
+		{"own tenant in path", http.MethodPut, "/v1/tenants/" + v2TenantA + "/budget", "token-a", `{}`, http.StatusOK},
+		{"no tenant named", http.MethodGet, "/v1/notifications/" + uuid.NewString(), "token-a", "", http.StatusOK},
+		{"read key reads", http.MethodGet, "/v1/notifications?tenant_id=" + v2TenantA, readPlain, "", http.StatusOK},
+		{"read key writes", http.MethodPost, "/v1/notifications", readPlain, `{"tenant_id":"` + v2TenantA + `"}`, http.StatusForbidden},
+		{"read-only token writes", http.MethodPost, "/v1/notifications", "support-a", `{"tenant_id":"` + v2TenantA + `"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = ""
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set(headerContentType, contentTypeJSON)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			mw.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && gotBody != tt.body {
+				t.Errorf("expected the handler to read the body %q, got %q", tt.body, gotBody)
+			}
+		})
+	}
+}
+
+func TestV1AuthMiddleware_AllowlistAndMasking(t *testing.T) {
+	keys := newMockAPIKeyRepo()
+	readKey := &db.APIKey{TenantID: uuid.MustParse(v2TenantA), Name: "support", Scopes: []string{ScopeRead}}
+	readPlain, _ := IssueAPIKey(readKey)
+	_ = keys.CreateAPIKey(context.Background(), readKey)
+
+	repo := NewMockRepository()
+	id := uuid.New()
+	repo.notifications[id.String()] = &db.Notification{
+		ID:       id,
+		TenantID: uuid.MustParse(v2TenantA),
+		Channel:  db.ChannelEmail,
+		Status:   db.StatusSent,
+		Payload:  json.RawMessage(`{"to":"user@example.com","body":"123456"}`),
+	}
+	handler := NewHandler(zap.NewNop(), repo)
+	allowlists := NewIPAllowlistHandler(zap.NewNop(), &mockAllowlistRepo{saved: map[uuid.UUID]*db.TenantIPAllowlist{
+		uuid.MustParse(v2TenantA): {TenantID: uuid.MustParse(v2TenantA), CIDRs: []string{"203.0.113.0/24"}},
+	}})
+
+	r := chi.NewRouter()
+	r.Use(V1AuthMiddleware(map[string]string{"token-a": v2TenantA}, nil, keys, zap.NewNop()))
+	r.Use(allowlists.V1Middleware)
+	r.Get("/v1/notifications/{id}", handler.GetNotification)
+
+	tests := []struct {
+		name           string
+		token          string
+		remoteAddr     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"read key is masked", readPlain, "203.0.113.9:4000", http.StatusOK, `"payload":{"body":"***","to":"***"}`},
+		{"full token sees the payload", "token-a", "203.0.113.9:4000", http.StatusOK, `"body":"123456"`},
+		{"read key outside the allowlist", readPlain, "198.51.100.7:4000", http.StatusForbidden, `"type":"ip_not_allowed"`},
+		{"full token outside the allowlist", "token-a", "198.51.100.7:4000", http.StatusForbidden, `"type":"ip_not_allowed"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/notifications/"+id.String(), nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.expectedStatus || !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("expected %d with %s, got %d: %s", tt.expectedStatus, tt.expectedBody, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
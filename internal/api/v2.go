@@ -0,0 +1,609 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/emailcheck"
+	"github.com/lalithlochan/nimbus/internal/observ"
+	"github.com/lalithlochan/nimbus/internal/redis"
+)
+
+// ErrorCode is a stable, machine-readable v2 error code. Clients should
+// branch on the code, never on the message text.
+type ErrorCode string
+
+const (
+	ErrCodeUnauthorized     ErrorCode = "unauthorized"
+	ErrCodeMalformedJSON    ErrorCode = "malformed_json"
+	ErrCodeMissingField     ErrorCode = "missing_field"
+	ErrCodeInvalidField     ErrorCode = "invalid_field"
+	ErrCodeTenantInBody     ErrorCode = "tenant_in_body"
+	ErrCodeNotFound         ErrorCode = "not_found"
+	ErrCodeDuplicateRequest ErrorCode = "duplicate_request"
+	ErrCodeAlreadyProcessed ErrorCode = "already_processed"
+	ErrCodeInternal         ErrorCode = "internal_error"
+	ErrCodeForbidden        ErrorCode = "forbidden"
+	ErrCodeIPNotAllowed     ErrorCode = "ip_not_allowed"
+	ErrCodeOverloaded       ErrorCode = "overloaded"
+)
+
+// Envelope is the shape of every /v2 response body. Exactly one of Data or
+// Errors is set; Meta is always present.
+type Envelope struct {
+	Data   any        `json:"data,omitempty"`
+	Meta   Meta       `json:"meta"`
+	Errors []APIError `json:"errors,omitempty"`
+}
+
+// Meta carries request-level information alongside the payload.
+type Meta struct {
+	Pagination    *Pagination         `json:"pagination,omitempty"`
+	RequestID     string              `json:"request_id,omitempty"`
+	CorrelationID string              `json:"correlation_id,omitempty"`
+	Replayed      bool                `json:"idempotent_replay,omitempty"`
+	SMS           *SMSEstimate        `json:"sms,omitempty"`
+	Email         *emailcheck.Verdict `json:"email,omitempty"`
+}
+
+// Pagination describes the page returned by a v2 list endpoint. Total is
+// only counted when the request asks for include_total=true.
+type Pagination struct {
+	Total   *int64 `json:"total,omitempty"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+	Count   int    `json:"count"`
+	HasMore bool   `json:"has_more"`
+}
+
+// APIError is one entry in Envelope.Errors. Field names the offending request
+// field for validation errors.
+type APIError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Field   string    `json:"field,omitempty"`
+}
+
+// V2NotificationRequest is the POST /v2/notifications body. There is no
+// tenant_id: the tenant comes from the API token. TenantID is only decoded so
+// we can reject it with a clear error instead of a generic unknown-field one.
+type V2NotificationRequest struct {
+	TenantID *string         `json:"tenant_id,omitempty"`
+	UserID   string          `json:"user_id"`
+	Channel  string          `json:"channel"`
+	Payload  json.RawMessage `json:"payload"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+	// SLASeconds is an optional delivery deadline, in seconds from now.
+	SLASeconds *int `json:"sla_seconds,omitempty"`
+	// GroupKey collapses related notifications; see NotificationRequest.
+	GroupKey string `json:"group_key,omitempty"`
+	// Category is one of the tenant's categories; see NotificationRequest.
+	Category string `json:"category,omitempty"`
+	// SendAt schedules the notification; see NotificationRequest.
+	SendAt *time.Time `json:"send_at,omitempty"`
+}
+
+// V2Handler serves the /v2 routes. It shares repository, idempotency and SQS
+// wiring with the v1 Handler so both versions create notifications the same
+// way; only the request/response contract differs.
+type V2Handler struct {
+	h        *Handler
+	maskMode MaskMode
+}
+
+// NewV2Handler wraps an existing v1 Handler's dependencies.
+func NewV2Handler(h *Handler) *V2Handler {
+	return &V2Handler{h: h, maskMode: MaskModeMask}
+}
+
+// CreateNotification handles POST /v2/notifications.
+func (v *V2Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, ok := TenantIDFromContext(ctx)
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	correlationID, ok := correlationIDFromRequest(r)
+	if !ok {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: errDetailInvalidCorrID,
+			Field:   observ.CorrelationIDHeader,
+		})
+		return
+	}
+
+	var req V2NotificationRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeMalformedJSON, Message: err.Error()})
+		return
+	}
+
+	if errs := validateV2Request(req); len(errs) > 0 {
+		writeV2Error(w, r, http.StatusBadRequest, errs...)
+		return
+	}
+	userID, _ := uuid.Parse(req.UserID)
+	tags, _ := normalizeTags(req.Tags)
+
+	payload, err := v.h.normalizeSMSRecipient(ctx, tenantID, req.Channel, req.Payload)
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: err.Error(),
+			Field:   "payload.phone_number",
+		})
+		return
+	}
+	req.Payload = payload
+
+	smsEstimate, err := v.h.estimateSMS(req.Channel, req.Payload)
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: err.Error(),
+			Field:   "payload.message",
+		})
+		return
+	}
+
+	emailVerdict, err := v.h.checkEmailRecipient(ctx, req.Channel, req.Payload)
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: err.Error(),
+			Field:   "payload.to",
+		})
+		return
+	}
+
+	if err := v.h.checkWebhookDestination(ctx, tenantID, req.Channel, req.Payload); err != nil {
+		if errors.Is(err, errWebhookSettingsUnavailable) {
+			writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: errTitleCreateFailed})
+			return
+		}
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: err.Error(),
+			Field:   "payload.url",
+		})
+		return
+	}
+
+	if err := v.h.checkCategory(ctx, tenantID, req.Category); err != nil {
+		if errors.Is(err, errCategoriesUnavailable) {
+			writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: errTitleCreateFailed})
+			return
+		}
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeInvalidField,
+			Message: err.Error(),
+			Field:   "category",
+		})
+		return
+	}
+
+	if status, reason, shed := v.h.shed(ctx, w, req.Channel, tags); shed {
+		writeV2Error(w, r, status, APIError{Code: ErrCodeOverloaded, Message: reason})
+		return
+	}
+
+	tenantKey := tenantID.String()
+	idempotencyKey := r.Header.Get(headerIdempotencyKey)
+	clientProvidedKey := idempotencyKey != ""
+	if !clientProvidedKey && v.h.requiresIdempotencyKey(ctx, tenantID) {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{
+			Code:    ErrCodeMissingField,
+			Message: errDetailIdempotencyKeyRequired,
+			Field:   headerIdempotencyKey,
+		})
+		return
+	}
+
+	var reservation string
+	if v.h.idempotency != nil {
+		if idempotencyKey == "" {
+			// Same content hash as v1, so a v1 and a v2 retry of the same
+			// request dedupe against each other.
+			idempotencyKey = generateContentHash(NotificationRequest{
+				TenantID: tenantKey,
+				UserID:   req.UserID,
+				Channel:  req.Channel,
+				Payload:  req.Payload,
+				Metadata: req.Metadata,
+				Tags:     req.Tags,
+				Category: req.Category,
+				SendAt:   req.SendAt,
+			})
+		}
+
+		var cachedResult *redis.IdempotencyResult
+		var err error
+		cachedResult, reservation, err = v.h.idempotency.CheckOrReserve(ctx, tenantKey, idempotencyKey)
+		if err != nil {
+			if errors.Is(err, redis.ErrDuplicateRequest) {
+				writeV2Error(w, r, http.StatusConflict, APIError{
+					Code:    ErrCodeDuplicateRequest,
+					Message: errDetailRequestInFlight,
+				})
+				return
+			}
+			observ.Logger(r.Context(), v.h.logger).Warn("idempotency check failed",
+				zap.Error(err),
+				zap.String(logFieldIdempotency, idempotencyKey),
+			)
+		} else if cachedResult != nil {
+			// Replays return the same shape as a fresh create: the stored
+			// notification, falling back to just its ID if it can't be read.
+			var data any = NotificationResponse{ID: cachedResult.NotificationID}
+			if id, err := uuid.Parse(cachedResult.NotificationID); err == nil {
+				if notif, err := v.h.repo.GetNotification(ctx, id); err == nil {
+					data = notif
+				}
+			}
+			w.Header().Set(headerReplay, replayHeaderValue)
+			meta := newMeta(r)
+			meta.Replayed = true
+			writeV2(w, cachedResult.StatusCode, Envelope{Data: data, Meta: meta})
+			return
+		}
+	}
+
+	notif := &db.Notification{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		UserID:        userID,
+		Channel:       req.Channel,
+		Payload:       req.Payload,
+		Status:        db.StatusPending,
+		Attempt:       initialAttempt,
+		CorrelationID: correlationID,
+		Metadata:      req.Metadata,
+		Tags:          tags,
+		SLASeconds:    req.SLASeconds,
+		GroupKey:      req.GroupKey,
+		Category:      req.Category,
+		NextRetryAt:   req.SendAt,
+	}
+
+	if err := v.h.persistNotification(ctx, notif, tenantKey, idempotencyKey, reservation, clientProvidedKey); err != nil {
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: errTitleCreateFailed})
+		return
+	}
+
+	meta := newMeta(r)
+	meta.CorrelationID = correlationID
+	meta.SMS = smsEstimate
+	meta.Email = emailVerdict
+	w.Header().Set(observ.CorrelationIDHeader, correlationID)
+	writeV2(w, http.StatusCreated, Envelope{Data: notif, Meta: meta})
+}
+
+// validateV2Request collects every validation problem rather than stopping
+// at the first, so clients can fix a request in one round trip.
+func validateV2Request(req V2NotificationRequest) []APIError {
+	var errs []APIError
+
+	if req.TenantID != nil {
+		errs = append(errs, APIError{
+			Code:    ErrCodeTenantInBody,
+			Message: "tenant is derived from the API token; remove tenant_id from the body",
+			Field:   "tenant_id",
+		})
+	}
+
+	if req.UserID == "" {
+		errs = append(errs, APIError{Code: ErrCodeMissingField, Message: "user_id is required", Field: "user_id"})
+	} else if _, err := uuid.Parse(req.UserID); err != nil {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: errDetailInvalidUser, Field: "user_id"})
+	}
+
+	if req.Channel == "" {
+		errs = append(errs, APIError{Code: ErrCodeMissingField, Message: "channel is required", Field: "channel"})
+	} else if !isValidChannel(req.Channel) {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: errDetailInvalidChannel, Field: "channel"})
+	}
+
+	if len(req.Payload) > 0 && !json.Valid(req.Payload) {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: errDetailInvalidPayload, Field: "payload"})
+	}
+
+	if err := validateMetadata(req.Metadata); err != nil {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: err.Error(), Field: "metadata"})
+	}
+
+	if _, err := normalizeTags(req.Tags); err != nil {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: err.Error(), Field: "tags"})
+	}
+
+	if err := validateSLA(req.SLASeconds); err != nil {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: err.Error(), Field: "sla_seconds"})
+	}
+
+	if err := validateGroupKey(req.GroupKey); err != nil {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: err.Error(), Field: "group_key"})
+	}
+
+	if err := validateCategory(req.Category); err != nil {
+		errs = append(errs, APIError{Code: ErrCodeInvalidField, Message: err.Error(), Field: "category"})
+	}
+
+	return errs
+}
+
+// GetNotification handles GET /v2/notifications/{id}. Another tenant's
+// notification is reported as not found so IDs can't be probed.
+func (v *V2Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
+	notif, ok := v.ownedNotification(w, r)
+	if !ok {
+		return
+	}
+
+	etag := notificationETag(notif)
+	w.Header().Set(headerETag, etag)
+	w.Header().Set(headerCacheControl, cacheControlRevalidate)
+	if etagMatches(r.Header.Get(headerIfNoneMatch), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeV2(w, http.StatusOK, Envelope{Data: maskNotification(r, notif, v.maskMode), Meta: newMeta(r)})
+}
+
+// ListNotifications handles GET /v2/notifications?limit=20&offset=0&tag=yyy&sort=status&order=asc&include_total=true.
+func (v *V2Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	filter := db.NotificationFilter{Tag: r.URL.Query().Get(queryParamTag)}
+	if filter.Tag != "" && !isValidTag(filter.Tag) {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: "tag must be 1-64 characters of [A-Za-z0-9-_.:]", Field: "tag"})
+		return
+	}
+	listSort, param, detail := parseListSort(r)
+	if detail != "" {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: detail, Field: param})
+		return
+	}
+	filter.Sort = listSort
+
+	limit, offset := parsePagination(r)
+	notifications, err := v.h.repo.ListNotificationsByTenant(r.Context(), tenantID, filter, limit+1, offset)
+	if err != nil {
+		observ.Logger(r.Context(), v.h.logger).Error("failed to list notifications", zap.Error(err))
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to list notifications"})
+		return
+	}
+	if notifications == nil {
+		notifications = []*db.Notification{}
+	}
+	notifications, hasMore := trimPage(notifications, limit)
+
+	meta := newMeta(r)
+	meta.Pagination = &Pagination{Limit: limit, Offset: offset, Count: len(notifications), HasMore: hasMore}
+	if includeTotal(r) {
+		total, err := v.h.repo.CountNotificationsByTenant(r.Context(), tenantID, filter)
+		if err != nil {
+			observ.Logger(r.Context(), v.h.logger).Error("failed to count notifications", zap.Error(err))
+			writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to count notifications"})
+			return
+		}
+		meta.Pagination.Total = &total
+	}
+	for i, n := range notifications {
+		notifications[i] = maskNotification(r, n, v.maskMode)
+	}
+	writeV2(w, http.StatusOK, Envelope{Data: notifications, Meta: meta})
+}
+
+// ListDeadLetterQueue handles GET /v2/dlq?reason=timeout&status=pending&limit=20&offset=0&sort=updated_at&include_total=true.
+func (v *V2Handler) ListDeadLetterQueue(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return
+	}
+
+	filter, _, detail := parseDeadLetterFilter(r)
+	if detail != "" {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: detail})
+		return
+	}
+	listSort, param, detail := parseListSort(r)
+	if detail != "" {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: detail, Field: param})
+		return
+	}
+	filter.Sort = listSort
+
+	limit, offset := parsePagination(r)
+	items, err := v.h.repo.ListDeadLetterByTenant(r.Context(), tenantID, filter, limit+1, offset)
+	if err != nil {
+		observ.Logger(r.Context(), v.h.logger).Error("failed to list dead letter queue", zap.Error(err))
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to list dead letter queue"})
+		return
+	}
+	if items == nil {
+		items = []*db.DeadLetterNotification{}
+	}
+	items, hasMore := trimPage(items, limit)
+
+	meta := newMeta(r)
+	meta.Pagination = &Pagination{Limit: limit, Offset: offset, Count: len(items), HasMore: hasMore}
+	if includeTotal(r) {
+		total, err := v.h.repo.CountDeadLetterByTenant(r.Context(), tenantID, filter)
+		if err != nil {
+			observ.Logger(r.Context(), v.h.logger).Error("failed to count dead letter queue", zap.Error(err))
+			writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to count dead letter queue"})
+			return
+		}
+		meta.Pagination.Total = &total
+	}
+	for i, item := range items {
+		items[i] = maskDeadLetter(r, item, v.maskMode)
+	}
+	writeV2(w, http.StatusOK, Envelope{Data: items, Meta: meta})
+}
+
+// GetDeadLetterItem handles GET /v2/dlq/{id}.
+func (v *V2Handler) GetDeadLetterItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := v.ownedDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	writeV2(w, http.StatusOK, Envelope{Data: maskDeadLetter(r, item, v.maskMode), Meta: newMeta(r)})
+}
+
+// RetryDeadLetterItem handles POST /v2/dlq/{id}/retry.
+func (v *V2Handler) RetryDeadLetterItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := v.ownedDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	if item.Status != db.DLQStatusPending {
+		writeV2Error(w, r, http.StatusConflict, APIError{
+			Code:    ErrCodeAlreadyProcessed,
+			Message: "dead letter item is already " + item.Status,
+		})
+		return
+	}
+
+	newNotif, err := v.h.repo.RetryDeadLetter(r.Context(), item.ID)
+	if err != nil {
+		observ.Logger(r.Context(), v.h.logger).Error("failed to retry dead letter item",
+			zap.Error(err),
+			zap.String("id", item.ID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to retry dead letter item"})
+		return
+	}
+
+	writeV2(w, http.StatusOK, Envelope{Data: newNotif, Meta: newMeta(r)})
+}
+
+// DiscardDeadLetterItem handles POST /v2/dlq/{id}/discard.
+func (v *V2Handler) DiscardDeadLetterItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := v.ownedDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	if item.Status != db.DLQStatusPending {
+		writeV2Error(w, r, http.StatusConflict, APIError{
+			Code:    ErrCodeAlreadyProcessed,
+			Message: "dead letter item is already " + item.Status,
+		})
+		return
+	}
+
+	if err := v.h.repo.DiscardDeadLetter(r.Context(), item.ID); err != nil {
+		observ.Logger(r.Context(), v.h.logger).Error("failed to discard dead letter item",
+			zap.Error(err),
+			zap.String("id", item.ID.String()),
+		)
+		writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "failed to discard dead letter item"})
+		return
+	}
+
+	item.Status = db.DLQStatusDiscarded
+	writeV2(w, http.StatusOK, Envelope{Data: item, Meta: newMeta(r)})
+}
+
+// ownedNotification loads the {id} notification with a query scoped to the
+// authenticated tenant, writing the error response itself when there is none.
+func (v *V2Handler) ownedNotification(w http.ResponseWriter, r *http.Request) (*db.Notification, bool) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: "id must be a valid UUID", Field: "id"})
+		return nil, false
+	}
+
+	notif, err := v.h.repo.GetNotificationForTenant(r.Context(), tenantID, id)
+	if err != nil {
+		v.writeLookupError(w, r, err, "notification not found")
+		return nil, false
+	}
+	return notif, true
+}
+
+// ownedDeadLetter is ownedNotification for DLQ items.
+func (v *V2Handler) ownedDeadLetter(w http.ResponseWriter, r *http.Request) (*db.DeadLetterNotification, bool) {
+	tenantID, ok := TenantIDFromContext(r.Context())
+	if !ok {
+		writeV2Error(w, r, http.StatusUnauthorized, APIError{Code: ErrCodeUnauthorized, Message: "no authenticated tenant"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeV2Error(w, r, http.StatusBadRequest, APIError{Code: ErrCodeInvalidField, Message: "id must be a valid UUID", Field: "id"})
+		return nil, false
+	}
+
+	item, err := v.h.repo.GetDeadLetterForTenant(r.Context(), tenantID, id)
+	if err != nil {
+		v.writeLookupError(w, r, err, "dead letter item not found")
+		return nil, false
+	}
+	return item, true
+}
+
+// writeLookupError is Handler.writeLookupError for v2: a missing row is a
+// 404 with notFound, any other failure a 500.
+func (v *V2Handler) writeLookupError(w http.ResponseWriter, r *http.Request, err error, notFound string) {
+	if errors.Is(err, db.ErrNotFound) {
+		writeV2Error(w, r, http.StatusNotFound, APIError{Code: ErrCodeNotFound, Message: notFound})
+		return
+	}
+	observ.Logger(r.Context(), v.h.logger).Error("lookup failed", zap.Error(err))
+	writeV2Error(w, r, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: "lookup failed"})
+}
+
+// parsePagination reads limit/offset with the same defaults and bounds as v1.
+func parsePagination(r *http.Request) (limit, offset int) {
+	limit = 20
+	if l, err := strconv.Atoi(r.URL.Query().Get(queryParamLimit)); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get(queryParamOffset)); err == nil && o >= 0 {
+		offset = o
+	}
+	return limit, offset
+}
+
+func newMeta(r *http.Request) Meta {
+	return Meta{RequestID: middleware.GetReqID(r.Context())}
+}
+
+func writeV2(w http.ResponseWriter, status int, env Envelope) {
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(env)
+}
+
+func writeV2Error(w http.ResponseWriter, r *http.Request, status int, errs ...APIError) {
+	writeV2(w, status, Envelope{Meta: newMeta(r), Errors: errs})
+}
@@ -0,0 +1,326 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	v2TenantA = "00000000-0000-0000-0000-0000000000aa"
+	v2TenantB = "00000000-0000-0000-0000-0000000000bb"
+)
+
+// newV2Router mounts the v2 handler behind the Bearer auth middleware, the
+// same way cmd/gateway does, with token-a → tenant A and token-b → tenant B.
+func newV2Router(repo *MockRepository) http.Handler {
+	v2 := NewV2Handler(NewHandler(zap.NewNop(), repo))
+	r := chi.NewRouter()
+	r.Use(BearerAuthMiddleware(map[string]string{
+		"token-a": v2TenantA,
+		"token-b": v2TenantB,
+	}, zap.NewNop()))
+	r.Post("/v2/notifications", v2.CreateNotification)
+	r.Get("/v2/notifications", v2.ListNotifications)
+	r.Get("/v2/notifications/{id}", v2.GetNotification)
+	return r
+}
+
+func doV2(t *testing.T, h http.Handler, method, path, token string, body any) (*httptest.ResponseRecorder, Envelope) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if s, ok := body.(string); ok {
+			buf.WriteString(s)
+		} else if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var env Envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("response is not a v2 envelope: %v (%s)", err, rec.Body.String())
+	}
+	return rec, env
+}
+
+func TestV2_Auth(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing token", ""},
+		{"unknown token", "nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, env := doV2(t, newV2Router(NewMockRepository()), http.MethodGet, "/v2/notifications", tt.token, nil)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+			if len(env.Errors) != 1 || env.Errors[0].Code != ErrCodeUnauthorized {
+				t.Errorf("expected a single unauthorized error, got %+v", env.Errors)
+			}
+		})
+	}
+}
+
+func TestV2_Auth_ScopesDatabaseToTenant(t *testing.T) {
+	var scoped uuid.UUID
+	h := BearerAuthMiddleware(map[string]string{"token-a": v2TenantA}, zap.NewNop())(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scoped, _ = db.TenantFromContext(r.Context())
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/notifications", nil)
+	req.Header.Set("Authorization", "Bearer token-a")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if scoped.String() != v2TenantA {
+		t.Errorf("expected database work scoped to %s, got %s", v2TenantA, scoped)
+	}
+}
+
+func TestV2_CreateNotification_TenantFromToken(t *testing.T) {
+	repo := NewMockRepository()
+	rec, env := doV2(t, newV2Router(repo), http.MethodPost, "/v2/notifications", "token-a", map[string]any{
+		"user_id": "00000000-0000-0000-0000-000000000002",
+		"channel": "email",
+		"payload": map[string]string{"to": "user@example.com"},
+	})
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(env.Errors) != 0 {
+		t.Errorf("expected no errors, got %+v", env.Errors)
+	}
+	if env.Meta.CorrelationID == "" {
+		t.Error("expected meta.correlation_id to be set")
+	}
+
+	data, _ := json.Marshal(env.Data)
+	var notif db.Notification
+	if err := json.Unmarshal(data, &notif); err != nil {
+		t.Fatalf("failed to decode data: %v", err)
+	}
+	stored := repo.notifications[notif.ID.String()]
+	if stored == nil {
+		t.Fatal("expected notification to be stored")
+	}
+	if stored.TenantID.String() != v2TenantA {
+		t.Errorf("expected tenant from token %s, got %s", v2TenantA, stored.TenantID)
+	}
+}
+
+func TestV2_CreateNotification_ValidationErrors(t *testing.T) {
+	repo := NewMockRepository()
+	rec, env := doV2(t, newV2Router(repo), http.MethodPost, "/v2/notifications", "token-a", map[string]any{
+		"tenant_id": v2TenantB,
+		"user_id":   "not-a-uuid",
+		"channel":   "telegram",
+	})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if repo.createCalled {
+		t.Error("expected nothing to be created")
+	}
+
+	// Every problem is reported at once, each tied to its field.
+	got := map[string]ErrorCode{}
+	for _, e := range env.Errors {
+		got[e.Field] = e.Code
+	}
+	want := map[string]ErrorCode{
+		"tenant_id": ErrCodeTenantInBody,
+		"user_id":   ErrCodeInvalidField,
+		"channel":   ErrCodeInvalidField,
+	}
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("field %s: expected code %q, got %q", field, code, got[field])
+		}
+	}
+}
+
+func TestV2_CreateNotification_MalformedJSON(t *testing.T) {
+	rec, env := doV2(t, newV2Router(NewMockRepository()), http.MethodPost, "/v2/notifications", "token-a", "{not json")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if len(env.Errors) != 1 || env.Errors[0].Code != ErrCodeMalformedJSON {
+		t.Errorf("expected malformed_json, got %+v", env.Errors)
+	}
+}
+
+func TestV2_GetNotification_CrossTenantIsNotFound(t *testing.T) {
+	repo := NewMockRepository()
+	id := uuid.New()
+	repo.notifications[id.String()] = &db.Notification{
+		ID:       id,
+		TenantID: uuid.MustParse(v2TenantA),
+		Channel:  db.ChannelEmail,
+		Status:   db.StatusPending,
+	}
+	router := newV2Router(repo)
+
+	rec, _ := doV2(t, router, http.MethodGet, "/v2/notifications/"+id.String(), "token-a", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("owner: expected 200, got %d", rec.Code)
+	}
+
+	rec, env := doV2(t, router, http.MethodGet, "/v2/notifications/"+id.String(), "token-b", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("other tenant: expected 404, got %d", rec.Code)
+	}
+	if len(env.Errors) != 1 || env.Errors[0].Code != ErrCodeNotFound {
+		t.Errorf("expected not_found, got %+v", env.Errors)
+	}
+}
+
+func TestV2_ListNotifications_Pagination(t *testing.T) {
+	repo := NewMockRepository()
+	for _, tenant := range []string{v2TenantA, v2TenantA, v2TenantB} {
+		id := uuid.New()
+		repo.notifications[id.String()] = &db.Notification{ID: id, TenantID: uuid.MustParse(tenant)}
+	}
+
+	rec, env := doV2(t, newV2Router(repo), http.MethodGet, "/v2/notifications?limit=10", "token-a", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if env.Meta.Pagination == nil {
+		t.Fatal("expected meta.pagination")
+	}
+	if env.Meta.Pagination.Limit != 10 || env.Meta.Pagination.Count != 2 {
+		t.Errorf("expected limit 10 count 2, got %+v", *env.Meta.Pagination)
+	}
+}
+
+func TestV2_ListNotifications_HasMoreAndTotal(t *testing.T) {
+	repo := NewMockRepository()
+	for range 3 {
+		id := uuid.New()
+		repo.notifications[id.String()] = &db.Notification{ID: id, TenantID: uuid.MustParse(v2TenantA)}
+	}
+	router := newV2Router(repo)
+
+	_, env := doV2(t, router, http.MethodGet, "/v2/notifications?limit=2", "token-a", nil)
+	if p := env.Meta.Pagination; p == nil || p.Count != 2 || !p.HasMore || p.Total != nil {
+		t.Fatalf("expected a first page of 2 with more and no total, got %+v", p)
+	}
+
+	_, env = doV2(t, router, http.MethodGet, "/v2/notifications?limit=2&offset=2&include_total=true", "token-a", nil)
+	p := env.Meta.Pagination
+	if p == nil || p.Count != 1 || p.HasMore {
+		t.Fatalf("expected a last page of 1, got %+v", p)
+	}
+	if p.Total == nil || *p.Total != 3 {
+		t.Errorf("expected total 3, got %v", p.Total)
+	}
+}
+
+func TestV2_ReadOnlyRole(t *testing.T) {
+	const payload = `{"to":"user@example.com","subject":"Your code","body":"123456"}`
+	repo := NewMockRepository()
+	id := uuid.New()
+	repo.notifications[id.String()] = &db.Notification{
+		ID:       id,
+		TenantID: uuid.MustParse(v2TenantA),
+		Channel:  db.ChannelEmail,
+		Status:   db.StatusSent,
+		Payload:  json.RawMessage(payload),
+		Metadata: json.RawMessage(`{"order_id":"42"}`),
+	}
+
+	tests := []struct {
+		name     string
+		mode     MaskMode
+		expected string
+	}{
+		{"mask", MaskModeMask, `{"body":"***","subject":"***","to":"***"}`},
+		{"omit", MaskModeOmit, `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v2 := NewV2Handler(NewHandler(zap.NewNop(), repo))
+			v2.SetMaskMode(tt.mode)
+			tokens := map[string]string{"token-a": v2TenantA, "support-a": v2TenantA}
+			r := chi.NewRouter()
+			r.Use(BearerAuthMiddleware(tokens, zap.NewNop()))
+			r.Use(RoleMiddleware(map[string]string{"support-a": string(RoleReadOnly)}))
+			r.Post("/v2/notifications", v2.CreateNotification)
+			r.Get("/v2/notifications", v2.ListNotifications)
+			r.Get("/v2/notifications/{id}", v2.GetNotification)
+
+			for _, path := range []string{"/v2/notifications/" + id.String(), "/v2/notifications"} {
+				rec := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.Header.Set("Authorization", "Bearer support-a")
+				r.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+				}
+
+				var env struct {
+					Data json.RawMessage `json:"data"`
+				}
+				_ = json.Unmarshal(rec.Body.Bytes(), &env)
+				var notif db.Notification
+				if env.Data[0] == '[' {
+					var list []db.Notification
+					_ = json.Unmarshal(env.Data, &list)
+					notif = list[0]
+				} else {
+					_ = json.Unmarshal(env.Data, &notif)
+				}
+				if string(notif.Payload) != tt.expected {
+					t.Errorf("%s: expected payload %s, got %s", path, tt.expected, notif.Payload)
+				}
+				if notif.Status != db.StatusSent || string(notif.Metadata) != `{"order_id":"42"}` {
+					t.Errorf("%s: expected status and metadata intact, got %+v", path, notif)
+				}
+			}
+
+			if got := string(repo.notifications[id.String()].Payload); got != payload {
+				t.Errorf("stored payload was modified: %s", got)
+			}
+
+			// Full-access tokens still see the payload.
+			rec, env := doV2(t, r, http.MethodGet, "/v2/notifications/"+id.String(), "token-a", nil)
+			data, _ := json.Marshal(env.Data)
+			if rec.Code != http.StatusOK || !bytes.Contains(data, []byte("123456")) {
+				t.Errorf("expected full payload for token-a, got %d %s", rec.Code, data)
+			}
+
+			rec, env = doV2(t, r, http.MethodPost, "/v2/notifications", "support-a", map[string]any{
+				"user_id": "00000000-0000-0000-0000-000000000002",
+				"channel": "email",
+				"payload": map[string]string{"to": "user@example.com"},
+			})
+			if rec.Code != http.StatusForbidden || len(env.Errors) != 1 || env.Errors[0].Code != ErrCodeForbidden {
+				t.Errorf("expected 403 forbidden for read-only create, got %d %+v", rec.Code, env.Errors)
+			}
+		})
+	}
+}
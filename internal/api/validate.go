@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/budget"
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/emailcheck"
+	"github.com/lalithlochan/nimbus/internal/worker"
+)
+
+// Checks reported by POST /v1/notifications/validate, in the order they run.
+const (
+	checkSchema      = "schema"
+	checkRecipient   = "recipient"
+	checkSMSLength   = "sms_length"
+	checkSuppression = "suppression"
+	checkQuota       = "quota"
+)
+
+// Check outcomes. A warning doesn't make the payload invalid: create would
+// accept it, but it would not be sent right away.
+const (
+	checkPass = "pass"
+	checkFail = "fail"
+	checkWarn = "warn"
+	checkSkip = "skip"
+)
+
+// PreflightRepository reads the delivery controls that decide whether an
+// accepted notification is sent right away. db.Store implements it.
+type PreflightRepository interface {
+	ListTenantPauses(ctx context.Context) ([]*db.TenantPause, error)
+	ListChannelKillSwitches(ctx context.Context) ([]*db.ChannelKillSwitch, error)
+	ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error)
+	ListBudgetUsage(ctx context.Context) ([]*db.BudgetUsage, error)
+}
+
+// ValidationCheck is the outcome of one check.
+type ValidationCheck struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ValidationResult is returned by POST /v1/notifications/validate. Valid is
+// false if any check failed, i.e. create would reject the request.
+type ValidationResult struct {
+	Valid  bool                `json:"valid"`
+	Checks []ValidationCheck   `json:"checks"`
+	SMS    *SMSEstimate        `json:"sms,omitempty"`
+	Email  *emailcheck.Verdict `json:"email,omitempty"`
+}
+
+func (v *ValidationResult) add(check, status, detail string) {
+	v.Checks = append(v.Checks, ValidationCheck{Check: check, Status: status, Detail: detail})
+	if status == checkFail {
+		v.Valid = false
+	}
+}
+
+// SetPreflight lets the validate endpoint report tenant pauses, killed
+// channels, send limits and budgets. Without it those checks are skipped.
+func (h *Handler) SetPreflight(repo PreflightRepository) {
+	h.preflight = repo
+}
+
+// ValidateNotification handles POST /v1/notifications/validate. It takes the
+// same body as create and runs the same validation, then reports whether
+// the notification would be held once accepted. Nothing is persisted and no
+// idempotency key is reserved, so integrators can pre-flight payloads
+// freely. The response is 200 whether or not the payload is valid; only a
+// body that isn't JSON at all is a 400.
+func (h *Handler) ValidateNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req NotificationRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, errTypeInvalidRequest, errTitleMalformedJSON, err.Error())
+		return
+	}
+
+	result := &ValidationResult{Valid: true}
+	tenantID, tags, ok := validateSchema(req, result)
+	if !ok {
+		h.writeValidation(w, result)
+		return
+	}
+
+	h.validateRecipient(ctx, tenantID, &req, result)
+
+	estimate, err := h.estimateSMS(req.Channel, req.Payload)
+	switch {
+	case err != nil:
+		result.add(checkSMSLength, checkFail, err.Error())
+	case estimate != nil:
+		result.SMS = estimate
+		result.add(checkSMSLength, checkPass, "")
+	}
+
+	if h.preflight == nil {
+		result.add(checkSuppression, checkSkip, "delivery controls are not configured")
+		result.add(checkQuota, checkSkip, "delivery controls are not configured")
+	} else {
+		h.validateSuppression(ctx, tenantID, req.Channel, result)
+		h.validateQuota(ctx, tenantID, tags, result)
+	}
+
+	h.writeValidation(w, result)
+}
+
+// validateSchema runs the request checks create does before it looks at the
+// recipient, plus the fields each channel's sender needs. ok is false if the
+// later checks can't run.
+func validateSchema(req NotificationRequest, result *ValidationResult) (tenantID uuid.UUID, tags []string, ok bool) {
+	fail := func(detail string) (uuid.UUID, []string, bool) {
+		result.add(checkSchema, checkFail, detail)
+		return uuid.Nil, nil, false
+	}
+
+	if req.TenantID == "" || req.UserID == "" || req.Channel == "" {
+		return fail(errDetailMissingFields)
+	}
+	if !isValidChannel(req.Channel) {
+		return fail(errDetailInvalidChannel)
+	}
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		return fail(errDetailInvalidTenant)
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		return fail(errDetailInvalidUser)
+	}
+	if len(req.Payload) > 0 && !json.Valid(req.Payload) {
+		return fail(errDetailInvalidPayload)
+	}
+	if err := validateMetadata(req.Metadata); err != nil {
+		return fail(err.Error())
+	}
+	tags, err = normalizeTags(req.Tags)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if err := validateGroupKey(req.GroupKey); err != nil {
+		return fail(err.Error())
+	}
+	if err := validateCategory(req.Category); err != nil {
+		return fail(err.Error())
+	}
+	if err := requiredPayloadFields(req.Channel, req.Payload); err != nil {
+		return fail(err.Error())
+	}
+
+	result.add(checkSchema, checkPass, "")
+	return tenantID, tags, true
+}
+
+// requiredPayloadFields checks that payload has what the channel's sender
+// needs. Create doesn't enforce this; a notification missing them fails in
+// the worker instead.
+func requiredPayloadFields(channel string, payload json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	if len(payload) == 0 || json.Unmarshal(payload, &fields) != nil {
+		return errors.New("payload must be a JSON object")
+	}
+	has := func(name string) bool {
+		var s string
+		return json.Unmarshal(fields[name], &s) == nil && s != ""
+	}
+
+	// recipient_ref addresses the send from the user's recipient record.
+	referenced := false
+	if _, ok := fields["recipient_ref"]; ok {
+		var ref string
+		if json.Unmarshal(fields["recipient_ref"], &ref) != nil || ref != db.RecipientRefUser {
+			return fmt.Errorf("recipient_ref must be %q", db.RecipientRefUser)
+		}
+		if channel != channelEmail && channel != channelSMS {
+			return fmt.Errorf("recipient_ref isn't supported for %s", channel)
+		}
+		referenced = true
+	}
+
+	var missing string
+	switch channel {
+	case channelEmail:
+		switch {
+		case !has("to") && !referenced:
+			missing = "to"
+		case has("template_id"):
+		case !has("subject"):
+			missing = "subject"
+		case !has("body") && !has("html"):
+			missing = "body"
+		}
+	case channelSMS:
+		switch {
+		case !has("phone_number") && !referenced:
+			missing = "phone_number"
+		case !has("message"):
+			missing = "message"
+		}
+	case channelWebhook:
+		if !has("url") {
+			missing = "url"
+		}
+		var templated bool
+		if json.Unmarshal(fields["templated"], &templated) == nil && templated {
+			if err := worker.CheckWebhookTemplate(fields["body"]); err != nil {
+				return fmt.Errorf("webhook body template: %w", err)
+			}
+		}
+	case channelSlack:
+		if !has("text") && len(fields["blocks"]) == 0 {
+			missing = "text or blocks"
+		}
+	}
+	if missing != "" {
+		return fmt.Errorf("%s payload requires %s", channel, missing)
+	}
+	return nil
+}
+
+// validateRecipient runs create's recipient checks for the channel. The
+// payload is rewritten with the normalized phone number, as create would,
+// so the SMS estimate that follows matches.
+func (h *Handler) validateRecipient(ctx context.Context, tenantID uuid.UUID, req *NotificationRequest, result *ValidationResult) {
+	payload, err := h.normalizeSMSRecipient(ctx, tenantID, req.Channel, req.Payload)
+	if err != nil {
+		result.add(checkRecipient, checkFail, err.Error())
+		return
+	}
+	req.Payload = payload
+
+	verdict, err := h.checkEmailRecipient(ctx, req.Channel, req.Payload)
+	if err != nil {
+		result.add(checkRecipient, checkFail, err.Error())
+		return
+	}
+	result.Email = verdict
+
+	if err := h.checkWebhookDestination(ctx, tenantID, req.Channel, req.Payload); err != nil {
+		if errors.Is(err, errWebhookSettingsUnavailable) {
+			result.add(checkRecipient, checkSkip, "webhook settings are unavailable")
+			return
+		}
+		result.add(checkRecipient, checkFail, err.Error())
+		return
+	}
+
+	// An invalid verdict the policy doesn't enforce is still worth flagging.
+	if verdict != nil && !verdict.Valid {
+		result.add(checkRecipient, checkWarn, verdict.Reason)
+		return
+	}
+	result.add(checkRecipient, checkPass, "")
+}
+
+// validateSuppression warns when the tenant or channel is paused, killed or
+// limited: create accepts the notification, but it is held until the
+// control is lifted.
+func (h *Handler) validateSuppression(ctx context.Context, tenantID uuid.UUID, channel string, result *ValidationResult) {
+	unavailable := func(what string, err error) {
+		h.logger.Warn("preflight lookup failed", zap.String("lookup", what), zap.Error(err))
+		result.add(checkSuppression, checkSkip, what+" are unavailable")
+	}
+
+	pauses, err := h.preflight.ListTenantPauses(ctx)
+	if err != nil {
+		unavailable("tenant pauses", err)
+		return
+	}
+	for _, p := range pauses {
+		if p.TenantID == tenantID {
+			result.add(checkSuppression, checkWarn, "tenant is paused: "+p.Reason)
+			return
+		}
+	}
+
+	switches, err := h.preflight.ListChannelKillSwitches(ctx)
+	if err != nil {
+		unavailable("channel kill switches", err)
+		return
+	}
+	for _, k := range switches {
+		if k.Channel == channel {
+			result.add(checkSuppression, checkWarn, channel+" is disabled: "+k.Reason)
+			return
+		}
+	}
+
+	limits, err := h.preflight.ListTenantSendLimits(ctx)
+	if err != nil {
+		unavailable("send limits", err)
+		return
+	}
+	for _, l := range limits {
+		if l.TenantID == tenantID && l.Channel == channel {
+			result.add(checkSuppression, checkWarn, fmt.Sprintf("tenant %s is %s: %s", channel, l.State, l.Reason))
+			return
+		}
+	}
+
+	result.add(checkSuppression, checkPass, "")
+}
+
+// validateQuota warns when the tenant has used up a blocking budget, unless
+// the notification is tagged critical and so exempt.
+func (h *Handler) validateQuota(ctx context.Context, tenantID uuid.UUID, tags []string, result *ValidationResult) {
+	usage, err := h.preflight.ListBudgetUsage(ctx)
+	if err != nil {
+		h.logger.Warn("preflight lookup failed", zap.String("lookup", "budget usage"), zap.Error(err))
+		result.add(checkQuota, checkSkip, "budget usage is unavailable")
+		return
+	}
+	for _, u := range usage {
+		if u.Budget.TenantID != tenantID || !budget.Blocked(u) {
+			continue
+		}
+		if slices.Contains(tags, db.TagCritical) {
+			result.add(checkQuota, checkPass, "budget is used up; critical notifications are exempt")
+			return
+		}
+		result.add(checkQuota, checkWarn, fmt.Sprintf("budget is %.0f%% used and blocks further sends", budget.Percent(u)))
+		return
+	}
+	result.add(checkQuota, checkPass, "")
+}
+
+func (h *Handler) writeValidation(w http.ResponseWriter, result *ValidationResult) {
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
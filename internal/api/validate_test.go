@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockPreflightRepo struct {
+	pauses   []*db.TenantPause
+	switches []*db.ChannelKillSwitch
+	limits   []*db.TenantSendLimit
+	usage    []*db.BudgetUsage
+}
+
+func (m *mockPreflightRepo) ListTenantPauses(ctx context.Context) ([]*db.TenantPause, error) {
+	return m.pauses, nil
+}
+
+func (m *mockPreflightRepo) ListChannelKillSwitches(ctx context.Context) ([]*db.ChannelKillSwitch, error) {
+	return m.switches, nil
+}
+
+func (m *mockPreflightRepo) ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error) {
+	return m.limits, nil
+}
+
+func (m *mockPreflightRepo) ListBudgetUsage(ctx context.Context) ([]*db.BudgetUsage, error) {
+	return m.usage, nil
+}
+
+func validate(t *testing.T, h *Handler, body string) ValidationResult {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/notifications/validate", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ValidateNotification(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result ValidationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return result
+}
+
+func checkStatus(result ValidationResult, check string) string {
+	for _, c := range result.Checks {
+		if c.Check == check {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func TestValidateNotification(t *testing.T) {
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	volume := int64(100)
+
+	tests := []struct {
+		preflight *mockPreflightRepo
+		name      string
+		body      string
+		valid     bool
+		want      map[string]string
+	}{
+		{
+			name:  "valid email",
+			body:  `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"email","payload":{"to":"a@example.com","subject":"Hi","body":"Hello"}}`,
+			valid: true,
+			want:  map[string]string{checkSchema: checkPass, checkRecipient: checkPass, checkSuppression: checkSkip, checkQuota: checkSkip},
+		},
+		{
+			name:  "email template without subject",
+			body:  `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"email","payload":{"to":"a@example.com","template_id":"welcome"}}`,
+			valid: true,
+			want:  map[string]string{checkSchema: checkPass},
+		},
+		{
+			name: "missing subject",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"email","payload":{"to":"a@example.com"}}`,
+			want: map[string]string{checkSchema: checkFail, checkRecipient: ""},
+		},
+		{
+			name:  "email addressed by recipient_ref",
+			body:  `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"email","payload":{"recipient_ref":"user","subject":"Hi","body":"Hello"}}`,
+			valid: true,
+			want:  map[string]string{checkSchema: checkPass},
+		},
+		{
+			name: "unknown recipient_ref",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"sms","payload":{"recipient_ref":"account","message":"hi"}}`,
+			want: map[string]string{checkSchema: checkFail},
+		},
+		{
+			name: "webhook body template that doesn't parse",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"webhook","payload":{"url":"https://example.com/hook","templated":true,"body":{"text":"{{.data.x"}}}`,
+			want: map[string]string{checkSchema: checkFail},
+		},
+		{
+			name:  "slack blocks",
+			body:  `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"slack","payload":{"channel":"#alerts","blocks":[{"type":"divider"}]}}`,
+			valid: true,
+			want:  map[string]string{checkSchema: checkPass},
+		},
+		{
+			name: "slack without text or blocks",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"slack","payload":{"channel":"#alerts"}}`,
+			want: map[string]string{checkSchema: checkFail},
+		},
+		{
+			name: "invalid channel",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"fax","payload":{}}`,
+			want: map[string]string{checkSchema: checkFail},
+		},
+		{
+			name:  "sms",
+			body:  `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"sms","payload":{"phone_number":"+1 415 555 0100","message":"Your code is 1234"}}`,
+			valid: true,
+			want:  map[string]string{checkRecipient: checkPass, checkSMSLength: checkPass},
+		},
+		{
+			name: "bad phone number",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"sms","payload":{"phone_number":"12","message":"hi"}}`,
+			want: map[string]string{checkRecipient: checkFail},
+		},
+		{
+			name:      "paused tenant",
+			body:      `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"webhook","payload":{"url":"https://example.com/hook"}}`,
+			preflight: &mockPreflightRepo{pauses: []*db.TenantPause{{TenantID: tenantID, Reason: "review"}}},
+			valid:     true,
+			want:      map[string]string{checkSuppression: checkWarn, checkQuota: checkPass},
+		},
+		{
+			name:      "killed channel",
+			body:      `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"webhook","payload":{"url":"https://example.com/hook"}}`,
+			preflight: &mockPreflightRepo{switches: []*db.ChannelKillSwitch{{Channel: "webhook", Reason: "incident"}}},
+			valid:     true,
+			want:      map[string]string{checkSuppression: checkWarn},
+		},
+		{
+			name: "budget used up",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"webhook","payload":{"url":"https://example.com/hook"}}`,
+			preflight: &mockPreflightRepo{usage: []*db.BudgetUsage{{
+				Budget: &db.TenantBudget{TenantID: tenantID, MonthlyVolume: &volume, BlockAtLimit: true},
+				Sent:   100,
+			}}},
+			valid: true,
+			want:  map[string]string{checkSuppression: checkPass, checkQuota: checkWarn},
+		},
+		{
+			name: "budget used up but critical",
+			body: `{"tenant_id":"00000000-0000-0000-0000-000000000001","user_id":"00000000-0000-0000-0000-000000000002","channel":"webhook","payload":{"url":"https://example.com/hook"},"tags":["critical"]}`,
+			preflight: &mockPreflightRepo{usage: []*db.BudgetUsage{{
+				Budget: &db.TenantBudget{TenantID: tenantID, MonthlyVolume: &volume, BlockAtLimit: true},
+				Sent:   100,
+			}}},
+			valid: true,
+			want:  map[string]string{checkQuota: checkPass},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockRepository()
+			h := NewHandler(zap.NewNop(), repo)
+			if tt.preflight != nil {
+				h.SetPreflight(tt.preflight)
+			}
+
+			result := validate(t, h, tt.body)
+			if result.Valid != tt.valid {
+				t.Errorf("expected valid=%v, got %+v", tt.valid, result)
+			}
+			for check, status := range tt.want {
+				if got := checkStatus(result, check); got != status {
+					t.Errorf("%s: expected %q, got %q (%+v)", check, status, got, result.Checks)
+				}
+			}
+			if repo.createCalled {
+				t.Error("validate must not persist the notification")
+			}
+		})
+	}
+}
+
+func TestValidateNotification_MalformedJSON(t *testing.T) {
+	h := NewHandler(zap.NewNop(), NewMockRepository())
+	req := httptest.NewRequest(http.MethodPost, "/v1/notifications/validate", strings.NewReader(`{`))
+	rec := httptest.NewRecorder()
+	h.ValidateNotification(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// errWebhookSettingsUnavailable means the tenant's allowlist couldn't be
+// read. Create fails closed rather than letting an unchecked URL through.
+var errWebhookSettingsUnavailable = errors.New("webhook settings unavailable")
+
+// checkWebhookDestination rejects a webhook payload whose url targets a host
+// outside the tenant's allowed_domains. Tenants without an allowlist, other
+// channels and payloads without a url are not checked.
+func (h *Handler) checkWebhookDestination(ctx context.Context, tenantID uuid.UUID, channel string, payload json.RawMessage) error {
+	if h.settings == nil || channel != channelWebhook || len(payload) == 0 {
+		return nil
+	}
+
+	var p struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.URL == "" {
+		return nil
+	}
+
+	record, err := h.settings.GetChannelSettings(ctx, tenantID, channelWebhook)
+	if err != nil {
+		h.logger.Error("failed to load webhook settings",
+			zap.Error(err),
+			zap.String(logFieldTenantID, tenantID.String()),
+		)
+		return errWebhookSettingsUnavailable
+	}
+	var settings db.WebhookSettings
+	_ = json.Unmarshal(record.Settings, &settings)
+	if len(settings.AllowedDomains) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if !hostAllowed(host, settings.AllowedDomains) {
+		return fmt.Errorf("url host %q is not in the tenant's allowed webhook domains", host)
+	}
+	return nil
+}
+
+// hostAllowed matches host against allowlist entries. "*.acme.com" matches
+// subdomains of acme.com but not acme.com itself.
+func hostAllowed(host string, allowed []string) bool {
+	for _, entry := range allowed {
+		if suffix, ok := strings.CutPrefix(entry, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestCreateNotification_WebhookAllowlist(t *testing.T) {
+	const tenant = "00000000-0000-0000-0000-000000000001"
+
+	tests := []struct {
+		name           string
+		allowed        string
+		url            string
+		expectedStatus int
+	}{
+		{"no allowlist", "", "https://anywhere.example.net/hook", http.StatusCreated},
+		{"exact host", `["hooks.acme.com"]`, "https://hooks.acme.com/notify", http.StatusCreated},
+		{"exact host with port", `["hooks.acme.com"]`, "https://HOOKS.acme.com:8443/notify", http.StatusCreated},
+		{"wildcard subdomain", `["*.acme.com"]`, "https://eu.hooks.acme.com/notify", http.StatusCreated},
+		{"wildcard excludes apex", `["*.acme.com"]`, "https://acme.com/notify", http.StatusBadRequest},
+		{"suffix lookalike", `["hooks.acme.com"]`, "https://hooks.acme.com.evil.io/notify", http.StatusBadRequest},
+		{"other host", `["hooks.acme.com"]`, "https://attacker.example/collect", http.StatusBadRequest},
+		{"userinfo trick", `["hooks.acme.com"]`, "https://hooks.acme.com@attacker.example/", http.StatusBadRequest},
+		{"not http", `["hooks.acme.com"]`, "ftp://hooks.acme.com/notify", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &mockSettingsRepo{saved: map[string]*db.TenantChannelSettings{}}
+			if tt.allowed != "" {
+				settings.saved[tenant+"/webhook"] = &db.TenantChannelSettings{
+					TenantID: uuid.MustParse(tenant),
+					Channel:  "webhook",
+					Settings: json.RawMessage(`{"allowed_domains":` + tt.allowed + `}`),
+				}
+			}
+			mockRepo := NewMockRepository()
+			handler := NewHandler(zap.NewNop(), mockRepo)
+			handler.SetChannelSettings(settings)
+
+			payload, _ := json.Marshal(map[string]string{"url": tt.url})
+			body, _ := json.Marshal(NotificationRequest{
+				TenantID: tenant,
+				UserID:   "00000000-0000-0000-0000-000000000002",
+				Channel:  "webhook",
+				Payload:  payload,
+			})
+			rec := httptest.NewRecorder()
+			handler.CreateNotification(rec, httptest.NewRequest(http.MethodPost, "/v1/notifications", bytes.NewReader(body)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated && mockRepo.createCalled {
+				t.Error("expected no notification to be created")
+			}
+		})
+	}
+}
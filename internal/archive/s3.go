@@ -0,0 +1,152 @@
+// Package archive stores the fully rendered message of every delivered
+// notification (final email HTML, webhook body, SMS text) in S3, so
+// compliance audits can see exactly what was sent after retention has
+// deleted the notification row.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// Config configures the S3 archive.
+type Config struct {
+	Region string
+	Bucket string
+	// Prefix is prepended to every key, e.g. "deliveries/". Bucket
+	// lifecycle rules (see terraform/s3.tf) filter on it.
+	Prefix string
+}
+
+// Record is the archived object. Payload is the notification payload as
+// the provider received it, after templates, enrichment and link
+// shortening were applied.
+type Record struct {
+	NotificationID    string          `json:"notification_id"`
+	TenantID          string          `json:"tenant_id"`
+	UserID            string          `json:"user_id"`
+	Channel           string          `json:"channel"`
+	CorrelationID     string          `json:"correlation_id,omitempty"`
+	Provider          string          `json:"provider,omitempty"`
+	ProviderMessageID string          `json:"provider_message_id,omitempty"`
+	Attempt           int             `json:"attempt"`
+	SentAt            time.Time       `json:"sent_at"`
+	Payload           json.RawMessage `json:"payload"`
+}
+
+// S3 writes archive records to a bucket. It signs PutObject requests with
+// the SDK's SigV4 signer rather than pulling in the full S3 client.
+type S3 struct {
+	cfg         Config
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// NewS3 creates an archive for cfg.Bucket using the default AWS
+// credential chain.
+func NewS3(ctx context.Context, cfg Config) (*S3, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, cfg.Region)
+	return newS3(cfg, endpoint, awsCfg.Credentials), nil
+}
+
+func newS3(cfg Config, endpoint string, credentials aws.CredentialsProvider) *S3 {
+	return &S3{
+		cfg:         cfg,
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the object key for notif sent at sentAt:
+// <prefix><tenant_id>/<yyyy>/<mm>/<dd>/<notification_id>.json. Grouping by
+// tenant lets a tenant's archive be exported or erased on its own.
+func (s *S3) Key(notif *db.Notification, sentAt time.Time) string {
+	return fmt.Sprintf("%s%s/%s/%s.json", s.cfg.Prefix, notif.TenantID, sentAt.UTC().Format("2006/01/02"), notif.ID)
+}
+
+// Archive writes notif's rendered payload to S3 and returns its key.
+func (s *S3) Archive(ctx context.Context, notif *db.Notification) (string, error) {
+	sentAt := time.Now()
+	// Without HTML escaping the archived payload stays byte-for-byte what
+	// was sent, rather than having <, > and & rewritten as \u escapes.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(Record{
+		NotificationID:    notif.ID.String(),
+		TenantID:          notif.TenantID.String(),
+		UserID:            notif.UserID.String(),
+		Channel:           notif.Channel,
+		CorrelationID:     notif.CorrelationID,
+		Provider:          notif.Provider,
+		ProviderMessageID: notif.ProviderMessageID,
+		Attempt:           notif.Attempt + 1,
+		SentAt:            sentAt.UTC(),
+		Payload:           notif.Payload,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal archive record: %w", err)
+	}
+	body := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	key := s.Key(notif, sentAt)
+	if err := s.put(ctx, key, body); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *S3) put(ctx context.Context, key string, body []byte) error {
+	u := s.endpoint + "/" + (&url.URL{Path: key}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build archive request: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Server-Side-Encryption", "AES256")
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieve aws credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.cfg.Region, time.Now()); err != nil {
+		return fmt.Errorf("sign archive request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("put archive object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("put archive object: status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
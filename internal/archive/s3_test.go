@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+var testCreds = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+})
+
+func TestKey(t *testing.T) {
+	s := newS3(Config{Prefix: "deliveries/"}, "https://bucket", testCreds)
+	notif := &db.Notification{ID: uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"), TenantID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")}
+	sentAt := time.Date(2026, 3, 9, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	want := "deliveries/550e8400-e29b-41d4-a716-446655440000/2026/03/10/7c9e6679-7425-40de-944b-e07fc1f90ae7.json"
+	if got := s.Key(notif, sentAt); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestArchive_PutsRenderedPayload(t *testing.T) {
+	var (
+		gotPath string
+		gotBody []byte
+		gotReq  *http.Request
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotReq = r.URL.Path, r
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	s := newS3(Config{Region: "us-east-1", Bucket: "b", Prefix: "deliveries/"}, srv.URL, testCreds)
+	notif := &db.Notification{
+		ID:                uuid.New(),
+		TenantID:          uuid.New(),
+		Channel:           db.ChannelEmail,
+		Payload:           json.RawMessage(`{"to":"a@example.com","html":"<p>Hi Ada</p>"}`),
+		Provider:          "ses",
+		ProviderMessageID: "0100-abc",
+	}
+
+	key, err := s.Archive(context.Background(), notif)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if gotPath != "/"+key || !strings.HasPrefix(key, "deliveries/"+notif.TenantID.String()+"/") {
+		t.Errorf("unexpected key %q put at %q", key, gotPath)
+	}
+	if !strings.HasPrefix(gotReq.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") || gotReq.Header.Get("X-Amz-Server-Side-Encryption") != "AES256" {
+		t.Errorf("expected a signed, encrypted put, got headers %v", gotReq.Header)
+	}
+
+	var rec Record
+	if err := json.Unmarshal(gotBody, &rec); err != nil {
+		t.Fatalf("decode record: %v", err)
+	}
+	if string(rec.Payload) != string(notif.Payload) || rec.ProviderMessageID != "0100-abc" || rec.Attempt != 1 {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestArchive_ReportsS3Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	s := newS3(Config{Region: "us-east-1", Bucket: "b"}, srv.URL, testCreds)
+	if _, err := s.Archive(context.Background(), &db.Notification{ID: uuid.New()}); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("expected the S3 error, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package backpressure sheds low-priority notification creates while the
+// delivery pipeline is backed up, e.g. during a provider outage, so the
+// backlog stops growing and recovers sooner. Notifications carrying an
+// exempt tag (critical by default) are always accepted.
+package backpressure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// refreshInterval is how stale the guard's view of the backlog may get.
+const refreshInterval = 10 * time.Second
+
+// DefaultRetryAfter is what we tell refused clients when no hint is
+// configured.
+const DefaultRetryAfter = 30 * time.Second
+
+// BacklogSource counts the platform's backlog.
+type BacklogSource interface {
+	GetBacklog(ctx context.Context) (*db.Backlog, error)
+}
+
+// Thresholds decide when creates are shed. Each is the backlog size above
+// which shedding starts; zero disables it.
+type Thresholds struct {
+	Due         int64
+	DeadLetters int64
+}
+
+// Exceeded describes the first threshold b is over, or returns "" if none.
+func (t Thresholds) Exceeded(b *db.Backlog) string {
+	switch {
+	case t.Due > 0 && b.Due > t.Due:
+		return fmt.Sprintf("%d notifications due to send, over the limit of %d", b.Due, t.Due)
+	case t.DeadLetters > 0 && b.DeadLetters > t.DeadLetters:
+		return fmt.Sprintf("%d unresolved dead letters, over the limit of %d", b.DeadLetters, t.DeadLetters)
+	default:
+		return ""
+	}
+}
+
+// Guard decides whether a create should be refused. The backlog is counted
+// per process, at most once per refreshInterval, on the request that finds
+// it stale.
+type Guard struct {
+	source     BacklogSource
+	thresholds Thresholds
+	exempt     []string
+	retryAfter time.Duration
+	logger     *zap.Logger
+
+	mu       sync.Mutex
+	reason   string
+	loadedAt time.Time
+}
+
+// NewGuard creates a guard. Notifications tagged with any of exemptTags are
+// never shed.
+func NewGuard(source BacklogSource, thresholds Thresholds, exemptTags []string, retryAfter time.Duration, logger *zap.Logger) *Guard {
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	return &Guard{
+		source:     source,
+		thresholds: thresholds,
+		exempt:     exemptTags,
+		retryAfter: retryAfter,
+		logger:     logger,
+	}
+}
+
+// Shed reports whether a notification with tags should be refused, and
+// why.
+func (g *Guard) Shed(ctx context.Context, tags []string) (reason string, shed bool) {
+	for _, tag := range tags {
+		if slices.Contains(g.exempt, tag) {
+			return "", false
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now := time.Now(); now.Sub(g.loadedAt) >= refreshInterval {
+		g.refresh(ctx, now)
+	}
+	return g.reason, g.reason != ""
+}
+
+// RetryAfter is the hint sent to refused clients in the Retry-After header.
+func (g *Guard) RetryAfter() time.Duration {
+	return g.retryAfter
+}
+
+// refresh recounts the backlog. On error shedding stops: refusing traffic
+// needs evidence of a backlog, and a failing count isn't that. Callers hold
+// g.mu.
+func (g *Guard) refresh(ctx context.Context, now time.Time) {
+	g.loadedAt = now
+
+	backlog, err := g.source.GetBacklog(ctx)
+	if err != nil {
+		g.logger.Warn("failed to count backlog, not shedding", zap.Error(err))
+		g.reason = ""
+		return
+	}
+
+	reason := g.thresholds.Exceeded(backlog)
+	switch {
+	case reason != "" && g.reason == "":
+		g.logger.Warn("backlog over threshold, shedding low-priority creates", zap.String("reason", reason))
+	case reason == "" && g.reason != "":
+		g.logger.Info("backlog recovered, accepting all creates")
+	}
+	g.reason = reason
+}
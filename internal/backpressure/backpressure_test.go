@@ -0,0 +1,77 @@
+package backpressure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestThresholds_Exceeded(t *testing.T) {
+	thresholds := Thresholds{Due: 1000, DeadLetters: 50}
+	tests := []struct {
+		name    string
+		backlog db.Backlog
+		want    bool
+	}{
+		{"healthy", db.Backlog{Due: 1000, DeadLetters: 50}, false},
+		{"due over", db.Backlog{Due: 1001}, true},
+		{"dead letters over", db.Backlog{DeadLetters: 51}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.Exceeded(&tt.backlog) != ""; got != tt.want {
+				t.Errorf("expected exceeded %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if (Thresholds{}).Exceeded(&db.Backlog{Due: 1e9, DeadLetters: 1e9}) != "" {
+		t.Error("expected zero thresholds to never shed")
+	}
+}
+
+type fakeSource struct {
+	backlog *db.Backlog
+	err     error
+	calls   int
+}
+
+func (f *fakeSource) GetBacklog(ctx context.Context) (*db.Backlog, error) {
+	f.calls++
+	return f.backlog, f.err
+}
+
+func TestGuard_Shed(t *testing.T) {
+	source := &fakeSource{backlog: &db.Backlog{Due: 5000}}
+	g := NewGuard(source, Thresholds{Due: 1000}, []string{db.TagCritical}, 0, zap.NewNop())
+
+	if _, shed := g.Shed(context.Background(), []string{"billing"}); !shed {
+		t.Error("expected an untagged create to be shed over the threshold")
+	}
+	if _, shed := g.Shed(context.Background(), []string{"billing", db.TagCritical}); shed {
+		t.Error("expected a critical create to be accepted")
+	}
+	if _, shed := g.Shed(context.Background(), nil); !shed {
+		t.Error("expected the cached backlog to keep shedding")
+	}
+	if source.calls != 1 {
+		t.Errorf("expected one backlog count within the refresh interval, got %d", source.calls)
+	}
+	if g.RetryAfter() != DefaultRetryAfter {
+		t.Errorf("expected the default retry-after, got %s", g.RetryAfter())
+	}
+}
+
+func TestGuard_ShedFailsOpen(t *testing.T) {
+	source := &fakeSource{err: errors.New("database error")}
+	g := NewGuard(source, Thresholds{Due: 1}, nil, 0, zap.NewNop())
+
+	if _, shed := g.Shed(context.Background(), nil); shed {
+		t.Error("expected creates accepted when the backlog can't be counted")
+	}
+}
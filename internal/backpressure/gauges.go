@@ -0,0 +1,81 @@
+package backpressure
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Gauges exports the backlog as Prometheus gauges for autoscaling the
+// worker deployment: the due count, how long the oldest due notification
+// has waited, and the due count per channel.
+//
+// Every replica samples, so each exports current values; scale on the max
+// across replicas rather than the sum.
+type Gauges struct {
+	source   BacklogSource
+	interval time.Duration
+	logger   *zap.Logger
+
+	channels map[string]bool // channels exported so far
+}
+
+// NewGauges creates a sampler that counts the backlog every interval.
+func NewGauges(source BacklogSource, interval time.Duration, logger *zap.Logger) *Gauges {
+	return &Gauges{
+		source:   source,
+		interval: interval,
+		logger:   logger,
+		channels: map[string]bool{},
+	}
+}
+
+// Run samples immediately, then every interval until ctx is done.
+func (g *Gauges) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		g.sample(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample counts the backlog and sets the gauges. On error the previous
+// values stay, so a blip doesn't read as an empty queue and scale down.
+func (g *Gauges) sample(ctx context.Context, now time.Time) {
+	backlog, err := g.source.GetBacklog(ctx)
+	if err != nil {
+		g.logger.Warn("failed to count backlog for queue gauges", zap.Error(err))
+		return
+	}
+	g.export(backlog, now)
+}
+
+func (g *Gauges) export(b *db.Backlog, now time.Time) {
+	var age time.Duration
+	if b.OldestDueAt != nil && now.After(*b.OldestDueAt) {
+		age = now.Sub(*b.OldestDueAt)
+	}
+	metrics.SetQueueBacklog(b.Due, age)
+
+	// A channel that drained drops out of the count; zero it rather than
+	// leave its last depth behind.
+	for channel := range g.channels {
+		if _, ok := b.Channels[channel]; !ok {
+			metrics.SetQueueDepth(channel, 0)
+		}
+	}
+	for channel, due := range b.Channels {
+		g.channels[channel] = true
+		metrics.SetQueueDepth(channel, due)
+	}
+}
@@ -0,0 +1,78 @@
+package backpressure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// gaugeSink records the last value of each gauge.
+type gaugeSink struct {
+	mu     sync.Mutex
+	gauges map[string]float64
+}
+
+func (s *gaugeSink) IncCounter(name string, labels metrics.Labels)             {}
+func (s *gaugeSink) Observe(name string, value float64, labels metrics.Labels) {}
+
+func (s *gaugeSink) SetGauge(name string, value float64, labels metrics.Labels) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gauges[name+labels["channel"]] = value
+}
+
+func (s *gaugeSink) get(key string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.gauges[key]
+	return v, ok
+}
+
+func TestGauges_Sample(t *testing.T) {
+	sink := &gaugeSink{gauges: map[string]float64{}}
+	metrics.SetSinks(sink)
+	defer metrics.SetSinks(metrics.Prometheus())
+
+	now := time.Now()
+	backlog := &db.Backlog{Channels: map[string]int64{}}
+	backlog.AddDue("email", 30, now.Add(-time.Minute))
+	backlog.AddDue("sms", 5, now.Add(-10*time.Second))
+	source := &fakeSource{backlog: backlog}
+	g := NewGauges(source, time.Minute, zap.NewNop())
+
+	g.sample(context.Background(), now)
+	for key, want := range map[string]float64{
+		"nimbus_queue_backlog":                35,
+		"nimbus_queue_oldest_due_age_seconds": 60,
+		"nimbus_queue_depthemail":             30,
+		"nimbus_queue_depthsms":               5,
+	} {
+		if got, _ := sink.get(key); got != want {
+			t.Errorf("expected %s = %v, got %v", key, want, got)
+		}
+	}
+
+	source.backlog = &db.Backlog{Channels: map[string]int64{}}
+	g.sample(context.Background(), now)
+	if got, ok := sink.get("nimbus_queue_depthemail"); !ok || got != 0 {
+		t.Errorf("expected a drained channel zeroed, got %v", got)
+	}
+	if got, _ := sink.get("nimbus_queue_oldest_due_age_seconds"); got != 0 {
+		t.Errorf("expected no age with nothing due, got %v", got)
+	}
+
+	source.err = errors.New("database error")
+	source.backlog = nil
+	sink.gauges["nimbus_queue_backlog"] = 7
+	g.sample(context.Background(), now)
+	if got, _ := sink.get("nimbus_queue_backlog"); got != 7 {
+		t.Errorf("expected the last value kept on error, got %v", got)
+	}
+}
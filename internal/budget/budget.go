@@ -0,0 +1,136 @@
+// Package budget enforces tenants' monthly spend and volume budgets. A
+// periodic check alerts a tenant as its usage crosses 80% and 100% of its
+// budget, by enqueuing an email through nimbus itself; Hold keeps the
+// worker from sending a blocked tenant's non-critical notifications until
+// the month rolls over.
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Thresholds are the percentages of a budget that trigger an alert, in
+// ascending order.
+var Thresholds = []int{80, 100}
+
+// Store reads budget usage and records alerts.
+type Store interface {
+	ListBudgetUsage(ctx context.Context) ([]*db.BudgetUsage, error)
+	RecordBudgetAlert(ctx context.Context, tenantID uuid.UUID, percent int, alert *db.Notification) (bool, error)
+}
+
+// Percent returns how much of its budget the tenant has used, by whichever
+// of spend and volume is further along.
+func Percent(u *db.BudgetUsage) float64 {
+	var pct float64
+	if c := u.Budget.MonthlyCost; c != nil && *c > 0 {
+		pct = u.Cost / *c * 100
+	}
+	if v := u.Budget.MonthlyVolume; v != nil && *v > 0 {
+		pct = max(pct, float64(u.Sent)/float64(*v)*100)
+	}
+	return pct
+}
+
+// Blocked reports whether the tenant's non-critical sends must be held.
+func Blocked(u *db.BudgetUsage) bool {
+	return u.Budget.BlockAtLimit && Percent(u) >= 100
+}
+
+// Check returns a job body that alerts each tenant whose usage has crossed
+// a threshold it hasn't been alerted on this month. Only the highest
+// threshold crossed is alerted, so a tenant that jumps straight past 100%
+// gets one email.
+func Check(store Store, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		usage, err := store.ListBudgetUsage(ctx)
+		if err != nil {
+			return fmt.Errorf("list budget usage: %w", err)
+		}
+
+		for _, u := range usage {
+			pct := Percent(u)
+			threshold := 0
+			for _, t := range Thresholds {
+				if pct >= float64(t) {
+					threshold = t
+				}
+			}
+			if threshold <= u.Budget.AlertedPercent {
+				continue
+			}
+
+			alert, err := alertNotification(u, threshold, pct)
+			if err != nil {
+				return err
+			}
+			sent, err := store.RecordBudgetAlert(ctx, u.Budget.TenantID, threshold, alert)
+			if err != nil {
+				return fmt.Errorf("record budget alert: %w", err)
+			}
+			if !sent {
+				continue
+			}
+
+			metrics.RecordBudgetAlert(threshold)
+			logger.Info("tenant budget alert enqueued",
+				zap.String("tenant_id", u.Budget.TenantID.String()),
+				zap.Int("threshold", threshold),
+				zap.Float64("percent", pct),
+				zap.String("notification_id", alert.ID.String()),
+			)
+		}
+		return nil
+	}
+}
+
+// alertNotification builds the alert email. It belongs to the tenant and is
+// tagged critical, so a block it announces doesn't hold it back.
+func alertNotification(u *db.BudgetUsage, threshold int, pct float64) (*db.Notification, error) {
+	b := u.Budget
+	month := time.Now().UTC().Format("January 2006")
+
+	body := fmt.Sprintf("Your nimbus usage for %s has reached %.0f%% of your monthly budget.\n\n", month, pct)
+	if b.MonthlyCost != nil {
+		body += fmt.Sprintf("Spend: $%.2f of $%.2f\n", u.Cost, *b.MonthlyCost)
+	}
+	if b.MonthlyVolume != nil {
+		body += fmt.Sprintf("Notifications: %d of %d\n", u.Sent, *b.MonthlyVolume)
+	}
+	if threshold >= 100 && b.BlockAtLimit {
+		body += "\nNotifications not tagged \"critical\" are held until next month or until the budget is raised.\n"
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"to":      b.AlertEmail,
+		"subject": fmt.Sprintf("nimbus budget alert: %d%% of your %s budget used", threshold, month),
+		"body":    body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal budget alert: %w", err)
+	}
+
+	return &db.Notification{
+		ID:       uuid.New(),
+		TenantID: b.TenantID,
+		Channel:  db.ChannelEmail,
+		Payload:  payload,
+		Status:   db.StatusPending,
+		Tags:     []string{db.TagCritical, "budget-alert"},
+	}, nil
+}
+
+// critical reports whether notif keeps sending over budget.
+func critical(notif *db.Notification) bool {
+	return slices.Contains(notif.Tags, db.TagCritical)
+}
@@ -0,0 +1,117 @@
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage db.BudgetUsage
+		want  float64
+	}{
+		{"cost only", db.BudgetUsage{Budget: &db.TenantBudget{MonthlyCost: ptr(10.0)}, Cost: 8}, 80},
+		{"volume only", db.BudgetUsage{Budget: &db.TenantBudget{MonthlyVolume: ptr(int64(1000))}, Sent: 500}, 50},
+		{"further along wins", db.BudgetUsage{
+			Budget: &db.TenantBudget{MonthlyCost: ptr(10.0), MonthlyVolume: ptr(int64(1000))},
+			Cost:   2, Sent: 1100,
+		}, 110},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percent(&tt.usage); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+type fakeStore struct {
+	usage  []*db.BudgetUsage
+	alerts map[uuid.UUID]*db.Notification
+}
+
+func (f *fakeStore) ListBudgetUsage(ctx context.Context) ([]*db.BudgetUsage, error) {
+	return f.usage, nil
+}
+
+func (f *fakeStore) RecordBudgetAlert(ctx context.Context, tenantID uuid.UUID, percent int, alert *db.Notification) (bool, error) {
+	for _, u := range f.usage {
+		if u.Budget.TenantID == tenantID {
+			u.Budget.AlertedPercent = percent
+		}
+	}
+	f.alerts[tenantID] = alert
+	return true, nil
+}
+
+func TestCheck(t *testing.T) {
+	under, near, over, alerted := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store := &fakeStore{
+		usage: []*db.BudgetUsage{
+			{Budget: &db.TenantBudget{TenantID: under, MonthlyCost: ptr(10.0), AlertEmail: "a@example.com"}, Cost: 5},
+			{Budget: &db.TenantBudget{TenantID: near, MonthlyCost: ptr(10.0), AlertEmail: "b@example.com"}, Cost: 8.5},
+			{Budget: &db.TenantBudget{TenantID: over, MonthlyVolume: ptr(int64(100)), AlertEmail: "c@example.com", BlockAtLimit: true}, Sent: 150},
+			{Budget: &db.TenantBudget{TenantID: alerted, MonthlyCost: ptr(10.0), AlertEmail: "d@example.com", AlertedPercent: 80}, Cost: 9},
+		},
+		alerts: make(map[uuid.UUID]*db.Notification),
+	}
+	check := Check(store, zap.NewNop())
+
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.alerts) != 2 || store.alerts[near] == nil || store.alerts[over] == nil {
+		t.Fatalf("expected alerts for the tenants past a new threshold, got %v", store.alerts)
+	}
+
+	var payload struct{ To, Subject, Body string }
+	alert := store.alerts[over]
+	if err := json.Unmarshal(alert.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.To != "c@example.com" || !strings.Contains(payload.Subject, "100%") || !strings.Contains(payload.Body, "held") {
+		t.Errorf("unexpected alert %+v", payload)
+	}
+	if alert.TenantID != over || !critical(alert) {
+		t.Errorf("expected a critical alert for the tenant, got %+v", alert)
+	}
+
+	store.alerts = make(map[uuid.UUID]*db.Notification)
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.alerts) != 0 {
+		t.Errorf("expected no repeat alerts, got %v", store.alerts)
+	}
+}
+
+func TestHold_Delay(t *testing.T) {
+	blocked, warned := uuid.New(), uuid.New()
+	hold := NewHold(&fakeStore{usage: []*db.BudgetUsage{
+		{Budget: &db.TenantBudget{TenantID: blocked, MonthlyCost: ptr(10.0), BlockAtLimit: true}, Cost: 10},
+		{Budget: &db.TenantBudget{TenantID: warned, MonthlyCost: ptr(10.0)}, Cost: 20},
+	}}, zap.NewNop())
+	ctx := context.Background()
+
+	if d := hold.Delay(ctx, &db.Notification{TenantID: blocked}); d <= 0 || d > time.Hour {
+		t.Errorf("expected a blocked tenant held for up to an hour, got %s", d)
+	}
+	if d := hold.Delay(ctx, &db.Notification{TenantID: blocked, Tags: []string{db.TagCritical}}); d != 0 {
+		t.Errorf("expected critical notifications sent, got %s", d)
+	}
+	if d := hold.Delay(ctx, &db.Notification{TenantID: warned}); d != 0 {
+		t.Errorf("expected a budget without blocking to only alert, got %s", d)
+	}
+}
@@ -0,0 +1,87 @@
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// refreshInterval is how stale the hold's view of blocked tenants may get.
+const refreshInterval = 30 * time.Second
+
+// UsageSource lists budgets with their usage this month.
+type UsageSource interface {
+	ListBudgetUsage(ctx context.Context) ([]*db.BudgetUsage, error)
+}
+
+// Hold keeps a tenant that hit 100% of a blocking budget from sending
+// anything but critical notifications. Usage is re-read every 30 seconds,
+// so a tenant can overshoot its budget by what it sends in that time.
+type Hold struct {
+	source UsageSource
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	blocked  map[uuid.UUID]bool
+	loadedAt time.Time
+}
+
+// NewHold creates a budget hold.
+func NewHold(source UsageSource, logger *zap.Logger) *Hold {
+	return &Hold{
+		source:  source,
+		logger:  logger,
+		blocked: make(map[uuid.UUID]bool),
+	}
+}
+
+// Delay reports how long notif must wait before it may be sent: zero
+// unless its tenant is blocked and it isn't critical. It fits
+// worker.Config.Throttle.
+func (h *Hold) Delay(ctx context.Context, notif *db.Notification) time.Duration {
+	if critical(notif) {
+		return 0
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	if now.Sub(h.loadedAt) >= refreshInterval {
+		h.refresh(ctx, now)
+	}
+	blocked := h.blocked[notif.TenantID]
+	h.mu.Unlock()
+	if !blocked {
+		return 0
+	}
+
+	// Check back hourly rather than sleeping until the month ends, so
+	// raising the budget doesn't leave held sends waiting for weeks.
+	utc := now.UTC()
+	nextMonth := time.Date(utc.Year(), utc.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return min(time.Hour, nextMonth.Sub(utc))
+}
+
+// refresh reloads the blocked set. On error the previous set is kept.
+// Callers hold h.mu.
+func (h *Hold) refresh(ctx context.Context, now time.Time) {
+	h.loadedAt = now
+
+	usage, err := h.source.ListBudgetUsage(ctx)
+	if err != nil {
+		h.logger.Warn("failed to refresh budgets, keeping previous", zap.Error(err))
+		return
+	}
+
+	blocked := make(map[uuid.UUID]bool)
+	for _, u := range usage {
+		if Blocked(u) {
+			blocked[u.Budget.TenantID] = true
+		}
+	}
+	h.blocked = blocked
+}
@@ -7,13 +7,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/pkg/sender"
 )
 
-// Sender mirrors the worker.Sender interface to avoid circular imports.
-type Sender interface {
-	Send(ctx context.Context, notif *db.Notification) error
-	SupportsChannel(channel string) bool
-}
+// Sender is the sender contract, shared with the worker through pkg/sender
+// to avoid circular imports.
+type Sender = sender.Sender
 
 // ProtectedSender wraps any Sender with a CircuitBreaker.
 // When the downstream service (SES, SNS, webhook endpoint) starts failing,
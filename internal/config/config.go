@@ -1,9 +1,32 @@
 package config
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+)
+
+// Email validation modes (EMAIL_VALIDATION_MODE).
+const (
+	EmailValidationOff     = "off"
+	EmailValidationWarn    = "warn"
+	EmailValidationEnforce = "enforce"
+)
+
+// Worker modes (WORKER_MODE).
+const (
+	WorkerModePoll = "poll"
+	WorkerModeSQS  = "sqs"
+)
+
+// Storage backends (DB_DRIVER).
+const (
+	DBDriverPostgres = "postgres"
+	DBDriverMySQL    = "mysql"
+	DBDriverSQLite   = "sqlite"
 )
 
 type Config struct {
@@ -12,24 +35,50 @@ type Config struct {
 	Env      string
 
 	// Database
+	DBDriver   string
 	DBHost     string
 	DBPort     int
 	DBUser     string
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+	DBPath     string // SQLite database file; ":memory:" for a throwaway one
+
+	// DBSlowQueryMS logs Postgres statements taking at least this many
+	// milliseconds, with their parameters redacted. 0 disables the log.
+	DBSlowQueryMS int // Default: 500
 
 	// Redis config
 	RedisHost     string
 	RedisPort     int
 	RedisPassword string
 	RedisDB       int
+	// ConfigCacheTTL is how many seconds tenant configuration stays cached
+	// in Redis; writes invalidate it sooner. Zero reads it from the
+	// database every time.
+	ConfigCacheTTL int
 
 	// SQS config
 	SQSRegion   string
 	SQSQueueURL string
 	SQSDLQURL   string
 
+	// SNS fan-out mode: when SNSFanoutTopicARN is set the gateway publishes
+	// to that topic instead of SQSQueueURL, and each channel in
+	// ChannelQueueURLs gets a dedicated worker consuming its queue, which
+	// is subscribed to the topic with a filter on the channel attribute.
+	SNSFanoutTopicARN string
+	ChannelQueueURLs  map[string]string
+
+	// WorkerMode is how the worker finds notifications to send.
+	// WorkerModePoll polls the database every 5 seconds. WorkerModeSQS
+	// long-polls SQSQueueURL, or the ChannelQueueURLs in fan-out mode, and
+	// polls the database only every WorkerSweepInterval seconds for what
+	// the queues don't announce: retries, deferred and scheduled sends, and
+	// messages that were lost.
+	WorkerMode          string
+	WorkerSweepInterval int
+
 	// SMTP config for email sending
 	SMTPHost     string
 	SMTPPort     int
@@ -42,14 +91,209 @@ type Config struct {
 	SESFromEmail string
 	SNSRegion    string // AWS region for SNS (SMS)
 
+	// SESConfigurationSet is attached to every SES send whose tenant has
+	// no configuration set of its own, so its event destination reports
+	// deliveries, opens, clicks and rendering failures.
+	SESConfigurationSet string
+
+	// EventBridgeBusName, when set, turns on publishing notification
+	// lifecycle events to that bus in EventBridgeRegion.
+	EventBridgeBusName string
+	EventBridgeRegion  string
+
+	// ArchiveS3Bucket, when set, turns on archiving the rendered message of
+	// every delivered notification under ArchiveS3Prefix in that bucket.
+	ArchiveS3Bucket string
+	ArchiveS3Prefix string
+	ArchiveS3Region string
+
+	// ImportS3Bucket, when set, lets POST /v1/imports read recipient files
+	// from that bucket by key, for lists too big to upload.
+	ImportS3Bucket string
+	ImportS3Region string
+
+	// DLQExportS3Bucket, when set, enables POST /v1/dlq/export, which
+	// writes DLQ items under DLQExportS3Prefix in that bucket.
+	DLQExportS3Bucket string
+	DLQExportS3Prefix string
+	DLQExportS3Region string
+
+	// ClickHouseURL, when set, turns on exporting lifecycle and provider
+	// delivery events to ClickHouse for analytics.
+	ClickHouseURL      string
+	ClickHouseDatabase string
+	ClickHouseUser     string
+	ClickHousePassword string
+
 	// Webhook config
 	WebhookTimeout int // Timeout for webhook requests in seconds
+	// Base64 AES-256 key sealing tenant webhook header values; unset
+	// disables tenant header sets
+	WebhookHeadersKey string
+
+	// Slack config. The slack channel is enabled when either is set: an
+	// incoming webhook posts to its own fixed channel, and a bot token posts
+	// to the channel named in each payload.
+	SlackWebhookURL string
+	SlackBotToken   string
 
 	// AI / OpenAI config
 	AIEnabled    bool   // Enable AI features (compose endpoint + content enrichment)
 	OpenAIAPIKey string // OpenAI API key
 	OpenAIModel  string // Model to use (default: gpt-4o-mini)
 
+	// MJML compilation for email templates. Publishing a template is refused
+	// until an API is configured; drafts can still be created.
+	MJMLEnabled   bool   // Set when MJML_API_URL or MJML_APP_ID is provided
+	MJMLAPIURL    string // MJML render API base URL (default: https://api.mjml.io)
+	MJMLAppID     string // MJML API application ID
+	MJMLSecretKey string // MJML API secret key
+
+	// SMS link shortening: long URLs in SMS bodies become <base>/r/{code}.
+	// Disabled unless SHORT_LINK_BASE_URL is set.
+	ShortLinkBaseURL string            // Public base URL of the gateway, e.g. https://nim.bz
+	ShortLinkDomains map[string]string // tenant_id → custom link domain
+
+	// SMS sizing: messages over SMSMaxSegments are rejected at create time,
+	// and the create response carries segments × SMSCostPerSegment.
+	SMSMaxSegments    int     // 0 disables the cap
+	SMSCostPerSegment float64 // 0 omits the cost estimate
+
+	// Delivery cost attribution: each successful send is priced and summed
+	// per tenant and day. SNS SMS is priced with SMSCostPerSegment.
+	SESCostPerEmail    float64 // Price of one SES email (default: 0.0001)
+	WebhookCostPerCall float64 // Price of one webhook delivery (default: 0)
+
+	// Email recipient validation at create time. In "warn" mode the verdict
+	// is returned but bad recipients are accepted; "enforce" rejects them.
+	EmailValidationMode    string   // off, warn or enforce (default: warn)
+	EmailMXLookup          bool     // Resolve the recipient domain's MX records
+	EmailDisposableDomains []string // Added to the built-in disposable blocklist
+
+	// Rate limiting
+	RateLimitPerTenant int            // Requests per minute per tenant across all /v1 routes
+	RateLimitGlobalRPS int            // Service-wide requests per second; 0 disables the ceiling
+	RateLimitBurst     int            // Extra requests per minute a tenant may burst to; overridable per tenant
+	RateLimitRoutes    map[string]int // Stricter per-tenant requests per minute for specific paths
+
+	// RateLimitFallbackPercent is the share of each limit a gateway process
+	// enforces on its own while Redis is down; 0 lets everything through.
+	RateLimitFallbackPercent int // Default: 25
+
+	// Internal traffic that skips the tenant and route limits: source
+	// CIDRs or addresses, and bearer tokens held by internal services.
+	RateLimitExemptCIDRs  []string
+	RateLimitExemptTokens []string
+
+	// Load balancers and proxies whose X-Forwarded-For is believed; without
+	// any the client address is the connection's peer.
+	TrustedProxyCIDRs []string
+
+	// Worker drain: how long shutdown waits for in-flight sends, in seconds
+	WorkerDrainTimeout int
+
+	// Stuck-processing reaper: notifications in 'processing' for longer than
+	// this many seconds are treated as orphaned by a crashed worker.
+	WorkerStuckTimeout int
+
+	// Batch tuning: the most notifications a worker poll may claim. Above
+	// the fixed batch size of 10 the worker grows and shrinks its claim
+	// with batch latency and failures; 0 keeps it fixed.
+	WorkerMaxBatchSize int
+
+	// Notification groups: a notification with a group_key created within
+	// this many seconds of a delivery in its group is collapsed into it
+	// instead of being sent. 0 turns collapsing off.
+	NotificationGroupWindow int
+
+	// Background cleanup jobs, in days. 0 (the default) keeps rows forever.
+	NotificationRetentionDays int // Delete sent and dead-lettered notifications after this
+	DLQPurgeAfterDays         int // Delete retried and discarded DLQ entries after this
+
+	// Email reputation guard: a tenant whose hard-bounce or complaint rate
+	// over the last 24h crosses a threshold is throttled, then paused, on
+	// email. Rates are fractions (0.05 = 5%). Tenants that sent fewer than
+	// ReputationMinSent emails in the window are not judged.
+	ReputationGuardEnabled      bool    // Default: true
+	ReputationMinSent           int     // Default: 200
+	ReputationBounceThrottle    float64 // Default: 0.05
+	ReputationBouncePause       float64 // Default: 0.10
+	ReputationComplaintThrottle float64 // Default: 0.001
+	ReputationComplaintPause    float64 // Default: 0.005
+	ReputationThrottlePerMinute int     // Emails per minute for a throttled tenant, per replica (default: 10)
+
+	// Provider canary: CanaryPercent of CanaryChannel's sends (email or sms)
+	// go to that channel's provider in CanaryRegion, and it is rolled back
+	// if its failure rate exceeds the baseline's by CanaryMaxFailureDelta.
+	// Empty CanaryChannel disables it.
+	CanaryChannel         string
+	CanaryPercent         float64 // Default: 5
+	CanaryRegion          string
+	CanaryMaxFailureDelta float64 // Default: 0.05
+
+	// Shadow sending: ShadowPercent of emails are mirrored to SES in
+	// ShadowSESRegion, addressed to the mailbox simulator, to compare its
+	// latency and acceptance with the primary. Empty region disables it.
+	ShadowSESRegion string
+	ShadowPercent   float64 // Default: 1
+
+	// Email warm-up: a new tenant may send at most EmailWarmupSchedule[d]
+	// emails on day d of sending (UTC days, counted from its first email),
+	// and is uncapped once the schedule runs out. Empty disables warm-up.
+	EmailWarmupSchedule []int
+
+	// Shared secret for POST /v1/providers/ses/events, passed as ?token= in
+	// the SNS subscription URL. The endpoint is only mounted when set.
+	DeliveryEventsToken string
+
+	// Maintenance mode: writes return 503 and the worker stops dispatching.
+	MaintenanceMode       bool // Start with maintenance mode already enabled
+	MaintenanceRetryAfter int  // Retry-After hint in seconds for refused writes
+
+	// Backpressure: while more notifications are due than BackpressureDue,
+	// or more dead letters are unresolved than BackpressureDeadLetters,
+	// creates without an exempt tag are refused with BackpressureStatus
+	// (429 or 503). Zero disables a threshold.
+	BackpressureDue         int
+	BackpressureDeadLetters int
+	BackpressureStatus      int
+	BackpressureRetryAfter  int // Retry-After hint in seconds
+	BackpressureExemptTags  []string
+
+	// QueueMetricsInterval is how often, in seconds, each replica counts
+	// the backlog for the nimbus_queue_* autoscaling gauges. Zero disables.
+	QueueMetricsInterval int
+
+	// SenderPlugins enables registered sender plugins by name, each with its
+	// JSON settings, e.g. {"slack":{"token":"..."}}.
+	SenderPlugins map[string]json.RawMessage
+
+	// PluginEndpoints maps a plugin name to the address of an external
+	// service implementing the sender.v1.SenderPlugin gRPC contract.
+	// PluginTLS dials them over TLS instead of plaintext.
+	PluginEndpoints map[string]string
+	PluginTLS       bool
+
+	// Fault injection for staging: with ChaosEnabled, provider sends fail
+	// ChaosSendErrorPercent of the time, ChaosSendLatencyPercent of them
+	// are delayed by ChaosSendLatency milliseconds, and Redis commands fail
+	// ChaosRedisErrorPercent of the time. Refused when Env is production.
+	ChaosEnabled            bool
+	ChaosSendErrorPercent   float64
+	ChaosSendLatency        int // milliseconds
+	ChaosSendLatencyPercent float64
+	ChaosRedisErrorPercent  float64
+
+	// Sandbox mode: the worker captures messages into the captured_deliveries
+	// table instead of sending them, and GET /v1/test/deliveries is exposed so
+	// integration tests can assert on what would have been delivered.
+	SandboxMode bool
+
+	// Demo mode: the gateway runs with nothing else installed. Storage is an
+	// in-memory SQLite database, the queue is in-process, Redis is skipped
+	// and email and SMS are only logged. Everything is lost on exit.
+	DemoMode bool
+
 	// gRPC server
 	// We run gRPC on a separate port from HTTP because:
 	// 1. HTTP/2 binary framing vs HTTP/1.1 text — mixing on one port adds complexity
@@ -61,6 +305,76 @@ type Config struct {
 	// In production these would be JWT secrets or fetched from a secrets manager.
 	// For dev/testing, set GRPC_AUTH_TOKENS="token1:tenant-uuid-1,token2:tenant-uuid-2"
 	GRPCAuthTokens map[string]string
+
+	// REST auth tokens for /v2: maps Bearer token → tenant_id, same format as
+	// GRPC_AUTH_TOKENS. /v1 only uses them with V1AuthRequired.
+	APIAuthTokens map[string]string
+
+	// V1AuthRequired makes /v1 take the same tokens and API keys as /v2 and
+	// refuse requests whose tenant_id isn't the caller's. Off by default so
+	// existing /v1 clients keep working until they have keys.
+	V1AuthRequired bool
+
+	// Optional role per /v2 token, set as a third field in API_AUTH_TOKENS
+	// ("token:tenant:readonly"). Read-only tokens can't write and see
+	// payloads per APIPayloadMasking: "mask" (default) or "omit".
+	APITokenRoles     map[string]string
+	APIPayloadMasking string
+
+	// Operator tokens for /v1/admin: maps Bearer token → operator name, set
+	// as ADMIN_AUTH_TOKENS="token:operator" with an optional third field for
+	// the role ("token:operator:support"). They are separate from tenant
+	// tokens; with none set the admin API refuses every request.
+	AdminAuthTokens map[string]string
+	AdminTokenRoles map[string]string
+
+	// Tenant label policy for per-tenant Prometheus series. "raw" keeps the
+	// tenant ID, "bucket" hashes it into MetricsTenantBuckets buckets, and
+	// "allowlist" keeps only MetricsTenantAllowlist and folds the rest into
+	// "other".
+	MetricsTenantLabels    string   // raw, bucket or allowlist (default: raw)
+	MetricsTenantBuckets   int      // Default: 64
+	MetricsTenantAllowlist []string // Tenant IDs that keep their own series
+
+	// Metrics backends: "prometheus" serves /metrics, "dogstatsd" pushes to
+	// a Datadog agent. Both can be enabled at once.
+	MetricsBackends []string // Default: prometheus
+	DogStatsDAddr   string   // Default: 127.0.0.1:8125
+	DogStatsDPrefix string   // Default: "nimbus."
+	DogStatsDTags   []string // Constant tags, e.g. env:prod
+
+	// Access log: paths never logged, and the fraction of 2xx responses
+	// logged. Non-2xx responses are always logged.
+	AccessLogSkipPaths  []string // Default: /health,/readyz,/metrics
+	AccessLogSampleRate float64  // 0 to 1 (default: 1)
+
+	// CORS for browser-based dashboards. No allowed origins disables it.
+	CORSAllowedOrigins []string // Exact origins, or "*"
+	CORSAllowedMethods []string // Default: GET,POST,PUT,PATCH,DELETE
+	CORSAllowedHeaders []string // Default: Authorization,Content-Type,Idempotency-Key,X-Tenant-ID,X-Correlation-ID,If-Match,If-None-Match
+	CORSMaxAge         int      // Preflight cache seconds (default: 600)
+
+	// Native TLS, for deployments without a load balancer in front. With a
+	// certificate file or autocert domains set, Port serves HTTPS (HTTP/2
+	// included) and TLSHTTPPort, if set, redirects plain HTTP to it.
+	TLSCertFile         string
+	TLSKeyFile          string
+	TLSAutocertDomains  []string // Let's Encrypt host names
+	TLSAutocertCacheDir string   // Default: autocert-cache
+	TLSAutocertEmail    string
+	TLSMinVersion       string // 1.2 or 1.3 (default: 1.2)
+	TLSHTTPPort         int    // Plain-HTTP redirect listener; 0 disables it
+	HSTSMaxAge          int    // Strict-Transport-Security seconds; 0 disables it (default: 31536000)
+}
+
+// MetricsBackendEnabled reports whether name is listed in METRICS_BACKENDS.
+func (c *Config) MetricsBackendEnabled(name string) bool {
+	for _, b := range c.MetricsBackends {
+		if b == name {
+			return true
+		}
+	}
+	return false
 }
 
 // Load reads configuration from environment variables with sensible defaults
@@ -71,12 +385,16 @@ func Load() (*Config, error) {
 		Env:      "development",
 
 		// Local postgres defaults
+		DBDriver:   DBDriverPostgres,
 		DBHost:     "localhost",
 		DBPort:     5432,
 		DBUser:     "lalithlochan",
 		DBPassword: "",
 		DBName:     "nimbus",
 		DBSSLMode:  "disable",
+		DBPath:     "nimbus.db",
+
+		DBSlowQueryMS: 500,
 
 		// Redis defaults
 		RedisHost:     "localhost",
@@ -89,10 +407,69 @@ func Load() (*Config, error) {
 		SMTPPort: 587,
 		SMTPFrom: "noreply@nimbus.local",
 
-		AWSRegion:      "us-east-1",
-		SESFromEmail:   "noreply@nimbus.local",
-		GRPCPort:       9090,
-		GRPCAuthTokens: map[string]string{},
+		AWSRegion:       "us-east-1",
+		SESFromEmail:    "noreply@nimbus.local",
+		GRPCPort:        9090,
+		GRPCAuthTokens:  map[string]string{},
+		APIAuthTokens:   map[string]string{},
+		APITokenRoles:   map[string]string{},
+		AdminAuthTokens: map[string]string{},
+		AdminTokenRoles: map[string]string{},
+
+		APIPayloadMasking: "mask",
+
+		MetricsTenantLabels:  "raw",
+		MetricsTenantBuckets: 64,
+
+		MetricsBackends: []string{"prometheus"},
+		DogStatsDAddr:   "127.0.0.1:8125",
+		DogStatsDPrefix: "nimbus.",
+
+		AccessLogSkipPaths:  []string{"/health", "/readyz", "/metrics"},
+		AccessLogSampleRate: 1,
+
+		CORSAllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		CORSAllowedHeaders: []string{
+			"Authorization", "Content-Type", "Idempotency-Key", "X-Tenant-ID",
+			"X-Correlation-ID", "If-Match", "If-None-Match",
+		},
+		CORSMaxAge: 600,
+
+		TLSAutocertCacheDir: "autocert-cache",
+		TLSMinVersion:       "1.2",
+		HSTSMaxAge:          31536000,
+
+		ShortLinkDomains: map[string]string{},
+
+		// Ten segments is ~1,500 GSM-7 characters; beyond that a message is
+		// almost certainly a bug, and some carriers drop it anyway.
+		SMSMaxSegments:  10,
+		SESCostPerEmail: 0.0001,
+
+		ArchiveS3Prefix:   "deliveries/",
+		DLQExportS3Prefix: "dlq-exports/",
+
+		EmailValidationMode: EmailValidationWarn,
+
+		ReputationGuardEnabled:      true,
+		CanaryPercent:               5,
+		CanaryMaxFailureDelta:       0.05,
+		ShadowPercent:               1,
+		ReputationMinSent:           200,
+		ReputationBounceThrottle:    0.05,
+		ReputationBouncePause:       0.10,
+		ReputationComplaintThrottle: 0.001,
+		ReputationComplaintPause:    0.005,
+		ReputationThrottlePerMinute: 10,
+
+		// AI routes call out to OpenAI on every request, so they get a much
+		// tighter budget than plain notification CRUD.
+		RateLimitPerTenant:       100,
+		RateLimitFallbackPercent: 25,
+		RateLimitRoutes: map[string]int{
+			"/v1/ai/compose": 10,
+			"/v1/ai/ask":     20,
+		},
 	}
 
 	if port := os.Getenv("PORT"); port != "" {
@@ -112,6 +489,15 @@ func Load() (*Config, error) {
 	}
 
 	// Database config
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		switch driver {
+		case DBDriverPostgres, DBDriverMySQL, DBDriverSQLite:
+			cfg.DBDriver = driver
+		default:
+			return nil, fmt.Errorf("invalid DB_DRIVER: %q (want postgres, mysql or sqlite)", driver)
+		}
+	}
+
 	if host := os.Getenv("DB_HOST"); host != "" {
 		cfg.DBHost = host
 	}
@@ -122,6 +508,8 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("invalid DB_PORT: %w", err)
 		}
 		cfg.DBPort = p
+	} else if cfg.DBDriver == DBDriverMySQL {
+		cfg.DBPort = 3306
 	}
 
 	if user := os.Getenv("DB_USER"); user != "" {
@@ -140,6 +528,18 @@ func Load() (*Config, error) {
 		cfg.DBSSLMode = sslmode
 	}
 
+	if path := os.Getenv("DB_PATH"); path != "" {
+		cfg.DBPath = path
+	}
+
+	if raw := os.Getenv("DB_SLOW_QUERY_MS"); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid DB_SLOW_QUERY_MS: %q (must be a non-negative number of milliseconds)", raw)
+		}
+		cfg.DBSlowQueryMS = ms
+	}
+
 	// Redis config
 	if host := os.Getenv("REDIS_HOST"); host != "" {
 		cfg.RedisHost = host
@@ -165,6 +565,16 @@ func Load() (*Config, error) {
 		cfg.RedisDB = d
 	}
 
+	if ttl := os.Getenv("CONFIG_CACHE_TTL"); ttl != "" {
+		t, err := strconv.Atoi(ttl)
+		if err != nil || t < 0 {
+			return nil, fmt.Errorf("invalid CONFIG_CACHE_TTL: %q", ttl)
+		}
+		cfg.ConfigCacheTTL = t
+	} else {
+		cfg.ConfigCacheTTL = 60 // default 1 minute
+	}
+
 	if host := os.Getenv("SMTP_HOST"); host != "" {
 		cfg.SMTPHost = host
 	}
@@ -196,6 +606,7 @@ func Load() (*Config, error) {
 	if from := os.Getenv("SES_FROM_EMAIL"); from != "" {
 		cfg.SESFromEmail = from
 	}
+	cfg.SESConfigurationSet = os.Getenv("SES_CONFIGURATION_SET")
 
 	// SQS config
 	if region := os.Getenv("SQS_REGION"); region != "" {
@@ -212,6 +623,21 @@ func Load() (*Config, error) {
 		cfg.SQSDLQURL = url
 	}
 
+	cfg.SNSFanoutTopicARN = os.Getenv("SNS_FANOUT_TOPIC_ARN")
+	for channel, env := range map[string]string{
+		"email":   "SQS_EMAIL_QUEUE_URL",
+		"sms":     "SQS_SMS_QUEUE_URL",
+		"webhook": "SQS_WEBHOOK_QUEUE_URL",
+		"slack":   "SQS_SLACK_QUEUE_URL",
+	} {
+		if url := os.Getenv(env); url != "" {
+			if cfg.ChannelQueueURLs == nil {
+				cfg.ChannelQueueURLs = make(map[string]string)
+			}
+			cfg.ChannelQueueURLs[channel] = url
+		}
+	}
+
 	// SNS config for SMS
 	if region := os.Getenv("SNS_REGION"); region != "" {
 		cfg.SNSRegion = region
@@ -219,6 +645,45 @@ func Load() (*Config, error) {
 		cfg.SNSRegion = cfg.AWSRegion
 	}
 
+	cfg.ArchiveS3Bucket = os.Getenv("ARCHIVE_S3_BUCKET")
+	if prefix, ok := os.LookupEnv("ARCHIVE_S3_PREFIX"); ok {
+		cfg.ArchiveS3Prefix = prefix
+	}
+	if region := os.Getenv("ARCHIVE_S3_REGION"); region != "" {
+		cfg.ArchiveS3Region = region
+	} else {
+		cfg.ArchiveS3Region = cfg.AWSRegion
+	}
+
+	cfg.ImportS3Bucket = os.Getenv("IMPORT_S3_BUCKET")
+	if region := os.Getenv("IMPORT_S3_REGION"); region != "" {
+		cfg.ImportS3Region = region
+	} else {
+		cfg.ImportS3Region = cfg.AWSRegion
+	}
+
+	cfg.DLQExportS3Bucket = os.Getenv("DLQ_EXPORT_S3_BUCKET")
+	if prefix, ok := os.LookupEnv("DLQ_EXPORT_S3_PREFIX"); ok {
+		cfg.DLQExportS3Prefix = prefix
+	}
+	if region := os.Getenv("DLQ_EXPORT_S3_REGION"); region != "" {
+		cfg.DLQExportS3Region = region
+	} else {
+		cfg.DLQExportS3Region = cfg.AWSRegion
+	}
+
+	cfg.EventBridgeBusName = os.Getenv("EVENTBRIDGE_BUS_NAME")
+	if region := os.Getenv("EVENTBRIDGE_REGION"); region != "" {
+		cfg.EventBridgeRegion = region
+	} else {
+		cfg.EventBridgeRegion = cfg.AWSRegion
+	}
+
+	cfg.ClickHouseURL = strings.TrimRight(os.Getenv("CLICKHOUSE_URL"), "/")
+	cfg.ClickHouseDatabase = os.Getenv("CLICKHOUSE_DATABASE")
+	cfg.ClickHouseUser = os.Getenv("CLICKHOUSE_USER")
+	cfg.ClickHousePassword = os.Getenv("CLICKHOUSE_PASSWORD")
+
 	// Webhook config
 	if timeout := os.Getenv("WEBHOOK_TIMEOUT"); timeout != "" {
 		t, err := strconv.Atoi(timeout)
@@ -229,6 +694,17 @@ func Load() (*Config, error) {
 	} else {
 		cfg.WebhookTimeout = 30 // default 30 seconds
 	}
+	if key := os.Getenv("WEBHOOK_HEADERS_KEY"); key != "" {
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("invalid WEBHOOK_HEADERS_KEY (must be 32 bytes, base64-encoded)")
+		}
+		cfg.WebhookHeadersKey = key
+	}
+
+	// Slack config
+	cfg.SlackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	cfg.SlackBotToken = os.Getenv("SLACK_BOT_TOKEN")
 
 	// AI config
 	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
@@ -241,6 +717,507 @@ func Load() (*Config, error) {
 		cfg.OpenAIModel = "gpt-4o-mini"
 	}
 
+	// MJML config
+	if url := os.Getenv("MJML_API_URL"); url != "" {
+		cfg.MJMLAPIURL = url
+		cfg.MJMLEnabled = true
+	}
+	if appID := os.Getenv("MJML_APP_ID"); appID != "" {
+		cfg.MJMLAppID = appID
+		cfg.MJMLSecretKey = os.Getenv("MJML_SECRET_KEY")
+		cfg.MJMLEnabled = true
+	}
+
+	// Short link config
+	if base := os.Getenv("SHORT_LINK_BASE_URL"); base != "" {
+		cfg.ShortLinkBaseURL = base
+	}
+
+	// Parse SHORT_LINK_DOMAINS="tenantUUID1:links.acme.com,tenantUUID2:go.example.org"
+	if raw := os.Getenv("SHORT_LINK_DOMAINS"); raw != "" {
+		for _, pair := range splitComma(raw) {
+			parts := splitColon(pair)
+			if len(parts) != 2 || parts[1] == "" {
+				return nil, fmt.Errorf("invalid SHORT_LINK_DOMAINS entry: %q", pair)
+			}
+			cfg.ShortLinkDomains[parts[0]] = parts[1]
+		}
+	}
+
+	// SMS sizing config
+	if segs := os.Getenv("SMS_MAX_SEGMENTS"); segs != "" {
+		n, err := strconv.Atoi(segs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMS_MAX_SEGMENTS: %w", err)
+		}
+		cfg.SMSMaxSegments = n
+	}
+
+	if cost := os.Getenv("SMS_COST_PER_SEGMENT"); cost != "" {
+		c, err := strconv.ParseFloat(cost, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMS_COST_PER_SEGMENT: %w", err)
+		}
+		cfg.SMSCostPerSegment = c
+	}
+
+	// Delivery cost config
+	if cost := os.Getenv("SES_COST_PER_EMAIL"); cost != "" {
+		c, err := strconv.ParseFloat(cost, 64)
+		if err != nil || c < 0 {
+			return nil, fmt.Errorf("invalid SES_COST_PER_EMAIL: %q", cost)
+		}
+		cfg.SESCostPerEmail = c
+	}
+
+	if cost := os.Getenv("WEBHOOK_COST_PER_CALL"); cost != "" {
+		c, err := strconv.ParseFloat(cost, 64)
+		if err != nil || c < 0 {
+			return nil, fmt.Errorf("invalid WEBHOOK_COST_PER_CALL: %q", cost)
+		}
+		cfg.WebhookCostPerCall = c
+	}
+
+	// Email validation config
+	if mode := os.Getenv("EMAIL_VALIDATION_MODE"); mode != "" {
+		switch mode {
+		case EmailValidationOff, EmailValidationWarn, EmailValidationEnforce:
+			cfg.EmailValidationMode = mode
+		default:
+			return nil, fmt.Errorf("invalid EMAIL_VALIDATION_MODE: %q (want off, warn or enforce)", mode)
+		}
+	}
+
+	if mx := os.Getenv("EMAIL_MX_LOOKUP"); mx != "" {
+		b, err := strconv.ParseBool(mx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EMAIL_MX_LOOKUP: %w", err)
+		}
+		cfg.EmailMXLookup = b
+	}
+
+	// Parse EMAIL_DISPOSABLE_DOMAINS="throwaway.example,tempbox.example"
+	if raw := os.Getenv("EMAIL_DISPOSABLE_DOMAINS"); raw != "" {
+		cfg.EmailDisposableDomains = splitComma(raw)
+	}
+
+	// Rate limit config
+	if limit := os.Getenv("RATE_LIMIT_PER_TENANT"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_PER_TENANT: %w", err)
+		}
+		cfg.RateLimitPerTenant = l
+	}
+
+	if burst := os.Getenv("RATE_LIMIT_BURST"); burst != "" {
+		b, err := strconv.Atoi(burst)
+		if err != nil || b < 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_BURST: %q", burst)
+		}
+		cfg.RateLimitBurst = b
+	}
+
+	if rps := os.Getenv("RATE_LIMIT_GLOBAL_RPS"); rps != "" {
+		r, err := strconv.Atoi(rps)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_GLOBAL_RPS: %w", err)
+		}
+		cfg.RateLimitGlobalRPS = r
+	}
+
+	if pct := os.Getenv("RATE_LIMIT_FALLBACK_PERCENT"); pct != "" {
+		p, err := strconv.Atoi(pct)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_FALLBACK_PERCENT: %q (must be between 0 and 100)", pct)
+		}
+		cfg.RateLimitFallbackPercent = p
+	}
+
+	// Parse RATE_LIMIT_ROUTES="/v1/ai/compose:5,/v1/notifications:60"
+	// Entries override the defaults; a limit of 0 removes the route limit.
+	if raw := os.Getenv("RATE_LIMIT_ROUTES"); raw != "" {
+		for _, pair := range splitComma(raw) {
+			parts := splitColon(pair)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES entry: %q", pair)
+			}
+			l, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES limit for %s: %w", parts[0], err)
+			}
+			if l <= 0 {
+				delete(cfg.RateLimitRoutes, parts[0])
+				continue
+			}
+			cfg.RateLimitRoutes[parts[0]] = l
+		}
+	}
+
+	for _, cidr := range splitComma(os.Getenv("RATE_LIMIT_EXEMPT_CIDRS")) {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cfg.RateLimitExemptCIDRs = append(cfg.RateLimitExemptCIDRs, cidr)
+		}
+	}
+	for _, token := range splitComma(os.Getenv("RATE_LIMIT_EXEMPT_TOKENS")) {
+		if token = strings.TrimSpace(token); token != "" {
+			cfg.RateLimitExemptTokens = append(cfg.RateLimitExemptTokens, token)
+		}
+	}
+	for _, cidr := range splitComma(os.Getenv("TRUSTED_PROXY_CIDRS")) {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cfg.TrustedProxyCIDRs = append(cfg.TrustedProxyCIDRs, cidr)
+		}
+	}
+
+	// Worker drain config
+	if drain := os.Getenv("WORKER_DRAIN_TIMEOUT"); drain != "" {
+		d, err := strconv.Atoi(drain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WORKER_DRAIN_TIMEOUT: %w", err)
+		}
+		cfg.WorkerDrainTimeout = d
+	} else {
+		cfg.WorkerDrainTimeout = 15 // default 15 seconds
+	}
+
+	if stuck := os.Getenv("WORKER_STUCK_TIMEOUT"); stuck != "" {
+		s, err := strconv.Atoi(stuck)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WORKER_STUCK_TIMEOUT: %w", err)
+		}
+		if s <= 0 {
+			return nil, fmt.Errorf("invalid WORKER_STUCK_TIMEOUT: %d (must be positive)", s)
+		}
+		cfg.WorkerStuckTimeout = s
+	} else {
+		cfg.WorkerStuckTimeout = 300 // default 5 minutes
+	}
+
+	cfg.WorkerMode = WorkerModePoll
+	if mode := os.Getenv("WORKER_MODE"); mode != "" {
+		switch mode {
+		case WorkerModePoll, WorkerModeSQS:
+			cfg.WorkerMode = mode
+		default:
+			return nil, fmt.Errorf("invalid WORKER_MODE: %q (want poll or sqs)", mode)
+		}
+	}
+	if cfg.WorkerMode == WorkerModeSQS {
+		if cfg.SNSFanoutTopicARN != "" && len(cfg.ChannelQueueURLs) == 0 {
+			return nil, fmt.Errorf("WORKER_MODE=sqs with SNS_FANOUT_TOPIC_ARN requires an SQS_<CHANNEL>_QUEUE_URL")
+		}
+		if cfg.SNSFanoutTopicARN == "" && cfg.SQSQueueURL == "" {
+			return nil, fmt.Errorf("WORKER_MODE=sqs requires SQS_QUEUE_URL")
+		}
+	}
+
+	if sweep := os.Getenv("WORKER_SWEEP_INTERVAL"); sweep != "" {
+		s, err := strconv.Atoi(sweep)
+		if err != nil || s <= 0 {
+			return nil, fmt.Errorf("invalid WORKER_SWEEP_INTERVAL: %q (must be a positive number of seconds)", sweep)
+		}
+		cfg.WorkerSweepInterval = s
+	} else {
+		cfg.WorkerSweepInterval = 60 // default 1 minute
+	}
+
+	if maxBatch := os.Getenv("WORKER_MAX_BATCH_SIZE"); maxBatch != "" {
+		m, err := strconv.Atoi(maxBatch)
+		if err != nil || m < 0 {
+			return nil, fmt.Errorf("invalid WORKER_MAX_BATCH_SIZE: %q", maxBatch)
+		}
+		cfg.WorkerMaxBatchSize = m
+	}
+
+	if window := os.Getenv("NOTIFICATION_GROUP_WINDOW"); window != "" {
+		w, err := strconv.Atoi(window)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid NOTIFICATION_GROUP_WINDOW: %q", window)
+		}
+		cfg.NotificationGroupWindow = w
+	} else {
+		cfg.NotificationGroupWindow = 300 // default 5 minutes
+	}
+
+	if days := os.Getenv("NOTIFICATION_RETENTION_DAYS"); days != "" {
+		d, err := strconv.Atoi(days)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid NOTIFICATION_RETENTION_DAYS: %q", days)
+		}
+		cfg.NotificationRetentionDays = d
+	}
+
+	if days := os.Getenv("DLQ_PURGE_AFTER_DAYS"); days != "" {
+		d, err := strconv.Atoi(days)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid DLQ_PURGE_AFTER_DAYS: %q", days)
+		}
+		cfg.DLQPurgeAfterDays = d
+	}
+
+	// Email reputation guard
+	if enabled := os.Getenv("REPUTATION_GUARD_ENABLED"); enabled != "" {
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REPUTATION_GUARD_ENABLED: %w", err)
+		}
+		cfg.ReputationGuardEnabled = b
+	}
+
+	if minSent := os.Getenv("REPUTATION_MIN_SENT"); minSent != "" {
+		n, err := strconv.Atoi(minSent)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid REPUTATION_MIN_SENT: %q", minSent)
+		}
+		cfg.ReputationMinSent = n
+	}
+
+	rates := []struct {
+		env string
+		dst *float64
+	}{
+		{"REPUTATION_BOUNCE_THROTTLE", &cfg.ReputationBounceThrottle},
+		{"REPUTATION_BOUNCE_PAUSE", &cfg.ReputationBouncePause},
+		{"REPUTATION_COMPLAINT_THROTTLE", &cfg.ReputationComplaintThrottle},
+		{"REPUTATION_COMPLAINT_PAUSE", &cfg.ReputationComplaintPause},
+	}
+	for _, rate := range rates {
+		v := os.Getenv(rate.env)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return nil, fmt.Errorf("invalid %s: %q (must be above 0 and at most 1)", rate.env, v)
+		}
+		*rate.dst = f
+	}
+	if cfg.ReputationBounceThrottle > cfg.ReputationBouncePause {
+		return nil, fmt.Errorf("invalid REPUTATION_BOUNCE_THROTTLE: %v is above REPUTATION_BOUNCE_PAUSE %v",
+			cfg.ReputationBounceThrottle, cfg.ReputationBouncePause)
+	}
+	if cfg.ReputationComplaintThrottle > cfg.ReputationComplaintPause {
+		return nil, fmt.Errorf("invalid REPUTATION_COMPLAINT_THROTTLE: %v is above REPUTATION_COMPLAINT_PAUSE %v",
+			cfg.ReputationComplaintThrottle, cfg.ReputationComplaintPause)
+	}
+
+	if perMinute := os.Getenv("REPUTATION_THROTTLE_PER_MINUTE"); perMinute != "" {
+		n, err := strconv.Atoi(perMinute)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid REPUTATION_THROTTLE_PER_MINUTE: %q", perMinute)
+		}
+		cfg.ReputationThrottlePerMinute = n
+	}
+
+	// Provider canary config
+	if channel := os.Getenv("CANARY_CHANNEL"); channel != "" {
+		if channel != "email" && channel != "sms" {
+			return nil, fmt.Errorf("invalid CANARY_CHANNEL: %q (must be email or sms)", channel)
+		}
+		cfg.CanaryChannel = channel
+		cfg.CanaryRegion = os.Getenv("CANARY_REGION")
+		if cfg.CanaryRegion == "" {
+			return nil, fmt.Errorf("CANARY_REGION is required when CANARY_CHANNEL is set")
+		}
+	}
+	if percent := os.Getenv("CANARY_PERCENT"); percent != "" {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid CANARY_PERCENT: %q (must be above 0 and at most 100)", percent)
+		}
+		cfg.CanaryPercent = p
+	}
+	if delta := os.Getenv("CANARY_MAX_FAILURE_DELTA"); delta != "" {
+		d, err := strconv.ParseFloat(delta, 64)
+		if err != nil || d <= 0 || d > 1 {
+			return nil, fmt.Errorf("invalid CANARY_MAX_FAILURE_DELTA: %q (must be above 0 and at most 1)", delta)
+		}
+		cfg.CanaryMaxFailureDelta = d
+	}
+
+	// Shadow sending config
+	cfg.ShadowSESRegion = os.Getenv("SHADOW_SES_REGION")
+	if percent := os.Getenv("SHADOW_PERCENT"); percent != "" {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid SHADOW_PERCENT: %q (must be above 0 and at most 100)", percent)
+		}
+		cfg.ShadowPercent = p
+	}
+
+	cfg.DeliveryEventsToken = os.Getenv("DELIVERY_EVENTS_TOKEN")
+
+	// Parse EMAIL_WARMUP_SCHEDULE="50,100,250,500,1000,2500,5000"
+	if raw := os.Getenv("EMAIL_WARMUP_SCHEDULE"); raw != "" {
+		for _, day := range splitComma(raw) {
+			n, err := strconv.Atoi(day)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid EMAIL_WARMUP_SCHEDULE entry: %q", day)
+			}
+			cfg.EmailWarmupSchedule = append(cfg.EmailWarmupSchedule, n)
+		}
+	}
+
+	// Maintenance mode
+	if maint := os.Getenv("MAINTENANCE_MODE"); maint != "" {
+		b, err := strconv.ParseBool(maint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAINTENANCE_MODE: %w", err)
+		}
+		cfg.MaintenanceMode = b
+	}
+
+	if retry := os.Getenv("MAINTENANCE_RETRY_AFTER"); retry != "" {
+		r, err := strconv.Atoi(retry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAINTENANCE_RETRY_AFTER: %w", err)
+		}
+		cfg.MaintenanceRetryAfter = r
+	} else {
+		cfg.MaintenanceRetryAfter = 60 // default 60 seconds
+	}
+
+	// Backpressure
+	for _, t := range []struct {
+		name string
+		dst  *int
+	}{
+		{"BACKPRESSURE_DUE_THRESHOLD", &cfg.BackpressureDue},
+		{"BACKPRESSURE_DLQ_THRESHOLD", &cfg.BackpressureDeadLetters},
+	} {
+		if raw := os.Getenv(t.name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s: %q", t.name, raw)
+			}
+			*t.dst = n
+		}
+	}
+
+	cfg.BackpressureStatus = 503
+	if status := os.Getenv("BACKPRESSURE_STATUS"); status != "" {
+		s, err := strconv.Atoi(status)
+		if err != nil || (s != 429 && s != 503) {
+			return nil, fmt.Errorf("invalid BACKPRESSURE_STATUS: %q (must be 429 or 503)", status)
+		}
+		cfg.BackpressureStatus = s
+	}
+
+	cfg.BackpressureRetryAfter = 30
+	if retry := os.Getenv("BACKPRESSURE_RETRY_AFTER"); retry != "" {
+		r, err := strconv.Atoi(retry)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("invalid BACKPRESSURE_RETRY_AFTER: %q", retry)
+		}
+		cfg.BackpressureRetryAfter = r
+	}
+
+	cfg.BackpressureExemptTags = []string{"critical"}
+	if tags, ok := os.LookupEnv("BACKPRESSURE_EXEMPT_TAGS"); ok {
+		cfg.BackpressureExemptTags = nil
+		for _, tag := range splitComma(tags) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				cfg.BackpressureExemptTags = append(cfg.BackpressureExemptTags, tag)
+			}
+		}
+	}
+
+	cfg.QueueMetricsInterval = 15
+	if interval := os.Getenv("QUEUE_METRICS_INTERVAL"); interval != "" {
+		i, err := strconv.Atoi(interval)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid QUEUE_METRICS_INTERVAL: %q", interval)
+		}
+		cfg.QueueMetricsInterval = i
+	}
+
+	// Sender plugins
+	if plugins := os.Getenv("SENDER_PLUGINS"); plugins != "" {
+		if err := json.Unmarshal([]byte(plugins), &cfg.SenderPlugins); err != nil {
+			return nil, fmt.Errorf("invalid SENDER_PLUGINS (want a JSON object of plugin settings): %w", err)
+		}
+	}
+
+	// Parse SENDER_PLUGIN_ENDPOINTS="chat=chat-plugin:9000,pager=pager:9000"
+	if raw := os.Getenv("SENDER_PLUGIN_ENDPOINTS"); raw != "" {
+		cfg.PluginEndpoints = make(map[string]string)
+		for _, entry := range splitComma(raw) {
+			name, addr, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok || name == "" || addr == "" {
+				return nil, fmt.Errorf("invalid SENDER_PLUGIN_ENDPOINTS entry %q (want name=host:port)", entry)
+			}
+			if _, dup := cfg.PluginEndpoints[name]; dup {
+				return nil, fmt.Errorf("invalid SENDER_PLUGIN_ENDPOINTS: %q listed twice", name)
+			}
+			cfg.PluginEndpoints[name] = addr
+		}
+	}
+	if pluginTLS := os.Getenv("SENDER_PLUGIN_TLS"); pluginTLS != "" {
+		b, err := strconv.ParseBool(pluginTLS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SENDER_PLUGIN_TLS: %w", err)
+		}
+		cfg.PluginTLS = b
+	}
+
+	// Fault injection
+	if chaos := os.Getenv("CHAOS_ENABLED"); chaos != "" {
+		b, err := strconv.ParseBool(chaos)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHAOS_ENABLED: %w", err)
+		}
+		cfg.ChaosEnabled = b
+	}
+	if cfg.ChaosEnabled && cfg.Env == "production" {
+		return nil, fmt.Errorf("CHAOS_ENABLED is not allowed when ENV is production")
+	}
+	for _, p := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"CHAOS_SEND_ERROR_PERCENT", &cfg.ChaosSendErrorPercent},
+		{"CHAOS_SEND_LATENCY_PERCENT", &cfg.ChaosSendLatencyPercent},
+		{"CHAOS_REDIS_ERROR_PERCENT", &cfg.ChaosRedisErrorPercent},
+	} {
+		if raw := os.Getenv(p.name); raw != "" {
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil || f < 0 || f > 100 {
+				return nil, fmt.Errorf("invalid %s: %q (must be 0-100)", p.name, raw)
+			}
+			*p.dst = f
+		}
+	}
+	if latency := os.Getenv("CHAOS_SEND_LATENCY_MS"); latency != "" {
+		l, err := strconv.Atoi(latency)
+		if err != nil || l < 0 {
+			return nil, fmt.Errorf("invalid CHAOS_SEND_LATENCY_MS: %q", latency)
+		}
+		cfg.ChaosSendLatency = l
+	}
+
+	// Sandbox mode
+	if sandbox := os.Getenv("SANDBOX_MODE"); sandbox != "" {
+		b, err := strconv.ParseBool(sandbox)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SANDBOX_MODE: %w", err)
+		}
+		cfg.SandboxMode = b
+	}
+
+	// Demo mode
+	if demo := os.Getenv("DEMO_MODE"); demo != "" {
+		b, err := strconv.ParseBool(demo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEMO_MODE: %w", err)
+		}
+		cfg.DemoMode = b
+	}
+	if cfg.DemoMode {
+		cfg.DBDriver = DBDriverSQLite
+		cfg.DBPath = ":memory:"
+	}
+
 	// gRPC config
 	cfg.GRPCPort = 9090
 	if port := os.Getenv("GRPC_PORT"); port != "" {
@@ -265,6 +1242,171 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// Parse API_AUTH_TOKENS="token1:tenantUUID1,token2:tenantUUID2"
+	cfg.APIAuthTokens = map[string]string{
+		// Default dev token — never use in production
+		"dev-token-nimbus": "00000000-0000-0000-0000-000000000001",
+	}
+	// An optional third field sets the token's role: "token:tenantUUID:readonly".
+	if raw := os.Getenv("API_AUTH_TOKENS"); raw != "" {
+		for _, pair := range splitComma(raw) {
+			parts := splitColon(pair)
+			switch len(parts) {
+			case 2:
+				cfg.APIAuthTokens[parts[0]] = parts[1]
+			case 3:
+				if parts[2] != "full" && parts[2] != "readonly" {
+					return nil, fmt.Errorf("invalid API_AUTH_TOKENS role %q (want full or readonly)", parts[2])
+				}
+				cfg.APIAuthTokens[parts[0]] = parts[1]
+				cfg.APITokenRoles[parts[0]] = parts[2]
+			}
+		}
+	}
+
+	// Parse ADMIN_AUTH_TOKENS="token1:operator1,token2:operator2:support"
+	if raw := os.Getenv("ADMIN_AUTH_TOKENS"); raw != "" {
+		for _, pair := range splitComma(raw) {
+			parts := splitColon(pair)
+			if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("invalid ADMIN_AUTH_TOKENS entry %q (want token:operator or token:operator:role)", pair)
+			}
+			if len(parts) == 3 {
+				if parts[2] != "admin" && parts[2] != "support" {
+					return nil, fmt.Errorf("invalid ADMIN_AUTH_TOKENS role %q (want admin or support)", parts[2])
+				}
+				cfg.AdminTokenRoles[parts[0]] = parts[2]
+			}
+			cfg.AdminAuthTokens[parts[0]] = parts[1]
+		}
+	}
+
+	if required := os.Getenv("V1_AUTH_REQUIRED"); required != "" {
+		b, err := strconv.ParseBool(required)
+		if err != nil {
+			return nil, fmt.Errorf("invalid V1_AUTH_REQUIRED: %w", err)
+		}
+		cfg.V1AuthRequired = b
+	}
+
+	if mode := os.Getenv("API_PAYLOAD_MASKING"); mode != "" {
+		if mode != "mask" && mode != "omit" {
+			return nil, fmt.Errorf("invalid API_PAYLOAD_MASKING: %q (want mask or omit)", mode)
+		}
+		cfg.APIPayloadMasking = mode
+	}
+
+	// Parse METRICS_TENANT_LABELS=bucket with METRICS_TENANT_BUCKETS=64, or
+	// METRICS_TENANT_LABELS=allowlist with METRICS_TENANT_ALLOWLIST="id1,id2"
+	if mode := os.Getenv("METRICS_TENANT_LABELS"); mode != "" {
+		if mode != "raw" && mode != "bucket" && mode != "allowlist" {
+			return nil, fmt.Errorf("invalid METRICS_TENANT_LABELS: %q (want raw, bucket or allowlist)", mode)
+		}
+		cfg.MetricsTenantLabels = mode
+	}
+
+	if buckets := os.Getenv("METRICS_TENANT_BUCKETS"); buckets != "" {
+		b, err := strconv.Atoi(buckets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid METRICS_TENANT_BUCKETS: %w", err)
+		}
+		if b <= 0 {
+			return nil, fmt.Errorf("invalid METRICS_TENANT_BUCKETS: %d (must be positive)", b)
+		}
+		cfg.MetricsTenantBuckets = b
+	}
+
+	if raw := os.Getenv("METRICS_TENANT_ALLOWLIST"); raw != "" {
+		cfg.MetricsTenantAllowlist = splitComma(raw)
+	}
+
+	// Parse METRICS_BACKENDS="prometheus,dogstatsd"
+	if raw := os.Getenv("METRICS_BACKENDS"); raw != "" {
+		backends := splitComma(raw)
+		for _, b := range backends {
+			if b != "prometheus" && b != "dogstatsd" {
+				return nil, fmt.Errorf("invalid METRICS_BACKENDS entry: %q (want prometheus or dogstatsd)", b)
+			}
+		}
+		cfg.MetricsBackends = backends
+	}
+
+	if addr := os.Getenv("DOGSTATSD_ADDR"); addr != "" {
+		cfg.DogStatsDAddr = addr
+	}
+	if prefix, ok := os.LookupEnv("DOGSTATSD_PREFIX"); ok {
+		cfg.DogStatsDPrefix = prefix
+	}
+	if raw := os.Getenv("DOGSTATSD_TAGS"); raw != "" {
+		cfg.DogStatsDTags = splitComma(raw)
+	}
+
+	// ACCESS_LOG_SKIP_PATHS replaces the defaults; set it to "-" to log everything
+	if raw := os.Getenv("ACCESS_LOG_SKIP_PATHS"); raw != "" {
+		cfg.AccessLogSkipPaths = nil
+		if raw != "-" {
+			cfg.AccessLogSkipPaths = splitComma(raw)
+		}
+	}
+
+	if rate := os.Getenv("ACCESS_LOG_SAMPLE_RATE"); rate != "" {
+		f, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACCESS_LOG_SAMPLE_RATE: %w", err)
+		}
+		if f < 0 || f > 1 {
+			return nil, fmt.Errorf("invalid ACCESS_LOG_SAMPLE_RATE: %v (must be between 0 and 1)", f)
+		}
+		cfg.AccessLogSampleRate = f
+	}
+
+	if raw := os.Getenv("CORS_ALLOWED_ORIGINS"); raw != "" {
+		cfg.CORSAllowedOrigins = splitComma(raw)
+	}
+	if raw := os.Getenv("CORS_ALLOWED_METHODS"); raw != "" {
+		cfg.CORSAllowedMethods = splitComma(raw)
+	}
+	if raw := os.Getenv("CORS_ALLOWED_HEADERS"); raw != "" {
+		cfg.CORSAllowedHeaders = splitComma(raw)
+	}
+	if raw := os.Getenv("CORS_MAX_AGE"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid CORS_MAX_AGE: %q (must be a non-negative number of seconds)", raw)
+		}
+		cfg.CORSMaxAge = n
+	}
+
+	cfg.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	if raw := os.Getenv("TLS_AUTOCERT_DOMAINS"); raw != "" {
+		cfg.TLSAutocertDomains = splitComma(raw)
+	}
+	if dir := os.Getenv("TLS_AUTOCERT_CACHE_DIR"); dir != "" {
+		cfg.TLSAutocertCacheDir = dir
+	}
+	cfg.TLSAutocertEmail = os.Getenv("TLS_AUTOCERT_EMAIL")
+	if v := os.Getenv("TLS_MIN_VERSION"); v != "" {
+		if v != "1.2" && v != "1.3" {
+			return nil, fmt.Errorf("invalid TLS_MIN_VERSION: %q (must be 1.2 or 1.3)", v)
+		}
+		cfg.TLSMinVersion = v
+	}
+	if raw := os.Getenv("TLS_HTTP_PORT"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid TLS_HTTP_PORT: %q", raw)
+		}
+		cfg.TLSHTTPPort = n
+	}
+	if raw := os.Getenv("HSTS_MAX_AGE"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid HSTS_MAX_AGE: %q (must be a non-negative number of seconds)", raw)
+		}
+		cfg.HSTSMaxAge = n
+	}
+
 	return cfg, nil
 }
 
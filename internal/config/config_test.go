@@ -56,3 +56,989 @@ func TestLoad_CustomValues(t *testing.T) {
 		t.Errorf("expected env 'production', got %s", cfg.Env)
 	}
 }
+
+func TestLoad_RateLimits(t *testing.T) {
+	os.Setenv("RATE_LIMIT_PER_TENANT", "250")
+	os.Setenv("RATE_LIMIT_GLOBAL_RPS", "500")
+	os.Setenv("RATE_LIMIT_BURST", "25")
+	os.Setenv("RATE_LIMIT_ROUTES", "/v1/ai/compose:5,/v1/ai/ask:0,/v1/notifications:60")
+	defer func() {
+		os.Unsetenv("RATE_LIMIT_PER_TENANT")
+		os.Unsetenv("RATE_LIMIT_GLOBAL_RPS")
+		os.Unsetenv("RATE_LIMIT_BURST")
+		os.Unsetenv("RATE_LIMIT_ROUTES")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if cfg.RateLimitPerTenant != 250 {
+		t.Errorf("expected per-tenant limit 250, got %d", cfg.RateLimitPerTenant)
+	}
+	if cfg.RateLimitGlobalRPS != 500 {
+		t.Errorf("expected global rps 500, got %d", cfg.RateLimitGlobalRPS)
+	}
+	if cfg.RateLimitBurst != 25 {
+		t.Errorf("expected burst 25, got %d", cfg.RateLimitBurst)
+	}
+	if cfg.RateLimitRoutes["/v1/ai/compose"] != 5 {
+		t.Errorf("expected compose limit 5, got %d", cfg.RateLimitRoutes["/v1/ai/compose"])
+	}
+	if _, ok := cfg.RateLimitRoutes["/v1/ai/ask"]; ok {
+		t.Error("expected /v1/ai/ask limit to be removed by a 0 override")
+	}
+	if cfg.RateLimitRoutes["/v1/notifications"] != 60 {
+		t.Errorf("expected notifications limit 60, got %d", cfg.RateLimitRoutes["/v1/notifications"])
+	}
+}
+
+func TestLoad_InvalidRateLimitBurst(t *testing.T) {
+	os.Setenv("RATE_LIMIT_BURST", "-1")
+	defer os.Unsetenv("RATE_LIMIT_BURST")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative RATE_LIMIT_BURST")
+	}
+}
+
+func TestLoad_InvalidRateLimitRoutes(t *testing.T) {
+	os.Setenv("RATE_LIMIT_ROUTES", "/v1/ai/compose")
+	defer os.Unsetenv("RATE_LIMIT_ROUTES")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for malformed RATE_LIMIT_ROUTES")
+	}
+}
+
+func TestLoad_ShortLinkDomains(t *testing.T) {
+	os.Setenv("SHORT_LINK_DOMAINS", "00000000-0000-0000-0000-000000000001:go.acme.com")
+	defer os.Unsetenv("SHORT_LINK_DOMAINS")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ShortLinkDomains["00000000-0000-0000-0000-000000000001"] != "go.acme.com" {
+		t.Errorf("expected custom domain, got %v", cfg.ShortLinkDomains)
+	}
+
+	os.Setenv("SHORT_LINK_DOMAINS", "go.acme.com")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for malformed SHORT_LINK_DOMAINS")
+	}
+}
+
+func TestLoad_EmailValidationMode(t *testing.T) {
+	os.Unsetenv("EMAIL_VALIDATION_MODE")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.EmailValidationMode != EmailValidationWarn {
+		t.Errorf("expected default mode %q, got %q", EmailValidationWarn, cfg.EmailValidationMode)
+	}
+
+	os.Setenv("EMAIL_VALIDATION_MODE", "strict")
+	defer os.Unsetenv("EMAIL_VALIDATION_MODE")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown EMAIL_VALIDATION_MODE")
+	}
+}
+
+func TestLoad_DBDriver(t *testing.T) {
+	os.Unsetenv("DB_DRIVER")
+	os.Unsetenv("DB_PORT")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DBDriver != DBDriverPostgres || cfg.DBPort != 5432 {
+		t.Errorf("expected postgres on 5432, got %s on %d", cfg.DBDriver, cfg.DBPort)
+	}
+
+	os.Setenv("DB_DRIVER", "mysql")
+	defer os.Unsetenv("DB_DRIVER")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DBDriver != DBDriverMySQL || cfg.DBPort != 3306 {
+		t.Errorf("expected mysql on 3306, got %s on %d", cfg.DBDriver, cfg.DBPort)
+	}
+
+	os.Setenv("DB_PORT", "3307")
+	defer os.Unsetenv("DB_PORT")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DBPort != 3307 {
+		t.Errorf("expected explicit DB_PORT 3307 to win, got %d", cfg.DBPort)
+	}
+
+	os.Setenv("DB_DRIVER", "sqlite")
+	os.Setenv("DB_PATH", "/tmp/nimbus-test.db")
+	defer os.Unsetenv("DB_PATH")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DBDriver != DBDriverSQLite || cfg.DBPath != "/tmp/nimbus-test.db" {
+		t.Errorf("expected sqlite at /tmp/nimbus-test.db, got %s at %s", cfg.DBDriver, cfg.DBPath)
+	}
+
+	os.Setenv("DB_DRIVER", "sqlserver")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown DB_DRIVER")
+	}
+}
+
+func TestLoad_DemoMode(t *testing.T) {
+	os.Setenv("DEMO_MODE", "true")
+	os.Setenv("DB_DRIVER", "postgres")
+	defer os.Unsetenv("DEMO_MODE")
+	defer os.Unsetenv("DB_DRIVER")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.DemoMode || cfg.DBDriver != DBDriverSQLite || cfg.DBPath != ":memory:" {
+		t.Errorf("expected demo mode on in-memory sqlite, got demo=%v %s at %s", cfg.DemoMode, cfg.DBDriver, cfg.DBPath)
+	}
+
+	os.Setenv("DEMO_MODE", "maybe")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid DEMO_MODE")
+	}
+}
+
+func TestLoad_APITokenRoles(t *testing.T) {
+	os.Setenv("API_AUTH_TOKENS", "ops:00000000-0000-0000-0000-000000000001,support:00000000-0000-0000-0000-000000000001:readonly")
+	defer os.Unsetenv("API_AUTH_TOKENS")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.APIAuthTokens["support"] != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("expected support token to map to its tenant, got %v", cfg.APIAuthTokens)
+	}
+	if cfg.APITokenRoles["support"] != "readonly" || cfg.APITokenRoles["ops"] != "" {
+		t.Errorf("expected only support to be readonly, got %v", cfg.APITokenRoles)
+	}
+
+	os.Setenv("API_AUTH_TOKENS", "support:00000000-0000-0000-0000-000000000001:admin")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown role")
+	}
+}
+
+func TestLoad_AdminAuthTokens(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.AdminAuthTokens) != 0 {
+		t.Errorf("expected no default operator tokens, got %v", cfg.AdminAuthTokens)
+	}
+
+	os.Setenv("ADMIN_AUTH_TOKENS", "s3cret:jane@example.com,h3lp:sam@example.com:support")
+	defer os.Unsetenv("ADMIN_AUTH_TOKENS")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.AdminAuthTokens["s3cret"] != "jane@example.com" || cfg.AdminAuthTokens["h3lp"] != "sam@example.com" {
+		t.Errorf("unexpected operator tokens %v", cfg.AdminAuthTokens)
+	}
+	if cfg.AdminTokenRoles["h3lp"] != "support" || cfg.AdminTokenRoles["s3cret"] != "" {
+		t.Errorf("expected only sam to be support, got %v", cfg.AdminTokenRoles)
+	}
+
+	for _, bad := range []string{"s3cret", "s3cret:jane:root", ":jane"} {
+		os.Setenv("ADMIN_AUTH_TOKENS", bad)
+		if _, err := Load(); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestLoad_MetricsTenantLabels(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.MetricsTenantLabels != "raw" || cfg.MetricsTenantBuckets != 64 {
+		t.Errorf("expected raw labels with 64 buckets by default, got %q/%d", cfg.MetricsTenantLabels, cfg.MetricsTenantBuckets)
+	}
+
+	os.Setenv("METRICS_TENANT_LABELS", "allowlist")
+	os.Setenv("METRICS_TENANT_ALLOWLIST", "tenant-a,tenant-b")
+	defer os.Unsetenv("METRICS_TENANT_LABELS")
+	defer os.Unsetenv("METRICS_TENANT_ALLOWLIST")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.MetricsTenantAllowlist) != 2 || cfg.MetricsTenantAllowlist[1] != "tenant-b" {
+		t.Errorf("expected two allowlisted tenants, got %v", cfg.MetricsTenantAllowlist)
+	}
+
+	os.Setenv("METRICS_TENANT_BUCKETS", "0")
+	defer os.Unsetenv("METRICS_TENANT_BUCKETS")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for zero METRICS_TENANT_BUCKETS")
+	}
+
+	os.Setenv("METRICS_TENANT_BUCKETS", "8")
+	os.Setenv("METRICS_TENANT_LABELS", "hashed")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown METRICS_TENANT_LABELS")
+	}
+}
+
+func TestLoad_MetricsBackends(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.MetricsBackendEnabled("prometheus") || cfg.MetricsBackendEnabled("dogstatsd") {
+		t.Errorf("expected prometheus only by default, got %v", cfg.MetricsBackends)
+	}
+
+	os.Setenv("METRICS_BACKENDS", "prometheus,dogstatsd")
+	os.Setenv("DOGSTATSD_TAGS", "env:test,service:nimbus")
+	defer os.Unsetenv("METRICS_BACKENDS")
+	defer os.Unsetenv("DOGSTATSD_TAGS")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.MetricsBackendEnabled("prometheus") || !cfg.MetricsBackendEnabled("dogstatsd") {
+		t.Errorf("expected both backends, got %v", cfg.MetricsBackends)
+	}
+	if len(cfg.DogStatsDTags) != 2 {
+		t.Errorf("expected two constant tags, got %v", cfg.DogStatsDTags)
+	}
+
+	os.Setenv("METRICS_BACKENDS", "graphite")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown METRICS_BACKENDS entry")
+	}
+}
+
+func TestLoad_AccessLog(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.AccessLogSkipPaths) != 3 || cfg.AccessLogSampleRate != 1 {
+		t.Errorf("expected default skip paths and full sampling, got %v/%v", cfg.AccessLogSkipPaths, cfg.AccessLogSampleRate)
+	}
+
+	os.Setenv("ACCESS_LOG_SKIP_PATHS", "-")
+	os.Setenv("ACCESS_LOG_SAMPLE_RATE", "0.1")
+	defer os.Unsetenv("ACCESS_LOG_SKIP_PATHS")
+	defer os.Unsetenv("ACCESS_LOG_SAMPLE_RATE")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.AccessLogSkipPaths) != 0 || cfg.AccessLogSampleRate != 0.1 {
+		t.Errorf("expected no skip paths and 10%% sampling, got %v/%v", cfg.AccessLogSkipPaths, cfg.AccessLogSampleRate)
+	}
+
+	os.Setenv("ACCESS_LOG_SAMPLE_RATE", "1.5")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for out-of-range ACCESS_LOG_SAMPLE_RATE")
+	}
+}
+
+func TestLoad_CORS(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.CORSAllowedOrigins) != 0 || len(cfg.CORSAllowedHeaders) != 7 || cfg.CORSMaxAge != 600 {
+		t.Errorf("expected CORS off with default headers, got %v/%v/%d", cfg.CORSAllowedOrigins, cfg.CORSAllowedHeaders, cfg.CORSMaxAge)
+	}
+
+	os.Setenv("CORS_ALLOWED_ORIGINS", "https://dash.acme.com,http://localhost:3000")
+	os.Setenv("CORS_ALLOWED_METHODS", "GET,POST")
+	os.Setenv("CORS_MAX_AGE", "0")
+	defer os.Unsetenv("CORS_ALLOWED_ORIGINS")
+	defer os.Unsetenv("CORS_ALLOWED_METHODS")
+	defer os.Unsetenv("CORS_MAX_AGE")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.CORSAllowedOrigins) != 2 || cfg.CORSAllowedOrigins[1] != "http://localhost:3000" ||
+		len(cfg.CORSAllowedMethods) != 2 || cfg.CORSMaxAge != 0 {
+		t.Errorf("unexpected CORS config: %v/%v/%d", cfg.CORSAllowedOrigins, cfg.CORSAllowedMethods, cfg.CORSMaxAge)
+	}
+
+	os.Setenv("CORS_MAX_AGE", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative CORS_MAX_AGE")
+	}
+}
+
+func TestLoad_DBSlowQuery(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DBSlowQueryMS != 500 {
+		t.Errorf("expected 500ms default, got %d", cfg.DBSlowQueryMS)
+	}
+
+	os.Setenv("DB_SLOW_QUERY_MS", "abc")
+	defer os.Unsetenv("DB_SLOW_QUERY_MS")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for non-numeric DB_SLOW_QUERY_MS")
+	}
+}
+
+func TestLoad_RateLimitFallback(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.RateLimitFallbackPercent != 25 {
+		t.Errorf("expected fallback 25%%, got %d", cfg.RateLimitFallbackPercent)
+	}
+
+	os.Setenv("RATE_LIMIT_FALLBACK_PERCENT", "0")
+	defer os.Unsetenv("RATE_LIMIT_FALLBACK_PERCENT")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.RateLimitFallbackPercent != 0 {
+		t.Errorf("expected fallback disabled, got %d", cfg.RateLimitFallbackPercent)
+	}
+
+	os.Setenv("RATE_LIMIT_FALLBACK_PERCENT", "150")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for RATE_LIMIT_FALLBACK_PERCENT over 100")
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.TLSCertFile != "" || len(cfg.TLSAutocertDomains) != 0 || cfg.TLSMinVersion != "1.2" || cfg.HSTSMaxAge != 31536000 {
+		t.Errorf("expected TLS off with defaults, got %+v", cfg)
+	}
+
+	os.Setenv("TLS_AUTOCERT_DOMAINS", "api.acme.com,hooks.acme.com")
+	os.Setenv("TLS_MIN_VERSION", "1.3")
+	os.Setenv("TLS_HTTP_PORT", "80")
+	os.Setenv("HSTS_MAX_AGE", "0")
+	defer os.Unsetenv("TLS_AUTOCERT_DOMAINS")
+	defer os.Unsetenv("TLS_MIN_VERSION")
+	defer os.Unsetenv("TLS_HTTP_PORT")
+	defer os.Unsetenv("HSTS_MAX_AGE")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.TLSAutocertDomains) != 2 || cfg.TLSMinVersion != "1.3" || cfg.TLSHTTPPort != 80 || cfg.HSTSMaxAge != 0 {
+		t.Errorf("unexpected TLS config: %v/%s/%d/%d", cfg.TLSAutocertDomains, cfg.TLSMinVersion, cfg.TLSHTTPPort, cfg.HSTSMaxAge)
+	}
+
+	os.Setenv("TLS_MIN_VERSION", "1.0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for TLS_MIN_VERSION 1.0")
+	}
+}
+
+func TestLoad_WebhookHeadersKey(t *testing.T) {
+	os.Setenv("WEBHOOK_HEADERS_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	defer os.Unsetenv("WEBHOOK_HEADERS_KEY")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WebhookHeadersKey == "" {
+		t.Error("expected the key to be loaded")
+	}
+
+	os.Setenv("WEBHOOK_HEADERS_KEY", "c2hvcnQ=")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a short WEBHOOK_HEADERS_KEY")
+	}
+}
+
+func TestLoad_WorkerStuckTimeout(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WorkerStuckTimeout != 300 {
+		t.Errorf("expected default of 300 seconds, got %d", cfg.WorkerStuckTimeout)
+	}
+
+	os.Setenv("WORKER_STUCK_TIMEOUT", "0")
+	defer os.Unsetenv("WORKER_STUCK_TIMEOUT")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for zero WORKER_STUCK_TIMEOUT")
+	}
+}
+
+func TestLoad_ConfigCacheTTL(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ConfigCacheTTL != 60 {
+		t.Errorf("expected default of 60 seconds, got %d", cfg.ConfigCacheTTL)
+	}
+
+	os.Setenv("CONFIG_CACHE_TTL", "0")
+	defer os.Unsetenv("CONFIG_CACHE_TTL")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ConfigCacheTTL != 0 {
+		t.Errorf("expected caching disabled, got %d", cfg.ConfigCacheTTL)
+	}
+
+	os.Setenv("CONFIG_CACHE_TTL", "-5")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a negative CONFIG_CACHE_TTL")
+	}
+}
+
+func TestLoad_WorkerMaxBatchSize(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WorkerMaxBatchSize != 0 {
+		t.Errorf("expected batch tuning off by default, got %d", cfg.WorkerMaxBatchSize)
+	}
+
+	os.Setenv("WORKER_MAX_BATCH_SIZE", "100")
+	defer os.Unsetenv("WORKER_MAX_BATCH_SIZE")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WorkerMaxBatchSize != 100 {
+		t.Errorf("expected 100, got %d", cfg.WorkerMaxBatchSize)
+	}
+
+	os.Setenv("WORKER_MAX_BATCH_SIZE", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a negative WORKER_MAX_BATCH_SIZE")
+	}
+}
+
+func TestLoad_WorkerMode(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WorkerMode != WorkerModePoll || cfg.WorkerSweepInterval != 60 {
+		t.Errorf("expected poll mode with a 60s sweep by default, got %q %d", cfg.WorkerMode, cfg.WorkerSweepInterval)
+	}
+
+	os.Setenv("WORKER_MODE", "sqs")
+	defer os.Unsetenv("WORKER_MODE")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for sqs mode without a queue")
+	}
+
+	os.Setenv("SQS_QUEUE_URL", "https://sqs/nimbus")
+	os.Setenv("WORKER_SWEEP_INTERVAL", "300")
+	defer os.Unsetenv("SQS_QUEUE_URL")
+	defer os.Unsetenv("WORKER_SWEEP_INTERVAL")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WorkerMode != WorkerModeSQS || cfg.WorkerSweepInterval != 300 {
+		t.Errorf("expected sqs mode with a 300s sweep, got %q %d", cfg.WorkerMode, cfg.WorkerSweepInterval)
+	}
+
+	os.Setenv("SNS_FANOUT_TOPIC_ARN", "arn:aws:sns:us-east-1:123:nimbus")
+	defer os.Unsetenv("SNS_FANOUT_TOPIC_ARN")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for sqs mode with fan-out but no channel queues")
+	}
+	os.Unsetenv("SNS_FANOUT_TOPIC_ARN")
+
+	for _, v := range []string{"0", "soon"} {
+		os.Setenv("WORKER_SWEEP_INTERVAL", v)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for WORKER_SWEEP_INTERVAL=%q", v)
+		}
+	}
+
+	os.Setenv("WORKER_MODE", "kafka")
+	os.Unsetenv("WORKER_SWEEP_INTERVAL")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unknown WORKER_MODE")
+	}
+}
+
+func TestLoad_QueueMetricsInterval(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.QueueMetricsInterval != 15 {
+		t.Errorf("expected default 15, got %d", cfg.QueueMetricsInterval)
+	}
+
+	os.Setenv("QUEUE_METRICS_INTERVAL", "0")
+	defer os.Unsetenv("QUEUE_METRICS_INTERVAL")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.QueueMetricsInterval != 0 {
+		t.Errorf("expected 0 to disable, got %d", cfg.QueueMetricsInterval)
+	}
+
+	os.Setenv("QUEUE_METRICS_INTERVAL", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative QUEUE_METRICS_INTERVAL")
+	}
+}
+
+func TestLoad_SenderPlugins(t *testing.T) {
+	os.Setenv("SENDER_PLUGINS", `{"slack":{"token":"xoxb"},"teams":null}`)
+	defer os.Unsetenv("SENDER_PLUGINS")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.SenderPlugins) != 2 || string(cfg.SenderPlugins["slack"]) != `{"token":"xoxb"}` {
+		t.Errorf("unexpected plugins: %v", cfg.SenderPlugins)
+	}
+
+	os.Setenv("SENDER_PLUGINS", "slack")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for SENDER_PLUGINS that isn't JSON")
+	}
+}
+
+func TestLoad_PluginEndpoints(t *testing.T) {
+	os.Setenv("SENDER_PLUGIN_ENDPOINTS", "chat=chat-plugin:9000, pager=10.0.0.5:9000")
+	defer os.Unsetenv("SENDER_PLUGIN_ENDPOINTS")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.PluginEndpoints) != 2 || cfg.PluginEndpoints["pager"] != "10.0.0.5:9000" || cfg.PluginTLS {
+		t.Errorf("unexpected plugin endpoints: %v, tls %v", cfg.PluginEndpoints, cfg.PluginTLS)
+	}
+
+	for _, raw := range []string{"chat", "chat=", "chat=a:1,chat=b:1"} {
+		os.Setenv("SENDER_PLUGIN_ENDPOINTS", raw)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for SENDER_PLUGIN_ENDPOINTS %q", raw)
+		}
+	}
+}
+
+func TestLoad_Chaos(t *testing.T) {
+	os.Setenv("CHAOS_ENABLED", "true")
+	os.Setenv("CHAOS_SEND_ERROR_PERCENT", "20")
+	os.Setenv("CHAOS_SEND_LATENCY_MS", "1500")
+	defer os.Unsetenv("CHAOS_ENABLED")
+	defer os.Unsetenv("CHAOS_SEND_ERROR_PERCENT")
+	defer os.Unsetenv("CHAOS_SEND_LATENCY_MS")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.ChaosEnabled || cfg.ChaosSendErrorPercent != 20 || cfg.ChaosSendLatency != 1500 {
+		t.Errorf("unexpected chaos config: %+v", cfg)
+	}
+
+	os.Setenv("CHAOS_REDIS_ERROR_PERCENT", "150")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for CHAOS_REDIS_ERROR_PERCENT over 100")
+	}
+	os.Unsetenv("CHAOS_REDIS_ERROR_PERCENT")
+
+	os.Setenv("ENV", "production")
+	defer os.Unsetenv("ENV")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected chaos refused in production")
+	}
+}
+
+func TestLoad_Backpressure(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.BackpressureDue != 0 || cfg.BackpressureStatus != 503 || cfg.BackpressureRetryAfter != 30 {
+		t.Errorf("unexpected defaults: %d, %d, %d", cfg.BackpressureDue, cfg.BackpressureStatus, cfg.BackpressureRetryAfter)
+	}
+	if len(cfg.BackpressureExemptTags) != 1 || cfg.BackpressureExemptTags[0] != "critical" {
+		t.Errorf("expected critical exempt by default, got %v", cfg.BackpressureExemptTags)
+	}
+
+	os.Setenv("BACKPRESSURE_DUE_THRESHOLD", "10000")
+	os.Setenv("BACKPRESSURE_EXEMPT_TAGS", "critical, security")
+	defer os.Unsetenv("BACKPRESSURE_DUE_THRESHOLD")
+	defer os.Unsetenv("BACKPRESSURE_EXEMPT_TAGS")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.BackpressureDue != 10000 || len(cfg.BackpressureExemptTags) != 2 || cfg.BackpressureExemptTags[1] != "security" {
+		t.Errorf("unexpected backpressure config: %d, %v", cfg.BackpressureDue, cfg.BackpressureExemptTags)
+	}
+
+	os.Setenv("BACKPRESSURE_STATUS", "500")
+	defer os.Unsetenv("BACKPRESSURE_STATUS")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for BACKPRESSURE_STATUS 500")
+	}
+}
+
+func TestLoad_RateLimitExemptions(t *testing.T) {
+	os.Setenv("RATE_LIMIT_EXEMPT_CIDRS", "10.0.0.0/8, 192.168.1.5")
+	os.Setenv("RATE_LIMIT_EXEMPT_TOKENS", "batch-token,")
+	defer os.Unsetenv("RATE_LIMIT_EXEMPT_CIDRS")
+	defer os.Unsetenv("RATE_LIMIT_EXEMPT_TOKENS")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.RateLimitExemptCIDRs) != 2 || cfg.RateLimitExemptCIDRs[1] != "192.168.1.5" {
+		t.Errorf("unexpected exempt cidrs: %v", cfg.RateLimitExemptCIDRs)
+	}
+	if len(cfg.RateLimitExemptTokens) != 1 || cfg.RateLimitExemptTokens[0] != "batch-token" {
+		t.Errorf("unexpected exempt tokens: %v", cfg.RateLimitExemptTokens)
+	}
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	os.Setenv("TRUSTED_PROXY_CIDRS", "10.0.0.0/8, ,172.16.0.1")
+	defer os.Unsetenv("TRUSTED_PROXY_CIDRS")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.TrustedProxyCIDRs) != 2 || cfg.TrustedProxyCIDRs[1] != "172.16.0.1" {
+		t.Errorf("unexpected trusted proxies: %v", cfg.TrustedProxyCIDRs)
+	}
+}
+
+func TestLoad_NotificationGroupWindow(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.NotificationGroupWindow != 300 {
+		t.Errorf("expected default of 300 seconds, got %d", cfg.NotificationGroupWindow)
+	}
+
+	os.Setenv("NOTIFICATION_GROUP_WINDOW", "-1")
+	defer os.Unsetenv("NOTIFICATION_GROUP_WINDOW")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a negative NOTIFICATION_GROUP_WINDOW")
+	}
+}
+
+func TestLoad_CleanupJobs(t *testing.T) {
+	os.Setenv("NOTIFICATION_RETENTION_DAYS", "90")
+	os.Setenv("DLQ_PURGE_AFTER_DAYS", "30")
+	defer os.Unsetenv("NOTIFICATION_RETENTION_DAYS")
+	defer os.Unsetenv("DLQ_PURGE_AFTER_DAYS")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.NotificationRetentionDays != 90 || cfg.DLQPurgeAfterDays != 30 {
+		t.Errorf("expected 90/30 days, got %d/%d", cfg.NotificationRetentionDays, cfg.DLQPurgeAfterDays)
+	}
+
+	os.Setenv("DLQ_PURGE_AFTER_DAYS", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative DLQ_PURGE_AFTER_DAYS")
+	}
+}
+
+func TestLoad_ReputationGuard(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.ReputationGuardEnabled || cfg.ReputationBouncePause != 0.10 || cfg.ReputationMinSent != 200 {
+		t.Errorf("unexpected reputation defaults: %+v", cfg)
+	}
+
+	os.Setenv("REPUTATION_BOUNCE_THROTTLE", "0.02")
+	os.Setenv("REPUTATION_THROTTLE_PER_MINUTE", "30")
+	defer os.Unsetenv("REPUTATION_BOUNCE_THROTTLE")
+	defer os.Unsetenv("REPUTATION_THROTTLE_PER_MINUTE")
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ReputationBounceThrottle != 0.02 || cfg.ReputationThrottlePerMinute != 30 {
+		t.Errorf("expected 0.02 and 30/min, got %v and %d", cfg.ReputationBounceThrottle, cfg.ReputationThrottlePerMinute)
+	}
+
+	os.Setenv("REPUTATION_BOUNCE_THROTTLE", "0.2")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a throttle threshold above the pause threshold")
+	}
+
+	os.Setenv("REPUTATION_BOUNCE_THROTTLE", "5")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a rate above 1")
+	}
+}
+
+func TestLoad_EmailWarmupSchedule(t *testing.T) {
+	os.Setenv("EMAIL_WARMUP_SCHEDULE", "50,100,500")
+	defer os.Unsetenv("EMAIL_WARMUP_SCHEDULE")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.EmailWarmupSchedule) != 3 || cfg.EmailWarmupSchedule[1] != 100 {
+		t.Errorf("expected [50 100 500], got %v", cfg.EmailWarmupSchedule)
+	}
+
+	os.Setenv("EMAIL_WARMUP_SCHEDULE", "50,0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a zero daily cap")
+	}
+}
+
+func TestLoad_DeliveryCosts(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.SESCostPerEmail != 0.0001 || cfg.WebhookCostPerCall != 0 {
+		t.Errorf("expected default prices 0.0001 and 0, got %v and %v", cfg.SESCostPerEmail, cfg.WebhookCostPerCall)
+	}
+
+	os.Setenv("WEBHOOK_COST_PER_CALL", "0.00002")
+	defer os.Unsetenv("WEBHOOK_COST_PER_CALL")
+	if cfg, err = Load(); err != nil || cfg.WebhookCostPerCall != 0.00002 {
+		t.Errorf("expected 0.00002, got %v (err %v)", cfg, err)
+	}
+
+	os.Setenv("SES_COST_PER_EMAIL", "-1")
+	defer os.Unsetenv("SES_COST_PER_EMAIL")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a negative price")
+	}
+}
+
+func TestLoad_SNSFanout(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.SNSFanoutTopicARN != "" || len(cfg.ChannelQueueURLs) != 0 {
+		t.Errorf("expected fan-out off by default, got %q %v", cfg.SNSFanoutTopicARN, cfg.ChannelQueueURLs)
+	}
+
+	os.Setenv("SNS_FANOUT_TOPIC_ARN", "arn:aws:sns:us-east-1:123:nimbus")
+	os.Setenv("SQS_SMS_QUEUE_URL", "https://sqs/sms")
+	defer os.Unsetenv("SNS_FANOUT_TOPIC_ARN")
+	defer os.Unsetenv("SQS_SMS_QUEUE_URL")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.SNSFanoutTopicARN != "arn:aws:sns:us-east-1:123:nimbus" {
+		t.Errorf("unexpected topic %q", cfg.SNSFanoutTopicARN)
+	}
+	if len(cfg.ChannelQueueURLs) != 1 || cfg.ChannelQueueURLs["sms"] != "https://sqs/sms" {
+		t.Errorf("expected only the sms queue, got %v", cfg.ChannelQueueURLs)
+	}
+}
+
+func TestLoad_EventBridge(t *testing.T) {
+	os.Setenv("AWS_REGION", "eu-west-1")
+	defer os.Unsetenv("AWS_REGION")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.EventBridgeBusName != "" || cfg.EventBridgeRegion != "eu-west-1" {
+		t.Errorf("expected events off in the AWS region by default, got %q in %q", cfg.EventBridgeBusName, cfg.EventBridgeRegion)
+	}
+
+	os.Setenv("EVENTBRIDGE_BUS_NAME", "nimbus-events")
+	os.Setenv("EVENTBRIDGE_REGION", "us-east-2")
+	defer os.Unsetenv("EVENTBRIDGE_BUS_NAME")
+	defer os.Unsetenv("EVENTBRIDGE_REGION")
+	if cfg, err = Load(); err != nil || cfg.EventBridgeBusName != "nimbus-events" || cfg.EventBridgeRegion != "us-east-2" {
+		t.Errorf("unexpected config %+v (err %v)", cfg, err)
+	}
+}
+
+func TestLoad_Archive(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ArchiveS3Bucket != "" || cfg.ArchiveS3Prefix != "deliveries/" {
+		t.Errorf("expected archival off with the default prefix, got %q %q", cfg.ArchiveS3Bucket, cfg.ArchiveS3Prefix)
+	}
+
+	os.Setenv("ARCHIVE_S3_BUCKET", "nimbus-archive")
+	os.Setenv("ARCHIVE_S3_PREFIX", "")
+	defer os.Unsetenv("ARCHIVE_S3_BUCKET")
+	defer os.Unsetenv("ARCHIVE_S3_PREFIX")
+	if cfg, err = Load(); err != nil || cfg.ArchiveS3Bucket != "nimbus-archive" || cfg.ArchiveS3Prefix != "" {
+		t.Errorf("expected bucket set and an explicit empty prefix kept, got %+v (err %v)", cfg, err)
+	}
+}
+
+func TestLoad_DLQExport(t *testing.T) {
+	os.Setenv("AWS_REGION", "eu-west-1")
+	defer os.Unsetenv("AWS_REGION")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DLQExportS3Bucket != "" || cfg.DLQExportS3Prefix != "dlq-exports/" || cfg.DLQExportS3Region != "eu-west-1" {
+		t.Errorf("expected exports off with the default prefix and AWS region, got %q %q %q",
+			cfg.DLQExportS3Bucket, cfg.DLQExportS3Prefix, cfg.DLQExportS3Region)
+	}
+
+	os.Setenv("DLQ_EXPORT_S3_BUCKET", "nimbus-dlq-exports")
+	os.Setenv("DLQ_EXPORT_S3_REGION", "us-west-2")
+	defer os.Unsetenv("DLQ_EXPORT_S3_BUCKET")
+	defer os.Unsetenv("DLQ_EXPORT_S3_REGION")
+	if cfg, err = Load(); err != nil || cfg.DLQExportS3Bucket != "nimbus-dlq-exports" || cfg.DLQExportS3Region != "us-west-2" {
+		t.Errorf("expected the export bucket and region set, got %+v (err %v)", cfg, err)
+	}
+}
+
+func TestLoad_ImportS3(t *testing.T) {
+	os.Setenv("AWS_REGION", "eu-west-1")
+	defer os.Unsetenv("AWS_REGION")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ImportS3Bucket != "" || cfg.ImportS3Region != "eu-west-1" {
+		t.Errorf("expected S3 imports off in the AWS region, got %q %q", cfg.ImportS3Bucket, cfg.ImportS3Region)
+	}
+
+	os.Setenv("IMPORT_S3_BUCKET", "nimbus-imports")
+	os.Setenv("IMPORT_S3_REGION", "us-west-2")
+	defer os.Unsetenv("IMPORT_S3_BUCKET")
+	defer os.Unsetenv("IMPORT_S3_REGION")
+	if cfg, err = Load(); err != nil || cfg.ImportS3Bucket != "nimbus-imports" || cfg.ImportS3Region != "us-west-2" {
+		t.Errorf("expected bucket and region set, got %+v (err %v)", cfg, err)
+	}
+}
+
+func TestLoad_ClickHouse(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ClickHouseURL != "" {
+		t.Errorf("expected analytics export off by default, got %q", cfg.ClickHouseURL)
+	}
+
+	os.Setenv("CLICKHOUSE_URL", "https://ch.internal:8443/")
+	os.Setenv("CLICKHOUSE_DATABASE", "analytics")
+	os.Setenv("CLICKHOUSE_USER", "nimbus")
+	defer os.Unsetenv("CLICKHOUSE_URL")
+	defer os.Unsetenv("CLICKHOUSE_DATABASE")
+	defer os.Unsetenv("CLICKHOUSE_USER")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ClickHouseURL != "https://ch.internal:8443" || cfg.ClickHouseDatabase != "analytics" || cfg.ClickHouseUser != "nimbus" {
+		t.Errorf("unexpected clickhouse config %q %q %q", cfg.ClickHouseURL, cfg.ClickHouseDatabase, cfg.ClickHouseUser)
+	}
+}
+
+func TestLoad_Canary(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CanaryChannel != "" || cfg.CanaryPercent != 5 || cfg.CanaryMaxFailureDelta != 0.05 {
+		t.Errorf("expected canary off with defaults, got %q %v %v", cfg.CanaryChannel, cfg.CanaryPercent, cfg.CanaryMaxFailureDelta)
+	}
+
+	os.Setenv("CANARY_CHANNEL", "email")
+	defer os.Unsetenv("CANARY_CHANNEL")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error without CANARY_REGION")
+	}
+
+	os.Setenv("CANARY_REGION", "eu-west-1")
+	os.Setenv("CANARY_PERCENT", "12.5")
+	os.Setenv("CANARY_MAX_FAILURE_DELTA", "0.02")
+	defer os.Unsetenv("CANARY_REGION")
+	defer os.Unsetenv("CANARY_PERCENT")
+	defer os.Unsetenv("CANARY_MAX_FAILURE_DELTA")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CanaryChannel != "email" || cfg.CanaryRegion != "eu-west-1" || cfg.CanaryPercent != 12.5 || cfg.CanaryMaxFailureDelta != 0.02 {
+		t.Errorf("unexpected canary config %q %q %v %v", cfg.CanaryChannel, cfg.CanaryRegion, cfg.CanaryPercent, cfg.CanaryMaxFailureDelta)
+	}
+
+	for env, value := range map[string]string{"CANARY_CHANNEL": "webhook", "CANARY_PERCENT": "150"} {
+		old := os.Getenv(env)
+		os.Setenv(env, value)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected an error for %s=%s", env, value)
+		}
+		os.Setenv(env, old)
+	}
+}
+
+func TestLoad_Shadow(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ShadowSESRegion != "" || cfg.ShadowPercent != 1 {
+		t.Errorf("expected shadow off with 1%% default, got %q %v", cfg.ShadowSESRegion, cfg.ShadowPercent)
+	}
+
+	os.Setenv("SHADOW_SES_REGION", "us-west-2")
+	os.Setenv("SHADOW_PERCENT", "0.5")
+	defer os.Unsetenv("SHADOW_SES_REGION")
+	defer os.Unsetenv("SHADOW_PERCENT")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.ShadowSESRegion != "us-west-2" || cfg.ShadowPercent != 0.5 {
+		t.Errorf("unexpected shadow config %q %v", cfg.ShadowSESRegion, cfg.ShadowPercent)
+	}
+
+	os.Setenv("SHADOW_PERCENT", "0")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for SHADOW_PERCENT=0")
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,17 +10,162 @@ import (
 
 // Notification represents a notification in the database
 type Notification struct {
-	Payload      json.RawMessage `json:"payload"` // 24 bytes (slice)
-	ID           uuid.UUID       `json:"id"`      // 16 bytes
-	TenantID     uuid.UUID       `json:"tenant_id"`
-	UserID       uuid.UUID       `json:"user_id"`
-	CreatedAt    time.Time       `json:"created_at"` // 24 bytes
-	UpdatedAt    time.Time       `json:"updated_at"`
-	NextRetryAt  *time.Time      `json:"next_retry_at,omitempty"` // 8 bytes
-	ErrorMessage *string         `json:"error_message,omitempty"`
-	Channel      string          `json:"channel"`   // 16 bytes
-	Status       string          `json:"status"`
-	Attempt      int             `json:"attempt"`   // 8 bytes
+	Payload       json.RawMessage `json:"payload"` // 24 bytes (slice)
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	Tags          []string        `json:"tags,omitempty"`
+	ID            uuid.UUID       `json:"id"` // 16 bytes
+	TenantID      uuid.UUID       `json:"tenant_id"`
+	UserID        uuid.UUID       `json:"user_id"`
+	CreatedAt     time.Time       `json:"created_at"` // 24 bytes
+	UpdatedAt     time.Time       `json:"updated_at"`
+	NextRetryAt   *time.Time      `json:"next_retry_at,omitempty"` // 8 bytes
+	ErrorMessage  *string         `json:"error_message,omitempty"`
+	Channel       string          `json:"channel"` // 16 bytes
+	Status        string          `json:"status"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	// Provider and ProviderMessageID identify the message at the provider
+	// once sent. Senders set them on success; the worker persists them.
+	Provider          string `json:"provider,omitempty"`
+	ProviderMessageID string `json:"provider_message_id,omitempty"`
+	// Cost is the estimated provider cost of the send, set once sent.
+	Cost *float64 `json:"cost,omitempty"`
+	// ArchiveKey is the S3 key of the archived rendered message, set once
+	// sent when archival is on. Like Provider, senders set it on success.
+	ArchiveKey string `json:"archive_key,omitempty"`
+	// SLASeconds is the optional delivery deadline, in seconds from
+	// creation. SLABreached is set once the notification is sent or
+	// dead-lettered: true if it wasn't delivered within the deadline.
+	SLASeconds  *int  `json:"sla_seconds,omitempty"`
+	SLABreached *bool `json:"sla_breached,omitempty"`
+	// Annotations is provider-specific detail about the latest send, such
+	// as a webhook's response headers. Like Provider, senders set it; the
+	// worker stores it as a NotificationAnnotation.
+	Annotations map[string]any `json:"-"`
+	// Category is one of the tenant's NotificationCategory names, such as
+	// billing or marketing. Users' opt-outs, quiet hours and frequency caps
+	// apply per category.
+	Category string `json:"category,omitempty"`
+	// GroupKey collapses related notifications to the same user and
+	// channel, such as repeated alerts for one incident. CollapsedInto is
+	// set on a notification the worker collapsed instead of sending: the
+	// ID of the notification delivered in its place.
+	GroupKey      string     `json:"group_key,omitempty"`
+	CollapsedInto *uuid.UUID `json:"collapsed_into,omitempty"`
+	Attempt       int        `json:"attempt"` // 8 bytes
+}
+
+// NotificationEvent is one state transition in a notification's timeline,
+// recorded by a trigger on notifications.
+type NotificationEvent struct {
+	ID             int64     `json:"id"`                    // 8 bytes
+	NotificationID uuid.UUID `json:"notification_id"`       // 16 bytes
+	OccurredAt     time.Time `json:"occurred_at"`           // 24 bytes
+	Error          *string   `json:"error,omitempty"`       // 8 bytes
+	FromStatus     string    `json:"from_status,omitempty"` // empty for the creation event
+	ToStatus       string    `json:"to_status"`
+	Actor          string    `json:"actor"` // one of the Actor* constants
+	Attempt        int       `json:"attempt"`
+}
+
+// NotificationAnnotation is provider-specific detail about a notification:
+// what a provider returned for one send attempt, or an event it reported
+// later. Data is a JSON object whose shape depends on the provider.
+type NotificationAnnotation struct {
+	ID             int64           `json:"id"`
+	NotificationID uuid.UUID       `json:"notification_id"`
+	RecordedAt     time.Time       `json:"recorded_at"`
+	Attempt        *int            `json:"attempt,omitempty"` // nil for provider events
+	Data           json.RawMessage `json:"data"`
+	Provider       string          `json:"provider"`
+	Kind           string          `json:"kind"` // AnnotationKindSend or a DeliveryEvent* type
+}
+
+// AnnotationKindSend marks an annotation recorded from a send's response.
+const AnnotationKindSend = "send"
+
+// Notification event actors
+const (
+	ActorTenant = "tenant" // an authenticated tenant request
+	ActorSystem = "system" // the worker, background jobs or an admin
+)
+
+// NotificationFilter narrows a tenant's notification list. Zero values mean
+// "don't filter".
+type NotificationFilter struct {
+	Sort ListSort
+	Tag  string // only notifications carrying this tag
+}
+
+// List sort fields, shared by the notification and DLQ lists
+const (
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	SortByStatus    = "status"
+)
+
+// ListSort orders a notification or DLQ list. The zero value is newest
+// first.
+type ListSort struct {
+	Field string // one of the SortBy constants; empty means created_at
+	Asc   bool
+}
+
+// ValidSortField reports whether field is one of the SortBy constants.
+func ValidSortField(field string) bool {
+	switch field {
+	case SortByCreatedAt, SortByUpdatedAt, SortByStatus:
+		return true
+	}
+	return false
+}
+
+// OrderBy returns the ORDER BY clause for s. Field is checked against the
+// SortBy constants, so the result is safe to build into a query; anything
+// else falls back to created_at. Status ties break on created_at so a page
+// boundary doesn't move between requests.
+func (s ListSort) OrderBy() string {
+	dir := " DESC"
+	if s.Asc {
+		dir = " ASC"
+	}
+	switch s.Field {
+	case SortByUpdatedAt:
+		return SortByUpdatedAt + dir
+	case SortByStatus:
+		return SortByStatus + dir + ", " + SortByCreatedAt + dir
+	default:
+		return SortByCreatedAt + dir
+	}
+}
+
+// RequeueFilter selects the notifications an operator requeue moves back to
+// pending. Status is required; empty fields match anything.
+type RequeueFilter struct {
+	TenantID      *uuid.UUID    // 8 bytes
+	OlderThan     time.Duration // 8 bytes, since the last update
+	Limit         int           // 8 bytes
+	Status        string        // 16 bytes, processing or failed
+	Channel       string
+	ErrorContains string // substring of error_message, case-sensitive
+}
+
+// NotificationEdit changes a pending notification. A nil Payload keeps the
+// current payload; SendAt replaces next_retry_at as given, so nil means
+// "send as soon as possible".
+type NotificationEdit struct {
+	Payload   json.RawMessage // 24 bytes
+	UpdatedAt time.Time       // 24 bytes, the version the caller read
+	SendAt    *time.Time      // 8 bytes
+	RequestID string          // 16 bytes, recorded in the audit row
+	TenantID  uuid.UUID       // 16 bytes, the owner; another tenant's row doesn't match
+}
+
+// StatusUpdate is one entry of a batch status update.
+type StatusUpdate struct {
+	Error   *string   // 8 bytes
+	Status  string    // 16 bytes
+	Attempt int       // 8 bytes
+	ID      uuid.UUID // 16 bytes
 }
 
 // Status constants
@@ -29,13 +175,31 @@ const (
 	StatusSent         = "sent"
 	StatusFailed       = "failed"
 	StatusDeadLettered = "dead_lettered"
+	// StatusHeld is a pending notification whose channel is killed; it goes
+	// back to pending when the channel's kill switch is lifted.
+	StatusHeld = "held"
+	// StatusFrequencyCapped is a notification never sent because its user
+	// had already had as many on the channel as the tenant's frequency cap
+	// allows. Caps that defer leave notifications pending instead.
+	StatusFrequencyCapped = "frequency_capped"
 )
 
+// CancelledError is the error of a notification cancelled before it was
+// sent. There is no cancelled status: a cancelled notification ends
+// 'failed' with this error, which its timeline shows.
+const CancelledError = "cancelled"
+
+// OptedOutError is the error of a notification not sent because its user
+// opted out of its category on its channel. Like a cancelled one, it ends
+// 'failed'.
+const OptedOutError = "opted out"
+
 // Channel constants
 const (
 	ChannelEmail   = "email"
 	ChannelSMS     = "sms"
 	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
 )
 
 // DLQ Status constants
@@ -45,10 +209,460 @@ const (
 	DLQStatusDiscarded = "discarded"
 )
 
+// DLQ reason codes classify why a notification was dead-lettered. The
+// worker picks one from the final error; last_error keeps the detail.
+const (
+	DLQReasonInvalidRecipient = "invalid_recipient" // the provider or endpoint rejected the address
+	DLQReasonProviderOutage   = "provider_outage"   // 5xx, throttling, or an open circuit breaker
+	DLQReasonTimeout          = "timeout"           // the send didn't finish in time
+	DLQReasonPayloadError     = "payload_error"     // the payload can't be sent as written
+	DLQReasonUnknown          = "unknown"           // anything else, including worker crashes
+)
+
+// ValidDLQReason reports whether reason is one of the DLQReason constants.
+func ValidDLQReason(reason string) bool {
+	switch reason {
+	case DLQReasonInvalidRecipient, DLQReasonProviderOutage, DLQReasonTimeout, DLQReasonPayloadError, DLQReasonUnknown:
+		return true
+	}
+	return false
+}
+
+// DeadLetterFilter narrows a tenant's DLQ list. Zero values mean "don't
+// filter".
+type DeadLetterFilter struct {
+	Sort   ListSort
+	Reason string // one of the DLQReason constants
+	Status string // one of the DLQStatus constants
+}
+
+// TenantChannelSettings holds a tenant's settings for one channel. Settings
+// is channel-specific JSON, e.g. SMSSettings for the sms channel.
+type TenantChannelSettings struct {
+	Settings  json.RawMessage `json:"settings"`  // 24 bytes
+	TenantID  uuid.UUID       `json:"tenant_id"` // 16 bytes
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Channel   string          `json:"channel"`
+}
+
+// TenantSettings are a tenant's API-wide settings, as opposed to the
+// per-channel delivery settings above. A tenant that never set them has the
+// zero value.
+type TenantSettings struct {
+	CreatedAt time.Time `json:"created_at"` // 24 bytes
+	UpdatedAt time.Time `json:"updated_at"`
+	TenantID  uuid.UUID `json:"tenant_id"` // 16 bytes
+	// RequireIdempotencyKey rejects creates without an Idempotency-Key
+	// header, so a client retry can never double-send.
+	RequireIdempotencyKey bool `json:"require_idempotency_key"`
+}
+
+// SMS message types, mapped onto AWS.SNS.SMS.SMSType by the SNS sender.
+const (
+	SMSTypeTransactional = "transactional"
+	SMSTypePromotional   = "promotional"
+)
+
+// SMSSettings is the settings shape for the sms channel. Empty fields fall
+// back to the account-level defaults configured at the provider.
+type SMSSettings struct {
+	SenderID          string `json:"sender_id,omitempty"`          // alphanumeric sender ID, where supported
+	OriginationNumber string `json:"origination_number,omitempty"` // E.164 long code, toll-free or short code
+	SMSType           string `json:"sms_type,omitempty"`           // transactional or promotional
+	DefaultCountry    string `json:"default_country,omitempty"`    // ISO 3166-1 alpha-2, for numbers without a country code
+}
+
+// EmailSettings is the settings shape for the email channel. They point the
+// tenant's email at its own verified SES identity so bounces, complaints and
+// reputation stay with its domain. Empty fields fall back to the
+// deployment's sender.
+type EmailSettings struct {
+	FromAddress      string `json:"from_address,omitempty"`      // verified address, or any address on a verified domain
+	MailFromDomain   string `json:"mail_from_domain,omitempty"`  // custom MAIL FROM subdomain set up on that identity
+	ConfigurationSet string `json:"configuration_set,omitempty"` // SES configuration set for events and IP pool
+}
+
+// WebhookSettings is the settings shape for the webhook channel. An empty
+// AllowedDomains allows any destination.
+type WebhookSettings struct {
+	// Hosts webhook URLs may target: "hooks.acme.com" matches that host
+	// exactly, "*.acme.com" matches any subdomain of acme.com.
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	// UserAgent replaces the default Nimbus User-Agent on deliveries.
+	UserAgent string `json:"user_agent,omitempty"`
+	// Headers are added to every delivery; a notification's own headers
+	// win. Values are stored sealed, since they often carry credentials.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// APIKey is a database-backed /v2 credential. Only the SHA-256 of the key is
+// stored; Prefix is its non-secret head, shown in lists to tell keys apart.
+type APIKey struct {
+	Scopes     []string   `json:"scopes"` // 24 bytes
+	ID         uuid.UUID  `json:"id"`     // 16 bytes
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	CreatedAt  time.Time  `json:"created_at"`           // 24 bytes
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // 8 bytes
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty"` // set when the key was rolled
+	Name       string     `json:"name"`                  // 16 bytes
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+}
+
+// Active reports whether the key can authenticate at t.
+func (k *APIKey) Active(t time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || t.Before(*k.ExpiresAt))
+}
+
+// TenantIPAllowlist restricts which source addresses may call /v2 with a
+// tenant's credentials. An empty CIDRs allows any address.
+type TenantIPAllowlist struct {
+	CIDRs     []string  `json:"cidrs"`     // 24 bytes
+	TenantID  uuid.UUID `json:"tenant_id"` // 16 bytes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TenantPause stops outbound delivery for a tenant until it is removed.
+type TenantPause struct {
+	Reason   string    `json:"reason"`    // 16 bytes
+	TenantID uuid.UUID `json:"tenant_id"` // 16 bytes
+	PausedAt time.Time `json:"paused_at"`
+}
+
+// ChannelKillSwitch disables delivery on a channel platform-wide.
+type ChannelKillSwitch struct {
+	KilledAt time.Time `json:"killed_at"` // 24 bytes
+	Channel  string    `json:"channel"`   // 16 bytes
+	Reason   string    `json:"reason"`
+}
+
+// DeliveryEvent is a delivery, bounce, complaint or engagement event a
+// provider reported for a message it accepted from us.
+type DeliveryEvent struct {
+	OccurredAt        time.Time // 24 bytes
+	Channel           string    // 16 bytes
+	Provider          string
+	ProviderMessageID string
+	Type              string // one of the DeliveryEvent* constants
+	BounceType        string // permanent, transient or undetermined; bounces only
+	Recipient         string
+}
+
+// Delivery event types
+const (
+	DeliveryEventDelivery  = "delivery"
+	DeliveryEventBounce    = "bounce"
+	DeliveryEventComplaint = "complaint"
+
+	// Reported by the SES configuration set's event destination. Only the
+	// first open and click of a message per recipient is kept.
+	DeliveryEventOpen             = "open"
+	DeliveryEventClick            = "click"
+	DeliveryEventRenderingFailure = "rendering_failure"
+)
+
+// BounceTypePermanent marks a hard bounce: the address doesn't exist or
+// will never accept mail. Only hard bounces count against reputation.
+const BounceTypePermanent = "permanent"
+
+// InvalidRecipient is an address a provider reported as permanently
+// undeliverable for a tenant. The worker skips sends to it until the
+// tenant reinstates it.
+type InvalidRecipient struct {
+	InvalidatedAt  time.Time  `json:"invalidated_at"`            // 24 bytes
+	NotificationID *uuid.UUID `json:"notification_id,omitempty"` // 8 bytes; the send that revealed it
+	TenantID       uuid.UUID  `json:"tenant_id"`                 // 16 bytes
+	Channel        string     `json:"channel"`                   // 16 bytes
+	Recipient      string     `json:"recipient"`
+	Reason         string     `json:"reason"` // one of the InvalidRecipient* constants
+	Detail         string     `json:"detail,omitempty"`
+}
+
+// Why a recipient was invalidated
+const (
+	InvalidRecipientHardBounce = "hard_bounce"       // the provider reported a permanent bounce
+	InvalidRecipientRejected   = "provider_rejected" // the provider refused the address at send time
+)
+
+// Recipient is a tenant's contact details for one of its users. Email and
+// SMS payloads that set "recipient_ref": "user" carry no address; the
+// worker fills it in from here at send time.
+type Recipient struct {
+	DeviceTokens []string  `json:"device_tokens"` // 24 bytes
+	CreatedAt    time.Time `json:"created_at"`    // 24 bytes
+	UpdatedAt    time.Time `json:"updated_at"`
+	TenantID     uuid.UUID `json:"tenant_id"` // 16 bytes
+	UserID       uuid.UUID `json:"user_id"`
+	Email        string    `json:"email"` // 16 bytes
+	Phone        string    `json:"phone"` // E.164
+	Locale       string    `json:"locale"`
+	Timezone     string    `json:"timezone"` // IANA name, e.g. Europe/Berlin
+}
+
+// RecipientRefUser is the "recipient_ref" payload value that addresses a
+// notification to its user's Recipient record.
+const RecipientRefUser = "user"
+
+// NormalizeRecipient puts recipient in the form invalid_recipients stores
+// it in. Email addresses are matched case-insensitively.
+func NormalizeRecipient(channel, recipient string) string {
+	recipient = strings.TrimSpace(recipient)
+	if channel == ChannelEmail {
+		return strings.ToLower(recipient)
+	}
+	return recipient
+}
+
+// TenantSendLimit restricts a tenant's sending on one channel.
+type TenantSendLimit struct {
+	Since    time.Time `json:"since"`   // 24 bytes
+	Channel  string    `json:"channel"` // 16 bytes
+	State    string    `json:"state"`
+	Reason   string    `json:"reason"`
+	TenantID uuid.UUID `json:"tenant_id"` // 16 bytes
+}
+
+// Send limit states
+const (
+	SendLimitThrottled = "throttled"
+	SendLimitPaused    = "paused"
+)
+
+// EmailWarmup is a tenant's email warm-up progress.
+type EmailWarmup struct {
+	StartedAt time.Time  `json:"started_at"`         // 24 bytes
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // 8 bytes
+	SentToday int        `json:"sent_today"`         // 8 bytes
+	TenantID  uuid.UUID  `json:"tenant_id"`          // 16 bytes
+}
+
+// DailyUsage is what a tenant sent on one channel on one UTC day.
+type DailyUsage struct {
+	Day     time.Time `json:"-"`       // 24 bytes
+	Channel string    `json:"channel"` // 16 bytes
+	Sent    int64     `json:"sent"`    // 8 bytes
+	Cost    float64   `json:"cost"`
+}
+
+// TenantBudget caps what a tenant means to spend or send in a UTC month.
+// At least one of MonthlyCost and MonthlyVolume is set.
+type TenantBudget struct {
+	CreatedAt     time.Time `json:"created_at"` // 24 bytes
+	UpdatedAt     time.Time `json:"updated_at"`
+	MonthlyCost   *float64  `json:"monthly_cost,omitempty"` // 8 bytes
+	MonthlyVolume *int64    `json:"monthly_volume,omitempty"`
+	AlertEmail    string    `json:"alert_email"` // 16 bytes
+	TenantID      uuid.UUID `json:"tenant_id"`
+	// AlertedPercent is the highest threshold alerted on this month.
+	AlertedPercent int  `json:"alerted_percent"`
+	BlockAtLimit   bool `json:"block_at_limit"` // hold non-critical sends at 100%
+}
+
+// FrequencyCap limits how many notifications one user gets on a channel
+// within a window, so a burst of events doesn't become a storm of
+// messages.
+// A cap with a Category counts and limits only that category's
+// notifications; one without caps the whole channel.
+type FrequencyCap struct {
+	CreatedAt     time.Time `json:"created_at"` // 24 bytes
+	UpdatedAt     time.Time `json:"updated_at"`
+	Channel       string    `json:"channel"` // 16 bytes
+	Category      string    `json:"category,omitempty"`
+	Action        string    `json:"action"`
+	TenantID      uuid.UUID `json:"tenant_id"`      // 16 bytes
+	MaxCount      int       `json:"max_count"`      // 8 bytes
+	WindowSeconds int       `json:"window_seconds"` // 8 bytes
+}
+
+// Frequency cap actions: what happens to a notification over the cap.
+const (
+	FrequencyCapDefer = "defer" // wait until the window has room
+	FrequencyCapDrop  = "drop"  // end it frequency_capped unsent
+)
+
+// NotificationCategory is one category of a tenant's taxonomy. A
+// notification may only carry a category its tenant has defined.
+type NotificationCategory struct {
+	CreatedAt time.Time `json:"created_at"` // 24 bytes
+	UpdatedAt time.Time `json:"updated_at"`
+	// QuietHours, when set, holds the category's notifications while it is
+	// quiet in the recipient's timezone.
+	QuietHours  *QuietHours `json:"quiet_hours,omitempty"` // 8 bytes
+	Name        string      `json:"name"`                  // 16 bytes
+	Description string      `json:"description"`
+	TenantID    uuid.UUID   `json:"tenant_id"` // 16 bytes
+	// Mandatory categories, such as security alerts, reach every user:
+	// opt-outs and quiet hours don't apply to them.
+	Mandatory bool `json:"mandatory"` // 1 byte
+}
+
+// QuietHours is a daily window of "HH:MM" local times. End before Start
+// wraps past midnight, so 21:00 to 08:00 is quiet overnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// CategoryOptOut is a user's opt-out of a category, on one channel or, with
+// no Channel, on every channel.
+type CategoryOptOut struct {
+	Category string `json:"category"`
+	Channel  string `json:"channel,omitempty"`
+}
+
+// CategoryStats counts a tenant's notifications of one category on one
+// channel in one status. Uncategorized notifications have no Category.
+type CategoryStats struct {
+	Category string `json:"category"` // 16 bytes
+	Channel  string `json:"channel"`
+	Status   string `json:"status"`
+	Count    int64  `json:"count"` // 8 bytes
+}
+
+// TenantRateLimit overrides the API rate limit for one tenant.
+type TenantRateLimit struct {
+	CreatedAt time.Time `json:"created_at"` // 24 bytes
+	UpdatedAt time.Time `json:"updated_at"`
+	// RetryAfterSeconds, when set, is the Retry-After hint on the tenant's
+	// 429s instead of the time until the window resets.
+	RetryAfterSeconds *int      `json:"retry_after_seconds,omitempty"` // 8 bytes
+	TenantID          uuid.UUID `json:"tenant_id"`                     // 16 bytes
+	Burst             int       `json:"burst"`                         // extra requests per window
+}
+
+// BudgetUsage is a tenant's budget with what it has used this month.
+type BudgetUsage struct {
+	Budget *TenantBudget
+	Sent   int64
+	Cost   float64
+}
+
+// TagCritical marks a notification that keeps sending when its tenant is
+// over budget, e.g. password resets.
+const TagCritical = "critical"
+
+// TenantReputation is a tenant's email outcomes over the reputation window.
+type TenantReputation struct {
+	Sent        int64 // 8 bytes
+	HardBounces int64
+	Complaints  int64
+	TenantID    uuid.UUID // 16 bytes
+}
+
+// Backlog is how much work is waiting across the platform: notifications
+// due to send and dead letters nobody has retried or discarded yet.
+type Backlog struct {
+	OldestDueAt *time.Time       `json:"oldest_due_at,omitempty"` // 8 bytes; nil when nothing is due
+	Channels    map[string]int64 `json:"channels"`                // due per channel; absent channels have none
+	Due         int64            `json:"due"`
+	DeadLetters int64            `json:"dead_letters"`
+}
+
+// AddDue folds one channel's due count and oldest due time into b. The
+// backends call it once per channel row.
+func (b *Backlog) AddDue(channel string, due int64, oldest time.Time) {
+	b.Channels[channel] = due
+	b.Due += due
+	if b.OldestDueAt == nil || oldest.Before(*b.OldestDueAt) {
+		b.OldestDueAt = &oldest
+	}
+}
+
+// QueueOverview is the platform-wide state of the notification queue and
+// what it finished over a recent window.
+type QueueOverview struct {
+	OldestDueAt       *time.Time           `json:"oldest_due_at,omitempty"` // 8 bytes
+	Channels          []*ChannelThroughput `json:"channels"`                // 24 bytes
+	TopFailingTenants []*TenantFailures    `json:"top_failing_tenants"`
+	Due               int64                `json:"due"`       // pending and ready to send
+	Scheduled         int64                `json:"scheduled"` // pending with a future next_retry_at
+	Processing        int64                `json:"processing"`
+	Held              int64                `json:"held"`
+}
+
+// ChannelThroughput is what one channel finished over the window.
+type ChannelThroughput struct {
+	Channel   string  `json:"channel"` // 16 bytes
+	Sent      int64   `json:"sent"`    // 8 bytes
+	Failed    int64   `json:"failed"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// TenantFailures is a tenant's failed notifications over the window.
+type TenantFailures struct {
+	TenantID  uuid.UUID `json:"tenant_id"` // 16 bytes
+	Failed    int64     `json:"failed"`    // 8 bytes
+	Finished  int64     `json:"finished"`
+	ErrorRate float64   `json:"error_rate"`
+}
+
+// JobRun is the lock and last-run record of one internal background job.
+type JobRun struct {
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"` // 8 bytes
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	LastDurationMS *int64     `json:"last_duration_ms,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	LockedBy       *string    `json:"locked_by,omitempty"`
+	Name           string     `json:"name"`      // 16 bytes
+	RunCount       int64      `json:"run_count"` // 8 bytes
+}
+
+// ShortLink maps a short code served at /r/{code} to the URL it replaced in
+// an outgoing message.
+type ShortLink struct {
+	TenantID       uuid.UUID `json:"tenant_id"` // 16 bytes
+	NotificationID uuid.UUID `json:"notification_id"`
+	CreatedAt      time.Time `json:"created_at"` // 24 bytes
+	Code           string    `json:"code"`       // 16 bytes
+	URL            string    `json:"url"`
+}
+
+// LinkClick is one redirect served for a short link.
+type LinkClick struct {
+	ID             uuid.UUID `json:"id"` // 16 bytes
+	TenantID       uuid.UUID `json:"tenant_id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	ClickedAt      time.Time `json:"clicked_at"` // 24 bytes
+	Code           string    `json:"code"`       // 16 bytes
+	UserAgent      string    `json:"user_agent"`
+	IPAddress      string    `json:"ip_address"`
+}
+
+// Template status constants
+const (
+	TemplateStatusDraft     = "draft"
+	TemplateStatusPublished = "published"
+)
+
+// Template is a tenant's email template. MJMLSource is what the tenant
+// authors; HTML is the compiled output, filled in when the template is
+// published and used verbatim by the worker.
+type Template struct {
+	ID          uuid.UUID  `json:"id"` // 16 bytes
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	CreatedAt   time.Time  `json:"created_at"` // 24 bytes
+	UpdatedAt   time.Time  `json:"updated_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"` // 8 bytes
+	Name        string     `json:"name"`                   // 16 bytes
+	Subject     string     `json:"subject"`
+	MJMLSource  string     `json:"mjml"`
+	TextBody    string     `json:"text,omitempty"`
+	HTML        string     `json:"html,omitempty"`
+	Status      string     `json:"status"`
+}
+
 // DeadLetterNotification represents a failed notification in the DLQ
 type DeadLetterNotification struct {
 	Payload                json.RawMessage `json:"payload"` // 24 bytes
-	ID                     uuid.UUID       `json:"id"`      // 16 bytes
+	Metadata               json.RawMessage `json:"metadata,omitempty"`
+	Tags                   []string        `json:"tags,omitempty"`
+	ID                     uuid.UUID       `json:"id"` // 16 bytes
 	OriginalNotificationID uuid.UUID       `json:"original_notification_id"`
 	TenantID               uuid.UUID       `json:"tenant_id"`
 	UserID                 uuid.UUID       `json:"user_id"`
@@ -57,6 +671,72 @@ type DeadLetterNotification struct {
 	RetriedNotificationID  *uuid.UUID      `json:"retried_notification_id,omitempty"` // 8 bytes
 	Channel                string          `json:"channel"`                           // 16 bytes
 	LastError              string          `json:"last_error"`
+	Reason                 string          `json:"reason"` // one of the DLQReason constants
 	Status                 string          `json:"status"`
+	CorrelationID          string          `json:"correlation_id,omitempty"`
 	Attempts               int             `json:"attempts"` // 8 bytes
+	// RetryCount is how many times the notification had already been
+	// retried out of the DLQ before landing here again.
+	RetryCount int `json:"retry_count"`
+}
+
+// DLQRetryPolicy retries a tenant's pending DLQ items with one reason code
+// automatically, once they have waited IntervalSeconds, until an item has
+// been retried MaxRetries times.
+type DLQRetryPolicy struct {
+	CreatedAt       time.Time `json:"created_at"` // 24 bytes
+	UpdatedAt       time.Time `json:"updated_at"`
+	Reason          string    `json:"reason"`    // 16 bytes
+	TenantID        uuid.UUID `json:"tenant_id"` // 16 bytes
+	IntervalSeconds int       `json:"interval_seconds"`
+	MaxRetries      int       `json:"max_retries"`
+}
+
+// CapturedDelivery is a message the worker would have sent while running in
+// sandbox mode. It is written instead of calling the real provider.
+type CapturedDelivery struct {
+	Payload        json.RawMessage `json:"payload"` // 24 bytes
+	ID             uuid.UUID       `json:"id"`      // 16 bytes
+	NotificationID uuid.UUID       `json:"notification_id"`
+	TenantID       uuid.UUID       `json:"tenant_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	CapturedAt     time.Time       `json:"captured_at"` // 24 bytes
+	Channel        string          `json:"channel"`     // 16 bytes
+	Recipient      string          `json:"recipient"`
+}
+
+// Import statuses. An import is completed once every row was attempted,
+// even if some failed; failed means the file itself couldn't be read.
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed"
+)
+
+// NotificationImport is a bulk import job: a CSV or JSONL file of
+// recipients turned into notifications in the background.
+type NotificationImport struct {
+	RowErrors   []ImportRowError `json:"row_errors"` // 24 bytes
+	ID          uuid.UUID        `json:"id"`         // 16 bytes
+	TenantID    uuid.UUID        `json:"tenant_id"`
+	CreatedAt   time.Time        `json:"created_at"` // 24 bytes
+	UpdatedAt   time.Time        `json:"updated_at"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty"` // 8 bytes
+	Error       *string          `json:"error,omitempty"`
+	Channel     string           `json:"channel"` // 16 bytes
+	Format      string           `json:"format"`
+	Source      string           `json:"source"` // "upload:<filename>" or "s3://<bucket>/<key>"
+	Status      string           `json:"status"`
+	TotalRows   int              `json:"total_rows"` // 8 bytes
+	CreatedRows int              `json:"created_rows"`
+	FailedRows  int              `json:"failed_rows"`
+}
+
+// ImportRowError explains why one row of an import created no
+// notification. Line is the 1-based line of the file, counting a CSV
+// header, so it matches what an editor or spreadsheet shows.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
 }
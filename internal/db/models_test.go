@@ -0,0 +1,22 @@
+package db
+
+import "testing"
+
+func TestListSort_OrderBy(t *testing.T) {
+	tests := []struct {
+		sort ListSort
+		want string
+	}{
+		{ListSort{}, "created_at DESC"},
+		{ListSort{Field: SortByCreatedAt, Asc: true}, "created_at ASC"},
+		{ListSort{Field: SortByUpdatedAt}, "updated_at DESC"},
+		{ListSort{Field: SortByStatus, Asc: true}, "status ASC, created_at ASC"},
+		{ListSort{Field: "id; DROP TABLE notifications"}, "created_at DESC"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sort.OrderBy(); got != tt.want {
+			t.Errorf("%+v: expected %q, got %q", tt.sort, tt.want, got)
+		}
+	}
+}
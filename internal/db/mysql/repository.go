@@ -0,0 +1,3508 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// errDuplicateEntry is the MySQL error number for a unique key failure.
+const errDuplicateEntry = 1062
+
+// notificationColumns is every notifications column a db.Notification
+// holds, in scanNotification's order.
+const notificationColumns = `
+	id, tenant_id, user_id, channel, payload,
+	status, attempt, error_message, next_retry_at,
+	created_at, updated_at, correlation_id, metadata, tags,
+	COALESCE(provider, ''), COALESCE(provider_message_id, ''), cost,
+	COALESCE(archive_key, ''), sla_seconds, sla_breached,
+	COALESCE(group_key, ''), collapsed_into, COALESCE(category, '')`
+
+// Repository implements db.Store on MySQL.
+//
+// MySQL has no RETURNING, so rows that are inserted or claimed are stamped
+// with the application's clock (see now) and the caller gets the values
+// written instead of reading them back.
+type Repository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+var _ db.Store = (*Repository)(nil)
+
+// NewRepository creates a new MySQL repository
+func NewRepository(database *DB, logger *zap.Logger) *Repository {
+	return &Repository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// now is the timestamp for rows written by this process, truncated to
+// the DATETIME(6) columns' precision so the value returned to the caller
+// is exactly the one stored.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNotification(row scanner) (*db.Notification, error) {
+	var notif db.Notification
+	err := row.Scan(
+		&notif.ID,
+		&notif.TenantID,
+		&notif.UserID,
+		&notif.Channel,
+		(*[]byte)(&notif.Payload),
+		&notif.Status,
+		&notif.Attempt,
+		&notif.ErrorMessage,
+		&notif.NextRetryAt,
+		&notif.CreatedAt,
+		&notif.UpdatedAt,
+		&notif.CorrelationID,
+		(*[]byte)(&notif.Metadata),
+		(*stringList)(&notif.Tags),
+		&notif.Provider,
+		&notif.ProviderMessageID,
+		&notif.Cost,
+		&notif.ArchiveKey,
+		&notif.SLASeconds,
+		&notif.SLABreached,
+		&notif.GroupKey,
+		&notif.CollapsedInto,
+		&notif.Category,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &notif, nil
+}
+
+func scanNotifications(rows *sql.Rows) ([]*db.Notification, error) {
+	defer rows.Close()
+
+	var notifications []*db.Notification
+	for rows.Next() {
+		notif, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		notifications = append(notifications, notif)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return notifications, nil
+}
+
+// CreateNotification inserts a new notification into the database. Its log
+// lines rely on the caller having scoped ctx's logger to the notification
+// (see observ.With).
+func (r *Repository) CreateNotification(ctx context.Context, notif *db.Notification) error {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	if err := insertNotification(ctx, s, notif); err != nil {
+		observ.Logger(ctx, r.logger).Error("failed to create notification", zap.Error(err))
+		return fmt.Errorf("insert notification: %w", err)
+	}
+
+	observ.Logger(ctx, r.logger).Info("notification created",
+		zap.String("channel", notif.Channel),
+	)
+
+	return nil
+}
+
+// execer is a session or a *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertNotification(ctx context.Context, q execer, notif *db.Notification) error {
+	query := `
+		INSERT INTO notifications (
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, next_retry_at, correlation_id,
+			metadata, tags, created_at, updated_at, sla_seconds, group_key, category
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+	`
+
+	ts := now()
+	_, err := q.ExecContext(ctx, query,
+		notif.ID,
+		notif.TenantID,
+		notif.UserID,
+		notif.Channel,
+		string(notif.Payload),
+		notif.Status,
+		notif.Attempt,
+		notif.NextRetryAt,
+		notif.CorrelationID,
+		jsonOrEmpty(notif.Metadata),
+		stringList(notif.Tags),
+		ts,
+		ts,
+		notif.SLASeconds,
+		notif.GroupKey,
+		notif.Category,
+	)
+	if err != nil {
+		return err
+	}
+	notif.CreatedAt, notif.UpdatedAt = ts, ts
+	return nil
+}
+
+// GetNotification retrieves a notification by ID
+func (r *Repository) GetNotification(ctx context.Context, id uuid.UUID) (*db.Notification, error) {
+	return r.getNotification(ctx, id, nil)
+}
+
+// GetNotificationForTenant retrieves a notification by ID only if it
+// belongs to tenantID; another tenant's notification is not found.
+func (r *Repository) GetNotificationForTenant(ctx context.Context, tenantID, id uuid.UUID) (*db.Notification, error) {
+	return r.getNotification(ctx, id, &tenantID)
+}
+
+// getNotification loads a notification, scoped to tenantID unless it is nil.
+func (r *Repository) getNotification(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE id = ? AND (? IS NULL OR tenant_id = ?)
+	`
+
+	notif, err := scanNotification(r.db.sql.QueryRowContext(ctx, query, id, tenantID, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("notification %w: %s", db.ErrNotFound, id)
+	}
+	if err != nil {
+		r.logger.Error("failed to get notification",
+			zap.Error(err),
+			zap.String("notification_id", id.String()),
+		)
+		return nil, fmt.Errorf("query notification: %w", err)
+	}
+
+	return notif, nil
+}
+
+// UpdateNotificationStatus updates the status and error message of a notification
+func (r *Repository) UpdateNotificationStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	status string,
+	attempt int,
+	errorMsg *string,
+	nextRetryAt *time.Time,
+) error {
+	return r.updateNotificationStatus(ctx, id, nil, status, attempt, errorMsg, nextRetryAt)
+}
+
+// UpdateNotificationStatusForTenant is UpdateNotificationStatus for a
+// notification that belongs to tenantID; another tenant's notification is
+// not found.
+func (r *Repository) UpdateNotificationStatusForTenant(
+	ctx context.Context,
+	tenantID, id uuid.UUID,
+	status string,
+	attempt int,
+	errorMsg *string,
+	nextRetryAt *time.Time,
+) error {
+	return r.updateNotificationStatus(ctx, id, &tenantID, status, attempt, errorMsg, nextRetryAt)
+}
+
+// updateNotificationStatus updates a notification's status, scoped to
+// tenantID unless it is nil.
+func (r *Repository) updateNotificationStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	tenantID *uuid.UUID,
+	status string,
+	attempt int,
+	errorMsg *string,
+	nextRetryAt *time.Time,
+) error {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	query := `
+		UPDATE notifications
+		SET status = ?, attempt = ?, error_message = ?, next_retry_at = ?
+		WHERE id = ? AND (? IS NULL OR tenant_id = ?)
+	`
+
+	result, err := s.ExecContext(ctx, query, status, attempt, errorMsg, nextRetryAt, id, tenantID, tenantID)
+	if err != nil {
+		r.logger.Error("failed to update notification status",
+			zap.Error(err),
+			zap.String("notification_id", id.String()),
+		)
+		return fmt.Errorf("update notification status: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("notification %w: %s", db.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// CancelPendingNotification ends a pending or held notification 'failed'
+// with db.CancelledError, so a scheduled send never fires. The status check
+// is part of the UPDATE, so it can't race the worker claiming the row.
+func (r *Repository) CancelPendingNotification(ctx context.Context, id uuid.UUID) error {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	result, err := s.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'failed', error_message = ?, next_retry_at = NULL
+		WHERE id = ? AND status IN ('pending', 'held')
+	`, db.CancelledError, id)
+	if err != nil {
+		return fmt.Errorf("cancel notification: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNotificationNotCancellable
+	}
+	return nil
+}
+
+// EditPendingNotification applies edit to a pending notification and records
+// the previous values in notification_edits, in one transaction. The row is
+// locked only while it is pending, still at edit.UpdatedAt and owned by
+// edit.TenantID, so the edit can't race the worker claiming it or another
+// edit.
+func (r *Repository) EditPendingNotification(ctx context.Context, id uuid.UUID, edit db.NotificationEdit) (*db.Notification, error) {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	tx, err := s.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var oldPayload []byte
+	var oldSendAt *time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT payload, next_retry_at
+		FROM notifications
+		WHERE id = ? AND tenant_id = ? AND status = 'pending' AND updated_at = ?
+		FOR UPDATE
+	`, id, edit.TenantID, edit.UpdatedAt.UTC()).Scan(&oldPayload, &oldSendAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNotificationNotEditable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock notification: %w", err)
+	}
+
+	newPayload := edit.Payload
+	if newPayload == nil {
+		newPayload = oldPayload
+	}
+
+	// updated_at is set explicitly: ON UPDATE CURRENT_TIMESTAMP skips rows
+	// whose values didn't change, and every edit must move the version.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE notifications
+		SET payload = ?, next_retry_at = ?, updated_at = ?
+		WHERE id = ? AND tenant_id = ?
+	`, string(newPayload), edit.SendAt, now(), id, edit.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("update notification: %w", err)
+	}
+
+	notif, err := scanNotification(tx.QueryRowContext(ctx,
+		`SELECT `+notificationColumns+` FROM notifications WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("update notification: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO notification_edits (
+			notification_id, old_payload, new_payload, old_send_at, new_send_at, request_id
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, id, string(oldPayload), string(newPayload), oldSendAt, edit.SendAt, edit.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("insert notification edit: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	observ.Logger(ctx, r.logger).Info("pending notification edited",
+		zap.String("notification_id", id.String()),
+		zap.Bool("payload_changed", edit.Payload != nil),
+	)
+
+	return notif, nil
+}
+
+// UpdateNotificationStatuses applies a batch of status updates and returns
+// the IDs that matched a notification. IDs missing from the result don't
+// exist. Like UpdateNotificationStatus it clears next_retry_at. IDs must be
+// unique within the batch.
+//
+// MySQL can't join an UPDATE to an array parameter, so the batch is one
+// locking SELECT for the IDs that exist and one UPDATE with a CASE per
+// column.
+func (r *Repository) UpdateNotificationStatuses(ctx context.Context, updates []db.StatusUpdate) ([]uuid.UUID, error) {
+	return r.updateNotificationStatuses(ctx, updates, nil)
+}
+
+// UpdateNotificationStatusesForTenant is UpdateNotificationStatuses limited
+// to tenantID's notifications; another tenant's IDs are left out of the
+// result as if they didn't exist.
+func (r *Repository) UpdateNotificationStatusesForTenant(ctx context.Context, tenantID uuid.UUID, updates []db.StatusUpdate) ([]uuid.UUID, error) {
+	return r.updateNotificationStatuses(ctx, updates, &tenantID)
+}
+
+// updateNotificationStatuses applies a batch of status updates, scoped to
+// tenantID unless it is nil.
+func (r *Repository) updateNotificationStatuses(ctx context.Context, updates []db.StatusUpdate, tenantID *uuid.UUID) ([]uuid.UUID, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	ids := make([]any, len(updates))
+	var statusCase, attemptCase, errorCase strings.Builder
+	var statusArgs, attemptArgs, errorArgs []any
+	for i, u := range updates {
+		ids[i] = u.ID
+		statusCase.WriteString(" WHEN ? THEN ?")
+		statusArgs = append(statusArgs, u.ID, u.Status)
+		attemptCase.WriteString(" WHEN ? THEN ?")
+		attemptArgs = append(attemptArgs, u.ID, u.Attempt)
+		errorCase.WriteString(" WHEN ? THEN ?")
+		errorArgs = append(errorArgs, u.ID, u.Error)
+	}
+	in := placeholders(len(updates))
+
+	tx, err := s.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id FROM notifications WHERE id IN (`+in+`) AND (? IS NULL OR tenant_id = ?) FOR UPDATE`,
+		append(ids, tenantID, tenantID)...)
+	if err != nil {
+		r.logger.Error("failed to batch update notification status",
+			zap.Error(err),
+			zap.Int("count", len(updates)),
+		)
+		return nil, fmt.Errorf("batch update notification status: %w", err)
+	}
+	updated := make([]uuid.UUID, 0, len(updates))
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan updated id: %w", err)
+		}
+		updated = append(updated, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("batch update notification status: %w", err)
+	}
+	if len(updated) == 0 {
+		return updated, nil
+	}
+
+	query := `
+		UPDATE notifications
+		SET status = CASE id` + statusCase.String() + ` END,
+		    attempt = CASE id` + attemptCase.String() + ` END,
+		    error_message = CASE id` + errorCase.String() + ` END,
+		    next_retry_at = NULL
+		WHERE id IN (` + in + `) AND (? IS NULL OR tenant_id = ?)
+	`
+	args := append(append(append(statusArgs, attemptArgs...), errorArgs...), ids...)
+	args = append(args, tenantID, tenantID)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		r.logger.Error("failed to batch update notification status",
+			zap.Error(err),
+			zap.Int("count", len(updates)),
+		)
+		return nil, fmt.Errorf("batch update notification status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
+// MarkNotificationSent records a successful send: status 'sent', the attempt
+// count, the provider's message ID when the sender reported one, the archive
+// key when the rendered message was archived, and the estimated cost, which
+// is also added to the tenant's usage for the day.
+func (r *Repository) MarkNotificationSent(ctx context.Context, id uuid.UUID, attempt int, provider, providerMessageID, archiveKey string, cost float64) error {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	tx, err := s.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'sent', attempt = ?, error_message = NULL, next_retry_at = NULL,
+		    provider = NULLIF(?, ''), provider_message_id = NULLIF(?, ''), cost = ?,
+		    archive_key = NULLIF(?, ''),
+		    sla_breached = IF(sla_seconds IS NULL, NULL, ? > created_at + INTERVAL sla_seconds SECOND)
+		WHERE id = ?
+	`, attempt, provider, providerMessageID, cost, archiveKey, now(), id)
+	if err != nil {
+		observ.Logger(ctx, r.logger).Error("failed to mark notification sent",
+			zap.Error(err),
+			zap.String("notification_id", id.String()),
+		)
+		return fmt.Errorf("mark notification sent: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("notification not found: %s", id)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tenant_daily_usage (tenant_id, day, channel, sent, cost)
+		SELECT tenant_id, UTC_DATE(), channel, 1, ?
+		FROM notifications
+		WHERE id = ?
+		ON DUPLICATE KEY UPDATE sent = tenant_daily_usage.sent + 1, cost = tenant_daily_usage.cost + ?
+	`, cost, id, cost)
+	if err != nil {
+		return fmt.Errorf("mark notification sent: record usage: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CollapseNotification collapses notif into its group's latest delivery, if
+// there is one: a notification with the same tenant, user, channel and
+// group key, created before notif but no more than window before it, that
+// was sent rather than itself collapsed. notif is then marked sent with
+// collapsed_into pointing at it, and its ID is returned. If there is none,
+// notif is left alone and the result is nil.
+func (r *Repository) CollapseNotification(ctx context.Context, notif *db.Notification, attempt int, window time.Duration) (*uuid.UUID, error) {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	var into uuid.UUID
+	err = s.QueryRowContext(ctx, `
+		SELECT id
+		FROM notifications
+		WHERE tenant_id = ? AND user_id = ? AND channel = ? AND group_key = ?
+		  AND status = 'sent' AND collapsed_into IS NULL
+		  AND created_at >= ? AND (created_at, id) < (?, ?)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, notif.TenantID, notif.UserID, notif.Channel, notif.GroupKey,
+		notif.CreatedAt.Add(-window), notif.CreatedAt, notif.ID).Scan(&into)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find group delivery: %w", err)
+	}
+
+	_, err = s.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'sent', attempt = ?, error_message = NULL, next_retry_at = NULL,
+		    collapsed_into = ?,
+		    sla_breached = IF(sla_seconds IS NULL, NULL, ? > created_at + INTERVAL sla_seconds SECOND)
+		WHERE id = ?
+	`, attempt, into, now(), notif.ID)
+	if err != nil {
+		return nil, fmt.Errorf("collapse notification: %w", err)
+	}
+	return &into, nil
+}
+
+// GetNotificationByProviderMessageID finds the notification a provider
+// message ID belongs to. An empty provider matches any provider; if an ID
+// is somehow reused across providers the most recent notification wins.
+func (r *Repository) GetNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*db.Notification, error) {
+	return r.getNotificationByProviderMessageID(ctx, provider, providerMessageID, nil)
+}
+
+// GetNotificationByProviderMessageIDForTenant is
+// GetNotificationByProviderMessageID limited to tenantID's notifications;
+// another tenant's notification is not found.
+func (r *Repository) GetNotificationByProviderMessageIDForTenant(ctx context.Context, tenantID uuid.UUID, provider, providerMessageID string) (*db.Notification, error) {
+	return r.getNotificationByProviderMessageID(ctx, provider, providerMessageID, &tenantID)
+}
+
+// getNotificationByProviderMessageID looks up a provider message ID, scoped
+// to tenantID unless it is nil.
+func (r *Repository) getNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string, tenantID *uuid.UUID) (*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE provider_message_id = ? AND (? = '' OR provider = ?)
+		  AND (? IS NULL OR tenant_id = ?)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	notif, err := scanNotification(r.db.sql.QueryRowContext(ctx, query, providerMessageID, provider, provider, tenantID, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("notification %w for provider message id: %s", db.ErrNotFound, providerMessageID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query notification by provider message id: %w", err)
+	}
+
+	return notif, nil
+}
+
+// ListNotificationsByTenant retrieves notifications for a tenant with pagination
+func (r *Repository) ListNotificationsByTenant(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	filter db.NotificationFilter,
+	limit int,
+	offset int,
+) ([]*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if filter.Tag != "" {
+		// MEMBER OF can use the multi-valued index on tags.
+		query += ` AND ? MEMBER OF (tags)`
+		args = append(args, filter.Tag)
+	}
+	query += `
+		ORDER BY ` + filter.Sort.OrderBy() + `
+		LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.db.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query notifications: %w", err)
+	}
+	return scanNotifications(rows)
+}
+
+// CountNotificationsByTenant counts the notifications ListNotificationsByTenant
+// pages through with the same filter.
+func (r *Repository) CountNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter db.NotificationFilter) (int64, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if filter.Tag != "" {
+		query += ` AND ? MEMBER OF (tags)`
+		args = append(args, filter.Tag)
+	}
+
+	var total int64
+	if err := r.db.sql.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count notifications: %w", err)
+	}
+	return total, nil
+}
+
+// SearchNotificationsByUser returns a user's most recent notifications
+// across every tenant, newest first, for operator investigations.
+func (r *Repository) SearchNotificationsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query notifications by user: %w", err)
+	}
+	return scanNotifications(rows)
+}
+
+// ListNotificationEvents returns a notification's state transitions, oldest
+// first.
+func (r *Repository) ListNotificationEvents(ctx context.Context, notificationID uuid.UUID) ([]*db.NotificationEvent, error) {
+	query := `
+		SELECT id, notification_id, COALESCE(from_status, ''), to_status,
+			attempt, error_message, actor, occurred_at
+		FROM notification_events
+		WHERE notification_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("query notification events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*db.NotificationEvent
+	for rows.Next() {
+		var e db.NotificationEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.NotificationID,
+			&e.FromStatus,
+			&e.ToStatus,
+			&e.Attempt,
+			&e.Error,
+			&e.Actor,
+			&e.OccurredAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan notification event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification events: %w", err)
+	}
+	return events, nil
+}
+
+// AddNotificationAnnotation records a's detail on its notification.
+func (r *Repository) AddNotificationAnnotation(ctx context.Context, a *db.NotificationAnnotation) error {
+	recordedAt := now()
+	result, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO notification_annotations (notification_id, tenant_id, provider, kind, attempt, data, recorded_at)
+		SELECT id, tenant_id, ?, ?, ?, ?, ?
+		FROM notifications
+		WHERE id = ?
+	`, a.Provider, a.Kind, a.Attempt, jsonOrEmpty(a.Data), recordedAt, a.NotificationID)
+	if err != nil {
+		return fmt.Errorf("add notification annotation: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("add notification annotation: notification %s not found", a.NotificationID)
+	}
+	if a.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("add notification annotation: %w", err)
+	}
+	a.RecordedAt = recordedAt
+	return nil
+}
+
+// AnnotateByProviderMessageID records a on the notification a.Provider sent
+// as providerMessageID, reporting false if there is none (e.g. it was sent
+// by another deployment sharing the provider account).
+func (r *Repository) AnnotateByProviderMessageID(ctx context.Context, providerMessageID string, a *db.NotificationAnnotation) (bool, error) {
+	err := r.db.sql.QueryRowContext(ctx, `
+		SELECT id
+		FROM notifications
+		WHERE provider_message_id = ? AND provider = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, providerMessageID, a.Provider).Scan(&a.NotificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("annotate by provider message id: %w", err)
+	}
+	if err := r.AddNotificationAnnotation(ctx, a); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListNotificationAnnotations returns a notification's provider
+// annotations, oldest first.
+func (r *Repository) ListNotificationAnnotations(ctx context.Context, notificationID uuid.UUID) ([]*db.NotificationAnnotation, error) {
+	query := `
+		SELECT id, notification_id, provider, kind, attempt, data, recorded_at
+		FROM notification_annotations
+		WHERE notification_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("query notification annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []*db.NotificationAnnotation
+	for rows.Next() {
+		var a db.NotificationAnnotation
+		if err := rows.Scan(
+			&a.ID,
+			&a.NotificationID,
+			&a.Provider,
+			&a.Kind,
+			&a.Attempt,
+			(*[]byte)(&a.Data),
+			&a.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan notification annotation: %w", err)
+		}
+		annotations = append(annotations, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// GetPendingNotifications lists due pending notifications, oldest first,
+// without claiming them.
+func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW(6))
+		ORDER BY created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query pending notifications: %w", err)
+	}
+	return scanNotifications(rows)
+}
+
+// claimable is the condition a pending notification must meet to be
+// claimed: due, and not held back by a tenant pause, channel kill switch
+// or reputation pause.
+const claimable = `
+	n.status = 'pending' AND (n.next_retry_at IS NULL OR n.next_retry_at <= NOW(6))
+	AND NOT EXISTS (
+		SELECT 1 FROM tenant_delivery_pauses p
+		WHERE p.tenant_id = n.tenant_id
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM channel_kill_switches k
+		WHERE k.channel = n.channel
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM tenant_send_limits l
+		WHERE l.tenant_id = n.tenant_id
+		  AND l.channel = n.channel
+		  AND l.state = 'paused' AND l.lifted_at IS NULL
+	)`
+
+// ClaimPendingNotifications atomically claims a batch of notifications for
+// processing, so that several worker replicas each get a disjoint batch.
+//
+// Postgres does this in one UPDATE ... WHERE id IN (SELECT ... FOR UPDATE
+// SKIP LOCKED) RETURNING statement. MySQL has neither RETURNING nor
+// UPDATEs that select from their own table, so the claim is a transaction:
+// SELECT ... FOR UPDATE SKIP LOCKED locks a batch no other worker holds,
+// then an UPDATE marks those rows 'processing' before the locks are
+// released on commit. A concurrent claimer skips the locked rows and, once
+// they're committed, no longer sees them as pending. Crashed workers'
+// rows are recovered by ClaimStuckNotifications, as on Postgres.
+func (r *Repository) ClaimPendingNotifications(ctx context.Context, limit int) ([]*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications n
+		WHERE ` + claimable + `
+		ORDER BY n.created_at ASC
+		LIMIT ?
+		FOR UPDATE OF n SKIP LOCKED
+	`
+
+	claimed, err := r.claim(ctx, db.StatusProcessing, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim pending notifications: %w", err)
+	}
+	return claimed, nil
+}
+
+// ClaimNotification claims a single notification for the given channel, as
+// ClaimPendingNotifications would, for consumers told about it by a queue
+// message. It returns nil when the row is not claimable: already sent or
+// claimed by the poller, not yet due, or held by a pause or kill switch.
+func (r *Repository) ClaimNotification(ctx context.Context, id uuid.UUID, channel string) (*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications n
+		WHERE n.id = ? AND n.channel = ? AND ` + claimable + `
+		FOR UPDATE OF n SKIP LOCKED
+	`
+
+	claimed, err := r.claim(ctx, db.StatusProcessing, query, id, channel)
+	if err != nil {
+		return nil, fmt.Errorf("claim notification: %w", err)
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+	return claimed[0], nil
+}
+
+// ClaimStuckNotifications claims up to limit rows that have sat in
+// 'processing' for longer than olderThan, which means the worker that claimed
+// them died mid-send. The rows stay 'processing' but their updated_at is
+// bumped, so concurrent reapers on other replicas skip them; the caller then
+// reschedules or dead-letters each one.
+//
+// olderThan must be comfortably larger than the longest possible single send
+// (SES/SNS/webhook timeout + retries), otherwise we'd reap a row that's still
+// being legitimately worked on and double-send it.
+func (r *Repository) ClaimStuckNotifications(ctx context.Context, olderThan time.Duration, limit int) ([]*db.Notification, error) {
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications n
+		WHERE n.status = 'processing' AND n.updated_at < NOW(6) - INTERVAL ? SECOND
+		ORDER BY n.updated_at ASC
+		LIMIT ?
+		FOR UPDATE OF n SKIP LOCKED
+	`
+
+	claimed, err := r.claim(ctx, "", query, int(olderThan.Seconds()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim stuck notifications: %w", err)
+	}
+	return claimed, nil
+}
+
+// claim runs query, a SELECT ... FOR UPDATE SKIP LOCKED of
+// notificationColumns, and stamps the rows it locked with a new updated_at
+// and, unless status is empty, status, in one transaction.
+func (r *Repository) claim(ctx context.Context, status, query string, args ...any) ([]*db.Notification, error) {
+	tx, err := r.db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := scanNotifications(rows)
+	if err != nil || len(claimed) == 0 {
+		return nil, err
+	}
+
+	ts := now()
+	ids := make([]any, len(claimed))
+	for i, notif := range claimed {
+		ids[i] = notif.ID
+		notif.UpdatedAt = ts
+		if status != "" {
+			notif.Status = status
+		}
+	}
+
+	update := `UPDATE notifications SET updated_at = ?, status = COALESCE(NULLIF(?, ''), status)
+		WHERE id IN (` + placeholders(len(ids)) + `)`
+	if _, err := tx.ExecContext(ctx, update, append([]any{ts, status}, ids...)...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return claimed, nil
+}
+
+// MoveToDeadLetter moves a failed notification to the dead letter queue. Like
+// CreateNotification, it logs through ctx's scoped logger.
+func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *db.Notification, reason, lastError string) (*db.DeadLetterNotification, error) {
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	tx, err := s.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ts := now()
+	dlq := &db.DeadLetterNotification{
+		ID:                     uuid.New(),
+		OriginalNotificationID: notif.ID,
+		TenantID:               notif.TenantID,
+		UserID:                 notif.UserID,
+		Channel:                notif.Channel,
+		Payload:                notif.Payload,
+		Attempts:               notif.Attempt,
+		LastError:              lastError,
+		Reason:                 reason,
+		Status:                 db.DLQStatusPending,
+		CorrelationID:          notif.CorrelationID,
+		Metadata:               notif.Metadata,
+		Tags:                   notif.Tags,
+		CreatedAt:              ts,
+		UpdatedAt:              ts,
+	}
+
+	// A notification created by a DLQ retry carries that entry's count on,
+	// so retry policies know when to stop.
+	err = tx.QueryRowContext(ctx, `
+		SELECT retry_count + 1 FROM dead_letter_notifications WHERE retried_notification_id = ?
+	`, notif.ID).Scan(&dlq.RetryCount)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("query retry count: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO dead_letter_notifications (
+			id, original_notification_id, tenant_id, user_id, channel,
+			payload, attempts, last_error, status, correlation_id,
+			metadata, tags, created_at, updated_at, reason, retry_count
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		dlq.ID,
+		dlq.OriginalNotificationID,
+		dlq.TenantID,
+		dlq.UserID,
+		dlq.Channel,
+		string(dlq.Payload),
+		dlq.Attempts,
+		dlq.LastError,
+		dlq.Status,
+		dlq.CorrelationID,
+		jsonOrEmpty(dlq.Metadata),
+		stringList(dlq.Tags),
+		ts,
+		ts,
+		dlq.Reason,
+		dlq.RetryCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert dead letter: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = ?, sla_breached = IF(sla_seconds IS NULL, NULL, TRUE)
+		WHERE id = ?
+	`, db.StatusDeadLettered, notif.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update notification status: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	observ.Logger(ctx, r.logger).Info("notification moved to dead letter queue",
+		zap.String("dlq_id", dlq.ID.String()),
+		zap.String("reason", reason),
+		zap.String("last_error", lastError),
+	)
+
+	return dlq, nil
+}
+
+const deadLetterColumns = `
+	id, original_notification_id, tenant_id, user_id, channel,
+	payload, attempts, last_error, status, retried_notification_id,
+	created_at, updated_at, correlation_id, metadata, tags, reason,
+	retry_count`
+
+func scanDeadLetter(row scanner) (*db.DeadLetterNotification, error) {
+	var dlq db.DeadLetterNotification
+	err := row.Scan(
+		&dlq.ID,
+		&dlq.OriginalNotificationID,
+		&dlq.TenantID,
+		&dlq.UserID,
+		&dlq.Channel,
+		(*[]byte)(&dlq.Payload),
+		&dlq.Attempts,
+		&dlq.LastError,
+		&dlq.Status,
+		&dlq.RetriedNotificationID,
+		&dlq.CreatedAt,
+		&dlq.UpdatedAt,
+		&dlq.CorrelationID,
+		(*[]byte)(&dlq.Metadata),
+		(*stringList)(&dlq.Tags),
+		&dlq.Reason,
+		&dlq.RetryCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &dlq, nil
+}
+
+// ListDeadLetterByTenant retrieves DLQ items for a tenant
+func (r *Repository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter, limit, offset int) ([]*db.DeadLetterNotification, error) {
+	query := `SELECT ` + deadLetterColumns + `
+		FROM dead_letter_notifications
+		WHERE tenant_id = ?
+		  AND (? = '' OR reason = ?)
+		  AND (? = '' OR status = ?)
+		ORDER BY ` + filter.Sort.OrderBy() + `
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID,
+		filter.Reason, filter.Reason, filter.Status, filter.Status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query dead letter notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*db.DeadLetterNotification
+	for rows.Next() {
+		dlq, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan dead letter: %w", err)
+		}
+		items = append(items, dlq)
+	}
+
+	return items, rows.Err()
+}
+
+// CountDeadLetterByTenant counts the DLQ items ListDeadLetterByTenant pages
+// through with the same filter.
+func (r *Repository) CountDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter db.DeadLetterFilter) (int64, error) {
+	var total int64
+	err := r.db.sql.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM dead_letter_notifications
+		WHERE tenant_id = ?
+		  AND (? = '' OR reason = ?)
+		  AND (? = '' OR status = ?)
+	`, tenantID, filter.Reason, filter.Reason, filter.Status, filter.Status).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("count dead letter notifications: %w", err)
+	}
+	return total, nil
+}
+
+// GetDeadLetter retrieves a single DLQ item by ID
+func (r *Repository) GetDeadLetter(ctx context.Context, id uuid.UUID) (*db.DeadLetterNotification, error) {
+	return r.getDeadLetter(ctx, id, nil)
+}
+
+// GetDeadLetterForTenant retrieves a DLQ item by ID only if it belongs to
+// tenantID, like GetNotificationForTenant.
+func (r *Repository) GetDeadLetterForTenant(ctx context.Context, tenantID, id uuid.UUID) (*db.DeadLetterNotification, error) {
+	return r.getDeadLetter(ctx, id, &tenantID)
+}
+
+// getDeadLetter loads a DLQ item, scoped to tenantID unless it is nil.
+func (r *Repository) getDeadLetter(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*db.DeadLetterNotification, error) {
+	query := `SELECT ` + deadLetterColumns + `
+		FROM dead_letter_notifications
+		WHERE id = ? AND (? IS NULL OR tenant_id = ?)
+	`
+
+	dlq, err := scanDeadLetter(r.db.sql.QueryRowContext(ctx, query, id, tenantID, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("dead letter %w: %s", db.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query dead letter: %w", err)
+	}
+
+	return dlq, nil
+}
+
+// RetryDeadLetter creates a new notification from a DLQ item and marks it as retried
+func (r *Repository) RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*db.Notification, error) {
+	dlq, err := r.GetDeadLetter(ctx, dlqID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dlq.Status != db.DLQStatusPending {
+		return nil, fmt.Errorf("dead letter already processed: %s", dlq.Status)
+	}
+
+	s, release, err := r.db.session(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer release()
+
+	tx, err := s.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The new notification keeps the original correlation ID so the replay
+	// shows up under the same trace as the failed attempts.
+	newNotif := &db.Notification{
+		ID:            uuid.New(),
+		TenantID:      dlq.TenantID,
+		UserID:        dlq.UserID,
+		Channel:       dlq.Channel,
+		Payload:       dlq.Payload,
+		Status:        db.StatusPending,
+		Attempt:       0,
+		CorrelationID: dlq.CorrelationID,
+		Metadata:      dlq.Metadata,
+		Tags:          dlq.Tags,
+	}
+	if err := insertNotification(ctx, tx, newNotif); err != nil {
+		return nil, fmt.Errorf("insert retry notification: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE dead_letter_notifications
+		SET status = ?, retried_notification_id = ?
+		WHERE id = ?
+	`, db.DLQStatusRetried, newNotif.ID, dlqID)
+	if err != nil {
+		return nil, fmt.Errorf("update dead letter: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	r.logger.Info("dead letter retried",
+		zap.String("dlq_id", dlqID.String()),
+		zap.String("new_notification_id", newNotif.ID.String()),
+		zap.String("correlation_id", newNotif.CorrelationID),
+	)
+
+	return newNotif, nil
+}
+
+// DiscardDeadLetter marks a DLQ item as discarded (won't be retried)
+func (r *Repository) DiscardDeadLetter(ctx context.Context, dlqID uuid.UUID) error {
+	query := `
+		UPDATE dead_letter_notifications
+		SET status = ?
+		WHERE id = ? AND status = ?
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query, db.DLQStatusDiscarded, dlqID, db.DLQStatusPending)
+	if err != nil {
+		return fmt.Errorf("discard dead letter: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("dead letter not found or already processed")
+	}
+
+	r.logger.Info("dead letter discarded", zap.String("dlq_id", dlqID.String()))
+
+	return nil
+}
+
+// CaptureDelivery records a message in the sandbox test inbox instead of
+// delivering it to a real provider.
+func (r *Repository) CaptureDelivery(ctx context.Context, delivery *db.CapturedDelivery) error {
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO captured_deliveries (
+			id, notification_id, tenant_id, user_id, channel, recipient, payload, captured_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ts := now()
+	_, err := r.db.sql.ExecContext(ctx, query,
+		delivery.ID,
+		delivery.NotificationID,
+		delivery.TenantID,
+		delivery.UserID,
+		delivery.Channel,
+		delivery.Recipient,
+		string(delivery.Payload),
+		ts,
+	)
+	if err != nil {
+		return fmt.Errorf("insert captured delivery: %w", err)
+	}
+	delivery.CapturedAt = ts
+
+	return nil
+}
+
+// ListCapturedDeliveries returns a tenant's captured deliveries, newest first.
+// An empty channel matches every channel.
+func (r *Repository) ListCapturedDeliveries(ctx context.Context, tenantID uuid.UUID, channel string, limit int) ([]*db.CapturedDelivery, error) {
+	query := `
+		SELECT
+			id, notification_id, tenant_id, user_id, channel,
+			recipient, payload, captured_at
+		FROM captured_deliveries
+		WHERE tenant_id = ? AND (? = '' OR channel = ?)
+		ORDER BY captured_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID, channel, channel, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query captured deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*db.CapturedDelivery
+	for rows.Next() {
+		var d db.CapturedDelivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.NotificationID,
+			&d.TenantID,
+			&d.UserID,
+			&d.Channel,
+			&d.Recipient,
+			(*[]byte)(&d.Payload),
+			&d.CapturedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan captured delivery: %w", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// ClearCapturedDeliveries empties a tenant's test inbox so each integration
+// test can start from a known state. Returns the number of rows removed.
+func (r *Repository) ClearCapturedDeliveries(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM captured_deliveries WHERE tenant_id = ?`, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("clear captured deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CreateTemplate inserts a new draft template.
+func (r *Repository) CreateTemplate(ctx context.Context, tmpl *db.Template) error {
+	if tmpl.ID == uuid.Nil {
+		tmpl.ID = uuid.New()
+	}
+	tmpl.Status = db.TemplateStatusDraft
+
+	query := `
+		INSERT INTO templates (
+			id, tenant_id, name, subject, mjml_source, text_body, html, status, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, '', ?, ?, ?)
+	`
+
+	ts := now()
+	_, err := r.db.sql.ExecContext(ctx, query,
+		tmpl.ID,
+		tmpl.TenantID,
+		tmpl.Name,
+		tmpl.Subject,
+		tmpl.MJMLSource,
+		tmpl.TextBody,
+		tmpl.Status,
+		ts,
+		ts,
+	)
+	if isDuplicateEntry(err) {
+		return db.ErrTemplateNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("insert template: %w", err)
+	}
+	tmpl.CreatedAt, tmpl.UpdatedAt = ts, ts
+
+	return nil
+}
+
+// GetTemplate retrieves a template by ID.
+func (r *Repository) GetTemplate(ctx context.Context, id uuid.UUID) (*db.Template, error) {
+	query := `
+		SELECT
+			id, tenant_id, name, subject, mjml_source, text_body,
+			html, status, published_at, created_at, updated_at
+		FROM templates
+		WHERE id = ?
+	`
+
+	var tmpl db.Template
+	err := r.db.sql.QueryRowContext(ctx, query, id).Scan(
+		&tmpl.ID,
+		&tmpl.TenantID,
+		&tmpl.Name,
+		&tmpl.Subject,
+		&tmpl.MJMLSource,
+		&tmpl.TextBody,
+		&tmpl.HTML,
+		&tmpl.Status,
+		&tmpl.PublishedAt,
+		&tmpl.CreatedAt,
+		&tmpl.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("template not found: %s", id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query template: %w", err)
+	}
+
+	return &tmpl, nil
+}
+
+// PublishTemplate stores the compiled HTML for a template and marks it
+// published. The caller compiles; this only records the result.
+func (r *Repository) PublishTemplate(ctx context.Context, id uuid.UUID, html string) (*db.Template, error) {
+	query := `
+		UPDATE templates
+		SET html = ?, status = ?, published_at = NOW(6), updated_at = NOW(6)
+		WHERE id = ?
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query, html, db.TemplateStatusPublished, id)
+	if err != nil {
+		return nil, fmt.Errorf("publish template: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("template not found: %s", id)
+	}
+
+	return r.GetTemplate(ctx, id)
+}
+
+// CreateShortLink stores a short link. If the notification already has a
+// link for this URL (a retried send), the existing code is kept and written
+// back into link.Code so the recipient never sees two codes for one URL.
+func (r *Repository) CreateShortLink(ctx context.Context, link *db.ShortLink) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO short_links (code, tenant_id, notification_id, url, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE url = url
+	`, link.Code, link.TenantID, link.NotificationID, link.URL, now())
+	if err != nil {
+		return fmt.Errorf("insert short link: %w", err)
+	}
+
+	// ON DUPLICATE KEY matches either unique key, so a colliding code
+	// is caught here rather than by the INSERT: it leaves no row for
+	// this notification and URL.
+	err = r.db.sql.QueryRowContext(ctx, `
+		SELECT code, created_at
+		FROM short_links
+		WHERE notification_id = ? AND url_hash = UNHEX(SHA2(?, 256))
+	`, link.NotificationID, link.URL).Scan(&link.Code, &link.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("insert short link: code %q already taken", link.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("insert short link: %w", err)
+	}
+
+	return nil
+}
+
+// GetShortLink retrieves a short link by its code.
+func (r *Repository) GetShortLink(ctx context.Context, code string) (*db.ShortLink, error) {
+	query := `
+		SELECT code, tenant_id, notification_id, url, created_at
+		FROM short_links
+		WHERE code = ?
+	`
+
+	var link db.ShortLink
+	err := r.db.sql.QueryRowContext(ctx, query, code).Scan(
+		&link.Code,
+		&link.TenantID,
+		&link.NotificationID,
+		&link.URL,
+		&link.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("short link not found: %s", code)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query short link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// RecordLinkClick stores a click event for a short link.
+func (r *Repository) RecordLinkClick(ctx context.Context, click *db.LinkClick) error {
+	if click.ID == uuid.Nil {
+		click.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO link_clicks (
+			id, code, tenant_id, notification_id, user_agent, ip_address, clicked_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ts := now()
+	_, err := r.db.sql.ExecContext(ctx, query,
+		click.ID,
+		click.Code,
+		click.TenantID,
+		click.NotificationID,
+		click.UserAgent,
+		click.IPAddress,
+		ts,
+	)
+	if err != nil {
+		return fmt.Errorf("insert link click: %w", err)
+	}
+	click.ClickedAt = ts
+
+	return nil
+}
+
+// GetChannelSettings returns a tenant's settings for a channel. A tenant
+// that never configured the channel gets empty settings, not an error.
+func (r *Repository) GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error) {
+	query := `
+		SELECT tenant_id, channel, settings, created_at, updated_at
+		FROM tenant_channel_settings
+		WHERE tenant_id = ? AND channel = ?
+	`
+
+	var s db.TenantChannelSettings
+	err := r.db.sql.QueryRowContext(ctx, query, tenantID, channel).Scan(
+		&s.TenantID,
+		&s.Channel,
+		(*[]byte)(&s.Settings),
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &db.TenantChannelSettings{
+			TenantID: tenantID,
+			Channel:  channel,
+			Settings: json.RawMessage(`{}`),
+		}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query channel settings: %w", err)
+	}
+
+	return &s, nil
+}
+
+// UpsertChannelSettings replaces a tenant's settings for a channel.
+func (r *Repository) UpsertChannelSettings(ctx context.Context, s *db.TenantChannelSettings) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_channel_settings (tenant_id, channel, settings)
+		VALUES (?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE settings = new.settings, updated_at = NOW(6)
+	`, s.TenantID, s.Channel, jsonOrEmpty(s.Settings))
+	if err != nil {
+		return fmt.Errorf("upsert channel settings: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx, `
+		SELECT created_at, updated_at FROM tenant_channel_settings WHERE tenant_id = ? AND channel = ?
+	`, s.TenantID, s.Channel).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert channel settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetTenantSettings returns a tenant's API settings. A tenant that never set
+// them gets the defaults, not an error.
+func (r *Repository) GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*db.TenantSettings, error) {
+	query := `
+		SELECT tenant_id, require_idempotency_key, created_at, updated_at
+		FROM tenant_settings
+		WHERE tenant_id = ?
+	`
+
+	var s db.TenantSettings
+	err := r.db.sql.QueryRowContext(ctx, query, tenantID).Scan(
+		&s.TenantID,
+		&s.RequireIdempotencyKey,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &db.TenantSettings{TenantID: tenantID}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query tenant settings: %w", err)
+	}
+
+	return &s, nil
+}
+
+// UpsertTenantSettings creates or replaces a tenant's API settings.
+func (r *Repository) UpsertTenantSettings(ctx context.Context, s *db.TenantSettings) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_settings (tenant_id, require_idempotency_key)
+		VALUES (?, ?) AS new
+		ON DUPLICATE KEY UPDATE require_idempotency_key = new.require_idempotency_key, updated_at = NOW(6)
+	`, s.TenantID, s.RequireIdempotencyKey)
+	if err != nil {
+		return fmt.Errorf("upsert tenant settings: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx, `
+		SELECT created_at, updated_at FROM tenant_settings WHERE tenant_id = ?
+	`, s.TenantID).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert tenant settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetIPAllowlist returns a tenant's source IP allowlist. A tenant that never
+// set one gets an empty list, not an error.
+func (r *Repository) GetIPAllowlist(ctx context.Context, tenantID uuid.UUID) (*db.TenantIPAllowlist, error) {
+	query := `
+		SELECT tenant_id, cidrs, created_at, updated_at
+		FROM tenant_ip_allowlists
+		WHERE tenant_id = ?
+	`
+
+	var a db.TenantIPAllowlist
+	err := r.db.sql.QueryRowContext(ctx, query, tenantID).Scan(
+		&a.TenantID,
+		(*stringList)(&a.CIDRs),
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &db.TenantIPAllowlist{TenantID: tenantID, CIDRs: []string{}}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query ip allowlist: %w", err)
+	}
+
+	return &a, nil
+}
+
+// UpsertIPAllowlist replaces a tenant's source IP allowlist.
+func (r *Repository) UpsertIPAllowlist(ctx context.Context, a *db.TenantIPAllowlist) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_ip_allowlists (tenant_id, cidrs)
+		VALUES (?, ?) AS new
+		ON DUPLICATE KEY UPDATE cidrs = new.cidrs, updated_at = NOW(6)
+	`, a.TenantID, stringList(a.CIDRs))
+	if err != nil {
+		return fmt.Errorf("upsert ip allowlist: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx, `
+		SELECT created_at, updated_at FROM tenant_ip_allowlists WHERE tenant_id = ?
+	`, a.TenantID).Scan(&a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert ip allowlist: %w", err)
+	}
+
+	return nil
+}
+
+const apiKeyColumns = `
+	id, tenant_id, name, prefix, key_hash, scopes, expires_at,
+	last_used_at, revoked_at, replaced_by, created_at
+`
+
+// CreateAPIKey stores a new API key. The caller generates the key and sets
+// Prefix and KeyHash; the plaintext never reaches the database.
+func (r *Repository) CreateAPIKey(ctx context.Context, key *db.APIKey) error {
+	return createAPIKey(ctx, r.db.sql, key)
+}
+
+func createAPIKey(ctx context.Context, q execer, key *db.APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ts := now()
+	_, err := q.ExecContext(ctx, query,
+		key.ID,
+		key.TenantID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		stringList(key.Scopes),
+		key.ExpiresAt,
+		ts,
+	)
+	if err != nil {
+		return fmt.Errorf("insert api key: %w", err)
+	}
+	key.CreatedAt = ts
+
+	return nil
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (r *Repository) GetAPIKey(ctx context.Context, id uuid.UUID) (*db.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+
+	key, err := scanAPIKey(r.db.sql.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("api key %w: %s", db.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash looks up the key a bearer token hashes to. Callers check
+// Active themselves; revoked and expired keys are returned too.
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*db.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(r.db.sql.QueryRowContext(ctx, query, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("api key not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns a tenant's API keys, newest first, including revoked
+// and expired ones.
+func (r *Repository) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*db.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE tenant_id = ?
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*db.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RollAPIKey stores replacement and makes the old key expire at oldExpiresAt
+// (or its existing expiry, if sooner), so clients can switch over during
+// the grace period.
+func (r *Repository) RollAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, replacement *db.APIKey) error {
+	tx, err := r.db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createAPIKey(ctx, tx, replacement); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE api_keys
+		SET replaced_by = ?, expires_at = LEAST(COALESCE(expires_at, ?), ?)
+		WHERE id = ? AND revoked_at IS NULL AND replaced_by IS NULL
+	`
+	result, err := tx.ExecContext(ctx, query, replacement.ID, oldExpiresAt, oldExpiresAt, oldID)
+	if err != nil {
+		return fmt.Errorf("expire rolled api key: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("api key not found or already rolled or revoked")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	r.logger.Info("api key rolled",
+		zap.String("api_key_id", oldID.String()),
+		zap.String("replacement_id", replacement.ID.String()),
+	)
+
+	return nil
+}
+
+// RevokeAPIKey disables a key immediately.
+func (r *Repository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW(6)
+		WHERE id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("api key not found or already revoked")
+	}
+
+	r.logger.Info("api key revoked", zap.String("api_key_id", id.String()))
+
+	return nil
+}
+
+// TouchAPIKey records that a key was used. Writes are throttled to one a
+// minute per key so busy keys don't turn every request into an UPDATE.
+func (r *Repository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW(6)
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW(6) - INTERVAL 1 MINUTE)
+	`
+
+	if _, err := r.db.sql.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row scanner) (*db.APIKey, error) {
+	var key db.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		(*stringList)(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.ReplacedBy,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// AcquireJob takes the lock on a background job for owner until ttl elapses.
+// It returns false, without error, if another owner holds an unexpired lock
+// or the job already started within minGap — the latter is what keeps N
+// replicas ticking on the same interval from running the job N times.
+//
+// MySQL's ON DUPLICATE KEY UPDATE can't be made conditional, so the job's
+// row is created first if missing and then taken with a conditional UPDATE.
+func (r *Repository) AcquireJob(ctx context.Context, name, owner string, minGap, ttl time.Duration) (bool, error) {
+	if _, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO job_runs (name) VALUES (?)
+		ON DUPLICATE KEY UPDATE name = name
+	`, name); err != nil {
+		return false, fmt.Errorf("acquire job %s: %w", name, err)
+	}
+
+	result, err := r.db.sql.ExecContext(ctx, `
+		UPDATE job_runs
+		SET locked_by = ?,
+		    locked_until = NOW(6) + INTERVAL ? MICROSECOND,
+		    last_started_at = NOW(6)
+		WHERE name = ?
+		  AND (locked_until IS NULL OR locked_until < NOW(6))
+		  AND (last_started_at IS NULL OR last_started_at <= NOW(6) - INTERVAL ? MICROSECOND)
+	`, owner, ttl.Microseconds(), name, minGap.Microseconds())
+	if err != nil {
+		return false, fmt.Errorf("acquire job %s: %w", name, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("acquire job %s: %w", name, err)
+	}
+	return n == 1, nil
+}
+
+// FinishJob records the outcome of a run and releases owner's lock. A run
+// that overran its lock and lost it to another owner records nothing.
+func (r *Repository) FinishJob(ctx context.Context, name, owner string, took time.Duration, runErr error) error {
+	var lastError *string
+	if runErr != nil {
+		msg := runErr.Error()
+		lastError = &msg
+	}
+
+	query := `
+		UPDATE job_runs
+		SET locked_by = NULL,
+		    locked_until = NULL,
+		    last_finished_at = NOW(6),
+		    last_duration_ms = ?,
+		    last_error = ?,
+		    last_success_at = IF(? IS NULL, NOW(6), last_success_at),
+		    run_count = run_count + 1
+		WHERE name = ? AND locked_by = ?
+	`
+
+	if _, err := r.db.sql.ExecContext(ctx, query, took.Milliseconds(), lastError, lastError, name, owner); err != nil {
+		return fmt.Errorf("finish job %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ListJobRuns returns every background job's lock and last-run record.
+func (r *Repository) ListJobRuns(ctx context.Context) ([]*db.JobRun, error) {
+	query := `
+		SELECT name, locked_by, locked_until, last_started_at, last_finished_at,
+		       last_duration_ms, last_error, last_success_at, run_count
+		FROM job_runs
+		ORDER BY name
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*db.JobRun
+	for rows.Next() {
+		var run db.JobRun
+		if err := rows.Scan(
+			&run.Name,
+			&run.LockedBy,
+			&run.LockedUntil,
+			&run.LastStartedAt,
+			&run.LastFinishedAt,
+			&run.LastDurationMS,
+			&run.LastError,
+			&run.LastSuccessAt,
+			&run.RunCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan job run: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+// RequeueNotifications moves up to f.Limit notifications matching f back to
+// pending, due now, oldest first, and returns how many it moved. The attempt
+// count is kept, so a requeued notification still dead-letters once it runs
+// out of retries.
+func (r *Repository) RequeueNotifications(ctx context.Context, f db.RequeueFilter) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET status = 'pending', next_retry_at = NULL
+		WHERE status = ?
+		  AND updated_at < NOW(6) - INTERVAL ? SECOND
+		  AND (? = '' OR channel = ?)
+		  AND (? IS NULL OR tenant_id = ?)
+		  AND (? = '' OR LOCATE(BINARY ?, error_message) > 0)
+		ORDER BY updated_at ASC
+		LIMIT ?
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query,
+		f.Status, int(f.OlderThan.Seconds()),
+		f.Channel, f.Channel,
+		f.TenantID, f.TenantID,
+		f.ErrorContains, f.ErrorContains,
+		f.Limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("requeue notifications: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteFinishedNotifications deletes up to limit sent or dead-lettered
+// notifications last updated before cutoff, oldest first, and returns how
+// many it removed. Callers loop until it returns less than limit, so one
+// huge DELETE never holds locks across the whole table.
+func (r *Repository) DeleteFinishedNotifications(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM notifications
+		WHERE status IN ('sent', 'dead_lettered') AND updated_at < ?
+		ORDER BY updated_at ASC
+		LIMIT ?
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete finished notifications: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// PurgeResolvedDeadLetters deletes up to limit DLQ entries that were
+// retried or discarded before cutoff. Pending entries are never purged:
+// they are still waiting for an operator.
+func (r *Repository) PurgeResolvedDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM dead_letter_notifications
+		WHERE status IN ('retried', 'discarded') AND updated_at < ?
+		ORDER BY updated_at ASC
+		LIMIT ?
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge dead letters: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+const dlqRetryPolicyColumns = `
+	tenant_id, reason, interval_seconds, max_retries, created_at, updated_at`
+
+func scanDLQRetryPolicy(row scanner) (*db.DLQRetryPolicy, error) {
+	var p db.DLQRetryPolicy
+	err := row.Scan(&p.TenantID, &p.Reason, &p.IntervalSeconds, &p.MaxRetries, &p.CreatedAt, &p.UpdatedAt)
+	return &p, err
+}
+
+// ListDLQRetryPolicies returns the tenant's DLQ retry policies by reason.
+func (r *Repository) ListDLQRetryPolicies(ctx context.Context, tenantID uuid.UUID) ([]*db.DLQRetryPolicy, error) {
+	query := `SELECT ` + dlqRetryPolicyColumns + ` FROM dlq_retry_policies WHERE tenant_id = ? ORDER BY reason`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list dlq retry policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*db.DLQRetryPolicy
+	for rows.Next() {
+		p, err := scanDLQRetryPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan dlq retry policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// UpsertDLQRetryPolicy sets the tenant's retry policy for p.Reason.
+func (r *Repository) UpsertDLQRetryPolicy(ctx context.Context, p *db.DLQRetryPolicy) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO dlq_retry_policies (tenant_id, reason, interval_seconds, max_retries)
+		VALUES (?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE interval_seconds = new.interval_seconds,
+			max_retries = new.max_retries,
+			updated_at = NOW(6)
+	`, p.TenantID, p.Reason, p.IntervalSeconds, p.MaxRetries)
+	if err != nil {
+		return fmt.Errorf("upsert dlq retry policy: %w", err)
+	}
+
+	saved, err := scanDLQRetryPolicy(r.db.sql.QueryRowContext(ctx,
+		`SELECT `+dlqRetryPolicyColumns+` FROM dlq_retry_policies WHERE tenant_id = ? AND reason = ?`,
+		p.TenantID, p.Reason))
+	if err != nil {
+		return fmt.Errorf("upsert dlq retry policy: %w", err)
+	}
+
+	*p = *saved
+	return nil
+}
+
+// DeleteDLQRetryPolicy removes the tenant's retry policy for reason.
+func (r *Repository) DeleteDLQRetryPolicy(ctx context.Context, tenantID uuid.UUID, reason string) error {
+	result, err := r.db.sql.ExecContext(ctx,
+		`DELETE FROM dlq_retry_policies WHERE tenant_id = ? AND reason = ?`, tenantID, reason)
+	if err != nil {
+		return fmt.Errorf("delete dlq retry policy: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoDLQRetryPolicy
+	}
+	return nil
+}
+
+// ListDueDeadLetters returns up to limit pending DLQ entries, oldest first,
+// that a retry policy says to retry now.
+func (r *Repository) ListDueDeadLetters(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT d.id
+		FROM dead_letter_notifications d
+		JOIN dlq_retry_policies p ON p.tenant_id = d.tenant_id AND p.reason = d.reason
+		WHERE d.status = ?
+		  AND d.retry_count < p.max_retries
+		  AND d.created_at <= ? - INTERVAL p.interval_seconds SECOND
+		ORDER BY d.created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, db.DLQStatusPending, now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan due dead letter: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// PauseTenant stops delivery for p.TenantID. Pausing an already paused
+// tenant updates the reason but keeps the original paused_at.
+func (r *Repository) PauseTenant(ctx context.Context, p *db.TenantPause) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_delivery_pauses (tenant_id, reason, paused_at)
+		VALUES (?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE reason = new.reason
+	`, p.TenantID, p.Reason, now())
+	if err != nil {
+		return fmt.Errorf("pause tenant: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx,
+		`SELECT paused_at FROM tenant_delivery_pauses WHERE tenant_id = ?`, p.TenantID).Scan(&p.PausedAt)
+	if err != nil {
+		return fmt.Errorf("pause tenant: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeTenant lifts a tenant's delivery pause.
+func (r *Repository) ResumeTenant(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM tenant_delivery_pauses WHERE tenant_id = ?`, tenantID)
+	if err != nil {
+		return fmt.Errorf("resume tenant: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrTenantNotPaused
+	}
+
+	return nil
+}
+
+// ListTenantPauses returns every paused tenant, longest paused first.
+func (r *Repository) ListTenantPauses(ctx context.Context) ([]*db.TenantPause, error) {
+	query := `
+		SELECT tenant_id, reason, paused_at
+		FROM tenant_delivery_pauses
+		ORDER BY paused_at
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []*db.TenantPause
+	for rows.Next() {
+		var p db.TenantPause
+		if err := rows.Scan(&p.TenantID, &p.Reason, &p.PausedAt); err != nil {
+			return nil, fmt.Errorf("scan tenant pause: %w", err)
+		}
+		pauses = append(pauses, &p)
+	}
+
+	return pauses, rows.Err()
+}
+
+// KillChannel turns on the kill switch for k.Channel. Killing an already
+// killed channel updates the reason but keeps the original killed_at.
+func (r *Repository) KillChannel(ctx context.Context, k *db.ChannelKillSwitch) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO channel_kill_switches (channel, reason, killed_at)
+		VALUES (?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE reason = new.reason
+	`, k.Channel, k.Reason, now())
+	if err != nil {
+		return fmt.Errorf("kill channel: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx,
+		`SELECT killed_at FROM channel_kill_switches WHERE channel = ?`, k.Channel).Scan(&k.KilledAt)
+	if err != nil {
+		return fmt.Errorf("kill channel: %w", err)
+	}
+
+	return nil
+}
+
+// ReviveChannel lifts a channel's kill switch. Its held notifications are
+// released by the worker's next SyncChannelHolds.
+func (r *Repository) ReviveChannel(ctx context.Context, channel string) error {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM channel_kill_switches WHERE channel = ?`, channel)
+	if err != nil {
+		return fmt.Errorf("revive channel: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrChannelNotKilled
+	}
+
+	return nil
+}
+
+// ListChannelKillSwitches returns every killed channel.
+func (r *Repository) ListChannelKillSwitches(ctx context.Context) ([]*db.ChannelKillSwitch, error) {
+	query := `
+		SELECT channel, reason, killed_at
+		FROM channel_kill_switches
+		ORDER BY channel
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query channel kill switches: %w", err)
+	}
+	defer rows.Close()
+
+	var switches []*db.ChannelKillSwitch
+	for rows.Next() {
+		var k db.ChannelKillSwitch
+		if err := rows.Scan(&k.Channel, &k.Reason, &k.KilledAt); err != nil {
+			return nil, fmt.Errorf("scan channel kill switch: %w", err)
+		}
+		switches = append(switches, &k)
+	}
+
+	return switches, rows.Err()
+}
+
+// SyncChannelHolds moves pending notifications on killed channels to 'held'
+// and held notifications whose channel is no longer killed back to
+// 'pending'. Running both directions every poll means a revive needs no
+// follow-up step, and a hold that raced a revive is undone on the next call.
+func (r *Repository) SyncChannelHolds(ctx context.Context) (held, released int64, err error) {
+	result, err := r.db.sql.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'held'
+		WHERE status = 'pending'
+		  AND channel IN (SELECT channel FROM channel_kill_switches)
+	`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("hold notifications: %w", err)
+	}
+	held, _ = result.RowsAffected()
+
+	result, err = r.db.sql.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'pending'
+		WHERE status = 'held'
+		  AND channel NOT IN (SELECT channel FROM channel_kill_switches)
+	`)
+	if err != nil {
+		return held, 0, fmt.Errorf("release held notifications: %w", err)
+	}
+	released, _ = result.RowsAffected()
+
+	return held, released, nil
+}
+
+// InsertDeliveryEvents stores provider-reported events, attributing each to
+// the notification (and tenant) whose provider message ID it carries.
+// Events already stored are skipped, since SNS may deliver one twice. A new
+// hard bounce also invalidates the recipient for the tenant. It returns how
+// many events were new.
+func (r *Repository) InsertDeliveryEvents(ctx context.Context, events []*db.DeliveryEvent) (int64, error) {
+	query := `
+		INSERT INTO delivery_events (
+			notification_id, tenant_id, channel, provider, provider_message_id,
+			event_type, bounce_type, recipient, occurred_at
+		)
+		SELECT n.id, n.tenant_id, ?, ?, ?, ?, ?, ?, ?
+		FROM (SELECT 1) AS one
+		LEFT JOIN LATERAL (
+			SELECT id, tenant_id
+			FROM notifications
+			WHERE provider_message_id = ? AND provider = ?
+			ORDER BY created_at DESC
+			LIMIT 1
+		) AS n ON TRUE
+	`
+
+	tx, err := r.db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var inserted int64
+	for _, e := range events {
+		_, err := tx.ExecContext(ctx, query,
+			e.Channel, e.Provider, e.ProviderMessageID,
+			e.Type, e.BounceType, e.Recipient, e.OccurredAt,
+			e.ProviderMessageID, e.Provider,
+		)
+		// A duplicate only rolls back its own statement, not the
+		// transaction. INSERT IGNORE would also swallow other errors.
+		if isDuplicateEntry(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("insert delivery event: %w", err)
+		}
+		inserted++
+
+		if !invalidatesRecipient(e) {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, invalidateBouncedQuery,
+			e.Channel, e.Recipient, db.InvalidRecipientHardBounce, e.Provider+": permanent bounce",
+			e.ProviderMessageID, e.Provider,
+		)
+		if err != nil && !isDuplicateEntry(err) {
+			return 0, fmt.Errorf("invalidate bounced recipient: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit delivery events: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// invalidateBouncedQuery invalidates a hard-bounced recipient for the
+// tenant that sent the bounced message. Bounces we can't attribute insert
+// nothing.
+const invalidateBouncedQuery = `
+	INSERT INTO invalid_recipients (tenant_id, channel, recipient, reason, detail, notification_id)
+	SELECT tenant_id, ?, ?, ?, ?, id
+	FROM notifications
+	WHERE provider_message_id = ? AND provider = ?
+	ORDER BY created_at DESC
+	LIMIT 1
+`
+
+// invalidatesRecipient reports whether e is a hard bounce for a known
+// recipient.
+func invalidatesRecipient(e *db.DeliveryEvent) bool {
+	return e.Type == db.DeliveryEventBounce && e.BounceType == db.BounceTypePermanent && e.Recipient != ""
+}
+
+const invalidRecipientColumns = `
+	tenant_id, channel, recipient, reason, detail, notification_id, invalidated_at`
+
+func scanInvalidRecipient(row scanner) (*db.InvalidRecipient, error) {
+	var ir db.InvalidRecipient
+	err := row.Scan(&ir.TenantID, &ir.Channel, &ir.Recipient, &ir.Reason, &ir.Detail,
+		&ir.NotificationID, &ir.InvalidatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &ir, nil
+}
+
+// InvalidateRecipient records ir.Recipient as undeliverable for the tenant.
+// An address that is already invalid keeps its original reason and time.
+func (r *Repository) InvalidateRecipient(ctx context.Context, ir *db.InvalidRecipient) error {
+	query := `
+		INSERT INTO invalid_recipients (
+			tenant_id, channel, recipient, reason, detail, notification_id, invalidated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.sql.ExecContext(ctx, query,
+		ir.TenantID, ir.Channel, ir.Recipient, ir.Reason, ir.Detail, ir.NotificationID, now())
+	if err != nil && !isDuplicateEntry(err) {
+		return fmt.Errorf("invalidate recipient: %w", err)
+	}
+	return nil
+}
+
+// IsRecipientInvalid reports whether the tenant's recipient on channel has
+// been invalidated.
+func (r *Repository) IsRecipientInvalid(ctx context.Context, tenantID uuid.UUID, channel, recipient string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM invalid_recipients
+			WHERE tenant_id = ? AND channel = ? AND recipient = ?
+		)
+	`
+
+	var invalid bool
+	if err := r.db.sql.QueryRowContext(ctx, query, tenantID, channel, recipient).Scan(&invalid); err != nil {
+		return false, fmt.Errorf("check invalid recipient: %w", err)
+	}
+	return invalid, nil
+}
+
+// ListInvalidRecipients returns the tenant's invalidated recipients, newest
+// first. An empty channel lists every channel.
+func (r *Repository) ListInvalidRecipients(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]*db.InvalidRecipient, error) {
+	query := `
+		SELECT ` + invalidRecipientColumns + `
+		FROM invalid_recipients
+		WHERE tenant_id = ? AND (? = '' OR channel = ?)
+		ORDER BY invalidated_at DESC, recipient
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID, channel, channel, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list invalid recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []*db.InvalidRecipient
+	for rows.Next() {
+		ir, err := scanInvalidRecipient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invalid recipient: %w", err)
+		}
+		recipients = append(recipients, ir)
+	}
+	return recipients, rows.Err()
+}
+
+// ReinstateRecipient makes an invalidated recipient deliverable again.
+func (r *Repository) ReinstateRecipient(ctx context.Context, tenantID uuid.UUID, channel, recipient string) error {
+	result, err := r.db.sql.ExecContext(ctx,
+		`DELETE FROM invalid_recipients WHERE tenant_id = ? AND channel = ? AND recipient = ?`,
+		tenantID, channel, recipient)
+	if err != nil {
+		return fmt.Errorf("reinstate recipient: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoInvalidRecipient
+	}
+	return nil
+}
+
+const recipientColumns = `
+	tenant_id, user_id, email, phone, device_tokens, locale, timezone, created_at, updated_at`
+
+func scanRecipient(row scanner) (*db.Recipient, error) {
+	var rc db.Recipient
+	err := row.Scan(&rc.TenantID, &rc.UserID, &rc.Email, &rc.Phone, (*stringList)(&rc.DeviceTokens),
+		&rc.Locale, &rc.Timezone, &rc.CreatedAt, &rc.UpdatedAt)
+	return &rc, err
+}
+
+// GetRecipient returns the tenant's recipient record for userID.
+func (r *Repository) GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error) {
+	query := `SELECT ` + recipientColumns + ` FROM recipients WHERE tenant_id = ? AND user_id = ?`
+
+	rc, err := scanRecipient(r.db.sql.QueryRowContext(ctx, query, tenantID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNoRecipient
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get recipient: %w", err)
+	}
+
+	return rc, nil
+}
+
+// UpsertRecipient replaces the tenant's recipient record for rc.UserID.
+func (r *Repository) UpsertRecipient(ctx context.Context, rc *db.Recipient) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO recipients (tenant_id, user_id, email, phone, device_tokens, locale, timezone)
+		VALUES (?, ?, ?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE email = new.email,
+			phone = new.phone,
+			device_tokens = new.device_tokens,
+			locale = new.locale,
+			timezone = new.timezone,
+			updated_at = NOW(6)
+	`, rc.TenantID, rc.UserID, rc.Email, rc.Phone, stringList(rc.DeviceTokens), rc.Locale, rc.Timezone)
+	if err != nil {
+		return fmt.Errorf("upsert recipient: %w", err)
+	}
+
+	saved, err := r.GetRecipient(ctx, rc.TenantID, rc.UserID)
+	if err != nil {
+		return fmt.Errorf("upsert recipient: %w", err)
+	}
+
+	*rc = *saved
+	return nil
+}
+
+// DeleteRecipient removes the tenant's recipient record for userID.
+func (r *Repository) DeleteRecipient(ctx context.Context, tenantID, userID uuid.UUID) error {
+	result, err := r.db.sql.ExecContext(ctx,
+		`DELETE FROM recipients WHERE tenant_id = ? AND user_id = ?`, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("delete recipient: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoRecipient
+	}
+	return nil
+}
+
+// GetEmailReputation counts, per tenant, the emails sent and the hard
+// bounces and complaints received since the given time. A tenant whose
+// email limit was lifted later than that is only judged from the lift on.
+// Tenants that sent no email in the window are omitted.
+func (r *Repository) GetEmailReputation(ctx context.Context, since time.Time) ([]*db.TenantReputation, error) {
+	query := `
+		WITH lifted AS (
+			SELECT tenant_id, lifted_at
+			FROM tenant_send_limits
+			WHERE channel = 'email' AND lifted_at > ?
+		),
+		sent AS (
+			SELECT n.tenant_id, COUNT(*) AS sent
+			FROM notifications n
+			LEFT JOIN lifted l ON l.tenant_id = n.tenant_id
+			WHERE n.channel = 'email' AND n.status = 'sent'
+			  AND n.updated_at >= COALESCE(l.lifted_at, ?)
+			GROUP BY n.tenant_id
+		),
+		events AS (
+			SELECT e.tenant_id,
+				SUM(e.event_type = 'bounce' AND e.bounce_type = 'permanent') AS hard_bounces,
+				SUM(e.event_type = 'complaint') AS complaints
+			FROM delivery_events e
+			LEFT JOIN lifted l ON l.tenant_id = e.tenant_id
+			WHERE e.channel = 'email' AND e.tenant_id IS NOT NULL
+			  AND e.occurred_at >= COALESCE(l.lifted_at, ?)
+			GROUP BY e.tenant_id
+		)
+		SELECT s.tenant_id, s.sent, COALESCE(e.hard_bounces, 0), COALESCE(e.complaints, 0)
+		FROM sent s
+		LEFT JOIN events e ON e.tenant_id = s.tenant_id
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, since, since, since)
+	if err != nil {
+		return nil, fmt.Errorf("query email reputation: %w", err)
+	}
+	defer rows.Close()
+
+	var reps []*db.TenantReputation
+	for rows.Next() {
+		var rep db.TenantReputation
+		if err := rows.Scan(&rep.TenantID, &rep.Sent, &rep.HardBounces, &rep.Complaints); err != nil {
+			return nil, fmt.Errorf("scan email reputation: %w", err)
+		}
+		reps = append(reps, &rep)
+	}
+
+	return reps, rows.Err()
+}
+
+// SetTenantSendLimit applies l, replacing any limit the tenant already has
+// on the channel. since is kept when the state doesn't change.
+func (r *Repository) SetTenantSendLimit(ctx context.Context, l *db.TenantSendLimit) error {
+	// MySQL applies the assignments in order, so since is decided while
+	// state and lifted_at still hold the old values.
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_send_limits (tenant_id, channel, state, reason, since)
+		VALUES (?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE
+			since = IF(tenant_send_limits.lifted_at IS NULL AND tenant_send_limits.state = new.state,
+				tenant_send_limits.since, new.since),
+			state = new.state,
+			reason = new.reason,
+			lifted_at = NULL
+	`, l.TenantID, l.Channel, l.State, l.Reason, now())
+	if err != nil {
+		return fmt.Errorf("set tenant send limit: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx, `
+		SELECT since FROM tenant_send_limits WHERE tenant_id = ? AND channel = ?
+	`, l.TenantID, l.Channel).Scan(&l.Since)
+	if err != nil {
+		return fmt.Errorf("set tenant send limit: %w", err)
+	}
+
+	return nil
+}
+
+// LiftTenantSendLimit removes the tenant's active limit on channel.
+func (r *Repository) LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error {
+	query := `
+		UPDATE tenant_send_limits
+		SET lifted_at = NOW(6)
+		WHERE tenant_id = ? AND channel = ? AND lifted_at IS NULL
+	`
+
+	result, err := r.db.sql.ExecContext(ctx, query, tenantID, channel)
+	if err != nil {
+		return fmt.Errorf("lift tenant send limit: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoSendLimit
+	}
+
+	return nil
+}
+
+// ListTenantSendLimits returns every active send limit, oldest first.
+func (r *Repository) ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error) {
+	query := `
+		SELECT tenant_id, channel, state, reason, since
+		FROM tenant_send_limits
+		WHERE lifted_at IS NULL
+		ORDER BY since
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant send limits: %w", err)
+	}
+	defer rows.Close()
+
+	var limits []*db.TenantSendLimit
+	for rows.Next() {
+		var l db.TenantSendLimit
+		if err := rows.Scan(&l.TenantID, &l.Channel, &l.State, &l.Reason, &l.Since); err != nil {
+			return nil, fmt.Errorf("scan tenant send limit: %w", err)
+		}
+		limits = append(limits, &l)
+	}
+
+	return limits, rows.Err()
+}
+
+// ReserveWarmupSend counts one email against the tenant's warm-up cap for
+// today, where schedule[d] is the cap on the tenant's day d of sending. The
+// first call for a tenant starts its warm-up. allowed is false, and nothing
+// is counted, when today's cap is used up. graduated reports that the
+// schedule no longer applies to the tenant, so callers can stop asking.
+//
+// The Postgres version decides in one conditional upsert; here the row is
+// locked and the cap checked in Go, in one transaction.
+func (r *Repository) ReserveWarmupSend(ctx context.Context, tenantID uuid.UUID, schedule []int) (allowed, graduated bool, err error) {
+	tx, err := r.db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return false, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Start the warm-up on the first send; a no-op afterwards.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO tenant_email_warmups (tenant_id, started_at, day, sent_today)
+		VALUES (?, NOW(6), UTC_DATE(), 0)
+		ON DUPLICATE KEY UPDATE tenant_id = tenant_id
+	`, tenantID); err != nil {
+		return false, false, fmt.Errorf("reserve warm-up send: %w", err)
+	}
+
+	var startedAt, day, today time.Time
+	var endedAt *time.Time
+	var sentToday int
+	err = tx.QueryRowContext(ctx, `
+		SELECT started_at, ended_at, day, sent_today, UTC_DATE()
+		FROM tenant_email_warmups
+		WHERE tenant_id = ?
+		FOR UPDATE
+	`, tenantID).Scan(&startedAt, &endedAt, &day, &sentToday, &today)
+	if err != nil {
+		return false, false, fmt.Errorf("reserve warm-up send: %w", err)
+	}
+
+	// Day 1 is the day the warm-up started.
+	started := time.Date(startedAt.Year(), startedAt.Month(), startedAt.Day(), 0, 0, 0, 0, time.UTC)
+	dayOfWarmup := int(today.Sub(started).Hours()/24) + 1
+	sameDay := day.Equal(today)
+
+	allowed = endedAt != nil || !sameDay ||
+		dayOfWarmup < 1 || dayOfWarmup > len(schedule) || sentToday < schedule[dayOfWarmup-1]
+	if !allowed {
+		return false, false, nil
+	}
+
+	if sameDay {
+		sentToday++
+	} else {
+		sentToday = 1
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tenant_email_warmups SET day = ?, sent_today = ? WHERE tenant_id = ?
+	`, today, sentToday, tenantID); err != nil {
+		return false, false, fmt.Errorf("reserve warm-up send: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, false, fmt.Errorf("reserve warm-up send: %w", err)
+	}
+
+	return true, endedAt != nil || dayOfWarmup > len(schedule), nil
+}
+
+const emailWarmupColumns = `tenant_id, started_at, ended_at,
+	CASE WHEN day = UTC_DATE() THEN sent_today ELSE 0 END`
+
+// GetEmailWarmup returns the tenant's warm-up progress.
+func (r *Repository) GetEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*db.EmailWarmup, error) {
+	query := `SELECT ` + emailWarmupColumns + ` FROM tenant_email_warmups WHERE tenant_id = ?`
+
+	var w db.EmailWarmup
+	err := r.db.sql.QueryRowContext(ctx, query, tenantID).Scan(&w.TenantID, &w.StartedAt, &w.EndedAt, &w.SentToday)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNoEmailWarmup
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get email warm-up: %w", err)
+	}
+
+	return &w, nil
+}
+
+// EndEmailWarmup lifts the warm-up cap for a tenant for good, e.g. one
+// moving an established sending history over to us. A tenant that hasn't
+// sent yet gets a warm-up row that is already ended.
+func (r *Repository) EndEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*db.EmailWarmup, error) {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_email_warmups (tenant_id, started_at, ended_at, day, sent_today)
+		VALUES (?, NOW(6), NOW(6), UTC_DATE(), 0) AS new
+		ON DUPLICATE KEY UPDATE ended_at = COALESCE(tenant_email_warmups.ended_at, new.ended_at)
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("end email warm-up: %w", err)
+	}
+
+	var w db.EmailWarmup
+	err = r.db.sql.QueryRowContext(ctx,
+		`SELECT `+emailWarmupColumns+` FROM tenant_email_warmups WHERE tenant_id = ?`, tenantID,
+	).Scan(&w.TenantID, &w.StartedAt, &w.EndedAt, &w.SentToday)
+	if err != nil {
+		return nil, fmt.Errorf("end email warm-up: %w", err)
+	}
+
+	return &w, nil
+}
+
+// ListTenantUsage returns a tenant's daily sent counts and costs per
+// channel for the UTC days from through to, inclusive, oldest first.
+func (r *Repository) ListTenantUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*db.DailyUsage, error) {
+	query := `
+		SELECT day, channel, sent, cost
+		FROM tenant_daily_usage
+		WHERE tenant_id = ? AND day BETWEEN DATE(?) AND DATE(?)
+		ORDER BY day, channel
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query tenant usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*db.DailyUsage
+	for rows.Next() {
+		var u db.DailyUsage
+		if err := rows.Scan(&u.Day, &u.Channel, &u.Sent, &u.Cost); err != nil {
+			return nil, fmt.Errorf("scan tenant usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	return usage, rows.Err()
+}
+
+// monthStart is the first day of the current UTC month.
+const monthStart = `(UTC_DATE() - INTERVAL (DAYOFMONTH(UTC_DATE()) - 1) DAY)`
+
+const tenantBudgetColumns = `
+	tenant_id, monthly_cost, monthly_volume, alert_email, block_at_limit,
+	CASE WHEN alerted_month = ` + monthStart + ` THEN alerted_percent ELSE 0 END,
+	created_at, updated_at`
+
+func scanTenantBudget(row scanner) (*db.TenantBudget, error) {
+	var b db.TenantBudget
+	err := row.Scan(&b.TenantID, &b.MonthlyCost, &b.MonthlyVolume, &b.AlertEmail, &b.BlockAtLimit,
+		&b.AlertedPercent, &b.CreatedAt, &b.UpdatedAt)
+	return &b, err
+}
+
+// GetTenantBudget returns the tenant's budget.
+func (r *Repository) GetTenantBudget(ctx context.Context, tenantID uuid.UUID) (*db.TenantBudget, error) {
+	query := `SELECT ` + tenantBudgetColumns + ` FROM tenant_budgets WHERE tenant_id = ?`
+
+	b, err := scanTenantBudget(r.db.sql.QueryRowContext(ctx, query, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNoTenantBudget
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tenant budget: %w", err)
+	}
+
+	return b, nil
+}
+
+// UpsertTenantBudget sets the tenant's budget. Changing it re-arms this
+// month's alerts, so a tenant still over a raised budget hears about it.
+func (r *Repository) UpsertTenantBudget(ctx context.Context, b *db.TenantBudget) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_budgets (tenant_id, monthly_cost, monthly_volume, alert_email, block_at_limit)
+		VALUES (?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE monthly_cost = new.monthly_cost,
+			monthly_volume = new.monthly_volume,
+			alert_email = new.alert_email,
+			block_at_limit = new.block_at_limit,
+			alerted_month = NULL,
+			alerted_percent = 0,
+			updated_at = NOW(6)
+	`, b.TenantID, b.MonthlyCost, b.MonthlyVolume, b.AlertEmail, b.BlockAtLimit)
+	if err != nil {
+		return fmt.Errorf("upsert tenant budget: %w", err)
+	}
+
+	saved, err := scanTenantBudget(r.db.sql.QueryRowContext(ctx,
+		`SELECT `+tenantBudgetColumns+` FROM tenant_budgets WHERE tenant_id = ?`, b.TenantID))
+	if err != nil {
+		return fmt.Errorf("upsert tenant budget: %w", err)
+	}
+
+	*b = *saved
+	return nil
+}
+
+// DeleteTenantBudget removes the tenant's budget, lifting any block.
+func (r *Repository) DeleteTenantBudget(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM tenant_budgets WHERE tenant_id = ?`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete tenant budget: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoTenantBudget
+	}
+
+	return nil
+}
+
+// ListBudgetUsage returns every budget with the tenant's sends and cost so
+// far in the current UTC month.
+func (r *Repository) ListBudgetUsage(ctx context.Context) ([]*db.BudgetUsage, error) {
+	query := `
+		SELECT ` + tenantBudgetColumns + `, COALESCE(u.sent, 0), COALESCE(u.cost, 0)
+		FROM tenant_budgets b
+		LEFT JOIN (
+			SELECT tenant_id AS usage_tenant_id, SUM(sent) AS sent, SUM(cost) AS cost
+			FROM tenant_daily_usage
+			WHERE day >= ` + monthStart + `
+			GROUP BY tenant_id
+		) u ON u.usage_tenant_id = b.tenant_id
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query budget usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*db.BudgetUsage
+	for rows.Next() {
+		var b db.TenantBudget
+		u := db.BudgetUsage{Budget: &b}
+		if err := rows.Scan(&b.TenantID, &b.MonthlyCost, &b.MonthlyVolume, &b.AlertEmail, &b.BlockAtLimit,
+			&b.AlertedPercent, &b.CreatedAt, &b.UpdatedAt, &u.Sent, &u.Cost); err != nil {
+			return nil, fmt.Errorf("scan budget usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	return usage, rows.Err()
+}
+
+// RecordBudgetAlert raises the tenant's alerted threshold for this month to
+// percent and, in the same transaction, enqueues alert. It returns false
+// without enqueuing when that threshold was already alerted on, so
+// concurrent or repeated checks send each alert once.
+func (r *Repository) RecordBudgetAlert(ctx context.Context, tenantID uuid.UUID, percent int, alert *db.Notification) (bool, error) {
+	tx, err := r.db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tenant_budgets
+		SET alerted_month = `+monthStart+`, alerted_percent = ?
+		WHERE tenant_id = ?
+		  AND (NOT (alerted_month <=> `+monthStart+`) OR alerted_percent < ?)
+	`, percent, tenantID, percent)
+	if err != nil {
+		return false, fmt.Errorf("record budget alert: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if err := insertNotification(ctx, tx, alert); err != nil {
+		return false, fmt.Errorf("insert budget alert: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+const frequencyCapColumns = `tenant_id, channel, category, max_count, window_seconds, action, created_at, updated_at`
+
+func scanFrequencyCap(row scanner) (*db.FrequencyCap, error) {
+	var c db.FrequencyCap
+	err := row.Scan(&c.TenantID, &c.Channel, &c.Category, &c.MaxCount, &c.WindowSeconds, &c.Action, &c.CreatedAt, &c.UpdatedAt)
+	return &c, err
+}
+
+// ListFrequencyCaps returns every tenant's frequency caps.
+func (r *Repository) ListFrequencyCaps(ctx context.Context) ([]*db.FrequencyCap, error) {
+	return r.queryFrequencyCaps(ctx, `SELECT `+frequencyCapColumns+` FROM frequency_caps`)
+}
+
+// ListTenantFrequencyCaps returns the tenant's frequency caps by channel
+// and category.
+func (r *Repository) ListTenantFrequencyCaps(ctx context.Context, tenantID uuid.UUID) ([]*db.FrequencyCap, error) {
+	return r.queryFrequencyCaps(ctx, `SELECT `+frequencyCapColumns+` FROM frequency_caps WHERE tenant_id = ? ORDER BY channel, category`, tenantID)
+}
+
+func (r *Repository) queryFrequencyCaps(ctx context.Context, query string, args ...any) ([]*db.FrequencyCap, error) {
+	rows, err := r.db.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query frequency caps: %w", err)
+	}
+	defer rows.Close()
+
+	var caps []*db.FrequencyCap
+	for rows.Next() {
+		c, err := scanFrequencyCap(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan frequency cap: %w", err)
+		}
+		caps = append(caps, c)
+	}
+
+	return caps, rows.Err()
+}
+
+// UpsertFrequencyCap sets the tenant's cap on c.Channel, for c.Category
+// or, if that is empty, the whole channel.
+func (r *Repository) UpsertFrequencyCap(ctx context.Context, c *db.FrequencyCap) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO frequency_caps (tenant_id, channel, category, max_count, window_seconds, action)
+		VALUES (?, ?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE max_count = new.max_count,
+			window_seconds = new.window_seconds,
+			action = new.action,
+			updated_at = NOW(6)
+	`, c.TenantID, c.Channel, c.Category, c.MaxCount, c.WindowSeconds, c.Action)
+	if err != nil {
+		return fmt.Errorf("upsert frequency cap: %w", err)
+	}
+
+	saved, err := scanFrequencyCap(r.db.sql.QueryRowContext(ctx,
+		`SELECT `+frequencyCapColumns+` FROM frequency_caps WHERE tenant_id = ? AND channel = ? AND category = ?`, c.TenantID, c.Channel, c.Category))
+	if err != nil {
+		return fmt.Errorf("upsert frequency cap: %w", err)
+	}
+
+	*c = *saved
+	return nil
+}
+
+// DeleteFrequencyCap removes the tenant's cap on channel for category (empty for
+// the channel-wide cap). Notifications it deferred go out on their next
+// check; capped ones stay capped.
+func (r *Repository) DeleteFrequencyCap(ctx context.Context, tenantID uuid.UUID, channel, category string) error {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM frequency_caps WHERE tenant_id = ? AND channel = ? AND category = ?`, tenantID, channel, category)
+	if err != nil {
+		return fmt.Errorf("delete frequency cap: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoFrequencyCap
+	}
+
+	return nil
+}
+
+// CountUserSends counts the notifications of category (empty for any) delivered
+// to the user on channel since then, and returns when the oldest of them
+// was sent (nil if none). Notifications collapsed into an earlier delivery
+// aren't counted: the user never saw them.
+func (r *Repository) CountUserSends(ctx context.Context, tenantID, userID uuid.UUID, channel, category string, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT COUNT(*), MIN(updated_at)
+		FROM notifications
+		WHERE tenant_id = ? AND user_id = ? AND channel = ?
+		  AND status = 'sent' AND collapsed_into IS NULL
+		  AND updated_at >= ?
+		  AND (? = '' OR category = ?)
+	`
+
+	var count int
+	var oldest *time.Time
+	err := r.db.sql.QueryRowContext(ctx, query, tenantID, userID, channel, since, category, category).Scan(&count, &oldest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("count user sends: %w", err)
+	}
+
+	return count, oldest, nil
+}
+
+const notificationCategoryColumns = `
+	tenant_id, name, description, mandatory, quiet_hours_start, quiet_hours_end, created_at, updated_at`
+
+func scanNotificationCategory(row scanner) (*db.NotificationCategory, error) {
+	var c db.NotificationCategory
+	var quietStart, quietEnd sql.NullString
+	err := row.Scan(&c.TenantID, &c.Name, &c.Description, &c.Mandatory, &quietStart, &quietEnd, &c.CreatedAt, &c.UpdatedAt)
+	if quietStart.Valid && quietEnd.Valid {
+		c.QuietHours = &db.QuietHours{Start: quietStart.String, End: quietEnd.String}
+	}
+	return &c, err
+}
+
+// quietHoursColumns splits q into the quiet_hours_start and quiet_hours_end
+// column values, both NULL when q is nil.
+func quietHoursColumns(q *db.QuietHours) (start, end *string) {
+	if q == nil {
+		return nil, nil
+	}
+	return &q.Start, &q.End
+}
+
+// ListNotificationCategories returns every tenant's notification categories.
+func (r *Repository) ListNotificationCategories(ctx context.Context) ([]*db.NotificationCategory, error) {
+	return r.queryNotificationCategories(ctx, `SELECT `+notificationCategoryColumns+` FROM notification_categories`)
+}
+
+// ListTenantNotificationCategories returns the tenant's taxonomy by name.
+func (r *Repository) ListTenantNotificationCategories(ctx context.Context, tenantID uuid.UUID) ([]*db.NotificationCategory, error) {
+	return r.queryNotificationCategories(ctx, `SELECT `+notificationCategoryColumns+` FROM notification_categories WHERE tenant_id = ? ORDER BY name`, tenantID)
+}
+
+func (r *Repository) queryNotificationCategories(ctx context.Context, query string, args ...any) ([]*db.NotificationCategory, error) {
+	rows, err := r.db.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query notification categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*db.NotificationCategory
+	for rows.Next() {
+		c, err := scanNotificationCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetNotificationCategory returns the tenant's category called name.
+func (r *Repository) GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*db.NotificationCategory, error) {
+	query := `SELECT ` + notificationCategoryColumns + ` FROM notification_categories WHERE tenant_id = ? AND name = ?`
+
+	c, err := scanNotificationCategory(r.db.sql.QueryRowContext(ctx, query, tenantID, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNoNotificationCategory
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get notification category: %w", err)
+	}
+
+	return c, nil
+}
+
+// UpsertNotificationCategory creates or replaces the tenant's category
+// c.Name.
+func (r *Repository) UpsertNotificationCategory(ctx context.Context, c *db.NotificationCategory) error {
+	quietStart, quietEnd := quietHoursColumns(c.QuietHours)
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO notification_categories (tenant_id, name, description, mandatory, quiet_hours_start, quiet_hours_end)
+		VALUES (?, ?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE description = new.description,
+			mandatory = new.mandatory,
+			quiet_hours_start = new.quiet_hours_start,
+			quiet_hours_end = new.quiet_hours_end,
+			updated_at = NOW(6)
+	`, c.TenantID, c.Name, c.Description, c.Mandatory, quietStart, quietEnd)
+	if err != nil {
+		return fmt.Errorf("upsert notification category: %w", err)
+	}
+
+	saved, err := r.GetNotificationCategory(ctx, c.TenantID, c.Name)
+	if err != nil {
+		return fmt.Errorf("upsert notification category: %w", err)
+	}
+
+	*c = *saved
+	return nil
+}
+
+// DeleteNotificationCategory removes the tenant's category name, and users'
+// opt-outs of it with it. Notifications already created keep the name.
+func (r *Repository) DeleteNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) error {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM notification_categories WHERE tenant_id = ? AND name = ?`, tenantID, name)
+	if err != nil {
+		return fmt.Errorf("delete notification category: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoNotificationCategory
+	}
+
+	return nil
+}
+
+// ListCategoryOptOuts returns the user's opt-outs by category and channel.
+func (r *Repository) ListCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID) ([]*db.CategoryOptOut, error) {
+	query := `
+		SELECT category, channel
+		FROM category_opt_outs
+		WHERE tenant_id = ? AND user_id = ?
+		ORDER BY category, channel
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query category opt-outs: %w", err)
+	}
+	defer rows.Close()
+
+	var optOuts []*db.CategoryOptOut
+	for rows.Next() {
+		var o db.CategoryOptOut
+		if err := rows.Scan(&o.Category, &o.Channel); err != nil {
+			return nil, fmt.Errorf("scan category opt-out: %w", err)
+		}
+		optOuts = append(optOuts, &o)
+	}
+
+	return optOuts, rows.Err()
+}
+
+// ReplaceCategoryOptOuts replaces all of the user's opt-outs with optOuts.
+// Every category must be one of the tenant's.
+func (r *Repository) ReplaceCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID, optOuts []*db.CategoryOptOut) error {
+	tx, err := r.db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM category_opt_outs WHERE tenant_id = ? AND user_id = ?`, tenantID, userID); err != nil {
+		return fmt.Errorf("delete category opt-outs: %w", err)
+	}
+	for _, o := range optOuts {
+		_, err := tx.ExecContext(ctx, `
+			INSERT IGNORE INTO category_opt_outs (tenant_id, user_id, category, channel)
+			VALUES (?, ?, ?, ?)
+		`, tenantID, userID, o.Category, o.Channel)
+		if err != nil {
+			return fmt.Errorf("insert category opt-out: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IsOptedOut reports whether the user opted out of category on channel,
+// or on every channel.
+func (r *Repository) IsOptedOut(ctx context.Context, tenantID, userID uuid.UUID, category, channel string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM category_opt_outs
+			WHERE tenant_id = ? AND user_id = ? AND category = ?
+			  AND channel IN ('', ?)
+		)
+	`
+
+	var optedOut bool
+	if err := r.db.sql.QueryRowContext(ctx, query, tenantID, userID, category, channel).Scan(&optedOut); err != nil {
+		return false, fmt.Errorf("check category opt-out: %w", err)
+	}
+
+	return optedOut, nil
+}
+
+// ListCategoryStats counts the tenant's notifications created in [from, to)
+// by category, channel and status.
+func (r *Repository) ListCategoryStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*db.CategoryStats, error) {
+	query := `
+		SELECT COALESCE(category, ''), channel, status, COUNT(*)
+		FROM notifications
+		WHERE tenant_id = ? AND created_at >= ? AND created_at < ?
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := r.db.sql.QueryContext(ctx, query, tenantID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query category stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*db.CategoryStats
+	for rows.Next() {
+		var s db.CategoryStats
+		if err := rows.Scan(&s.Category, &s.Channel, &s.Status, &s.Count); err != nil {
+			return nil, fmt.Errorf("scan category stats: %w", err)
+		}
+		stats = append(stats, &s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetQueueOverview returns current queue depth, plus per-channel outcomes
+// and the topTenants tenants with the most failures among notifications
+// finished since since. Failed counts both failed and dead-lettered rows.
+func (r *Repository) GetQueueOverview(ctx context.Context, since time.Time, topTenants int) (*db.QueueOverview, error) {
+	var o db.QueueOverview
+	err := r.db.sql.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW(6))), 0),
+			COALESCE(SUM(status = 'pending' AND next_retry_at > NOW(6)), 0),
+			COALESCE(SUM(status = 'processing'), 0),
+			COALESCE(SUM(status = 'held'), 0),
+			MIN(CASE WHEN status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW(6))
+				THEN COALESCE(next_retry_at, created_at) END)
+		FROM notifications
+		WHERE status IN ('pending', 'processing', 'held')
+	`).Scan(&o.Due, &o.Scheduled, &o.Processing, &o.Held, &o.OldestDueAt)
+	if err != nil {
+		return nil, fmt.Errorf("query queue depth: %w", err)
+	}
+
+	rows, err := r.db.sql.QueryContext(ctx, `
+		SELECT channel, SUM(status = 'sent'), SUM(status <> 'sent')
+		FROM notifications
+		WHERE status IN ('sent', 'failed', 'dead_lettered') AND updated_at >= ?
+		GROUP BY channel
+		ORDER BY channel
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("query channel throughput: %w", err)
+	}
+	defer rows.Close()
+
+	o.Channels = []*db.ChannelThroughput{}
+	for rows.Next() {
+		var c db.ChannelThroughput
+		if err := rows.Scan(&c.Channel, &c.Sent, &c.Failed); err != nil {
+			return nil, fmt.Errorf("scan channel throughput: %w", err)
+		}
+		c.ErrorRate = float64(c.Failed) / float64(c.Sent+c.Failed)
+		o.Channels = append(o.Channels, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan channel throughput: %w", err)
+	}
+
+	rows, err = r.db.sql.QueryContext(ctx, `
+		SELECT tenant_id, SUM(status <> 'sent') AS failed, COUNT(*)
+		FROM notifications
+		WHERE status IN ('sent', 'failed', 'dead_lettered') AND updated_at >= ?
+		GROUP BY tenant_id
+		HAVING failed > 0
+		ORDER BY failed DESC, tenant_id
+		LIMIT ?
+	`, since, topTenants)
+	if err != nil {
+		return nil, fmt.Errorf("query failing tenants: %w", err)
+	}
+	defer rows.Close()
+
+	o.TopFailingTenants = []*db.TenantFailures{}
+	for rows.Next() {
+		var t db.TenantFailures
+		if err := rows.Scan(&t.TenantID, &t.Failed, &t.Finished); err != nil {
+			return nil, fmt.Errorf("scan failing tenant: %w", err)
+		}
+		t.ErrorRate = float64(t.Failed) / float64(t.Finished)
+		o.TopFailingTenants = append(o.TopFailingTenants, &t)
+	}
+
+	return &o, rows.Err()
+}
+
+// GetBacklog counts due notifications and unresolved dead letters.
+func (r *Repository) GetBacklog(ctx context.Context) (*db.Backlog, error) {
+	b := db.Backlog{Channels: map[string]int64{}}
+	rows, err := r.db.sql.QueryContext(ctx, `
+		SELECT channel, COUNT(*), MIN(COALESCE(next_retry_at, created_at))
+		FROM notifications
+		WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW(6))
+		GROUP BY channel
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query backlog: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var channel string
+		var due int64
+		var oldest time.Time
+		if err := rows.Scan(&channel, &due, &oldest); err != nil {
+			return nil, fmt.Errorf("scan backlog: %w", err)
+		}
+		b.AddDue(channel, due, oldest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan backlog: %w", err)
+	}
+
+	err = r.db.sql.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM dead_letter_notifications WHERE status = 'pending'
+	`).Scan(&b.DeadLetters)
+	if err != nil {
+		return nil, fmt.Errorf("query dead letter backlog: %w", err)
+	}
+	return &b, nil
+}
+
+const tenantRateLimitColumns = `tenant_id, burst, retry_after_seconds, created_at, updated_at`
+
+func scanTenantRateLimit(row scanner) (*db.TenantRateLimit, error) {
+	var l db.TenantRateLimit
+	err := row.Scan(&l.TenantID, &l.Burst, &l.RetryAfterSeconds, &l.CreatedAt, &l.UpdatedAt)
+	return &l, err
+}
+
+// GetTenantRateLimit returns the tenant's rate limit override.
+func (r *Repository) GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*db.TenantRateLimit, error) {
+	query := `SELECT ` + tenantRateLimitColumns + ` FROM tenant_rate_limits WHERE tenant_id = ?`
+
+	l, err := scanTenantRateLimit(r.db.sql.QueryRowContext(ctx, query, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNoTenantRateLimit
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tenant rate limit: %w", err)
+	}
+
+	return l, nil
+}
+
+// UpsertTenantRateLimit sets the tenant's rate limit override.
+func (r *Repository) UpsertTenantRateLimit(ctx context.Context, l *db.TenantRateLimit) error {
+	_, err := r.db.sql.ExecContext(ctx, `
+		INSERT INTO tenant_rate_limits (tenant_id, burst, retry_after_seconds)
+		VALUES (?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE burst = new.burst,
+			retry_after_seconds = new.retry_after_seconds,
+			updated_at = NOW(6)
+	`, l.TenantID, l.Burst, l.RetryAfterSeconds)
+	if err != nil {
+		return fmt.Errorf("upsert tenant rate limit: %w", err)
+	}
+
+	saved, err := scanTenantRateLimit(r.db.sql.QueryRowContext(ctx,
+		`SELECT `+tenantRateLimitColumns+` FROM tenant_rate_limits WHERE tenant_id = ?`, l.TenantID))
+	if err != nil {
+		return fmt.Errorf("upsert tenant rate limit: %w", err)
+	}
+
+	*l = *saved
+	return nil
+}
+
+// DeleteTenantRateLimit removes the tenant's override, putting it back on
+// the defaults.
+func (r *Repository) DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := r.db.sql.ExecContext(ctx, `DELETE FROM tenant_rate_limits WHERE tenant_id = ?`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete tenant rate limit: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrNoTenantRateLimit
+	}
+
+	return nil
+}
+
+// ListTenantRateLimits returns every rate limit override.
+func (r *Repository) ListTenantRateLimits(ctx context.Context) ([]*db.TenantRateLimit, error) {
+	rows, err := r.db.sql.QueryContext(ctx, `SELECT `+tenantRateLimitColumns+` FROM tenant_rate_limits ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant rate limits: %w", err)
+	}
+	defer rows.Close()
+
+	var limits []*db.TenantRateLimit
+	for rows.Next() {
+		l, err := scanTenantRateLimit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan tenant rate limit: %w", err)
+		}
+		limits = append(limits, l)
+	}
+
+	return limits, rows.Err()
+}
+
+const importColumns = `
+	id, tenant_id, channel, format, source, status, total_rows, created_rows,
+	failed_rows, row_errors, error, created_at, updated_at, finished_at`
+
+func scanImport(row scanner) (*db.NotificationImport, error) {
+	var imp db.NotificationImport
+	var rowErrors []byte
+	err := row.Scan(&imp.ID, &imp.TenantID, &imp.Channel, &imp.Format, &imp.Source, &imp.Status,
+		&imp.TotalRows, &imp.CreatedRows, &imp.FailedRows, &rowErrors, &imp.Error,
+		&imp.CreatedAt, &imp.UpdatedAt, &imp.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rowErrors, &imp.RowErrors); err != nil {
+		return nil, fmt.Errorf("decode row errors: %w", err)
+	}
+	return &imp, nil
+}
+
+// importRowErrors encodes an import's row errors, as '[]' when there are
+// none.
+func importRowErrors(errs []db.ImportRowError) ([]byte, error) {
+	if errs == nil {
+		errs = []db.ImportRowError{}
+	}
+	return json.Marshal(errs)
+}
+
+// CreateImport records a new import job. imp.ID is generated when unset.
+func (r *Repository) CreateImport(ctx context.Context, imp *db.NotificationImport) error {
+	if imp.ID == uuid.Nil {
+		imp.ID = uuid.New()
+	}
+	rowErrors, err := importRowErrors(imp.RowErrors)
+	if err != nil {
+		return fmt.Errorf("encode row errors: %w", err)
+	}
+
+	query := `
+		INSERT INTO notification_imports (
+			id, tenant_id, channel, format, source, status, row_errors, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ts := now()
+	_, err = r.db.sql.ExecContext(ctx, query,
+		imp.ID, imp.TenantID, imp.Channel, imp.Format, imp.Source, imp.Status, rowErrors, ts, ts,
+	)
+	if err != nil {
+		return fmt.Errorf("insert import: %w", err)
+	}
+	imp.CreatedAt, imp.UpdatedAt = ts, ts
+
+	return nil
+}
+
+// GetImport returns an import job by ID.
+func (r *Repository) GetImport(ctx context.Context, id uuid.UUID) (*db.NotificationImport, error) {
+	query := `SELECT ` + importColumns + ` FROM notification_imports WHERE id = ?`
+
+	imp, err := scanImport(r.db.sql.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrImportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get import: %w", err)
+	}
+
+	return imp, nil
+}
+
+// UpdateImport saves an import's progress: its status, row counts, row
+// errors, error and finish time.
+func (r *Repository) UpdateImport(ctx context.Context, imp *db.NotificationImport) error {
+	rowErrors, err := importRowErrors(imp.RowErrors)
+	if err != nil {
+		return fmt.Errorf("encode row errors: %w", err)
+	}
+
+	// updated_at is set explicitly: MySQL reports an UPDATE that changes
+	// nothing as zero affected rows, which would read as not found.
+	query := `
+		UPDATE notification_imports
+		SET status = ?, total_rows = ?, created_rows = ?, failed_rows = ?,
+		    row_errors = ?, error = ?, finished_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	ts := now()
+	result, err := r.db.sql.ExecContext(ctx, query,
+		imp.Status, imp.TotalRows, imp.CreatedRows, imp.FailedRows,
+		rowErrors, imp.Error, imp.FinishedAt, ts, imp.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update import: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return db.ErrImportNotFound
+	}
+	imp.UpdatedAt = ts
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// newTestRepository connects to the MySQL 8.0.20+ server in MYSQL_TEST_DSN,
+// skipping the test when it is unset. It takes a go-sql-driver DSN for a
+// database the tests may write to, e.g.
+//
+//	MYSQL_TEST_DSN='root:nimbus@tcp(localhost:3306)/nimbus_test' go test ./internal/db/mysql/
+//
+// migrations/mysql is applied to it first, as cmd/migrator would. Each test
+// works in its own tenant and deletes its rows afterwards.
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN not set")
+	}
+	ctx := context.Background()
+
+	mc, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_TEST_DSN: %v", err)
+	}
+	migrate(t, mc.Clone())
+
+	host, port, err := net.SplitHostPort(mc.Addr)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_TEST_DSN address: %v", err)
+	}
+	portNum, _ := strconv.Atoi(port)
+	database, err := New(ctx, db.Config{
+		Host:     host,
+		Port:     portNum,
+		User:     mc.User,
+		Password: mc.Passwd,
+		Database: mc.DBName,
+		SSLMode:  "disable",
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(database.Close)
+	return NewRepository(database, zap.NewNop())
+}
+
+// migrate applies the migrations schema_migrations doesn't list yet.
+func migrate(t *testing.T, mc *gomysql.Config) {
+	t.Helper()
+	ctx := context.Background()
+	mc.MultiStatements = true
+	conn, err := sql.Open("mysql", mc.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open migration connection: %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name VARCHAR(255) NOT NULL PRIMARY KEY,
+			applied_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+		)
+	`); err != nil {
+		t.Fatalf("failed to create schema_migrations: %v", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join("..", "..", "..", "migrations", "mysql", "*.up.sql"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no MySQL migrations found: %v", err)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		name := filepath.Base(path)
+		var applied bool
+		if err := conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = ?)", name).Scan(&applied); err != nil {
+			t.Fatalf("failed to check %s: %v", name, err)
+		}
+		if applied {
+			continue
+		}
+		script, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(ctx, string(script)); err != nil {
+			t.Fatalf("failed to apply %s: %v", name, err)
+		}
+		if _, err := conn.ExecContext(ctx, "INSERT IGNORE INTO schema_migrations(name) VALUES(?)", name); err != nil {
+			t.Fatalf("failed to record %s: %v", name, err)
+		}
+	}
+}
+
+// newTestTenant returns a fresh tenant ID whose notifications and usage are
+// deleted when the test ends.
+func newTestTenant(t *testing.T, repo *Repository) uuid.UUID {
+	t.Helper()
+	tenantID := uuid.New()
+	t.Cleanup(func() {
+		for _, table := range []string{"notifications", "tenant_daily_usage"} {
+			_, _ = repo.db.SQL().ExecContext(context.Background(), "DELETE FROM "+table+" WHERE tenant_id = ?", tenantID)
+		}
+	})
+	return tenantID
+}
+
+func newTestNotification(tenantID uuid.UUID) *db.Notification {
+	return &db.Notification{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+		UserID:   uuid.New(),
+		Channel:  db.ChannelEmail,
+		Payload:  json.RawMessage(`{"to":"user@example.com","subject":"Hi","body":"Hello"}`),
+		Status:   db.StatusPending,
+	}
+}
+
+// claimOwn claims pending notifications and returns the ones among ids; other
+// tests' rows in a shared database are put back.
+func claimOwn(t *testing.T, repo *Repository, ids ...uuid.UUID) []*db.Notification {
+	t.Helper()
+	ctx := context.Background()
+	claimed, err := repo.ClaimPendingNotifications(ctx, 1000)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	want := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var own []*db.Notification
+	for _, n := range claimed {
+		if want[n.ID] {
+			own = append(own, n)
+			continue
+		}
+		_ = repo.UpdateNotificationStatus(ctx, n.ID, db.StatusPending, n.Attempt, n.ErrorMessage, n.NextRetryAt)
+	}
+	return own
+}
+
+func TestRepository_CreateAndGetNotification(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	tenantID := newTestTenant(t, repo)
+
+	notif := newTestNotification(tenantID)
+	notif.Tags = []string{"welcome"}
+	if err := repo.CreateNotification(ctx, notif); err != nil {
+		t.Fatalf("failed to create notification: %v", err)
+	}
+
+	got, err := repo.GetNotificationForTenant(ctx, tenantID, notif.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusPending || got.Channel != db.ChannelEmail {
+		t.Errorf("unexpected notification: %+v", got)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "welcome" {
+		t.Errorf("expected tags [welcome], got %v", got.Tags)
+	}
+
+	if _, err := repo.GetNotificationForTenant(ctx, uuid.New(), notif.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected another tenant's lookup to be not found, got %v", err)
+	}
+	if _, err := repo.GetNotification(ctx, uuid.New()); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected a missing notification to be not found, got %v", err)
+	}
+}
+
+func TestRepository_ClaimAndUpdate(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	tenantID := newTestTenant(t, repo)
+
+	first := newTestNotification(tenantID)
+	second := newTestNotification(tenantID)
+	for _, n := range []*db.Notification{first, second} {
+		if err := repo.CreateNotification(ctx, n); err != nil {
+			t.Fatalf("failed to create notification: %v", err)
+		}
+	}
+
+	claimed := claimOwn(t, repo, first.ID, second.ID)
+	if len(claimed) != 2 {
+		t.Fatalf("expected 2 claimed, got %d", len(claimed))
+	}
+	for _, n := range claimed {
+		if n.Status != db.StatusProcessing {
+			t.Errorf("expected claimed rows to be processing, got %s", n.Status)
+		}
+	}
+	if again := claimOwn(t, repo, first.ID, second.ID); len(again) != 0 {
+		t.Fatalf("expected nothing left to claim, got %d", len(again))
+	}
+
+	// A failed attempt goes back to pending, but isn't claimable before its
+	// retry time.
+	errMsg := "mailbox full"
+	retryAt := time.Now().Add(time.Hour)
+	if err := repo.UpdateNotificationStatus(ctx, first.ID, db.StatusPending, 1, &errMsg, &retryAt); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	if again := claimOwn(t, repo, first.ID); len(again) != 0 {
+		t.Fatalf("expected a retry not yet due to stay unclaimed, got %d", len(again))
+	}
+	got, err := repo.GetNotification(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusPending || got.Attempt != 1 || got.ErrorMessage == nil || *got.ErrorMessage != errMsg {
+		t.Errorf("unexpected notification after the failed attempt: %+v", got)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if err := repo.UpdateNotificationStatus(ctx, first.ID, db.StatusPending, 1, &errMsg, &past); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	if again := claimOwn(t, repo, first.ID); len(again) != 1 {
+		t.Fatalf("expected the due retry to be claimed, got %d", len(again))
+	}
+
+	if err := repo.MarkNotificationSent(ctx, second.ID, 1, "ses", "msg-1", "", 0.0001); err != nil {
+		t.Fatalf("failed to mark sent: %v", err)
+	}
+	got, err = repo.GetNotification(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusSent || got.Attempt != 1 || got.Provider != "ses" || got.ProviderMessageID != "msg-1" {
+		t.Errorf("unexpected notification after the send: %+v", got)
+	}
+
+	if err := repo.UpdateNotificationStatus(ctx, uuid.New(), db.StatusSent, 1, nil, nil); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected updating a missing notification to be not found, got %v", err)
+	}
+}
+
+func TestRepository_TenantScopedStatusUpdates(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	tenantID, otherTenant := newTestTenant(t, repo), uuid.New()
+
+	notif := newTestNotification(tenantID)
+	if err := repo.CreateNotification(ctx, notif); err != nil {
+		t.Fatalf("failed to create notification: %v", err)
+	}
+	// Provider message IDs must be unique across runs against a shared
+	// database.
+	providerMessageID := uuid.NewString()
+	if err := repo.MarkNotificationSent(ctx, notif.ID, 1, "ses", providerMessageID, "", 0); err != nil {
+		t.Fatalf("failed to mark sent: %v", err)
+	}
+
+	if _, err := repo.GetNotificationByProviderMessageIDForTenant(ctx, otherTenant, "ses", providerMessageID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected another tenant's provider lookup to be not found, got %v", err)
+	}
+	if got, err := repo.GetNotificationByProviderMessageIDForTenant(ctx, tenantID, "ses", providerMessageID); err != nil || got.ID != notif.ID {
+		t.Errorf("expected the owning tenant to find the notification, got %v (%v)", got, err)
+	}
+
+	if err := repo.UpdateNotificationStatusForTenant(ctx, otherTenant, notif.ID, db.StatusFailed, 2, nil, nil); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected another tenant's update to be not found, got %v", err)
+	}
+	updated, err := repo.UpdateNotificationStatusesForTenant(ctx, otherTenant, []db.StatusUpdate{{ID: notif.ID, Status: db.StatusFailed, Attempt: 2}})
+	if err != nil || len(updated) != 0 {
+		t.Errorf("expected another tenant's batch to update nothing, got %v (%v)", updated, err)
+	}
+	got, err := repo.GetNotification(ctx, notif.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusSent {
+		t.Errorf("expected the notification untouched, got %s", got.Status)
+	}
+
+	updated, err = repo.UpdateNotificationStatusesForTenant(ctx, tenantID, []db.StatusUpdate{{ID: notif.ID, Status: db.StatusFailed, Attempt: 2}})
+	if err != nil || len(updated) != 1 {
+		t.Errorf("expected the owning tenant's batch to update the notification, got %v (%v)", updated, err)
+	}
+	if err := repo.UpdateNotificationStatusForTenant(ctx, tenantID, notif.ID, db.StatusSent, 3, nil, nil); err != nil {
+		t.Errorf("expected the owning tenant's update to succeed, got %v", err)
+	}
+}
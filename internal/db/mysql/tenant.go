@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// resetTimeout bounds clearing a connection's tenant on release.
+const resetTimeout = 5 * time.Second
+
+// session is what queries run on: the pool, or a single connection
+// scoped to a tenant.
+type session interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// session returns where to run writes to notifications for ctx. For a
+// tenant-scoped context (db.WithTenant) it is a dedicated connection with
+// @nimbus_tenant_id set, which the notification_events triggers read to
+// record the tenant as the actor; release clears it. Other contexts get
+// the pool and pay no extra round trips.
+func (d *DB) session(ctx context.Context) (s session, release func(), err error) {
+	tenantID, ok := db.TenantFromContext(ctx)
+	if !ok {
+		return d.sql, func() {}, nil
+	}
+
+	conn, err := d.sql.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SET @nimbus_tenant_id = ?", tenantID.String()); err != nil {
+		d.discard(conn)
+		return nil, nil, err
+	}
+	return conn, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+		defer cancel()
+		if _, err := conn.ExecContext(ctx, "SET @nimbus_tenant_id = NULL"); err != nil {
+			d.logger.Warn("failed to clear connection tenant, closing it", zap.Error(err))
+			d.discard(conn)
+			return
+		}
+		_ = conn.Close()
+	}, nil
+}
+
+// discard returns conn to the pool marked bad, so the pool closes it
+// instead of reusing it.
+func (d *DB) discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	gomysql "github.com/go-sql-driver/mysql"
+)
+
+// stringList stores a []string in a JSON array column, MySQL's stand-in for
+// the TEXT[] columns on Postgres. nil is stored as [].
+type stringList []string
+
+// Value implements driver.Valuer.
+func (l stringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *stringList) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	default:
+		return fmt.Errorf("cannot scan %T into a string list", src)
+	}
+}
+
+// jsonOrEmpty maps an absent JSON value to '{}' for the NOT NULL JSON
+// columns. JSON is sent as a string: the driver sends []byte as binary,
+// which MySQL refuses to store in a JSON column.
+func jsonOrEmpty(v json.RawMessage) string {
+	if len(v) == 0 {
+		return "{}"
+	}
+	return string(v)
+}
+
+// isDuplicateEntry reports whether err is a unique key violation.
+func isDuplicateEntry(err error) bool {
+	var myErr *gomysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == errDuplicateEntry
+}
+
+// placeholders returns n comma-separated ? placeholders, for IN lists.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
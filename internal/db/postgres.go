@@ -9,6 +9,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// resetTimeout bounds clearing a connection's tenant scope on release.
+const resetTimeout = 5 * time.Second
+
 // DB wraps the pgx connection pool
 type DB struct {
 	pool   *pgxpool.Pool
@@ -23,6 +26,10 @@ type Config struct {
 	Database string
 	SSLMode  string
 	Port     int
+
+	// SlowQueryThreshold logs statements that take at least this long;
+	// 0 turns the slow query log off. Query durations are always recorded.
+	SlowQueryThreshold time.Duration
 }
 
 // New creates a new database connection pool
@@ -54,6 +61,14 @@ func New(ctx context.Context, cfg Config, logger *zap.Logger) (*DB, error) {
 	poolConfig.MaxConnIdleTime = 30 * time.Minute  // Close idle connections
 	poolConfig.HealthCheckPeriod = 1 * time.Minute // Check connection health
 
+	// Row-level security: scope connections to the tenant in the context
+	scoper := &tenantScoper{logger: logger}
+	poolConfig.BeforeAcquire = scoper.beforeAcquire
+	poolConfig.AfterRelease = scoper.afterRelease
+
+	// Per-query duration histograms and the slow query log
+	poolConfig.ConnConfig.Tracer = &queryTracer{logger: logger, slowThreshold: cfg.SlowQueryThreshold}
+
 	// Create the pool
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
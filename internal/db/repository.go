@@ -2,14 +2,22 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/observ"
 )
 
+// pgUniqueViolation is the Postgres SQLSTATE for a unique constraint failure.
+const pgUniqueViolation = "23505"
+
 // Repository handles database operations for notifications
 type Repository struct {
 	db     *DB
@@ -24,14 +32,17 @@ func NewRepository(db *DB, logger *zap.Logger) *Repository {
 	}
 }
 
-// CreateNotification inserts a new notification into the database
+// CreateNotification inserts a new notification into the database. Its log
+// lines rely on the caller having scoped ctx's logger to the notification
+// (see observ.With).
 func (r *Repository) CreateNotification(ctx context.Context, notif *Notification) error {
 	query := `
 		INSERT INTO notifications (
 			id, tenant_id, user_id, channel, payload, 
-			status, attempt, next_retry_at
+			status, attempt, next_retry_at, correlation_id,
+			metadata, tags, sla_seconds, group_key, category
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, ''), NULLIF($14, '')
 		)
 		RETURNING created_at, updated_at
 	`
@@ -47,38 +58,60 @@ func (r *Repository) CreateNotification(ctx context.Context, notif *Notification
 		notif.Status,
 		notif.Attempt,
 		notif.NextRetryAt,
+		notif.CorrelationID,
+		jsonbOrEmpty(notif.Metadata),
+		textArray(notif.Tags),
+		notif.SLASeconds,
+		notif.GroupKey,
+		notif.Category,
 	).Scan(&notif.CreatedAt, &notif.UpdatedAt)
 
 	if err != nil {
-		r.logger.Error("failed to create notification",
-			zap.Error(err),
-			zap.String("notification_id", notif.ID.String()),
-		)
+		observ.Logger(ctx, r.logger).Error("failed to create notification", zap.Error(err))
 		return fmt.Errorf("insert notification: %w", err)
 	}
 
-	r.logger.Info("notification created",
-		zap.String("notification_id", notif.ID.String()),
-		zap.String("tenant_id", notif.TenantID.String()),
+	observ.Logger(ctx, r.logger).Info("notification created",
 		zap.String("channel", notif.Channel),
 	)
 
 	return nil
 }
 
+// ErrNotFound is wrapped by the notification and dead letter lookups
+// (GetNotification, GetDeadLetter, GetNotificationByProviderMessageID and
+// their tenant-scoped variants, GetAPIKey) and by UpdateNotificationStatus
+// when no such row exists, so callers can tell a miss from a failed query.
+var ErrNotFound = errors.New("not found")
+
 // GetNotification retrieves a notification by ID
 func (r *Repository) GetNotification(ctx context.Context, id uuid.UUID) (*Notification, error) {
+	return r.getNotification(ctx, id, nil)
+}
+
+// GetNotificationForTenant retrieves a notification by ID only if it
+// belongs to tenantID; another tenant's notification is not found. Callers
+// serving an authenticated tenant should use it over GetNotification.
+func (r *Repository) GetNotificationForTenant(ctx context.Context, tenantID, id uuid.UUID) (*Notification, error) {
+	return r.getNotification(ctx, id, &tenantID)
+}
+
+// getNotification loads a notification, scoped to tenantID unless it is nil.
+func (r *Repository) getNotification(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*Notification, error) {
 	query := `
 		SELECT 
 			id, tenant_id, user_id, channel, payload,
 			status, attempt, error_message, next_retry_at,
-			created_at, updated_at
+			created_at, updated_at, correlation_id, metadata, tags,
+			COALESCE(provider, ''), COALESCE(provider_message_id, ''), cost,
+			COALESCE(archive_key, ''), sla_seconds, sla_breached,
+			COALESCE(group_key, ''), collapsed_into, COALESCE(category, '')
 		FROM notifications
-		WHERE id = $1
+		WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
 	`
 
 	var notif Notification
-	err := r.db.Pool().QueryRow(ctx, query, id).Scan(
+	err := r.db.Pool().QueryRow(ctx, query, id, tenantID).Scan(
 		&notif.ID,
 		&notif.TenantID,
 		&notif.UserID,
@@ -90,10 +123,22 @@ func (r *Repository) GetNotification(ctx context.Context, id uuid.UUID) (*Notifi
 		&notif.NextRetryAt,
 		&notif.CreatedAt,
 		&notif.UpdatedAt,
+		&notif.CorrelationID,
+		&notif.Metadata,
+		&notif.Tags,
+		&notif.Provider,
+		&notif.ProviderMessageID,
+		&notif.Cost,
+		&notif.ArchiveKey,
+		&notif.SLASeconds,
+		&notif.SLABreached,
+		&notif.GroupKey,
+		&notif.CollapsedInto,
+		&notif.Category,
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("notification not found: %s", id)
+		return nil, fmt.Errorf("notification %w: %s", ErrNotFound, id)
 	}
 
 	if err != nil {
@@ -115,14 +160,42 @@ func (r *Repository) UpdateNotificationStatus(
 	attempt int,
 	errorMsg *string,
 	nextRetryAt *time.Time,
+) error {
+	return r.updateNotificationStatus(ctx, id, nil, status, attempt, errorMsg, nextRetryAt)
+}
+
+// UpdateNotificationStatusForTenant is UpdateNotificationStatus for a
+// notification that belongs to tenantID; another tenant's notification is
+// not found.
+func (r *Repository) UpdateNotificationStatusForTenant(
+	ctx context.Context,
+	tenantID, id uuid.UUID,
+	status string,
+	attempt int,
+	errorMsg *string,
+	nextRetryAt *time.Time,
+) error {
+	return r.updateNotificationStatus(ctx, id, &tenantID, status, attempt, errorMsg, nextRetryAt)
+}
+
+// updateNotificationStatus updates a notification's status, scoped to
+// tenantID unless it is nil.
+func (r *Repository) updateNotificationStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	tenantID *uuid.UUID,
+	status string,
+	attempt int,
+	errorMsg *string,
+	nextRetryAt *time.Time,
 ) error {
 	query := `
 		UPDATE notifications
 		SET status = $1, attempt = $2, error_message = $3, next_retry_at = $4
-		WHERE id = $5
+		WHERE id = $5 AND ($6::uuid IS NULL OR tenant_id = $6)
 	`
 
-	result, err := r.db.Pool().Exec(ctx, query, status, attempt, errorMsg, nextRetryAt, id)
+	result, err := r.db.Pool().Exec(ctx, query, status, attempt, errorMsg, nextRetryAt, id, tenantID)
 	if err != nil {
 		r.logger.Error("failed to update notification status",
 			zap.Error(err),
@@ -131,6 +204,223 @@ func (r *Repository) UpdateNotificationStatus(
 		return fmt.Errorf("update notification status: %w", err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("notification %w: %s", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// ErrNotificationNotCancellable is returned by CancelPendingNotification
+// when the notification is no longer pending or held: the worker has
+// picked it up, or it is already finished.
+var ErrNotificationNotCancellable = errors.New("notification is not pending")
+
+// CancelPendingNotification ends a pending or held notification 'failed'
+// with CancelledError, so a scheduled send never fires. The status check is
+// part of the UPDATE, so it can't race the worker claiming the row.
+func (r *Repository) CancelPendingNotification(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET status = 'failed', error_message = $1, next_retry_at = NULL
+		WHERE id = $2 AND status IN ('pending', 'held')
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, CancelledError, id)
+	if err != nil {
+		return fmt.Errorf("cancel notification: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotificationNotCancellable
+	}
+	return nil
+}
+
+// ErrNotificationNotEditable is returned by EditPendingNotification when the
+// notification is no longer pending or has changed since the caller read it.
+var ErrNotificationNotEditable = errors.New("notification is not pending or has changed")
+
+// EditPendingNotification applies edit to a pending notification and records
+// the previous values in notification_edits, in one transaction. The UPDATE
+// only matches while the row is pending, still at edit.UpdatedAt and owned by
+// edit.TenantID, so it can't race the worker claiming it or another edit.
+func (r *Repository) EditPendingNotification(ctx context.Context, id uuid.UUID, edit NotificationEdit) (*Notification, error) {
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var oldPayload json.RawMessage
+	var oldSendAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT payload, next_retry_at
+		FROM notifications
+		WHERE id = $1 AND tenant_id = $3 AND status = 'pending' AND updated_at = $2
+		FOR UPDATE
+	`, id, edit.UpdatedAt, edit.TenantID).Scan(&oldPayload, &oldSendAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotificationNotEditable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock notification: %w", err)
+	}
+
+	newPayload := edit.Payload
+	if newPayload == nil {
+		newPayload = oldPayload
+	}
+
+	query := `
+		UPDATE notifications
+		SET payload = $1, next_retry_at = $2
+		WHERE id = $3 AND tenant_id = $4
+		RETURNING
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, error_message, next_retry_at,
+			created_at, updated_at, correlation_id, metadata, tags
+	`
+
+	var notif Notification
+	err = tx.QueryRow(ctx, query, newPayload, edit.SendAt, id, edit.TenantID).Scan(
+		&notif.ID,
+		&notif.TenantID,
+		&notif.UserID,
+		&notif.Channel,
+		&notif.Payload,
+		&notif.Status,
+		&notif.Attempt,
+		&notif.ErrorMessage,
+		&notif.NextRetryAt,
+		&notif.CreatedAt,
+		&notif.UpdatedAt,
+		&notif.CorrelationID,
+		&notif.Metadata,
+		&notif.Tags,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update notification: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO notification_edits (
+			notification_id, old_payload, new_payload, old_send_at, new_send_at, request_id
+		) VALUES ($1, $2, $3, $4, $5, $6)
+	`, id, oldPayload, newPayload, oldSendAt, edit.SendAt, edit.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("insert notification edit: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	observ.Logger(ctx, r.logger).Info("pending notification edited",
+		zap.String("notification_id", id.String()),
+		zap.Bool("payload_changed", edit.Payload != nil),
+	)
+
+	return &notif, nil
+}
+
+// UpdateNotificationStatuses applies a batch of status updates in one
+// statement and returns the IDs that matched a notification. IDs missing
+// from the result don't exist. Like UpdateNotificationStatus it clears
+// next_retry_at. IDs must be unique within the batch.
+func (r *Repository) UpdateNotificationStatuses(ctx context.Context, updates []StatusUpdate) ([]uuid.UUID, error) {
+	return r.updateNotificationStatuses(ctx, updates, nil)
+}
+
+// UpdateNotificationStatusesForTenant is UpdateNotificationStatuses limited
+// to tenantID's notifications; another tenant's IDs are left out of the
+// result as if they didn't exist.
+func (r *Repository) UpdateNotificationStatusesForTenant(ctx context.Context, tenantID uuid.UUID, updates []StatusUpdate) ([]uuid.UUID, error) {
+	return r.updateNotificationStatuses(ctx, updates, &tenantID)
+}
+
+// updateNotificationStatuses applies a batch of status updates, scoped to
+// tenantID unless it is nil.
+func (r *Repository) updateNotificationStatuses(ctx context.Context, updates []StatusUpdate, tenantID *uuid.UUID) ([]uuid.UUID, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(updates))
+	statuses := make([]string, len(updates))
+	attempts := make([]int32, len(updates))
+	errorMsgs := make([]*string, len(updates))
+	for i, u := range updates {
+		ids[i] = u.ID
+		statuses[i] = u.Status
+		attempts[i] = int32(u.Attempt)
+		errorMsgs[i] = u.Error
+	}
+
+	query := `
+		UPDATE notifications n
+		SET status = u.status, attempt = u.attempt, error_message = u.error_message, next_retry_at = NULL
+		FROM unnest($1::uuid[], $2::text[], $3::int[], $4::text[]) AS u(id, status, attempt, error_message)
+		WHERE n.id = u.id AND ($5::uuid IS NULL OR n.tenant_id = $5)
+		RETURNING n.id
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, ids, statuses, attempts, errorMsgs, tenantID)
+	if err != nil {
+		r.logger.Error("failed to batch update notification status",
+			zap.Error(err),
+			zap.Int("count", len(updates)),
+		)
+		return nil, fmt.Errorf("batch update notification status: %w", err)
+	}
+	defer rows.Close()
+
+	updated := make([]uuid.UUID, 0, len(updates))
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan updated id: %w", err)
+		}
+		updated = append(updated, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("batch update notification status: %w", err)
+	}
+
+	return updated, nil
+}
+
+// MarkNotificationSent records a successful send: status 'sent', the attempt
+// count, the provider's message ID when the sender reported one, the archive
+// key when the rendered message was archived, and the estimated cost, which
+// is also added to the tenant's usage for the day.
+func (r *Repository) MarkNotificationSent(ctx context.Context, id uuid.UUID, attempt int, provider, providerMessageID, archiveKey string, cost float64) error {
+	query := `
+		WITH sent AS (
+			UPDATE notifications
+			SET status = 'sent', attempt = $1, error_message = NULL, next_retry_at = NULL,
+			    provider = NULLIF($2, ''), provider_message_id = NULLIF($3, ''), cost = $5,
+			    archive_key = NULLIF($6, ''),
+			    sla_breached = CASE WHEN sla_seconds IS NULL THEN NULL
+			        ELSE NOW() > created_at + sla_seconds * INTERVAL '1 second' END
+			WHERE id = $4
+			RETURNING tenant_id, channel
+		)
+		INSERT INTO tenant_daily_usage (tenant_id, day, channel, sent, cost)
+		SELECT tenant_id, (NOW() AT TIME ZONE 'UTC')::date, channel, 1, $5
+		FROM sent
+		ON CONFLICT (tenant_id, day, channel)
+		DO UPDATE SET sent = tenant_daily_usage.sent + 1, cost = tenant_daily_usage.cost + EXCLUDED.cost
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, attempt, provider, providerMessageID, id, cost, archiveKey)
+	if err != nil {
+		observ.Logger(ctx, r.logger).Error("failed to mark notification sent",
+			zap.Error(err),
+			zap.String("notification_id", id.String()),
+		)
+		return fmt.Errorf("mark notification sent: %w", err)
+	}
+
 	if result.RowsAffected() == 0 {
 		return fmt.Errorf("notification not found: %s", id)
 	}
@@ -138,10 +428,90 @@ func (r *Repository) UpdateNotificationStatus(
 	return nil
 }
 
+// CollapseNotification collapses notif into its group's latest delivery, if
+// there is one: a notification with the same tenant, user, channel and
+// group key, created before notif but no more than window before it, that
+// was sent rather than itself collapsed. notif is then marked sent with
+// collapsed_into pointing at it, and its ID is returned. If there is none,
+// notif is left alone and the result is nil.
+func (r *Repository) CollapseNotification(ctx context.Context, notif *Notification, attempt int, window time.Duration) (*uuid.UUID, error) {
+	query := `
+		WITH leader AS (
+			SELECT id
+			FROM notifications
+			WHERE tenant_id = $2 AND user_id = $3 AND channel = $4 AND group_key = $5
+			  AND status = 'sent' AND collapsed_into IS NULL
+			  AND created_at >= $6 AND (created_at, id) < ($7, $1)
+			ORDER BY created_at DESC
+			LIMIT 1
+		)
+		UPDATE notifications n
+		SET status = 'sent', attempt = $8, error_message = NULL, next_retry_at = NULL,
+		    collapsed_into = leader.id,
+		    sla_breached = CASE WHEN n.sla_seconds IS NULL THEN NULL
+		        ELSE NOW() > n.created_at + n.sla_seconds * INTERVAL '1 second' END
+		FROM leader
+		WHERE n.id = $1
+		RETURNING leader.id
+	`
+
+	var into uuid.UUID
+	err := r.db.Pool().QueryRow(ctx, query,
+		notif.ID, notif.TenantID, notif.UserID, notif.Channel, notif.GroupKey,
+		notif.CreatedAt.Add(-window), notif.CreatedAt, attempt,
+	).Scan(&into)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("collapse notification: %w", err)
+	}
+	return &into, nil
+}
+
+// GetNotificationByProviderMessageID finds the notification a provider
+// message ID belongs to. An empty provider matches any provider; if an ID
+// is somehow reused across providers the most recent notification wins.
+func (r *Repository) GetNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*Notification, error) {
+	return r.getNotificationByProviderMessageID(ctx, provider, providerMessageID, nil)
+}
+
+// GetNotificationByProviderMessageIDForTenant is
+// GetNotificationByProviderMessageID limited to tenantID's notifications;
+// another tenant's notification is not found.
+func (r *Repository) GetNotificationByProviderMessageIDForTenant(ctx context.Context, tenantID uuid.UUID, provider, providerMessageID string) (*Notification, error) {
+	return r.getNotificationByProviderMessageID(ctx, provider, providerMessageID, &tenantID)
+}
+
+// getNotificationByProviderMessageID looks up a provider message ID, scoped
+// to tenantID unless it is nil.
+func (r *Repository) getNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string, tenantID *uuid.UUID) (*Notification, error) {
+	query := `
+		SELECT id
+		FROM notifications
+		WHERE provider_message_id = $1 AND ($2 = '' OR provider = $2)
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var id uuid.UUID
+	err := r.db.Pool().QueryRow(ctx, query, providerMessageID, provider, tenantID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("notification %w for provider message id: %s", ErrNotFound, providerMessageID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query notification by provider message id: %w", err)
+	}
+
+	return r.GetNotification(ctx, id)
+}
+
 // ListNotificationsByTenant retrieves notifications for a tenant with pagination
 func (r *Repository) ListNotificationsByTenant(
 	ctx context.Context,
 	tenantID uuid.UUID,
+	filter NotificationFilter,
 	limit int,
 	offset int,
 ) ([]*Notification, error) {
@@ -149,14 +519,18 @@ func (r *Repository) ListNotificationsByTenant(
 		SELECT 
 			id, tenant_id, user_id, channel, payload,
 			status, attempt, error_message, next_retry_at,
-			created_at, updated_at
+			created_at, updated_at, correlation_id, metadata, tags,
+			COALESCE(provider, ''), COALESCE(provider_message_id, ''), cost,
+			COALESCE(archive_key, ''), sla_seconds, sla_breached,
+			COALESCE(group_key, ''), collapsed_into, COALESCE(category, '')
 		FROM notifications
 		WHERE tenant_id = $1
-		ORDER BY created_at DESC
+		  AND ($4::text = '' OR tags @> ARRAY[$4::text])
+		ORDER BY ` + filter.Sort.OrderBy() + `
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool().Query(ctx, query, tenantID, limit, offset)
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, limit, offset, filter.Tag)
 	if err != nil {
 		return nil, fmt.Errorf("query notifications: %w", err)
 	}
@@ -177,6 +551,18 @@ func (r *Repository) ListNotificationsByTenant(
 			&notif.NextRetryAt,
 			&notif.CreatedAt,
 			&notif.UpdatedAt,
+			&notif.CorrelationID,
+			&notif.Metadata,
+			&notif.Tags,
+			&notif.Provider,
+			&notif.ProviderMessageID,
+			&notif.Cost,
+			&notif.ArchiveKey,
+			&notif.SLASeconds,
+			&notif.SLABreached,
+			&notif.GroupKey,
+			&notif.CollapsedInto,
+			&notif.Category,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan notification: %w", err)
@@ -191,21 +577,44 @@ func (r *Repository) ListNotificationsByTenant(
 	return notifications, nil
 }
 
-func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]*Notification, error) {
+// CountNotificationsByTenant counts the notifications ListNotificationsByTenant
+// pages through with the same filter.
+func (r *Repository) CountNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter NotificationFilter) (int64, error) {
+	var total int64
+	err := r.db.Pool().QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE tenant_id = $1
+		  AND ($2::text = '' OR tags @> ARRAY[$2::text])
+	`, tenantID, filter.Tag).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("count notifications: %w", err)
+	}
+	return total, nil
+}
+
+// SearchNotificationsByUser returns a user's most recent notifications
+// across every tenant, newest first. It is for operator investigations
+// only: the caller must not be tenant-scoped, or row-level security limits
+// the result to that tenant.
+func (r *Repository) SearchNotificationsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*Notification, error) {
 	query := `
 		SELECT 
 			id, tenant_id, user_id, channel, payload,
 			status, attempt, error_message, next_retry_at,
-			created_at, updated_at
+			created_at, updated_at, correlation_id, metadata, tags,
+			COALESCE(provider, ''), COALESCE(provider_message_id, ''), cost,
+			COALESCE(archive_key, ''), sla_seconds, sla_breached,
+			COALESCE(group_key, ''), collapsed_into, COALESCE(category, '')
 		FROM notifications
-		WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW())
-		ORDER BY created_at ASC
-		LIMIT $1
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
 	`
 
-	rows, err := r.db.Pool().Query(ctx, query, limit)
+	rows, err := r.db.Pool().Query(ctx, query, userID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("query pending notifications: %w", err)
+		return nil, fmt.Errorf("query notifications by user: %w", err)
 	}
 	defer rows.Close()
 
@@ -224,6 +633,18 @@ func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]
 			&notif.NextRetryAt,
 			&notif.CreatedAt,
 			&notif.UpdatedAt,
+			&notif.CorrelationID,
+			&notif.Metadata,
+			&notif.Tags,
+			&notif.Provider,
+			&notif.ProviderMessageID,
+			&notif.Cost,
+			&notif.ArchiveKey,
+			&notif.SLASeconds,
+			&notif.SLABreached,
+			&notif.GroupKey,
+			&notif.CollapsedInto,
+			&notif.Category,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan notification: %w", err)
@@ -231,113 +652,375 @@ func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]
 		notifications = append(notifications, &notif)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
 	return notifications, nil
 }
 
-// stuckProcessingTimeout is how long a notification can sit in 'processing'
-// before we assume the worker that claimed it crashed and reclaim the row.
-// It must be comfortably larger than the longest possible single send
-// (SES/SNS/webhook timeout + retries), otherwise we'd reclaim a row that's
-// still being legitimately worked on and double-send it.
-const stuckProcessingTimeout = 5 * time.Minute
-
-// ClaimPendingNotifications atomically claims a batch of notifications for
-// processing. This is the heart of running multiple worker replicas safely.
-//
-// THE PROBLEM IT SOLVES:
-// The naive approach — SELECT pending rows, then UPDATE them to 'processing'
-// in a second statement — has a race window. With N worker replicas polling
-// concurrently, two workers can SELECT the *same* rows before either UPDATEs,
-// and both will send the notification. That breaks our "exactly-once-effect"
-// guarantee and spams users with duplicates.
-//
-// THE FIX:
-// We do the claim in ONE atomic statement using `FOR UPDATE SKIP LOCKED`:
-//
-//		UPDATE ... WHERE id IN (
-//		    SELECT id ... FOR UPDATE SKIP LOCKED
-//		)
-//
-//	  - FOR UPDATE row-locks the selected rows for the duration of the statement.
-//	  - SKIP LOCKED tells Postgres: if a row is already locked by another worker,
-//	    skip it instead of blocking. So each worker gets a DISJOINT set of rows.
-//
-// This is the canonical "queue on top of Postgres" pattern — the same approach
-// used by libraries like Que, GoodJob, and River. No external queue needed for
-// correctness; SQS becomes an optional fan-out, not the source of truth.
-//
-// CRASH RECOVERY:
-// We also reclaim rows stuck in 'processing' longer than stuckProcessingTimeout.
-// If a worker crashes mid-send, its row would otherwise be orphaned forever
-// (the old query only looked at status='pending'). This makes the system
-// self-healing: a crashed worker's in-flight work is picked up by a healthy one.
-//
-// Interview talking point:
-// "The single biggest correctness bug in a multi-replica queue worker is the
-//
-//	SELECT-then-UPDATE race. I close it with FOR UPDATE SKIP LOCKED so each
-//	replica atomically claims a disjoint batch, and I reclaim stuck 'processing'
-//	rows on a timeout so a crashed worker doesn't strand notifications."
-func (r *Repository) ClaimPendingNotifications(ctx context.Context, limit int) ([]*Notification, error) {
+// ListNotificationEvents returns a notification's state transitions, oldest
+// first.
+func (r *Repository) ListNotificationEvents(ctx context.Context, notificationID uuid.UUID) ([]*NotificationEvent, error) {
 	query := `
-		UPDATE notifications
-		SET status = 'processing', updated_at = NOW()
-		WHERE id IN (
-			SELECT id
-			FROM notifications
-			WHERE (status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW()))
-			   OR (status = 'processing' AND updated_at < NOW() - ($2 * INTERVAL '1 second'))
-			ORDER BY created_at ASC
-			LIMIT $1
-			FOR UPDATE SKIP LOCKED
-		)
-		RETURNING
-			id, tenant_id, user_id, channel, payload,
-			status, attempt, error_message, next_retry_at,
-			created_at, updated_at
+		SELECT id, notification_id, COALESCE(from_status, ''), to_status,
+			attempt, error_message, actor, occurred_at
+		FROM notification_events
+		WHERE notification_id = $1
+		ORDER BY id
 	`
 
-	// Pass the timeout as an integer number of seconds and multiply by a
-	// 1-second interval. We deliberately avoid Duration.String() ("5m0s")
-	// because Postgres interval parsing treats a bare "m" as MONTHS, not minutes.
-	reclaimSeconds := int(stuckProcessingTimeout.Seconds())
-
-	rows, err := r.db.Pool().Query(ctx, query, limit, reclaimSeconds)
+	rows, err := r.db.Pool().Query(ctx, query, notificationID)
 	if err != nil {
-		return nil, fmt.Errorf("claim pending notifications: %w", err)
+		return nil, fmt.Errorf("query notification events: %w", err)
 	}
 	defer rows.Close()
 
-	var notifications []*Notification
+	var events []*NotificationEvent
 	for rows.Next() {
-		var notif Notification
+		var e NotificationEvent
 		if err := rows.Scan(
-			&notif.ID,
-			&notif.TenantID,
-			&notif.UserID,
-			&notif.Channel,
-			&notif.Payload,
-			&notif.Status,
-			&notif.Attempt,
-			&notif.ErrorMessage,
-			&notif.NextRetryAt,
-			&notif.CreatedAt,
-			&notif.UpdatedAt,
+			&e.ID,
+			&e.NotificationID,
+			&e.FromStatus,
+			&e.ToStatus,
+			&e.Attempt,
+			&e.Error,
+			&e.Actor,
+			&e.OccurredAt,
 		); err != nil {
-			return nil, fmt.Errorf("scan claimed notification: %w", err)
+			return nil, fmt.Errorf("scan notification event: %w", err)
 		}
-		notifications = append(notifications, &notif)
+		events = append(events, &e)
 	}
-
-	return notifications, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification events: %w", err)
+	}
+	return events, nil
 }
 
-// MoveToDeadLetter moves a failed notification to the dead letter queue
-func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification, lastError string) (*DeadLetterNotification, error) {
-	// Start a transaction
-	tx, err := r.db.Pool().Begin(ctx)
+// AddNotificationAnnotation records a's detail on its notification.
+func (r *Repository) AddNotificationAnnotation(ctx context.Context, a *NotificationAnnotation) error {
+	query := `
+		INSERT INTO notification_annotations (notification_id, tenant_id, provider, kind, attempt, data)
+		SELECT id, tenant_id, $2, $3, $4, $5
+		FROM notifications
+		WHERE id = $1
+		RETURNING id, recorded_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query, a.NotificationID, a.Provider, a.Kind, a.Attempt, jsonbOrEmpty(a.Data)).Scan(&a.ID, &a.RecordedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("add notification annotation: notification %s not found", a.NotificationID)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("begin transaction: %w", err)
+		return fmt.Errorf("add notification annotation: %w", err)
+	}
+	return nil
+}
+
+// AnnotateByProviderMessageID records a on the notification a.Provider sent
+// as providerMessageID, reporting false if there is none (e.g. it was sent
+// by another deployment sharing the provider account).
+func (r *Repository) AnnotateByProviderMessageID(ctx context.Context, providerMessageID string, a *NotificationAnnotation) (bool, error) {
+	query := `
+		INSERT INTO notification_annotations (notification_id, tenant_id, provider, kind, attempt, data)
+		SELECT id, tenant_id, $2, $3, $4, $5
+		FROM notifications
+		WHERE provider_message_id = $1 AND provider = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+		RETURNING id, notification_id, recorded_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query, providerMessageID, a.Provider, a.Kind, a.Attempt, jsonbOrEmpty(a.Data)).Scan(&a.ID, &a.NotificationID, &a.RecordedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("annotate by provider message id: %w", err)
+	}
+	return true, nil
+}
+
+// ListNotificationAnnotations returns a notification's provider
+// annotations, oldest first.
+func (r *Repository) ListNotificationAnnotations(ctx context.Context, notificationID uuid.UUID) ([]*NotificationAnnotation, error) {
+	query := `
+		SELECT id, notification_id, provider, kind, attempt, data, recorded_at
+		FROM notification_annotations
+		WHERE notification_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("query notification annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []*NotificationAnnotation
+	for rows.Next() {
+		var a NotificationAnnotation
+		if err := rows.Scan(&a.ID, &a.NotificationID, &a.Provider, &a.Kind, &a.Attempt, &a.Data, &a.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan notification annotation: %w", err)
+		}
+		annotations = append(annotations, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]*Notification, error) {
+	query := `
+		SELECT 
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, error_message, next_retry_at,
+			created_at, updated_at, correlation_id, metadata, tags
+		FROM notifications
+		WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW())
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*Notification
+	for rows.Next() {
+		var notif Notification
+		err := rows.Scan(
+			&notif.ID,
+			&notif.TenantID,
+			&notif.UserID,
+			&notif.Channel,
+			&notif.Payload,
+			&notif.Status,
+			&notif.Attempt,
+			&notif.ErrorMessage,
+			&notif.NextRetryAt,
+			&notif.CreatedAt,
+			&notif.UpdatedAt,
+			&notif.CorrelationID,
+			&notif.Metadata,
+			&notif.Tags,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		notifications = append(notifications, &notif)
+	}
+
+	return notifications, nil
+}
+
+// ClaimPendingNotifications atomically claims a batch of notifications for
+// processing. This is the heart of running multiple worker replicas safely.
+//
+// THE PROBLEM IT SOLVES:
+// The naive approach — SELECT pending rows, then UPDATE them to 'processing'
+// in a second statement — has a race window. With N worker replicas polling
+// concurrently, two workers can SELECT the *same* rows before either UPDATEs,
+// and both will send the notification. That breaks our "exactly-once-effect"
+// guarantee and spams users with duplicates.
+//
+// THE FIX:
+// We do the claim in ONE atomic statement using `FOR UPDATE SKIP LOCKED`:
+//
+//		UPDATE ... WHERE id IN (
+//		    SELECT id ... FOR UPDATE SKIP LOCKED
+//		)
+//
+//	  - FOR UPDATE row-locks the selected rows for the duration of the statement.
+//	  - SKIP LOCKED tells Postgres: if a row is already locked by another worker,
+//	    skip it instead of blocking. So each worker gets a DISJOINT set of rows.
+//
+// This is the canonical "queue on top of Postgres" pattern — the same approach
+// used by libraries like Que, GoodJob, and River. No external queue needed for
+// correctness; SQS becomes an optional fan-out, not the source of truth.
+//
+// CRASH RECOVERY:
+// Rows stranded in 'processing' by a crashed worker are not picked up here;
+// the worker's reaper claims them with ClaimStuckNotifications and counts the
+// crash as a failed attempt, so a notification that kills its worker every
+// time ends up in the dead letter queue instead of looping forever.
+//
+// Interview talking point:
+// "The single biggest correctness bug in a multi-replica queue worker is the
+//
+//	SELECT-then-UPDATE race. I close it with FOR UPDATE SKIP LOCKED so each
+//	replica atomically claims a disjoint batch, and a reaper recovers stuck
+//	'processing' rows on a timeout so a crashed worker doesn't strand them."
+func (r *Repository) ClaimPendingNotifications(ctx context.Context, limit int) ([]*Notification, error) {
+	query := `
+		UPDATE notifications
+		SET status = 'processing', updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW())
+			  AND NOT EXISTS (
+				SELECT 1 FROM tenant_delivery_pauses p
+				WHERE p.tenant_id = notifications.tenant_id
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM channel_kill_switches k
+				WHERE k.channel = notifications.channel
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM tenant_send_limits l
+				WHERE l.tenant_id = notifications.tenant_id
+				  AND l.channel = notifications.channel
+				  AND l.state = 'paused' AND l.lifted_at IS NULL
+			  )
+			ORDER BY created_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, error_message, next_retry_at,
+			created_at, updated_at, correlation_id, metadata, tags,
+			sla_seconds, COALESCE(group_key, ''), COALESCE(category, '')
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim pending notifications: %w", err)
+	}
+	return scanClaimedNotifications(rows)
+}
+
+// ClaimNotification claims a single notification for the given channel, as
+// ClaimPendingNotifications would, for consumers told about it by a queue
+// message. It returns nil when the row is not claimable: already sent or
+// claimed by the poller, not yet due, or held by a pause or kill switch.
+func (r *Repository) ClaimNotification(ctx context.Context, id uuid.UUID, channel string) (*Notification, error) {
+	query := `
+		UPDATE notifications
+		SET status = 'processing', updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE id = $1 AND channel = $2
+			  AND status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW())
+			  AND NOT EXISTS (
+				SELECT 1 FROM tenant_delivery_pauses p
+				WHERE p.tenant_id = notifications.tenant_id
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM channel_kill_switches k
+				WHERE k.channel = notifications.channel
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM tenant_send_limits l
+				WHERE l.tenant_id = notifications.tenant_id
+				  AND l.channel = notifications.channel
+				  AND l.state = 'paused' AND l.lifted_at IS NULL
+			  )
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, error_message, next_retry_at,
+			created_at, updated_at, correlation_id, metadata, tags,
+			sla_seconds, COALESCE(group_key, ''), COALESCE(category, '')
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, id, channel)
+	if err != nil {
+		return nil, fmt.Errorf("claim notification: %w", err)
+	}
+	claimed, err := scanClaimedNotifications(rows)
+	if err != nil || len(claimed) == 0 {
+		return nil, err
+	}
+	return claimed[0], nil
+}
+
+// ClaimStuckNotifications claims up to limit rows that have sat in
+// 'processing' for longer than olderThan, which means the worker that claimed
+// them died mid-send. The rows stay 'processing' but their updated_at is
+// bumped, so concurrent reapers on other replicas skip them; the caller then
+// reschedules or dead-letters each one.
+//
+// olderThan must be comfortably larger than the longest possible single send
+// (SES/SNS/webhook timeout + retries), otherwise we'd reap a row that's still
+// being legitimately worked on and double-send it.
+func (r *Repository) ClaimStuckNotifications(ctx context.Context, olderThan time.Duration, limit int) ([]*Notification, error) {
+	query := `
+		UPDATE notifications
+		SET updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE status = 'processing' AND updated_at < NOW() - ($2 * INTERVAL '1 second')
+			ORDER BY updated_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, error_message, next_retry_at,
+			created_at, updated_at, correlation_id, metadata, tags,
+			sla_seconds, COALESCE(group_key, ''), COALESCE(category, '')
+	`
+
+	// Pass the timeout as an integer number of seconds and multiply by a
+	// 1-second interval. We deliberately avoid Duration.String() ("5m0s")
+	// because Postgres interval parsing treats a bare "m" as MONTHS, not minutes.
+	rows, err := r.db.Pool().Query(ctx, query, limit, int(olderThan.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("claim stuck notifications: %w", err)
+	}
+	return scanClaimedNotifications(rows)
+}
+
+func scanClaimedNotifications(rows pgx.Rows) ([]*Notification, error) {
+	defer rows.Close()
+
+	var notifications []*Notification
+	for rows.Next() {
+		var notif Notification
+		if err := rows.Scan(
+			&notif.ID,
+			&notif.TenantID,
+			&notif.UserID,
+			&notif.Channel,
+			&notif.Payload,
+			&notif.Status,
+			&notif.Attempt,
+			&notif.ErrorMessage,
+			&notif.NextRetryAt,
+			&notif.CreatedAt,
+			&notif.UpdatedAt,
+			&notif.CorrelationID,
+			&notif.Metadata,
+			&notif.Tags,
+			&notif.SLASeconds,
+			&notif.GroupKey,
+			&notif.Category,
+		); err != nil {
+			return nil, fmt.Errorf("scan claimed notification: %w", err)
+		}
+		notifications = append(notifications, &notif)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MoveToDeadLetter moves a failed notification to the dead letter queue. Like
+// CreateNotification, it logs through ctx's scoped logger.
+func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification, reason, lastError string) (*DeadLetterNotification, error) {
+	// Start a transaction
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
@@ -351,14 +1034,28 @@ func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification,
 		Payload:                notif.Payload,
 		Attempts:               notif.Attempt,
 		LastError:              lastError,
+		Reason:                 reason,
 		Status:                 DLQStatusPending,
+		CorrelationID:          notif.CorrelationID,
+		Metadata:               notif.Metadata,
+		Tags:                   notif.Tags,
+	}
+
+	// A notification created by a DLQ retry carries that entry's count on,
+	// so retry policies know when to stop.
+	err = tx.QueryRow(ctx, `
+		SELECT retry_count + 1 FROM dead_letter_notifications WHERE retried_notification_id = $1
+	`, notif.ID).Scan(&dlq.RetryCount)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("query retry count: %w", err)
 	}
 
 	insertQuery := `
 		INSERT INTO dead_letter_notifications (
 			id, original_notification_id, tenant_id, user_id, channel,
-			payload, attempts, last_error, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			payload, attempts, last_error, status, correlation_id,
+			metadata, tags, reason, retry_count
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING created_at, updated_at
 	`
 
@@ -372,6 +1069,11 @@ func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification,
 		dlq.Attempts,
 		dlq.LastError,
 		dlq.Status,
+		dlq.CorrelationID,
+		jsonbOrEmpty(dlq.Metadata),
+		textArray(dlq.Tags),
+		dlq.Reason,
+		dlq.RetryCount,
 	).Scan(&dlq.CreatedAt, &dlq.UpdatedAt)
 
 	if err != nil {
@@ -379,7 +1081,11 @@ func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification,
 	}
 
 	// Update original notification status
-	updateQuery := `UPDATE notifications SET status = $1 WHERE id = $2`
+	updateQuery := `
+		UPDATE notifications
+		SET status = $1, sla_breached = CASE WHEN sla_seconds IS NULL THEN NULL ELSE TRUE END
+		WHERE id = $2
+	`
 	_, err = tx.Exec(ctx, updateQuery, StatusDeadLettered, notif.ID)
 	if err != nil {
 		return nil, fmt.Errorf("update notification status: %w", err)
@@ -389,9 +1095,9 @@ func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification,
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	r.logger.Info("notification moved to dead letter queue",
-		zap.String("notification_id", notif.ID.String()),
+	observ.Logger(ctx, r.logger).Info("notification moved to dead letter queue",
 		zap.String("dlq_id", dlq.ID.String()),
+		zap.String("reason", reason),
 		zap.String("last_error", lastError),
 	)
 
@@ -399,19 +1105,22 @@ func (r *Repository) MoveToDeadLetter(ctx context.Context, notif *Notification,
 }
 
 // ListDeadLetterByTenant retrieves DLQ items for a tenant
-func (r *Repository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*DeadLetterNotification, error) {
+func (r *Repository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter DeadLetterFilter, limit, offset int) ([]*DeadLetterNotification, error) {
 	query := `
 		SELECT 
 			id, original_notification_id, tenant_id, user_id, channel,
 			payload, attempts, last_error, status, retried_notification_id,
-			created_at, updated_at
+			created_at, updated_at, correlation_id, metadata, tags, reason,
+			retry_count
 		FROM dead_letter_notifications
 		WHERE tenant_id = $1
-		ORDER BY created_at DESC
+		  AND ($4::text = '' OR reason = $4)
+		  AND ($5::text = '' OR status = $5)
+		ORDER BY ` + filter.Sort.OrderBy() + `
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool().Query(ctx, query, tenantID, limit, offset)
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, limit, offset, filter.Reason, filter.Status)
 	if err != nil {
 		return nil, fmt.Errorf("query dead letter notifications: %w", err)
 	}
@@ -433,6 +1142,11 @@ func (r *Repository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.U
 			&dlq.RetriedNotificationID,
 			&dlq.CreatedAt,
 			&dlq.UpdatedAt,
+			&dlq.CorrelationID,
+			&dlq.Metadata,
+			&dlq.Tags,
+			&dlq.Reason,
+			&dlq.RetryCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan dead letter: %w", err)
@@ -443,19 +1157,48 @@ func (r *Repository) ListDeadLetterByTenant(ctx context.Context, tenantID uuid.U
 	return items, nil
 }
 
+// CountDeadLetterByTenant counts the DLQ items ListDeadLetterByTenant pages
+// through with the same filter.
+func (r *Repository) CountDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter DeadLetterFilter) (int64, error) {
+	var total int64
+	err := r.db.Pool().QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM dead_letter_notifications
+		WHERE tenant_id = $1
+		  AND ($2::text = '' OR reason = $2)
+		  AND ($3::text = '' OR status = $3)
+	`, tenantID, filter.Reason, filter.Status).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("count dead letter notifications: %w", err)
+	}
+	return total, nil
+}
+
 // GetDeadLetter retrieves a single DLQ item by ID
 func (r *Repository) GetDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetterNotification, error) {
+	return r.getDeadLetter(ctx, id, nil)
+}
+
+// GetDeadLetterForTenant retrieves a DLQ item by ID only if it belongs to
+// tenantID, like GetNotificationForTenant.
+func (r *Repository) GetDeadLetterForTenant(ctx context.Context, tenantID, id uuid.UUID) (*DeadLetterNotification, error) {
+	return r.getDeadLetter(ctx, id, &tenantID)
+}
+
+// getDeadLetter loads a DLQ item, scoped to tenantID unless it is nil.
+func (r *Repository) getDeadLetter(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*DeadLetterNotification, error) {
 	query := `
 		SELECT 
 			id, original_notification_id, tenant_id, user_id, channel,
 			payload, attempts, last_error, status, retried_notification_id,
-			created_at, updated_at
+			created_at, updated_at, correlation_id, metadata, tags, reason,
+			retry_count
 		FROM dead_letter_notifications
-		WHERE id = $1
+		WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
 	`
 
 	var dlq DeadLetterNotification
-	err := r.db.Pool().QueryRow(ctx, query, id).Scan(
+	err := r.db.Pool().QueryRow(ctx, query, id, tenantID).Scan(
 		&dlq.ID,
 		&dlq.OriginalNotificationID,
 		&dlq.TenantID,
@@ -468,10 +1211,15 @@ func (r *Repository) GetDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLett
 		&dlq.RetriedNotificationID,
 		&dlq.CreatedAt,
 		&dlq.UpdatedAt,
+		&dlq.CorrelationID,
+		&dlq.Metadata,
+		&dlq.Tags,
+		&dlq.Reason,
+		&dlq.RetryCount,
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("dead letter not found: %s", id)
+		return nil, fmt.Errorf("dead letter %w: %s", ErrNotFound, id)
 	}
 
 	if err != nil {
@@ -500,21 +1248,26 @@ func (r *Repository) RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*Not
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	// Create new notification
+	// Create new notification. It keeps the original correlation ID so the
+	// replay shows up under the same trace as the failed attempts.
 	newNotif := &Notification{
-		ID:       uuid.New(),
-		TenantID: dlq.TenantID,
-		UserID:   dlq.UserID,
-		Channel:  dlq.Channel,
-		Payload:  dlq.Payload,
-		Status:   StatusPending,
-		Attempt:  0,
+		ID:            uuid.New(),
+		TenantID:      dlq.TenantID,
+		UserID:        dlq.UserID,
+		Channel:       dlq.Channel,
+		Payload:       dlq.Payload,
+		Status:        StatusPending,
+		Attempt:       0,
+		CorrelationID: dlq.CorrelationID,
+		Metadata:      dlq.Metadata,
+		Tags:          dlq.Tags,
 	}
 
 	insertQuery := `
 		INSERT INTO notifications (
-			id, tenant_id, user_id, channel, payload, status, attempt
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			id, tenant_id, user_id, channel, payload, status, attempt, correlation_id,
+			metadata, tags
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING created_at, updated_at
 	`
 
@@ -526,6 +1279,9 @@ func (r *Repository) RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*Not
 		newNotif.Payload,
 		newNotif.Status,
 		newNotif.Attempt,
+		newNotif.CorrelationID,
+		jsonbOrEmpty(newNotif.Metadata),
+		textArray(newNotif.Tags),
 	).Scan(&newNotif.CreatedAt, &newNotif.UpdatedAt)
 
 	if err != nil {
@@ -550,6 +1306,7 @@ func (r *Repository) RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*Not
 	r.logger.Info("dead letter retried",
 		zap.String("dlq_id", dlqID.String()),
 		zap.String("new_notification_id", newNotif.ID.String()),
+		zap.String("correlation_id", newNotif.CorrelationID),
 	)
 
 	return newNotif, nil
@@ -576,3 +1333,2294 @@ func (r *Repository) DiscardDeadLetter(ctx context.Context, dlqID uuid.UUID) err
 
 	return nil
 }
+
+// CaptureDelivery records a message in the sandbox test inbox instead of
+// delivering it to a real provider.
+func (r *Repository) CaptureDelivery(ctx context.Context, delivery *CapturedDelivery) error {
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO captured_deliveries (
+			id, notification_id, tenant_id, user_id, channel, recipient, payload
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING captured_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query,
+		delivery.ID,
+		delivery.NotificationID,
+		delivery.TenantID,
+		delivery.UserID,
+		delivery.Channel,
+		delivery.Recipient,
+		delivery.Payload,
+	).Scan(&delivery.CapturedAt)
+	if err != nil {
+		return fmt.Errorf("insert captured delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListCapturedDeliveries returns a tenant's captured deliveries, newest first.
+// An empty channel matches every channel.
+func (r *Repository) ListCapturedDeliveries(ctx context.Context, tenantID uuid.UUID, channel string, limit int) ([]*CapturedDelivery, error) {
+	query := `
+		SELECT
+			id, notification_id, tenant_id, user_id, channel,
+			recipient, payload, captured_at
+		FROM captured_deliveries
+		WHERE tenant_id = $1 AND ($2::text = '' OR channel = $2)
+		ORDER BY captured_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, channel, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query captured deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*CapturedDelivery
+	for rows.Next() {
+		var d CapturedDelivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.NotificationID,
+			&d.TenantID,
+			&d.UserID,
+			&d.Channel,
+			&d.Recipient,
+			&d.Payload,
+			&d.CapturedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan captured delivery: %w", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// ClearCapturedDeliveries empties a tenant's test inbox so each integration
+// test can start from a known state. Returns the number of rows removed.
+func (r *Repository) ClearCapturedDeliveries(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM captured_deliveries WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("clear captured deliveries: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// ErrTemplateNameTaken is returned by CreateTemplate when the tenant already
+// has a template with that name.
+var ErrTemplateNameTaken = errors.New("template name already exists")
+
+// CreateTemplate inserts a new draft template.
+func (r *Repository) CreateTemplate(ctx context.Context, tmpl *Template) error {
+	if tmpl.ID == uuid.Nil {
+		tmpl.ID = uuid.New()
+	}
+	tmpl.Status = TemplateStatusDraft
+
+	query := `
+		INSERT INTO templates (
+			id, tenant_id, name, subject, mjml_source, text_body, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query,
+		tmpl.ID,
+		tmpl.TenantID,
+		tmpl.Name,
+		tmpl.Subject,
+		tmpl.MJMLSource,
+		tmpl.TextBody,
+		tmpl.Status,
+	).Scan(&tmpl.CreatedAt, &tmpl.UpdatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrTemplateNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("insert template: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplate retrieves a template by ID.
+func (r *Repository) GetTemplate(ctx context.Context, id uuid.UUID) (*Template, error) {
+	query := `
+		SELECT
+			id, tenant_id, name, subject, mjml_source, text_body,
+			html, status, published_at, created_at, updated_at
+		FROM templates
+		WHERE id = $1
+	`
+
+	var tmpl Template
+	err := r.db.Pool().QueryRow(ctx, query, id).Scan(
+		&tmpl.ID,
+		&tmpl.TenantID,
+		&tmpl.Name,
+		&tmpl.Subject,
+		&tmpl.MJMLSource,
+		&tmpl.TextBody,
+		&tmpl.HTML,
+		&tmpl.Status,
+		&tmpl.PublishedAt,
+		&tmpl.CreatedAt,
+		&tmpl.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("template not found: %s", id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query template: %w", err)
+	}
+
+	return &tmpl, nil
+}
+
+// PublishTemplate stores the compiled HTML for a template and marks it
+// published. The caller compiles; this only records the result.
+func (r *Repository) PublishTemplate(ctx context.Context, id uuid.UUID, html string) (*Template, error) {
+	query := `
+		UPDATE templates
+		SET html = $2, status = $3, published_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING
+			id, tenant_id, name, subject, mjml_source, text_body,
+			html, status, published_at, created_at, updated_at
+	`
+
+	var tmpl Template
+	err := r.db.Pool().QueryRow(ctx, query, id, html, TemplateStatusPublished).Scan(
+		&tmpl.ID,
+		&tmpl.TenantID,
+		&tmpl.Name,
+		&tmpl.Subject,
+		&tmpl.MJMLSource,
+		&tmpl.TextBody,
+		&tmpl.HTML,
+		&tmpl.Status,
+		&tmpl.PublishedAt,
+		&tmpl.CreatedAt,
+		&tmpl.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("template not found: %s", id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("publish template: %w", err)
+	}
+
+	return &tmpl, nil
+}
+
+// CreateShortLink stores a short link. If the notification already has a
+// link for this URL (a retried send), the existing code is kept and written
+// back into link.Code so the recipient never sees two codes for one URL.
+func (r *Repository) CreateShortLink(ctx context.Context, link *ShortLink) error {
+	query := `
+		INSERT INTO short_links (code, tenant_id, notification_id, url)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (notification_id, url) DO UPDATE SET url = EXCLUDED.url
+		RETURNING code, created_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query,
+		link.Code,
+		link.TenantID,
+		link.NotificationID,
+		link.URL,
+	).Scan(&link.Code, &link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert short link: %w", err)
+	}
+
+	return nil
+}
+
+// GetShortLink retrieves a short link by its code.
+func (r *Repository) GetShortLink(ctx context.Context, code string) (*ShortLink, error) {
+	query := `
+		SELECT code, tenant_id, notification_id, url, created_at
+		FROM short_links
+		WHERE code = $1
+	`
+
+	var link ShortLink
+	err := r.db.Pool().QueryRow(ctx, query, code).Scan(
+		&link.Code,
+		&link.TenantID,
+		&link.NotificationID,
+		&link.URL,
+		&link.CreatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("short link not found: %s", code)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query short link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// RecordLinkClick stores a click event for a short link.
+func (r *Repository) RecordLinkClick(ctx context.Context, click *LinkClick) error {
+	if click.ID == uuid.Nil {
+		click.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO link_clicks (
+			id, code, tenant_id, notification_id, user_agent, ip_address
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING clicked_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query,
+		click.ID,
+		click.Code,
+		click.TenantID,
+		click.NotificationID,
+		click.UserAgent,
+		click.IPAddress,
+	).Scan(&click.ClickedAt)
+	if err != nil {
+		return fmt.Errorf("insert link click: %w", err)
+	}
+
+	return nil
+}
+
+// GetChannelSettings returns a tenant's settings for a channel. A tenant
+// that never configured the channel gets empty settings, not an error.
+func (r *Repository) GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*TenantChannelSettings, error) {
+	query := `
+		SELECT tenant_id, channel, settings, created_at, updated_at
+		FROM tenant_channel_settings
+		WHERE tenant_id = $1 AND channel = $2
+	`
+
+	var s TenantChannelSettings
+	err := r.db.Pool().QueryRow(ctx, query, tenantID, channel).Scan(
+		&s.TenantID,
+		&s.Channel,
+		&s.Settings,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return &TenantChannelSettings{
+			TenantID: tenantID,
+			Channel:  channel,
+			Settings: json.RawMessage(`{}`),
+		}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query channel settings: %w", err)
+	}
+
+	return &s, nil
+}
+
+// UpsertChannelSettings replaces a tenant's settings for a channel.
+func (r *Repository) UpsertChannelSettings(ctx context.Context, s *TenantChannelSettings) error {
+	query := `
+		INSERT INTO tenant_channel_settings (tenant_id, channel, settings)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, channel)
+		DO UPDATE SET settings = EXCLUDED.settings, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query,
+		s.TenantID,
+		s.Channel,
+		jsonbOrEmpty(s.Settings),
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert channel settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetTenantSettings returns a tenant's API settings. A tenant that never set
+// them gets the defaults, not an error.
+func (r *Repository) GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*TenantSettings, error) {
+	query := `
+		SELECT tenant_id, require_idempotency_key, created_at, updated_at
+		FROM tenant_settings
+		WHERE tenant_id = $1
+	`
+
+	var s TenantSettings
+	err := r.db.Pool().QueryRow(ctx, query, tenantID).Scan(
+		&s.TenantID,
+		&s.RequireIdempotencyKey,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return &TenantSettings{TenantID: tenantID}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query tenant settings: %w", err)
+	}
+
+	return &s, nil
+}
+
+// UpsertTenantSettings creates or replaces a tenant's API settings.
+func (r *Repository) UpsertTenantSettings(ctx context.Context, s *TenantSettings) error {
+	query := `
+		INSERT INTO tenant_settings (tenant_id, require_idempotency_key)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id)
+		DO UPDATE SET require_idempotency_key = EXCLUDED.require_idempotency_key, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query, s.TenantID, s.RequireIdempotencyKey).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert tenant settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetIPAllowlist returns a tenant's source IP allowlist. A tenant that never
+// set one gets an empty list, not an error.
+func (r *Repository) GetIPAllowlist(ctx context.Context, tenantID uuid.UUID) (*TenantIPAllowlist, error) {
+	query := `
+		SELECT tenant_id, cidrs, created_at, updated_at
+		FROM tenant_ip_allowlists
+		WHERE tenant_id = $1
+	`
+
+	var a TenantIPAllowlist
+	err := r.db.Pool().QueryRow(ctx, query, tenantID).Scan(
+		&a.TenantID,
+		&a.CIDRs,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return &TenantIPAllowlist{TenantID: tenantID, CIDRs: []string{}}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query ip allowlist: %w", err)
+	}
+
+	return &a, nil
+}
+
+// UpsertIPAllowlist replaces a tenant's source IP allowlist.
+func (r *Repository) UpsertIPAllowlist(ctx context.Context, a *TenantIPAllowlist) error {
+	query := `
+		INSERT INTO tenant_ip_allowlists (tenant_id, cidrs)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id)
+		DO UPDATE SET cidrs = EXCLUDED.cidrs, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.Pool().QueryRow(ctx, query, a.TenantID, textArray(a.CIDRs)).Scan(&a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert ip allowlist: %w", err)
+	}
+
+	return nil
+}
+
+const apiKeyColumns = `
+	id, tenant_id, name, prefix, key_hash, scopes, expires_at,
+	last_used_at, revoked_at, replaced_by, created_at
+`
+
+// CreateAPIKey stores a new API key. The caller generates the key and sets
+// Prefix and KeyHash; the plaintext never reaches the database.
+func (r *Repository) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	return createAPIKey(ctx, r.db.Pool(), key)
+}
+
+// pgxQuerier is satisfied by both the pool and a transaction.
+type pgxQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func createAPIKey(ctx context.Context, q pgxQuerier, key *APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		key.ID,
+		key.TenantID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		textArray(key.Scopes),
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert api key: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (r *Repository) GetAPIKey(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	key, err := scanAPIKey(r.db.Pool().QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("api key %w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash looks up the key a bearer token hashes to. Callers check
+// Active themselves; revoked and expired keys are returned too.
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.Pool().QueryRow(ctx, query, keyHash))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("api key not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns a tenant's API keys, newest first, including revoked
+// and expired ones.
+func (r *Repository) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RollAPIKey stores replacement and makes the old key expire at oldExpiresAt
+// (or its existing expiry, if sooner), so clients can switch over during
+// the grace period.
+func (r *Repository) RollAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, replacement *APIKey) error {
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := createAPIKey(ctx, tx, replacement); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE api_keys
+		SET replaced_by = $2, expires_at = LEAST(COALESCE(expires_at, $3), $3)
+		WHERE id = $1 AND revoked_at IS NULL AND replaced_by IS NULL
+	`
+	result, err := tx.Exec(ctx, query, oldID, replacement.ID, oldExpiresAt)
+	if err != nil {
+		return fmt.Errorf("expire rolled api key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("api key not found or already rolled or revoked")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	r.logger.Info("api key rolled",
+		zap.String("api_key_id", oldID.String()),
+		zap.String("replacement_id", replacement.ID.String()),
+	)
+
+	return nil
+}
+
+// RevokeAPIKey disables a key immediately.
+func (r *Repository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("api key not found or already revoked")
+	}
+
+	r.logger.Info("api key revoked", zap.String("api_key_id", id.String()))
+
+	return nil
+}
+
+// TouchAPIKey records that a key was used. Writes are throttled to one a
+// minute per key so busy keys don't turn every request into an UPDATE.
+func (r *Repository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := r.db.Pool().Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*APIKey, error) {
+	var key APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.ReplacedBy,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// jsonbOrEmpty maps an absent JSON value to '{}' so it satisfies the NOT NULL
+// DEFAULT '{}' JSONB columns (a nil RawMessage would be sent as NULL).
+func jsonbOrEmpty(v json.RawMessage) json.RawMessage {
+	if len(v) == 0 {
+		return json.RawMessage(`{}`)
+	}
+	return v
+}
+
+// textArray maps a nil slice to an empty one so it is sent as '{}' rather
+// than NULL for the NOT NULL TEXT[] columns.
+func textArray(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
+
+// AcquireJob takes the lock on a background job for owner until ttl elapses.
+// It returns false, without error, if another owner holds an unexpired lock
+// or the job already started within minGap — the latter is what keeps N
+// replicas ticking on the same interval from running the job N times.
+func (r *Repository) AcquireJob(ctx context.Context, name, owner string, minGap, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO job_runs (name, locked_by, locked_until, last_started_at)
+		VALUES ($1, $2, NOW() + ($3 * INTERVAL '1 millisecond'), NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET locked_by = EXCLUDED.locked_by,
+		    locked_until = EXCLUDED.locked_until,
+		    last_started_at = EXCLUDED.last_started_at
+		WHERE (job_runs.locked_until IS NULL OR job_runs.locked_until < NOW())
+		  AND (job_runs.last_started_at IS NULL OR job_runs.last_started_at <= NOW() - ($4 * INTERVAL '1 millisecond'))
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, name, owner, ttl.Milliseconds(), minGap.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("acquire job %s: %w", name, err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// FinishJob records the outcome of a run and releases owner's lock. A run
+// that overran its lock and lost it to another owner records nothing.
+func (r *Repository) FinishJob(ctx context.Context, name, owner string, took time.Duration, runErr error) error {
+	var lastError *string
+	if runErr != nil {
+		msg := runErr.Error()
+		lastError = &msg
+	}
+
+	query := `
+		UPDATE job_runs
+		SET locked_by = NULL,
+		    locked_until = NULL,
+		    last_finished_at = NOW(),
+		    last_duration_ms = $3,
+		    last_error = $4,
+		    last_success_at = CASE WHEN $4::text IS NULL THEN NOW() ELSE last_success_at END,
+		    run_count = run_count + 1
+		WHERE name = $1 AND locked_by = $2
+	`
+
+	if _, err := r.db.Pool().Exec(ctx, query, name, owner, took.Milliseconds(), lastError); err != nil {
+		return fmt.Errorf("finish job %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ListJobRuns returns every background job's lock and last-run record.
+func (r *Repository) ListJobRuns(ctx context.Context) ([]*JobRun, error) {
+	query := `
+		SELECT name, locked_by, locked_until, last_started_at, last_finished_at,
+		       last_duration_ms, last_error, last_success_at, run_count
+		FROM job_runs
+		ORDER BY name
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*JobRun
+	for rows.Next() {
+		var run JobRun
+		if err := rows.Scan(
+			&run.Name,
+			&run.LockedBy,
+			&run.LockedUntil,
+			&run.LastStartedAt,
+			&run.LastFinishedAt,
+			&run.LastDurationMS,
+			&run.LastError,
+			&run.LastSuccessAt,
+			&run.RunCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan job run: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+// RequeueNotifications moves up to f.Limit notifications matching f back to
+// pending, due now, oldest first, and returns how many it moved. The attempt
+// count is kept, so a requeued notification still dead-letters once it runs
+// out of retries.
+func (r *Repository) RequeueNotifications(ctx context.Context, f RequeueFilter) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET status = 'pending', next_retry_at = NULL
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE status = $1
+			  AND updated_at < NOW() - ($2 * INTERVAL '1 second')
+			  AND ($3 = '' OR channel = $3)
+			  AND ($4::uuid IS NULL OR tenant_id = $4)
+			  AND ($5 = '' OR strpos(error_message, $5) > 0)
+			ORDER BY updated_at ASC
+			LIMIT $6
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+
+	tag, err := r.db.Pool().Exec(ctx, query,
+		f.Status, int(f.OlderThan.Seconds()), f.Channel, f.TenantID, f.ErrorContains, f.Limit)
+	if err != nil {
+		return 0, fmt.Errorf("requeue notifications: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// DeleteFinishedNotifications deletes up to limit sent or dead-lettered
+// notifications last updated before cutoff, oldest first, and returns how
+// many it removed. Callers loop until it returns less than limit, so one
+// huge DELETE never holds locks across the whole table.
+func (r *Repository) DeleteFinishedNotifications(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM notifications
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE status IN ('sent', 'dead_lettered') AND updated_at < $1
+			ORDER BY updated_at ASC
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete finished notifications: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// PurgeResolvedDeadLetters deletes up to limit DLQ entries that were
+// retried or discarded before cutoff. Pending entries are never purged:
+// they are still waiting for an operator.
+func (r *Repository) PurgeResolvedDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM dead_letter_notifications
+		WHERE id IN (
+			SELECT id
+			FROM dead_letter_notifications
+			WHERE status IN ('retried', 'discarded') AND updated_at < $1
+			ORDER BY updated_at ASC
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge dead letters: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ErrNoDLQRetryPolicy is returned when a tenant has no retry policy for a
+// reason code.
+var ErrNoDLQRetryPolicy = errors.New("no DLQ retry policy for reason")
+
+const dlqRetryPolicyColumns = `
+	tenant_id, reason, interval_seconds, max_retries, created_at, updated_at`
+
+func scanDLQRetryPolicy(row pgx.Row) (*DLQRetryPolicy, error) {
+	var p DLQRetryPolicy
+	err := row.Scan(&p.TenantID, &p.Reason, &p.IntervalSeconds, &p.MaxRetries, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListDLQRetryPolicies returns the tenant's DLQ retry policies by reason.
+func (r *Repository) ListDLQRetryPolicies(ctx context.Context, tenantID uuid.UUID) ([]*DLQRetryPolicy, error) {
+	query := `SELECT ` + dlqRetryPolicyColumns + ` FROM dlq_retry_policies WHERE tenant_id = $1 ORDER BY reason`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list dlq retry policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*DLQRetryPolicy
+	for rows.Next() {
+		p, err := scanDLQRetryPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan dlq retry policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// UpsertDLQRetryPolicy sets the tenant's retry policy for p.Reason.
+func (r *Repository) UpsertDLQRetryPolicy(ctx context.Context, p *DLQRetryPolicy) error {
+	query := `
+		INSERT INTO dlq_retry_policies (tenant_id, reason, interval_seconds, max_retries)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, reason)
+		DO UPDATE SET interval_seconds = EXCLUDED.interval_seconds,
+			max_retries = EXCLUDED.max_retries,
+			updated_at = NOW()
+		RETURNING ` + dlqRetryPolicyColumns
+
+	saved, err := scanDLQRetryPolicy(r.db.Pool().QueryRow(ctx, query,
+		p.TenantID, p.Reason, p.IntervalSeconds, p.MaxRetries))
+	if err != nil {
+		return fmt.Errorf("upsert dlq retry policy: %w", err)
+	}
+
+	*p = *saved
+	return nil
+}
+
+// DeleteDLQRetryPolicy removes the tenant's retry policy for reason.
+func (r *Repository) DeleteDLQRetryPolicy(ctx context.Context, tenantID uuid.UUID, reason string) error {
+	result, err := r.db.Pool().Exec(ctx,
+		`DELETE FROM dlq_retry_policies WHERE tenant_id = $1 AND reason = $2`, tenantID, reason)
+	if err != nil {
+		return fmt.Errorf("delete dlq retry policy: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoDLQRetryPolicy
+	}
+	return nil
+}
+
+// ListDueDeadLetters returns up to limit pending DLQ entries, oldest first,
+// that a retry policy says to retry now: the entry has waited the policy's
+// interval and hasn't used up its retries.
+func (r *Repository) ListDueDeadLetters(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT d.id
+		FROM dead_letter_notifications d
+		JOIN dlq_retry_policies p ON p.tenant_id = d.tenant_id AND p.reason = d.reason
+		WHERE d.status = $1
+		  AND d.retry_count < p.max_retries
+		  AND d.created_at <= NOW() - p.interval_seconds * INTERVAL '1 second'
+		ORDER BY d.created_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, DLQStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan due dead letter: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ErrTenantNotPaused is returned by ResumeTenant when the tenant has no pause.
+var ErrTenantNotPaused = errors.New("tenant delivery is not paused")
+
+// PauseTenant stops delivery for p.TenantID. Pausing an already paused
+// tenant updates the reason but keeps the original paused_at.
+func (r *Repository) PauseTenant(ctx context.Context, p *TenantPause) error {
+	query := `
+		INSERT INTO tenant_delivery_pauses (tenant_id, reason)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id)
+		DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING paused_at
+	`
+
+	if err := r.db.Pool().QueryRow(ctx, query, p.TenantID, p.Reason).Scan(&p.PausedAt); err != nil {
+		return fmt.Errorf("pause tenant: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeTenant lifts a tenant's delivery pause.
+func (r *Repository) ResumeTenant(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM tenant_delivery_pauses WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("resume tenant: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTenantNotPaused
+	}
+
+	return nil
+}
+
+// ListTenantPauses returns every paused tenant, longest paused first.
+func (r *Repository) ListTenantPauses(ctx context.Context) ([]*TenantPause, error) {
+	query := `
+		SELECT tenant_id, reason, paused_at
+		FROM tenant_delivery_pauses
+		ORDER BY paused_at
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []*TenantPause
+	for rows.Next() {
+		var p TenantPause
+		if err := rows.Scan(&p.TenantID, &p.Reason, &p.PausedAt); err != nil {
+			return nil, fmt.Errorf("scan tenant pause: %w", err)
+		}
+		pauses = append(pauses, &p)
+	}
+
+	return pauses, rows.Err()
+}
+
+// ErrChannelNotKilled is returned by ReviveChannel when the channel has no
+// kill switch.
+var ErrChannelNotKilled = errors.New("channel is not killed")
+
+// KillChannel turns on the kill switch for k.Channel. Killing an already
+// killed channel updates the reason but keeps the original killed_at.
+func (r *Repository) KillChannel(ctx context.Context, k *ChannelKillSwitch) error {
+	query := `
+		INSERT INTO channel_kill_switches (channel, reason)
+		VALUES ($1, $2)
+		ON CONFLICT (channel)
+		DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING killed_at
+	`
+
+	if err := r.db.Pool().QueryRow(ctx, query, k.Channel, k.Reason).Scan(&k.KilledAt); err != nil {
+		return fmt.Errorf("kill channel: %w", err)
+	}
+
+	return nil
+}
+
+// ReviveChannel lifts a channel's kill switch. Its held notifications are
+// released by the worker's next SyncChannelHolds.
+func (r *Repository) ReviveChannel(ctx context.Context, channel string) error {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM channel_kill_switches WHERE channel = $1`, channel)
+	if err != nil {
+		return fmt.Errorf("revive channel: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrChannelNotKilled
+	}
+
+	return nil
+}
+
+// ListChannelKillSwitches returns every killed channel.
+func (r *Repository) ListChannelKillSwitches(ctx context.Context) ([]*ChannelKillSwitch, error) {
+	query := `
+		SELECT channel, reason, killed_at
+		FROM channel_kill_switches
+		ORDER BY channel
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query channel kill switches: %w", err)
+	}
+	defer rows.Close()
+
+	var switches []*ChannelKillSwitch
+	for rows.Next() {
+		var k ChannelKillSwitch
+		if err := rows.Scan(&k.Channel, &k.Reason, &k.KilledAt); err != nil {
+			return nil, fmt.Errorf("scan channel kill switch: %w", err)
+		}
+		switches = append(switches, &k)
+	}
+
+	return switches, rows.Err()
+}
+
+// SyncChannelHolds moves pending notifications on killed channels to 'held'
+// and held notifications whose channel is no longer killed back to
+// 'pending'. Running both directions every poll means a revive needs no
+// follow-up step, and a hold that raced a revive is undone on the next call.
+func (r *Repository) SyncChannelHolds(ctx context.Context) (held, released int64, err error) {
+	result, err := r.db.Pool().Exec(ctx, `
+		UPDATE notifications
+		SET status = 'held'
+		WHERE status = 'pending'
+		  AND channel IN (SELECT channel FROM channel_kill_switches)
+	`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("hold notifications: %w", err)
+	}
+	held = result.RowsAffected()
+
+	result, err = r.db.Pool().Exec(ctx, `
+		UPDATE notifications
+		SET status = 'pending'
+		WHERE status = 'held'
+		  AND channel NOT IN (SELECT channel FROM channel_kill_switches)
+	`)
+	if err != nil {
+		return held, 0, fmt.Errorf("release held notifications: %w", err)
+	}
+
+	return held, result.RowsAffected(), nil
+}
+
+// InsertDeliveryEvents stores provider-reported events, attributing each to
+// the notification (and tenant) whose provider message ID it carries.
+// Events already stored are skipped, since SNS may deliver one twice. A new
+// hard bounce also invalidates the recipient for the tenant. It returns how
+// many events were new.
+func (r *Repository) InsertDeliveryEvents(ctx context.Context, events []*DeliveryEvent) (int64, error) {
+	query := `
+		INSERT INTO delivery_events (
+			notification_id, tenant_id, channel, provider, provider_message_id,
+			event_type, bounce_type, recipient, occurred_at
+		)
+		SELECT n.id, n.tenant_id, $1, $2, $3, $4, $5, $6, $7
+		FROM (SELECT 1) AS one
+		LEFT JOIN LATERAL (
+			SELECT id, tenant_id
+			FROM notifications
+			WHERE provider_message_id = $3 AND provider = $2
+			ORDER BY created_at DESC
+			LIMIT 1
+		) n ON true
+		ON CONFLICT (provider, provider_message_id, event_type, recipient) DO NOTHING
+	`
+
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var inserted int64
+	for _, e := range events {
+		result, err := tx.Exec(ctx, query,
+			e.Channel, e.Provider, e.ProviderMessageID,
+			e.Type, e.BounceType, e.Recipient, e.OccurredAt,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert delivery event: %w", err)
+		}
+		inserted += result.RowsAffected()
+
+		if result.RowsAffected() == 0 || !invalidatesRecipient(e) {
+			continue
+		}
+		if _, err := tx.Exec(ctx, invalidateBouncedQuery,
+			e.Channel, e.Recipient, InvalidRecipientHardBounce, e.Provider+": permanent bounce",
+			e.ProviderMessageID, e.Provider,
+		); err != nil {
+			return 0, fmt.Errorf("invalidate bounced recipient: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit delivery events: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// GetEmailReputation counts, per tenant, the emails sent and the hard
+// bounces and complaints received since the given time. A tenant whose
+// email limit was lifted later than that is only judged from the lift on.
+// Tenants that sent no email in the window are omitted.
+func (r *Repository) GetEmailReputation(ctx context.Context, since time.Time) ([]*TenantReputation, error) {
+	query := `
+		WITH lifted AS (
+			SELECT tenant_id, lifted_at
+			FROM tenant_send_limits
+			WHERE channel = 'email' AND lifted_at > $1
+		),
+		sent AS (
+			SELECT n.tenant_id, COUNT(*) AS sent
+			FROM notifications n
+			LEFT JOIN lifted l ON l.tenant_id = n.tenant_id
+			WHERE n.channel = 'email' AND n.status = 'sent'
+			  AND n.updated_at >= COALESCE(l.lifted_at, $1)
+			GROUP BY n.tenant_id
+		),
+		events AS (
+			SELECT e.tenant_id,
+				COUNT(*) FILTER (WHERE e.event_type = 'bounce' AND e.bounce_type = 'permanent') AS hard_bounces,
+				COUNT(*) FILTER (WHERE e.event_type = 'complaint') AS complaints
+			FROM delivery_events e
+			LEFT JOIN lifted l ON l.tenant_id = e.tenant_id
+			WHERE e.channel = 'email' AND e.tenant_id IS NOT NULL
+			  AND e.occurred_at >= COALESCE(l.lifted_at, $1)
+			GROUP BY e.tenant_id
+		)
+		SELECT s.tenant_id, s.sent, COALESCE(e.hard_bounces, 0), COALESCE(e.complaints, 0)
+		FROM sent s
+		LEFT JOIN events e ON e.tenant_id = s.tenant_id
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("query email reputation: %w", err)
+	}
+	defer rows.Close()
+
+	var reps []*TenantReputation
+	for rows.Next() {
+		var rep TenantReputation
+		if err := rows.Scan(&rep.TenantID, &rep.Sent, &rep.HardBounces, &rep.Complaints); err != nil {
+			return nil, fmt.Errorf("scan email reputation: %w", err)
+		}
+		reps = append(reps, &rep)
+	}
+
+	return reps, rows.Err()
+}
+
+// invalidateBouncedQuery invalidates a hard-bounced recipient for the
+// tenant that sent the bounced message. Bounces we can't attribute insert
+// nothing.
+const invalidateBouncedQuery = `
+	INSERT INTO invalid_recipients (tenant_id, channel, recipient, reason, detail, notification_id)
+	SELECT tenant_id, $1, $2, $3, $4, id
+	FROM notifications
+	WHERE provider_message_id = $5 AND provider = $6
+	ORDER BY created_at DESC
+	LIMIT 1
+	ON CONFLICT (tenant_id, channel, recipient) DO NOTHING
+`
+
+// invalidatesRecipient reports whether e is a hard bounce for a known
+// recipient.
+func invalidatesRecipient(e *DeliveryEvent) bool {
+	return e.Type == DeliveryEventBounce && e.BounceType == BounceTypePermanent && e.Recipient != ""
+}
+
+// ErrNoInvalidRecipient is returned by ReinstateRecipient when the
+// recipient isn't invalidated.
+var ErrNoInvalidRecipient = errors.New("recipient is not invalidated")
+
+const invalidRecipientColumns = `
+	tenant_id, channel, recipient, reason, detail, notification_id, invalidated_at`
+
+func scanInvalidRecipient(row pgx.Row) (*InvalidRecipient, error) {
+	var ir InvalidRecipient
+	err := row.Scan(&ir.TenantID, &ir.Channel, &ir.Recipient, &ir.Reason, &ir.Detail,
+		&ir.NotificationID, &ir.InvalidatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &ir, nil
+}
+
+// InvalidateRecipient records ir.Recipient as undeliverable for the tenant.
+// An address that is already invalid keeps its original reason and time.
+func (r *Repository) InvalidateRecipient(ctx context.Context, ir *InvalidRecipient) error {
+	query := `
+		INSERT INTO invalid_recipients (tenant_id, channel, recipient, reason, detail, notification_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, channel, recipient) DO NOTHING
+	`
+
+	_, err := r.db.Pool().Exec(ctx, query,
+		ir.TenantID, ir.Channel, ir.Recipient, ir.Reason, ir.Detail, ir.NotificationID)
+	if err != nil {
+		return fmt.Errorf("invalidate recipient: %w", err)
+	}
+	return nil
+}
+
+// IsRecipientInvalid reports whether the tenant's recipient on channel has
+// been invalidated.
+func (r *Repository) IsRecipientInvalid(ctx context.Context, tenantID uuid.UUID, channel, recipient string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM invalid_recipients
+			WHERE tenant_id = $1 AND channel = $2 AND recipient = $3
+		)
+	`
+
+	var invalid bool
+	if err := r.db.Pool().QueryRow(ctx, query, tenantID, channel, recipient).Scan(&invalid); err != nil {
+		return false, fmt.Errorf("check invalid recipient: %w", err)
+	}
+	return invalid, nil
+}
+
+// ListInvalidRecipients returns the tenant's invalidated recipients, newest
+// first. An empty channel lists every channel.
+func (r *Repository) ListInvalidRecipients(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]*InvalidRecipient, error) {
+	query := `
+		SELECT ` + invalidRecipientColumns + `
+		FROM invalid_recipients
+		WHERE tenant_id = $1 AND ($2::text = '' OR channel = $2)
+		ORDER BY invalidated_at DESC, recipient
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, channel, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list invalid recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []*InvalidRecipient
+	for rows.Next() {
+		ir, err := scanInvalidRecipient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invalid recipient: %w", err)
+		}
+		recipients = append(recipients, ir)
+	}
+	return recipients, rows.Err()
+}
+
+// ReinstateRecipient makes an invalidated recipient deliverable again.
+func (r *Repository) ReinstateRecipient(ctx context.Context, tenantID uuid.UUID, channel, recipient string) error {
+	result, err := r.db.Pool().Exec(ctx,
+		`DELETE FROM invalid_recipients WHERE tenant_id = $1 AND channel = $2 AND recipient = $3`,
+		tenantID, channel, recipient)
+	if err != nil {
+		return fmt.Errorf("reinstate recipient: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoInvalidRecipient
+	}
+	return nil
+}
+
+// ErrNoRecipient is returned when a user has no recipient record.
+var ErrNoRecipient = errors.New("user has no recipient record")
+
+const recipientColumns = `
+	tenant_id, user_id, email, phone, device_tokens, locale, timezone, created_at, updated_at`
+
+func scanRecipient(row pgx.Row) (*Recipient, error) {
+	var rc Recipient
+	err := row.Scan(&rc.TenantID, &rc.UserID, &rc.Email, &rc.Phone, &rc.DeviceTokens,
+		&rc.Locale, &rc.Timezone, &rc.CreatedAt, &rc.UpdatedAt)
+	return &rc, err
+}
+
+// GetRecipient returns the tenant's recipient record for userID.
+func (r *Repository) GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*Recipient, error) {
+	query := `SELECT ` + recipientColumns + ` FROM recipients WHERE tenant_id = $1 AND user_id = $2`
+
+	rc, err := scanRecipient(r.db.Pool().QueryRow(ctx, query, tenantID, userID))
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoRecipient
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get recipient: %w", err)
+	}
+
+	return rc, nil
+}
+
+// UpsertRecipient replaces the tenant's recipient record for rc.UserID.
+func (r *Repository) UpsertRecipient(ctx context.Context, rc *Recipient) error {
+	query := `
+		INSERT INTO recipients (tenant_id, user_id, email, phone, device_tokens, locale, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, user_id)
+		DO UPDATE SET email = EXCLUDED.email,
+			phone = EXCLUDED.phone,
+			device_tokens = EXCLUDED.device_tokens,
+			locale = EXCLUDED.locale,
+			timezone = EXCLUDED.timezone,
+			updated_at = NOW()
+		RETURNING ` + recipientColumns
+
+	saved, err := scanRecipient(r.db.Pool().QueryRow(ctx, query,
+		rc.TenantID, rc.UserID, rc.Email, rc.Phone, textArray(rc.DeviceTokens), rc.Locale, rc.Timezone))
+	if err != nil {
+		return fmt.Errorf("upsert recipient: %w", err)
+	}
+
+	*rc = *saved
+	return nil
+}
+
+// DeleteRecipient removes the tenant's recipient record for userID.
+func (r *Repository) DeleteRecipient(ctx context.Context, tenantID, userID uuid.UUID) error {
+	result, err := r.db.Pool().Exec(ctx,
+		`DELETE FROM recipients WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("delete recipient: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoRecipient
+	}
+	return nil
+}
+
+// ErrNoSendLimit is returned by LiftTenantSendLimit when the tenant has no
+// active limit on the channel.
+var ErrNoSendLimit = errors.New("tenant has no send limit on this channel")
+
+// SetTenantSendLimit applies l, replacing any limit the tenant already has
+// on the channel. since is kept when the state doesn't change.
+func (r *Repository) SetTenantSendLimit(ctx context.Context, l *TenantSendLimit) error {
+	query := `
+		INSERT INTO tenant_send_limits (tenant_id, channel, state, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, channel)
+		DO UPDATE SET
+			state = EXCLUDED.state,
+			reason = EXCLUDED.reason,
+			since = CASE
+				WHEN tenant_send_limits.lifted_at IS NULL AND tenant_send_limits.state = EXCLUDED.state
+				THEN tenant_send_limits.since
+				ELSE NOW()
+			END,
+			lifted_at = NULL
+		RETURNING since
+	`
+
+	if err := r.db.Pool().QueryRow(ctx, query, l.TenantID, l.Channel, l.State, l.Reason).Scan(&l.Since); err != nil {
+		return fmt.Errorf("set tenant send limit: %w", err)
+	}
+
+	return nil
+}
+
+// LiftTenantSendLimit removes the tenant's active limit on channel.
+func (r *Repository) LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error {
+	query := `
+		UPDATE tenant_send_limits
+		SET lifted_at = NOW()
+		WHERE tenant_id = $1 AND channel = $2 AND lifted_at IS NULL
+	`
+
+	result, err := r.db.Pool().Exec(ctx, query, tenantID, channel)
+	if err != nil {
+		return fmt.Errorf("lift tenant send limit: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoSendLimit
+	}
+
+	return nil
+}
+
+// ListTenantSendLimits returns every active send limit, oldest first.
+func (r *Repository) ListTenantSendLimits(ctx context.Context) ([]*TenantSendLimit, error) {
+	query := `
+		SELECT tenant_id, channel, state, reason, since
+		FROM tenant_send_limits
+		WHERE lifted_at IS NULL
+		ORDER BY since
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant send limits: %w", err)
+	}
+	defer rows.Close()
+
+	var limits []*TenantSendLimit
+	for rows.Next() {
+		var l TenantSendLimit
+		if err := rows.Scan(&l.TenantID, &l.Channel, &l.State, &l.Reason, &l.Since); err != nil {
+			return nil, fmt.Errorf("scan tenant send limit: %w", err)
+		}
+		limits = append(limits, &l)
+	}
+
+	return limits, rows.Err()
+}
+
+// ReserveWarmupSend counts one email against the tenant's warm-up cap for
+// today, where schedule[d] is the cap on the tenant's day d of sending. The
+// first call for a tenant starts its warm-up. allowed is false, and nothing
+// is counted, when today's cap is used up. graduated reports that the
+// schedule no longer applies to the tenant, so callers can stop asking.
+func (r *Repository) ReserveWarmupSend(ctx context.Context, tenantID uuid.UUID, schedule []int) (allowed, graduated bool, err error) {
+	query := `
+		WITH today AS (SELECT (NOW() AT TIME ZONE 'UTC')::date AS day)
+		INSERT INTO tenant_email_warmups AS w (tenant_id, sent_today)
+		VALUES ($1, 1)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			day = (SELECT day FROM today),
+			sent_today = CASE WHEN w.day = (SELECT day FROM today) THEN w.sent_today + 1 ELSE 1 END
+		WHERE w.ended_at IS NOT NULL
+		   OR w.day <> (SELECT day FROM today)
+		   OR COALESCE(w.sent_today < ($2::int[])[(SELECT day FROM today) - (w.started_at AT TIME ZONE 'UTC')::date + 1], true)
+		RETURNING w.ended_at IS NOT NULL
+		       OR (SELECT day FROM today) - (w.started_at AT TIME ZONE 'UTC')::date + 1 > cardinality($2::int[])
+	`
+
+	err = r.db.Pool().QueryRow(ctx, query, tenantID, schedule).Scan(&graduated)
+	if err == pgx.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("reserve warm-up send: %w", err)
+	}
+
+	return true, graduated, nil
+}
+
+// ErrNoEmailWarmup is returned by GetEmailWarmup for a tenant that has
+// never sent email.
+var ErrNoEmailWarmup = errors.New("tenant has no email warm-up")
+
+// GetEmailWarmup returns the tenant's warm-up progress.
+func (r *Repository) GetEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*EmailWarmup, error) {
+	query := `
+		SELECT tenant_id, started_at, ended_at,
+			CASE WHEN day = (NOW() AT TIME ZONE 'UTC')::date THEN sent_today ELSE 0 END
+		FROM tenant_email_warmups
+		WHERE tenant_id = $1
+	`
+
+	var w EmailWarmup
+	err := r.db.Pool().QueryRow(ctx, query, tenantID).Scan(&w.TenantID, &w.StartedAt, &w.EndedAt, &w.SentToday)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoEmailWarmup
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get email warm-up: %w", err)
+	}
+
+	return &w, nil
+}
+
+// EndEmailWarmup lifts the warm-up cap for a tenant for good, e.g. one
+// moving an established sending history over to us. A tenant that hasn't
+// sent yet gets a warm-up row that is already ended.
+func (r *Repository) EndEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*EmailWarmup, error) {
+	query := `
+		INSERT INTO tenant_email_warmups (tenant_id, ended_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (tenant_id)
+		DO UPDATE SET ended_at = COALESCE(tenant_email_warmups.ended_at, NOW())
+		RETURNING tenant_id, started_at, ended_at,
+			CASE WHEN day = (NOW() AT TIME ZONE 'UTC')::date THEN sent_today ELSE 0 END
+	`
+
+	var w EmailWarmup
+	if err := r.db.Pool().QueryRow(ctx, query, tenantID).Scan(&w.TenantID, &w.StartedAt, &w.EndedAt, &w.SentToday); err != nil {
+		return nil, fmt.Errorf("end email warm-up: %w", err)
+	}
+
+	return &w, nil
+}
+
+// ListTenantUsage returns a tenant's daily sent counts and costs per
+// channel for the UTC days from through to, inclusive, oldest first.
+func (r *Repository) ListTenantUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*DailyUsage, error) {
+	query := `
+		SELECT day, channel, sent, cost
+		FROM tenant_daily_usage
+		WHERE tenant_id = $1 AND day BETWEEN $2::date AND $3::date
+		ORDER BY day, channel
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*DailyUsage
+	for rows.Next() {
+		var u DailyUsage
+		if err := rows.Scan(&u.Day, &u.Channel, &u.Sent, &u.Cost); err != nil {
+			return nil, fmt.Errorf("scan tenant usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	return usage, rows.Err()
+}
+
+// ErrNoTenantBudget is returned when a tenant has no budget set.
+var ErrNoTenantBudget = errors.New("tenant has no budget")
+
+const tenantBudgetColumns = `
+	tenant_id, monthly_cost, monthly_volume, alert_email, block_at_limit,
+	CASE WHEN alerted_month = date_trunc('month', NOW() AT TIME ZONE 'UTC')::date THEN alerted_percent ELSE 0 END,
+	created_at, updated_at`
+
+func scanTenantBudget(row pgx.Row) (*TenantBudget, error) {
+	var b TenantBudget
+	err := row.Scan(&b.TenantID, &b.MonthlyCost, &b.MonthlyVolume, &b.AlertEmail, &b.BlockAtLimit,
+		&b.AlertedPercent, &b.CreatedAt, &b.UpdatedAt)
+	return &b, err
+}
+
+// GetTenantBudget returns the tenant's budget.
+func (r *Repository) GetTenantBudget(ctx context.Context, tenantID uuid.UUID) (*TenantBudget, error) {
+	query := `SELECT ` + tenantBudgetColumns + ` FROM tenant_budgets WHERE tenant_id = $1`
+
+	b, err := scanTenantBudget(r.db.Pool().QueryRow(ctx, query, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoTenantBudget
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tenant budget: %w", err)
+	}
+
+	return b, nil
+}
+
+// UpsertTenantBudget sets the tenant's budget. Changing it re-arms this
+// month's alerts, so a tenant still over a raised budget hears about it.
+func (r *Repository) UpsertTenantBudget(ctx context.Context, b *TenantBudget) error {
+	query := `
+		INSERT INTO tenant_budgets (tenant_id, monthly_cost, monthly_volume, alert_email, block_at_limit)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id)
+		DO UPDATE SET monthly_cost = EXCLUDED.monthly_cost,
+			monthly_volume = EXCLUDED.monthly_volume,
+			alert_email = EXCLUDED.alert_email,
+			block_at_limit = EXCLUDED.block_at_limit,
+			alerted_month = NULL,
+			alerted_percent = 0,
+			updated_at = NOW()
+		RETURNING ` + tenantBudgetColumns
+
+	saved, err := scanTenantBudget(r.db.Pool().QueryRow(ctx, query,
+		b.TenantID, b.MonthlyCost, b.MonthlyVolume, b.AlertEmail, b.BlockAtLimit))
+	if err != nil {
+		return fmt.Errorf("upsert tenant budget: %w", err)
+	}
+
+	*b = *saved
+	return nil
+}
+
+// DeleteTenantBudget removes the tenant's budget, lifting any block.
+func (r *Repository) DeleteTenantBudget(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM tenant_budgets WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete tenant budget: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoTenantBudget
+	}
+
+	return nil
+}
+
+// ListBudgetUsage returns every budget with the tenant's sends and cost so
+// far in the current UTC month.
+func (r *Repository) ListBudgetUsage(ctx context.Context) ([]*BudgetUsage, error) {
+	query := `
+		SELECT ` + tenantBudgetColumns + `, COALESCE(u.sent, 0), COALESCE(u.cost, 0)
+		FROM tenant_budgets b
+		LEFT JOIN LATERAL (
+			SELECT SUM(sent) AS sent, SUM(cost) AS cost
+			FROM tenant_daily_usage
+			WHERE tenant_id = b.tenant_id
+			  AND day >= date_trunc('month', NOW() AT TIME ZONE 'UTC')::date
+		) u ON TRUE
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query budget usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*BudgetUsage
+	for rows.Next() {
+		var b TenantBudget
+		u := BudgetUsage{Budget: &b}
+		if err := rows.Scan(&b.TenantID, &b.MonthlyCost, &b.MonthlyVolume, &b.AlertEmail, &b.BlockAtLimit,
+			&b.AlertedPercent, &b.CreatedAt, &b.UpdatedAt, &u.Sent, &u.Cost); err != nil {
+			return nil, fmt.Errorf("scan budget usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	return usage, rows.Err()
+}
+
+// RecordBudgetAlert raises the tenant's alerted threshold for this month to
+// percent and, in the same transaction, enqueues alert. It returns false
+// without enqueuing when that threshold was already alerted on, so
+// concurrent or repeated checks send each alert once.
+func (r *Repository) RecordBudgetAlert(ctx context.Context, tenantID uuid.UUID, percent int, alert *Notification) (bool, error) {
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE tenant_budgets
+		SET alerted_month = date_trunc('month', NOW() AT TIME ZONE 'UTC')::date, alerted_percent = $2
+		WHERE tenant_id = $1
+		  AND (alerted_month IS DISTINCT FROM date_trunc('month', NOW() AT TIME ZONE 'UTC')::date
+		       OR alerted_percent < $2)
+	`, tenantID, percent)
+	if err != nil {
+		return false, fmt.Errorf("record budget alert: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO notifications (
+			id, tenant_id, user_id, channel, payload,
+			status, attempt, correlation_id, metadata, tags
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`,
+		alert.ID, alert.TenantID, alert.UserID, alert.Channel, alert.Payload,
+		alert.Status, alert.Attempt, alert.CorrelationID,
+		jsonbOrEmpty(alert.Metadata), textArray(alert.Tags),
+	).Scan(&alert.CreatedAt, &alert.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("insert budget alert: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// ErrNoFrequencyCap is returned by DeleteFrequencyCap when the tenant has
+// no cap on the channel.
+var ErrNoFrequencyCap = errors.New("tenant has no frequency cap on this channel")
+
+const frequencyCapColumns = `tenant_id, channel, category, max_count, window_seconds, action, created_at, updated_at`
+
+func scanFrequencyCap(row pgx.Row) (*FrequencyCap, error) {
+	var c FrequencyCap
+	err := row.Scan(&c.TenantID, &c.Channel, &c.Category, &c.MaxCount, &c.WindowSeconds, &c.Action, &c.CreatedAt, &c.UpdatedAt)
+	return &c, err
+}
+
+// ListFrequencyCaps returns every tenant's frequency caps.
+func (r *Repository) ListFrequencyCaps(ctx context.Context) ([]*FrequencyCap, error) {
+	return r.queryFrequencyCaps(ctx, `SELECT `+frequencyCapColumns+` FROM frequency_caps`)
+}
+
+// ListTenantFrequencyCaps returns the tenant's frequency caps by channel
+// and category.
+func (r *Repository) ListTenantFrequencyCaps(ctx context.Context, tenantID uuid.UUID) ([]*FrequencyCap, error) {
+	return r.queryFrequencyCaps(ctx, `SELECT `+frequencyCapColumns+` FROM frequency_caps WHERE tenant_id = $1 ORDER BY channel, category`, tenantID)
+}
+
+func (r *Repository) queryFrequencyCaps(ctx context.Context, query string, args ...any) ([]*FrequencyCap, error) {
+	rows, err := r.db.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query frequency caps: %w", err)
+	}
+	defer rows.Close()
+
+	var caps []*FrequencyCap
+	for rows.Next() {
+		c, err := scanFrequencyCap(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan frequency cap: %w", err)
+		}
+		caps = append(caps, c)
+	}
+
+	return caps, rows.Err()
+}
+
+// UpsertFrequencyCap sets the tenant's cap on c.Channel, for c.Category
+// or, if that is empty, the whole channel.
+func (r *Repository) UpsertFrequencyCap(ctx context.Context, c *FrequencyCap) error {
+	query := `
+		INSERT INTO frequency_caps (tenant_id, channel, category, max_count, window_seconds, action)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, channel, category)
+		DO UPDATE SET max_count = EXCLUDED.max_count,
+			window_seconds = EXCLUDED.window_seconds,
+			action = EXCLUDED.action,
+			updated_at = NOW()
+		RETURNING ` + frequencyCapColumns
+
+	saved, err := scanFrequencyCap(r.db.Pool().QueryRow(ctx, query,
+		c.TenantID, c.Channel, c.Category, c.MaxCount, c.WindowSeconds, c.Action))
+	if err != nil {
+		return fmt.Errorf("upsert frequency cap: %w", err)
+	}
+
+	*c = *saved
+	return nil
+}
+
+// DeleteFrequencyCap removes the tenant's cap on channel for category (empty for
+// the channel-wide cap). Notifications it deferred go out on their next
+// check; capped ones stay capped.
+func (r *Repository) DeleteFrequencyCap(ctx context.Context, tenantID uuid.UUID, channel, category string) error {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM frequency_caps WHERE tenant_id = $1 AND channel = $2 AND category = $3`, tenantID, channel, category)
+	if err != nil {
+		return fmt.Errorf("delete frequency cap: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoFrequencyCap
+	}
+
+	return nil
+}
+
+// CountUserSends counts the notifications of category (empty for any) delivered
+// to the user on channel since then, and returns when the oldest of them
+// was sent (nil if none). Notifications collapsed into an earlier delivery
+// aren't counted: the user never saw them.
+func (r *Repository) CountUserSends(ctx context.Context, tenantID, userID uuid.UUID, channel, category string, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT COUNT(*), MIN(updated_at)
+		FROM notifications
+		WHERE tenant_id = $1 AND user_id = $2 AND channel = $3
+		  AND status = 'sent' AND collapsed_into IS NULL
+		  AND updated_at >= $4
+		  AND ($5 = '' OR category = $5)
+	`
+
+	var count int
+	var oldest *time.Time
+	err := r.db.Pool().QueryRow(ctx, query, tenantID, userID, channel, since, category).Scan(&count, &oldest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("count user sends: %w", err)
+	}
+
+	return count, oldest, nil
+}
+
+// ErrNoNotificationCategory is returned when a tenant has no category of
+// that name.
+var ErrNoNotificationCategory = errors.New("tenant has no notification category of that name")
+
+const notificationCategoryColumns = `
+	tenant_id, name, description, mandatory, quiet_hours_start, quiet_hours_end, created_at, updated_at`
+
+func scanNotificationCategory(row pgx.Row) (*NotificationCategory, error) {
+	var c NotificationCategory
+	var quietStart, quietEnd *string
+	err := row.Scan(&c.TenantID, &c.Name, &c.Description, &c.Mandatory, &quietStart, &quietEnd, &c.CreatedAt, &c.UpdatedAt)
+	if quietStart != nil && quietEnd != nil {
+		c.QuietHours = &QuietHours{Start: *quietStart, End: *quietEnd}
+	}
+	return &c, err
+}
+
+// quietHoursColumns splits q into the quiet_hours_start and quiet_hours_end
+// column values, both NULL when q is nil.
+func quietHoursColumns(q *QuietHours) (start, end *string) {
+	if q == nil {
+		return nil, nil
+	}
+	return &q.Start, &q.End
+}
+
+// ListNotificationCategories returns every tenant's notification categories.
+func (r *Repository) ListNotificationCategories(ctx context.Context) ([]*NotificationCategory, error) {
+	return r.queryNotificationCategories(ctx, `SELECT `+notificationCategoryColumns+` FROM notification_categories`)
+}
+
+// ListTenantNotificationCategories returns the tenant's taxonomy by name.
+func (r *Repository) ListTenantNotificationCategories(ctx context.Context, tenantID uuid.UUID) ([]*NotificationCategory, error) {
+	return r.queryNotificationCategories(ctx, `SELECT `+notificationCategoryColumns+` FROM notification_categories WHERE tenant_id = $1 ORDER BY name`, tenantID)
+}
+
+func (r *Repository) queryNotificationCategories(ctx context.Context, query string, args ...any) ([]*NotificationCategory, error) {
+	rows, err := r.db.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query notification categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*NotificationCategory
+	for rows.Next() {
+		c, err := scanNotificationCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetNotificationCategory returns the tenant's category called name.
+func (r *Repository) GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*NotificationCategory, error) {
+	query := `SELECT ` + notificationCategoryColumns + ` FROM notification_categories WHERE tenant_id = $1 AND name = $2`
+
+	c, err := scanNotificationCategory(r.db.Pool().QueryRow(ctx, query, tenantID, name))
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoNotificationCategory
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get notification category: %w", err)
+	}
+
+	return c, nil
+}
+
+// UpsertNotificationCategory creates or replaces the tenant's category
+// c.Name.
+func (r *Repository) UpsertNotificationCategory(ctx context.Context, c *NotificationCategory) error {
+	query := `
+		INSERT INTO notification_categories (tenant_id, name, description, mandatory, quiet_hours_start, quiet_hours_end)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, name)
+		DO UPDATE SET description = EXCLUDED.description,
+			mandatory = EXCLUDED.mandatory,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			updated_at = NOW()
+		RETURNING ` + notificationCategoryColumns
+
+	quietStart, quietEnd := quietHoursColumns(c.QuietHours)
+	saved, err := scanNotificationCategory(r.db.Pool().QueryRow(ctx, query,
+		c.TenantID, c.Name, c.Description, c.Mandatory, quietStart, quietEnd))
+	if err != nil {
+		return fmt.Errorf("upsert notification category: %w", err)
+	}
+
+	*c = *saved
+	return nil
+}
+
+// DeleteNotificationCategory removes the tenant's category name, and users'
+// opt-outs of it with it. Notifications already created keep the name.
+func (r *Repository) DeleteNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) error {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM notification_categories WHERE tenant_id = $1 AND name = $2`, tenantID, name)
+	if err != nil {
+		return fmt.Errorf("delete notification category: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoNotificationCategory
+	}
+
+	return nil
+}
+
+// ListCategoryOptOuts returns the user's opt-outs by category and channel.
+func (r *Repository) ListCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID) ([]*CategoryOptOut, error) {
+	query := `
+		SELECT category, channel
+		FROM category_opt_outs
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY category, channel
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query category opt-outs: %w", err)
+	}
+	defer rows.Close()
+
+	var optOuts []*CategoryOptOut
+	for rows.Next() {
+		var o CategoryOptOut
+		if err := rows.Scan(&o.Category, &o.Channel); err != nil {
+			return nil, fmt.Errorf("scan category opt-out: %w", err)
+		}
+		optOuts = append(optOuts, &o)
+	}
+
+	return optOuts, rows.Err()
+}
+
+// ReplaceCategoryOptOuts replaces all of the user's opt-outs with optOuts.
+// Every category must be one of the tenant's.
+func (r *Repository) ReplaceCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID, optOuts []*CategoryOptOut) error {
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM category_opt_outs WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID); err != nil {
+		return fmt.Errorf("delete category opt-outs: %w", err)
+	}
+	for _, o := range optOuts {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO category_opt_outs (tenant_id, user_id, category, channel)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, tenantID, userID, o.Category, o.Channel)
+		if err != nil {
+			return fmt.Errorf("insert category opt-out: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IsOptedOut reports whether the user opted out of category on channel,
+// or on every channel.
+func (r *Repository) IsOptedOut(ctx context.Context, tenantID, userID uuid.UUID, category, channel string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM category_opt_outs
+			WHERE tenant_id = $1 AND user_id = $2 AND category = $3
+			  AND channel IN ('', $4)
+		)
+	`
+
+	var optedOut bool
+	if err := r.db.Pool().QueryRow(ctx, query, tenantID, userID, category, channel).Scan(&optedOut); err != nil {
+		return false, fmt.Errorf("check category opt-out: %w", err)
+	}
+
+	return optedOut, nil
+}
+
+// ListCategoryStats counts the tenant's notifications created in [from, to)
+// by category, channel and status.
+func (r *Repository) ListCategoryStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*CategoryStats, error) {
+	query := `
+		SELECT COALESCE(category, ''), channel, status, COUNT(*)
+		FROM notifications
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := r.db.Pool().Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query category stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*CategoryStats
+	for rows.Next() {
+		var s CategoryStats
+		if err := rows.Scan(&s.Category, &s.Channel, &s.Status, &s.Count); err != nil {
+			return nil, fmt.Errorf("scan category stats: %w", err)
+		}
+		stats = append(stats, &s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetQueueOverview returns current queue depth, plus per-channel outcomes
+// and the topTenants tenants with the most failures among notifications
+// finished since since. Failed counts both failed and dead-lettered rows.
+func (r *Repository) GetQueueOverview(ctx context.Context, since time.Time, topTenants int) (*QueueOverview, error) {
+	var o QueueOverview
+	err := r.db.Pool().QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW())),
+			COUNT(*) FILTER (WHERE status = 'pending' AND next_retry_at > NOW()),
+			COUNT(*) FILTER (WHERE status = 'processing'),
+			COUNT(*) FILTER (WHERE status = 'held'),
+			MIN(COALESCE(next_retry_at, created_at)) FILTER (WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW()))
+		FROM notifications
+		WHERE status IN ('pending', 'processing', 'held')
+	`).Scan(&o.Due, &o.Scheduled, &o.Processing, &o.Held, &o.OldestDueAt)
+	if err != nil {
+		return nil, fmt.Errorf("query queue depth: %w", err)
+	}
+
+	rows, err := r.db.Pool().Query(ctx, `
+		SELECT channel,
+			COUNT(*) FILTER (WHERE status = 'sent'),
+			COUNT(*) FILTER (WHERE status <> 'sent')
+		FROM notifications
+		WHERE status IN ('sent', 'failed', 'dead_lettered') AND updated_at >= $1
+		GROUP BY channel
+		ORDER BY channel
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("query channel throughput: %w", err)
+	}
+	defer rows.Close()
+
+	o.Channels = []*ChannelThroughput{}
+	for rows.Next() {
+		var c ChannelThroughput
+		if err := rows.Scan(&c.Channel, &c.Sent, &c.Failed); err != nil {
+			return nil, fmt.Errorf("scan channel throughput: %w", err)
+		}
+		c.ErrorRate = float64(c.Failed) / float64(c.Sent+c.Failed)
+		o.Channels = append(o.Channels, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan channel throughput: %w", err)
+	}
+
+	rows, err = r.db.Pool().Query(ctx, `
+		SELECT tenant_id,
+			COUNT(*) FILTER (WHERE status <> 'sent') AS failed,
+			COUNT(*)
+		FROM notifications
+		WHERE status IN ('sent', 'failed', 'dead_lettered') AND updated_at >= $1
+		GROUP BY tenant_id
+		HAVING COUNT(*) FILTER (WHERE status <> 'sent') > 0
+		ORDER BY failed DESC, tenant_id
+		LIMIT $2
+	`, since, topTenants)
+	if err != nil {
+		return nil, fmt.Errorf("query failing tenants: %w", err)
+	}
+	defer rows.Close()
+
+	o.TopFailingTenants = []*TenantFailures{}
+	for rows.Next() {
+		var t TenantFailures
+		if err := rows.Scan(&t.TenantID, &t.Failed, &t.Finished); err != nil {
+			return nil, fmt.Errorf("scan failing tenant: %w", err)
+		}
+		t.ErrorRate = float64(t.Failed) / float64(t.Finished)
+		o.TopFailingTenants = append(o.TopFailingTenants, &t)
+	}
+
+	return &o, rows.Err()
+}
+
+// GetBacklog counts due notifications and unresolved dead letters.
+func (r *Repository) GetBacklog(ctx context.Context) (*Backlog, error) {
+	b := Backlog{Channels: map[string]int64{}}
+	rows, err := r.db.Pool().Query(ctx, `
+		SELECT channel, COUNT(*), MIN(COALESCE(next_retry_at, created_at))
+		FROM notifications
+		WHERE status = 'pending' AND (next_retry_at IS NULL OR next_retry_at <= NOW())
+		GROUP BY channel
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query backlog: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var channel string
+		var due int64
+		var oldest time.Time
+		if err := rows.Scan(&channel, &due, &oldest); err != nil {
+			return nil, fmt.Errorf("scan backlog: %w", err)
+		}
+		b.AddDue(channel, due, oldest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan backlog: %w", err)
+	}
+
+	err = r.db.Pool().QueryRow(ctx, `
+		SELECT COUNT(*) FROM dead_letter_notifications WHERE status = 'pending'
+	`).Scan(&b.DeadLetters)
+	if err != nil {
+		return nil, fmt.Errorf("query dead letter backlog: %w", err)
+	}
+	return &b, nil
+}
+
+// ErrNoTenantRateLimit is returned when a tenant has no rate limit override.
+var ErrNoTenantRateLimit = errors.New("tenant has no rate limit override")
+
+const tenantRateLimitColumns = `tenant_id, burst, retry_after_seconds, created_at, updated_at`
+
+func scanTenantRateLimit(row pgx.Row) (*TenantRateLimit, error) {
+	var l TenantRateLimit
+	err := row.Scan(&l.TenantID, &l.Burst, &l.RetryAfterSeconds, &l.CreatedAt, &l.UpdatedAt)
+	return &l, err
+}
+
+// GetTenantRateLimit returns the tenant's rate limit override.
+func (r *Repository) GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*TenantRateLimit, error) {
+	query := `SELECT ` + tenantRateLimitColumns + ` FROM tenant_rate_limits WHERE tenant_id = $1`
+
+	l, err := scanTenantRateLimit(r.db.Pool().QueryRow(ctx, query, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoTenantRateLimit
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tenant rate limit: %w", err)
+	}
+
+	return l, nil
+}
+
+// UpsertTenantRateLimit sets the tenant's rate limit override.
+func (r *Repository) UpsertTenantRateLimit(ctx context.Context, l *TenantRateLimit) error {
+	query := `
+		INSERT INTO tenant_rate_limits (tenant_id, burst, retry_after_seconds)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id)
+		DO UPDATE SET burst = EXCLUDED.burst,
+			retry_after_seconds = EXCLUDED.retry_after_seconds,
+			updated_at = NOW()
+		RETURNING ` + tenantRateLimitColumns
+
+	saved, err := scanTenantRateLimit(r.db.Pool().QueryRow(ctx, query, l.TenantID, l.Burst, l.RetryAfterSeconds))
+	if err != nil {
+		return fmt.Errorf("upsert tenant rate limit: %w", err)
+	}
+
+	*l = *saved
+	return nil
+}
+
+// DeleteTenantRateLimit removes the tenant's override, putting it back on
+// the defaults.
+func (r *Repository) DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := r.db.Pool().Exec(ctx, `DELETE FROM tenant_rate_limits WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete tenant rate limit: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoTenantRateLimit
+	}
+
+	return nil
+}
+
+// ListTenantRateLimits returns every rate limit override.
+func (r *Repository) ListTenantRateLimits(ctx context.Context) ([]*TenantRateLimit, error) {
+	rows, err := r.db.Pool().Query(ctx, `SELECT `+tenantRateLimitColumns+` FROM tenant_rate_limits ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query tenant rate limits: %w", err)
+	}
+	defer rows.Close()
+
+	var limits []*TenantRateLimit
+	for rows.Next() {
+		l, err := scanTenantRateLimit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan tenant rate limit: %w", err)
+		}
+		limits = append(limits, l)
+	}
+
+	return limits, rows.Err()
+}
+
+// ErrImportNotFound is returned when no import has the given ID.
+var ErrImportNotFound = errors.New("import not found")
+
+const importColumns = `
+	id, tenant_id, channel, format, source, status, total_rows, created_rows,
+	failed_rows, row_errors, error, created_at, updated_at, finished_at`
+
+func scanImport(row pgx.Row) (*NotificationImport, error) {
+	var imp NotificationImport
+	var rowErrors []byte
+	err := row.Scan(&imp.ID, &imp.TenantID, &imp.Channel, &imp.Format, &imp.Source, &imp.Status,
+		&imp.TotalRows, &imp.CreatedRows, &imp.FailedRows, &rowErrors, &imp.Error,
+		&imp.CreatedAt, &imp.UpdatedAt, &imp.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rowErrors, &imp.RowErrors); err != nil {
+		return nil, fmt.Errorf("decode row errors: %w", err)
+	}
+	return &imp, nil
+}
+
+// importRowErrors encodes an import's row errors, as '[]' when there are
+// none.
+func importRowErrors(errs []ImportRowError) ([]byte, error) {
+	if errs == nil {
+		errs = []ImportRowError{}
+	}
+	return json.Marshal(errs)
+}
+
+// CreateImport records a new import job. imp.ID is generated when unset.
+func (r *Repository) CreateImport(ctx context.Context, imp *NotificationImport) error {
+	if imp.ID == uuid.Nil {
+		imp.ID = uuid.New()
+	}
+	rowErrors, err := importRowErrors(imp.RowErrors)
+	if err != nil {
+		return fmt.Errorf("encode row errors: %w", err)
+	}
+
+	query := `
+		INSERT INTO notification_imports (id, tenant_id, channel, format, source, status, row_errors)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.Pool().QueryRow(ctx, query,
+		imp.ID, imp.TenantID, imp.Channel, imp.Format, imp.Source, imp.Status, rowErrors,
+	).Scan(&imp.CreatedAt, &imp.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert import: %w", err)
+	}
+
+	return nil
+}
+
+// GetImport returns an import job by ID.
+func (r *Repository) GetImport(ctx context.Context, id uuid.UUID) (*NotificationImport, error) {
+	query := `SELECT ` + importColumns + ` FROM notification_imports WHERE id = $1`
+
+	imp, err := scanImport(r.db.Pool().QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, ErrImportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get import: %w", err)
+	}
+
+	return imp, nil
+}
+
+// UpdateImport saves an import's progress: its status, row counts, row
+// errors, error and finish time.
+func (r *Repository) UpdateImport(ctx context.Context, imp *NotificationImport) error {
+	rowErrors, err := importRowErrors(imp.RowErrors)
+	if err != nil {
+		return fmt.Errorf("encode row errors: %w", err)
+	}
+
+	query := `
+		UPDATE notification_imports
+		SET status = $2, total_rows = $3, created_rows = $4, failed_rows = $5,
+		    row_errors = $6, error = $7, finished_at = $8
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err = r.db.Pool().QueryRow(ctx, query,
+		imp.ID, imp.Status, imp.TotalRows, imp.CreatedRows, imp.FailedRows,
+		rowErrors, imp.Error, imp.FinishedAt,
+	).Scan(&imp.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrImportNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update import: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+DROP TRIGGER IF EXISTS tenant_rate_limits_updated_at;
+DROP TRIGGER IF EXISTS tenant_budgets_updated_at;
+DROP TRIGGER IF EXISTS tenant_ip_allowlists_updated_at;
+DROP TRIGGER IF EXISTS tenant_channel_settings_updated_at;
+DROP TRIGGER IF EXISTS templates_updated_at;
+DROP TRIGGER IF EXISTS dead_letter_notifications_updated_at;
+DROP TRIGGER IF EXISTS notifications_updated_at;
+DROP TRIGGER IF EXISTS record_notification_transition;
+DROP TRIGGER IF EXISTS record_notification_created;
+DROP TABLE IF EXISTS session_tenant;
+DROP TABLE IF EXISTS tenant_rate_limits;
+DROP TABLE IF EXISTS notification_events;
+DROP TABLE IF EXISTS tenant_budgets;
+DROP TABLE IF EXISTS tenant_daily_usage;
+DROP TABLE IF EXISTS tenant_email_warmups;
+DROP TABLE IF EXISTS tenant_send_limits;
+DROP TABLE IF EXISTS delivery_events;
+DROP TABLE IF EXISTS channel_kill_switches;
+DROP TABLE IF EXISTS tenant_delivery_pauses;
+DROP TABLE IF EXISTS notification_edits;
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS tenant_ip_allowlists;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS tenant_channel_settings;
+DROP TABLE IF EXISTS link_clicks;
+DROP TABLE IF EXISTS short_links;
+DROP TABLE IF EXISTS templates;
+DROP TABLE IF EXISTS captured_deliveries;
+DROP TABLE IF EXISTS dead_letter_notifications;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,384 @@
+-- Nimbus schema for SQLite, equivalent to Postgres migrations 001-026 (see
+-- migrations/README.md). The gateway applies it on startup with
+-- DB_DRIVER=sqlite. Differences from the Postgres schema:
+--   * UUIDs are TEXT, JSONB and TEXT[] columns are JSON text.
+--   * Timestamps are DATETIME text in UTC, always formatted as
+--     'YYYY-MM-DD HH:MM:SS.SSS' so that they compare correctly as strings.
+--     Only write them with the now expression below or the repository's
+--     formatTime.
+--   * updated_at is maintained by triggers, as on Postgres, but only when
+--     the UPDATE doesn't set it itself.
+--   * There is no row level security and no knowledge_base (pgvector).
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook')),
+    payload JSON NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held')),
+    attempt INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    next_retry_at DATETIME,
+
+    correlation_id TEXT NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT '{}',
+    tags JSON NOT NULL DEFAULT '[]',
+
+    provider TEXT,
+    provider_message_id TEXT,
+    cost REAL,
+    archive_key TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_retry ON notifications (next_retry_at, created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notifications_processing ON notifications (updated_at) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant ON notifications (tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (tenant_id, user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_channel ON notifications (channel, status);
+CREATE INDEX IF NOT EXISTS idx_notifications_provider_message_id ON notifications (provider_message_id) WHERE provider_message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_finished ON notifications (updated_at) WHERE status IN ('sent', 'dead_lettered');
+
+CREATE TABLE IF NOT EXISTS dead_letter_notifications (
+    id TEXT NOT NULL PRIMARY KEY,
+    original_notification_id TEXT NOT NULL,
+
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook')),
+    payload JSON NOT NULL,
+    correlation_id TEXT NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT '{}',
+    tags JSON NOT NULL DEFAULT '[]',
+
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'retried', 'discarded')),
+    retried_notification_id TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_dlq_tenant ON dead_letter_notifications (tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_dlq_resolved ON dead_letter_notifications (updated_at) WHERE status IN ('retried', 'discarded');
+
+CREATE TABLE IF NOT EXISTS captured_deliveries (
+    id TEXT NOT NULL PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook')),
+    recipient TEXT NOT NULL,
+    payload JSON NOT NULL,
+
+    captured_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_captured_deliveries_tenant ON captured_deliveries (tenant_id, captured_at);
+
+CREATE TABLE IF NOT EXISTS templates (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+
+    subject TEXT NOT NULL,
+    mjml_source TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+
+    html TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published')),
+    published_at DATETIME,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS short_links (
+    code TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    notification_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    UNIQUE (notification_id, url)
+);
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id TEXT NOT NULL PRIMARY KEY,
+    code TEXT NOT NULL REFERENCES short_links (code) ON DELETE CASCADE,
+    tenant_id TEXT NOT NULL,
+    notification_id TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    clicked_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_clicks_notification ON link_clicks (notification_id, clicked_at);
+
+CREATE TABLE IF NOT EXISTS tenant_channel_settings (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook')),
+    settings JSON NOT NULL DEFAULT '{}',
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, channel)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+
+    prefix TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes JSON NOT NULL DEFAULT '[]',
+
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    replaced_by TEXT REFERENCES api_keys (id),
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS tenant_ip_allowlists (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    cidrs JSON NOT NULL DEFAULT '[]',
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    name TEXT NOT NULL PRIMARY KEY,
+
+    locked_by TEXT,
+    locked_until DATETIME,
+
+    last_started_at DATETIME,
+    last_finished_at DATETIME,
+    last_duration_ms INTEGER,
+    last_error TEXT,
+    last_success_at DATETIME,
+    run_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS notification_edits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    notification_id TEXT NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+
+    old_payload JSON NOT NULL,
+    new_payload JSON NOT NULL,
+    old_send_at DATETIME,
+    new_send_at DATETIME,
+    request_id TEXT NOT NULL DEFAULT '',
+
+    edited_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_edits_notification ON notification_edits (notification_id, edited_at);
+
+CREATE TABLE IF NOT EXISTS tenant_delivery_pauses (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    reason TEXT NOT NULL DEFAULT '',
+
+    paused_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS channel_kill_switches (
+    channel TEXT NOT NULL PRIMARY KEY CHECK (channel IN ('email', 'sms', 'webhook')),
+    reason TEXT NOT NULL DEFAULT '',
+
+    killed_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS delivery_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    notification_id TEXT,
+    tenant_id TEXT,
+    channel TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    provider_message_id TEXT NOT NULL,
+    event_type TEXT NOT NULL CHECK (event_type IN ('delivery', 'bounce', 'complaint')),
+    bounce_type TEXT NOT NULL DEFAULT '',
+    recipient TEXT NOT NULL DEFAULT '',
+
+    occurred_at DATETIME NOT NULL,
+    received_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    UNIQUE (provider, provider_message_id, event_type, recipient)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_events_tenant ON delivery_events (tenant_id, occurred_at);
+
+CREATE TABLE IF NOT EXISTS tenant_send_limits (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    state TEXT NOT NULL CHECK (state IN ('throttled', 'paused')),
+    reason TEXT NOT NULL DEFAULT '',
+
+    since DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    lifted_at DATETIME,
+
+    PRIMARY KEY (tenant_id, channel)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_email_warmups (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    started_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    ended_at DATETIME,
+
+    day DATE NOT NULL DEFAULT (date('now')),
+    sent_today INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS tenant_daily_usage (
+    tenant_id TEXT NOT NULL,
+    day DATE NOT NULL,
+    channel TEXT NOT NULL,
+    sent INTEGER NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (tenant_id, day, channel)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_budgets (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    monthly_cost REAL CHECK (monthly_cost > 0),
+    monthly_volume INTEGER CHECK (monthly_volume > 0),
+    alert_email TEXT NOT NULL,
+    block_at_limit BOOLEAN NOT NULL DEFAULT FALSE,
+
+    alerted_month DATE,
+    alerted_percent INTEGER NOT NULL DEFAULT 0,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    CHECK (monthly_cost IS NOT NULL OR monthly_volume IS NOT NULL)
+);
+
+CREATE TABLE IF NOT EXISTS notification_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    notification_id TEXT NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+    tenant_id TEXT NOT NULL,
+
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    error_message TEXT,
+    actor TEXT NOT NULL CHECK (actor IN ('tenant', 'system')),
+
+    occurred_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_notification ON notification_events (notification_id, id);
+
+CREATE TABLE IF NOT EXISTS tenant_rate_limits (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    burst INTEGER NOT NULL DEFAULT 0 CHECK (burst >= 0),
+    retry_after_seconds INTEGER CHECK (retry_after_seconds IS NULL OR retry_after_seconds > 0),
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+-- session_tenant holds the tenant the gateway is writing for, if any (see
+-- DB.session). The notification_events triggers read it to pick the actor.
+CREATE TABLE IF NOT EXISTS session_tenant (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    tenant_id TEXT
+);
+
+INSERT OR IGNORE INTO session_tenant (id, tenant_id) VALUES (1, NULL);
+
+CREATE TRIGGER IF NOT EXISTS record_notification_created
+AFTER INSERT ON notifications
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id, NULL, NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN (SELECT tenant_id FROM session_tenant) IS NULL THEN 'system' ELSE 'tenant' END
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS record_notification_transition
+AFTER UPDATE OF status, attempt ON notifications
+WHEN OLD.status IS NOT NEW.status OR OLD.attempt IS NOT NEW.attempt
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id, OLD.status, NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN (SELECT tenant_id FROM session_tenant) IS NULL THEN 'system' ELSE 'tenant' END
+    );
+END;
+
+-- Keep updated_at current, as the Postgres update_updated_at_column()
+-- triggers do. An UPDATE that sets updated_at itself keeps its value.
+CREATE TRIGGER IF NOT EXISTS notifications_updated_at
+AFTER UPDATE ON notifications
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE notifications SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS dead_letter_notifications_updated_at
+AFTER UPDATE ON dead_letter_notifications
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE dead_letter_notifications SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS templates_updated_at
+AFTER UPDATE ON templates
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE templates SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tenant_channel_settings_updated_at
+AFTER UPDATE ON tenant_channel_settings
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tenant_channel_settings SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE tenant_id = NEW.tenant_id AND channel = NEW.channel;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tenant_ip_allowlists_updated_at
+AFTER UPDATE ON tenant_ip_allowlists
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tenant_ip_allowlists SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tenant_budgets_updated_at
+AFTER UPDATE ON tenant_budgets
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tenant_budgets SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tenant_rate_limits_updated_at
+AFTER UPDATE ON tenant_rate_limits
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tenant_rate_limits SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id;
+END;
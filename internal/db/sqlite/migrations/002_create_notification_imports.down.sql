@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS notification_imports_updated_at;
+DROP TABLE IF EXISTS notification_imports;
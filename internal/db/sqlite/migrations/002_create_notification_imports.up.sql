@@ -0,0 +1,27 @@
+-- Bulk import jobs created by POST /v1/imports (Postgres 027).
+CREATE TABLE IF NOT EXISTS notification_imports (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook')),
+    format TEXT NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    source TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    row_errors TEXT NOT NULL DEFAULT '[]',
+    error TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    finished_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_imports_tenant ON notification_imports (tenant_id, created_at);
+
+CREATE TRIGGER IF NOT EXISTS notification_imports_updated_at
+AFTER UPDATE ON notification_imports
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE notification_imports SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS tenant_settings_updated_at;
+DROP TABLE IF EXISTS tenant_settings;
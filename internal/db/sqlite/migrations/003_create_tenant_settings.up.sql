@@ -0,0 +1,15 @@
+-- Tenant-wide API settings (Postgres 028).
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    require_idempotency_key BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TRIGGER IF NOT EXISTS tenant_settings_updated_at
+AFTER UPDATE ON tenant_settings
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tenant_settings SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id;
+END;
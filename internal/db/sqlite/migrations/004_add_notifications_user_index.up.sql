@@ -0,0 +1,2 @@
+-- Cross-tenant lookups by user_id (Postgres 029).
+CREATE INDEX IF NOT EXISTS idx_notifications_user_global ON notifications (user_id, created_at);
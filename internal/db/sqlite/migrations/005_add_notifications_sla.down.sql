@@ -0,0 +1,2 @@
+ALTER TABLE notifications DROP COLUMN sla_breached;
+ALTER TABLE notifications DROP COLUMN sla_seconds;
@@ -0,0 +1,3 @@
+-- Delivery SLA tracking (Postgres 030).
+ALTER TABLE notifications ADD COLUMN sla_seconds INTEGER;
+ALTER TABLE notifications ADD COLUMN sla_breached INTEGER;
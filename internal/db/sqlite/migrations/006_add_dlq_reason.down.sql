@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_dlq_tenant_reason;
+ALTER TABLE dead_letter_notifications DROP COLUMN reason;
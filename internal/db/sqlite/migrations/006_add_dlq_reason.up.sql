@@ -0,0 +1,13 @@
+-- DLQ reason codes (Postgres 031).
+ALTER TABLE dead_letter_notifications ADD COLUMN reason TEXT NOT NULL DEFAULT 'unknown'
+    CHECK (reason IN ('invalid_recipient', 'provider_outage', 'timeout', 'payload_error', 'unknown'));
+
+UPDATE dead_letter_notifications
+SET reason = CASE
+    WHEN LOWER(last_error) LIKE '%circuit breaker is open%' THEN 'provider_outage'
+    WHEN LOWER(last_error) LIKE '%deadline exceeded%' OR LOWER(last_error) LIKE '%timeout%' THEN 'timeout'
+    WHEN LOWER(last_error) LIKE '%payload%' THEN 'payload_error'
+    ELSE 'unknown'
+END;
+
+CREATE INDEX IF NOT EXISTS idx_dlq_tenant_reason ON dead_letter_notifications (tenant_id, reason, created_at);
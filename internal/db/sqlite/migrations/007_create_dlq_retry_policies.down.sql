@@ -0,0 +1,2 @@
+ALTER TABLE dead_letter_notifications DROP COLUMN retry_count;
+DROP TABLE IF EXISTS dlq_retry_policies;
@@ -0,0 +1,15 @@
+-- Automatic DLQ retries (Postgres 032).
+CREATE TABLE IF NOT EXISTS dlq_retry_policies (
+    tenant_id TEXT NOT NULL,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('invalid_recipient', 'provider_outage', 'timeout', 'payload_error', 'unknown')),
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds > 0),
+    max_retries INTEGER NOT NULL CHECK (max_retries > 0),
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, reason)
+);
+
+ALTER TABLE dead_letter_notifications ADD COLUMN retry_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,17 @@
+-- Recipient hygiene (Postgres 033).
+CREATE TABLE IF NOT EXISTS invalid_recipients (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('hard_bounce', 'provider_rejected')),
+    detail TEXT NOT NULL DEFAULT '',
+    notification_id TEXT,
+
+    invalidated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, channel, recipient)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invalid_recipients_tenant
+ON invalid_recipients (tenant_id, invalidated_at DESC);
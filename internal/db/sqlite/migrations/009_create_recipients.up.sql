@@ -0,0 +1,15 @@
+-- Reusable recipients (Postgres 034).
+CREATE TABLE IF NOT EXISTS recipients (
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    device_tokens JSON NOT NULL DEFAULT '[]',
+    locale TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT '',
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, user_id)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notifications_group;
+ALTER TABLE notifications DROP COLUMN collapsed_into;
+ALTER TABLE notifications DROP COLUMN group_key;
@@ -0,0 +1,7 @@
+-- Notification groups (Postgres 035).
+ALTER TABLE notifications ADD COLUMN group_key TEXT;
+ALTER TABLE notifications ADD COLUMN collapsed_into TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_group
+    ON notifications (tenant_id, user_id, channel, group_key, created_at)
+    WHERE group_key IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_dlq_tenant_status;
+DROP INDEX IF EXISTS idx_dlq_tenant_updated;
+DROP INDEX IF EXISTS idx_notifications_tenant_status;
+DROP INDEX IF EXISTS idx_notifications_tenant_updated;
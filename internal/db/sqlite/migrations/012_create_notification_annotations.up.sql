@@ -0,0 +1,15 @@
+-- Provider annotations (Postgres 037).
+CREATE TABLE IF NOT EXISTS notification_annotations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    notification_id TEXT NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+    tenant_id TEXT NOT NULL,
+
+    provider TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    attempt INTEGER,
+    data JSON NOT NULL DEFAULT '{}',
+
+    recorded_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_annotations_notification ON notification_annotations (notification_id, id);
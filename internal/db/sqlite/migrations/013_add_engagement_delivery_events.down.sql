@@ -0,0 +1,23 @@
+CREATE TABLE delivery_events_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    notification_id TEXT,
+    tenant_id TEXT,
+    channel TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    provider_message_id TEXT NOT NULL,
+    event_type TEXT NOT NULL CHECK (event_type IN ('delivery', 'bounce', 'complaint')),
+    bounce_type TEXT NOT NULL DEFAULT '',
+    recipient TEXT NOT NULL DEFAULT '',
+
+    occurred_at DATETIME NOT NULL,
+    received_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    UNIQUE (provider, provider_message_id, event_type, recipient)
+);
+
+INSERT INTO delivery_events_old
+SELECT * FROM delivery_events WHERE event_type IN ('delivery', 'bounce', 'complaint');
+DROP TABLE delivery_events;
+ALTER TABLE delivery_events_old RENAME TO delivery_events;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_events_tenant ON delivery_events (tenant_id, occurred_at);
@@ -0,0 +1,24 @@
+-- Engagement delivery events (Postgres 038). SQLite can't alter a CHECK
+-- constraint, so the table is rebuilt; nothing references it.
+CREATE TABLE delivery_events_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    notification_id TEXT,
+    tenant_id TEXT,
+    channel TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    provider_message_id TEXT NOT NULL,
+    event_type TEXT NOT NULL CHECK (event_type IN ('delivery', 'bounce', 'complaint', 'open', 'click', 'rendering_failure')),
+    bounce_type TEXT NOT NULL DEFAULT '',
+    recipient TEXT NOT NULL DEFAULT '',
+
+    occurred_at DATETIME NOT NULL,
+    received_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    UNIQUE (provider, provider_message_id, event_type, recipient)
+);
+
+INSERT INTO delivery_events_new SELECT * FROM delivery_events;
+DROP TABLE delivery_events;
+ALTER TABLE delivery_events_new RENAME TO delivery_events;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_events_tenant ON delivery_events (tenant_id, occurred_at);
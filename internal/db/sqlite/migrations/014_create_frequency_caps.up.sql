@@ -0,0 +1,117 @@
+-- Frequency caps (Postgres 039).
+CREATE TABLE IF NOT EXISTS frequency_caps (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    max_count INTEGER NOT NULL CHECK (max_count > 0),
+    window_seconds INTEGER NOT NULL CHECK (window_seconds > 0),
+    action TEXT NOT NULL DEFAULT 'defer' CHECK (action IN ('defer', 'drop')),
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, channel)
+);
+
+CREATE TRIGGER IF NOT EXISTS frequency_caps_updated_at
+AFTER UPDATE ON frequency_caps
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE frequency_caps SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id AND channel = NEW.channel;
+END;
+
+-- SQLite can't alter a CHECK constraint, so notifications is rebuilt to
+-- allow 'frequency_capped'. Migrations run in a transaction, where foreign
+-- keys can't be switched off, so dropping the old table empties the tables
+-- that reference it: their rows are copied aside and put back.
+CREATE TEMP TABLE notification_edits_copy AS SELECT * FROM notification_edits;
+CREATE TEMP TABLE notification_events_copy AS SELECT * FROM notification_events;
+CREATE TEMP TABLE notification_annotations_copy AS SELECT * FROM notification_annotations;
+
+CREATE TABLE notifications_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook')),
+    payload JSON NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held', 'frequency_capped')),
+    attempt INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    next_retry_at DATETIME,
+
+    correlation_id TEXT NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT '{}',
+    tags JSON NOT NULL DEFAULT '[]',
+
+    provider TEXT,
+    provider_message_id TEXT,
+    cost REAL,
+    archive_key TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    sla_seconds INTEGER,
+    sla_breached INTEGER,
+    group_key TEXT,
+    collapsed_into TEXT
+);
+
+INSERT INTO notifications_new SELECT * FROM notifications;
+DROP TABLE notifications;
+ALTER TABLE notifications_new RENAME TO notifications;
+
+INSERT INTO notification_edits SELECT * FROM notification_edits_copy;
+INSERT INTO notification_events SELECT * FROM notification_events_copy;
+INSERT INTO notification_annotations SELECT * FROM notification_annotations_copy;
+DROP TABLE notification_edits_copy;
+DROP TABLE notification_events_copy;
+DROP TABLE notification_annotations_copy;
+
+CREATE INDEX idx_notifications_retry ON notifications (next_retry_at, created_at) WHERE status = 'pending';
+CREATE INDEX idx_notifications_processing ON notifications (updated_at) WHERE status = 'processing';
+CREATE INDEX idx_notifications_tenant ON notifications (tenant_id, created_at);
+CREATE INDEX idx_notifications_user ON notifications (tenant_id, user_id, created_at);
+CREATE INDEX idx_notifications_channel ON notifications (channel, status);
+CREATE INDEX idx_notifications_provider_message_id ON notifications (provider_message_id) WHERE provider_message_id IS NOT NULL;
+CREATE INDEX idx_notifications_finished ON notifications (updated_at) WHERE status IN ('sent', 'dead_lettered');
+CREATE INDEX idx_notifications_user_global ON notifications (user_id, created_at);
+CREATE INDEX idx_notifications_group
+    ON notifications (tenant_id, user_id, channel, group_key, created_at)
+    WHERE group_key IS NOT NULL;
+CREATE INDEX idx_notifications_tenant_updated ON notifications (tenant_id, updated_at);
+CREATE INDEX idx_notifications_tenant_status ON notifications (tenant_id, status, created_at);
+
+CREATE TRIGGER record_notification_created
+AFTER INSERT ON notifications
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id, NULL, NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN (SELECT tenant_id FROM session_tenant) IS NULL THEN 'system' ELSE 'tenant' END
+    );
+END;
+
+CREATE TRIGGER record_notification_transition
+AFTER UPDATE OF status, attempt ON notifications
+WHEN OLD.status IS NOT NEW.status OR OLD.attempt IS NOT NEW.attempt
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id, OLD.status, NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN (SELECT tenant_id FROM session_tenant) IS NULL THEN 'system' ELSE 'tenant' END
+    );
+END;
+
+CREATE TRIGGER notifications_updated_at
+AFTER UPDATE ON notifications
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE notifications SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE INDEX idx_notifications_user_sent ON notifications (tenant_id, user_id, channel, updated_at) WHERE status = 'sent';
@@ -0,0 +1,30 @@
+CREATE TABLE frequency_caps_old (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    max_count INTEGER NOT NULL CHECK (max_count > 0),
+    window_seconds INTEGER NOT NULL CHECK (window_seconds > 0),
+    action TEXT NOT NULL DEFAULT 'defer' CHECK (action IN ('defer', 'drop')),
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, channel)
+);
+
+INSERT INTO frequency_caps_old (tenant_id, channel, max_count, window_seconds, action, created_at, updated_at)
+SELECT tenant_id, channel, max_count, window_seconds, action, created_at, updated_at FROM frequency_caps WHERE category = '';
+
+DROP TABLE frequency_caps;
+ALTER TABLE frequency_caps_old RENAME TO frequency_caps;
+
+CREATE TRIGGER IF NOT EXISTS frequency_caps_updated_at
+AFTER UPDATE ON frequency_caps
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE frequency_caps SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id AND channel = NEW.channel;
+END;
+
+DROP INDEX IF EXISTS idx_notifications_category;
+ALTER TABLE notifications DROP COLUMN category;
+DROP TABLE IF EXISTS category_opt_outs;
+DROP TABLE IF EXISTS notification_categories;
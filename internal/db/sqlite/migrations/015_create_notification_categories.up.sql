@@ -0,0 +1,68 @@
+-- Notification categories (Postgres 040).
+CREATE TABLE IF NOT EXISTS notification_categories (
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    quiet_hours_start TEXT,
+    quiet_hours_end TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, name),
+    CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE TRIGGER IF NOT EXISTS notification_categories_updated_at
+AFTER UPDATE ON notification_categories
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE notification_categories SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id AND name = NEW.name;
+END;
+
+CREATE TABLE IF NOT EXISTS category_opt_outs (
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    channel TEXT NOT NULL DEFAULT '',
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, user_id, category, channel),
+    FOREIGN KEY (tenant_id, category) REFERENCES notification_categories (tenant_id, name) ON DELETE CASCADE
+);
+
+ALTER TABLE notifications ADD COLUMN category TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_category
+    ON notifications (tenant_id, category, created_at)
+    WHERE category IS NOT NULL;
+
+-- SQLite can't change a primary key, so frequency_caps is rebuilt with
+-- category in it. Nothing references the table.
+CREATE TABLE frequency_caps_new (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    max_count INTEGER NOT NULL CHECK (max_count > 0),
+    window_seconds INTEGER NOT NULL CHECK (window_seconds > 0),
+    action TEXT NOT NULL DEFAULT 'defer' CHECK (action IN ('defer', 'drop')),
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, channel, category)
+);
+
+INSERT INTO frequency_caps_new (tenant_id, channel, max_count, window_seconds, action, created_at, updated_at)
+SELECT tenant_id, channel, max_count, window_seconds, action, created_at, updated_at FROM frequency_caps;
+
+DROP TABLE frequency_caps;
+ALTER TABLE frequency_caps_new RENAME TO frequency_caps;
+
+CREATE TRIGGER IF NOT EXISTS frequency_caps_updated_at
+AFTER UPDATE ON frequency_caps
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE frequency_caps SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE tenant_id = NEW.tenant_id AND channel = NEW.channel AND category = NEW.category;
+END;
@@ -0,0 +1 @@
+ALTER TABLE notification_categories DROP COLUMN mandatory;
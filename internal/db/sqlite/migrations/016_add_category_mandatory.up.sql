@@ -0,0 +1,2 @@
+-- Mandatory notification categories (Postgres 041).
+ALTER TABLE notification_categories ADD COLUMN mandatory BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,228 @@
+-- Slack channel (Postgres 042).
+--
+-- SQLite can't alter a CHECK constraint, so every table that checks its
+-- channel is rebuilt to allow 'slack'. As in 014, the rows of the tables
+-- that reference notifications are copied aside and put back, since dropping
+-- notifications inside the migration's transaction cascades to them.
+CREATE TEMP TABLE notification_edits_copy AS SELECT * FROM notification_edits;
+CREATE TEMP TABLE notification_events_copy AS SELECT * FROM notification_events;
+CREATE TEMP TABLE notification_annotations_copy AS SELECT * FROM notification_annotations;
+
+CREATE TABLE notifications_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook', 'slack')),
+    payload JSON NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held', 'frequency_capped')),
+    attempt INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    next_retry_at DATETIME,
+
+    correlation_id TEXT NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT '{}',
+    tags JSON NOT NULL DEFAULT '[]',
+
+    provider TEXT,
+    provider_message_id TEXT,
+    cost REAL,
+    archive_key TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    sla_seconds INTEGER,
+    sla_breached INTEGER,
+    group_key TEXT,
+    collapsed_into TEXT,
+    category TEXT
+);
+
+INSERT INTO notifications_new SELECT * FROM notifications;
+DROP TABLE notifications;
+ALTER TABLE notifications_new RENAME TO notifications;
+
+INSERT INTO notification_edits SELECT * FROM notification_edits_copy;
+INSERT INTO notification_events SELECT * FROM notification_events_copy;
+INSERT INTO notification_annotations SELECT * FROM notification_annotations_copy;
+DROP TABLE notification_edits_copy;
+DROP TABLE notification_events_copy;
+DROP TABLE notification_annotations_copy;
+
+CREATE INDEX idx_notifications_retry ON notifications (next_retry_at, created_at) WHERE status = 'pending';
+CREATE INDEX idx_notifications_processing ON notifications (updated_at) WHERE status = 'processing';
+CREATE INDEX idx_notifications_tenant ON notifications (tenant_id, created_at);
+CREATE INDEX idx_notifications_user ON notifications (tenant_id, user_id, created_at);
+CREATE INDEX idx_notifications_channel ON notifications (channel, status);
+CREATE INDEX idx_notifications_provider_message_id ON notifications (provider_message_id) WHERE provider_message_id IS NOT NULL;
+CREATE INDEX idx_notifications_finished ON notifications (updated_at) WHERE status IN ('sent', 'dead_lettered');
+CREATE INDEX idx_notifications_user_global ON notifications (user_id, created_at);
+CREATE INDEX idx_notifications_group
+    ON notifications (tenant_id, user_id, channel, group_key, created_at)
+    WHERE group_key IS NOT NULL;
+CREATE INDEX idx_notifications_tenant_updated ON notifications (tenant_id, updated_at);
+CREATE INDEX idx_notifications_tenant_status ON notifications (tenant_id, status, created_at);
+CREATE INDEX idx_notifications_user_sent ON notifications (tenant_id, user_id, channel, updated_at) WHERE status = 'sent';
+CREATE INDEX idx_notifications_category
+    ON notifications (tenant_id, category, created_at)
+    WHERE category IS NOT NULL;
+
+CREATE TRIGGER record_notification_created
+AFTER INSERT ON notifications
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id, NULL, NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN (SELECT tenant_id FROM session_tenant) IS NULL THEN 'system' ELSE 'tenant' END
+    );
+END;
+
+CREATE TRIGGER record_notification_transition
+AFTER UPDATE OF status, attempt ON notifications
+WHEN OLD.status IS NOT NEW.status OR OLD.attempt IS NOT NEW.attempt
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id, OLD.status, NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN (SELECT tenant_id FROM session_tenant) IS NULL THEN 'system' ELSE 'tenant' END
+    );
+END;
+
+CREATE TRIGGER notifications_updated_at
+AFTER UPDATE ON notifications
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE notifications SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TABLE dead_letter_notifications_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    original_notification_id TEXT NOT NULL,
+
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook', 'slack')),
+    payload JSON NOT NULL,
+    correlation_id TEXT NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT '{}',
+    tags JSON NOT NULL DEFAULT '[]',
+
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'retried', 'discarded')),
+    retried_notification_id TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    reason TEXT NOT NULL DEFAULT 'unknown'
+        CHECK (reason IN ('invalid_recipient', 'provider_outage', 'timeout', 'payload_error', 'unknown')),
+    retry_count INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO dead_letter_notifications_new SELECT * FROM dead_letter_notifications;
+DROP TABLE dead_letter_notifications;
+ALTER TABLE dead_letter_notifications_new RENAME TO dead_letter_notifications;
+
+CREATE INDEX idx_dlq_tenant ON dead_letter_notifications (tenant_id, created_at);
+CREATE INDEX idx_dlq_resolved ON dead_letter_notifications (updated_at) WHERE status IN ('retried', 'discarded');
+CREATE INDEX idx_dlq_tenant_reason ON dead_letter_notifications (tenant_id, reason, created_at);
+CREATE INDEX idx_dlq_tenant_updated ON dead_letter_notifications (tenant_id, updated_at);
+CREATE INDEX idx_dlq_tenant_status ON dead_letter_notifications (tenant_id, status, created_at);
+
+CREATE TRIGGER dead_letter_notifications_updated_at
+AFTER UPDATE ON dead_letter_notifications
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE dead_letter_notifications SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TABLE captured_deliveries_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook', 'slack')),
+    recipient TEXT NOT NULL,
+    payload JSON NOT NULL,
+
+    captured_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+INSERT INTO captured_deliveries_new SELECT * FROM captured_deliveries;
+DROP TABLE captured_deliveries;
+ALTER TABLE captured_deliveries_new RENAME TO captured_deliveries;
+
+CREATE INDEX idx_captured_deliveries_tenant ON captured_deliveries (tenant_id, captured_at);
+
+CREATE TABLE tenant_channel_settings_new (
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook', 'slack')),
+    settings JSON NOT NULL DEFAULT '{}',
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+
+    PRIMARY KEY (tenant_id, channel)
+);
+
+INSERT INTO tenant_channel_settings_new SELECT * FROM tenant_channel_settings;
+DROP TABLE tenant_channel_settings;
+ALTER TABLE tenant_channel_settings_new RENAME TO tenant_channel_settings;
+
+CREATE TRIGGER tenant_channel_settings_updated_at
+AFTER UPDATE ON tenant_channel_settings
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tenant_channel_settings SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE tenant_id = NEW.tenant_id AND channel = NEW.channel;
+END;
+
+CREATE TABLE channel_kill_switches_new (
+    channel TEXT NOT NULL PRIMARY KEY CHECK (channel IN ('email', 'sms', 'webhook', 'slack')),
+    reason TEXT NOT NULL DEFAULT '',
+
+    killed_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+INSERT INTO channel_kill_switches_new SELECT * FROM channel_kill_switches;
+DROP TABLE channel_kill_switches;
+ALTER TABLE channel_kill_switches_new RENAME TO channel_kill_switches;
+
+CREATE TABLE notification_imports_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'webhook', 'slack')),
+    format TEXT NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    source TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    row_errors TEXT NOT NULL DEFAULT '[]',
+    error TEXT,
+
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    finished_at DATETIME
+);
+
+INSERT INTO notification_imports_new SELECT * FROM notification_imports;
+DROP TABLE notification_imports;
+ALTER TABLE notification_imports_new RENAME TO notification_imports;
+
+CREATE INDEX idx_notification_imports_tenant ON notification_imports (tenant_id, created_at);
+
+CREATE TRIGGER notification_imports_updated_at
+AFTER UPDATE ON notification_imports
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE notification_imports SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
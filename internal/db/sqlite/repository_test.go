@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func newTestRepository(t *testing.T) (*Repository, *DB) {
+	t.Helper()
+	database, err := New(context.Background(), ":memory:", zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(database.Close)
+	return NewRepository(database, zap.NewNop()), database
+}
+
+func newTestNotification(tenantID uuid.UUID) *db.Notification {
+	return &db.Notification{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+		UserID:   uuid.New(),
+		Channel:  db.ChannelEmail,
+		Payload:  json.RawMessage(`{"to":"user@example.com","subject":"Hi","body":"Hello"}`),
+		Status:   db.StatusPending,
+	}
+}
+
+func TestNew_AppliesMigrations(t *testing.T) {
+	_, database := newTestRepository(t)
+	ctx := context.Background()
+
+	names, err := fs.Glob(migrations, "migrations/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied int
+	if err := database.SQL().QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("failed to count migrations: %v", err)
+	}
+	if applied != len(names) {
+		t.Errorf("expected %d migrations applied, got %d", len(names), applied)
+	}
+
+	// A second run finds nothing left to apply.
+	if err := database.migrate(ctx); err != nil {
+		t.Errorf("expected migrating again to be a no-op, got %v", err)
+	}
+}
+
+func TestRepository_CreateAndGetNotification(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	notif := newTestNotification(tenantID)
+	notif.Tags = []string{"welcome"}
+	if err := repo.CreateNotification(ctx, notif); err != nil {
+		t.Fatalf("failed to create notification: %v", err)
+	}
+
+	got, err := repo.GetNotificationForTenant(ctx, tenantID, notif.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusPending || got.Channel != db.ChannelEmail || !got.CreatedAt.Equal(notif.CreatedAt) {
+		t.Errorf("unexpected notification: %+v", got)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "welcome" {
+		t.Errorf("expected tags [welcome], got %v", got.Tags)
+	}
+
+	if _, err := repo.GetNotificationForTenant(ctx, uuid.New(), notif.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected another tenant's lookup to be not found, got %v", err)
+	}
+	if _, err := repo.GetNotification(ctx, uuid.New()); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected a missing notification to be not found, got %v", err)
+	}
+}
+
+func TestRepository_ClaimAndUpdate(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	first := newTestNotification(tenantID)
+	second := newTestNotification(tenantID)
+	for _, n := range []*db.Notification{first, second} {
+		if err := repo.CreateNotification(ctx, n); err != nil {
+			t.Fatalf("failed to create notification: %v", err)
+		}
+	}
+
+	claimed, err := repo.ClaimPendingNotifications(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("expected 2 claimed, got %d", len(claimed))
+	}
+	for _, n := range claimed {
+		if n.Status != db.StatusProcessing {
+			t.Errorf("expected claimed rows to be processing, got %s", n.Status)
+		}
+	}
+	if again, err := repo.ClaimPendingNotifications(ctx, 10); err != nil || len(again) != 0 {
+		t.Fatalf("expected nothing left to claim, got %d (%v)", len(again), err)
+	}
+
+	// A failed attempt goes back to pending, but isn't claimable before its
+	// retry time.
+	errMsg := "mailbox full"
+	retryAt := time.Now().Add(time.Hour)
+	if err := repo.UpdateNotificationStatus(ctx, first.ID, db.StatusPending, 1, &errMsg, &retryAt); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	if again, err := repo.ClaimPendingNotifications(ctx, 10); err != nil || len(again) != 0 {
+		t.Fatalf("expected a retry not yet due to stay unclaimed, got %d (%v)", len(again), err)
+	}
+	got, err := repo.GetNotification(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusPending || got.Attempt != 1 || got.ErrorMessage == nil || *got.ErrorMessage != errMsg {
+		t.Errorf("unexpected notification after the failed attempt: %+v", got)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if err := repo.UpdateNotificationStatus(ctx, first.ID, db.StatusPending, 1, &errMsg, &past); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	if again, err := repo.ClaimPendingNotifications(ctx, 10); err != nil || len(again) != 1 || again[0].ID != first.ID {
+		t.Fatalf("expected the due retry to be claimed, got %v (%v)", again, err)
+	}
+
+	if err := repo.MarkNotificationSent(ctx, second.ID, 1, "ses", "msg-1", "", 0.0001); err != nil {
+		t.Fatalf("failed to mark sent: %v", err)
+	}
+	got, err = repo.GetNotification(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusSent || got.Attempt != 1 || got.Provider != "ses" || got.ProviderMessageID != "msg-1" {
+		t.Errorf("unexpected notification after the send: %+v", got)
+	}
+
+	if err := repo.UpdateNotificationStatus(ctx, uuid.New(), db.StatusSent, 1, nil, nil); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected updating a missing notification to be not found, got %v", err)
+	}
+}
+
+func TestRepository_TenantScopedStatusUpdates(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	tenantID, otherTenant := uuid.New(), uuid.New()
+
+	notif := newTestNotification(tenantID)
+	if err := repo.CreateNotification(ctx, notif); err != nil {
+		t.Fatalf("failed to create notification: %v", err)
+	}
+	if err := repo.MarkNotificationSent(ctx, notif.ID, 1, "ses", "msg-1", "", 0); err != nil {
+		t.Fatalf("failed to mark sent: %v", err)
+	}
+
+	if _, err := repo.GetNotificationByProviderMessageIDForTenant(ctx, otherTenant, "ses", "msg-1"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected another tenant's provider lookup to be not found, got %v", err)
+	}
+	if got, err := repo.GetNotificationByProviderMessageIDForTenant(ctx, tenantID, "ses", "msg-1"); err != nil || got.ID != notif.ID {
+		t.Errorf("expected the owning tenant to find the notification, got %v (%v)", got, err)
+	}
+
+	if err := repo.UpdateNotificationStatusForTenant(ctx, otherTenant, notif.ID, db.StatusFailed, 2, nil, nil); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected another tenant's update to be not found, got %v", err)
+	}
+	updated, err := repo.UpdateNotificationStatusesForTenant(ctx, otherTenant, []db.StatusUpdate{{ID: notif.ID, Status: db.StatusFailed, Attempt: 2}})
+	if err != nil || len(updated) != 0 {
+		t.Errorf("expected another tenant's batch to update nothing, got %v (%v)", updated, err)
+	}
+	got, err := repo.GetNotification(ctx, notif.ID)
+	if err != nil {
+		t.Fatalf("failed to get notification: %v", err)
+	}
+	if got.Status != db.StatusSent {
+		t.Errorf("expected the notification untouched, got %s", got.Status)
+	}
+
+	updated, err = repo.UpdateNotificationStatusesForTenant(ctx, tenantID, []db.StatusUpdate{{ID: notif.ID, Status: db.StatusFailed, Attempt: 2}})
+	if err != nil || len(updated) != 1 {
+		t.Errorf("expected the owning tenant's batch to update the notification, got %v (%v)", updated, err)
+	}
+	if err := repo.UpdateNotificationStatusForTenant(ctx, tenantID, notif.ID, db.StatusSent, 3, nil, nil); err != nil {
+		t.Errorf("expected the owning tenant's update to succeed, got %v", err)
+	}
+}
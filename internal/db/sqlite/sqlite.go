@@ -0,0 +1,152 @@
+// Package sqlite is the SQLite implementation of db.Store, for running the
+// gateway on a laptop with no database server (DB_DRIVER=sqlite). It is
+// pure Go, so it needs no cgo either.
+//
+// It is meant for local development, not production: everything goes
+// through a single connection, so the worker and the API take turns, and
+// there is no row level security or AI knowledge base. The schema lives in
+// the migrations directory next to this file and is applied by New.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+//go:embed migrations/*.up.sql
+var migrations embed.FS
+
+// DB wraps the database/sql handle
+type DB struct {
+	sql    *sql.DB
+	logger *zap.Logger
+}
+
+// New opens (creating it if needed) the SQLite database at path and brings
+// its schema up to date. ":memory:" gives a throwaway in-memory database.
+func New(ctx context.Context, path string, logger *zap.Logger) (*DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+
+	handle, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	// SQLite allows one writer at a time. A single connection serializes
+	// the gateway's writes instead of failing them with SQLITE_BUSY, and
+	// keeps an in-memory database alive for the life of the process.
+	handle.SetMaxOpenConns(1)
+	handle.SetMaxIdleConns(1)
+	handle.SetConnMaxLifetime(0)
+	handle.SetConnMaxIdleTime(0)
+
+	if err := handle.PingContext(ctx); err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+
+	d := &DB{
+		sql:    handle,
+		logger: logger,
+	}
+	if err := d.migrate(ctx); err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("migrate database: %w", err)
+	}
+
+	logger.Info("database connection established",
+		zap.String("driver", "sqlite"),
+		zap.String("path", path),
+	)
+
+	return d, nil
+}
+
+// migrate applies the embedded migrations that schema_migrations doesn't
+// list yet, each in its own transaction, the way cmd/migrator does for
+// Postgres and MySQL.
+func (d *DB) migrate(ctx context.Context) error {
+	if _, err := d.sql.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT PRIMARY KEY,
+			applied_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+		)
+	`); err != nil {
+		return fmt.Errorf("ensure schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, path := range names {
+		name := strings.TrimPrefix(path, "migrations/")
+
+		var applied bool
+		if err := d.sql.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = ?)`, name).Scan(&applied); err != nil {
+			return fmt.Errorf("check applied %s: %w", name, err)
+		}
+		if applied {
+			continue
+		}
+
+		contents, err := migrations.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+
+		tx, err := d.sql.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, string(contents)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("execute %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (name) VALUES (?)`, name); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("mark applied %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit %s: %w", name, err)
+		}
+
+		d.logger.Info("applied migration", zap.String("name", name))
+	}
+
+	return nil
+}
+
+// Close closes the database
+func (d *DB) Close() {
+	d.logger.Info("closing database")
+	if err := d.sql.Close(); err != nil {
+		d.logger.Warn("failed to close database", zap.Error(err))
+	}
+}
+
+// SQL returns the underlying database handle
+func (d *DB) SQL() *sql.DB {
+	return d.sql
+}
+
+// Health checks if the database is reachable
+func (d *DB) Health(ctx context.Context) error {
+	return d.sql.PingContext(ctx)
+}
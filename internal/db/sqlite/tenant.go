@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// resetTimeout bounds clearing the session tenant on release.
+const resetTimeout = 5 * time.Second
+
+// session is what queries run on: the database handle, or its connection
+// held for a tenant.
+type session interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// session returns where to run writes to notifications for ctx. For a
+// tenant-scoped context (db.WithTenant) it holds the connection with the
+// tenant stored in session_tenant, which the notification_events triggers
+// read to record the tenant as the actor; release clears it. Nothing else
+// can use the database until release, so session must not be held while
+// running queries on d.sql.
+func (d *DB) session(ctx context.Context) (s session, release func(), err error) {
+	tenantID, ok := db.TenantFromContext(ctx)
+	if !ok {
+		return d.sql, func() {}, nil
+	}
+
+	conn, err := d.sql.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, `UPDATE session_tenant SET tenant_id = ?`, tenantID); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return conn, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+		defer cancel()
+		if _, err := conn.ExecContext(ctx, `UPDATE session_tenant SET tenant_id = NULL`); err != nil {
+			d.logger.Warn("failed to clear session tenant, closing connection", zap.Error(err))
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		_ = conn.Close()
+	}, nil
+}
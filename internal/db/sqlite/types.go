@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gosqlite "modernc.org/sqlite"
+)
+
+// SQLite extended result codes for unique key failures.
+const (
+	errConstraintPrimaryKey = 1555
+	errConstraintUnique     = 2067
+)
+
+// timeLayout is how timestamps are stored: UTC, fixed width, so that
+// comparing them as strings compares them as times. It matches
+// strftime('%Y-%m-%d %H:%M:%f', 'now') (sqlNow), which the schema's
+// defaults use.
+const timeLayout = "2006-01-02 15:04:05.000"
+
+// sqlNow is the current time in timeLayout.
+const sqlNow = `strftime('%Y-%m-%d %H:%M:%f', 'now')`
+
+// formatTime formats t for a DATETIME column.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// formatNullTime formats t for a nullable DATETIME column.
+func formatNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// timestamp scans a DATETIME column into a time.Time. The driver already
+// parses plain columns, but not expressions or RETURNING values.
+type timestamp struct{ t *time.Time }
+
+// Scan implements sql.Scanner.
+func (s timestamp) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*s.t = v.UTC()
+		return nil
+	case string:
+		return parseTime(v, s.t)
+	case []byte:
+		return parseTime(string(v), s.t)
+	default:
+		return fmt.Errorf("cannot scan %T into a time", src)
+	}
+}
+
+// nullTimestamp is timestamp for a nullable column.
+type nullTimestamp struct{ t **time.Time }
+
+// Scan implements sql.Scanner.
+func (s nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*s.t = nil
+		return nil
+	}
+	var t time.Time
+	if err := (timestamp{&t}).Scan(src); err != nil {
+		return err
+	}
+	*s.t = &t
+	return nil
+}
+
+func parseTime(s string, t *time.Time) error {
+	for _, layout := range []string{timeLayout, time.DateOnly, time.RFC3339Nano} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = parsed.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a time", s)
+}
+
+// stringList stores a []string in a JSON array column, SQLite's stand-in
+// for the TEXT[] columns on Postgres. nil is stored as [].
+type stringList []string
+
+// Value implements driver.Valuer.
+func (l stringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *stringList) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	default:
+		return fmt.Errorf("cannot scan %T into a string list", src)
+	}
+}
+
+// jsonOrEmpty maps an absent JSON value to '{}' for the NOT NULL JSON
+// columns. JSON is stored as text, so it is sent as a string.
+func jsonOrEmpty(v json.RawMessage) string {
+	if len(v) == 0 {
+		return "{}"
+	}
+	return string(v)
+}
+
+// isDuplicateEntry reports whether err is a unique key violation.
+func isDuplicateEntry(err error) bool {
+	var sqliteErr *gosqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == errConstraintUnique || code == errConstraintPrimaryKey
+}
+
+// placeholders returns n comma-separated ? placeholders, for IN lists.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
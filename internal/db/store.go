@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store is everything the gateway persists. Repository implements it on
+// Postgres and mysql.Repository on MySQL. Packages that use storage declare
+// the narrow slice of it they need; only main wires up a whole Store.
+type Store interface {
+	// Notifications
+	CreateNotification(ctx context.Context, notif *Notification) error
+	GetNotification(ctx context.Context, id uuid.UUID) (*Notification, error)
+	GetNotificationForTenant(ctx context.Context, tenantID, id uuid.UUID) (*Notification, error)
+	UpdateNotificationStatus(ctx context.Context, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error
+	UpdateNotificationStatusForTenant(ctx context.Context, tenantID, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error
+	EditPendingNotification(ctx context.Context, id uuid.UUID, edit NotificationEdit) (*Notification, error)
+	CancelPendingNotification(ctx context.Context, id uuid.UUID) error
+	UpdateNotificationStatuses(ctx context.Context, updates []StatusUpdate) ([]uuid.UUID, error)
+	UpdateNotificationStatusesForTenant(ctx context.Context, tenantID uuid.UUID, updates []StatusUpdate) ([]uuid.UUID, error)
+	MarkNotificationSent(ctx context.Context, id uuid.UUID, attempt int, provider, providerMessageID, archiveKey string, cost float64) error
+	CollapseNotification(ctx context.Context, notif *Notification, attempt int, window time.Duration) (*uuid.UUID, error)
+	GetNotificationByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*Notification, error)
+	GetNotificationByProviderMessageIDForTenant(ctx context.Context, tenantID uuid.UUID, provider, providerMessageID string) (*Notification, error)
+	ListNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter NotificationFilter, limit int, offset int) ([]*Notification, error)
+	CountNotificationsByTenant(ctx context.Context, tenantID uuid.UUID, filter NotificationFilter) (int64, error)
+	SearchNotificationsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*Notification, error)
+	ListNotificationEvents(ctx context.Context, notificationID uuid.UUID) ([]*NotificationEvent, error)
+	AddNotificationAnnotation(ctx context.Context, a *NotificationAnnotation) error
+	AnnotateByProviderMessageID(ctx context.Context, providerMessageID string, a *NotificationAnnotation) (bool, error)
+	ListNotificationAnnotations(ctx context.Context, notificationID uuid.UUID) ([]*NotificationAnnotation, error)
+	GetPendingNotifications(ctx context.Context, limit int) ([]*Notification, error)
+	ClaimPendingNotifications(ctx context.Context, limit int) ([]*Notification, error)
+	ClaimNotification(ctx context.Context, id uuid.UUID, channel string) (*Notification, error)
+	ClaimStuckNotifications(ctx context.Context, olderThan time.Duration, limit int) ([]*Notification, error)
+	DeleteFinishedNotifications(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	RequeueNotifications(ctx context.Context, filter RequeueFilter) (int64, error)
+	GetQueueOverview(ctx context.Context, since time.Time, topTenants int) (*QueueOverview, error)
+	GetBacklog(ctx context.Context) (*Backlog, error)
+
+	// Dead letter queue
+	MoveToDeadLetter(ctx context.Context, notif *Notification, reason, lastError string) (*DeadLetterNotification, error)
+	ListDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter DeadLetterFilter, limit, offset int) ([]*DeadLetterNotification, error)
+	CountDeadLetterByTenant(ctx context.Context, tenantID uuid.UUID, filter DeadLetterFilter) (int64, error)
+	GetDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetterNotification, error)
+	GetDeadLetterForTenant(ctx context.Context, tenantID, id uuid.UUID) (*DeadLetterNotification, error)
+	RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*Notification, error)
+	DiscardDeadLetter(ctx context.Context, dlqID uuid.UUID) error
+	PurgeResolvedDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	ListDLQRetryPolicies(ctx context.Context, tenantID uuid.UUID) ([]*DLQRetryPolicy, error)
+	UpsertDLQRetryPolicy(ctx context.Context, p *DLQRetryPolicy) error
+	DeleteDLQRetryPolicy(ctx context.Context, tenantID uuid.UUID, reason string) error
+	ListDueDeadLetters(ctx context.Context, limit int) ([]uuid.UUID, error)
+
+	// Sandbox captures
+	CaptureDelivery(ctx context.Context, delivery *CapturedDelivery) error
+	ListCapturedDeliveries(ctx context.Context, tenantID uuid.UUID, channel string, limit int) ([]*CapturedDelivery, error)
+	ClearCapturedDeliveries(ctx context.Context, tenantID uuid.UUID) (int64, error)
+
+	// Templates and link tracking
+	CreateTemplate(ctx context.Context, tmpl *Template) error
+	GetTemplate(ctx context.Context, id uuid.UUID) (*Template, error)
+	PublishTemplate(ctx context.Context, id uuid.UUID, html string) (*Template, error)
+	CreateShortLink(ctx context.Context, link *ShortLink) error
+	GetShortLink(ctx context.Context, code string) (*ShortLink, error)
+	RecordLinkClick(ctx context.Context, click *LinkClick) error
+
+	// Tenant settings
+	GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*TenantChannelSettings, error)
+	UpsertChannelSettings(ctx context.Context, s *TenantChannelSettings) error
+	GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*TenantSettings, error)
+	UpsertTenantSettings(ctx context.Context, s *TenantSettings) error
+	GetIPAllowlist(ctx context.Context, tenantID uuid.UUID) (*TenantIPAllowlist, error)
+	UpsertIPAllowlist(ctx context.Context, a *TenantIPAllowlist) error
+	GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*TenantRateLimit, error)
+	UpsertTenantRateLimit(ctx context.Context, l *TenantRateLimit) error
+	DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error
+	ListTenantRateLimits(ctx context.Context) ([]*TenantRateLimit, error)
+
+	// Bulk imports
+	CreateImport(ctx context.Context, imp *NotificationImport) error
+	GetImport(ctx context.Context, id uuid.UUID) (*NotificationImport, error)
+	UpdateImport(ctx context.Context, imp *NotificationImport) error
+
+	// API keys
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*APIKey, error)
+	RollAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, replacement *APIKey) error
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+
+	// Background jobs
+	AcquireJob(ctx context.Context, name, owner string, minGap, ttl time.Duration) (bool, error)
+	FinishJob(ctx context.Context, name, owner string, took time.Duration, runErr error) error
+	ListJobRuns(ctx context.Context) ([]*JobRun, error)
+
+	// Delivery controls
+	PauseTenant(ctx context.Context, p *TenantPause) error
+	ResumeTenant(ctx context.Context, tenantID uuid.UUID) error
+	ListTenantPauses(ctx context.Context) ([]*TenantPause, error)
+	KillChannel(ctx context.Context, k *ChannelKillSwitch) error
+	ReviveChannel(ctx context.Context, channel string) error
+	ListChannelKillSwitches(ctx context.Context) ([]*ChannelKillSwitch, error)
+	SyncChannelHolds(ctx context.Context) (held, released int64, err error)
+
+	// Reputation and warm-up
+	InsertDeliveryEvents(ctx context.Context, events []*DeliveryEvent) (int64, error)
+	GetEmailReputation(ctx context.Context, since time.Time) ([]*TenantReputation, error)
+	SetTenantSendLimit(ctx context.Context, l *TenantSendLimit) error
+	LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error
+	ListTenantSendLimits(ctx context.Context) ([]*TenantSendLimit, error)
+	ReserveWarmupSend(ctx context.Context, tenantID uuid.UUID, schedule []int) (allowed, graduated bool, err error)
+	GetEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*EmailWarmup, error)
+	EndEmailWarmup(ctx context.Context, tenantID uuid.UUID) (*EmailWarmup, error)
+
+	// Recipients and their hygiene
+	InvalidateRecipient(ctx context.Context, r *InvalidRecipient) error
+	IsRecipientInvalid(ctx context.Context, tenantID uuid.UUID, channel, recipient string) (bool, error)
+	ListInvalidRecipients(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]*InvalidRecipient, error)
+	ReinstateRecipient(ctx context.Context, tenantID uuid.UUID, channel, recipient string) error
+	GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*Recipient, error)
+	UpsertRecipient(ctx context.Context, r *Recipient) error
+	DeleteRecipient(ctx context.Context, tenantID, userID uuid.UUID) error
+
+	// Usage and budgets
+	ListTenantUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*DailyUsage, error)
+	GetTenantBudget(ctx context.Context, tenantID uuid.UUID) (*TenantBudget, error)
+	UpsertTenantBudget(ctx context.Context, b *TenantBudget) error
+	DeleteTenantBudget(ctx context.Context, tenantID uuid.UUID) error
+	ListBudgetUsage(ctx context.Context) ([]*BudgetUsage, error)
+	RecordBudgetAlert(ctx context.Context, tenantID uuid.UUID, percent int, alert *Notification) (bool, error)
+
+	// Frequency caps
+	ListFrequencyCaps(ctx context.Context) ([]*FrequencyCap, error)
+	ListTenantFrequencyCaps(ctx context.Context, tenantID uuid.UUID) ([]*FrequencyCap, error)
+	UpsertFrequencyCap(ctx context.Context, c *FrequencyCap) error
+	DeleteFrequencyCap(ctx context.Context, tenantID uuid.UUID, channel, category string) error
+	CountUserSends(ctx context.Context, tenantID, userID uuid.UUID, channel, category string, since time.Time) (count int, oldest *time.Time, err error)
+
+	// Categories and preferences
+	ListNotificationCategories(ctx context.Context) ([]*NotificationCategory, error)
+	ListTenantNotificationCategories(ctx context.Context, tenantID uuid.UUID) ([]*NotificationCategory, error)
+	GetNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) (*NotificationCategory, error)
+	UpsertNotificationCategory(ctx context.Context, c *NotificationCategory) error
+	DeleteNotificationCategory(ctx context.Context, tenantID uuid.UUID, name string) error
+	ListCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID) ([]*CategoryOptOut, error)
+	ReplaceCategoryOptOuts(ctx context.Context, tenantID, userID uuid.UUID, optOuts []*CategoryOptOut) error
+	IsOptedOut(ctx context.Context, tenantID, userID uuid.UUID, category, channel string) (bool, error)
+	ListCategoryStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*CategoryStats, error)
+}
+
+var _ Store = (*Repository)(nil)
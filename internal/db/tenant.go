@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// tenantSetting is the Postgres setting the row-level security policies
+// on notifications and dead_letter_notifications read.
+const tenantSetting = "nimbus.tenant_id"
+
+type tenantContextKey struct{}
+
+// WithTenant scopes database work done with ctx to tenantID: connections
+// acquired with it have nimbus.tenant_id set, so row-level security hides
+// other tenants' rows even if a query forgets to filter on tenant_id. Set
+// it from the authenticated tenant only, never from request input.
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set by WithTenant.
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(uuid.UUID)
+	return tenantID, ok
+}
+
+// tenantScoper sets nimbus.tenant_id on connections as they are acquired
+// for a tenant-scoped context, and clears it when they go back to the
+// pool. Connections used without a tenant never pay the extra round trip.
+type tenantScoper struct {
+	logger *zap.Logger
+	scoped sync.Map // *pgx.Conn → struct{}
+}
+
+// beforeAcquire fits pgxpool.Config.BeforeAcquire. Returning false
+// discards the connection rather than handing it out unscoped.
+func (s *tenantScoper) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return true
+	}
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", tenantSetting, tenantID.String()); err != nil {
+		s.logger.Warn("failed to scope connection to tenant, discarding it", zap.Error(err))
+		return false
+	}
+	s.scoped.Store(conn, struct{}{})
+	return true
+}
+
+// afterRelease fits pgxpool.Config.AfterRelease. Returning false closes
+// a connection that can't be cleared, so it never leaks a tenant scope.
+func (s *tenantScoper) afterRelease(conn *pgx.Conn) bool {
+	if _, ok := s.scoped.LoadAndDelete(conn); !ok {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+	defer cancel()
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, '', false)", tenantSetting); err != nil {
+		s.logger.Warn("failed to clear connection tenant scope, closing it", zap.Error(err))
+		return false
+	}
+	return true
+}
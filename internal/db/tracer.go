@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// queryTracer times every query on the pool: each one lands in the
+// nimbus_db_query_duration_seconds histogram under its queryName, and
+// queries slower than slowThreshold are logged. Argument values are never
+// logged, only their types, since they carry recipients and payloads.
+type queryTracer struct {
+	logger        *zap.Logger
+	slowThreshold time.Duration // 0 disables the slow query log
+}
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	sql   string
+	args  []any
+	start time.Time
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, &queryTrace{sql: data.SQL, args: data.Args, start: time.Now()})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(trace.start)
+	name := queryName(trace.sql)
+
+	outcome := "ok"
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		outcome = "error"
+	}
+	metrics.RecordDBQuery(name, outcome, elapsed)
+
+	if t.slowThreshold > 0 && elapsed >= t.slowThreshold {
+		t.logger.Warn("slow query",
+			zap.String("query", name),
+			zap.Duration("duration", elapsed),
+			zap.String("sql", compactSQL(trace.sql)),
+			zap.Strings("args", redactArgs(trace.args)),
+			zap.String("outcome", outcome),
+		)
+	}
+}
+
+// queryName labels a statement for metrics. A leading "-- name: X" comment
+// wins; otherwise it is the verb and the first table, e.g. "select
+// notifications", which keeps the label set bounded.
+func queryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name:"); ok {
+		name, _, _ := strings.Cut(rest, "\n")
+		return strings.TrimSpace(name)
+	}
+
+	fields := strings.Fields(strings.ToLower(sql))
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	verb := fields[0]
+	var marker string
+	switch verb {
+	case "select", "delete":
+		marker = "from"
+	case "insert":
+		marker = "into"
+	case "update":
+		if len(fields) > 1 {
+			return verb + " " + tableName(fields[1])
+		}
+		return verb
+	case "with":
+		// Naming a CTE needs a parser; give it a "-- name:" comment instead.
+		return verb
+	default:
+		return verb
+	}
+	for i, f := range fields[:len(fields)-1] {
+		if f == marker {
+			return verb + " " + tableName(fields[i+1])
+		}
+	}
+	return verb
+}
+
+func tableName(s string) string {
+	s, _, _ = strings.Cut(s, "(")
+	return strings.Trim(s, `";`)
+}
+
+// compactSQL collapses the indentation of multi-line statements onto one
+// line for the log.
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// redactArgs replaces argument values with their types.
+func redactArgs(args []any) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = fmt.Sprintf("$%d=%T", i+1, a)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := map[string]string{
+		"SELECT id, status FROM notifications WHERE id = $1":                  "select notifications",
+		"\n\t\tINSERT INTO notifications (id, tenant_id) VALUES ($1, $2)":     "insert notifications",
+		"UPDATE tenants SET paused = true WHERE id = $1":                      "update tenants",
+		"DELETE FROM dlq WHERE created_at < $1":                               "delete dlq",
+		"-- name: ClaimBatch\nUPDATE notifications SET status = 'processing'": "ClaimBatch",
+		"WITH due AS (SELECT id FROM notifications) UPDATE notifications":     "with",
+		"SELECT 1":               "select",
+		"SET app.tenant_id = $1": "set",
+	}
+	for sql, want := range tests {
+		if got := queryName(sql); got != want {
+			t.Errorf("queryName(%q) = %q, want %q", sql, got, want)
+		}
+	}
+}
+
+func TestQueryTracer_SlowQueryRedactsArgs(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	tracer := &queryTracer{logger: zap.New(core), slowThreshold: time.Nanosecond}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT *\n\tFROM notifications\n\tWHERE recipient = $1",
+		Args: []any{"alice@example.com"},
+	})
+	time.Sleep(time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected one slow query log, got %d", logs.Len())
+	}
+	fields := logs.All()[0].ContextMap()
+	if fields["sql"] != "SELECT * FROM notifications WHERE recipient = $1" || fields["outcome"] != "error" {
+		t.Errorf("unexpected log fields: %v", fields)
+	}
+	if args, _ := fields["args"].([]interface{}); len(args) != 1 || args[0] != "$1=string" {
+		t.Errorf("expected redacted args, got %v", fields["args"])
+	}
+}
+
+func TestQueryTracer_FastQueryNotLogged(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	tracer := &queryTracer{logger: zap.New(core), slowThreshold: time.Hour}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	if logs.Len() != 0 {
+		t.Errorf("expected no log, got %d", logs.Len())
+	}
+}
@@ -0,0 +1,173 @@
+// Package dlqexport writes a tenant's dead letters to S3 as JSON Lines and
+// hands back a presigned URL, so tenants can post-mortem failures in their
+// own tooling without API pagination.
+package dlqexport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/google/uuid"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// DefaultURLExpiry is how long a download URL works when Config doesn't
+// say.
+const DefaultURLExpiry = time.Hour
+
+// Config configures the export bucket.
+type Config struct {
+	Region string
+	Bucket string
+	// Prefix is prepended to every key, e.g. "dlq-exports/", so a bucket
+	// lifecycle rule can expire old exports.
+	Prefix string
+	// URLExpiry is how long the presigned download URL works. SigV4 caps
+	// it at 7 days.
+	URLExpiry time.Duration
+}
+
+// Export is a written export and where to download it.
+type Export struct {
+	Key       string    `json:"key"`
+	URL       string    `json:"url"`
+	Count     int       `json:"count"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// S3 writes exports to a bucket. Like the delivery archive, it signs
+// requests with the SDK's SigV4 signer rather than pulling in the full S3
+// client.
+type S3 struct {
+	cfg         Config
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// NewS3 creates an exporter for cfg.Bucket using the default AWS
+// credential chain.
+func NewS3(ctx context.Context, cfg Config) (*S3, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, cfg.Region)
+	return newS3(cfg, endpoint, awsCfg.Credentials), nil
+}
+
+func newS3(cfg Config, endpoint string, credentials aws.CredentialsProvider) *S3 {
+	if cfg.URLExpiry <= 0 {
+		cfg.URLExpiry = DefaultURLExpiry
+	}
+	return &S3{
+		cfg:         cfg,
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Key returns the object key for an export started at at:
+// <prefix><tenant_id>/<yyyy-mm-ddThhmmssZ>-<id>.jsonl.
+func (s *S3) Key(tenantID uuid.UUID, at time.Time) string {
+	return fmt.Sprintf("%s%s/%s-%s.jsonl", s.cfg.Prefix, tenantID, at.UTC().Format("2006-01-02T150405Z"), uuid.New())
+}
+
+// Export writes items as one JSON object per line and returns a presigned
+// GET URL for the object.
+func (s *S3) Export(ctx context.Context, tenantID uuid.UUID, items []*db.DeadLetterNotification) (*Export, error) {
+	// Payloads are exported as stored, without <, > and & rewritten.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return nil, fmt.Errorf("marshal dead letter %s: %w", item.ID, err)
+		}
+	}
+
+	now := time.Now()
+	key := s.Key(tenantID, now)
+	if err := s.put(ctx, key, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	u, err := s.presign(ctx, key, now)
+	if err != nil {
+		return nil, err
+	}
+	return &Export{Key: key, URL: u, Count: len(items), ExpiresAt: now.Add(s.cfg.URLExpiry).UTC()}, nil
+}
+
+func (s *S3) objectURL(key string) string {
+	return s.endpoint + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+func (s *S3) put(ctx context.Context, key string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build export request: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Server-Side-Encryption", "AES256")
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieve aws credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.cfg.Region, time.Now()); err != nil {
+		return fmt.Errorf("sign export request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("put export object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("put export object: status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// presign returns a GET URL for key that works for URLExpiry from at.
+func (s *S3) presign(ctx context.Context, key string, at time.Time) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return "", fmt.Errorf("build presign request: %w", err)
+	}
+	query := req.URL.Query()
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(s.cfg.URLExpiry/time.Second), 10))
+	req.URL.RawQuery = query.Encode()
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("retrieve aws credentials: %w", err)
+	}
+	signed, _, err := s.signer.PresignHTTP(ctx, creds, req, "UNSIGNED-PAYLOAD", "s3", s.cfg.Region, at)
+	if err != nil {
+		return "", fmt.Errorf("presign export url: %w", err)
+	}
+	return signed, nil
+}
@@ -0,0 +1,103 @@
+package dlqexport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+var testCreds = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+})
+
+func TestExport_WritesJSONLines(t *testing.T) {
+	var (
+		gotReq  *http.Request
+		gotBody []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	tenantID := uuid.New()
+	items := []*db.DeadLetterNotification{
+		{ID: uuid.New(), TenantID: tenantID, Reason: db.DLQReasonTimeout, Payload: json.RawMessage(`{"html":"<p>Hi</p>"}`)},
+		{ID: uuid.New(), TenantID: tenantID, Reason: db.DLQReasonInvalidRecipient, Payload: json.RawMessage(`{}`)},
+	}
+
+	s := newS3(Config{Region: "us-east-1", Bucket: "b", Prefix: "dlq-exports/", URLExpiry: 15 * time.Minute}, srv.URL, testCreds)
+	export, err := s.Export(context.Background(), tenantID, items)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	if gotReq.Method != http.MethodPut || gotReq.URL.Path != "/"+export.Key {
+		t.Errorf("unexpected request %s %s", gotReq.Method, gotReq.URL.Path)
+	}
+	if !strings.HasPrefix(export.Key, "dlq-exports/"+tenantID.String()+"/") || !strings.HasSuffix(export.Key, ".jsonl") {
+		t.Errorf("unexpected key %q", export.Key)
+	}
+	if !strings.HasPrefix(gotReq.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		t.Errorf("expected a SigV4 Authorization header, got %q", gotReq.Header.Get("Authorization"))
+	}
+	if !bytes.Contains(gotBody, []byte(`<p>Hi</p>`)) {
+		t.Errorf("expected the payload written unescaped, got %s", gotBody)
+	}
+
+	var lines int
+	sc := bufio.NewScanner(bytes.NewReader(gotBody))
+	for sc.Scan() {
+		var item db.DeadLetterNotification
+		if err := json.Unmarshal(sc.Bytes(), &item); err != nil {
+			t.Fatalf("line %d is not a dead letter: %v", lines+1, err)
+		}
+		if item.ID != items[lines].ID || item.Reason != items[lines].Reason {
+			t.Errorf("line %d: got %s/%s, want %s/%s", lines+1, item.ID, item.Reason, items[lines].ID, items[lines].Reason)
+		}
+		lines++
+	}
+	if lines != 2 || export.Count != 2 {
+		t.Errorf("expected 2 lines and count 2, got %d and %d", lines, export.Count)
+	}
+
+	u, err := url.Parse(export.URL)
+	if err != nil {
+		t.Fatalf("bad presigned url: %v", err)
+	}
+	q := u.Query()
+	if u.Path != "/"+export.Key || q.Get("X-Amz-Expires") != "900" || q.Get("X-Amz-Signature") == "" ||
+		!strings.HasPrefix(q.Get("X-Amz-Credential"), "AKID/") {
+		t.Errorf("unexpected presigned url %s", export.URL)
+	}
+	if until := time.Until(export.ExpiresAt); until < 14*time.Minute || until > 15*time.Minute {
+		t.Errorf("expected the url to expire in 15m, got %s", until)
+	}
+}
+
+func TestExport_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code></Error>")
+	}))
+	defer srv.Close()
+
+	s := newS3(Config{Region: "us-east-1", Bucket: "b"}, srv.URL, testCreds)
+	if _, err := s.Export(context.Background(), uuid.New(), nil); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("expected the S3 error, got %v", err)
+	}
+}
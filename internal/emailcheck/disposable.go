@@ -0,0 +1,53 @@
+package emailcheck
+
+// disposableDomains are well-known throwaway-mailbox providers. Extend the
+// list per deployment with EMAIL_DISPOSABLE_DOMAINS rather than editing it.
+var disposableDomains = []string{
+	"10minutemail.com",
+	"20minutemail.com",
+	"33mail.com",
+	"anonaddy.me",
+	"burnermail.io",
+	"discard.email",
+	"dispostable.com",
+	"emailondeck.com",
+	"fakeinbox.com",
+	"getairmail.com",
+	"getnada.com",
+	"guerrillamail.biz",
+	"guerrillamail.com",
+	"guerrillamail.de",
+	"guerrillamail.info",
+	"guerrillamail.net",
+	"guerrillamail.org",
+	"guerrillamailblock.com",
+	"harakirimail.com",
+	"inboxkitten.com",
+	"mail-temp.com",
+	"mailcatch.com",
+	"maildrop.cc",
+	"mailinator.com",
+	"mailinator.net",
+	"mailnesia.com",
+	"mailpoof.com",
+	"mintemail.com",
+	"mohmal.com",
+	"moakt.com",
+	"mytemp.email",
+	"sharklasers.com",
+	"spam4.me",
+	"spamgourmet.com",
+	"temp-mail.io",
+	"temp-mail.org",
+	"tempail.com",
+	"tempmail.dev",
+	"tempmail.net",
+	"tempmailo.com",
+	"tempr.email",
+	"throwawaymail.com",
+	"trashmail.com",
+	"trashmail.de",
+	"yopmail.com",
+	"yopmail.fr",
+	"yopmail.net",
+}
@@ -0,0 +1,186 @@
+// Package emailcheck judges whether an email recipient is worth sending to:
+// syntactically valid, not on a disposable-mailbox domain, and (optionally)
+// backed by a domain that accepts mail.
+package emailcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxAddressLength   = 254
+	maxLocalPartLength = 64
+	defaultMXTimeout   = 2 * time.Second
+)
+
+// Verdict reasons.
+const (
+	ReasonInvalidSyntax = "invalid_syntax"
+	ReasonDisposable    = "disposable_domain"
+	ReasonNoMX          = "no_mx_records"
+)
+
+// Verdict is the outcome of checking one address.
+type Verdict struct {
+	Address    string `json:"address"`
+	Valid      bool   `json:"valid"`
+	Reason     string `json:"reason,omitempty"`
+	Disposable bool   `json:"disposable"`
+	// MXChecked is false when MX lookup is disabled or the DNS query failed
+	// transiently; only a definitive "no such domain / no MX" fails it.
+	MXChecked bool `json:"mx_checked"`
+}
+
+// MXResolver is the subset of *net.Resolver the checker uses.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// Config holds the checker configuration.
+type Config struct {
+	LookupMX        bool          // Resolve MX records for the domain
+	MXTimeout       time.Duration // Per-lookup timeout (default: 2s)
+	ExtraDisposable []string      // Domains added to the built-in blocklist
+	Resolver        MXResolver    // default: net.DefaultResolver
+}
+
+// Checker validates email addresses.
+type Checker struct {
+	lookupMX   bool
+	mxTimeout  time.Duration
+	disposable map[string]bool
+	resolver   MXResolver
+	logger     *zap.Logger
+}
+
+// New creates a checker.
+func New(cfg Config, logger *zap.Logger) *Checker {
+	if cfg.MXTimeout == 0 {
+		cfg.MXTimeout = defaultMXTimeout
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = net.DefaultResolver
+	}
+
+	disposable := make(map[string]bool, len(disposableDomains)+len(cfg.ExtraDisposable))
+	for _, d := range disposableDomains {
+		disposable[d] = true
+	}
+	for _, d := range cfg.ExtraDisposable {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			disposable[d] = true
+		}
+	}
+
+	return &Checker{
+		lookupMX:   cfg.LookupMX,
+		mxTimeout:  cfg.MXTimeout,
+		disposable: disposable,
+		resolver:   cfg.Resolver,
+		logger:     logger,
+	}
+}
+
+// Check judges address. Checks run cheapest first and stop at the first
+// failure.
+func (c *Checker) Check(ctx context.Context, address string) Verdict {
+	v := Verdict{Address: address}
+
+	domain, ok := parseAddress(address)
+	if !ok {
+		v.Reason = ReasonInvalidSyntax
+		return v
+	}
+
+	if c.isDisposable(domain) {
+		v.Disposable = true
+		v.Reason = ReasonDisposable
+		return v
+	}
+
+	if c.lookupMX {
+		hasMX, definitive := c.hasMX(ctx, domain)
+		v.MXChecked = definitive
+		if definitive && !hasMX {
+			v.Reason = ReasonNoMX
+			return v
+		}
+	}
+
+	v.Valid = true
+	return v
+}
+
+// parseAddress accepts a bare addr-spec (no display name) and returns its
+// lower-cased domain.
+func parseAddress(address string) (string, bool) {
+	if len(address) > maxAddressLength {
+		return "", false
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return "", false
+	}
+
+	at := strings.LastIndexByte(address, '@')
+	local, domain := address[:at], strings.ToLower(address[at+1:])
+	if len(local) > maxLocalPartLength {
+		return "", false
+	}
+	// Reject dotless and bracketed-IP domains: neither is a real recipient.
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, "[") ||
+		strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return domain, true
+}
+
+// isDisposable matches the domain and each parent domain, so subdomains of a
+// blocked domain are blocked too.
+func (c *Checker) isDisposable(domain string) bool {
+	for {
+		if c.disposable[domain] {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+// hasMX reports whether domain has MX records. definitive is false when the
+// lookup failed for reasons that say nothing about the domain (timeouts,
+// SERVFAIL), so a flaky resolver never rejects good addresses.
+func (c *Checker) hasMX(ctx context.Context, domain string) (hasMX, definitive bool) {
+	ctx, cancel := context.WithTimeout(ctx, c.mxTimeout)
+	defer cancel()
+
+	records, err := c.resolver.LookupMX(ctx, domain)
+	if err == nil {
+		// A single "." MX is the null MX (RFC 7505): the domain takes no mail.
+		if len(records) == 1 && records[0].Host == "." {
+			return false, true
+		}
+		return len(records) > 0, true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, true
+	}
+
+	c.logger.Debug("mx lookup inconclusive",
+		zap.String("domain", domain),
+		zap.Error(err),
+	)
+	return false, false
+}
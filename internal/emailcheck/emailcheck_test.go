@@ -0,0 +1,83 @@
+package emailcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type fakeResolver struct {
+	records map[string][]*net.MX
+	errs    map[string]error
+}
+
+func (f *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if err, ok := f.errs[name]; ok {
+		return nil, err
+	}
+	if records, ok := f.records[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func TestCheck(t *testing.T) {
+	resolver := &fakeResolver{
+		records: map[string][]*net.MX{
+			"example.com": {{Host: "mx.example.com.", Pref: 10}},
+			"nomail.com":  {{Host: ".", Pref: 0}},
+		},
+		errs: map[string]error{
+			"flaky.com": &net.DNSError{Err: "server misbehaving", Name: "flaky.com", IsTemporary: true},
+			"weird.com": errors.New("boom"),
+		},
+	}
+	checker := New(Config{
+		LookupMX:        true,
+		Resolver:        resolver,
+		ExtraDisposable: []string{"Burner.Example"},
+	}, zap.NewNop())
+
+	tests := []struct {
+		address    string
+		valid      bool
+		reason     string
+		mxChecked  bool
+		disposable bool
+	}{
+		{"alice@example.com", true, "", true, false},
+		{"Alice <alice@example.com>", false, ReasonInvalidSyntax, false, false},
+		{"alice@", false, ReasonInvalidSyntax, false, false},
+		{"alice@localhost", false, ReasonInvalidSyntax, false, false},
+		{"alice@[127.0.0.1]", false, ReasonInvalidSyntax, false, false},
+		{"not an email", false, ReasonInvalidSyntax, false, false},
+		{"bob@mailinator.com", false, ReasonDisposable, false, true},
+		{"bob@eu.mailinator.com", false, ReasonDisposable, false, true},
+		{"bob@burner.example", false, ReasonDisposable, false, true},
+		{"carol@missing.com", false, ReasonNoMX, true, false},
+		{"carol@nomail.com", false, ReasonNoMX, true, false},
+		{"dave@flaky.com", true, "", false, false},
+		{"dave@weird.com", true, "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got := checker.Check(context.Background(), tt.address)
+			if got.Valid != tt.valid || got.Reason != tt.reason || got.MXChecked != tt.mxChecked || got.Disposable != tt.disposable {
+				t.Errorf("Check(%q) = %+v, want valid=%v reason=%q mx_checked=%v disposable=%v",
+					tt.address, got, tt.valid, tt.reason, tt.mxChecked, tt.disposable)
+			}
+		})
+	}
+}
+
+func TestCheck_NoMXLookup(t *testing.T) {
+	checker := New(Config{Resolver: &fakeResolver{}}, zap.NewNop())
+	got := checker.Check(context.Background(), "carol@missing.com")
+	if !got.Valid || got.MXChecked {
+		t.Errorf("expected valid without an MX check, got %+v", got)
+	}
+}
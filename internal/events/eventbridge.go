@@ -0,0 +1,249 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// EventBridge limits: entries per PutEvents call.
+const maxPutEntries = 10
+
+// Publishing outcomes, the "outcome" label of nimbus_lifecycle_events_total.
+const (
+	outcomePublished = "published"
+	outcomeDropped   = "dropped"
+	outcomeFailed    = "failed"
+)
+
+// Config configures the EventBridge emitter.
+type Config struct {
+	Region  string
+	BusName string
+	// BufferSize bounds the events waiting to be published. Emit drops
+	// events once it is full rather than slow down sends.
+	BufferSize int
+	// FlushInterval is the longest an event waits for a full batch.
+	FlushInterval time.Duration
+}
+
+// EventBridge publishes lifecycle events to an EventBridge bus in the
+// background, batching up to ten per PutEvents call. It speaks the
+// PutEvents JSON API directly with the SDK's SigV4 signer, which keeps
+// the dependency footprint to the SDK core.
+type EventBridge struct {
+	cfg         Config
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+	logger      *zap.Logger
+
+	queue     chan Detail
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEventBridge creates an emitter for cfg.BusName using the default AWS
+// credential chain, and starts its publishing loop. Call Close on shutdown
+// to flush what is buffered.
+func NewEventBridge(ctx context.Context, cfg Config, logger *zap.Logger) (*EventBridge, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	endpoint := fmt.Sprintf("https://events.%s.amazonaws.com/", cfg.Region)
+	return newEventBridge(cfg, endpoint, awsCfg.Credentials, logger), nil
+}
+
+func newEventBridge(cfg Config, endpoint string, credentials aws.CredentialsProvider, logger *zap.Logger) *EventBridge {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	e := &EventBridge{
+		cfg:         cfg,
+		endpoint:    endpoint,
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		queue:       make(chan Detail, cfg.BufferSize),
+		done:        make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Emit queues detail for publishing. It never blocks: when the buffer is
+// full the event is dropped and counted.
+func (e *EventBridge) Emit(detail Detail) {
+	select {
+	case e.queue <- detail:
+	default:
+		metrics.RecordLifecycleEvent(detail.Type, outcomeDropped)
+	}
+}
+
+// Close stops accepting events and publishes what is buffered, giving up
+// when ctx is done. Emit must not be called after Close.
+func (e *EventBridge) Close(ctx context.Context) error {
+	e.closeOnce.Do(func() { close(e.queue) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *EventBridge) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Detail, 0, maxPutEntries)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		e.publish(ctx, batch)
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case d, ok := <-e.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, d)
+			if len(batch) == maxPutEntries {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+type putEventsEntry struct {
+	Source       string `json:"Source"`
+	DetailType   string `json:"DetailType"`
+	Detail       string `json:"Detail"`
+	EventBusName string `json:"EventBusName"`
+	Time         int64  `json:"Time"`
+}
+
+type putEventsResult struct {
+	FailedEntryCount int `json:"FailedEntryCount"`
+	Entries          []struct {
+		EventID      string `json:"EventId"`
+		ErrorCode    string `json:"ErrorCode"`
+		ErrorMessage string `json:"ErrorMessage"`
+	} `json:"Entries"`
+}
+
+// publish sends one PutEvents call and records each event's outcome.
+// Failed events are not retried: lifecycle events are best-effort and the
+// API remains the source of truth.
+func (e *EventBridge) publish(ctx context.Context, batch []Detail) {
+	entries := make([]putEventsEntry, 0, len(batch))
+	for _, d := range batch {
+		detail, err := json.Marshal(d)
+		if err != nil {
+			metrics.RecordLifecycleEvent(d.Type, outcomeFailed)
+			continue
+		}
+		entries = append(entries, putEventsEntry{
+			Source:       Source,
+			DetailType:   d.Type,
+			Detail:       string(detail),
+			EventBusName: e.cfg.BusName,
+			Time:         d.OccurredAt.Unix(),
+		})
+	}
+
+	result, err := e.putEvents(ctx, entries)
+	if err != nil {
+		e.logger.Warn("failed to publish lifecycle events", zap.Error(err), zap.Int("events", len(entries)))
+		for _, entry := range entries {
+			metrics.RecordLifecycleEvent(entry.DetailType, outcomeFailed)
+		}
+		return
+	}
+	for i, entry := range entries {
+		outcome := outcomePublished
+		if i < len(result.Entries) && result.Entries[i].ErrorCode != "" {
+			outcome = outcomeFailed
+			e.logger.Warn("lifecycle event rejected",
+				zap.String("type", entry.DetailType),
+				zap.String("error_code", result.Entries[i].ErrorCode),
+				zap.String("error", result.Entries[i].ErrorMessage),
+			)
+		}
+		metrics.RecordLifecycleEvent(entry.DetailType, outcome)
+	}
+}
+
+func (e *EventBridge) putEvents(ctx context.Context, entries []putEventsEntry) (*putEventsResult, error) {
+	body, err := json.Marshal(map[string]any{"Entries": entries})
+	if err != nil {
+		return nil, fmt.Errorf("marshal put events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build put events request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSEvents.PutEvents")
+
+	creds, err := e.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve aws credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := e.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "events", e.cfg.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("sign put events request: %w", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("put events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read put events response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("put events: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result putEventsResult
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("decode put events response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,132 @@
+// Package events publishes notification lifecycle events (created, sent,
+// failed, dead_lettered, collapsed, frequency_capped, opted_out) for
+// customer automations and analytics, so they can react to deliveries
+// without polling the API.
+package events
+
+import (
+	"time"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// Lifecycle event types. They are the EventBridge detail-type and the
+// "type" field of Detail.
+const (
+	TypeCreated      = "notification.created"
+	TypeSent         = "notification.sent"
+	TypeFailed       = "notification.failed"
+	TypeDeadLettered = "notification.dead_lettered"
+	TypeCollapsed    = "notification.collapsed"
+	// TypeFrequencyCapped is a notification dropped by its tenant's
+	// frequency cap.
+	TypeFrequencyCapped = "notification.frequency_capped"
+	// TypeOptedOut is a notification not sent because its user opted out
+	// of its category.
+	TypeOptedOut = "notification.opted_out"
+)
+
+// Source is the EventBridge source of every lifecycle event; rules match
+// on it together with detail-type.
+const Source = "nimbus.notifications"
+
+// SchemaVersion is bumped whenever Detail changes incompatibly.
+const SchemaVersion = "1"
+
+// Detail is the event body (EventBridge "detail"). See docs/API.md for the
+// documented schema; fields are only ever added.
+type Detail struct {
+	Version           string     `json:"version"`
+	Type              string     `json:"type"`
+	NotificationID    string     `json:"notification_id"`
+	TenantID          string     `json:"tenant_id"`
+	UserID            string     `json:"user_id"`
+	Channel           string     `json:"channel"`
+	Status            string     `json:"status"`
+	Attempt           int        `json:"attempt"`
+	CorrelationID     string     `json:"correlation_id,omitempty"`
+	Tags              []string   `json:"tags,omitempty"`
+	Category          string     `json:"category,omitempty"`
+	Provider          string     `json:"provider,omitempty"`
+	ProviderMessageID string     `json:"provider_message_id,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	NextRetryAt       *time.Time `json:"next_retry_at,omitempty"`
+	OccurredAt        time.Time  `json:"occurred_at"`
+}
+
+// Emitter publishes lifecycle events. Emit must not block the send path;
+// delivery is best-effort.
+type Emitter interface {
+	Emit(detail Detail)
+}
+
+// statusAfter is the notification status each event type leaves behind.
+// A failed attempt goes back to pending for its retry.
+var statusAfter = map[string]string{
+	TypeCreated:      db.StatusPending,
+	TypeSent:         db.StatusSent,
+	TypeFailed:       db.StatusPending,
+	TypeDeadLettered: db.StatusDeadLettered,
+	TypeCollapsed:    db.StatusSent,
+
+	TypeFrequencyCapped: db.StatusFrequencyCapped,
+	TypeOptedOut:        db.StatusFailed,
+}
+
+// New builds the Detail of an eventType event for notif as it stands.
+// Callers fill in what the transition itself changed, such as Attempt,
+// Error and NextRetryAt.
+func New(eventType string, notif *db.Notification) Detail {
+	status, ok := statusAfter[eventType]
+	if !ok {
+		status = notif.Status
+	}
+	d := Detail{
+		Version:           SchemaVersion,
+		Type:              eventType,
+		NotificationID:    notif.ID.String(),
+		TenantID:          notif.TenantID.String(),
+		UserID:            notif.UserID.String(),
+		Channel:           notif.Channel,
+		Status:            status,
+		Attempt:           notif.Attempt,
+		CorrelationID:     notif.CorrelationID,
+		Tags:              notif.Tags,
+		Category:          notif.Category,
+		Provider:          notif.Provider,
+		ProviderMessageID: notif.ProviderMessageID,
+		NextRetryAt:       notif.NextRetryAt,
+		OccurredAt:        time.Now().UTC(),
+	}
+	if notif.ErrorMessage != nil {
+		d.Error = *notif.ErrorMessage
+	}
+	return d
+}
+
+// Multi returns an Emitter that hands every event to each non-nil
+// emitter, or nil if there are none, so callers can keep treating a nil
+// Emitter as "events off".
+func Multi(emitters ...Emitter) Emitter {
+	var m multi
+	for _, e := range emitters {
+		if e != nil {
+			m = append(m, e)
+		}
+	}
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
+	}
+	return m
+}
+
+type multi []Emitter
+
+func (m multi) Emit(detail Detail) {
+	for _, e := range m {
+		e.Emit(detail)
+	}
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestNew(t *testing.T) {
+	errMsg := "smtp timeout"
+	notif := &db.Notification{
+		ID:            uuid.New(),
+		TenantID:      uuid.New(),
+		UserID:        uuid.New(),
+		Channel:       db.ChannelEmail,
+		Status:        db.StatusProcessing,
+		Attempt:       1,
+		CorrelationID: "corr-1",
+		ErrorMessage:  &errMsg,
+	}
+
+	tests := []struct {
+		eventType string
+		status    string
+	}{
+		{TypeCreated, db.StatusPending},
+		{TypeSent, db.StatusSent},
+		{TypeFailed, db.StatusPending},
+		{TypeDeadLettered, db.StatusDeadLettered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			d := New(tt.eventType, notif)
+			if d.Type != tt.eventType || d.Status != tt.status {
+				t.Errorf("got type %q status %q, want %q %q", d.Type, d.Status, tt.eventType, tt.status)
+			}
+			if d.Version != SchemaVersion || d.NotificationID != notif.ID.String() || d.CorrelationID != "corr-1" {
+				t.Errorf("unexpected detail: %+v", d)
+			}
+			if d.Error != errMsg {
+				t.Errorf("error = %q, want %q", d.Error, errMsg)
+			}
+		})
+	}
+}
+
+func TestEventBridge_PublishesBatches(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]putEventsEntry
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AWSEvents.PutEvents" {
+			t.Errorf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			t.Errorf("expected a SigV4 signed request, got %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req struct{ Entries []putEventsEntry }
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		batches = append(batches, req.Entries)
+		mu.Unlock()
+		w.Write([]byte(`{"FailedEntryCount":0,"Entries":[]}`))
+	}))
+	defer srv.Close()
+
+	creds := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+	})
+	e := newEventBridge(Config{Region: "us-east-1", BusName: "nimbus", FlushInterval: time.Hour}, srv.URL, creds, zap.NewNop())
+
+	notif := &db.Notification{ID: uuid.New(), TenantID: uuid.New(), Channel: db.ChannelSMS}
+	for i := 0; i < 12; i++ {
+		e.Emit(New(TypeSent, notif))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 2 || len(batches[0]) != maxPutEntries || len(batches[1]) != 2 {
+		t.Fatalf("expected a full batch and the remainder flushed on close, got %d batches", len(batches))
+	}
+	entry := batches[0][0]
+	if entry.Source != Source || entry.DetailType != TypeSent || entry.EventBusName != "nimbus" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	var detail Detail
+	if err := json.Unmarshal([]byte(entry.Detail), &detail); err != nil || detail.NotificationID != notif.ID.String() {
+		t.Errorf("unexpected detail %q (err %v)", entry.Detail, err)
+	}
+}
+
+type countingEmitter struct{ n int }
+
+func (c *countingEmitter) Emit(Detail) { c.n++ }
+
+func TestMulti(t *testing.T) {
+	if Multi() != nil || Multi(nil, nil) != nil {
+		t.Error("expected no emitters to mean events off")
+	}
+	a, b := &countingEmitter{}, &countingEmitter{}
+	Multi(a, nil, b).Emit(Detail{})
+	if a.n != 1 || b.n != 1 {
+		t.Errorf("expected each emitter called once, got %d and %d", a.n, b.n)
+	}
+}
@@ -0,0 +1,140 @@
+// Package frequency enforces tenants' frequency caps: at most max_count
+// notifications to one user on a channel within a window, so a burst of
+// events upstream doesn't become a storm of messages to the same person.
+// A cap can cover the whole channel or one notification category on it.
+package frequency
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// refreshInterval is how stale the capper's view of the caps may get.
+const refreshInterval = 30 * time.Second
+
+// minDelay keeps a deferred notification from coming straight back when
+// the oldest counted send is just leaving the window.
+const minDelay = time.Second
+
+// Store lists the caps and counts what users have been sent.
+type Store interface {
+	ListFrequencyCaps(ctx context.Context) ([]*db.FrequencyCap, error)
+	CountUserSends(ctx context.Context, tenantID, userID uuid.UUID, channel, category string, since time.Time) (count int, oldest *time.Time, err error)
+}
+
+// Capper checks notifications against their tenant's caps on the channel:
+// the cap on their category, if any, and the channel-wide one.
+// Sends are counted from the notifications table, so the cap holds across
+// worker replicas, but notifications to one user sent at the same moment
+// can each see room for one more and overshoot it slightly.
+type Capper struct {
+	store  Store
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	caps     map[capKey]*db.FrequencyCap
+	loadedAt time.Time
+}
+
+type capKey struct {
+	tenantID uuid.UUID
+	channel  string
+	category string // "" for the channel-wide cap
+}
+
+// New creates a capper.
+func New(store Store, logger *zap.Logger) *Capper {
+	return &Capper{
+		store:  store,
+		logger: logger,
+		caps:   make(map[capKey]*db.FrequencyCap),
+	}
+}
+
+// Check reports what to do with notif: send it now (zero delay, no drop),
+// defer it for delay, or drop it. It fits worker.Config.FrequencyCap.
+// Critical notifications are never capped. When both a category cap and
+// the channel-wide cap are reached, a drop wins, else the longer delay.
+func (c *Capper) Check(ctx context.Context, notif *db.Notification) (delay time.Duration, drop bool) {
+	if slices.Contains(notif.Tags, db.TagCritical) {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	if now.Sub(c.loadedAt) >= refreshInterval {
+		c.refresh(ctx, now)
+	}
+	limits := []*db.FrequencyCap{c.caps[capKey{tenantID: notif.TenantID, channel: notif.Channel}]}
+	if notif.Category != "" {
+		limits = append(limits, c.caps[capKey{tenantID: notif.TenantID, channel: notif.Channel, category: notif.Category}])
+	}
+	c.mu.Unlock()
+
+	for _, limit := range limits {
+		if limit == nil {
+			continue
+		}
+		wait, capped := c.check(ctx, notif, limit, now)
+		if capped {
+			return 0, true
+		}
+		delay = max(delay, wait)
+	}
+	return delay, false
+}
+
+// check checks notif against one cap.
+func (c *Capper) check(ctx context.Context, notif *db.Notification, limit *db.FrequencyCap, now time.Time) (delay time.Duration, drop bool) {
+	window := time.Duration(limit.WindowSeconds) * time.Second
+	count, oldest, err := c.store.CountUserSends(ctx, notif.TenantID, notif.UserID, notif.Channel, limit.Category, now.Add(-window))
+	if err != nil {
+		// Fail open, like the other send limits: a database blip shouldn't
+		// hold up every capped tenant's sends.
+		c.logger.Warn("failed to check frequency cap, sending anyway",
+			zap.Error(err),
+			zap.String("tenant_id", notif.TenantID.String()),
+			zap.String("channel", notif.Channel),
+			zap.String("category", limit.Category),
+		)
+		return 0, false
+	}
+	if count < limit.MaxCount {
+		return 0, false
+	}
+	if limit.Action == db.FrequencyCapDrop {
+		return 0, true
+	}
+
+	// The window has room again once its oldest send leaves it.
+	delay = window
+	if oldest != nil {
+		delay = oldest.Add(window).Sub(now)
+	}
+	return max(delay, minDelay), false
+}
+
+// refresh reloads the caps. On error the previous caps are kept. Callers
+// hold c.mu.
+func (c *Capper) refresh(ctx context.Context, now time.Time) {
+	c.loadedAt = now
+
+	caps, err := c.store.ListFrequencyCaps(ctx)
+	if err != nil {
+		c.logger.Warn("failed to refresh frequency caps, keeping previous", zap.Error(err))
+		return
+	}
+
+	loaded := make(map[capKey]*db.FrequencyCap, len(caps))
+	for _, limit := range caps {
+		loaded[capKey{tenantID: limit.TenantID, channel: limit.Channel, category: limit.Category}] = limit
+	}
+	c.caps = loaded
+}
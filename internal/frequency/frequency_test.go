@@ -0,0 +1,122 @@
+package frequency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type fakeStore struct {
+	caps          []*db.FrequencyCap
+	count         int
+	categoryCount int // sends counted for a category cap
+	oldest        *time.Time
+	err           error
+}
+
+func (f *fakeStore) ListFrequencyCaps(ctx context.Context) ([]*db.FrequencyCap, error) {
+	return f.caps, nil
+}
+
+func (f *fakeStore) CountUserSends(ctx context.Context, tenantID, userID uuid.UUID, channel, category string, since time.Time) (int, *time.Time, error) {
+	if category != "" {
+		return f.categoryCount, f.oldest, f.err
+	}
+	return f.count, f.oldest, f.err
+}
+
+func TestCheck(t *testing.T) {
+	tenantID := uuid.New()
+	oldest := time.Now().Add(-50 * time.Minute)
+
+	tests := []struct {
+		name      string
+		action    string
+		channel   string
+		tags      []string
+		count     int
+		err       error
+		wantDelay bool
+		wantDrop  bool
+	}{
+		{name: "under the cap", action: db.FrequencyCapDefer, channel: "email", count: 2},
+		{name: "other channel", action: db.FrequencyCapDefer, channel: "sms", count: 5},
+		{name: "at the cap, deferred", action: db.FrequencyCapDefer, channel: "email", count: 3, wantDelay: true},
+		{name: "at the cap, dropped", action: db.FrequencyCapDrop, channel: "email", count: 3, wantDrop: true},
+		{name: "critical", action: db.FrequencyCapDrop, channel: "email", tags: []string{db.TagCritical}, count: 5},
+		{name: "store error", action: db.FrequencyCapDrop, channel: "email", err: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{
+				caps: []*db.FrequencyCap{{
+					TenantID: tenantID, Channel: "email", MaxCount: 3, WindowSeconds: 3600, Action: tt.action,
+				}},
+				count:  tt.count,
+				oldest: &oldest,
+				err:    tt.err,
+			}
+			notif := &db.Notification{TenantID: tenantID, UserID: uuid.New(), Channel: tt.channel, Tags: tt.tags}
+
+			delay, drop := New(store, zap.NewNop()).Check(context.Background(), notif)
+			if drop != tt.wantDrop {
+				t.Errorf("expected drop %v, got %v", tt.wantDrop, drop)
+			}
+			if !tt.wantDelay {
+				if delay != 0 {
+					t.Errorf("expected no delay, got %v", delay)
+				}
+				return
+			}
+			// The oldest send leaves the hour-long window in ten minutes.
+			if delay < 9*time.Minute || delay > 10*time.Minute {
+				t.Errorf("expected a delay of about 10m, got %v", delay)
+			}
+		})
+	}
+}
+
+func TestCheck_Category(t *testing.T) {
+	tenantID := uuid.New()
+	oldest := time.Now().Add(-50 * time.Minute)
+	caps := []*db.FrequencyCap{
+		{TenantID: tenantID, Channel: "email", MaxCount: 3, WindowSeconds: 3600, Action: db.FrequencyCapDefer},
+		{TenantID: tenantID, Channel: "email", Category: "marketing", MaxCount: 1, WindowSeconds: 3600, Action: db.FrequencyCapDrop},
+	}
+
+	tests := []struct {
+		name          string
+		category      string
+		count         int
+		categoryCount int
+		wantDelay     bool
+		wantDrop      bool
+	}{
+		{name: "at the category cap", category: "marketing", categoryCount: 1, wantDrop: true},
+		{name: "category without a cap", category: "billing", categoryCount: 1},
+		{name: "at the channel cap", category: "marketing", count: 3, wantDelay: true},
+		{name: "at both caps", category: "marketing", count: 3, categoryCount: 1, wantDrop: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{caps: caps, count: tt.count, categoryCount: tt.categoryCount, oldest: &oldest}
+			notif := &db.Notification{TenantID: tenantID, UserID: uuid.New(), Channel: "email", Category: tt.category}
+
+			delay, drop := New(store, zap.NewNop()).Check(context.Background(), notif)
+			if drop != tt.wantDrop {
+				t.Errorf("expected drop %v, got %v", tt.wantDrop, drop)
+			}
+			if (delay > 0) != tt.wantDelay {
+				t.Errorf("expected delay %v, got %v", tt.wantDelay, delay)
+			}
+		})
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"strings"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/maintenance"
 )
 
 // ContextKey is a typed key to avoid collisions in context.WithValue.
@@ -33,6 +37,17 @@ func TenantIDFromContext(ctx context.Context) (string, bool) {
 	return v, ok && v != ""
 }
 
+// withTenant injects the authenticated tenant for handlers and, when it is
+// a UUID, scopes the request's database connections to it so row-level
+// security backs up the handlers' own tenant checks.
+func withTenant(ctx context.Context, tenantID string) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyTenantID, tenantID)
+	if id, err := uuid.Parse(tenantID); err == nil {
+		ctx = db.WithTenant(ctx, id)
+	}
+	return ctx
+}
+
 // AuthInterceptor returns a gRPC UnaryServerInterceptor that validates
 // Bearer tokens on every incoming unary RPC.
 //
@@ -70,8 +85,7 @@ func AuthInterceptor(validTokens map[string]string, logger *zap.Logger) grpc.Una
 		}
 		// Inject tenant_id into context — downstream handlers read it
 		// via ctx.Value(ContextKeyTenantID) without touching the token again.
-		ctx = context.WithValue(ctx, ContextKeyTenantID, tenantID)
-		return handler(ctx, req)
+		return handler(withTenant(ctx, tenantID), req)
 	}
 }
 
@@ -94,7 +108,7 @@ func StreamAuthInterceptor(validTokens map[string]string, logger *zap.Logger) gr
 		if err != nil {
 			return err
 		}
-		ctx := context.WithValue(ss.Context(), ContextKeyTenantID, tenantID)
+		ctx := withTenant(ss.Context(), tenantID)
 		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
 	}
 }
@@ -148,3 +162,26 @@ func extractAndValidate(ctx context.Context, validTokens map[string]string, logg
 
 	return tenantID, nil
 }
+
+// MaintenanceInterceptor refuses write RPCs with UNAVAILABLE while
+// maintenance mode is on, mirroring the REST middleware. Reads
+// (GetNotification, StreamDeliveryUpdates) are unaffected.
+func MaintenanceInterceptor(mode *maintenance.Mode) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if mode != nil && mode.Enabled() && isWriteMethod(info.FullMethod) {
+			return nil, status.Errorf(codes.Unavailable, "service under maintenance, retry after %ds", int(mode.RetryAfter().Seconds()))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// isWriteMethod reports whether a fully-qualified RPC name mutates state.
+func isWriteMethod(fullMethod string) bool {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	return strings.HasPrefix(method, "Create") || strings.HasPrefix(method, "Update") || strings.HasPrefix(method, "Delete")
+}
@@ -2,15 +2,19 @@ package grpc
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/events"
+	"github.com/lalithlochan/nimbus/internal/observ"
 	notificationv1 "github.com/lalithlochan/nimbus/proto/notification/v1"
 )
 
@@ -25,6 +29,7 @@ type Server struct {
 	notificationv1.UnimplementedNotificationServiceServer
 	repo   NotificationRepository
 	logger *zap.Logger
+	events events.Emitter // optional; lifecycle events
 }
 
 // NotificationRepository is the subset of DB operations the gRPC server needs.
@@ -40,6 +45,12 @@ func NewServer(repo NotificationRepository, logger *zap.Logger) *Server {
 	return &Server{repo: repo, logger: logger}
 }
 
+// SetEvents makes CreateNotification emit a notification.created
+// lifecycle event.
+func (s *Server) SetEvents(emitter events.Emitter) {
+	s.events = emitter
+}
+
 // ─── RPCs ────────────────────────────────────────────────────────────────────
 
 // CreateNotification — Unary RPC.
@@ -77,31 +88,43 @@ func (s *Server) CreateNotification(
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user_id: %v", err)
 	}
 
-	validChannels := map[string]bool{"email": true, "sms": true, "webhook": true}
+	validChannels := map[string]bool{"email": true, "sms": true, "webhook": true, "slack": true}
 	if !validChannels[req.Channel] {
-		return nil, status.Errorf(codes.InvalidArgument, "channel must be email, sms, or webhook")
+		return nil, status.Errorf(codes.InvalidArgument, "channel must be email, sms, webhook, or slack")
+	}
+
+	correlationID, err := correlationIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	notif := &db.Notification{
-		ID:       uuid.New(),
-		TenantID: tenantID,
-		UserID:   userID,
-		Channel:  req.Channel,
-		Payload:  req.Payload,
-		Status:   db.StatusPending,
-		Attempt:  0,
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		UserID:        userID,
+		Channel:       req.Channel,
+		Payload:       req.Payload,
+		Status:        db.StatusPending,
+		Attempt:       0,
+		CorrelationID: correlationID,
 	}
 
+	ctx = observ.With(ctx, s.logger,
+		zap.String(observ.FieldTenantID, authTenant),
+		zap.String(observ.FieldNotificationID, notif.ID.String()),
+		zap.String(observ.FieldCorrelationID, correlationID),
+	)
+
 	if err := s.repo.CreateNotification(ctx, notif); err != nil {
-		s.logger.Error("gRPC CreateNotification: DB write failed",
-			zap.Error(err),
-			zap.String("tenant_id", req.TenantId),
-		)
+		observ.Logger(ctx, s.logger).Error("gRPC CreateNotification: DB write failed", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to create notification")
 	}
 
-	s.logger.Info("gRPC: notification created",
-		zap.String("id", notif.ID.String()),
+	if s.events != nil {
+		s.events.Emit(events.New(events.TypeCreated, notif))
+	}
+
+	observ.Logger(ctx, s.logger).Info("gRPC: notification created",
 		zap.String("channel", req.Channel),
 	)
 
@@ -204,6 +227,8 @@ func (s *Server) StreamDeliveryUpdates(
 		"sent":          true,
 		"failed":        true,
 		"dead_lettered": true,
+
+		"frequency_capped": true,
 	}
 
 	for {
@@ -250,6 +275,21 @@ func (s *Server) StreamDeliveryUpdates(
 	}
 }
 
+// correlationIDFromContext returns the caller's x-correlation-id metadata
+// value, or a fresh ID when none was sent. Same rules as the REST header.
+func correlationIDFromContext(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(strings.ToLower(observ.CorrelationIDHeader))
+	if len(values) == 0 || values[0] == "" {
+		return observ.NewCorrelationID(), nil
+	}
+	if !observ.ValidCorrelationID(values[0]) {
+		return "", status.Errorf(codes.InvalidArgument,
+			"x-correlation-id must be at most %d characters of [A-Za-z0-9-_.:]", observ.MaxCorrelationIDLength)
+	}
+	return values[0], nil
+}
+
 // toProto converts a DB model to a protobuf message.
 // Keeping conversion logic in a single helper avoids duplication and makes
 // the mapping easy to audit when the schema changes.
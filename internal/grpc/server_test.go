@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/lalithlochan/nimbus/internal/db"
@@ -109,6 +110,50 @@ func TestCreateNotification_RequiresAuthenticatedTenant(t *testing.T) {
 	}
 }
 
+// TestCreateNotification_CorrelationID verifies the x-correlation-id metadata
+// is stored when valid, generated when absent and rejected when malformed.
+func TestCreateNotification_CorrelationID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantCode codes.Code
+	}{
+		{"generated when absent", "", codes.OK},
+		{"accepted from metadata", "req-123", codes.OK},
+		{"rejected when invalid", "not valid!", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			srv := NewServer(repo, zap.NewNop())
+
+			ctx := ctxWithTenant(tenantA)
+			if tt.incoming != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-correlation-id", tt.incoming))
+			}
+
+			_, err := srv.CreateNotification(ctx, &notificationv1.CreateNotificationRequest{
+				UserId:  someUser,
+				Channel: "email",
+				Payload: []byte(`{}`),
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected %v, got: %v", tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if repo.created.CorrelationID == "" {
+				t.Fatal("expected a correlation ID to be stored")
+			}
+			if tt.incoming != "" && repo.created.CorrelationID != tt.incoming {
+				t.Errorf("expected correlation ID %q, got %q", tt.incoming, repo.created.CorrelationID)
+			}
+		})
+	}
+}
+
 // TestGetNotification_BlocksCrossTenantRead verifies a caller can't read another
 // tenant's notification by guessing its UUID — we return NotFound (not
 // PermissionDenied) to avoid leaking that the ID exists.
@@ -0,0 +1,259 @@
+// Package imports reads the recipient files behind POST /v1/imports: CSV
+// with a header row, or JSON Lines of flat objects. Every row names a
+// user_id and recipient, and its remaining columns are variables that Render
+// substitutes into the import's payload template.
+package imports
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Supported file formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Reserved columns. Every other column is a template variable.
+const (
+	ColumnUserID    = "user_id"
+	ColumnRecipient = "recipient"
+)
+
+// maxLineBytes bounds one JSONL line, well above any sane row.
+const maxLineBytes = 64 << 10
+
+// Row is one recipient. Line is the 1-based line it was read from, counting
+// the CSV header, so row errors point at the line a spreadsheet shows.
+type Row struct {
+	Line      int
+	UserID    string
+	Recipient string
+	Vars      map[string]string
+}
+
+// RowError reports a row that could not be parsed. The reader can carry on
+// past it; any other error from Next ends the file.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// Reader returns the rows of a file in order. Next returns io.EOF after the
+// last row.
+type Reader interface {
+	Next() (*Row, error)
+}
+
+// ValidFormat reports whether format is one NewReader understands.
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSONL
+}
+
+// NewReader reads r as format. A CSV header is read here, so a file without
+// one fails before any row is processed.
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 0, 4096), maxLineBytes)
+		return &jsonlReader{scanner: s}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+	}
+	if !contains(header, ColumnUserID) || !contains(header, ColumnRecipient) {
+		return nil, fmt.Errorf("header must include %s and %s", ColumnUserID, ColumnRecipient)
+	}
+	// Rows must then have as many fields as the header.
+	cr.FieldsPerRecord = len(header)
+	return &csvReader{r: cr, header: header}, nil
+}
+
+func (c *csvReader) Next() (*Row, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+			return nil, &RowError{Line: parseErr.StartLine, Err: fmt.Errorf("expected %d fields, got %d", len(c.header), len(record))}
+		}
+		return nil, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	row := &Row{Line: line, Vars: make(map[string]string, len(record))}
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		switch c.header[i] {
+		case ColumnUserID:
+			row.UserID = value
+		case ColumnRecipient:
+			row.Recipient = value
+		default:
+			row.Vars[c.header[i]] = value
+		}
+	}
+	return row, nil
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (j *jsonlReader) Next() (*Row, error) {
+	for j.scanner.Scan() {
+		j.line++
+		text := strings.TrimSpace(j.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return nil, &RowError{Line: j.line, Err: errors.New("line is not a JSON object")}
+		}
+
+		row := &Row{Line: j.line, Vars: make(map[string]string, len(fields))}
+		for name, v := range fields {
+			value, err := scalar(v)
+			if err != nil {
+				return nil, &RowError{Line: j.line, Err: fmt.Errorf("%s: %w", name, err)}
+			}
+			switch name {
+			case ColumnUserID:
+				row.UserID = value
+			case ColumnRecipient:
+				row.Recipient = value
+			default:
+				row.Vars[name] = value
+			}
+		}
+		return row, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d: longer than %d bytes", j.line+1, maxLineBytes)
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// scalar turns a JSONL value into the string a CSV cell would hold.
+func scalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	default:
+		return "", errors.New("value must be a string, number, boolean or null")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholder matches {{name}}. Go template actions such as {{.name}} in
+// template_data are left for the template renderer.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// Render substitutes vars into every {{name}} placeholder in the string
+// values of tmpl, a JSON payload. Object keys are left alone. A placeholder
+// without a variable is an error, so a typo in the template fails the row
+// instead of sending "{{frist_name}}" to a customer.
+func Render(tmpl json.RawMessage, vars map[string]string) (json.RawMessage, error) {
+	var payload any
+	if err := json.Unmarshal(tmpl, &payload); err != nil {
+		return nil, fmt.Errorf("payload template is not valid JSON: %w", err)
+	}
+	rendered, err := render(payload, vars)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rendered)
+}
+
+func render(v any, vars map[string]string) (any, error) {
+	switch v := v.(type) {
+	case string:
+		var missing string
+		out := placeholder.ReplaceAllStringFunc(v, func(m string) string {
+			name := placeholder.FindStringSubmatch(m)[1]
+			value, ok := vars[name]
+			if !ok && missing == "" {
+				missing = name
+			}
+			return value
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("missing variable %q", missing)
+		}
+		return out, nil
+	case map[string]any:
+		for k, elem := range v {
+			r, err := render(elem, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+		return v, nil
+	case []any:
+		for i, elem := range v {
+			r, err := render(elem, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,149 @@
+package imports
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, r Reader) ([]*Row, []*RowError) {
+	t.Helper()
+	var rows []*Row
+	var rowErrs []*RowError
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows, rowErrs
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrs = append(rowErrs, rowErr)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	file := "user_id,recipient,first_name\n" +
+		"u1,ada@example.com,Ada\n" +
+		"u2,grace@example.com\n" +
+		"u3, alan@example.com ,Alan\n"
+
+	r, err := NewReader(strings.NewReader(file), FormatCSV)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	rows, rowErrs := readAll(t, r)
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].UserID != "u1" || rows[0].Recipient != "ada@example.com" || rows[0].Vars["first_name"] != "Ada" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if _, ok := rows[0].Vars[ColumnUserID]; ok {
+		t.Error("reserved columns should not be variables")
+	}
+	if rows[1].Line != 4 || rows[1].Recipient != "alan@example.com" {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+	if len(rowErrs) != 1 || rowErrs[0].Line != 3 {
+		t.Errorf("expected a field count error on line 3, got %v", rowErrs)
+	}
+}
+
+func TestCSVReader_Header(t *testing.T) {
+	for name, file := range map[string]string{
+		"empty":             "",
+		"missing recipient": "user_id,first_name\nu1,Ada\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewReader(strings.NewReader(file), FormatCSV); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	file := `{"user_id":"u1","recipient":"+14155550100","code":4821,"vip":true}` + "\n" +
+		"\n" +
+		"not json\n" +
+		`{"user_id":"u2","recipient":"+14155550101","nested":{"a":1}}` + "\n" +
+		`{"user_id":"u3","recipient":"+14155550102","note":null}`
+
+	r, err := NewReader(strings.NewReader(file), FormatJSONL)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	rows, rowErrs := readAll(t, r)
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Line != 1 || rows[0].Vars["code"] != "4821" || rows[0].Vars["vip"] != "true" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Line != 5 || rows[1].UserID != "u3" || rows[1].Vars["note"] != "" {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Line != 3 || rowErrs[1].Line != 4 {
+		t.Errorf("expected row errors on lines 3 and 4, got %v", rowErrs)
+	}
+}
+
+func TestNewReader_UnsupportedFormat(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), "xlsx"); err == nil {
+		t.Error("expected an error")
+	}
+	if ValidFormat("xlsx") || !ValidFormat(FormatCSV) || !ValidFormat(FormatJSONL) {
+		t.Error("unexpected ValidFormat result")
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := json.RawMessage(`{"subject":"Hi {{ first_name }}","body":"Your code is {{code}}.","template_data":{"name":"{{.name}}"},"list":["{{code}}"],"n":1}`)
+
+	got, err := Render(tmpl, map[string]string{"first_name": "Ada", "code": "4821"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(got, &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if payload["subject"] != "Hi Ada" || payload["body"] != "Your code is 4821." {
+		t.Errorf("unexpected payload: %s", got)
+	}
+	if payload["template_data"].(map[string]any)["name"] != "{{.name}}" {
+		t.Errorf("expected Go template actions to be left alone: %s", got)
+	}
+	if payload["list"].([]any)[0] != "4821" || payload["n"] != float64(1) {
+		t.Errorf("unexpected payload: %s", got)
+	}
+}
+
+func TestRender_MissingVariable(t *testing.T) {
+	_, err := Render(json.RawMessage(`{"subject":"Hi {{frist_name}}"}`), map[string]string{"first_name": "Ada"})
+	if err == nil || !strings.Contains(err.Error(), "frist_name") {
+		t.Errorf("expected missing variable error, got %v", err)
+	}
+}
+
+func TestRender_EscapesValues(t *testing.T) {
+	got, err := Render(json.RawMessage(`{"body":"{{name}}"}`), map[string]string{"name": `"quoted" \ <b>`})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(got, &payload); err != nil || payload["body"] != `"quoted" \ <b>` {
+		t.Errorf("expected the value to survive JSON encoding, got %s (%v)", got, err)
+	}
+}
@@ -0,0 +1,59 @@
+package imports
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var testCreds = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+})
+
+func TestS3Open(t *testing.T) {
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		_, _ = io.WriteString(w, "user_id,recipient\n")
+	}))
+	defer srv.Close()
+
+	s := newS3(S3Config{Region: "us-east-1", Bucket: "b"}, srv.URL, testCreds)
+	body, err := s.Open(context.Background(), "tenant/imports/list 1.csv")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer body.Close()
+
+	data, _ := io.ReadAll(body)
+	if string(data) != "user_id,recipient\n" {
+		t.Errorf("unexpected body %q", data)
+	}
+	if gotReq.Method != http.MethodGet || gotReq.URL.Path != "/tenant/imports/list 1.csv" {
+		t.Errorf("unexpected request %s %s", gotReq.Method, gotReq.URL.Path)
+	}
+	if !strings.HasPrefix(gotReq.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		t.Errorf("expected a SigV4 Authorization header, got %q", gotReq.Header.Get("Authorization"))
+	}
+	if gotReq.Header.Get("X-Amz-Content-Sha256") != emptyPayloadHash {
+		t.Errorf("expected the empty payload hash, got %q", gotReq.Header.Get("X-Amz-Content-Sha256"))
+	}
+}
+
+func TestS3Open_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+	}))
+	defer srv.Close()
+
+	s := newS3(S3Config{Region: "us-east-1", Bucket: "b"}, srv.URL, testCreds)
+	if _, err := s.Open(context.Background(), "missing.csv"); err == nil || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Errorf("expected the S3 error, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// deleteBatchSize caps each DELETE a cleanup job issues.
+const deleteBatchSize = 1000
+
+// CleanupStore deletes rows that have aged out.
+type CleanupStore interface {
+	DeleteFinishedNotifications(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	PurgeResolvedDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// NotificationRetention returns a job body that deletes sent and
+// dead-lettered notifications older than maxAge.
+func NotificationRetention(store CleanupStore, maxAge time.Duration, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := deleteInBatches(ctx, time.Now().Add(-maxAge), store.DeleteFinishedNotifications)
+		if n > 0 {
+			logger.Info("deleted notifications past retention", zap.Int64("count", n))
+		}
+		return err
+	}
+}
+
+// DeadLetterPurge returns a job body that deletes retried and discarded DLQ
+// entries older than maxAge. Entries still pending are kept.
+func DeadLetterPurge(store CleanupStore, maxAge time.Duration, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := deleteInBatches(ctx, time.Now().Add(-maxAge), store.PurgeResolvedDeadLetters)
+		if n > 0 {
+			logger.Info("purged resolved dead letters", zap.Int64("count", n))
+		}
+		return err
+	}
+}
+
+// deleteInBatches calls del until it deletes less than a full batch or ctx
+// ends, and returns the total deleted.
+func deleteInBatches(ctx context.Context, cutoff time.Time, del func(context.Context, time.Time, int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		n, err := del(ctx, cutoff, deleteBatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < deleteBatchSize {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
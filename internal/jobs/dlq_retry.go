@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// retryBatchSize caps how many DLQ entries one auto-retry run replays.
+const retryBatchSize = 500
+
+// DeadLetterRetryStore finds DLQ entries a retry policy has made due and
+// retries them.
+type DeadLetterRetryStore interface {
+	ListDueDeadLetters(ctx context.Context, limit int) ([]uuid.UUID, error)
+	RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*db.Notification, error)
+}
+
+// DeadLetterAutoRetry returns a job body that retries DLQ entries under
+// their tenant's retry policy, the same way POST /v1/dlq/{id}/retry does.
+// One failed retry doesn't stop the rest; the run reports an error if any
+// failed.
+func DeadLetterAutoRetry(store DeadLetterRetryStore, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ids, err := store.ListDueDeadLetters(ctx, retryBatchSize)
+		if err != nil {
+			return fmt.Errorf("list due dead letters: %w", err)
+		}
+
+		var retried, failed int
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := store.RetryDeadLetter(ctx, id); err != nil {
+				logger.Warn("automatic dead letter retry failed", zap.String("dlq_id", id.String()), zap.Error(err))
+				failed++
+				continue
+			}
+			retried++
+		}
+
+		if retried > 0 {
+			logger.Info("dead letters retried by policy", zap.Int("count", retried))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d dead letters failed to retry", failed, len(ids))
+		}
+		return nil
+	}
+}
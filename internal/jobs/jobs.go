@@ -0,0 +1,166 @@
+// Package jobs runs the gateway's periodic background work — reaping stuck
+// notifications, retention, DLQ purges — on one shared runner instead of a
+// goroutine per feature.
+//
+// Every job runs on a fixed interval. Before each run the runner takes the
+// job's row in job_runs as a lock, so with several replicas each run happens
+// on exactly one of them, and the row keeps the last run's timing and error.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Job outcomes recorded in nimbus_job_runs_total.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Job is one named periodic task.
+type Job struct {
+	Name     string
+	Interval time.Duration
+
+	// Timeout bounds a single run and is also how long the lock is held, so
+	// a replica that dies mid-run frees the job once it elapses. Defaults
+	// to Interval.
+	Timeout time.Duration
+
+	Run func(ctx context.Context) error
+}
+
+// Store persists job locks and last-run records.
+type Store interface {
+	AcquireJob(ctx context.Context, name, owner string, minGap, ttl time.Duration) (bool, error)
+	FinishJob(ctx context.Context, name, owner string, took time.Duration, runErr error) error
+}
+
+// Runner schedules registered jobs.
+type Runner struct {
+	store  Store
+	owner  string
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	jobs []Job
+}
+
+// NewRunner creates a runner. owner identifies this replica in job locks.
+func NewRunner(store Store, owner string, logger *zap.Logger) *Runner {
+	return &Runner{
+		store:  store,
+		owner:  owner,
+		logger: logger.Named("jobs"),
+	}
+}
+
+// Register adds a job. It must be called before Start.
+func (r *Runner) Register(job Job) error {
+	if job.Name == "" || job.Run == nil || job.Interval <= 0 {
+		return errors.New("job needs a name, a run function and a positive interval")
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = job.Interval
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.jobs {
+		if j.Name == job.Name {
+			return fmt.Errorf("job %q already registered", job.Name)
+		}
+	}
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+// Jobs returns the registered jobs.
+func (r *Runner) Jobs() []Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Job(nil), r.jobs...)
+}
+
+// Start runs every registered job on its interval until ctx is cancelled,
+// then waits for runs in progress to return.
+func (r *Runner) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range r.Jobs() {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.runOnce(ctx, job)
+		}
+	}
+}
+
+// runOnce runs job if this replica wins its lock. Replicas tick out of
+// phase, so the gap required since the last start is a little under the
+// interval; otherwise a replica ticking a few ms early would skip a run.
+func (r *Runner) runOnce(ctx context.Context, job Job) {
+	acquired, err := r.store.AcquireJob(ctx, job.Name, r.owner, job.Interval*9/10, job.Timeout)
+	if err != nil {
+		r.logger.Warn("failed to acquire job lock", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	start := time.Now()
+	runErr := r.safeRun(runCtx, job)
+	took := time.Since(start)
+	cancel()
+
+	outcome := OutcomeSuccess
+	if runErr != nil {
+		outcome = OutcomeError
+		r.logger.Error("job failed", zap.String("job", job.Name), zap.Duration("took", took), zap.Error(runErr))
+	} else {
+		r.logger.Debug("job finished", zap.String("job", job.Name), zap.Duration("took", took))
+	}
+	metrics.RecordJobRun(job.Name, outcome, took)
+
+	// Record the outcome even if ctx was cancelled mid-run, so the lock
+	// isn't left held until it expires.
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := r.store.FinishJob(finishCtx, job.Name, r.owner, took, runErr); err != nil {
+		r.logger.Warn("failed to record job run", zap.String("job", job.Name), zap.Error(err))
+	}
+}
+
+// safeRun turns a panicking job into a failed run rather than a dead runner.
+func (r *Runner) safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type fakeStore struct {
+	acquire    bool
+	acquireErr error
+	finished   []error
+}
+
+func (f *fakeStore) AcquireJob(ctx context.Context, name, owner string, minGap, ttl time.Duration) (bool, error) {
+	return f.acquire, f.acquireErr
+}
+
+func (f *fakeStore) FinishJob(ctx context.Context, name, owner string, took time.Duration, runErr error) error {
+	f.finished = append(f.finished, runErr)
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRunner(&fakeStore{}, "test", zap.NewNop())
+	noop := func(context.Context) error { return nil }
+
+	if err := r.Register(Job{Name: "a", Interval: time.Minute, Run: noop}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register(Job{Name: "a", Interval: time.Minute, Run: noop}); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if err := r.Register(Job{Name: "b", Run: noop}); err == nil {
+		t.Error("expected an error for a zero interval")
+	}
+	if got := r.Jobs()[0].Timeout; got != time.Minute {
+		t.Errorf("expected timeout to default to the interval, got %s", got)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name         string
+		store        *fakeStore
+		run          func(context.Context) error
+		expectRun    bool
+		expectFinish []error
+	}{
+		{"runs when lock acquired", &fakeStore{acquire: true}, func(context.Context) error { return nil }, true, []error{nil}},
+		{"records errors", &fakeStore{acquire: true}, func(context.Context) error { return boom }, true, []error{boom}},
+		{"skips when another replica holds it", &fakeStore{acquire: false}, func(context.Context) error { return nil }, false, nil},
+		{"skips when the lock check fails", &fakeStore{acquireErr: errors.New("db down")}, func(context.Context) error { return nil }, false, nil},
+		{"recovers panics", &fakeStore{acquire: true}, func(context.Context) error { panic("oops") }, true, []error{errors.New("job panicked: oops")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := false
+			r := NewRunner(tt.store, "test", zap.NewNop())
+			r.runOnce(context.Background(), Job{
+				Name:     "job",
+				Interval: time.Minute,
+				Timeout:  time.Minute,
+				Run: func(ctx context.Context) error {
+					ran = true
+					return tt.run(ctx)
+				},
+			})
+
+			if ran != tt.expectRun {
+				t.Errorf("expected ran=%v, got %v", tt.expectRun, ran)
+			}
+			if len(tt.store.finished) != len(tt.expectFinish) {
+				t.Fatalf("expected %d finish records, got %d", len(tt.expectFinish), len(tt.store.finished))
+			}
+			for i, want := range tt.expectFinish {
+				got := tt.store.finished[i]
+				if (want == nil) != (got == nil) || (want != nil && want.Error() != got.Error()) {
+					t.Errorf("expected finish error %v, got %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+type fakeCleanupStore struct {
+	remaining int64
+	calls     int
+}
+
+func (f *fakeCleanupStore) DeleteFinishedNotifications(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	f.calls++
+	n := min(f.remaining, int64(limit))
+	f.remaining -= n
+	return n, nil
+}
+
+func (f *fakeCleanupStore) PurgeResolvedDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return f.DeleteFinishedNotifications(ctx, cutoff, limit)
+}
+
+func TestNotificationRetention_DeletesInBatches(t *testing.T) {
+	store := &fakeCleanupStore{remaining: 2*deleteBatchSize + 10}
+	if err := NotificationRetention(store, 24*time.Hour, zap.NewNop())(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.remaining != 0 || store.calls != 3 {
+		t.Errorf("expected everything deleted in 3 batches, got %d left after %d calls", store.remaining, store.calls)
+	}
+}
+
+type fakeRetryStore struct {
+	due     []uuid.UUID
+	failing uuid.UUID
+	retried []uuid.UUID
+}
+
+func (f *fakeRetryStore) ListDueDeadLetters(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return f.due[:min(len(f.due), limit)], nil
+}
+
+func (f *fakeRetryStore) RetryDeadLetter(ctx context.Context, dlqID uuid.UUID) (*db.Notification, error) {
+	if dlqID == f.failing {
+		return nil, errors.New("dead letter already processed: retried")
+	}
+	f.retried = append(f.retried, dlqID)
+	return &db.Notification{ID: uuid.New()}, nil
+}
+
+func TestDeadLetterAutoRetry(t *testing.T) {
+	store := &fakeRetryStore{due: []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}}
+	store.failing = store.due[1]
+
+	err := DeadLetterAutoRetry(store, zap.NewNop())(context.Background())
+	if err == nil {
+		t.Error("expected the run to report the failed retry")
+	}
+	if len(store.retried) != 2 || store.retried[0] != store.due[0] || store.retried[1] != store.due[2] {
+		t.Errorf("expected the other two entries retried, got %v", store.retried)
+	}
+}
@@ -0,0 +1,118 @@
+// Package maintenance provides the switch that puts Nimbus into maintenance
+// mode: reads keep working, writes are refused with 503, and the worker stops
+// claiming new notifications until the switch is turned off.
+package maintenance
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRetryAfter is what we tell clients when no estimate is configured.
+const DefaultRetryAfter = 60 * time.Second
+
+// Status is a point-in-time snapshot of the switch, for the admin endpoint.
+type Status struct {
+	Enabled    bool       `json:"enabled"`
+	Reason     string     `json:"reason,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+	RetryAfter int        `json:"retry_after_seconds"`
+}
+
+// Mode is a process-wide maintenance switch. It is safe for concurrent use:
+// the HTTP middleware and the worker poll loop both read it on every tick.
+//
+// The switch is per process. To put a whole fleet into maintenance, set
+// MAINTENANCE_MODE=true on the deployment, or call the admin endpoint on
+// every replica.
+type Mode struct {
+	mu         sync.RWMutex
+	logger     *zap.Logger
+	enabled    bool
+	reason     string
+	since      time.Time
+	retryAfter time.Duration
+}
+
+// New creates a maintenance switch, optionally already enabled (for
+// deployments started with MAINTENANCE_MODE=true).
+func New(enabled bool, retryAfter time.Duration, logger *zap.Logger) *Mode {
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+
+	m := &Mode{
+		logger:     logger,
+		retryAfter: retryAfter,
+	}
+	if enabled {
+		m.enabled = true
+		m.reason = "enabled at startup"
+		m.since = time.Now()
+	}
+	return m
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Mode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// Enable turns maintenance mode on. Calling it while already enabled only
+// updates the reason; Since keeps the original start time.
+func (m *Mode) Enable(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		m.enabled = true
+		m.since = time.Now()
+	}
+	m.reason = reason
+
+	m.logger.Warn("maintenance mode enabled", zap.String("reason", reason))
+}
+
+// Disable turns maintenance mode off.
+func (m *Mode) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		return
+	}
+	m.logger.Info("maintenance mode disabled",
+		zap.Duration("duration", time.Since(m.since)),
+	)
+	m.enabled = false
+	m.reason = ""
+	m.since = time.Time{}
+}
+
+// RetryAfter is the hint sent to clients in the Retry-After header.
+func (m *Mode) RetryAfter() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.retryAfter
+}
+
+// Status returns a snapshot of the switch.
+func (m *Mode) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s := Status{
+		Enabled:    m.enabled,
+		Reason:     m.reason,
+		RetryAfter: int(m.retryAfter.Seconds()),
+	}
+	if m.enabled {
+		since := m.since
+		s.Since = &since
+	}
+	return s
+}
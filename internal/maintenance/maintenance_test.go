@@ -0,0 +1,62 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestMode_StartsDisabled(t *testing.T) {
+	m := New(false, 0, zap.NewNop())
+
+	if m.Enabled() {
+		t.Fatal("expected maintenance mode to start disabled")
+	}
+	if m.RetryAfter() != DefaultRetryAfter {
+		t.Errorf("expected default retry-after %s, got %s", DefaultRetryAfter, m.RetryAfter())
+	}
+	if s := m.Status(); s.Since != nil {
+		t.Error("expected no Since while disabled")
+	}
+}
+
+func TestMode_EnabledAtStartup(t *testing.T) {
+	m := New(true, 2*time.Minute, zap.NewNop())
+
+	s := m.Status()
+	if !s.Enabled || s.Since == nil {
+		t.Fatalf("expected enabled status with Since, got %+v", s)
+	}
+	if s.RetryAfter != 120 {
+		t.Errorf("expected retry_after 120, got %d", s.RetryAfter)
+	}
+}
+
+func TestMode_EnableDisable(t *testing.T) {
+	m := New(false, time.Minute, zap.NewNop())
+
+	m.Enable("schema migration")
+	first := m.Status()
+	if !first.Enabled || first.Reason != "schema migration" {
+		t.Fatalf("unexpected status after Enable: %+v", first)
+	}
+
+	// Re-enabling updates the reason but keeps the original start time.
+	m.Enable("provider incident")
+	second := m.Status()
+	if second.Reason != "provider incident" {
+		t.Errorf("expected updated reason, got %q", second.Reason)
+	}
+	if !second.Since.Equal(*first.Since) {
+		t.Error("expected Since to be preserved across re-enable")
+	}
+
+	m.Disable()
+	if m.Enabled() {
+		t.Fatal("expected maintenance mode to be disabled")
+	}
+	if s := m.Status(); s.Reason != "" || s.Since != nil {
+		t.Errorf("expected cleared status after Disable, got %+v", s)
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DogStatsDConfig configures the DogStatsD sink.
+type DogStatsDConfig struct {
+	Addr   string   // Agent address, e.g. 127.0.0.1:8125
+	Prefix string   // Prepended to every metric name, e.g. "nimbus."
+	Tags   []string // Constant tags added to every metric, e.g. "env:prod"
+}
+
+// DogStatsD sends metrics to a Datadog agent over UDP. Each observation is
+// one datagram; send errors are dropped, since a missing agent must never
+// slow down or fail a request.
+type DogStatsD struct {
+	conn   net.Conn
+	prefix string
+	tags   string
+}
+
+// NewDogStatsD dials the agent. UDP dialing doesn't contact the agent, so
+// this only fails on a malformed or unresolvable address.
+func NewDogStatsD(cfg DogStatsDConfig) (*DogStatsD, error) {
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial dogstatsd %s: %w", cfg.Addr, err)
+	}
+	tags := make([]string, len(cfg.Tags))
+	for i, t := range cfg.Tags {
+		tags[i] = sanitizeTag(t)
+	}
+	return &DogStatsD{conn: conn, prefix: cfg.Prefix, tags: strings.Join(tags, ",")}, nil
+}
+
+// Close closes the UDP socket.
+func (d *DogStatsD) Close() error {
+	return d.conn.Close()
+}
+
+func (d *DogStatsD) IncCounter(name string, labels Labels) {
+	d.send(name, "1", "c", labels)
+}
+
+func (d *DogStatsD) SetGauge(name string, value float64, labels Labels) {
+	d.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", labels)
+}
+
+func (d *DogStatsD) Observe(name string, value float64, labels Labels) {
+	d.send(name, strconv.FormatFloat(value, 'f', -1, 64), "h", labels)
+}
+
+func (d *DogStatsD) send(name, value, kind string, labels Labels) {
+	_, _ = d.conn.Write([]byte(d.format(name, value, kind, labels)))
+}
+
+// format builds one datagram: <prefix><name>:<value>|<kind>|#<tags>.
+func (d *DogStatsD) format(name, value, kind string, labels Labels) string {
+	var b strings.Builder
+	b.WriteString(d.prefix)
+	b.WriteString(statsdName(name))
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+
+	tags := d.tags
+	if len(labels) > 0 {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys)+1)
+		if tags != "" {
+			pairs = append(pairs, tags)
+		}
+		for _, k := range keys {
+			pairs = append(pairs, sanitizeTag(k+":"+labels[k]))
+		}
+		tags = strings.Join(pairs, ",")
+	}
+	if tags != "" {
+		b.WriteString("|#")
+		b.WriteString(tags)
+	}
+	return b.String()
+}
+
+// statsdName drops the Prometheus namespace and _total suffix, so
+// nimbus_http_requests_total becomes http_requests; the configured prefix
+// supplies the namespace instead.
+func statsdName(name string) string {
+	name = strings.TrimPrefix(name, "nimbus_")
+	return strings.TrimSuffix(name, "_total")
+}
+
+// sanitizeTag replaces the characters that delimit the DogStatsD wire format.
+func sanitizeTag(t string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ',', '|', '#', '\n':
+			return '_'
+		}
+		return r
+	}, t)
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDogStatsD_Format(t *testing.T) {
+	d := &DogStatsD{prefix: "nimbus.", tags: "env:test"}
+
+	tests := []struct {
+		name     string
+		metric   string
+		value    string
+		kind     string
+		labels   Labels
+		expected string
+	}{
+		{"counter with labels", nameNotificationsEnqueued, "1", "c", Labels{"tenant_id": "t1", "channel": "email"},
+			"nimbus.notifications_enqueued:1|c|#env:test,channel:email,tenant_id:t1"},
+		{"gauge without labels", nameSQSMessagesInFlight, "3", "g", nil,
+			"nimbus.sqs_messages_in_flight:3|g|#env:test"},
+		{"delimiters in values", nameHTTPRequests, "1", "c", Labels{"path": "/a,b|c"},
+			"nimbus.http_requests:1|c|#env:test,path:/a_b_c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.format(tt.metric, tt.value, tt.kind, tt.labels); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDogStatsD_SendsOverUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer pc.Close()
+
+	d, err := NewDogStatsD(DogStatsDConfig{Addr: pc.LocalAddr().String(), Prefix: "nimbus."})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer d.Close()
+
+	d.Observe(nameSenderDuration, 0.25, Labels{"provider": "ses"})
+
+	buf := make([]byte, 512)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("expected a datagram: %v", err)
+	}
+	if got, want := string(buf[:n]), "nimbus.sender_duration_seconds:0.25|h|#provider:ses"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+type recordingSink struct{ counters []string }
+
+func (r *recordingSink) IncCounter(name string, _ Labels) { r.counters = append(r.counters, name) }
+func (r *recordingSink) SetGauge(string, float64, Labels) {}
+func (r *recordingSink) Observe(string, float64, Labels)  {}
+
+func TestSetSinks_FansOut(t *testing.T) {
+	t.Cleanup(func() { SetSinks(Prometheus()) })
+
+	a, b := &recordingSink{}, &recordingSink{}
+	SetSinks(Prometheus(), a, b)
+	RecordWorkerPanic("email")
+
+	if len(a.counters) != 1 || len(b.counters) != 1 || a.counters[0] != nameWorkerPanics {
+		t.Errorf("expected both sinks to see one counter, got %v and %v", a.counters, b.counters)
+	}
+}
@@ -13,7 +13,7 @@ import (
 var (
 	httpRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "nimbus_http_requests_total",
+			Name: nameHTTPRequests,
 			Help: "Total HTTP requests by method, path, and status",
 		},
 		[]string{"method", "path", "status"},
@@ -21,7 +21,7 @@ var (
 
 	httpRequestDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "nimbus_http_request_duration_seconds",
+			Name:    nameHTTPRequestDuration,
 			Help:    "HTTP request latency distribution",
 			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1},
 		},
@@ -30,7 +30,7 @@ var (
 
 	notificationsEnqueued = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "nimbus_notifications_enqueued_total",
+			Name: nameNotificationsEnqueued,
 			Help: "Total notifications enqueued by tenant and channel",
 		},
 		[]string{"tenant_id", "channel"},
@@ -38,7 +38,7 @@ var (
 
 	notificationsProcessed = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "nimbus_notifications_processed_total",
+			Name: nameNotificationsProcessed,
 			Help: "Total notifications processed by status",
 		},
 		[]string{"status", "channel"},
@@ -46,47 +46,303 @@ var (
 
 	notificationLatency = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "nimbus_notification_latency_seconds",
+			Name:    nameNotificationLatency,
 			Help:    "Time from enqueue to delivery",
 			Buckets: []float64{.1, .5, 1, 2, 5, 10, 30, 60},
 		},
 		[]string{"channel"},
 	)
 
-	sqsMessagesInFlight = promauto.NewGauge(
+	notificationSLA = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationSLA,
+			Help: "Notifications with a delivery SLA by outcome (met, breached); dead-lettered ones count as breached",
+		},
+		[]string{"tenant_id", "channel", "outcome"},
+	)
+
+	deadLetters = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameDeadLetters,
+			Help: "Notifications moved to the dead letter queue, by channel and reason code",
+		},
+		[]string{"channel", "reason"},
+	)
+
+	recipientsSuppressed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameRecipientsSuppressed,
+			Help: "Sends skipped because the recipient was invalidated by a hard bounce or provider rejection, by channel",
+		},
+		[]string{"channel"},
+	)
+
+	notificationsCollapsed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationsCollapsed,
+			Help: "Notifications collapsed into an earlier delivery in their group instead of being sent, by channel",
+		},
+		[]string{"channel"},
+	)
+
+	senderDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    nameSenderDuration,
+			Help:    "Time spent in a provider Send call, by provider, channel, and outcome",
+			Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"provider", "channel", "outcome"},
+	)
+
+	workerPanics = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameWorkerPanics,
+			Help: "Panics recovered while processing a notification, by channel",
+		},
+		[]string{"channel"},
+	)
+
+	notificationsReaped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationsReaped,
+			Help: "Notifications recovered from a stuck 'processing' state, by channel",
+		},
+		[]string{"channel"},
+	)
+
+	workerLastPoll = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameWorkerLastPoll,
+			Help: "Unix time of the worker's last poll; alert when time() minus this grows",
+		},
+		nil,
+	)
+
+	workerBatchSize = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameWorkerBatchSize,
+			Help: "How many notifications the worker claims per poll",
+		},
+		nil,
+	)
+
+	queueBacklog = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameQueueBacklog,
+			Help: "Notifications due to send across all channels",
+		},
+		nil,
+	)
+
+	queueOldestDueAge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameQueueOldestDueAge,
+			Help: "How long the oldest due notification has been waiting, 0 when none are due",
+		},
+		nil,
+	)
+
+	queueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameQueueDepth,
+			Help: "Notifications due to send by channel",
+		},
+		[]string{"channel"},
+	)
+
+	jobRuns = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameJobRuns,
+			Help: "Background job runs by job and outcome",
+		},
+		[]string{"job", "outcome"},
+	)
+
+	jobDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    nameJobDuration,
+			Help:    "Background job run duration",
+			Buckets: []float64{.01, .1, .5, 1, 5, 15, 60, 300},
+		},
+		[]string{"job"},
+	)
+
+	jobLastSuccess = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameJobLastSuccess,
+			Help: "Unix time of each background job's last successful run on this replica",
+		},
+		[]string{"job"},
+	)
+
+	deliveryEvents = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameDeliveryEvents,
+			Help: "Delivery, bounce and complaint events received from providers",
+		},
+		[]string{"provider", "type"},
+	)
+
+	reputationActions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameReputationActions,
+			Help: "Send limits applied or lifted by the reputation guard, by action",
+		},
+		[]string{"action"},
+	)
+
+	notificationsThrottled = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationsThrottled,
+			Help: "Sends deferred because the tenant is throttled, by channel",
+		},
+		[]string{"channel"},
+	)
+
+	notificationsFrequencyCapped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationsFrequencyCapped,
+			Help: "Sends deferred or dropped because the user reached the tenant's frequency cap, by channel and action",
+		},
+		[]string{"channel", "action"},
+	)
+
+	notificationsPreferences = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationsPreferences,
+			Help: "Sends dropped because the user opted out of the category, or deferred for its quiet hours, by channel and action",
+		},
+		[]string{"channel", "action"},
+	)
+
+	notificationsShed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameNotificationsShed,
+			Help: "Creates refused because the delivery backlog is over its threshold, by channel",
+		},
+		[]string{"channel"},
+	)
+
+	budgetAlerts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameBudgetAlerts,
+			Help: "Tenant budget alerts sent, by threshold percent",
+		},
+		[]string{"threshold"},
+	)
+
+	lifecycleEvents = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameLifecycleEvents,
+			Help: "Notification lifecycle events published to EventBridge, by type and outcome (published, dropped, failed)",
+		},
+		[]string{"type", "outcome"},
+	)
+
+	archiveWrites = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameArchiveWrites,
+			Help: "Delivered messages archived to S3, by channel and outcome (success, error)",
+		},
+		[]string{"channel", "outcome"},
+	)
+
+	analyticsExports = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameAnalyticsExports,
+			Help: "Analytics warehouse inserts by table and outcome (exported, failed), plus rows dropped on a full buffer (dropped)",
+		},
+		[]string{"table", "outcome"},
+	)
+
+	canarySends = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameCanarySends,
+			Help: "Sends on a canaried channel by variant (baseline, canary) and outcome (success, error)",
+		},
+		[]string{"channel", "variant", "outcome"},
+	)
+
+	canaryActive = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "nimbus_sqs_messages_in_flight",
+			Name: nameCanaryActive,
+			Help: "1 while a channel's canary takes traffic, 0 once it has been rolled back",
+		},
+		[]string{"channel"},
+	)
+
+	shadowSends = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: nameShadowSends,
+			Help: "Sends mirrored to a shadow provider by shadow outcome (success, error, timeout, skipped) and the primary's outcome",
+		},
+		[]string{"channel", "outcome", "primary_outcome"},
+	)
+
+	deliveryCost = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    nameDeliveryCost,
+			Help:    "Estimated provider cost per delivery; _sum is total spend for chargeback",
+			Buckets: []float64{0, .0001, .001, .01, .05, .1, .5},
+		},
+		[]string{"tenant_id", "channel", "provider"},
+	)
+
+	sqsMessagesInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameSQSMessagesInFlight,
 			Help: "Current messages being processed from SQS",
 		},
+		nil,
 	)
 
-	idempotencyHits = promauto.NewCounter(
+	idempotencyHits = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "nimbus_idempotency_hits_total",
+			Name: nameIdempotencyHits,
 			Help: "Requests served from idempotency cache",
 		},
+		nil,
 	)
 
 	rateLimitRejections = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "nimbus_rate_limit_rejections_total",
+			Name: nameRateLimitRejections,
 			Help: "Requests rejected by rate limiter",
 		},
 		[]string{"tenant_id"},
 	)
 
-	dbConnectionsActive = promauto.NewGauge(
+	rateLimitDegraded = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "nimbus_db_connections_active",
+			Name: nameRateLimitDegraded,
+			Help: "1 while a rate limiter can't reach Redis and enforces its in-memory fallback or fails open",
+		},
+		[]string{"limiter"},
+	)
+
+	dbQueryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    nameDBQueryDuration,
+			Help:    "Postgres query latency by query name and outcome (ok, error)",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+		},
+		[]string{"query", "outcome"},
+	)
+
+	dbConnectionsActive = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: nameDBConnectionsActive,
 			Help: "Active database connections",
 		},
+		nil,
 	)
 
-	redisConnectionsActive = promauto.NewGauge(
+	redisConnectionsActive = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "nimbus_redis_connections_active",
+			Name: nameRedisConnectionsActive,
 			Help: "Active Redis connections",
 		},
+		nil,
 	)
 )
 
@@ -97,48 +353,218 @@ func Handler() http.Handler {
 
 // RecordRequest records HTTP request metrics
 func RecordRequest(method, path string, status int, duration time.Duration) {
-	httpRequestsTotal.WithLabelValues(method, path, strconv.Itoa(status)).Inc()
-	httpRequestDuration.WithLabelValues(method, path).Observe(duration.Seconds())
+	incCounter(nameHTTPRequests, Labels{"method": method, "path": path, "status": strconv.Itoa(status)})
+	observe(nameHTTPRequestDuration, duration.Seconds(), Labels{"method": method, "path": path})
 }
 
-// RecordNotificationEnqueued records a notification enqueue event
+// RecordNotificationEnqueued records a notification enqueue event. The
+// tenant label follows the policy set by SetTenantLabels.
 func RecordNotificationEnqueued(tenantID, channel string) {
-	notificationsEnqueued.WithLabelValues(tenantID, channel).Inc()
+	incCounter(nameNotificationsEnqueued, Labels{"tenant_id": tenantLabel(tenantID), "channel": channel})
 }
 
 // RecordNotificationProcessed records notification processing result
 func RecordNotificationProcessed(status, channel string) {
-	notificationsProcessed.WithLabelValues(status, channel).Inc()
+	incCounter(nameNotificationsProcessed, Labels{"status": status, "channel": channel})
 }
 
 // RecordNotificationLatency records end-to-end notification delivery time
 func RecordNotificationLatency(channel string, latency time.Duration) {
-	notificationLatency.WithLabelValues(channel).Observe(latency.Seconds())
+	observe(nameNotificationLatency, latency.Seconds(), Labels{"channel": channel})
+}
+
+// RecordNotificationSLA records whether a notification with a delivery SLA
+// met it. The tenant label follows the policy set by SetTenantLabels.
+func RecordNotificationSLA(tenantID, channel string, breached bool) {
+	outcome := "met"
+	if breached {
+		outcome = "breached"
+	}
+	incCounter(nameNotificationSLA, Labels{"tenant_id": tenantLabel(tenantID), "channel": channel, "outcome": outcome})
+}
+
+// RecordDeadLettered records a notification moved to the dead letter queue
+func RecordDeadLettered(channel, reason string) {
+	incCounter(nameDeadLetters, Labels{"channel": channel, "reason": reason})
+}
+
+// RecordRecipientSuppressed records a send skipped because its recipient
+// was invalidated
+func RecordRecipientSuppressed(channel string) {
+	incCounter(nameRecipientsSuppressed, Labels{"channel": channel})
+}
+
+// RecordNotificationCollapsed records a notification collapsed into an
+// earlier delivery in its group
+func RecordNotificationCollapsed(channel string) {
+	incCounter(nameNotificationsCollapsed, Labels{"channel": channel})
+}
+
+// RecordSenderDuration records how long one provider Send call took
+func RecordSenderDuration(provider, channel, outcome string, duration time.Duration) {
+	observe(nameSenderDuration, duration.Seconds(), Labels{"provider": provider, "channel": channel, "outcome": outcome})
+}
+
+// RecordWorkerPanic records a panic recovered by the worker
+func RecordWorkerPanic(channel string) {
+	incCounter(nameWorkerPanics, Labels{"channel": channel})
+}
+
+// RecordNotificationReaped records a notification recovered from a worker
+// that died mid-send
+func RecordNotificationReaped(channel string) {
+	incCounter(nameNotificationsReaped, Labels{"channel": channel})
+}
+
+// SetWorkerLastPoll records when the worker loop last polled
+func SetWorkerLastPoll(t time.Time) {
+	setGauge(nameWorkerLastPoll, float64(t.Unix()), nil)
+}
+
+// SetWorkerBatchSize records the worker's current claim size
+func SetWorkerBatchSize(size int) {
+	setGauge(nameWorkerBatchSize, float64(size), nil)
+}
+
+// SetQueueBacklog records how many notifications are due and how long the
+// oldest has waited
+func SetQueueBacklog(due int64, oldestAge time.Duration) {
+	setGauge(nameQueueBacklog, float64(due), nil)
+	setGauge(nameQueueOldestDueAge, oldestAge.Seconds(), nil)
+}
+
+// SetQueueDepth records how many notifications are due on one channel
+func SetQueueDepth(channel string, due int64) {
+	setGauge(nameQueueDepth, float64(due), Labels{"channel": channel})
+}
+
+// RecordJobRun records one background job run
+func RecordJobRun(job, outcome string, duration time.Duration) {
+	incCounter(nameJobRuns, Labels{"job": job, "outcome": outcome})
+	observe(nameJobDuration, duration.Seconds(), Labels{"job": job})
+	if outcome == "success" {
+		setGauge(nameJobLastSuccess, float64(time.Now().Unix()), Labels{"job": job})
+	}
+}
+
+// RecordDeliveryEvent records a provider-reported delivery event
+func RecordDeliveryEvent(provider, eventType string) {
+	incCounter(nameDeliveryEvents, Labels{"provider": provider, "type": eventType})
+}
+
+// RecordReputationAction records the reputation guard throttling, pausing
+// or lifting a tenant
+func RecordReputationAction(action string) {
+	incCounter(nameReputationActions, Labels{"action": action})
+}
+
+// RecordNotificationThrottled records a send deferred by a tenant throttle
+func RecordNotificationThrottled(channel string) {
+	incCounter(nameNotificationsThrottled, Labels{"channel": channel})
+}
+
+// RecordNotificationFrequencyCapped records a send deferred or dropped by a
+// frequency cap
+func RecordNotificationFrequencyCapped(channel, action string) {
+	incCounter(nameNotificationsFrequencyCapped, Labels{"channel": channel, "action": action})
+}
+
+// RecordNotificationPreference records a send dropped by a category opt-out
+// (action "opted_out") or deferred for quiet hours ("quiet_hours")
+func RecordNotificationPreference(channel, action string) {
+	incCounter(nameNotificationsPreferences, Labels{"channel": channel, "action": action})
+}
+
+// RecordNotificationShed records a create refused under backpressure
+func RecordNotificationShed(channel string) {
+	incCounter(nameNotificationsShed, Labels{"channel": channel})
+}
+
+// RecordBudgetAlert records a tenant budget alert at threshold percent
+func RecordBudgetAlert(threshold int) {
+	incCounter(nameBudgetAlerts, Labels{"threshold": strconv.Itoa(threshold)})
+}
+
+// RecordLifecycleEvent records the outcome of publishing one lifecycle event
+func RecordLifecycleEvent(eventType, outcome string) {
+	incCounter(nameLifecycleEvents, Labels{"type": eventType, "outcome": outcome})
+}
+
+// RecordArchiveWrite records the outcome of archiving one delivered message
+func RecordArchiveWrite(channel, outcome string) {
+	incCounter(nameArchiveWrites, Labels{"channel": channel, "outcome": outcome})
+}
+
+// RecordAnalyticsExport records one analytics insert, or one dropped row
+func RecordAnalyticsExport(table, outcome string) {
+	incCounter(nameAnalyticsExports, Labels{"table": table, "outcome": outcome})
+}
+
+// RecordCanarySend records one send on a canaried channel
+func RecordCanarySend(channel, variant, outcome string) {
+	incCounter(nameCanarySends, Labels{"channel": channel, "variant": variant, "outcome": outcome})
+}
+
+// SetCanaryActive records whether a channel's canary is taking traffic
+func SetCanaryActive(channel string, active bool) {
+	v := 0.0
+	if active {
+		v = 1
+	}
+	setGauge(nameCanaryActive, v, Labels{"channel": channel})
+}
+
+// RecordShadowSend records one mirrored send and the outcome of the
+// primary send it mirrored
+func RecordShadowSend(channel, outcome, primaryOutcome string) {
+	incCounter(nameShadowSends, Labels{"channel": channel, "outcome": outcome, "primary_outcome": primaryOutcome})
+}
+
+// RecordDeliveryCost records the estimated cost of one delivery. The
+// tenant label follows the policy set by SetTenantLabels.
+func RecordDeliveryCost(tenantID, channel, provider string, cost float64) {
+	observe(nameDeliveryCost, cost, Labels{"tenant_id": tenantLabel(tenantID), "channel": channel, "provider": provider})
 }
 
 // SetSQSMessagesInFlight sets the current in-flight message count
 func SetSQSMessagesInFlight(count int) {
-	sqsMessagesInFlight.Set(float64(count))
+	setGauge(nameSQSMessagesInFlight, float64(count), nil)
 }
 
 // RecordIdempotencyHit records a cache hit for idempotency
 func RecordIdempotencyHit() {
-	idempotencyHits.Inc()
+	incCounter(nameIdempotencyHits, nil)
 }
 
-// RecordRateLimitRejection records a rate limit rejection
+// RecordRateLimitRejection records a rate limit rejection. The tenant
+// label follows the policy set by SetTenantLabels.
 func RecordRateLimitRejection(tenantID string) {
-	rateLimitRejections.WithLabelValues(tenantID).Inc()
+	incCounter(nameRateLimitRejections, Labels{"tenant_id": tenantLabel(tenantID)})
+}
+
+// SetRateLimitDegraded records whether the named rate limiter has lost
+// Redis and is running on its in-memory fallback or failing open
+func SetRateLimitDegraded(limiter string, degraded bool) {
+	v := 0.0
+	if degraded {
+		v = 1
+	}
+	setGauge(nameRateLimitDegraded, v, Labels{"limiter": limiter})
+}
+
+// RecordDBQuery records the duration of one Postgres query
+func RecordDBQuery(query, outcome string, duration time.Duration) {
+	observe(nameDBQueryDuration, duration.Seconds(), Labels{"query": query, "outcome": outcome})
 }
 
 // SetDBConnections sets active database connection count
 func SetDBConnections(count int) {
-	dbConnectionsActive.Set(float64(count))
+	setGauge(nameDBConnectionsActive, float64(count), nil)
 }
 
 // SetRedisConnections sets active Redis connection count
 func SetRedisConnections(count int) {
-	redisConnectionsActive.Set(float64(count))
+	setGauge(nameRedisConnectionsActive, float64(count), nil)
 }
 
 // responseWriter wraps http.ResponseWriter to capture status code
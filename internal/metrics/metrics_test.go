@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -28,6 +29,39 @@ func TestRecordNotificationLatency(t *testing.T) {
 	RecordNotificationLatency("sms", 200*time.Millisecond)
 }
 
+func TestRecordSenderDuration(t *testing.T) {
+	RecordSenderDuration("ses", "email", "success", 120*time.Millisecond)
+	RecordSenderDuration("webhook", "webhook", "timeout", 30*time.Second)
+}
+
+func TestRecordWorkerPanic(t *testing.T) {
+	RecordWorkerPanic("email")
+	RecordWorkerPanic("webhook")
+}
+
+func TestRecordNotificationReaped(t *testing.T) {
+	RecordNotificationReaped("email")
+}
+
+func TestSetWorkerLastPoll(t *testing.T) {
+	SetWorkerLastPoll(time.Now())
+}
+
+func TestSetWorkerBatchSize(t *testing.T) {
+	SetWorkerBatchSize(12)
+}
+
+func TestSetQueueBacklog(t *testing.T) {
+	SetQueueBacklog(120, 45*time.Second)
+	SetQueueDepth("email", 100)
+	SetQueueDepth("sms", 20)
+}
+
+func TestRecordJobRun(t *testing.T) {
+	RecordJobRun("stuck-reaper", "success", 20*time.Millisecond)
+	RecordJobRun("dlq-purge", "error", time.Second)
+}
+
 func TestSetSQSMessagesInFlight(t *testing.T) {
 	SetSQSMessagesInFlight(10)
 	SetSQSMessagesInFlight(5)
@@ -118,3 +152,61 @@ func TestResponseWriter_ExplicitStatus(t *testing.T) {
 		t.Errorf("expected status 404, got %d", rw.status)
 	}
 }
+
+func TestTenantLabel(t *testing.T) {
+	t.Cleanup(func() { tenantLabels.Store(nil) })
+
+	tests := []struct {
+		name     string
+		cfg      TenantLabelConfig
+		tenantID string
+		expected string
+	}{
+		{"raw", TenantLabelConfig{Mode: TenantLabelsRaw}, "tenant-1", "tenant-1"},
+		{"allowlisted", TenantLabelConfig{Mode: TenantLabelsAllowlist, Allowlist: []string{"tenant-1"}}, "tenant-1", "tenant-1"},
+		{"not allowlisted", TenantLabelConfig{Mode: TenantLabelsAllowlist, Allowlist: []string{"tenant-1"}}, "tenant-2", TenantLabelOther},
+		{"single bucket", TenantLabelConfig{Mode: TenantLabelsBucket, Buckets: 1}, "tenant-1", "bucket-00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetTenantLabels(tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tenantLabel(tt.tenantID); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTenantLabel_BucketsAreStable(t *testing.T) {
+	t.Cleanup(func() { tenantLabels.Store(nil) })
+
+	if err := SetTenantLabels(TenantLabelConfig{Mode: TenantLabelsBucket, Buckets: 16}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("tenant-%d", i)
+		label := tenantLabel(id)
+		if label != tenantLabel(id) {
+			t.Fatalf("label for %s is not stable", id)
+		}
+		seen[label] = true
+	}
+	if len(seen) > 16 {
+		t.Errorf("expected at most 16 distinct labels, got %d", len(seen))
+	}
+}
+
+func TestSetTenantLabels_Invalid(t *testing.T) {
+	t.Cleanup(func() { tenantLabels.Store(nil) })
+
+	if err := SetTenantLabels(TenantLabelConfig{Mode: TenantLabelsBucket}); err == nil {
+		t.Error("expected an error for zero buckets")
+	}
+	if err := SetTenantLabels(TenantLabelConfig{Mode: "hashed"}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric names. These are the Prometheus series names; other sinks derive
+// their own naming from them.
+const (
+	nameHTTPRequests                 = "nimbus_http_requests_total"
+	nameHTTPRequestDuration          = "nimbus_http_request_duration_seconds"
+	nameNotificationsEnqueued        = "nimbus_notifications_enqueued_total"
+	nameNotificationsProcessed       = "nimbus_notifications_processed_total"
+	nameNotificationLatency          = "nimbus_notification_latency_seconds"
+	nameNotificationSLA              = "nimbus_notification_sla_total"
+	nameDeadLetters                  = "nimbus_dead_letters_total"
+	nameRecipientsSuppressed         = "nimbus_recipients_suppressed_total"
+	nameNotificationsCollapsed       = "nimbus_notifications_collapsed_total"
+	nameSenderDuration               = "nimbus_sender_duration_seconds"
+	nameWorkerPanics                 = "nimbus_worker_panics_total"
+	nameNotificationsReaped          = "nimbus_notifications_reaped_total"
+	nameWorkerLastPoll               = "nimbus_worker_last_poll_timestamp_seconds"
+	nameWorkerBatchSize              = "nimbus_worker_batch_size"
+	nameQueueBacklog                 = "nimbus_queue_backlog"
+	nameQueueOldestDueAge            = "nimbus_queue_oldest_due_age_seconds"
+	nameQueueDepth                   = "nimbus_queue_depth"
+	nameJobRuns                      = "nimbus_job_runs_total"
+	nameDeliveryEvents               = "nimbus_delivery_events_total"
+	nameReputationActions            = "nimbus_reputation_actions_total"
+	nameNotificationsThrottled       = "nimbus_notifications_throttled_total"
+	nameNotificationsShed            = "nimbus_notifications_shed_total"
+	nameNotificationsFrequencyCapped = "nimbus_notifications_frequency_capped_total"
+	nameNotificationsPreferences     = "nimbus_notifications_preferences_total"
+	nameDeliveryCost                 = "nimbus_delivery_cost_dollars"
+	nameBudgetAlerts                 = "nimbus_budget_alerts_total"
+	nameLifecycleEvents              = "nimbus_lifecycle_events_total"
+	nameArchiveWrites                = "nimbus_archive_writes_total"
+	nameAnalyticsExports             = "nimbus_analytics_exports_total"
+	nameCanarySends                  = "nimbus_canary_sends_total"
+	nameCanaryActive                 = "nimbus_canary_active"
+	nameShadowSends                  = "nimbus_shadow_sends_total"
+	nameJobDuration                  = "nimbus_job_duration_seconds"
+	nameJobLastSuccess               = "nimbus_job_last_success_timestamp_seconds"
+	nameSQSMessagesInFlight          = "nimbus_sqs_messages_in_flight"
+	nameIdempotencyHits              = "nimbus_idempotency_hits_total"
+	nameRateLimitRejections          = "nimbus_rate_limit_rejections_total"
+	nameRateLimitDegraded            = "nimbus_rate_limit_degraded"
+	nameDBConnectionsActive          = "nimbus_db_connections_active"
+	nameDBQueryDuration              = "nimbus_db_query_duration_seconds"
+	nameRedisConnectionsActive       = "nimbus_redis_connections_active"
+)
+
+// Labels are the label (or tag) values for one observation.
+type Labels map[string]string
+
+// Sink is a metrics backend. Every Record*/Set* call in this package is
+// fanned out to each installed sink, so Prometheus and DogStatsD can run
+// side by side. Implementations must be safe for concurrent use and must
+// not block: metrics are recorded on the request and send paths.
+type Sink interface {
+	IncCounter(name string, labels Labels)
+	SetGauge(name string, value float64, labels Labels)
+	Observe(name string, value float64, labels Labels)
+}
+
+var sinks atomic.Pointer[[]Sink]
+
+func init() {
+	SetSinks(Prometheus())
+}
+
+// SetSinks replaces the installed sinks. Call it once at startup; the
+// default is Prometheus alone. With no sinks, metrics are discarded.
+func SetSinks(s ...Sink) {
+	sinks.Store(&s)
+}
+
+func incCounter(name string, labels Labels) {
+	for _, s := range *sinks.Load() {
+		s.IncCounter(name, labels)
+	}
+}
+
+func setGauge(name string, value float64, labels Labels) {
+	for _, s := range *sinks.Load() {
+		s.SetGauge(name, value, labels)
+	}
+}
+
+func observe(name string, value float64, labels Labels) {
+	for _, s := range *sinks.Load() {
+		s.Observe(name, value, labels)
+	}
+}
+
+// prometheusSink updates the collectors registered in metrics.go, which are
+// served by Handler.
+type prometheusSink struct {
+	counters   map[string]*prometheus.CounterVec
+	gauges     map[string]*prometheus.GaugeVec
+	histograms map[string]*prometheus.HistogramVec
+}
+
+// Prometheus returns the sink backing the /metrics endpoint.
+func Prometheus() Sink {
+	return &prometheusSink{
+		counters: map[string]*prometheus.CounterVec{
+			nameHTTPRequests:                 httpRequestsTotal,
+			nameNotificationsEnqueued:        notificationsEnqueued,
+			nameNotificationsProcessed:       notificationsProcessed,
+			nameWorkerPanics:                 workerPanics,
+			nameNotificationsReaped:          notificationsReaped,
+			nameJobRuns:                      jobRuns,
+			nameDeliveryEvents:               deliveryEvents,
+			nameReputationActions:            reputationActions,
+			nameNotificationsThrottled:       notificationsThrottled,
+			nameNotificationsShed:            notificationsShed,
+			nameNotificationsFrequencyCapped: notificationsFrequencyCapped,
+			nameNotificationsPreferences:     notificationsPreferences,
+			nameBudgetAlerts:                 budgetAlerts,
+			nameLifecycleEvents:              lifecycleEvents,
+			nameArchiveWrites:                archiveWrites,
+			nameAnalyticsExports:             analyticsExports,
+			nameCanarySends:                  canarySends,
+			nameShadowSends:                  shadowSends,
+			nameIdempotencyHits:              idempotencyHits,
+			nameRateLimitRejections:          rateLimitRejections,
+			nameNotificationSLA:              notificationSLA,
+			nameDeadLetters:                  deadLetters,
+			nameRecipientsSuppressed:         recipientsSuppressed,
+			nameNotificationsCollapsed:       notificationsCollapsed,
+		},
+		gauges: map[string]*prometheus.GaugeVec{
+			nameWorkerLastPoll:         workerLastPoll,
+			nameWorkerBatchSize:        workerBatchSize,
+			nameQueueBacklog:           queueBacklog,
+			nameQueueOldestDueAge:      queueOldestDueAge,
+			nameQueueDepth:             queueDepth,
+			nameJobLastSuccess:         jobLastSuccess,
+			nameSQSMessagesInFlight:    sqsMessagesInFlight,
+			nameCanaryActive:           canaryActive,
+			nameDBConnectionsActive:    dbConnectionsActive,
+			nameRedisConnectionsActive: redisConnectionsActive,
+			nameRateLimitDegraded:      rateLimitDegraded,
+		},
+		histograms: map[string]*prometheus.HistogramVec{
+			nameHTTPRequestDuration: httpRequestDuration,
+			nameNotificationLatency: notificationLatency,
+			nameSenderDuration:      senderDuration,
+			nameJobDuration:         jobDuration,
+			nameDeliveryCost:        deliveryCost,
+			nameDBQueryDuration:     dbQueryDuration,
+		},
+	}
+}
+
+func (p *prometheusSink) IncCounter(name string, labels Labels) {
+	if c, ok := p.counters[name]; ok {
+		c.With(prometheus.Labels(labels)).Inc()
+	}
+}
+
+func (p *prometheusSink) SetGauge(name string, value float64, labels Labels) {
+	if g, ok := p.gauges[name]; ok {
+		g.With(prometheus.Labels(labels)).Set(value)
+	}
+}
+
+func (p *prometheusSink) Observe(name string, value float64, labels Labels) {
+	if h, ok := p.histograms[name]; ok {
+		h.With(prometheus.Labels(labels)).Observe(value)
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// Tenant label modes (METRICS_TENANT_LABELS).
+const (
+	TenantLabelsRaw       = "raw"       // the tenant ID as-is
+	TenantLabelsBucket    = "bucket"    // hashed into a fixed number of buckets
+	TenantLabelsAllowlist = "allowlist" // listed tenants as-is, everyone else as "other"
+)
+
+// TenantLabelOther is the label for tenants outside the allowlist.
+const TenantLabelOther = "other"
+
+// TenantLabelConfig controls how tenant IDs become the tenant_id label on
+// per-tenant series. Raw IDs give one series per tenant, which is fine for a
+// handful of tenants and unbounded at scale; bucket and allowlist cap it.
+type TenantLabelConfig struct {
+	Mode      string
+	Buckets   int      // bucket mode: number of buckets
+	Allowlist []string // allowlist mode: tenant IDs that keep their own series
+}
+
+type tenantLabeler struct {
+	mode    string
+	buckets uint32
+	allowed map[string]struct{}
+}
+
+var tenantLabels atomic.Pointer[tenantLabeler]
+
+// SetTenantLabels installs the tenant label policy. Call it once at startup,
+// before any per-tenant metrics are recorded; series already emitted under
+// the previous policy are not relabelled.
+func SetTenantLabels(cfg TenantLabelConfig) error {
+	l := &tenantLabeler{mode: cfg.Mode}
+	switch cfg.Mode {
+	case "", TenantLabelsRaw:
+		l.mode = TenantLabelsRaw
+	case TenantLabelsBucket:
+		if cfg.Buckets <= 0 {
+			return fmt.Errorf("tenant label buckets must be positive, got %d", cfg.Buckets)
+		}
+		l.buckets = uint32(cfg.Buckets)
+	case TenantLabelsAllowlist:
+		l.allowed = make(map[string]struct{}, len(cfg.Allowlist))
+		for _, id := range cfg.Allowlist {
+			l.allowed[id] = struct{}{}
+		}
+	default:
+		return fmt.Errorf("unknown tenant label mode %q", cfg.Mode)
+	}
+	tenantLabels.Store(l)
+	return nil
+}
+
+// tenantLabel maps a tenant ID to its label value under the current policy.
+func tenantLabel(tenantID string) string {
+	l := tenantLabels.Load()
+	if l == nil {
+		return tenantID
+	}
+	switch l.mode {
+	case TenantLabelsBucket:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(tenantID))
+		return fmt.Sprintf("bucket-%02d", h.Sum32()%l.buckets)
+	case TenantLabelsAllowlist:
+		if _, ok := l.allowed[tenantID]; ok {
+			return tenantID
+		}
+		return TenantLabelOther
+	default:
+		return tenantID
+	}
+}
@@ -0,0 +1,152 @@
+// Package mjml compiles MJML email markup into responsive HTML.
+//
+// Compilation is delegated to an MJML HTTP API (https://mjml.io/api or a
+// self-hosted server exposing the same /v1/render endpoint) rather than
+// embedding the Node.js compiler in the gateway image.
+package mjml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxResponseBytes bounds how much of a render response we read; compiled
+// emails are tens of KB, so anything near this is a misbehaving server.
+const maxResponseBytes = 5 << 20
+
+// Config holds the MJML API client configuration.
+type Config struct {
+	BaseURL   string // API base URL (default: https://api.mjml.io)
+	AppID     string // Basic auth user; optional for self-hosted servers
+	SecretKey string // Basic auth password
+	Timeout   time.Duration
+}
+
+// Client calls the MJML render API.
+type Client struct {
+	baseURL    string
+	appID      string
+	secretKey  string
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// NewClient creates a new MJML API client.
+func NewClient(cfg Config, logger *zap.Logger) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.mjml.io"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &Client{
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		appID:     cfg.AppID,
+		secretKey: cfg.SecretKey,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		logger: logger,
+	}
+}
+
+// CompileError means the MJML itself is invalid. It is the author's mistake,
+// unlike transport or API failures, so callers should surface the messages.
+type CompileError struct {
+	Messages []string
+}
+
+func (e *CompileError) Error() string {
+	return "invalid mjml: " + strings.Join(e.Messages, "; ")
+}
+
+type renderRequest struct {
+	MJML string `json:"mjml"`
+}
+
+type renderResponse struct {
+	HTML   string `json:"html"`
+	Errors []struct {
+		Line             int    `json:"line"`
+		Message          string `json:"message"`
+		FormattedMessage string `json:"formattedMessage"`
+	} `json:"errors"`
+	Message string `json:"message"` // set on non-200 responses
+}
+
+// Compile renders MJML source to HTML. Validation errors reported by the
+// compiler are returned as a *CompileError, even though the API still
+// produces best-effort HTML for them: we'd rather refuse to publish than
+// send a half-broken layout.
+func (c *Client) Compile(ctx context.Context, source string) (string, error) {
+	body, err := json.Marshal(renderRequest{MJML: source})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/render", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.appID != "" {
+		req.SetBasicAuth(c.appID, c.secretKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("mjml API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	var rendered renderResponse
+	if err := json.Unmarshal(respBody, &rendered); err != nil {
+		return "", fmt.Errorf("mjml API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// The API answers 400 for unparseable MJML; everything else is ours
+		// or theirs, not the author's.
+		if resp.StatusCode == http.StatusBadRequest && rendered.Message != "" {
+			return "", &CompileError{Messages: []string{rendered.Message}}
+		}
+		return "", fmt.Errorf("mjml API returned %d: %s", resp.StatusCode, rendered.Message)
+	}
+
+	if len(rendered.Errors) > 0 {
+		messages := make([]string, 0, len(rendered.Errors))
+		for _, e := range rendered.Errors {
+			msg := e.FormattedMessage
+			if msg == "" {
+				msg = fmt.Sprintf("line %d: %s", e.Line, e.Message)
+			}
+			messages = append(messages, msg)
+		}
+		return "", &CompileError{Messages: messages}
+	}
+
+	if rendered.HTML == "" {
+		return "", fmt.Errorf("mjml API returned no html")
+	}
+
+	c.logger.Debug("compiled mjml",
+		zap.Int("source_length", len(source)),
+		zap.Int("html_length", len(rendered.HTML)),
+	)
+
+	return rendered.HTML, nil
+}
@@ -0,0 +1,96 @@
+package mjml
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestClient_Compile(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		response     string
+		wantHTML     string
+		wantCompile  bool
+		wantOtherErr bool
+	}{
+		{
+			name:     "success",
+			status:   http.StatusOK,
+			response: `{"html":"<html>ok</html>","errors":[]}`,
+			wantHTML: "<html>ok</html>",
+		},
+		{
+			name:        "validation errors",
+			status:      http.StatusOK,
+			response:    `{"html":"<html></html>","errors":[{"line":3,"message":"mj-text cannot be used inside mj-body","formattedMessage":"Line 3 of mjml (mj-text) — mj-text cannot be used inside mj-body"}]}`,
+			wantCompile: true,
+		},
+		{
+			name:        "unparseable mjml",
+			status:      http.StatusBadRequest,
+			response:    `{"message":"Malformed MJML"}`,
+			wantCompile: true,
+		},
+		{
+			name:         "bad credentials",
+			status:       http.StatusUnauthorized,
+			response:     `{"message":"Unauthorized"}`,
+			wantOtherErr: true,
+		},
+		{
+			name:         "non-json error",
+			status:       http.StatusBadGateway,
+			response:     `bad gateway`,
+			wantOtherErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/render" {
+					t.Errorf("expected /v1/render, got %s", r.URL.Path)
+				}
+				if user, pass, ok := r.BasicAuth(); !ok || user != "app" || pass != "secret" {
+					t.Errorf("expected basic auth app:secret, got %q:%q", user, pass)
+				}
+				var req renderRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MJML == "" {
+					t.Errorf("expected mjml in request body, err=%v", err)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{BaseURL: server.URL, AppID: "app", SecretKey: "secret"}, zap.NewNop())
+			html, err := client.Compile(context.Background(), "<mjml><mj-body></mj-body></mjml>")
+
+			var compileErr *CompileError
+			switch {
+			case tt.wantCompile:
+				if !errors.As(err, &compileErr) {
+					t.Fatalf("expected CompileError, got %v", err)
+				}
+			case tt.wantOtherErr:
+				if err == nil || errors.As(err, &compileErr) {
+					t.Fatalf("expected a non-compile error, got %v", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if html != tt.wantHTML {
+					t.Errorf("expected %q, got %q", tt.wantHTML, html)
+				}
+			}
+		})
+	}
+}
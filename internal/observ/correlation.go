@@ -0,0 +1,40 @@
+package observ
+
+import (
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader is the HTTP header (and, lowercased, the gRPC metadata
+// key) a caller can use to supply its own correlation ID. We echo it back on
+// the response and forward it to webhook receivers under the same name.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// MaxCorrelationIDLength caps caller-supplied IDs. The ID ends up in logs,
+// SQS attributes, webhook headers and SES tags, so it must stay small.
+const MaxCorrelationIDLength = 128
+
+// NewCorrelationID returns a fresh ID for a notification that arrived
+// without one.
+func NewCorrelationID() string {
+	return uuid.NewString()
+}
+
+// ValidCorrelationID reports whether id is safe to accept from a caller:
+// non-empty, at most MaxCorrelationIDLength bytes, and limited to
+// [A-Za-z0-9-_.:]. The charset keeps the value header- and log-safe and is
+// a superset of what trace ID formats (UUIDs, W3C trace IDs) use.
+func ValidCorrelationID(id string) bool {
+	if id == "" || len(id) > MaxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
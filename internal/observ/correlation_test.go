@@ -0,0 +1,41 @@
+package observ
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidCorrelationID(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{"uuid", "3f2504e0-4f89-11d3-9a0c-0305e82c3301", true},
+		{"w3c trace id", "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"dotted and colons", "svc.checkout:req_42", true},
+		{"empty", "", false},
+		{"space", "abc def", false},
+		{"newline", "abc\nX-Injected: 1", false},
+		{"too long", strings.Repeat("a", MaxCorrelationIDLength+1), false},
+		{"max length", strings.Repeat("a", MaxCorrelationIDLength), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCorrelationID(tt.id); got != tt.valid {
+				t.Errorf("ValidCorrelationID(%q) = %v, want %v", tt.id, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestNewCorrelationID_IsValid(t *testing.T) {
+	id := NewCorrelationID()
+	if !ValidCorrelationID(id) {
+		t.Errorf("generated ID %q is not valid", id)
+	}
+	if id == NewCorrelationID() {
+		t.Error("expected distinct IDs")
+	}
+}
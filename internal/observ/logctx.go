@@ -0,0 +1,41 @@
+package observ
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Field names shared by every log line that carries request context, so the
+// same ID is searchable under the same key whether it was logged by the API,
+// the repository or a sender.
+const (
+	FieldRequestID      = "request_id"
+	FieldTenantID       = "tenant_id"
+	FieldNotificationID = "notification_id"
+	FieldCorrelationID  = "correlation_id"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger stored in ctx, or fallback if there is none.
+// Components keep their own logger as the fallback, so they log the same way
+// whether or not the caller set up a scoped one.
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return fallback
+}
+
+// With derives a logger from the one in ctx (or fallback) with fields added,
+// and returns a context carrying it. Use it at the point an ID becomes known,
+// e.g. once auth resolves the tenant or the worker claims a notification.
+func With(ctx context.Context, fallback *zap.Logger, fields ...zap.Field) context.Context {
+	return WithLogger(ctx, Logger(ctx, fallback).With(fields...))
+}
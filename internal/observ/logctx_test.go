@@ -0,0 +1,34 @@
+package observ
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_FallsBackWithoutScopedLogger(t *testing.T) {
+	fallback := zap.NewNop()
+	if got := Logger(context.Background(), fallback); got != fallback {
+		t.Error("expected the fallback logger")
+	}
+}
+
+func TestWith_AccumulatesFields(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core)
+
+	ctx := With(context.Background(), base, zap.String(FieldRequestID, "req-1"))
+	ctx = With(ctx, zap.NewNop(), zap.String(FieldTenantID, "tenant-1"))
+	Logger(ctx, zap.NewNop()).Info("hello")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields[FieldRequestID] != "req-1" || fields[FieldTenantID] != "tenant-1" {
+		t.Errorf("expected request and tenant fields, got %v", fields)
+	}
+}
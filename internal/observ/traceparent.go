@@ -0,0 +1,57 @@
+package observ
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceParentHeader is the W3C Trace Context header (and SQS message
+// attribute) that carries a trace across a hop.
+const TraceParentHeader = "traceparent"
+
+// TraceParent returns a W3C traceparent for a hop of the trace identified
+// by correlationID, with a fresh span ID for the hop. Correlation IDs that
+// are UUIDs or W3C trace IDs map onto the trace ID directly; anything else
+// can't be expressed as a trace ID, so the result is empty.
+func TraceParent(correlationID string) string {
+	traceID := TraceIDFromCorrelationID(correlationID)
+	if traceID == "" {
+		return ""
+	}
+	var span [8]byte
+	if _, err := rand.Read(span[:]); err != nil {
+		return ""
+	}
+	return "00-" + traceID + "-" + hex.EncodeToString(span[:]) + "-01"
+}
+
+// TraceIDFromCorrelationID returns the 32-hex-digit trace ID correlationID
+// stands for, or "" if it isn't a UUID or trace ID.
+func TraceIDFromCorrelationID(correlationID string) string {
+	id := strings.ToLower(strings.ReplaceAll(correlationID, "-", ""))
+	if len(id) != 32 || id == strings.Repeat("0", 32) {
+		return ""
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return ""
+	}
+	// Only a canonical UUID may carry dashes.
+	if strings.Contains(correlationID, "-") && len(correlationID) != 36 {
+		return ""
+	}
+	return id
+}
+
+// TraceIDFromParent returns the trace ID field of a traceparent, or "" if
+// it is malformed.
+func TraceIDFromParent(traceParent string) string {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
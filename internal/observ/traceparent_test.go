@@ -0,0 +1,44 @@
+package observ
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTraceIDFromCorrelationID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"uuid", "3f2504e0-4f89-11d3-9a0c-0305e82c3301", "3f2504e04f8911d39a0c0305e82c3301"},
+		{"w3c trace id", "4BF92F3577B34DA6A3CE929D0E0E4736", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"all zero", strings.Repeat("0", 32), ""},
+		{"free-form", "svc.checkout:req_42", ""},
+		{"stray dashes", "4bf92f3577b34da6a3ce929d0e0e47-36", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TraceIDFromCorrelationID(tt.id); got != tt.want {
+				t.Errorf("TraceIDFromCorrelationID(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceParent_RoundTrips(t *testing.T) {
+	tp := TraceParent("3f2504e0-4f89-11d3-9a0c-0305e82c3301")
+	if len(tp) != 55 || !strings.HasPrefix(tp, "00-") || !strings.HasSuffix(tp, "-01") {
+		t.Fatalf("malformed traceparent %q", tp)
+	}
+	if got := TraceIDFromParent(tp); got != "3f2504e04f8911d39a0c0305e82c3301" {
+		t.Errorf("trace id = %q", got)
+	}
+	if TraceParent("3f2504e0-4f89-11d3-9a0c-0305e82c3301") == tp {
+		t.Error("expected a fresh span id per hop")
+	}
+	if TraceParent("not-a-trace") != "" {
+		t.Error("expected no traceparent for a free-form correlation id")
+	}
+}
@@ -0,0 +1,87 @@
+package phone
+
+type country struct {
+	code string // ITU calling code, without '+'
+	// keepsTrunkZero is set where the leading 0 is part of the number
+	// itself rather than a trunk prefix (Italy and its enclaves).
+	keepsTrunkZero bool
+}
+
+// countries maps ISO 3166-1 alpha-2 codes to calling codes.
+var countries = map[string]country{
+	// North American Numbering Plan
+	"US": {code: "1"}, "CA": {code: "1"}, "PR": {code: "1"}, "DO": {code: "1"},
+	"JM": {code: "1"}, "TT": {code: "1"}, "BS": {code: "1"}, "BB": {code: "1"},
+
+	"RU": {code: "7"}, "KZ": {code: "7"},
+
+	"EG": {code: "20"}, "ZA": {code: "27"}, "GR": {code: "30"}, "NL": {code: "31"},
+	"BE": {code: "32"}, "FR": {code: "33"}, "ES": {code: "34"}, "HU": {code: "36"},
+	"IT": {code: "39", keepsTrunkZero: true}, "VA": {code: "39", keepsTrunkZero: true},
+	"RO": {code: "40"}, "CH": {code: "41"}, "AT": {code: "43"}, "GB": {code: "44"},
+	"DK": {code: "45"}, "SE": {code: "46"}, "NO": {code: "47"}, "PL": {code: "48"},
+	"DE": {code: "49"},
+
+	"PE": {code: "51"}, "MX": {code: "52"}, "CU": {code: "53"}, "AR": {code: "54"},
+	"BR": {code: "55"}, "CL": {code: "56"}, "CO": {code: "57"}, "VE": {code: "58"},
+
+	"MY": {code: "60"}, "AU": {code: "61"}, "ID": {code: "62"}, "PH": {code: "63"},
+	"NZ": {code: "64"}, "SG": {code: "65"}, "TH": {code: "66"},
+
+	"JP": {code: "81"}, "KR": {code: "82"}, "VN": {code: "84"}, "CN": {code: "86"},
+
+	"TR": {code: "90"}, "IN": {code: "91"}, "PK": {code: "92"}, "AF": {code: "93"},
+	"LK": {code: "94"}, "MM": {code: "95"}, "IR": {code: "98"},
+
+	"SS": {code: "211"}, "MA": {code: "212"}, "DZ": {code: "213"}, "TN": {code: "216"},
+	"LY": {code: "218"}, "GM": {code: "220"}, "SN": {code: "221"}, "MR": {code: "222"},
+	"ML": {code: "223"}, "GN": {code: "224"}, "CI": {code: "225"}, "BF": {code: "226"},
+	"NE": {code: "227"}, "TG": {code: "228"}, "BJ": {code: "229"}, "MU": {code: "230"},
+	"LR": {code: "231"}, "SL": {code: "232"}, "GH": {code: "233"}, "NG": {code: "234"},
+	"TD": {code: "235"}, "CF": {code: "236"}, "CM": {code: "237"}, "CV": {code: "238"},
+	"ST": {code: "239"}, "GQ": {code: "240"}, "GA": {code: "241"}, "CG": {code: "242"},
+	"CD": {code: "243"}, "AO": {code: "244"}, "GW": {code: "245"}, "SC": {code: "248"},
+	"SD": {code: "249"}, "RW": {code: "250"}, "ET": {code: "251"}, "SO": {code: "252"},
+	"DJ": {code: "253"}, "KE": {code: "254"}, "TZ": {code: "255"}, "UG": {code: "256"},
+	"BI": {code: "257"}, "MZ": {code: "258"}, "ZM": {code: "260"}, "MG": {code: "261"},
+	"RE": {code: "262"}, "ZW": {code: "263"}, "NA": {code: "264"}, "MW": {code: "265"},
+	"LS": {code: "266"}, "BW": {code: "267"}, "SZ": {code: "268"}, "KM": {code: "269"},
+	"ER": {code: "291"}, "AW": {code: "297"}, "FO": {code: "298"}, "GL": {code: "299"},
+
+	"GI": {code: "350"}, "PT": {code: "351"}, "LU": {code: "352"}, "IE": {code: "353"},
+	"IS": {code: "354"}, "AL": {code: "355"}, "MT": {code: "356"}, "CY": {code: "357"},
+	"FI": {code: "358"}, "BG": {code: "359"}, "LT": {code: "370"}, "LV": {code: "371"},
+	"EE": {code: "372"}, "MD": {code: "373"}, "AM": {code: "374"}, "BY": {code: "375"},
+	"AD": {code: "376"}, "MC": {code: "377"}, "SM": {code: "378", keepsTrunkZero: true},
+	"UA": {code: "380"}, "RS": {code: "381"}, "ME": {code: "382"}, "XK": {code: "383"},
+	"HR": {code: "385"}, "SI": {code: "386"}, "BA": {code: "387"}, "MK": {code: "389"},
+	"CZ": {code: "420"}, "SK": {code: "421"}, "LI": {code: "423"},
+
+	"BZ": {code: "501"}, "GT": {code: "502"}, "SV": {code: "503"}, "HN": {code: "504"},
+	"NI": {code: "505"}, "CR": {code: "506"}, "PA": {code: "507"}, "HT": {code: "509"},
+	"BO": {code: "591"}, "GY": {code: "592"}, "EC": {code: "593"}, "PY": {code: "595"},
+	"SR": {code: "597"}, "UY": {code: "598"},
+
+	"TL": {code: "670"}, "BN": {code: "673"}, "PG": {code: "675"}, "TO": {code: "676"},
+	"SB": {code: "677"}, "VU": {code: "678"}, "FJ": {code: "679"}, "WS": {code: "685"},
+	"KI": {code: "686"}, "NC": {code: "687"}, "PF": {code: "689"},
+
+	"KP": {code: "850"}, "HK": {code: "852"}, "MO": {code: "853"}, "KH": {code: "855"},
+	"LA": {code: "856"}, "BD": {code: "880"}, "TW": {code: "886"},
+
+	"MV": {code: "960"}, "LB": {code: "961"}, "JO": {code: "962"}, "SY": {code: "963"},
+	"IQ": {code: "964"}, "KW": {code: "965"}, "SA": {code: "966"}, "YE": {code: "967"},
+	"OM": {code: "968"}, "PS": {code: "970"}, "AE": {code: "971"}, "IL": {code: "972"},
+	"BH": {code: "973"}, "QA": {code: "974"}, "BT": {code: "975"}, "MN": {code: "976"},
+	"NP": {code: "977"}, "TJ": {code: "992"}, "TM": {code: "993"}, "AZ": {code: "994"},
+	"GE": {code: "995"}, "KG": {code: "996"}, "UZ": {code: "998"},
+}
+
+// callingCodes is the set of calling codes in countries.
+var callingCodes = func() map[string]bool {
+	set := make(map[string]bool, len(countries))
+	for _, c := range countries {
+		set[c.code] = true
+	}
+	return set
+}()
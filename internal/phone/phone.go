@@ -0,0 +1,137 @@
+// Package phone validates phone numbers and normalizes them to E.164.
+//
+// It is deliberately light: it knows each country's calling code and trunk
+// prefix, and the North American Numbering Plan's shape, which is enough to
+// catch typos and missing country codes before a send burns its retries.
+// It does not know per-country number lengths or which ranges are assigned.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxE164Digits = 15
+	// minNationalDigits is the shortest subscriber number in use (a handful
+	// of small island nations); anything shorter is a typo.
+	minNationalDigits = 4
+	nanpCallingCode   = "1"
+	nanpNationalLen   = 10
+)
+
+var (
+	// ErrMissingCountry means the number has no country code and no default
+	// country was given to infer one from.
+	ErrMissingCountry = errors.New("number has no country code; use E.164 (+14155552671) or set a default country")
+	// ErrInvalidNumber means the number can't be a real phone number.
+	ErrInvalidNumber = errors.New("not a valid phone number")
+)
+
+// Normalize returns raw in E.164 form (+<country code><number>).
+//
+// Numbers may use spaces, dots, dashes and parentheses as separators, and an
+// international 00 prefix in place of '+'. Numbers without either are
+// treated as national numbers in defaultCountry (ISO 3166-1 alpha-2), with
+// the country's trunk prefix removed.
+func Normalize(raw, defaultCountry string) (string, error) {
+	digits, international, err := clean(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if !international {
+		if defaultCountry == "" {
+			return "", ErrMissingCountry
+		}
+		country, ok := countries[strings.ToUpper(defaultCountry)]
+		if !ok {
+			return "", fmt.Errorf("unknown country %q", defaultCountry)
+		}
+		digits = country.code + stripTrunkPrefix(digits, country)
+	}
+
+	code, ok := callingCode(digits)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown country code", ErrInvalidNumber)
+	}
+	national := digits[len(code):]
+
+	if len(digits) > maxE164Digits || len(national) < minNationalDigits {
+		return "", fmt.Errorf("%w: wrong length", ErrInvalidNumber)
+	}
+	if code == nanpCallingCode && !validNANP(national) {
+		return "", fmt.Errorf("%w: not a valid North American number", ErrInvalidNumber)
+	}
+
+	return "+" + digits, nil
+}
+
+// ValidCountry reports whether country is an ISO 3166-1 alpha-2 code this
+// package can infer a calling code for.
+func ValidCountry(country string) bool {
+	_, ok := countries[strings.ToUpper(country)]
+	return ok
+}
+
+// clean strips separators and reports whether the number carried an
+// international prefix.
+func clean(raw string) (digits string, international bool, err error) {
+	s := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s, international = s[1:], true
+	case strings.HasPrefix(s, "00"):
+		s, international = s[2:], true
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ', r == '-', r == '.', r == '(', r == ')':
+		default:
+			return "", false, fmt.Errorf("%w: unexpected character %q", ErrInvalidNumber, r)
+		}
+	}
+
+	digits = b.String()
+	if digits == "" || (international && digits[0] == '0') {
+		return "", false, ErrInvalidNumber
+	}
+	return digits, international, nil
+}
+
+func stripTrunkPrefix(national string, c country) string {
+	if c.code == nanpCallingCode {
+		// 1-555-123-4567 dialled nationally.
+		if len(national) == nanpNationalLen+1 && national[0] == '1' {
+			return national[1:]
+		}
+		return national
+	}
+	if c.keepsTrunkZero {
+		return national
+	}
+	return strings.TrimPrefix(national, "0")
+}
+
+// callingCode finds the country calling code digits starts with. Calling
+// codes are prefix-free, so at most one of the 1-3 digit prefixes matches.
+func callingCode(digits string) (string, bool) {
+	for n := 1; n <= 3 && n <= len(digits); n++ {
+		if callingCodes[digits[:n]] {
+			return digits[:n], true
+		}
+	}
+	return "", false
+}
+
+// validNANP checks NXX-NXX-XXXX: ten digits, and neither the area code nor
+// the exchange may start with 0 or 1.
+func validNANP(national string) bool {
+	return len(national) == nanpNationalLen &&
+		national[0] >= '2' && national[3] >= '2'
+}
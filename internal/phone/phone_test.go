@@ -0,0 +1,62 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		country string
+		want    string
+		wantErr error
+	}{
+		{"e164 us", "+14155552671", "", "+14155552671", nil},
+		{"separators", "+1 (415) 555-2671", "", "+14155552671", nil},
+		{"00 prefix", "0044 20 7946 0958", "", "+442079460958", nil},
+		{"national gb with trunk zero", "020 7946 0958", "GB", "+442079460958", nil},
+		{"national us", "415-555-2671", "us", "+14155552671", nil},
+		{"national us with leading 1", "1 415 555 2671", "US", "+14155552671", nil},
+		{"italy keeps leading zero", "06 6988 1234", "IT", "+390669881234", nil},
+		{"international ignores default", "+4915123456789", "US", "+4915123456789", nil},
+		{"national without default", "4155552671", "", "", ErrMissingCountry},
+		{"letters", "+1 415 CALL NOW", "", "", ErrInvalidNumber},
+		{"too long", "+4412345678901234", "", "", ErrInvalidNumber},
+		{"too short", "+44123", "", "", ErrInvalidNumber},
+		{"nanp area code starts with 1", "+11155552671", "", "", ErrInvalidNumber},
+		{"nanp exchange starts with 1", "+15551234567", "", "", ErrInvalidNumber},
+		{"nanp wrong length", "+1415555267", "", "", ErrInvalidNumber},
+		{"unknown calling code", "+8091234567", "", "", ErrInvalidNumber},
+		{"empty", "", "", "", ErrInvalidNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.country)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Normalize(%q, %q) error = %v, want %v", tt.raw, tt.country, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q, %q) unexpected error: %v", tt.raw, tt.country, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.raw, tt.country, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallingCodesArePrefixFree(t *testing.T) {
+	for code := range callingCodes {
+		for n := 1; n < len(code); n++ {
+			if callingCodes[code[:n]] {
+				t.Errorf("calling code %s has %s as a prefix", code, code[:n])
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package preferences applies what users chose about a notification's
+// category at send time: a user who opted out of the category on the
+// channel doesn't get it, and a category's quiet hours hold its
+// notifications until the quiet window ends in the user's timezone.
+// Mandatory categories, such as security alerts, skip both.
+package preferences
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// refreshInterval is how stale the engine's view of the categories may get.
+const refreshInterval = 30 * time.Second
+
+// retryDelay is how long a notification waits when its user's opt-outs
+// can't be read. Sending it anyway could reach a user who opted out.
+const retryDelay = time.Minute
+
+// clockLayout is the "HH:MM" format of quiet hours.
+const clockLayout = "15:04"
+
+// Store lists the categories and reads users' opt-outs and timezones.
+type Store interface {
+	ListNotificationCategories(ctx context.Context) ([]*db.NotificationCategory, error)
+	IsOptedOut(ctx context.Context, tenantID, userID uuid.UUID, category, channel string) (bool, error)
+	GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error)
+}
+
+// Engine checks notifications against their user's preferences.
+type Engine struct {
+	store  Store
+	logger *zap.Logger
+
+	mu         sync.Mutex
+	categories map[categoryKey]*db.NotificationCategory
+	loadedAt   time.Time
+}
+
+type categoryKey struct {
+	tenantID uuid.UUID
+	name     string
+}
+
+// New creates an engine.
+func New(store Store, logger *zap.Logger) *Engine {
+	return &Engine{
+		store:      store,
+		logger:     logger,
+		categories: make(map[categoryKey]*db.NotificationCategory),
+	}
+}
+
+// Check reports what to do with notif: send it now (zero delay), defer it
+// for delay, or drop it because its user opted out. It fits
+// worker.Config.Preferences. Notifications without a category, or in a
+// mandatory one, always go.
+func (e *Engine) Check(ctx context.Context, notif *db.Notification) (delay time.Duration, optedOut bool) {
+	if notif.Category == "" {
+		return 0, false
+	}
+
+	category := e.category(ctx, notif)
+	if category != nil && category.Mandatory {
+		return 0, false
+	}
+
+	optedOut, err := e.store.IsOptedOut(ctx, notif.TenantID, notif.UserID, notif.Category, notif.Channel)
+	if err != nil {
+		e.logger.Warn("failed to check category opt-out, deferring send",
+			zap.Error(err),
+			zap.String("tenant_id", notif.TenantID.String()),
+			zap.String("category", notif.Category),
+		)
+		return retryDelay, false
+	}
+	if optedOut {
+		return 0, true
+	}
+	if category == nil || category.QuietHours == nil {
+		return 0, false
+	}
+
+	return QuietFor(category.QuietHours, time.Now().In(e.location(ctx, notif))), false
+}
+
+// category returns notif's category as of the last refresh, or nil if the
+// tenant hasn't defined it (e.g. it was deleted since).
+func (e *Engine) category(ctx context.Context, notif *db.Notification) *db.NotificationCategory {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now := time.Now(); now.Sub(e.loadedAt) >= refreshInterval {
+		e.refresh(ctx, now)
+	}
+	return e.categories[categoryKey{tenantID: notif.TenantID, name: notif.Category}]
+}
+
+// location is the user's timezone: their recipient record's, or UTC if
+// they have none.
+func (e *Engine) location(ctx context.Context, notif *db.Notification) *time.Location {
+	rc, err := e.store.GetRecipient(ctx, notif.TenantID, notif.UserID)
+	if err != nil {
+		if !errors.Is(err, db.ErrNoRecipient) {
+			e.logger.Warn("failed to read recipient timezone, using UTC",
+				zap.Error(err),
+				zap.String("tenant_id", notif.TenantID.String()),
+			)
+		}
+		return time.UTC
+	}
+	if rc.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(rc.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietFor returns how long it stays quiet after now, in now's location:
+// zero outside the window, else the time until it ends. Malformed quiet
+// hours are never quiet.
+func QuietFor(q *db.QuietHours, now time.Time) time.Duration {
+	start, err := time.Parse(clockLayout, q.Start)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(clockLayout, q.End)
+	if err != nil {
+		return 0
+	}
+
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+	nowMin := now.Hour()*60 + now.Minute()
+
+	var quiet bool
+	switch {
+	case startMin < endMin:
+		quiet = nowMin >= startMin && nowMin < endMin
+	case startMin > endMin: // wraps past midnight
+		quiet = nowMin >= startMin || nowMin < endMin
+	}
+	if !quiet {
+		return 0
+	}
+
+	until := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), 0, 0, now.Location())
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until.Sub(now)
+}
+
+// refresh reloads the categories. On error the previous ones are kept.
+// Callers hold e.mu.
+func (e *Engine) refresh(ctx context.Context, now time.Time) {
+	e.loadedAt = now
+
+	categories, err := e.store.ListNotificationCategories(ctx)
+	if err != nil {
+		e.logger.Warn("failed to refresh notification categories, keeping previous", zap.Error(err))
+		return
+	}
+
+	loaded := make(map[categoryKey]*db.NotificationCategory, len(categories))
+	for _, c := range categories {
+		loaded[categoryKey{tenantID: c.TenantID, name: c.Name}] = c
+	}
+	e.categories = loaded
+}
@@ -0,0 +1,122 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type fakeStore struct {
+	categories []*db.NotificationCategory
+	optedOut   bool
+	optOutErr  error
+	recipient  *db.Recipient
+}
+
+func (f *fakeStore) ListNotificationCategories(ctx context.Context) ([]*db.NotificationCategory, error) {
+	return f.categories, nil
+}
+
+func (f *fakeStore) IsOptedOut(ctx context.Context, tenantID, userID uuid.UUID, category, channel string) (bool, error) {
+	return f.optedOut, f.optOutErr
+}
+
+func (f *fakeStore) GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error) {
+	if f.recipient == nil {
+		return nil, db.ErrNoRecipient
+	}
+	return f.recipient, nil
+}
+
+func TestCheck(t *testing.T) {
+	tenantID := uuid.New()
+	// Quiet all day except the minute around now, so the test doesn't
+	// depend on the clock: the window wraps past midnight in UTC.
+	now := time.Now().UTC()
+	awake := &db.QuietHours{
+		Start: now.Add(2 * time.Minute).Format(clockLayout),
+		End:   now.Add(-2 * time.Minute).Format(clockLayout),
+	}
+	asleep := &db.QuietHours{
+		Start: now.Add(-2 * time.Minute).Format(clockLayout),
+		End:   now.Add(2 * time.Minute).Format(clockLayout),
+	}
+
+	tests := []struct {
+		name         string
+		category     string
+		quietHours   *db.QuietHours
+		mandatory    bool
+		optedOut     bool
+		optOutErr    error
+		wantDelay    bool
+		wantOptedOut bool
+	}{
+		{name: "no category", category: "", optedOut: true},
+		{name: "no preferences", category: "billing"},
+		{name: "opted out", category: "marketing", optedOut: true, wantOptedOut: true},
+		{name: "opt-out lookup fails", category: "marketing", optOutErr: errors.New("db down"), wantDelay: true},
+		{name: "outside quiet hours", category: "marketing", quietHours: awake},
+		{name: "inside quiet hours", category: "marketing", quietHours: asleep, wantDelay: true},
+		{name: "mandatory despite opt-out", category: "marketing", mandatory: true, optedOut: true},
+		{name: "mandatory despite quiet hours", category: "marketing", mandatory: true, quietHours: asleep},
+		{name: "mandatory despite failed opt-out lookup", category: "marketing", mandatory: true, optOutErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{
+				categories: []*db.NotificationCategory{{TenantID: tenantID, Name: "marketing", QuietHours: tt.quietHours, Mandatory: tt.mandatory}},
+				optedOut:   tt.optedOut,
+				optOutErr:  tt.optOutErr,
+			}
+			notif := &db.Notification{TenantID: tenantID, UserID: uuid.New(), Channel: "email", Category: tt.category}
+
+			delay, optedOut := New(store, zap.NewNop()).Check(context.Background(), notif)
+			if optedOut != tt.wantOptedOut {
+				t.Errorf("expected opted out %v, got %v", tt.wantOptedOut, optedOut)
+			}
+			if (delay > 0) != tt.wantDelay {
+				t.Errorf("expected delay %v, got %v", tt.wantDelay, delay)
+			}
+		})
+	}
+}
+
+func TestQuietFor(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	overnight := &db.QuietHours{Start: "21:00", End: "08:00"}
+	lunch := &db.QuietHours{Start: "12:00", End: "13:30"}
+
+	tests := []struct {
+		name  string
+		quiet *db.QuietHours
+		now   time.Time
+		want  time.Duration
+	}{
+		{"before an overnight window", overnight, time.Date(2026, 3, 2, 20, 0, 0, 0, berlin), 0},
+		{"evening in an overnight window", overnight, time.Date(2026, 3, 2, 22, 30, 0, 0, berlin), 9*time.Hour + 30*time.Minute},
+		{"morning in an overnight window", overnight, time.Date(2026, 3, 3, 7, 15, 0, 0, berlin), 45 * time.Minute},
+		{"at the end of the window", overnight, time.Date(2026, 3, 3, 8, 0, 0, 0, berlin), 0},
+		{"inside a daytime window", lunch, time.Date(2026, 3, 3, 12, 10, 0, 0, berlin), 80 * time.Minute},
+		{"after a daytime window", lunch, time.Date(2026, 3, 3, 14, 0, 0, 0, berlin), 0},
+		{"malformed", &db.QuietHours{Start: "9pm", End: "08:00"}, time.Date(2026, 3, 2, 22, 0, 0, 0, berlin), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuietFor(tt.quiet, tt.now); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInjectedFault fails a command picked by InjectFaults.
+var ErrInjectedFault = errors.New("chaos: injected redis failure")
+
+// InjectFaults makes percent (0-100) of commands and pipelines fail with
+// ErrInjectedFault instead of reaching Redis, for exercising the fallbacks
+// (idempotency off, in-memory rate limiting, the config cache falling
+// through to the store) in staging. Call it before the client is shared.
+func (c *Client) InjectFaults(percent float64) {
+	c.rdb.AddHook(faultHook{
+		percent: percent,
+		roll:    func() float64 { return rand.Float64() * 100 },
+	})
+}
+
+type faultHook struct {
+	percent float64
+	roll    func() float64 // in [0, 100)
+}
+
+func (h faultHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h faultHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if h.roll() < h.percent {
+			cmd.SetErr(ErrInjectedFault)
+			return ErrInjectedFault
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h faultHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if h.roll() < h.percent {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrInjectedFault)
+			}
+			return ErrInjectedFault
+		}
+		return next(ctx, cmds)
+	}
+}
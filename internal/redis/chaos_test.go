@@ -0,0 +1,32 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInjectFaults(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	client.InjectFaults(0)
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("expected no faults at 0%%, got %v", err)
+	}
+
+	client.InjectFaults(100)
+	if err := client.Ping(ctx); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected an injected fault, got %v", err)
+	}
+	_, err := client.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "k")
+		return nil
+	})
+	if !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected an injected pipeline fault, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// configKeyPrefix namespaces cached tenant configuration; the full key is
+// nimbus:config:<kind>:<tenant>[:<channel>].
+const configKeyPrefix = "nimbus:config:"
+
+// ConfigCache fronts a db.Store with a Redis cache of the tenant
+// configuration read on every request or send: tenant settings, rate limit
+// overrides and channel settings (which hold webhook credentials, sealed
+// as they are in the database). Every other method goes straight to the
+// store.
+//
+// Writes through the cache delete the cached entry, so every replica sees
+// a change on its next read. A read racing a write can put the old value
+// back, so entries also expire after ttl. When Redis is unavailable, reads
+// fall through to the store.
+type ConfigCache struct {
+	db.Store
+	client *Client
+	ttl    time.Duration
+	logger *zap.Logger
+}
+
+// NewConfigCache wraps store with a configuration cache whose entries live
+// for ttl.
+func NewConfigCache(store db.Store, client *Client, ttl time.Duration, logger *zap.Logger) *ConfigCache {
+	return &ConfigCache{
+		Store:  store,
+		client: client,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+func tenantSettingsKey(tenantID uuid.UUID) string {
+	return configKeyPrefix + "tenant-settings:" + tenantID.String()
+}
+
+func rateLimitKey(tenantID uuid.UUID) string {
+	return configKeyPrefix + "rate-limit:" + tenantID.String()
+}
+
+func channelSettingsKey(tenantID uuid.UUID, channel string) string {
+	return configKeyPrefix + "channel-settings:" + tenantID.String() + ":" + channel
+}
+
+// GetTenantSettings returns the tenant's settings, from cache when present.
+func (c *ConfigCache) GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*db.TenantSettings, error) {
+	return readThrough(ctx, c, tenantSettingsKey(tenantID), nil, func() (*db.TenantSettings, error) {
+		return c.Store.GetTenantSettings(ctx, tenantID)
+	})
+}
+
+// UpsertTenantSettings saves the settings and drops the cached copy.
+func (c *ConfigCache) UpsertTenantSettings(ctx context.Context, s *db.TenantSettings) error {
+	if err := c.Store.UpsertTenantSettings(ctx, s); err != nil {
+		return err
+	}
+	c.invalidate(ctx, tenantSettingsKey(s.TenantID))
+	return nil
+}
+
+// GetTenantRateLimit returns the tenant's rate limit override, from cache
+// when present. Most tenants have none, so db.ErrNoTenantRateLimit is
+// cached too.
+func (c *ConfigCache) GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*db.TenantRateLimit, error) {
+	return readThrough(ctx, c, rateLimitKey(tenantID), db.ErrNoTenantRateLimit, func() (*db.TenantRateLimit, error) {
+		return c.Store.GetTenantRateLimit(ctx, tenantID)
+	})
+}
+
+// UpsertTenantRateLimit saves the override and drops the cached copy.
+func (c *ConfigCache) UpsertTenantRateLimit(ctx context.Context, l *db.TenantRateLimit) error {
+	if err := c.Store.UpsertTenantRateLimit(ctx, l); err != nil {
+		return err
+	}
+	c.invalidate(ctx, rateLimitKey(l.TenantID))
+	return nil
+}
+
+// DeleteTenantRateLimit removes the override and drops the cached copy.
+func (c *ConfigCache) DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error {
+	if err := c.Store.DeleteTenantRateLimit(ctx, tenantID); err != nil {
+		return err
+	}
+	c.invalidate(ctx, rateLimitKey(tenantID))
+	return nil
+}
+
+// GetChannelSettings returns the tenant's settings for channel, from cache
+// when present.
+func (c *ConfigCache) GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error) {
+	return readThrough(ctx, c, channelSettingsKey(tenantID, channel), nil, func() (*db.TenantChannelSettings, error) {
+		return c.Store.GetChannelSettings(ctx, tenantID, channel)
+	})
+}
+
+// UpsertChannelSettings saves the settings and drops the cached copy.
+func (c *ConfigCache) UpsertChannelSettings(ctx context.Context, s *db.TenantChannelSettings) error {
+	if err := c.Store.UpsertChannelSettings(ctx, s); err != nil {
+		return err
+	}
+	c.invalidate(ctx, channelSettingsKey(s.TenantID, s.Channel))
+	return nil
+}
+
+// readThrough returns the value cached under key, or loads and caches it.
+// If missing is set, a load failing with it is cached as JSON null and
+// replayed as that error.
+func readThrough[T any](ctx context.Context, c *ConfigCache, key string, missing error, load func() (*T, error)) (*T, error) {
+	raw, err := c.client.rdb.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		var v *T
+		if err := json.Unmarshal(raw, &v); err == nil {
+			if v == nil && missing != nil {
+				return nil, missing
+			}
+			if v != nil {
+				return v, nil
+			}
+		}
+	case !errors.Is(err, redis.Nil):
+		c.logger.Debug("config cache read failed, using the store", zap.Error(err), zap.String("key", key))
+	}
+
+	v, err := load()
+	if err != nil && (missing == nil || !errors.Is(err, missing)) {
+		return nil, err
+	}
+	if setErr := c.set(ctx, key, v); setErr != nil {
+		c.logger.Debug("config cache write failed", zap.Error(setErr), zap.String("key", key))
+	}
+	return v, err
+}
+
+func (c *ConfigCache) set(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", key, err)
+	}
+	return c.client.rdb.Set(ctx, key, data, c.ttl).Err()
+}
+
+// invalidate drops a cached entry after a write. If that fails the entry
+// stays stale until it expires, which the write can't be undone over.
+func (c *ConfigCache) invalidate(ctx context.Context, key string) {
+	if err := c.client.rdb.Del(ctx, key).Err(); err != nil {
+		c.logger.Warn("failed to invalidate cached config, stale until it expires",
+			zap.Error(err),
+			zap.String("key", key),
+			zap.Duration("ttl", c.ttl),
+		)
+	}
+}
@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// fakeConfigStore implements the configuration slice of db.Store; the
+// embedded nil Store panics if the cache calls anything else.
+type fakeConfigStore struct {
+	db.Store
+	reads    int
+	settings map[uuid.UUID]*db.TenantSettings
+	limits   map[uuid.UUID]*db.TenantRateLimit
+	channels map[string]*db.TenantChannelSettings
+}
+
+func newFakeConfigStore() *fakeConfigStore {
+	return &fakeConfigStore{
+		settings: map[uuid.UUID]*db.TenantSettings{},
+		limits:   map[uuid.UUID]*db.TenantRateLimit{},
+		channels: map[string]*db.TenantChannelSettings{},
+	}
+}
+
+func (f *fakeConfigStore) GetTenantSettings(ctx context.Context, tenantID uuid.UUID) (*db.TenantSettings, error) {
+	f.reads++
+	if s, ok := f.settings[tenantID]; ok {
+		return s, nil
+	}
+	return &db.TenantSettings{TenantID: tenantID}, nil
+}
+
+func (f *fakeConfigStore) UpsertTenantSettings(ctx context.Context, s *db.TenantSettings) error {
+	f.settings[s.TenantID] = s
+	return nil
+}
+
+func (f *fakeConfigStore) GetTenantRateLimit(ctx context.Context, tenantID uuid.UUID) (*db.TenantRateLimit, error) {
+	f.reads++
+	if l, ok := f.limits[tenantID]; ok {
+		return l, nil
+	}
+	return nil, db.ErrNoTenantRateLimit
+}
+
+func (f *fakeConfigStore) UpsertTenantRateLimit(ctx context.Context, l *db.TenantRateLimit) error {
+	f.limits[l.TenantID] = l
+	return nil
+}
+
+func (f *fakeConfigStore) DeleteTenantRateLimit(ctx context.Context, tenantID uuid.UUID) error {
+	delete(f.limits, tenantID)
+	return nil
+}
+
+func (f *fakeConfigStore) GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error) {
+	f.reads++
+	if s, ok := f.channels[tenantID.String()+channel]; ok {
+		return s, nil
+	}
+	return &db.TenantChannelSettings{TenantID: tenantID, Channel: channel, Settings: json.RawMessage(`{}`)}, nil
+}
+
+func (f *fakeConfigStore) UpsertChannelSettings(ctx context.Context, s *db.TenantChannelSettings) error {
+	f.channels[s.TenantID.String()+s.Channel] = s
+	return nil
+}
+
+func TestConfigCache_TenantSettings(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	store := newFakeConfigStore()
+	cache := NewConfigCache(store, client, time.Minute, zap.NewNop())
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	for range 3 {
+		s, err := cache.GetTenantSettings(ctx, tenantID)
+		if err != nil || s.RequireIdempotencyKey {
+			t.Fatalf("expected default settings, got %+v, %v", s, err)
+		}
+	}
+	if store.reads != 1 {
+		t.Errorf("expected one store read, got %d", store.reads)
+	}
+
+	if err := cache.UpsertTenantSettings(ctx, &db.TenantSettings{TenantID: tenantID, RequireIdempotencyKey: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, err := cache.GetTenantSettings(ctx, tenantID)
+	if err != nil || !s.RequireIdempotencyKey {
+		t.Errorf("expected the update visible after invalidation, got %+v, %v", s, err)
+	}
+}
+
+func TestConfigCache_TenantRateLimit(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	store := newFakeConfigStore()
+	cache := NewConfigCache(store, client, time.Minute, zap.NewNop())
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	for range 2 {
+		if _, err := cache.GetTenantRateLimit(ctx, tenantID); !errors.Is(err, db.ErrNoTenantRateLimit) {
+			t.Fatalf("expected ErrNoTenantRateLimit, got %v", err)
+		}
+	}
+	if store.reads != 1 {
+		t.Errorf("expected the missing override cached, got %d store reads", store.reads)
+	}
+
+	if err := cache.UpsertTenantRateLimit(ctx, &db.TenantRateLimit{TenantID: tenantID, Burst: 50}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l, err := cache.GetTenantRateLimit(ctx, tenantID)
+	if err != nil || l.Burst != 50 {
+		t.Fatalf("expected burst 50, got %+v, %v", l, err)
+	}
+
+	if err := cache.DeleteTenantRateLimit(ctx, tenantID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cache.GetTenantRateLimit(ctx, tenantID); !errors.Is(err, db.ErrNoTenantRateLimit) {
+		t.Errorf("expected the override gone after delete, got %v", err)
+	}
+}
+
+func TestConfigCache_ChannelSettings(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	store := newFakeConfigStore()
+	cache := NewConfigCache(store, client, time.Minute, zap.NewNop())
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	if err := cache.UpsertChannelSettings(ctx, &db.TenantChannelSettings{
+		TenantID: tenantID,
+		Channel:  "sms",
+		Settings: json.RawMessage(`{"sender_id":"NIMBUS"}`),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 2 {
+		s, err := cache.GetChannelSettings(ctx, tenantID, "sms")
+		if err != nil || string(s.Settings) != `{"sender_id":"NIMBUS"}` {
+			t.Fatalf("unexpected settings %+v, %v", s, err)
+		}
+	}
+	if _, err := cache.GetChannelSettings(ctx, tenantID, "webhook"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.reads != 2 {
+		t.Errorf("expected one store read per channel, got %d", store.reads)
+	}
+}
+
+func TestConfigCache_FallsThroughWithoutRedis(t *testing.T) {
+	client, cleanup := setupTestRedis(t)
+	cleanup()
+
+	store := newFakeConfigStore()
+	cache := NewConfigCache(store, client, time.Minute, zap.NewNop())
+
+	if _, err := cache.GetTenantSettings(context.Background(), uuid.New()); err != nil {
+		t.Fatalf("expected the store read to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// heartbeatKeyPrefix namespaces worker heartbeats; the full key is
+// nimbus:worker:heartbeat:<instance>.
+const heartbeatKeyPrefix = "nimbus:worker:heartbeat:"
+
+// HeartbeatStore publishes worker heartbeats to Redis so liveness is visible
+// across replicas. Each key expires after ttl, so a worker that stops
+// publishing disappears instead of leaving a stale entry behind.
+type HeartbeatStore struct {
+	client *Client
+	ttl    time.Duration
+}
+
+// NewHeartbeatStore creates a heartbeat store with the given key TTL.
+func NewHeartbeatStore(client *Client, ttl time.Duration) *HeartbeatStore {
+	return &HeartbeatStore{client: client, ttl: ttl}
+}
+
+// Publish stores v as JSON under the instance's heartbeat key.
+func (s *HeartbeatStore) Publish(ctx context.Context, instance string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal heartbeat: %w", err)
+	}
+	if err := s.client.rdb.Set(ctx, heartbeatKeyPrefix+instance, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("publish heartbeat: %w", err)
+	}
+	return nil
+}
+
+// List returns the heartbeats of every worker that has published within
+// the TTL, as the JSON they were published as.
+func (s *HeartbeatStore) List(ctx context.Context) ([]json.RawMessage, error) {
+	var keys []string
+	iter := s.client.rdb.Scan(ctx, 0, heartbeatKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("scan heartbeats: %w", err)
+	}
+	if len(keys) == 0 {
+		return []json.RawMessage{}, nil
+	}
+
+	values, err := s.client.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get heartbeats: %w", err)
+	}
+
+	heartbeats := make([]json.RawMessage, 0, len(values))
+	for _, v := range values {
+		// Keys that expired between SCAN and MGET come back nil.
+		if str, ok := v.(string); ok {
+			heartbeats = append(heartbeats, json.RawMessage(str))
+		}
+	}
+	return heartbeats, nil
+}
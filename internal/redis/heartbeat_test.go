@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func TestHeartbeatStore_List(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	store := NewHeartbeatStore(&Client{rdb: rdb, logger: zap.NewNop()}, time.Minute)
+	ctx := context.Background()
+
+	heartbeats, err := store.List(ctx)
+	if err != nil || len(heartbeats) != 0 {
+		t.Fatalf("expected no heartbeats, got %v (err %v)", heartbeats, err)
+	}
+
+	for _, instance := range []string{"a", "b"} {
+		if err := store.Publish(ctx, instance, map[string]string{"instance": instance}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rdb.Set(ctx, "nimbus:other", "x", 0)
+
+	heartbeats, err = store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heartbeats) != 2 {
+		t.Errorf("expected 2 heartbeats, got %d", len(heartbeats))
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if heartbeats, _ = store.List(ctx); len(heartbeats) != 0 {
+		t.Errorf("expected expired heartbeats gone, got %d", len(heartbeats))
+	}
+}
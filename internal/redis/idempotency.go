@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
@@ -23,6 +25,7 @@ const (
 	// processingTTL is the lock duration while a request is being processed.
 	processingTTL = 5 * time.Minute
 
+	// processingMarker prefixes reservation tokens, "processing:<uuid>".
 	processingMarker = "processing"
 )
 
@@ -68,7 +71,13 @@ func (s *IdempotencyService) Check(ctx context.Context, tenantID, idempotencyKey
 		return nil, fmt.Errorf("redis get failed: %w", err)
 	}
 
-	if val == processingMarker {
+	return s.decode(tenantID, val)
+}
+
+// decode turns a stored value into a result. Reservations, including the
+// bare marker older gateways write, mean the request is still in flight.
+func (s *IdempotencyService) decode(tenantID, val string) (*IdempotencyResult, error) {
+	if strings.HasPrefix(val, processingMarker) {
 		return nil, ErrDuplicateRequest
 	}
 
@@ -109,47 +118,45 @@ func (s *IdempotencyService) Store(ctx context.Context, tenantID, idempotencyKey
 	return nil
 }
 
-// Reserve acquires an idempotency lock using SET NX (atomic set-if-not-exists).
-// Returns true if lock acquired, false if key already exists.
-func (s *IdempotencyService) Reserve(ctx context.Context, tenantID, idempotencyKey string) (bool, error) {
-	key := s.buildKey(tenantID, idempotencyKey)
-
-	set, err := s.client.rdb.SetNX(ctx, key, processingMarker, processingTTL).Result()
-	if err != nil {
-		return false, fmt.Errorf("redis setnx failed: %w", err)
-	}
-
-	return set, nil
-}
+// reserveScript returns whatever the key holds, or, if it is empty, sets it
+// to the caller's reservation token and returns nil. Doing both in one
+// script means two gateways racing on the same key can't both see it empty,
+// and a request costs one round trip instead of a GET and a SETNX.
+var reserveScript = redis.NewScript(`
+	local current = redis.call("GET", KEYS[1])
+	if current then
+		return current
+	end
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return false
+`)
 
 // CheckOrReserve atomically checks for an existing result or reserves the key.
-// Returns cached result if found, nil if reserved successfully, or error.
-func (s *IdempotencyService) CheckOrReserve(ctx context.Context, tenantID, idempotencyKey string) (*IdempotencyResult, error) {
-	result, err := s.Check(ctx, tenantID, idempotencyKey)
-	if err != nil {
-		return nil, err
-	}
-	if result != nil {
-		return result, nil
-	}
+// It returns the cached result if there is one, or the reservation token to
+// pass to Release if this request now holds the key. A key held by another
+// request returns ErrDuplicateRequest.
+func (s *IdempotencyService) CheckOrReserve(ctx context.Context, tenantID, idempotencyKey string) (*IdempotencyResult, string, error) {
+	key := s.buildKey(tenantID, idempotencyKey)
+	token := processingMarker + ":" + uuid.NewString()
 
-	reserved, err := s.Reserve(ctx, tenantID, idempotencyKey)
-	if err != nil {
-		return nil, err
+	val, err := reserveScript.Run(ctx, s.client.rdb, []string{key}, token, processingTTL.Milliseconds()).Text()
+	if err == redis.Nil {
+		return nil, token, nil
 	}
-
-	if !reserved {
-		return nil, ErrDuplicateRequest
+	if err != nil {
+		return nil, "", fmt.Errorf("redis reserve failed: %w", err)
 	}
 
-	return nil, nil
+	result, err := s.decode(tenantID, val)
+	return result, "", err
 }
 
 // releaseScript atomically deletes the key ONLY if it still holds the
-// "processing" marker. We must not delete a key that has already been
+// caller's reservation token. We must not delete a key that has already been
 // overwritten with a real stored result — that would let a duplicate request
-// re-execute. Compare-and-delete in a single Lua script makes this race-free
-// (Redis runs the whole script atomically).
+// re-execute — nor one another request reserved after ours expired.
+// Compare-and-delete in a single Lua script makes this race-free (Redis runs
+// the whole script atomically).
 var releaseScript = redis.NewScript(`
 	if redis.call("GET", KEYS[1]) == ARGV[1] then
 		return redis.call("DEL", KEYS[1])
@@ -158,12 +165,12 @@ var releaseScript = redis.NewScript(`
 `)
 
 // Release frees a reservation made by CheckOrReserve when the request ultimately
-// FAILED (e.g. the DB write errored). Without this, the "processing" marker would
+// FAILED (e.g. the DB write errored). Without this, the reservation would
 // linger for the full processingTTL (5 min), and every retry of the failed
 // request would get a 409 Conflict — effectively a 5-minute outage for that key.
 //
-// It is a no-op if the key already holds a stored success result, so it's always
-// safe to call on the error path.
+// It is a no-op if the key no longer holds the reservation, so it's always safe
+// to call on the error path.
 //
 // Interview talking point:
 // "Reserving an idempotency key is a lock. Any lock you take, you must release on
@@ -171,9 +178,9 @@ var releaseScript = redis.NewScript(`
 //	the failure path — otherwise a transient DB blip poisons that key for the whole
 //	TTL and the client can't retry. I release with a compare-and-delete Lua script
 //	so I never clobber a result that another request legitimately stored."
-func (s *IdempotencyService) Release(ctx context.Context, tenantID, idempotencyKey string) error {
+func (s *IdempotencyService) Release(ctx context.Context, tenantID, idempotencyKey, reservation string) error {
 	key := s.buildKey(tenantID, idempotencyKey)
-	if err := releaseScript.Run(ctx, s.client.rdb, []string{key}, processingMarker).Err(); err != nil {
+	if err := releaseScript.Run(ctx, s.client.rdb, []string{key}, reservation).Err(); err != nil {
 		return fmt.Errorf("redis release failed: %w", err)
 	}
 	return nil
@@ -33,13 +33,16 @@ func TestIdempotencyService_NewRequest(t *testing.T) {
 	svc := NewIdempotencyService(client, zap.NewNop())
 	ctx := context.Background()
 
-	result, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
+	result, reservation, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if result != nil {
 		t.Fatalf("expected nil result for new request, got: %+v", result)
 	}
+	if reservation == "" {
+		t.Fatal("expected a reservation token for a new request")
+	}
 }
 
 func TestIdempotencyService_DuplicateRequest(t *testing.T) {
@@ -50,13 +53,21 @@ func TestIdempotencyService_DuplicateRequest(t *testing.T) {
 	ctx := context.Background()
 
 	// First request
-	if _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != nil {
+	if _, _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != nil {
 		t.Fatalf("first request failed: %v", err)
 	}
 
 	// Duplicate request
-	if _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != ErrDuplicateRequest {
-		t.Fatalf("expected ErrDuplicateRequest, got: %v", err)
+	if _, reservation, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != ErrDuplicateRequest || reservation != "" {
+		t.Fatalf("expected ErrDuplicateRequest without a reservation, got: %q, %v", reservation, err)
+	}
+
+	// A reservation written by an older gateway is still in flight
+	if err := client.rdb.Set(ctx, svc.buildKey("tenant-1", "key-2"), processingMarker, time.Minute).Err(); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-2"); err != ErrDuplicateRequest {
+		t.Fatalf("expected ErrDuplicateRequest for a legacy marker, got: %v", err)
 	}
 }
 
@@ -97,12 +108,12 @@ func TestIdempotencyService_TenantIsolation(t *testing.T) {
 	ctx := context.Background()
 
 	// Tenant A reserves a key
-	if _, err := svc.CheckOrReserve(ctx, "tenant-A", "same-key"); err != nil {
+	if _, _, err := svc.CheckOrReserve(ctx, "tenant-A", "same-key"); err != nil {
 		t.Fatalf("tenant A failed: %v", err)
 	}
 
 	// Tenant B can use the same key
-	result, err := svc.CheckOrReserve(ctx, "tenant-B", "same-key")
+	result, _, err := svc.CheckOrReserve(ctx, "tenant-B", "same-key")
 	if err != nil {
 		t.Fatalf("tenant B should succeed: %v", err)
 	}
@@ -119,9 +130,9 @@ func TestIdempotencyService_ReserveThenStore(t *testing.T) {
 	ctx := context.Background()
 
 	// Reserve
-	reserved, err := svc.Reserve(ctx, "tenant-1", "key-1")
-	if err != nil || !reserved {
-		t.Fatalf("reserve failed: %v, reserved: %v", err, reserved)
+	_, reservation, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
+	if err != nil || reservation == "" {
+		t.Fatalf("reserve failed: %v, reservation: %q", err, reservation)
 	}
 
 	// Store result
@@ -132,13 +143,13 @@ func TestIdempotencyService_ReserveThenStore(t *testing.T) {
 		t.Fatalf("store failed: %v", err)
 	}
 
-	// Check returns stored result
-	cached, err := svc.Check(ctx, "tenant-1", "key-1")
+	// A retry gets the stored result, not a reservation
+	cached, reservation, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
 	if err != nil {
 		t.Fatalf("check failed: %v", err)
 	}
-	if cached.NotificationID != "notif-789" {
-		t.Errorf("expected notif-789, got %s", cached.NotificationID)
+	if cached == nil || cached.NotificationID != "notif-789" || reservation != "" {
+		t.Errorf("expected notif-789 without a reservation, got %+v, %q", cached, reservation)
 	}
 }
 
@@ -153,17 +164,26 @@ func TestIdempotencyService_ReleaseFreesReservation(t *testing.T) {
 	ctx := context.Background()
 
 	// First attempt reserves the key.
-	if _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != nil {
+	_, reservation, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
+	if err != nil {
 		t.Fatalf("first reserve failed: %v", err)
 	}
 
+	// Someone else's token doesn't release it.
+	if err := svc.Release(ctx, "tenant-1", "key-1", processingMarker+":other"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if _, _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != ErrDuplicateRequest {
+		t.Fatalf("expected the reservation to survive another token's release, got: %v", err)
+	}
+
 	// Simulate the request failing → release the reservation.
-	if err := svc.Release(ctx, "tenant-1", "key-1"); err != nil {
+	if err := svc.Release(ctx, "tenant-1", "key-1", reservation); err != nil {
 		t.Fatalf("release failed: %v", err)
 	}
 
 	// A retry must now succeed as a NEW request, not get ErrDuplicateRequest.
-	result, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
+	result, _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
 	if err != nil {
 		t.Fatalf("retry after release should succeed, got: %v", err)
 	}
@@ -182,7 +202,8 @@ func TestIdempotencyService_ReleaseDoesNotClobberStoredResult(t *testing.T) {
 	svc := NewIdempotencyService(client, zap.NewNop())
 	ctx := context.Background()
 
-	if _, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1"); err != nil {
+	_, reservation, err := svc.CheckOrReserve(ctx, "tenant-1", "key-1")
+	if err != nil {
 		t.Fatalf("reserve failed: %v", err)
 	}
 
@@ -195,8 +216,8 @@ func TestIdempotencyService_ReleaseDoesNotClobberStoredResult(t *testing.T) {
 	}
 
 	// A late/erroneous Release must be a no-op because the value is no longer
-	// the processing marker — the stored result has to survive.
-	if err := svc.Release(ctx, "tenant-1", "key-1"); err != nil {
+	// the reservation — the stored result has to survive.
+	if err := svc.Release(ctx, "tenant-1", "key-1", reservation); err != nil {
 		t.Fatalf("release failed: %v", err)
 	}
 
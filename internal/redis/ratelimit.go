@@ -2,40 +2,68 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/lalithlochan/nimbus/internal/metrics"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 // RateLimitConfig defines rate limiting parameters.
 type RateLimitConfig struct {
+	Name   string        // Labels the limiter's metrics, e.g. "tenant" or "route:/v1/ai/compose"
 	Limit  int           // Maximum requests allowed
 	Window time.Duration // Time window for the limit
+	Burst  int           // Extra requests tolerated on top of Limit within a window
+
+	// FallbackPercent is the share of Limit (and Burst) each process still
+	// allows, from an in-memory token bucket, while Redis is unreachable.
+	// It is per process, so keep it conservative when running several
+	// replicas. 0 fails open: every request is allowed during an outage.
+	FallbackPercent int
 }
 
 // RateLimitResult contains the result of a rate limit check.
 type RateLimitResult struct {
 	Allowed   bool
-	Remaining int
-	ResetAt   time.Time
+	Limit     int           // Maximum for the window, burst included
+	Remaining int           // Requests left in the current window
+	ResetAt   time.Time     // When the window fully resets
+	Window    time.Duration // Length of the window, for RateLimit-Policy
 }
 
+// errNoRedis is what a limiter built without a Redis client degrades on.
+var errNoRedis = errors.New("redis unavailable at startup")
+
 // RateLimiter implements sliding window rate limiting using Redis.
 type RateLimiter struct {
-	client *Client
-	logger *zap.Logger
-	config RateLimitConfig
+	client   *Client
+	logger   *zap.Logger
+	config   RateLimitConfig
+	fallback *localLimiter // nil when FallbackPercent is 0
+
+	// isDegraded is set while Redis checks fail, so entering and leaving
+	// degraded mode is logged, and the degraded gauge written, once rather
+	// than on every request.
+	isDegraded atomic.Bool
 }
 
 // NewRateLimiter creates a new rate limiter with the given configuration.
+// A nil client, when Redis was unreachable at startup, gives a limiter that
+// stays degraded: it enforces FallbackPercent in memory, or fails open.
 func NewRateLimiter(client *Client, logger *zap.Logger, config RateLimitConfig) *RateLimiter {
-	return &RateLimiter{
+	r := &RateLimiter{
 		client: client,
 		logger: logger,
 		config: config,
 	}
+	if config.FallbackPercent > 0 {
+		r.fallback = newLocalLimiter(config.Window)
+	}
+	return r
 }
 
 // Allow checks if a request is allowed under the rate limit.
@@ -46,6 +74,21 @@ func (r *RateLimiter) Allow(ctx context.Context, key string) (*RateLimitResult,
 
 // AllowN checks if n requests are allowed under the rate limit.
 func (r *RateLimiter) AllowN(ctx context.Context, key string, n int) (*RateLimitResult, error) {
+	return r.allow(ctx, key, n, r.config.Burst)
+}
+
+// AllowBurst is Allow with burst in place of the configured Burst, for
+// keys with their own allowance (e.g. a tenant override).
+func (r *RateLimiter) AllowBurst(ctx context.Context, key string, burst int) (*RateLimitResult, error) {
+	return r.allow(ctx, key, 1, burst)
+}
+
+func (r *RateLimiter) allow(ctx context.Context, key string, n, burst int) (*RateLimitResult, error) {
+	if r.client == nil {
+		return r.degraded(key, n, burst, errNoRedis)
+	}
+
+	limit := r.config.Limit + max(0, burst)
 	now := time.Now()
 	windowStart := now.Add(-r.config.Window)
 	resetAt := now.Add(r.config.Window)
@@ -64,23 +107,26 @@ func (r *RateLimiter) AllowN(ctx context.Context, key string, n int) (*RateLimit
 	// Execute pipeline
 	_, err := pipe.Exec(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("redis pipeline failed: %w", err)
+		return r.degraded(key, n, burst, fmt.Errorf("redis pipeline failed: %w", err))
 	}
 
 	currentCount := int(countCmd.Val())
-	remaining := r.config.Limit - currentCount
+	remaining := limit - currentCount
 
 	// Check if request would exceed limit
-	if currentCount+n > r.config.Limit {
+	if currentCount+n > limit {
 		r.logger.Debug("rate limit exceeded",
 			zap.String("key", key),
 			zap.Int("current", currentCount),
-			zap.Int("limit", r.config.Limit),
+			zap.Int("limit", limit),
 		)
+		r.recovered()
 		return &RateLimitResult{
 			Allowed:   false,
+			Limit:     limit,
 			Remaining: max(0, remaining),
 			ResetAt:   resetAt,
+			Window:    r.config.Window,
 		}, nil
 	}
 
@@ -101,12 +147,53 @@ func (r *RateLimiter) AllowN(ctx context.Context, key string, n int) (*RateLimit
 	pipe2.Expire(ctx, redisKey, r.config.Window+time.Second)
 
 	if _, err := pipe2.Exec(ctx); err != nil {
-		return nil, fmt.Errorf("redis zadd failed: %w", err)
+		return r.degraded(key, n, burst, fmt.Errorf("redis zadd failed: %w", err))
 	}
+	r.recovered()
 
 	return &RateLimitResult{
 		Allowed:   true,
+		Limit:     limit,
 		Remaining: remaining - n,
 		ResetAt:   resetAt,
+		Window:    r.config.Window,
 	}, nil
 }
+
+// degraded answers a check from the in-memory fallback after Redis failed
+// with err. Without a fallback the error is returned, and callers fail
+// open.
+func (r *RateLimiter) degraded(key string, n, burst int, err error) (*RateLimitResult, error) {
+	if !r.isDegraded.Swap(true) {
+		metrics.SetRateLimitDegraded(r.config.Name, true)
+		msg := "rate limiter degraded to in-memory fallback"
+		if r.fallback == nil {
+			msg = "rate limiter degraded, failing open"
+		}
+		r.logger.Warn(msg,
+			zap.String("limiter", r.config.Name),
+			zap.Int("limit", r.config.Limit),
+			zap.Duration("window", r.config.Window),
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
+	if r.fallback == nil {
+		return nil, err
+	}
+
+	limit := max(1, (r.config.Limit+max(0, burst))*r.config.FallbackPercent/100)
+	return r.fallback.allow(key, n, limit, time.Now()), nil
+}
+
+// recovered marks a check Redis answered, logging the end of degraded mode.
+func (r *RateLimiter) recovered() {
+	if r.isDegraded.Swap(false) {
+		metrics.SetRateLimitDegraded(r.config.Name, false)
+		r.logger.Info("rate limiter recovered, using redis again",
+			zap.String("limiter", r.config.Name),
+			zap.Int("limit", r.config.Limit),
+			zap.Duration("window", r.config.Window),
+		)
+	}
+}
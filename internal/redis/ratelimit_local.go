@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"sync"
+	"time"
+)
+
+// localLimiter is a per-process token bucket per key, used by RateLimiter
+// while Redis is unreachable. Each bucket holds up to limit tokens and
+// refills at limit per window, so a key gets about the same rate as the
+// sliding window, just without a shared count across replicas.
+type localLimiter struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// localLimiterMaxKeys bounds the bucket map; past it, buckets that have
+// refilled completely are dropped since they are equivalent to new ones.
+const localLimiterMaxKeys = 10000
+
+func newLocalLimiter(window time.Duration) *localLimiter {
+	return &localLimiter{window: window, buckets: make(map[string]*tokenBucket)}
+}
+
+func (l *localLimiter) allow(key string, n, limit int, now time.Time) *RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= localLimiterMaxKeys {
+			l.sweep(now)
+		}
+		b = &tokenBucket{tokens: float64(limit), last: now}
+		l.buckets[key] = b
+	}
+
+	rate := float64(limit) / l.window.Seconds()
+	b.tokens = min(float64(limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	allowed := b.tokens >= float64(n)
+	if allowed {
+		b.tokens -= float64(n)
+	}
+	missing := float64(limit) - b.tokens
+	return &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(b.tokens),
+		ResetAt:   now.Add(time.Duration(missing / rate * float64(time.Second))),
+		Window:    l.window,
+	}
+}
+
+// sweep drops buckets that are full again. Called with mu held.
+func (l *localLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.window {
+			delete(l.buckets, key)
+		}
+	}
+}
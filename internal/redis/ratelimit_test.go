@@ -2,12 +2,18 @@ package redis
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/lalithlochan/nimbus/internal/metrics"
 )
 
 func setupTestRateLimiter(t *testing.T, limit int, window time.Duration) (*RateLimiter, func()) {
@@ -123,3 +129,223 @@ func TestRateLimiter_AllowN(t *testing.T) {
 		t.Fatal("should be blocked")
 	}
 }
+
+func TestRateLimiter_ReportsConfiguredLimit(t *testing.T) {
+	limiter, cleanup := setupTestRateLimiter(t, 3, time.Minute)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		result, err := limiter.Allow(ctx, "test-key")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		// Limit must stay at the configured value regardless of how much of
+		// the window has been consumed.
+		if result.Limit != 3 {
+			t.Errorf("request %d: expected limit 3, got %d", i, result.Limit)
+		}
+		if result.Window != time.Minute {
+			t.Errorf("request %d: expected window 1m, got %s", i, result.Window)
+		}
+	}
+}
+
+func TestRateLimiter_Burst(t *testing.T) {
+	limiter, cleanup := setupTestRateLimiter(t, 3, time.Minute)
+	defer cleanup()
+	limiter.config.Burst = 2
+
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		result, err := limiter.Allow(ctx, "burst-key")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d should be allowed within limit plus burst", i)
+		}
+		if result.Limit != 5 {
+			t.Errorf("expected limit to include the burst, got %d", result.Limit)
+		}
+	}
+	if result, _ := limiter.Allow(ctx, "burst-key"); result.Allowed {
+		t.Error("expected the request after the burst to be blocked")
+	}
+
+	// A key's own allowance replaces the configured one.
+	for i := 0; i < 3; i++ {
+		if result, _ := limiter.AllowBurst(ctx, "override-key", 0); !result.Allowed {
+			t.Fatalf("request %d should be allowed within the limit", i)
+		}
+	}
+	if result, _ := limiter.AllowBurst(ctx, "override-key", 0); result.Allowed || result.Limit != 3 {
+		t.Errorf("expected a zero override to drop the burst, got %+v", result)
+	}
+}
+
+func TestRateLimiter_FallbackWhenRedisDown(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer rdb.Close()
+	client := &Client{rdb: rdb, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	failOpen := NewRateLimiter(client, zap.NewNop(), RateLimitConfig{Limit: 8, Window: time.Minute})
+	limiter := NewRateLimiter(client, zap.NewNop(), RateLimitConfig{Limit: 8, Window: time.Minute, FallbackPercent: 50})
+	mr.Close()
+
+	if _, err := failOpen.Allow(ctx, "k"); err == nil {
+		t.Error("expected an error without a fallback")
+	}
+
+	// Half of 8 is enforced per process while Redis is unreachable.
+	for i := 0; i < 4; i++ {
+		result, err := limiter.Allow(ctx, "k")
+		if err != nil {
+			t.Fatalf("request %d: expected the fallback to answer, got %v", i, err)
+		}
+		if !result.Allowed || result.Limit != 4 {
+			t.Fatalf("request %d: expected allowed with limit 4, got %+v", i, result)
+		}
+	}
+	if result, _ := limiter.Allow(ctx, "k"); result.Allowed {
+		t.Error("expected the fallback to block past its limit")
+	}
+	if result, _ := limiter.Allow(ctx, "other"); !result.Allowed {
+		t.Error("expected keys to have their own buckets")
+	}
+}
+
+func TestRateLimiter_LogsDegradedOnce(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer rdb.Close()
+	core, logs := observer.New(zapcore.InfoLevel)
+	limiter := NewRateLimiter(&Client{rdb: rdb, logger: zap.NewNop()}, zap.New(core), RateLimitConfig{Limit: 100, Window: time.Minute, FallbackPercent: 50})
+	ctx := context.Background()
+
+	mr.Close()
+	for i := 0; i < 5; i++ {
+		_, _ = limiter.Allow(ctx, "k")
+	}
+	if n := logs.FilterMessage("rate limiter degraded to in-memory fallback").Len(); n != 1 {
+		t.Errorf("expected 1 degraded log over 5 requests, got %d", n)
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("failed to restart miniredis: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := limiter.Allow(ctx, "k"); err != nil {
+			t.Fatalf("expected redis to answer after the restart, got %v", err)
+		}
+	}
+	if n := logs.FilterMessage("rate limiter recovered, using redis again").Len(); n != 1 {
+		t.Errorf("expected 1 recovery log, got %d", n)
+	}
+}
+
+// gaugeRecorder is a metrics.Sink keeping every rate limiter degraded gauge
+// write, as "limiter=value".
+type gaugeRecorder struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (g *gaugeRecorder) IncCounter(string, metrics.Labels)       {}
+func (g *gaugeRecorder) Observe(string, float64, metrics.Labels) {}
+func (g *gaugeRecorder) SetGauge(name string, value float64, labels metrics.Labels) {
+	if name != "nimbus_rate_limit_degraded" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.writes = append(g.writes, fmt.Sprintf("%s=%g", labels["limiter"], value))
+}
+
+func TestRateLimiter_DegradedGaugeOnTransitions(t *testing.T) {
+	recorder := &gaugeRecorder{}
+	metrics.SetSinks(recorder)
+	defer metrics.SetSinks(metrics.Prometheus())
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer rdb.Close()
+	client := &Client{rdb: rdb, logger: zap.NewNop()}
+	failOpen := NewRateLimiter(client, zap.NewNop(), RateLimitConfig{Name: "global", Limit: 100, Window: time.Minute})
+	limiter := NewRateLimiter(client, zap.NewNop(), RateLimitConfig{Name: "tenant", Limit: 100, Window: time.Minute, FallbackPercent: 50})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, _ = failOpen.Allow(ctx, "k")
+		_, _ = limiter.Allow(ctx, "k")
+	}
+	mr.Close()
+	for i := 0; i < 3; i++ {
+		_, _ = failOpen.Allow(ctx, "k")
+		_, _ = limiter.Allow(ctx, "k")
+	}
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("failed to restart miniredis: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_, _ = failOpen.Allow(ctx, "k")
+		_, _ = limiter.Allow(ctx, "k")
+	}
+
+	want := []string{"global=1", "tenant=1", "global=0", "tenant=0"}
+	if fmt.Sprint(recorder.writes) != fmt.Sprint(want) {
+		t.Errorf("expected gauge writes %v, got %v", want, recorder.writes)
+	}
+}
+
+func TestRateLimiter_WithoutRedisAtStartup(t *testing.T) {
+	ctx := context.Background()
+
+	failOpen := NewRateLimiter(nil, zap.NewNop(), RateLimitConfig{Limit: 8, Window: time.Minute})
+	if _, err := failOpen.Allow(ctx, "k"); err == nil {
+		t.Error("expected an error without a fallback")
+	}
+
+	limiter := NewRateLimiter(nil, zap.NewNop(), RateLimitConfig{Limit: 8, Window: time.Minute, FallbackPercent: 50})
+	for i := 0; i < 4; i++ {
+		if result, err := limiter.Allow(ctx, "k"); err != nil || !result.Allowed {
+			t.Fatalf("request %d: expected the fallback to allow, got %+v, %v", i, result, err)
+		}
+	}
+	if result, _ := limiter.Allow(ctx, "k"); result.Allowed {
+		t.Error("expected the fallback to block past its limit")
+	}
+}
+
+func TestLocalLimiter_Refills(t *testing.T) {
+	l := newLocalLimiter(time.Minute)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if !l.allow("k", 1, 2, now).Allowed {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	if l.allow("k", 1, 2, now).Allowed {
+		t.Fatal("expected the bucket to be empty")
+	}
+	// Two per minute refills one token every 30 seconds.
+	if !l.allow("k", 1, 2, now.Add(30*time.Second)).Allowed {
+		t.Error("expected a token after 30s")
+	}
+}
@@ -0,0 +1,138 @@
+// Package reputation protects the platform's email sender reputation from a
+// single tenant's bad lists. A periodic guard compares each tenant's recent
+// hard-bounce and complaint rates against thresholds and throttles or
+// pauses their email when they cross them; the worker enforces throttles
+// through Throttle, and the claim query enforces pauses.
+package reputation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Window is how far back the guard looks when computing rates.
+const Window = 24 * time.Hour
+
+// Actions recorded in nimbus_reputation_actions_total.
+const (
+	ActionThrottle = "throttle"
+	ActionPause    = "pause"
+	ActionLift     = "lift"
+)
+
+// Thresholds decide when a tenant is throttled or paused. Rates are
+// fractions of emails sent in the window.
+type Thresholds struct {
+	MinSent           int64 // below this volume a tenant isn't judged
+	BounceThrottle    float64
+	BouncePause       float64
+	ComplaintThrottle float64
+	ComplaintPause    float64
+}
+
+// Evaluate returns the state rep warrants: db.SendLimitPaused,
+// db.SendLimitThrottled, or "" for none. ok is false when the tenant sent
+// too little to judge, in which case any current limit should stay as is.
+func (t Thresholds) Evaluate(rep *db.TenantReputation) (state, reason string, ok bool) {
+	if rep.Sent < t.MinSent {
+		return "", "", false
+	}
+
+	bounceRate := float64(rep.HardBounces) / float64(rep.Sent)
+	complaintRate := float64(rep.Complaints) / float64(rep.Sent)
+	reason = fmt.Sprintf("hard bounce rate %.2f%%, complaint rate %.3f%% over %d emails",
+		bounceRate*100, complaintRate*100, rep.Sent)
+
+	switch {
+	case bounceRate >= t.BouncePause || complaintRate >= t.ComplaintPause:
+		return db.SendLimitPaused, reason, true
+	case bounceRate >= t.BounceThrottle || complaintRate >= t.ComplaintThrottle:
+		return db.SendLimitThrottled, reason, true
+	default:
+		return "", reason, true
+	}
+}
+
+// Store reads reputation and reads and writes send limits.
+type Store interface {
+	GetEmailReputation(ctx context.Context, since time.Time) ([]*db.TenantReputation, error)
+	ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error)
+	SetTenantSendLimit(ctx context.Context, l *db.TenantSendLimit) error
+	LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error
+}
+
+// Guard returns a job body that applies thresholds to every tenant's email
+// reputation. It escalates (throttle, then pause) and lifts throttles once
+// a tenant recovers, but never lifts a pause: that takes an operator, via
+// DELETE /v1/admin/tenants/{tenantID}/send-limits/email.
+func Guard(store Store, thresholds Thresholds, logger *zap.Logger) func(context.Context) error {
+	return func(ctx context.Context) error {
+		reps, err := store.GetEmailReputation(ctx, time.Now().Add(-Window))
+		if err != nil {
+			return fmt.Errorf("get email reputation: %w", err)
+		}
+		limits, err := store.ListTenantSendLimits(ctx)
+		if err != nil {
+			return fmt.Errorf("list send limits: %w", err)
+		}
+
+		current := make(map[uuid.UUID]string)
+		for _, l := range limits {
+			if l.Channel == db.ChannelEmail {
+				current[l.TenantID] = l.State
+			}
+		}
+
+		for _, rep := range reps {
+			state, reason, ok := thresholds.Evaluate(rep)
+			if !ok {
+				continue
+			}
+			log := logger.With(
+				zap.String("tenant_id", rep.TenantID.String()),
+				zap.String("reason", reason),
+			)
+
+			have := current[rep.TenantID]
+			switch {
+			case state == have, have == db.SendLimitPaused:
+				continue
+			case state == "":
+				if err := store.LiftTenantSendLimit(ctx, rep.TenantID, db.ChannelEmail); err != nil {
+					return fmt.Errorf("lift send limit: %w", err)
+				}
+				metrics.RecordReputationAction(ActionLift)
+				log.Info("tenant email reputation recovered, throttle lifted")
+			default:
+				limit := &db.TenantSendLimit{
+					TenantID: rep.TenantID,
+					Channel:  db.ChannelEmail,
+					State:    state,
+					Reason:   reason,
+				}
+				if err := store.SetTenantSendLimit(ctx, limit); err != nil {
+					return fmt.Errorf("set send limit: %w", err)
+				}
+				action := ActionThrottle
+				if state == db.SendLimitPaused {
+					action = ActionPause
+				}
+				metrics.RecordReputationAction(action)
+				// Logged at error level so it pages like any other alert.
+				log.Error("tenant email "+state+" for poor sender reputation",
+					zap.Int64("sent", rep.Sent),
+					zap.Int64("hard_bounces", rep.HardBounces),
+					zap.Int64("complaints", rep.Complaints),
+				)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package reputation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+var testThresholds = Thresholds{
+	MinSent:           100,
+	BounceThrottle:    0.05,
+	BouncePause:       0.10,
+	ComplaintThrottle: 0.001,
+	ComplaintPause:    0.005,
+}
+
+func TestThresholds_Evaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		rep       db.TenantReputation
+		wantState string
+		wantOK    bool
+	}{
+		{"too little volume", db.TenantReputation{Sent: 50, HardBounces: 50}, "", false},
+		{"healthy", db.TenantReputation{Sent: 1000, HardBounces: 10}, "", true},
+		{"bounces throttle", db.TenantReputation{Sent: 1000, HardBounces: 60}, db.SendLimitThrottled, true},
+		{"bounces pause", db.TenantReputation{Sent: 1000, HardBounces: 100}, db.SendLimitPaused, true},
+		{"complaints throttle", db.TenantReputation{Sent: 1000, Complaints: 2}, db.SendLimitThrottled, true},
+		{"complaints pause", db.TenantReputation{Sent: 1000, Complaints: 5}, db.SendLimitPaused, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, _, ok := testThresholds.Evaluate(&tt.rep)
+			if state != tt.wantState || ok != tt.wantOK {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tt.wantState, tt.wantOK, state, ok)
+			}
+		})
+	}
+}
+
+type fakeStore struct {
+	reps   []*db.TenantReputation
+	limits map[uuid.UUID]*db.TenantSendLimit
+}
+
+func (f *fakeStore) GetEmailReputation(ctx context.Context, since time.Time) ([]*db.TenantReputation, error) {
+	return f.reps, nil
+}
+
+func (f *fakeStore) ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error) {
+	var out []*db.TenantSendLimit
+	for _, l := range f.limits {
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func (f *fakeStore) SetTenantSendLimit(ctx context.Context, l *db.TenantSendLimit) error {
+	f.limits[l.TenantID] = l
+	return nil
+}
+
+func (f *fakeStore) LiftTenantSendLimit(ctx context.Context, tenantID uuid.UUID, channel string) error {
+	delete(f.limits, tenantID)
+	return nil
+}
+
+func TestGuard(t *testing.T) {
+	bouncing, recovered, paused := uuid.New(), uuid.New(), uuid.New()
+	store := &fakeStore{
+		reps: []*db.TenantReputation{
+			{TenantID: bouncing, Sent: 1000, HardBounces: 70},
+			{TenantID: recovered, Sent: 1000},
+			{TenantID: paused, Sent: 1000},
+		},
+		limits: map[uuid.UUID]*db.TenantSendLimit{
+			recovered: {TenantID: recovered, Channel: db.ChannelEmail, State: db.SendLimitThrottled},
+			paused:    {TenantID: paused, Channel: db.ChannelEmail, State: db.SendLimitPaused},
+		},
+	}
+
+	if err := Guard(store, testThresholds, zap.NewNop())(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if l := store.limits[bouncing]; l == nil || l.State != db.SendLimitThrottled {
+		t.Errorf("expected bouncing tenant throttled, got %+v", l)
+	}
+	if _, ok := store.limits[recovered]; ok {
+		t.Error("expected recovered tenant's throttle lifted")
+	}
+	if l := store.limits[paused]; l == nil || l.State != db.SendLimitPaused {
+		t.Errorf("expected pause left for an operator to lift, got %+v", l)
+	}
+}
+
+func TestThrottle_Delay(t *testing.T) {
+	tenantID := uuid.New()
+	store := &fakeStore{limits: map[uuid.UUID]*db.TenantSendLimit{
+		tenantID: {TenantID: tenantID, Channel: db.ChannelEmail, State: db.SendLimitThrottled},
+	}}
+	throttle := NewThrottle(store, 2, zap.NewNop())
+	ctx := context.Background()
+
+	email := &db.Notification{TenantID: tenantID, Channel: db.ChannelEmail}
+	for i := 0; i < 2; i++ {
+		if d := throttle.Delay(ctx, email); d != 0 {
+			t.Fatalf("send %d: expected no delay, got %s", i+1, d)
+		}
+	}
+	if d := throttle.Delay(ctx, email); d <= 0 || d > time.Minute {
+		t.Errorf("expected a delay of up to a minute once over the limit, got %s", d)
+	}
+
+	sms := &db.Notification{TenantID: tenantID, Channel: db.ChannelSMS}
+	if d := throttle.Delay(ctx, sms); d != 0 {
+		t.Errorf("expected other channels unthrottled, got %s", d)
+	}
+	other := &db.Notification{TenantID: uuid.New(), Channel: db.ChannelEmail}
+	if d := throttle.Delay(ctx, other); d != 0 {
+		t.Errorf("expected other tenants unthrottled, got %s", d)
+	}
+}
+
+type fakeWarmupStore struct {
+	allowed, graduated bool
+	calls              int
+}
+
+func (f *fakeWarmupStore) ReserveWarmupSend(ctx context.Context, tenantID uuid.UUID, schedule []int) (bool, bool, error) {
+	f.calls++
+	return f.allowed, f.graduated, nil
+}
+
+func TestWarmup_Delay(t *testing.T) {
+	ctx := context.Background()
+	email := &db.Notification{TenantID: uuid.New(), Channel: db.ChannelEmail}
+
+	store := &fakeWarmupStore{allowed: false}
+	warmup := NewWarmup(store, []int{50, 100}, zap.NewNop())
+	if d := warmup.Delay(ctx, email); d <= 0 || d > 24*time.Hour {
+		t.Errorf("expected a delay until the next UTC day over the cap, got %s", d)
+	}
+	if d := warmup.Delay(ctx, &db.Notification{TenantID: email.TenantID, Channel: db.ChannelSMS}); d != 0 {
+		t.Errorf("expected sms uncapped, got %s", d)
+	}
+
+	store = &fakeWarmupStore{allowed: true, graduated: true}
+	warmup = NewWarmup(store, []int{50, 100}, zap.NewNop())
+	for i := 0; i < 3; i++ {
+		if d := warmup.Delay(ctx, email); d != 0 {
+			t.Fatalf("expected no delay, got %s", d)
+		}
+	}
+	if store.calls != 1 {
+		t.Errorf("expected a graduated tenant to skip the store, got %d calls", store.calls)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	var asked []string
+	delay := func(name string, d time.Duration) func(context.Context, *db.Notification) time.Duration {
+		return func(context.Context, *db.Notification) time.Duration {
+			asked = append(asked, name)
+			return d
+		}
+	}
+
+	got := Combine(delay("a", 0), delay("b", time.Minute), delay("c", time.Hour))(context.Background(), &db.Notification{})
+	if got != time.Minute || len(asked) != 2 {
+		t.Errorf("expected b's delay with c never asked, got %s after %v", got, asked)
+	}
+}
@@ -0,0 +1,112 @@
+package reputation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// refreshInterval is how stale the throttle's view of send limits may get.
+const refreshInterval = 30 * time.Second
+
+// LimitSource lists the active send limits.
+type LimitSource interface {
+	ListTenantSendLimits(ctx context.Context) ([]*db.TenantSendLimit, error)
+}
+
+// Throttle caps how fast a throttled tenant's notifications are sent. The
+// count is kept per process, so with several worker replicas a tenant's
+// effective rate is perMinute times the replica count.
+type Throttle struct {
+	source    LimitSource
+	perMinute int
+	logger    *zap.Logger
+
+	mu        sync.Mutex
+	throttled map[limitKey]bool
+	loadedAt  time.Time
+	windows   map[limitKey]*window
+}
+
+type limitKey struct {
+	tenantID uuid.UUID
+	channel  string
+}
+
+// window is a fixed one-minute counting window.
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewThrottle creates a throttle allowing perMinute sends per throttled
+// tenant and channel.
+func NewThrottle(source LimitSource, perMinute int, logger *zap.Logger) *Throttle {
+	return &Throttle{
+		source:    source,
+		perMinute: perMinute,
+		logger:    logger,
+		throttled: make(map[limitKey]bool),
+		windows:   make(map[limitKey]*window),
+	}
+}
+
+// Delay reports how long notif must wait before it may be sent; zero means
+// send now. It fits worker.Config.Throttle.
+func (t *Throttle) Delay(ctx context.Context, notif *db.Notification) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.loadedAt) >= refreshInterval {
+		t.refresh(ctx, now)
+	}
+
+	key := limitKey{tenantID: notif.TenantID, channel: notif.Channel}
+	if !t.throttled[key] {
+		return 0
+	}
+
+	w := t.windows[key]
+	if w == nil || now.Sub(w.start) >= time.Minute {
+		w = &window{start: now}
+		t.windows[key] = w
+	}
+	if w.count >= t.perMinute {
+		return w.start.Add(time.Minute).Sub(now)
+	}
+	w.count++
+	return 0
+}
+
+// refresh reloads the throttled set. On error the previous set is kept:
+// briefly enforcing a stale throttle beats dropping every throttle on a
+// database blip. Callers hold t.mu.
+func (t *Throttle) refresh(ctx context.Context, now time.Time) {
+	t.loadedAt = now
+
+	limits, err := t.source.ListTenantSendLimits(ctx)
+	if err != nil {
+		t.logger.Warn("failed to refresh send limits, keeping previous", zap.Error(err))
+		return
+	}
+
+	throttled := make(map[limitKey]bool, len(limits))
+	for _, l := range limits {
+		if l.State == db.SendLimitThrottled {
+			throttled[limitKey{tenantID: l.TenantID, channel: l.Channel}] = true
+		}
+	}
+	t.throttled = throttled
+
+	for key := range t.windows {
+		if !throttled[key] {
+			delete(t.windows, key)
+		}
+	}
+}
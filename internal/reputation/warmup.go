@@ -0,0 +1,96 @@
+package reputation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// WarmupStore counts sends against the warm-up schedule.
+type WarmupStore interface {
+	ReserveWarmupSend(ctx context.Context, tenantID uuid.UUID, schedule []int) (allowed, graduated bool, err error)
+}
+
+// Warmup caps a new tenant's daily email volume, ramping it up day by day
+// the way a new sending IP or domain is warmed up, so a tenant's first
+// blast can't burn the platform's reputation with mailbox providers.
+//
+// The count lives in Postgres, so the cap holds across worker replicas. A
+// send is counted when it is dispatched, so one that then fails still uses
+// up its slot for the day.
+type Warmup struct {
+	store    WarmupStore
+	schedule []int
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	graduated map[uuid.UUID]bool
+}
+
+// NewWarmup creates a warm-up limiter. schedule[d] is the email cap on a
+// tenant's day d of sending; past the end of it tenants are uncapped.
+func NewWarmup(store WarmupStore, schedule []int, logger *zap.Logger) *Warmup {
+	return &Warmup{
+		store:     store,
+		schedule:  schedule,
+		logger:    logger,
+		graduated: make(map[uuid.UUID]bool),
+	}
+}
+
+// Delay reports how long notif must wait before it may be sent: until the
+// next UTC day when the tenant has used today's cap, otherwise zero. It
+// fits worker.Config.Throttle.
+func (w *Warmup) Delay(ctx context.Context, notif *db.Notification) time.Duration {
+	if notif.Channel != db.ChannelEmail {
+		return 0
+	}
+
+	w.mu.Lock()
+	done := w.graduated[notif.TenantID]
+	w.mu.Unlock()
+	if done {
+		return 0
+	}
+
+	allowed, graduated, err := w.store.ReserveWarmupSend(ctx, notif.TenantID, w.schedule)
+	if err != nil {
+		// Fail open: a database blip shouldn't hold up every tenant's email.
+		w.logger.Warn("failed to check email warm-up, sending anyway",
+			zap.Error(err),
+			zap.String("tenant_id", notif.TenantID.String()),
+		)
+		return 0
+	}
+	if graduated {
+		w.mu.Lock()
+		w.graduated[notif.TenantID] = true
+		w.mu.Unlock()
+	}
+	if allowed {
+		return 0
+	}
+
+	now := time.Now().UTC()
+	return now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
+
+// Combine runs each delay func in turn and returns the first positive
+// delay, so several limits can share worker.Config.Throttle. Later funcs
+// aren't asked once one defers, so put limits that count sends, like
+// Warmup, last.
+func Combine(delays ...func(context.Context, *db.Notification) time.Duration) func(context.Context, *db.Notification) time.Duration {
+	return func(ctx context.Context, notif *db.Notification) time.Duration {
+		for _, delay := range delays {
+			if d := delay(ctx, notif); d > 0 {
+				return d
+			}
+		}
+		return 0
+	}
+}
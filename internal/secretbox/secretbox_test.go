@@ -0,0 +1,55 @@
+package secretbox
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 ASCII bytes
+
+func TestSealOpen(t *testing.T) {
+	box, err := New(testKey)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	sealed, err := box.Seal("Bearer s3cret")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "s3cret") {
+		t.Errorf("expected an opaque sealed value, got %q", sealed)
+	}
+	if again, _ := box.Seal("Bearer s3cret"); again == sealed {
+		t.Error("expected a fresh nonce per seal")
+	}
+
+	opened, err := box.Open(sealed)
+	if err != nil || opened != "Bearer s3cret" {
+		t.Errorf("expected the plaintext back, got %q, %v", opened, err)
+	}
+}
+
+func TestOpen_Rejects(t *testing.T) {
+	box, _ := New(testKey)
+	other, _ := New("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+	sealed, _ := other.Seal("x")
+
+	for name, v := range map[string]string{
+		"plaintext":     "Bearer s3cret",
+		"truncated":     "sealed:v1:AAAA",
+		"different key": sealed,
+	} {
+		if _, err := box.Open(v); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNew_BadKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", "c2hvcnQ="} {
+		if _, err := New(key); err == nil {
+			t.Errorf("expected an error for key %q", key)
+		}
+	}
+}
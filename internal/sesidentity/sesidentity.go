@@ -0,0 +1,135 @@
+// Package sesidentity checks, when a tenant saves its email settings, that
+// SES can send with them: the sending identity is verified and signs with
+// DKIM, the custom MAIL FROM domain is set up on it, and the configuration
+// set exists. Checking up front turns a misconfigured domain into a 400 at
+// setup rather than every email failing later.
+package sesidentity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// ErrNotReady wraps every reason SES can't send with a tenant's settings.
+// Any other error from Check means SES couldn't be asked.
+var ErrNotReady = errors.New("ses identity not ready")
+
+// API is the part of the SES client the checker uses.
+type API interface {
+	GetIdentityVerificationAttributes(ctx context.Context, in *ses.GetIdentityVerificationAttributesInput, optFns ...func(*ses.Options)) (*ses.GetIdentityVerificationAttributesOutput, error)
+	GetIdentityDkimAttributes(ctx context.Context, in *ses.GetIdentityDkimAttributesInput, optFns ...func(*ses.Options)) (*ses.GetIdentityDkimAttributesOutput, error)
+	GetIdentityMailFromDomainAttributes(ctx context.Context, in *ses.GetIdentityMailFromDomainAttributesInput, optFns ...func(*ses.Options)) (*ses.GetIdentityMailFromDomainAttributesOutput, error)
+	DescribeConfigurationSet(ctx context.Context, in *ses.DescribeConfigurationSetInput, optFns ...func(*ses.Options)) (*ses.DescribeConfigurationSetOutput, error)
+}
+
+// Checker checks email settings against one SES region.
+type Checker struct {
+	client API
+}
+
+// New creates a checker for the SES account and region the worker sends
+// through.
+func New(ctx context.Context, region string) (*Checker, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default AWS config: %w", err)
+	}
+	return NewWithClient(ses.NewFromConfig(awsCfg)), nil
+}
+
+// NewWithClient creates a checker using client.
+func NewWithClient(client API) *Checker {
+	return &Checker{client: client}
+}
+
+// Check reports whether SES can send with s. The identity is the
+// from_address's domain if that is verified, so DKIM and MAIL FROM are
+// checked where SES applies them; otherwise the address itself.
+func (c *Checker) Check(ctx context.Context, s *db.EmailSettings) error {
+	if s.FromAddress != "" {
+		identity, err := c.verifiedIdentity(ctx, s.FromAddress)
+		if err != nil {
+			return err
+		}
+		if err := c.checkDKIM(ctx, identity); err != nil {
+			return err
+		}
+		if s.MailFromDomain != "" {
+			if err := c.checkMailFrom(ctx, identity, s.MailFromDomain); err != nil {
+				return err
+			}
+		}
+	}
+	if s.ConfigurationSet != "" {
+		_, err := c.client.DescribeConfigurationSet(ctx, &ses.DescribeConfigurationSetInput{
+			ConfigurationSetName: aws.String(s.ConfigurationSet),
+		})
+		var missing *types.ConfigurationSetDoesNotExistException
+		if errors.As(err, &missing) {
+			return fmt.Errorf("%w: configuration set %q does not exist", ErrNotReady, s.ConfigurationSet)
+		}
+		if err != nil {
+			return fmt.Errorf("describe configuration set: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Checker) verifiedIdentity(ctx context.Context, address string) (string, error) {
+	_, domain, _ := strings.Cut(address, "@")
+	out, err := c.client.GetIdentityVerificationAttributes(ctx, &ses.GetIdentityVerificationAttributesInput{
+		Identities: []string{domain, address},
+	})
+	if err != nil {
+		return "", fmt.Errorf("get identity verification: %w", err)
+	}
+	for _, identity := range []string{domain, address} {
+		if attrs, ok := out.VerificationAttributes[identity]; ok && attrs.VerificationStatus == types.VerificationStatusSuccess {
+			return identity, nil
+		}
+	}
+	return "", fmt.Errorf("%w: neither %s nor %s is a verified SES identity", ErrNotReady, domain, address)
+}
+
+func (c *Checker) checkDKIM(ctx context.Context, identity string) error {
+	out, err := c.client.GetIdentityDkimAttributes(ctx, &ses.GetIdentityDkimAttributesInput{
+		Identities: []string{identity},
+	})
+	if err != nil {
+		return fmt.Errorf("get identity dkim: %w", err)
+	}
+	attrs, ok := out.DkimAttributes[identity]
+	if !ok || !attrs.DkimEnabled {
+		return fmt.Errorf("%w: DKIM signing is not enabled for %s", ErrNotReady, identity)
+	}
+	if attrs.DkimVerificationStatus != types.VerificationStatusSuccess {
+		return fmt.Errorf("%w: DKIM for %s is %s, not verified; publish its CNAME records", ErrNotReady, identity, attrs.DkimVerificationStatus)
+	}
+	return nil
+}
+
+func (c *Checker) checkMailFrom(ctx context.Context, identity, mailFrom string) error {
+	out, err := c.client.GetIdentityMailFromDomainAttributes(ctx, &ses.GetIdentityMailFromDomainAttributesInput{
+		Identities: []string{identity},
+	})
+	if err != nil {
+		return fmt.Errorf("get identity mail from domain: %w", err)
+	}
+	attrs, ok := out.MailFromDomainAttributes[identity]
+	if !ok || !strings.EqualFold(aws.ToString(attrs.MailFromDomain), mailFrom) {
+		return fmt.Errorf("%w: %s is not the MAIL FROM domain of %s in SES", ErrNotReady, mailFrom, identity)
+	}
+	if attrs.MailFromDomainStatus != types.CustomMailFromStatusSuccess {
+		return fmt.Errorf("%w: MAIL FROM domain %s is %s, not verified; publish its MX and SPF records", ErrNotReady, mailFrom, attrs.MailFromDomainStatus)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package sesidentity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type fakeSES struct {
+	verified   map[string]types.VerificationStatus
+	dkim       map[string]types.IdentityDkimAttributes
+	mailFrom   map[string]types.IdentityMailFromDomainAttributes
+	configSets map[string]bool
+	err        error
+}
+
+func (f *fakeSES) GetIdentityVerificationAttributes(ctx context.Context, in *ses.GetIdentityVerificationAttributesInput, optFns ...func(*ses.Options)) (*ses.GetIdentityVerificationAttributesOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := &ses.GetIdentityVerificationAttributesOutput{VerificationAttributes: map[string]types.IdentityVerificationAttributes{}}
+	for _, id := range in.Identities {
+		if status, ok := f.verified[id]; ok {
+			out.VerificationAttributes[id] = types.IdentityVerificationAttributes{VerificationStatus: status}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSES) GetIdentityDkimAttributes(ctx context.Context, in *ses.GetIdentityDkimAttributesInput, optFns ...func(*ses.Options)) (*ses.GetIdentityDkimAttributesOutput, error) {
+	return &ses.GetIdentityDkimAttributesOutput{DkimAttributes: f.dkim}, nil
+}
+
+func (f *fakeSES) GetIdentityMailFromDomainAttributes(ctx context.Context, in *ses.GetIdentityMailFromDomainAttributesInput, optFns ...func(*ses.Options)) (*ses.GetIdentityMailFromDomainAttributesOutput, error) {
+	return &ses.GetIdentityMailFromDomainAttributesOutput{MailFromDomainAttributes: f.mailFrom}, nil
+}
+
+func (f *fakeSES) DescribeConfigurationSet(ctx context.Context, in *ses.DescribeConfigurationSetInput, optFns ...func(*ses.Options)) (*ses.DescribeConfigurationSetOutput, error) {
+	if !f.configSets[aws.ToString(in.ConfigurationSetName)] {
+		return nil, &types.ConfigurationSetDoesNotExistException{}
+	}
+	return &ses.DescribeConfigurationSetOutput{}, nil
+}
+
+func readyDomain() *fakeSES {
+	return &fakeSES{
+		verified: map[string]types.VerificationStatus{"acme.com": types.VerificationStatusSuccess},
+		dkim: map[string]types.IdentityDkimAttributes{
+			"acme.com": {DkimEnabled: true, DkimVerificationStatus: types.VerificationStatusSuccess},
+		},
+		mailFrom: map[string]types.IdentityMailFromDomainAttributes{
+			"acme.com": {MailFromDomain: aws.String("bounce.acme.com"), MailFromDomainStatus: types.CustomMailFromStatusSuccess},
+		},
+		configSets: map[string]bool{"acme-prod": true},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	settings := db.EmailSettings{
+		FromAddress:      "notify@acme.com",
+		MailFromDomain:   "bounce.acme.com",
+		ConfigurationSet: "acme-prod",
+	}
+
+	tests := []struct {
+		name     string
+		setup    func(f *fakeSES)
+		settings func(s *db.EmailSettings)
+		notReady bool
+	}{
+		{name: "ready"},
+		{name: "unverified domain", setup: func(f *fakeSES) {
+			f.verified["acme.com"] = types.VerificationStatusPending
+		}, notReady: true},
+		{name: "verified address only", setup: func(f *fakeSES) {
+			f.verified = map[string]types.VerificationStatus{"notify@acme.com": types.VerificationStatusSuccess}
+		}, notReady: true},
+		{name: "dkim disabled", setup: func(f *fakeSES) {
+			f.dkim["acme.com"] = types.IdentityDkimAttributes{DkimVerificationStatus: types.VerificationStatusSuccess}
+		}, notReady: true},
+		{name: "dkim pending", setup: func(f *fakeSES) {
+			f.dkim["acme.com"] = types.IdentityDkimAttributes{DkimEnabled: true, DkimVerificationStatus: types.VerificationStatusPending}
+		}, notReady: true},
+		{name: "other mail from domain", setup: func(f *fakeSES) {
+			f.mailFrom["acme.com"] = types.IdentityMailFromDomainAttributes{MailFromDomain: aws.String("mail.acme.com"), MailFromDomainStatus: types.CustomMailFromStatusSuccess}
+		}, notReady: true},
+		{name: "mail from pending", setup: func(f *fakeSES) {
+			f.mailFrom["acme.com"] = types.IdentityMailFromDomainAttributes{MailFromDomain: aws.String("bounce.acme.com"), MailFromDomainStatus: types.CustomMailFromStatusPending}
+		}, notReady: true},
+		{name: "missing configuration set", setup: func(f *fakeSES) {
+			f.configSets = nil
+		}, notReady: true},
+		{name: "configuration set only", setup: func(f *fakeSES) {
+			f.verified = nil
+		}, settings: func(s *db.EmailSettings) {
+			*s = db.EmailSettings{ConfigurationSet: "acme-prod"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := readyDomain()
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			s := settings
+			if tt.settings != nil {
+				tt.settings(&s)
+			}
+
+			err := NewWithClient(f).Check(context.Background(), &s)
+			if tt.notReady {
+				if !errors.Is(err, ErrNotReady) {
+					t.Fatalf("expected ErrNotReady, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected settings to be ready, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheck_SESError(t *testing.T) {
+	f := readyDomain()
+	f.err = errors.New("throttled")
+
+	err := NewWithClient(f).Check(context.Background(), &db.EmailSettings{FromAddress: "notify@acme.com"})
+	if err == nil || errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected an SES error, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Package shortlink replaces long URLs in outgoing messages with short
+// /r/{code} links served by the gateway, which records a click and
+// redirects to the original URL.
+package shortlink
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+const (
+	// CodeLength gives 62^8 ≈ 2e14 codes; collisions are vanishingly rare
+	// and fail the insert rather than hijack another link.
+	CodeLength = 8
+	codeChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	// PathPrefix is where the gateway serves redirects.
+	PathPrefix = "/r/"
+)
+
+// urlPattern matches http(s) URLs up to the next whitespace. Trailing
+// punctuation is trimmed separately since it usually ends the sentence.
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+const trailingPunctuation = ".,;:!?)]}'"
+
+// Store persists short links.
+type Store interface {
+	CreateShortLink(ctx context.Context, link *db.ShortLink) error
+}
+
+// Config holds the shortener configuration.
+type Config struct {
+	BaseURL       string            // Default link host, e.g. https://nim.bz
+	TenantDomains map[string]string // tenant_id → custom domain, e.g. links.acme.com
+}
+
+// Shortener rewrites URLs in message text into short links.
+type Shortener struct {
+	store         Store
+	baseURL       string
+	tenantDomains map[string]string
+	logger        *zap.Logger
+}
+
+// New creates a shortener.
+func New(store Store, cfg Config, logger *zap.Logger) *Shortener {
+	return &Shortener{
+		store:         store,
+		baseURL:       strings.TrimRight(cfg.BaseURL, "/"),
+		tenantDomains: cfg.TenantDomains,
+		logger:        logger,
+	}
+}
+
+// BaseURLFor returns the link host for a tenant: its custom domain if it has
+// one, otherwise the default base URL.
+func (s *Shortener) BaseURLFor(tenantID uuid.UUID) string {
+	if domain, ok := s.tenantDomains[tenantID.String()]; ok && domain != "" {
+		return "https://" + domain
+	}
+	return s.baseURL
+}
+
+// ShortenText replaces every URL in text that is longer than its short form.
+// URLs that wouldn't get shorter are left alone, so short messages pass
+// through without touching the database.
+func (s *Shortener) ShortenText(ctx context.Context, notif *db.Notification, text string) (string, error) {
+	base := s.BaseURLFor(notif.TenantID)
+	shortLen := len(base) + len(PathPrefix) + CodeLength
+
+	var firstErr error
+	shortened := 0
+	out := urlPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+
+		target := strings.TrimRight(match, trailingPunctuation)
+		suffix := match[len(target):]
+		if len(target) <= shortLen {
+			return match
+		}
+
+		code, err := newCode()
+		if err != nil {
+			firstErr = err
+			return match
+		}
+
+		link := &db.ShortLink{
+			Code:           code,
+			TenantID:       notif.TenantID,
+			NotificationID: notif.ID,
+			URL:            target,
+		}
+		if err := s.store.CreateShortLink(ctx, link); err != nil {
+			firstErr = err
+			return match
+		}
+
+		shortened++
+		return base + PathPrefix + link.Code + suffix
+	})
+	if firstErr != nil {
+		return "", fmt.Errorf("shorten links: %w", firstErr)
+	}
+
+	if shortened > 0 {
+		s.logger.Debug("shortened links",
+			zap.String("notification_id", notif.ID.String()),
+			zap.Int("count", shortened),
+			zap.Int("saved_chars", len(text)-len(out)),
+		)
+	}
+
+	return out, nil
+}
+
+// ValidCode reports whether code could have been produced by newCode, so
+// the redirect handler can reject junk without a database lookup.
+func ValidCode(code string) bool {
+	if len(code) != CodeLength {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if !strings.ContainsRune(codeChars, rune(code[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func newCode() (string, error) {
+	b := make([]byte, CodeLength)
+	max := big.NewInt(int64(len(codeChars)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("generate code: %w", err)
+		}
+		b[i] = codeChars[n.Int64()]
+	}
+	return string(b), nil
+}
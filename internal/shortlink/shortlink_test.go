@@ -0,0 +1,125 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockStore struct {
+	links      []*db.ShortLink
+	shouldFail bool
+}
+
+func (m *mockStore) CreateShortLink(ctx context.Context, link *db.ShortLink) error {
+	if m.shouldFail {
+		return errors.New("database error")
+	}
+	m.links = append(m.links, link)
+	return nil
+}
+
+func TestShortenText(t *testing.T) {
+	longURL := "https://shop.example.com/orders/12345/tracking?utm_source=sms&utm_campaign=shipping"
+
+	tests := []struct {
+		name      string
+		text      string
+		wantLinks int
+	}{
+		{"no urls", "Your code is 123456", 0},
+		{"short url left alone", "See https://ex.co/a", 0},
+		{"long url", "Track it: " + longURL, 1},
+		{"trailing punctuation kept", "Track it (" + longURL + ").", 1},
+		{"two urls", longURL + " and " + longURL + "&x=1", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{}
+			s := New(store, Config{BaseURL: "https://nim.bz/"}, zap.NewNop())
+			notif := &db.Notification{ID: uuid.New(), TenantID: uuid.New()}
+
+			out, err := s.ShortenText(context.Background(), notif, tt.text)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(store.links) != tt.wantLinks {
+				t.Fatalf("expected %d links, got %d", tt.wantLinks, len(store.links))
+			}
+			if tt.wantLinks == 0 && out != tt.text {
+				t.Errorf("expected text unchanged, got %q", out)
+			}
+			for _, link := range store.links {
+				if !ValidCode(link.Code) {
+					t.Errorf("invalid code %q", link.Code)
+				}
+				if link.NotificationID != notif.ID || link.TenantID != notif.TenantID {
+					t.Error("link does not reference the notification")
+				}
+				if !strings.Contains(out, "https://nim.bz/r/"+link.Code) {
+					t.Errorf("expected %q to contain short link for %s", out, link.Code)
+				}
+				if strings.HasSuffix(link.URL, ")") || strings.HasSuffix(link.URL, ".") {
+					t.Errorf("trailing punctuation leaked into url %q", link.URL)
+				}
+			}
+			if tt.name == "trailing punctuation kept" && !strings.HasSuffix(out, ").") {
+				t.Errorf("expected trailing punctuation to survive, got %q", out)
+			}
+		})
+	}
+}
+
+func TestShortenText_CustomDomain(t *testing.T) {
+	tenantID := uuid.New()
+	store := &mockStore{}
+	s := New(store, Config{
+		BaseURL:       "https://nim.bz",
+		TenantDomains: map[string]string{tenantID.String(): "go.acme.com"},
+	}, zap.NewNop())
+
+	out, err := s.ShortenText(context.Background(), &db.Notification{ID: uuid.New(), TenantID: tenantID},
+		"https://acme.com/a/very/long/path/that/should/definitely/be/shortened")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(out, "https://go.acme.com/r/") {
+		t.Errorf("expected tenant domain, got %q", out)
+	}
+}
+
+func TestShortenText_StoreError(t *testing.T) {
+	s := New(&mockStore{shouldFail: true}, Config{BaseURL: "https://nim.bz"}, zap.NewNop())
+	_, err := s.ShortenText(context.Background(), &db.Notification{ID: uuid.New()},
+		"https://example.com/a/very/long/path/that/should/be/shortened")
+	if err == nil {
+		t.Fatal("expected an error when the link can't be stored")
+	}
+}
+
+func TestValidCode(t *testing.T) {
+	code, err := newCode()
+	if err != nil {
+		t.Fatalf("newCode: %v", err)
+	}
+	for _, tt := range []struct {
+		code string
+		want bool
+	}{
+		{code, true},
+		{"abc", false},
+		{"abcd-fgh", false},
+		{"", false},
+	} {
+		if got := ValidCode(tt.code); got != tt.want {
+			t.Errorf("ValidCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package sms counts how many carrier segments an SMS message will be split
+// into, which is what providers bill for.
+package sms
+
+import "unicode/utf16"
+
+// Encoding is the character set a message is sent in.
+type Encoding string
+
+const (
+	// EncodingGSM7 is the GSM 03.38 default alphabet: 7 bits per character.
+	EncodingGSM7 Encoding = "GSM-7"
+	// EncodingUCS2 is used as soon as any character falls outside GSM-7.
+	EncodingUCS2 Encoding = "UCS-2"
+)
+
+// Per-segment capacities. A message that doesn't fit in one segment is sent
+// as a concatenated SMS, and each part loses room to the UDH header.
+const (
+	gsm7SingleSegment = 160
+	gsm7MultiSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2MultiSegment  = 67
+)
+
+// gsm7Basic is the GSM 03.38 basic character set; each costs one septet.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension characters are sent as an escape plus a septet, so each
+// costs two.
+const gsm7Extension = "^{}\\[~]|€\f"
+
+var (
+	basicSet     = runeSet(gsm7Basic)
+	extensionSet = runeSet(gsm7Extension)
+)
+
+func runeSet(s string) map[rune]bool {
+	set := make(map[rune]bool)
+	for _, r := range s {
+		set[r] = true
+	}
+	return set
+}
+
+// Estimate describes how a message will be encoded and billed.
+type Estimate struct {
+	Encoding Encoding `json:"encoding"`
+	// Units is the encoded length: septets for GSM-7, UTF-16 code units for
+	// UCS-2. It is what segment limits are measured in.
+	Units    int `json:"units"`
+	Segments int `json:"segments"`
+}
+
+// Count works out the encoding and segment count for message. An empty
+// message is one (empty) segment.
+func Count(message string) Estimate {
+	septets, ok := gsm7Length(message)
+	if ok {
+		return Estimate{
+			Encoding: EncodingGSM7,
+			Units:    septets,
+			Segments: segments(septets, gsm7SingleSegment, gsm7MultiSegment),
+		}
+	}
+
+	units := len(utf16.Encode([]rune(message)))
+	return Estimate{
+		Encoding: EncodingUCS2,
+		Units:    units,
+		Segments: segments(units, ucs2SingleSegment, ucs2MultiSegment),
+	}
+}
+
+// gsm7Length returns the septet count of message, or ok=false if any
+// character can't be represented in GSM-7.
+func gsm7Length(message string) (septets int, ok bool) {
+	for _, r := range message {
+		switch {
+		case basicSet[r]:
+			septets++
+		case extensionSet[r]:
+			septets += 2
+		default:
+			return 0, false
+		}
+	}
+	return septets, true
+}
+
+func segments(units, single, multi int) int {
+	if units <= single {
+		return 1
+	}
+	return (units + multi - 1) / multi
+}
@@ -0,0 +1,37 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		encoding Encoding
+		units    int
+		segments int
+	}{
+		{"empty", "", EncodingGSM7, 0, 1},
+		{"plain ascii", "Your code is 123456", EncodingGSM7, 19, 1},
+		{"gsm7 single segment limit", strings.Repeat("a", 160), EncodingGSM7, 160, 1},
+		{"gsm7 spills into two", strings.Repeat("a", 161), EncodingGSM7, 161, 2},
+		{"gsm7 three segments", strings.Repeat("a", 307), EncodingGSM7, 307, 3},
+		{"extension chars count twice", "Price: 5€ [promo]", EncodingGSM7, 20, 1},
+		{"accented gsm7 chars", "Café à Zürich", EncodingGSM7, 13, 1},
+		{"emoji forces ucs2", "Hi 👋", EncodingUCS2, 5, 1},
+		{"non-gsm accent forces ucs2", "Crème brûlée", EncodingUCS2, 12, 1},
+		{"ucs2 single segment limit", strings.Repeat("ж", 70), EncodingUCS2, 70, 1},
+		{"ucs2 spills into two", strings.Repeat("ж", 71), EncodingUCS2, 71, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Count(tt.message)
+			if got.Encoding != tt.encoding || got.Units != tt.units || got.Segments != tt.segments {
+				t.Errorf("Count(%q) = %+v, want {%s %d %d}", tt.message, got, tt.encoding, tt.units, tt.segments)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/sqs"
+)
+
+// Enqueue publishes notif to the topic for fan-out to per-channel SQS
+// queues. The body is the same sqs.Message a direct enqueue sends and the
+// attributes are sqs.Attributes, so queues subscribed with raw message
+// delivery and a filter policy on "channel" are read by the same consumer.
+func (p *Publisher) Enqueue(ctx context.Context, notif *db.Notification) (string, error) {
+	body, err := json.Marshal(sqs.NewMessage(notif))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	attrs := make(map[string]types.MessageAttributeValue)
+	for name, v := range sqs.Attributes(notif) {
+		attrs[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(v),
+		}
+	}
+
+	result, err := p.client.Publish(ctx, &sns.PublishInput{
+		TopicArn:          aws.String(p.topicARN),
+		Message:           aws.String(string(body)),
+		MessageAttributes: attrs,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to publish to SNS: %w", err)
+	}
+
+	return *result.MessageId, nil
+}
@@ -0,0 +1,254 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// maxBatchSize is the most messages SQS returns, deletes or re-hides in
+// one call.
+const maxBatchSize = 10
+
+// Nack backoff bounds, in seconds. A failed message is hidden for
+// nackBaseDelay doubled per earlier receive, up to nackMaxDelay.
+const (
+	nackBaseDelay = 30
+	nackMaxDelay  = 15 * 60
+)
+
+// Received is a message taken from the queue along with what is needed to
+// acknowledge it. Message is nil when the body could not be decoded.
+type Received struct {
+	Message       *Message
+	ReceiptHandle string
+	// TraceParent is the producer's W3C traceparent, if it sent one.
+	TraceParent  string
+	ReceiveCount int
+}
+
+// BatchResult reports how a ProcessBatch call went, per message.
+type BatchResult struct {
+	Received  int
+	Succeeded int
+	Failed    int
+}
+
+// ReceiveBatch retrieves up to max messages from SQS with long polling.
+// Bodies that don't decode are still returned, with a nil Message, so the
+// caller can nack them and let the redrive policy move them to the DLQ.
+func (c *Consumer) ReceiveBatch(ctx context.Context, max int32) ([]*Received, error) {
+	if max <= 0 || max > maxBatchSize {
+		max = maxBatchSize
+	}
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.queueURL),
+		MaxNumberOfMessages: max,
+		WaitTimeSeconds:     20,
+		VisibilityTimeout:   visibilityTimeout,
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameApproximateReceiveCount,
+		},
+		MessageAttributeNames: []string{"All"},
+	}
+
+	result, err := c.client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("sqs receive failed: %w", err)
+	}
+
+	received := make([]*Received, 0, len(result.Messages))
+	for _, m := range result.Messages {
+		r := &Received{
+			ReceiptHandle: aws.ToString(m.ReceiptHandle),
+			TraceParent:   aws.ToString(m.MessageAttributes[traceParentAttribute].StringValue),
+			ReceiveCount:  1,
+		}
+		if n, err := strconv.Atoi(m.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]); err == nil && n > 0 {
+			r.ReceiveCount = n
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(aws.ToString(m.Body)), &msg); err != nil {
+			c.logger.Error("failed to unmarshal message",
+				zap.Error(err),
+				zap.String("message_id", aws.ToString(m.MessageId)),
+			)
+		} else {
+			applyAttributes(&msg, m.MessageAttributes)
+			r.Message = &msg
+		}
+		received = append(received, r)
+	}
+
+	return received, nil
+}
+
+// ProcessBatch receives up to a full batch and runs handle on each
+// message in turn, keeping every unfinished message hidden while it waits
+// its turn. Only the messages handle succeeded on are deleted; failures
+// are nacked by hiding them for a backoff that grows with the receive
+// count, so one bad message neither holds back nor resends the rest.
+func (c *Consumer) ProcessBatch(ctx context.Context, handle func(context.Context, *Message) error) (BatchResult, error) {
+	received, err := c.ReceiveBatch(ctx, maxBatchSize)
+	if err != nil || len(received) == 0 {
+		return BatchResult{}, err
+	}
+
+	pending := newPendingSet(received)
+	stop := keepHidden(ctx, func(ctx context.Context) error {
+		entries := visibilityEntries(pending.list(), func(*Received) int32 { return visibilityTimeout })
+		return c.changeVisibilityBatch(ctx, entries)
+	}, visibilityTimeout*time.Second/2, c.logger)
+
+	succeeded, failed := runBatch(ctx, received, handle, pending.done, c.logger)
+	stop()
+
+	// The handlers' work is done; don't let a cancelled ctx leave sent
+	// messages behind to be sent again.
+	ackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	result := BatchResult{Received: len(received), Succeeded: len(succeeded), Failed: len(failed)}
+	if err := c.deleteBatch(ackCtx, succeeded); err != nil {
+		return result, err
+	}
+	if err := c.changeVisibilityBatch(ackCtx, visibilityEntries(failed, nackVisibility)); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// runBatch calls handle on each decoded message and splits the batch by
+// outcome. done is called as each message finishes so it stops being
+// renewed.
+func runBatch(ctx context.Context, received []*Received, handle func(context.Context, *Message) error, done func(*Received), logger *zap.Logger) (succeeded, failed []*Received) {
+	for _, r := range received {
+		if r.Message == nil {
+			failed = append(failed, r)
+			done(r)
+			continue
+		}
+		if err := handle(ctx, r.Message); err != nil {
+			logger.Warn("failed to handle sqs message",
+				zap.Error(err),
+				zap.String("notification_id", r.Message.NotificationID),
+				zap.String("trace_id", observ.TraceIDFromParent(r.TraceParent)),
+				zap.Int("receive_count", r.ReceiveCount),
+			)
+			failed = append(failed, r)
+		} else {
+			succeeded = append(succeeded, r)
+		}
+		done(r)
+	}
+	return succeeded, failed
+}
+
+// nackVisibility is how long, in seconds, a failed message stays hidden
+// before its next attempt.
+func nackVisibility(r *Received) int32 {
+	delay := int32(nackBaseDelay)
+	for i := 1; i < r.ReceiveCount && delay < nackMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, nackMaxDelay)
+}
+
+// pendingSet tracks the messages of a batch still being handled.
+type pendingSet struct {
+	mu sync.Mutex
+	m  map[*Received]struct{}
+}
+
+func newPendingSet(received []*Received) *pendingSet {
+	p := &pendingSet{m: make(map[*Received]struct{}, len(received))}
+	for _, r := range received {
+		p.m[r] = struct{}{}
+	}
+	return p
+}
+
+func (p *pendingSet) done(r *Received) {
+	p.mu.Lock()
+	delete(p.m, r)
+	p.mu.Unlock()
+}
+
+func (p *pendingSet) list() []*Received {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]*Received, 0, len(p.m))
+	for r := range p.m {
+		out = append(out, r)
+	}
+	return out
+}
+
+func visibilityEntries(received []*Received, seconds func(*Received) int32) []types.ChangeMessageVisibilityBatchRequestEntry {
+	entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, 0, len(received))
+	for i, r := range received {
+		entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(i)),
+			ReceiptHandle:     aws.String(r.ReceiptHandle),
+			VisibilityTimeout: seconds(r),
+		})
+	}
+	return entries
+}
+
+// deleteBatch deletes the given messages, reporting any SQS rejected.
+func (c *Consumer) deleteBatch(ctx context.Context, received []*Received) error {
+	if len(received) == 0 {
+		return nil
+	}
+	entries := make([]types.DeleteMessageBatchRequestEntry, 0, len(received))
+	for i, r := range received {
+		entries = append(entries, types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: aws.String(r.ReceiptHandle),
+		})
+	}
+
+	out, err := c.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(c.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("sqs delete batch failed: %w", err)
+	}
+	if len(out.Failed) > 0 {
+		return fmt.Errorf("sqs delete batch: %d of %d entries failed: %s",
+			len(out.Failed), len(entries), aws.ToString(out.Failed[0].Message))
+	}
+	return nil
+}
+
+// changeVisibilityBatch applies the given visibility changes, reporting
+// any SQS rejected.
+func (c *Consumer) changeVisibilityBatch(ctx context.Context, entries []types.ChangeMessageVisibilityBatchRequestEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	out, err := c.client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+		QueueUrl: aws.String(c.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("sqs change visibility batch failed: %w", err)
+	}
+	if len(out.Failed) > 0 {
+		return fmt.Errorf("sqs change visibility batch: %d of %d entries failed: %s",
+			len(out.Failed), len(entries), aws.ToString(out.Failed[0].Message))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNackVisibility_BacksOffPerReceive(t *testing.T) {
+	tests := []struct {
+		receives int
+		want     int32
+	}{
+		{receives: 0, want: 30},
+		{receives: 1, want: 30},
+		{receives: 2, want: 60},
+		{receives: 3, want: 120},
+		{receives: 5, want: 480},
+		{receives: 6, want: nackMaxDelay},
+		{receives: 100, want: nackMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := nackVisibility(&Received{ReceiveCount: tt.receives}); got != tt.want {
+			t.Errorf("receives=%d: got %d, want %d", tt.receives, got, tt.want)
+		}
+	}
+}
+
+func TestRunBatch_SplitsByOutcome(t *testing.T) {
+	ok := &Received{Message: &Message{NotificationID: "a"}, ReceiptHandle: "h-a"}
+	bad := &Received{Message: &Message{NotificationID: "b"}, ReceiptHandle: "h-b"}
+	undecodable := &Received{ReceiptHandle: "h-c"}
+	ok2 := &Received{Message: &Message{NotificationID: "d"}, ReceiptHandle: "h-d"}
+	batch := []*Received{ok, bad, undecodable, ok2}
+
+	pending := newPendingSet(batch)
+	var handled []string
+	succeeded, failed := runBatch(context.Background(), batch, func(_ context.Context, m *Message) error {
+		handled = append(handled, m.NotificationID)
+		if m.NotificationID == "b" {
+			return errors.New("provider down")
+		}
+		return nil
+	}, pending.done, zap.NewNop())
+
+	if len(handled) != 3 {
+		t.Errorf("expected every decoded message handled despite the failure, got %v", handled)
+	}
+	if len(succeeded) != 2 || succeeded[0] != ok || succeeded[1] != ok2 {
+		t.Errorf("unexpected succeeded: %v", succeeded)
+	}
+	if len(failed) != 2 || failed[0] != bad || failed[1] != undecodable {
+		t.Errorf("unexpected failed: %v", failed)
+	}
+	if left := pending.list(); len(left) != 0 {
+		t.Errorf("expected no messages left to renew, got %d", len(left))
+	}
+}
+
+func TestVisibilityEntries(t *testing.T) {
+	batch := []*Received{
+		{ReceiptHandle: "h-1", ReceiveCount: 1},
+		{ReceiptHandle: "h-2", ReceiveCount: 3},
+	}
+	entries := visibilityEntries(batch, nackVisibility)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if *entries[0].Id == *entries[1].Id {
+		t.Error("expected unique entry ids within a batch")
+	}
+	if *entries[1].ReceiptHandle != "h-2" || entries[1].VisibilityTimeout != 120 {
+		t.Errorf("unexpected entry: %s %d", *entries[1].ReceiptHandle, entries[1].VisibilityTimeout)
+	}
+}
@@ -0,0 +1,111 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// ErrQueueFull is returned by MemoryQueue.Enqueue when the channel's queue
+// has no room left.
+var ErrQueueFull = errors.New("memory queue full")
+
+// memoryWait is how long an empty MemoryChannel waits for a message before
+// returning an empty batch, standing in for SQS long polling.
+const memoryWait = time.Second
+
+// MemoryQueue is an in-process stand-in for the SNS fan-out topic and its
+// per-channel queues, used by DEMO_MODE. Enqueue routes a notification to
+// its channel's queue; Channel returns that queue for a worker to consume.
+// Nothing survives a restart, which is fine: as with SQS, the database row
+// is the source of truth and the poll loop covers anything lost.
+type MemoryQueue struct {
+	queues map[string]chan *Message
+}
+
+// NewMemoryQueue creates a queue per channel, each holding up to size
+// messages.
+func NewMemoryQueue(size int, channels ...string) *MemoryQueue {
+	queues := make(map[string]chan *Message, len(channels))
+	for _, channel := range channels {
+		queues[channel] = make(chan *Message, size)
+	}
+	return &MemoryQueue{queues: queues}
+}
+
+// Enqueue adds notif to its channel's queue and returns a generated message
+// ID. It never blocks: a full queue returns ErrQueueFull.
+func (q *MemoryQueue) Enqueue(ctx context.Context, notif *db.Notification) (string, error) {
+	messages, ok := q.queues[notif.Channel]
+	if !ok {
+		return "", fmt.Errorf("no memory queue for channel %q", notif.Channel)
+	}
+
+	msg := NewMessage(notif)
+	select {
+	case messages <- &msg:
+		return uuid.NewString(), nil
+	default:
+		return "", ErrQueueFull
+	}
+}
+
+// Channel returns the consumer side of channel's queue, or nil if the queue
+// has no such channel.
+func (q *MemoryQueue) Channel(channel string) *MemoryChannel {
+	messages, ok := q.queues[channel]
+	if !ok {
+		return nil
+	}
+	return &MemoryChannel{messages: messages}
+}
+
+// MemoryChannel consumes one channel of a MemoryQueue. It implements the
+// same ProcessBatch contract as Consumer.
+type MemoryChannel struct {
+	messages chan *Message
+}
+
+// ProcessBatch waits briefly for a message, then runs handle on it and on
+// whatever else is already queued, up to a full batch. A message handle
+// fails on is dropped rather than redelivered: its row is still pending,
+// so the poll loop picks it up.
+func (c *MemoryChannel) ProcessBatch(ctx context.Context, handle func(context.Context, *Message) error) (BatchResult, error) {
+	timer := time.NewTimer(memoryWait)
+	defer timer.Stop()
+
+	var batch []*Message
+	select {
+	case msg := <-c.messages:
+		batch = append(batch, msg)
+	case <-timer.C:
+		return BatchResult{}, nil
+	case <-ctx.Done():
+		return BatchResult{}, ctx.Err()
+	}
+
+fill:
+	for len(batch) < maxBatchSize {
+		select {
+		case msg := <-c.messages:
+			batch = append(batch, msg)
+		default:
+			break fill
+		}
+	}
+
+	result := BatchResult{Received: len(batch)}
+	for _, msg := range batch {
+		if err := handle(ctx, msg); err != nil {
+			result.Failed++
+			continue
+		}
+		result.Succeeded++
+	}
+	return result, nil
+}
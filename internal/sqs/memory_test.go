@@ -0,0 +1,102 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestMemoryQueue_RoutesByChannel(t *testing.T) {
+	q := NewMemoryQueue(10, "email", "sms")
+	ctx := context.Background()
+
+	email := &db.Notification{ID: uuid.New(), Channel: "email"}
+	sms := &db.Notification{ID: uuid.New(), Channel: "sms"}
+	for _, n := range []*db.Notification{email, sms} {
+		if _, err := q.Enqueue(ctx, n); err != nil {
+			t.Fatalf("enqueue %s: %v", n.Channel, err)
+		}
+	}
+	if _, err := q.Enqueue(ctx, &db.Notification{ID: uuid.New(), Channel: "webhook"}); err == nil {
+		t.Error("expected error for a channel without a queue")
+	}
+	if q.Channel("webhook") != nil {
+		t.Error("expected nil consumer for a channel without a queue")
+	}
+
+	var handled []string
+	result, err := q.Channel("email").ProcessBatch(ctx, func(_ context.Context, m *Message) error {
+		handled = append(handled, m.NotificationID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("process batch: %v", err)
+	}
+	if result.Received != 1 || result.Succeeded != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(handled) != 1 || handled[0] != email.ID.String() {
+		t.Errorf("expected only the email notification, got %v", handled)
+	}
+}
+
+func TestMemoryQueue_Full(t *testing.T) {
+	q := NewMemoryQueue(1, "email")
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, &db.Notification{ID: uuid.New(), Channel: "email"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Enqueue(ctx, &db.Notification{ID: uuid.New(), Channel: "email"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestMemoryChannel_BatchesAndCountsFailures(t *testing.T) {
+	q := NewMemoryQueue(20, "email")
+	ctx := context.Background()
+
+	for i := 0; i < maxBatchSize+2; i++ {
+		if _, err := q.Enqueue(ctx, &db.Notification{ID: uuid.New(), Channel: "email"}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	calls := 0
+	result, err := q.Channel("email").ProcessBatch(ctx, func(context.Context, *Message) error {
+		calls++
+		if calls == 1 {
+			return errors.New("claim failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("process batch: %v", err)
+	}
+	if result.Received != maxBatchSize || result.Failed != 1 || result.Succeeded != maxBatchSize-1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	result, err = q.Channel("email").ProcessBatch(ctx, func(context.Context, *Message) error { return nil })
+	if err != nil {
+		t.Fatalf("process batch: %v", err)
+	}
+	if result.Received != 2 {
+		t.Errorf("expected the remaining 2 messages, got %+v", result)
+	}
+}
+
+func TestMemoryChannel_StopsOnCancel(t *testing.T) {
+	q := NewMemoryQueue(1, "email")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := q.Channel("email").ProcessBatch(ctx, func(context.Context, *Message) error { return nil })
+	if !errors.Is(err, context.Canceled) || result.Received != 0 {
+		t.Errorf("expected empty result and context.Canceled, got %+v, %v", result, err)
+	}
+}
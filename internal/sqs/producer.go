@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
 )
 
 // Config holds SQS configuration.
@@ -28,10 +31,83 @@ type Message struct {
 	UserID         string          `json:"user_id"`
 	Channel        string          `json:"channel"`
 	Payload        json.RawMessage `json:"payload"`
+	CorrelationID  string          `json:"correlation_id,omitempty"`
 	Attempt        int             `json:"attempt"`
 	EnqueuedAt     int64           `json:"enqueued_at"`
 }
 
+// SQS message attributes set on every enqueue, so consumers and
+// subscription filters can route and log without parsing the body.
+const (
+	correlationIDAttribute = "correlation_id"
+	channelAttribute       = "channel"
+	tenantIDAttribute      = "tenant_id"
+	priorityAttribute      = "priority"
+	traceParentAttribute   = observ.TraceParentHeader
+)
+
+// Priority attribute values. Critical notifications keep sending while
+// their tenant is blocked, so consumers may want to drain them first.
+const (
+	PriorityCritical = "critical"
+	PriorityNormal   = "normal"
+)
+
+// Priority returns the priority attribute value for notif.
+func Priority(notif *db.Notification) string {
+	if slices.Contains(notif.Tags, db.TagCritical) {
+		return PriorityCritical
+	}
+	return PriorityNormal
+}
+
+// Attributes returns the routing and tracing attributes for notif, all
+// String-typed. The SNS fan-out publisher sends the same set, so channel
+// queues subscribed to its topic see what direct enqueues carry.
+func Attributes(notif *db.Notification) map[string]string {
+	attrs := map[string]string{
+		channelAttribute:  notif.Channel,
+		tenantIDAttribute: notif.TenantID.String(),
+		priorityAttribute: Priority(notif),
+	}
+	if notif.CorrelationID != "" {
+		attrs[correlationIDAttribute] = notif.CorrelationID
+		if tp := observ.TraceParent(notif.CorrelationID); tp != "" {
+			attrs[traceParentAttribute] = tp
+		}
+	}
+	return attrs
+}
+
+func messageAttributes(notif *db.Notification) map[string]types.MessageAttributeValue {
+	attrs := make(map[string]types.MessageAttributeValue)
+	for name, v := range Attributes(notif) {
+		attrs[name] = stringAttribute(v)
+	}
+	return attrs
+}
+
+func stringAttribute(v string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(v),
+	}
+}
+
+// NewMessage builds the queue message announcing notif.
+func NewMessage(notif *db.Notification) Message {
+	return Message{
+		NotificationID: notif.ID.String(),
+		TenantID:       notif.TenantID.String(),
+		UserID:         notif.UserID.String(),
+		Channel:        notif.Channel,
+		Payload:        notif.Payload,
+		CorrelationID:  notif.CorrelationID,
+		Attempt:        notif.Attempt,
+		EnqueuedAt:     time.Now().UnixNano(),
+	}
+}
+
 // Producer sends notifications to SQS.
 type Producer struct {
 	client   *sqs.Client
@@ -62,24 +138,15 @@ func NewProducer(ctx context.Context, cfg Config, logger *zap.Logger) (*Producer
 // Enqueue sends a notification to SQS for asynchronous processing.
 // Returns the message ID for tracking.
 func (p *Producer) Enqueue(ctx context.Context, notif *db.Notification) (string, error) {
-	msg := Message{
-		NotificationID: notif.ID.String(),
-		TenantID:       notif.TenantID.String(),
-		UserID:         notif.UserID.String(),
-		Channel:        notif.Channel,
-		Payload:        notif.Payload,
-		Attempt:        notif.Attempt,
-		EnqueuedAt:     time.Now().UnixNano(),
-	}
-
-	body, err := json.Marshal(msg)
+	body, err := json.Marshal(NewMessage(notif))
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal message: %w", err)
 	}
 
 	input := &sqs.SendMessageInput{
-		QueueUrl:    aws.String(p.queueURL),
-		MessageBody: aws.String(string(body)),
+		QueueUrl:          aws.String(p.queueURL),
+		MessageBody:       aws.String(string(body)),
+		MessageAttributes: messageAttributes(notif),
 	}
 
 	result, err := p.client.SendMessage(ctx, input)
@@ -87,6 +154,7 @@ func (p *Producer) Enqueue(ctx context.Context, notif *db.Notification) (string,
 		p.logger.Error("failed to send message to sqs",
 			zap.Error(err),
 			zap.String("notification_id", notif.ID.String()),
+			zap.String("correlation_id", notif.CorrelationID),
 		)
 		return "", fmt.Errorf("sqs send failed: %w", err)
 	}
@@ -148,10 +216,11 @@ func NewConsumer(ctx context.Context, cfg Config, logger *zap.Logger) (*Consumer
 // ReceiveMessage retrieves a message from SQS with long polling.
 func (c *Consumer) ReceiveMessage(ctx context.Context) (*Message, string, error) {
 	input := &sqs.ReceiveMessageInput{
-		QueueUrl:            aws.String(c.queueURL),
-		MaxNumberOfMessages: 1,
-		WaitTimeSeconds:     20,
-		VisibilityTimeout:   60,
+		QueueUrl:              aws.String(c.queueURL),
+		MaxNumberOfMessages:   1,
+		WaitTimeSeconds:       20,
+		VisibilityTimeout:     visibilityTimeout,
+		MessageAttributeNames: []string{"All"},
 	}
 
 	result, err := c.client.ReceiveMessage(ctx, input)
@@ -170,10 +239,19 @@ func (c *Consumer) ReceiveMessage(ctx context.Context) (*Message, string, error)
 		c.logger.Error("failed to unmarshal message", zap.Error(err))
 		return nil, "", fmt.Errorf("invalid message format: %w", err)
 	}
+	applyAttributes(&msg, msgData.MessageAttributes)
 
 	return &msg, *msgData.ReceiptHandle, nil
 }
 
+// applyAttributes fills body fields left empty by older producers from the
+// message's attributes.
+func applyAttributes(msg *Message, attrs map[string]types.MessageAttributeValue) {
+	if msg.CorrelationID == "" {
+		msg.CorrelationID = aws.ToString(attrs[correlationIDAttribute].StringValue)
+	}
+}
+
 // DeleteMessage removes a message from SQS after successful processing.
 func (c *Consumer) DeleteMessage(ctx context.Context, receiptHandle string) error {
 	input := &sqs.DeleteMessageInput{
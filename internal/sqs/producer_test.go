@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
 )
 
 func TestMessage_Marshal(t *testing.T) {
@@ -98,3 +99,43 @@ func TestEnqueueBatchEmpty(t *testing.T) {
 		t.Errorf("expected empty result, got %d items", len(result))
 	}
 }
+
+func TestMessageAttributes(t *testing.T) {
+	notif := &db.Notification{
+		ID:            uuid.New(),
+		TenantID:      uuid.New(),
+		Channel:       db.ChannelSMS,
+		Tags:          []string{db.TagCritical},
+		CorrelationID: "3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+	}
+
+	attrs := messageAttributes(notif)
+	want := map[string]string{
+		channelAttribute:       db.ChannelSMS,
+		tenantIDAttribute:      notif.TenantID.String(),
+		priorityAttribute:      PriorityCritical,
+		correlationIDAttribute: notif.CorrelationID,
+	}
+	for name, v := range want {
+		if got := attrs[name].StringValue; got == nil || *got != v {
+			t.Errorf("attribute %s = %v, want %q", name, got, v)
+		}
+	}
+	tp := attrs[traceParentAttribute].StringValue
+	if tp == nil || observ.TraceIDFromParent(*tp) != "3f2504e04f8911d39a0c0305e82c3301" {
+		t.Errorf("expected traceparent continuing the correlation id's trace, got %v", tp)
+	}
+}
+
+func TestMessageAttributes_Defaults(t *testing.T) {
+	attrs := messageAttributes(&db.Notification{TenantID: uuid.New(), Channel: db.ChannelEmail})
+	if got := *attrs[priorityAttribute].StringValue; got != PriorityNormal {
+		t.Errorf("priority = %q, want %q", got, PriorityNormal)
+	}
+	if _, ok := attrs[correlationIDAttribute]; ok {
+		t.Error("expected no correlation id attribute without a correlation id")
+	}
+	if _, ok := attrs[traceParentAttribute]; ok {
+		t.Error("expected no traceparent without a correlation id")
+	}
+}
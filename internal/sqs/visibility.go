@@ -0,0 +1,81 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// visibilityTimeout is how long, in seconds, a received message stays
+// hidden from other consumers. KeepHidden renews it while a send runs.
+const visibilityTimeout = 60
+
+// KeepHidden renews the message's visibility timeout every half timeout
+// until stop is called or ctx is done, so a slow webhook or a large email
+// doesn't reappear on the queue and get sent a second time by another
+// consumer mid-send. stop waits for any renewal in flight, so deleting the
+// message after it never races a ChangeVisibility on a stale handle.
+func (c *Consumer) KeepHidden(ctx context.Context, receiptHandle string) (stop func()) {
+	return keepHidden(ctx, func(ctx context.Context) error {
+		return c.ChangeVisibility(ctx, receiptHandle, visibilityTimeout)
+	}, visibilityTimeout*time.Second/2, c.logger)
+}
+
+// keepHidden calls extend every interval until stopped. A failed renewal
+// is logged and retried on the next tick: the message may become visible
+// again, but giving up would guarantee it.
+func keepHidden(ctx context.Context, extend func(context.Context) error, interval time.Duration, logger *zap.Logger) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := extend(ctx); err != nil && ctx.Err() == nil {
+					logger.Warn("failed to extend sqs message visibility", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// Process receives one message and runs handle on it, keeping the message
+// hidden for as long as handle takes. The message is deleted when handle
+// succeeds and left to reappear for a retry when it fails. ok is false
+// when no message arrived within the long poll.
+func (c *Consumer) Process(ctx context.Context, handle func(context.Context, *Message) error) (ok bool, err error) {
+	msg, receiptHandle, err := c.ReceiveMessage(ctx)
+	if err != nil || msg == nil {
+		return false, err
+	}
+
+	stop := c.KeepHidden(ctx, receiptHandle)
+	err = handle(ctx, msg)
+	stop()
+	if err != nil {
+		return true, fmt.Errorf("handle message %s: %w", msg.NotificationID, err)
+	}
+
+	// The handler's work is done; don't let a cancelled ctx leave the
+	// message behind to be sent again.
+	deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	return true, c.DeleteMessage(deleteCtx, receiptHandle)
+}
@@ -0,0 +1,62 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestKeepHidden_ExtendsUntilStopped(t *testing.T) {
+	var calls atomic.Int32
+	stop := keepHidden(context.Background(), func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}, 10*time.Millisecond, zap.NewNop())
+
+	time.Sleep(55 * time.Millisecond)
+	stop()
+	n := calls.Load()
+	if n < 3 {
+		t.Fatalf("expected several renewals during a long send, got %d", n)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if calls.Load() != n {
+		t.Error("expected no renewals after stop")
+	}
+	stop() // safe to call twice
+}
+
+func TestKeepHidden_KeepsTryingAfterFailure(t *testing.T) {
+	var calls atomic.Int32
+	stop := keepHidden(context.Background(), func(context.Context) error {
+		calls.Add(1)
+		return errors.New("throttled")
+	}, 10*time.Millisecond, zap.NewNop())
+	defer stop()
+
+	time.Sleep(45 * time.Millisecond)
+	if calls.Load() < 2 {
+		t.Errorf("expected renewals to continue after a failure, got %d", calls.Load())
+	}
+}
+
+func TestKeepHidden_StopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	stop := keepHidden(ctx, func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}, 10*time.Millisecond, zap.NewNop())
+	defer stop()
+
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Errorf("expected no renewals after the context is done, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,22 @@
+package tlsserve
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autocertConfig obtains and renews certificates from Let's Encrypt. Their
+// TLS-ALPN-01 challenge is answered on the HTTPS port itself; the returned
+// handler also answers HTTP-01 on the plain-HTTP listener, passing other
+// requests to fallback.
+func autocertConfig(cfg Config, fallback http.Handler) (*tls.Config, http.Handler) {
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+		Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		Email:      cfg.AutocertEmail,
+	}
+	return m.TLSConfig(), m.HTTPHandler(fallback)
+}
@@ -0,0 +1,134 @@
+// Package tlsserve lets the gateway terminate TLS itself, for deployments
+// without a load balancer in front: from a certificate and key on disk, or
+// from Let's Encrypt through autocert. HTTP/2 is negotiated over ALPN by
+// net/http once the server has a TLS config.
+package tlsserve
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// Config selects where certificates come from. Set CertFile and KeyFile,
+// or AutocertDomains, not both.
+type Config struct {
+	CertFile string
+	KeyFile  string
+
+	// AutocertDomains are the host names certificates are requested for.
+	// Let's Encrypt must reach the gateway on port 443 to validate them.
+	AutocertDomains  []string
+	AutocertCacheDir string // where issued certificates are kept across restarts
+	AutocertEmail    string // optional contact for expiry notices
+
+	// MinVersion is the oldest TLS version accepted: "1.2" or "1.3".
+	MinVersion string
+}
+
+// Enabled reports whether the gateway should serve HTTPS.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertDomains) > 0
+}
+
+// cipherSuites are the TLS 1.2 suites offered: ECDHE for forward secrecy
+// and AEAD ciphers only. TLS 1.3 suites are not configurable in Go and are
+// all modern. The AES-128-GCM suites must stay listed for HTTP/2 (RFC 7540
+// section 9.2.2).
+var cipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// New returns the server's TLS config and the handler for the plain-HTTP
+// listener, which redirects to HTTPS on httpsPort (and, with autocert, also
+// answers ACME http-01 challenges).
+func New(cfg Config, httpsPort int) (*tls.Config, http.Handler, error) {
+	minVersion, err := parseVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		tlsConfig *tls.Config
+		redirect  = RedirectHandler(httpsPort)
+	)
+	switch {
+	case cfg.CertFile != "" && len(cfg.AutocertDomains) > 0:
+		return nil, nil, errors.New("set a certificate file or autocert domains, not both")
+	case cfg.CertFile != "":
+		if cfg.KeyFile == "" {
+			return nil, nil, errors.New("a certificate file needs a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	case len(cfg.AutocertDomains) > 0:
+		tlsConfig, redirect = autocertConfig(cfg, redirect)
+	default:
+		return nil, nil, errors.New("TLS is not configured")
+	}
+
+	tlsConfig.MinVersion = minVersion
+	tlsConfig.CipherSuites = cipherSuites
+	return tlsConfig, redirect, nil
+}
+
+func parseVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q (use 1.2 or 1.3)", v)
+	}
+}
+
+// RedirectHandler sends every request to the same URL over HTTPS on
+// httpsPort. Only GET and HEAD are redirected permanently; anything else
+// gets 400, so a client that POSTed over plain HTTP learns about it instead
+// of having its body silently dropped by the redirect.
+func RedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "use HTTPS", http.StatusBadRequest)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// HSTS tells browsers to use HTTPS only for maxAge seconds. The header is
+// only set on requests that arrived over TLS, as RFC 6797 requires; 0
+// disables it.
+func HSTS(maxAge int) func(http.Handler) http.Handler {
+	value := "max-age=" + strconv.Itoa(maxAge)
+	return func(next http.Handler) http.Handler {
+		if maxAge <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS != nil {
+				w.Header().Set("Strict-Transport-Security", value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
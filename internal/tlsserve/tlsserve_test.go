@@ -0,0 +1,156 @@
+package tlsserve
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSigned writes a throwaway certificate and key for localhost.
+func writeSelfSigned(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	certFile, keyFile, _ = writeSelfSignedPEM(t)
+	return certFile, keyFile
+}
+
+// writeSelfSignedPEM is writeSelfSigned that also returns the certificate,
+// for clients that must trust it.
+func writeSelfSignedPEM(t *testing.T) (certFile, keyFile string, certPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, certPEM
+}
+
+func TestNew_CertFiles(t *testing.T) {
+	certFile, keyFile, certPEM := writeSelfSignedPEM(t)
+
+	tlsConfig, _, err := New(Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"}, 8443)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("unexpected config: %d certificates, min version %x", len(tlsConfig.Certificates), tlsConfig.MinVersion)
+	}
+
+	// Serve over TLS with HTTP/2 the way the gateway does.
+	srv := httptest.NewUnstartedServer(HSTS(60)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})))
+	srv.TLS = tlsConfig
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", resp.Proto)
+	}
+	if resp.Header.Get("Strict-Transport-Security") != "max-age=60" {
+		t.Errorf("expected an HSTS header, got %q", resp.Header.Get("Strict-Transport-Security"))
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t)
+
+	tests := map[string]Config{
+		"nothing":          {},
+		"missing key":      {CertFile: certFile},
+		"unreadable":       {CertFile: certFile + ".missing", KeyFile: keyFile},
+		"both sources":     {CertFile: certFile, KeyFile: keyFile, AutocertDomains: []string{"api.example.com"}},
+		"bad min version":  {CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"},
+		"swapped pem pair": {CertFile: keyFile, KeyFile: certFile},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := New(cfg, 443); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     int
+		method   string
+		target   string
+		status   int
+		location string
+	}{
+		{"default port", 443, http.MethodGet, "http://api.example.com:8080/v1/notifications?limit=5", http.StatusMovedPermanently, "https://api.example.com/v1/notifications?limit=5"},
+		{"custom port", 8443, http.MethodGet, "http://api.example.com/health", http.StatusMovedPermanently, "https://api.example.com:8443/health"},
+		{"post", 443, http.MethodPost, "http://api.example.com/v1/notifications", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RedirectHandler(tt.port).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+				t.Errorf("expected %d %q, got %d %q", tt.status, tt.location, rec.Code, rec.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestHSTS_PlainHTTP(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HSTS(60)(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Header().Get("Strict-Transport-Security") != "" {
+		t.Error("HSTS must not be sent over plain HTTP")
+	}
+}
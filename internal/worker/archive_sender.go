@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// archiveTimeout bounds the archive write after a send. Like the status
+// write it ignores cancellation: the message is already delivered.
+const archiveTimeout = 10 * time.Second
+
+// Archiver stores the rendered message of a delivered notification and
+// returns the key it was stored under.
+type Archiver interface {
+	Archive(ctx context.Context, notif *db.Notification) (string, error)
+}
+
+// ArchiveSender wraps the full sender chain and, after each successful
+// send, archives the payload as the provider received it, setting
+// notif.ArchiveKey for the worker to persist. Wrap it outermost, so
+// templates, enrichment and link shortening have all been applied.
+// Archival is best-effort: a failed write is logged and counted but never
+// fails a send that already went out.
+type ArchiveSender struct {
+	inner    Sender
+	archiver Archiver
+	logger   *zap.Logger
+}
+
+// NewArchiveSender wraps inner with delivery archival.
+func NewArchiveSender(inner Sender, archiver Archiver, logger *zap.Logger) *ArchiveSender {
+	return &ArchiveSender{
+		inner:    inner,
+		archiver: archiver,
+		logger:   logger,
+	}
+}
+
+// Send delivers notif and archives it on success.
+func (s *ArchiveSender) Send(ctx context.Context, notif *db.Notification) error {
+	if err := s.inner.Send(ctx, notif); err != nil {
+		return err
+	}
+
+	archiveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), archiveTimeout)
+	defer cancel()
+	key, err := s.archiver.Archive(archiveCtx, notif)
+	if err != nil {
+		metrics.RecordArchiveWrite(notif.Channel, OutcomeError)
+		observ.Logger(ctx, s.logger).Warn("failed to archive delivered message",
+			zap.Error(err),
+			zap.String("channel", notif.Channel),
+		)
+		return nil
+	}
+	metrics.RecordArchiveWrite(notif.Channel, OutcomeSuccess)
+	notif.ArchiveKey = key
+	return nil
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *ArchiveSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
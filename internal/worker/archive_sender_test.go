@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type fakeArchiver struct {
+	archived []json.RawMessage
+	err      error
+}
+
+func (a *fakeArchiver) Archive(ctx context.Context, notif *db.Notification) (string, error) {
+	if a.err != nil {
+		return "", a.err
+	}
+	a.archived = append(a.archived, notif.Payload)
+	return "deliveries/" + notif.ID.String() + ".json", nil
+}
+
+// renderingSender stands in for the template sender: it rewrites the
+// payload before delivering it.
+type renderingSender struct {
+	MockSender
+}
+
+func (s *renderingSender) Send(ctx context.Context, notif *db.Notification) error {
+	notif.Payload = json.RawMessage(`{"html":"<p>rendered</p>"}`)
+	return s.MockSender.Send(ctx, notif)
+}
+
+func TestArchiveSender(t *testing.T) {
+	t.Run("archives the rendered payload", func(t *testing.T) {
+		archiver := &fakeArchiver{}
+		s := NewArchiveSender(&renderingSender{}, archiver, zap.NewNop())
+		notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelEmail, Payload: json.RawMessage(`{"template_id":"welcome"}`)}
+
+		if err := s.Send(context.Background(), notif); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(archiver.archived) != 1 || string(archiver.archived[0]) != `{"html":"<p>rendered</p>"}` {
+			t.Errorf("expected the rendered payload archived, got %s", archiver.archived)
+		}
+		if notif.ArchiveKey != "deliveries/"+notif.ID.String()+".json" {
+			t.Errorf("unexpected archive key %q", notif.ArchiveKey)
+		}
+	})
+
+	t.Run("failed send is not archived", func(t *testing.T) {
+		archiver := &fakeArchiver{}
+		s := NewArchiveSender(&MockSender{shouldFail: true}, archiver, zap.NewNop())
+		notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelSMS}
+
+		if err := s.Send(context.Background(), notif); err == nil {
+			t.Fatal("expected the send error")
+		}
+		if len(archiver.archived) != 0 || notif.ArchiveKey != "" {
+			t.Error("expected nothing archived")
+		}
+	})
+
+	t.Run("archive failure keeps the send", func(t *testing.T) {
+		s := NewArchiveSender(&MockSender{}, &fakeArchiver{err: errors.New("s3 down")}, zap.NewNop())
+		notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelWebhook}
+
+		if err := s.Send(context.Background(), notif); err != nil {
+			t.Fatalf("expected a delivered send to succeed, got %v", err)
+		}
+		if notif.ArchiveKey != "" {
+			t.Errorf("expected no archive key, got %q", notif.ArchiveKey)
+		}
+	})
+}
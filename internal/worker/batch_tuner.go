@@ -0,0 +1,27 @@
+package worker
+
+import "time"
+
+// batchTuner adapts the claim size to how the last batch went, additive
+// increase and multiplicative decrease: a full batch that finished within
+// target with few failures grows the next claim by a quarter, and a batch
+// that overran target or failed more than a fifth of its sends halves it.
+// Growing only on full batches keeps a quiet queue from inflating the size
+// past what the providers have actually been shown to handle.
+type batchTuner struct {
+	size   int
+	min    int
+	max    int
+	target time.Duration
+}
+
+// observe records a finished batch and returns the claim size for the next.
+func (t *batchTuner) observe(claimed, failed int, took time.Duration) int {
+	switch {
+	case took > t.target || failed*5 > claimed:
+		t.size = max(t.min, t.size/2)
+	case claimed >= t.size:
+		t.size = min(t.max, t.size+max(1, t.size/4))
+	}
+	return t.size
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchTuner(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		claimed int
+		failed  int
+		took    time.Duration
+		want    int
+	}{
+		{"full and fast grows by a quarter", 20, 20, 0, time.Second, 25},
+		{"small sizes grow by at least one", 2, 2, 0, time.Second, 3},
+		{"growth stops at max", 48, 48, 0, time.Second, 50},
+		{"partial batch holds", 20, 7, 0, time.Second, 20},
+		{"a few failures still grow", 20, 20, 4, time.Second, 25},
+		{"many failures halve", 20, 20, 5, time.Second, 10},
+		{"overrunning the target halves", 20, 20, 0, 6 * time.Second, 10},
+		{"slow partial batch halves", 20, 3, 0, 6 * time.Second, 10},
+		{"shrinking stops at min", 3, 3, 3, time.Second, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuner := &batchTuner{size: tt.size, min: 2, max: 50, target: 5 * time.Second}
+			if got := tuner.observe(tt.claimed, tt.failed, tt.took); got != tt.want {
+				t.Errorf("expected size %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
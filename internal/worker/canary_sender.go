@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Canary variants, the "variant" label of nimbus_canary_sends_total.
+const (
+	CanaryVariantBaseline = "baseline"
+	CanaryVariantCanary   = "canary"
+)
+
+// CanaryConfig configures a CanarySender.
+type CanaryConfig struct {
+	Channel string  // the channel being canaried
+	Percent float64 // share of the channel's sends routed to the canary, 0-100
+
+	// The canary is rolled back once, within one Window, both variants have
+	// at least MinSamples sends and the canary's failure rate exceeds the
+	// baseline's by more than MaxFailureDelta (0.05 = 5 points).
+	Window          time.Duration
+	MinSamples      int
+	MaxFailureDelta float64
+}
+
+// canaryStats counts one variant's sends in the current window.
+type canaryStats struct {
+	sent, failed int
+	latency      time.Duration
+}
+
+func (s canaryStats) failureRate() float64 {
+	if s.sent == 0 {
+		return 0
+	}
+	return float64(s.failed) / float64(s.sent)
+}
+
+func (s canaryStats) meanLatency() time.Duration {
+	if s.sent == 0 {
+		return 0
+	}
+	return s.latency / time.Duration(s.sent)
+}
+
+// CanarySender routes Percent of a channel's sends to a new provider
+// implementation and the rest to the current one, comparing their failure
+// rates and latency as it goes. If the canary does measurably worse it is
+// rolled back: every send goes to the baseline until the process restarts.
+// Wrap each side in its own MetricsSender, under distinct provider names,
+// so nimbus_sender_duration_seconds compares their latency too.
+type CanarySender struct {
+	baseline Sender
+	canary   Sender
+	cfg      CanaryConfig
+	logger   *zap.Logger
+	roll     func() float64 // in [0, 100)
+
+	mu          sync.Mutex
+	rolledBack  bool
+	windowStart time.Time
+	stats       map[string]*canaryStats
+}
+
+// NewCanarySender creates a sender that canaries cfg.Channel on canary.
+// Other channels always go to baseline.
+func NewCanarySender(baseline, canary Sender, cfg CanaryConfig, logger *zap.Logger) *CanarySender {
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Minute
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 50
+	}
+	if cfg.MaxFailureDelta <= 0 {
+		cfg.MaxFailureDelta = 0.05
+	}
+	metrics.SetCanaryActive(cfg.Channel, true)
+	return &CanarySender{
+		baseline:    baseline,
+		canary:      canary,
+		cfg:         cfg,
+		logger:      logger,
+		roll:        func() float64 { return rand.Float64() * 100 },
+		windowStart: time.Now(),
+		stats: map[string]*canaryStats{
+			CanaryVariantBaseline: {},
+			CanaryVariantCanary:   {},
+		},
+	}
+}
+
+// Send routes the notification to the baseline or the canary and records
+// the outcome against that variant.
+func (s *CanarySender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != s.cfg.Channel {
+		return s.baseline.Send(ctx, notif)
+	}
+
+	variant, sender := CanaryVariantBaseline, s.baseline
+	if !s.RolledBack() && s.roll() < s.cfg.Percent {
+		variant, sender = CanaryVariantCanary, s.canary
+	}
+
+	start := time.Now()
+	err := sender.Send(ctx, notif)
+	s.record(variant, err, time.Since(start))
+	return err
+}
+
+// SupportsChannel reports whether the baseline supports the channel; the
+// canary only ever takes a share of the baseline's traffic.
+func (s *CanarySender) SupportsChannel(channel string) bool {
+	return s.baseline.SupportsChannel(channel)
+}
+
+// RolledBack reports whether the canary has been rolled back.
+func (s *CanarySender) RolledBack() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rolledBack
+}
+
+func (s *CanarySender) record(variant string, err error, d time.Duration) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	metrics.RecordCanarySend(s.cfg.Channel, variant, outcome)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.windowStart) >= s.cfg.Window {
+		s.windowStart = time.Now()
+		s.stats[CanaryVariantBaseline] = &canaryStats{}
+		s.stats[CanaryVariantCanary] = &canaryStats{}
+	}
+	st := s.stats[variant]
+	st.sent++
+	st.latency += d
+	if err != nil {
+		st.failed++
+	}
+
+	if !s.rolledBack && s.shouldRollBack() {
+		s.rolledBack = true
+		metrics.SetCanaryActive(s.cfg.Channel, false)
+		base, canary := s.stats[CanaryVariantBaseline], s.stats[CanaryVariantCanary]
+		s.logger.Error("canary rolled back, all sends on the baseline provider",
+			zap.String("channel", s.cfg.Channel),
+			zap.Float64("canary_failure_rate", canary.failureRate()),
+			zap.Float64("baseline_failure_rate", base.failureRate()),
+			zap.Duration("canary_mean_latency", canary.meanLatency()),
+			zap.Duration("baseline_mean_latency", base.meanLatency()),
+			zap.Int("canary_sent", canary.sent),
+			zap.Int("baseline_sent", base.sent),
+		)
+	}
+}
+
+// shouldRollBack must be called with s.mu held.
+func (s *CanarySender) shouldRollBack() bool {
+	base, canary := s.stats[CanaryVariantBaseline], s.stats[CanaryVariantCanary]
+	if base.sent < s.cfg.MinSamples || canary.sent < s.cfg.MinSamples {
+		return false
+	}
+	return canary.failureRate() > base.failureRate()+s.cfg.MaxFailureDelta
+}
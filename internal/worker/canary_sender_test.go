@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type countingSender struct {
+	channel string
+	err     error
+	sent    int
+}
+
+func (s *countingSender) Send(ctx context.Context, notif *db.Notification) error {
+	s.sent++
+	return s.err
+}
+
+func (s *countingSender) SupportsChannel(channel string) bool { return channel == s.channel }
+
+// newTestCanary routes every other email send to the canary.
+func newTestCanary(baseline, canary Sender, cfg CanaryConfig) *CanarySender {
+	s := NewCanarySender(baseline, canary, cfg, zap.NewNop())
+	n := 0
+	s.roll = func() float64 {
+		n++
+		if n%2 == 0 {
+			return 0
+		}
+		return 99.9
+	}
+	return s
+}
+
+func TestCanarySender_SplitsTraffic(t *testing.T) {
+	baseline := &countingSender{channel: "email"}
+	canary := &countingSender{channel: "email"}
+	s := newTestCanary(baseline, canary, CanaryConfig{Channel: "email", Percent: 50})
+
+	for i := 0; i < 10; i++ {
+		if err := s.Send(context.Background(), &db.Notification{Channel: "email"}); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+	if baseline.sent != 5 || canary.sent != 5 {
+		t.Errorf("expected an even split, got baseline %d canary %d", baseline.sent, canary.sent)
+	}
+
+	// Other channels never reach the canary.
+	_ = s.Send(context.Background(), &db.Notification{Channel: "sms"})
+	if canary.sent != 5 || baseline.sent != 6 {
+		t.Errorf("expected sms on the baseline, got baseline %d canary %d", baseline.sent, canary.sent)
+	}
+	if !s.SupportsChannel("email") || s.SupportsChannel("webhook") {
+		t.Error("expected SupportsChannel to follow the baseline")
+	}
+}
+
+func TestCanarySender_RollsBackOnHigherFailureRate(t *testing.T) {
+	baseline := &countingSender{channel: "email"}
+	canary := &countingSender{channel: "email", err: errors.New("throttled")}
+	s := newTestCanary(baseline, canary, CanaryConfig{Channel: "email", Percent: 50, MinSamples: 5})
+
+	for i := 0; i < 9; i++ {
+		_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+	}
+	if s.RolledBack() {
+		t.Fatal("expected no rollback before both variants have MinSamples sends")
+	}
+	_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+	if !s.RolledBack() {
+		t.Fatal("expected rollback once the canary fails more than the baseline")
+	}
+
+	canarySent := canary.sent
+	for i := 0; i < 10; i++ {
+		if err := s.Send(context.Background(), &db.Notification{Channel: "email"}); err != nil {
+			t.Fatalf("expected sends after rollback to use the baseline, got %v", err)
+		}
+	}
+	if canary.sent != canarySent {
+		t.Errorf("expected no canary sends after rollback, got %d more", canary.sent-canarySent)
+	}
+}
+
+func TestCanarySender_ToleratesComparableFailureRate(t *testing.T) {
+	failing := errors.New("provider error")
+	baseline := &countingSender{channel: "email", err: failing}
+	canary := &countingSender{channel: "email", err: failing}
+	s := newTestCanary(baseline, canary, CanaryConfig{Channel: "email", Percent: 50, MinSamples: 5})
+
+	for i := 0; i < 20; i++ {
+		_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+	}
+	if s.RolledBack() {
+		t.Error("expected no rollback when the canary fails no more than the baseline")
+	}
+}
+
+func TestCanarySender_WindowResetsStats(t *testing.T) {
+	baseline := &countingSender{channel: "email"}
+	canary := &countingSender{channel: "email", err: errors.New("throttled")}
+	s := newTestCanary(baseline, canary, CanaryConfig{Channel: "email", Percent: 50, MinSamples: 5, Window: time.Hour})
+
+	for i := 0; i < 8; i++ {
+		_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+	}
+	// Start a new window: the earlier failures no longer count.
+	s.mu.Lock()
+	s.windowStart = time.Now().Add(-2 * time.Hour)
+	s.mu.Unlock()
+	for i := 0; i < 8; i++ {
+		_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+	}
+	if s.RolledBack() {
+		t.Error("expected stats from an expired window to be discarded")
+	}
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockCaptureStore struct {
+	captured   []*db.CapturedDelivery
+	shouldFail bool
+}
+
+func (m *mockCaptureStore) CaptureDelivery(ctx context.Context, delivery *db.CapturedDelivery) error {
+	if m.shouldFail {
+		return errors.New("database error")
+	}
+	m.captured = append(m.captured, delivery)
+	return nil
+}
+
+func TestCaptureSender_RecordsRecipient(t *testing.T) {
+	tests := []struct {
+		channel   string
+		payload   string
+		recipient string
+	}{
+		{"email", `{"to":"user@example.com","subject":"Hi","body":"Hello"}`, "user@example.com"},
+		{"sms", `{"phone_number":"+15551234567","message":"Hi"}`, "+15551234567"},
+		{"webhook", `{"url":"https://example.com/hook"}`, "https://example.com/hook"},
+		{"email", `not-json`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			store := &mockCaptureStore{}
+			sender := NewCaptureSender(store, zap.NewNop())
+
+			notif := &db.Notification{
+				ID:       uuid.New(),
+				TenantID: uuid.New(),
+				Channel:  tt.channel,
+				Payload:  []byte(tt.payload),
+			}
+
+			if err := sender.Send(context.Background(), notif); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(store.captured) != 1 {
+				t.Fatalf("expected 1 captured delivery, got %d", len(store.captured))
+			}
+
+			got := store.captured[0]
+			if got.NotificationID != notif.ID || got.TenantID != notif.TenantID {
+				t.Errorf("captured delivery does not reference the notification")
+			}
+			if got.Recipient != tt.recipient {
+				t.Errorf("expected recipient %q, got %q", tt.recipient, got.Recipient)
+			}
+		})
+	}
+}
+
+func TestCaptureSender_StoreError(t *testing.T) {
+	sender := NewCaptureSender(&mockCaptureStore{shouldFail: true}, zap.NewNop())
+
+	if err := sender.Send(context.Background(), makeTestNotification("email")); err == nil {
+		t.Fatal("expected error when store fails")
+	}
+}
+
+func TestCaptureSender_SupportsChannel(t *testing.T) {
+	sender := NewCaptureSender(&mockCaptureStore{}, zap.NewNop())
+
+	for _, ch := range []string{"email", "sms", "webhook"} {
+		if !sender.SupportsChannel(ch) {
+			t.Errorf("expected %s to be supported", ch)
+		}
+	}
+	if sender.SupportsChannel("telegram") {
+		t.Error("expected telegram to be unsupported")
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// ErrInjectedFault is returned by a ChaosSender for a send it failed on
+// purpose. It is classified as a provider outage, so it retries and
+// dead-letters like one.
+var ErrInjectedFault = errors.New("chaos: injected send failure")
+
+// ChaosConfig configures a ChaosSender. Percentages are 0-100.
+type ChaosConfig struct {
+	ErrorPercent   float64       // share of sends failed with ErrInjectedFault
+	Latency        time.Duration // delay added to a delayed send
+	LatencyPercent float64       // share of sends delayed by Latency
+}
+
+// ChaosSender wraps a provider Sender and injects failures and latency,
+// for exercising retries, circuit breakers and the DLQ in staging. Wrap the
+// provider itself, inside MetricsSender and the breaker, so injected
+// faults look exactly like a misbehaving provider.
+type ChaosSender struct {
+	inner Sender
+	cfg   ChaosConfig
+	roll  func() float64 // in [0, 100)
+}
+
+// NewChaosSender wraps inner with fault injection.
+func NewChaosSender(inner Sender, cfg ChaosConfig) *ChaosSender {
+	return &ChaosSender{
+		inner: inner,
+		cfg:   cfg,
+		roll:  func() float64 { return rand.Float64() * 100 },
+	}
+}
+
+// Send delays and fails a sample of sends; the rest reach the inner sender.
+// A delay honours ctx, so a send timeout still cuts it short.
+func (s *ChaosSender) Send(ctx context.Context, notif *db.Notification) error {
+	if s.cfg.Latency > 0 && s.roll() < s.cfg.LatencyPercent {
+		timer := time.NewTimer(s.cfg.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if s.roll() < s.cfg.ErrorPercent {
+		return ErrInjectedFault
+	}
+	return s.inner.Send(ctx, notif)
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *ChaosSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestChaosSender_InjectsFailures(t *testing.T) {
+	inner := &countingSender{channel: "email"}
+	s := NewChaosSender(inner, ChaosConfig{ErrorPercent: 50})
+	n := 0
+	s.roll = func() float64 {
+		n++
+		if n%2 == 0 {
+			return 0
+		}
+		return 99.9
+	}
+
+	failed := 0
+	for i := 0; i < 10; i++ {
+		if err := s.Send(context.Background(), &db.Notification{Channel: "email"}); errors.Is(err, ErrInjectedFault) {
+			failed++
+		}
+	}
+	if failed != 5 || inner.sent != 5 {
+		t.Errorf("expected half the sends failed, got %d failed and %d sent", failed, inner.sent)
+	}
+}
+
+func TestChaosSender_AddsLatency(t *testing.T) {
+	inner := &countingSender{channel: "email"}
+	s := NewChaosSender(inner, ChaosConfig{Latency: time.Hour, LatencyPercent: 100})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Send(ctx, &db.Notification{Channel: "email"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the delay cut short by the deadline, got %v", err)
+	}
+	if inner.sent != 0 {
+		t.Errorf("expected a timed-out send not to reach the provider, got %d", inner.sent)
+	}
+
+	s = NewChaosSender(inner, ChaosConfig{Latency: 5 * time.Millisecond, LatencyPercent: 100})
+	start := time.Now()
+	if err := s.Send(context.Background(), &db.Notification{Channel: "email"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) < 5*time.Millisecond || inner.sent != 1 {
+		t.Errorf("expected a delayed send delivered, took %s, sent %d", time.Since(start), inner.sent)
+	}
+}
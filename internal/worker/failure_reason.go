@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/lalithlochan/nimbus/internal/circuitbreaker"
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// payloadError marks a send that failed because of the notification's
+// payload, so retrying it unchanged can't succeed.
+type payloadError struct{ err error }
+
+func (e *payloadError) Error() string { return e.err.Error() }
+func (e *payloadError) Unwrap() error { return e.err }
+
+// payloadErrorf is fmt.Errorf for payload problems.
+func payloadErrorf(format string, args ...any) error {
+	return &payloadError{err: fmt.Errorf(format, args...)}
+}
+
+// webhookStatusError is a webhook response whose status wasn't accepted.
+type webhookStatusError struct {
+	statusCode int
+	err        error
+}
+
+func (e *webhookStatusError) Error() string { return e.err.Error() }
+func (e *webhookStatusError) Unwrap() error { return e.err }
+
+// slackAPIError is an error code returned by Slack's chat.postMessage, such
+// as channel_not_found. Codes in slackPermanentErrors wrap
+// sender.ErrPermanent.
+type slackAPIError struct {
+	code string
+	err  error
+}
+
+func (e *slackAPIError) Error() string { return e.err.Error() }
+func (e *slackAPIError) Unwrap() error { return e.err }
+
+// recipientErrorCodes are AWS error codes meaning the address itself was
+// rejected: SES refuses the message, or SNS can't parse the phone number.
+var recipientErrorCodes = map[string]bool{
+	"MessageRejected":       true,
+	"InvalidParameter":      true,
+	"InvalidParameterValue": true,
+}
+
+// failureReason classifies a send error into one of the db.DLQReason codes.
+// Errors are matched by type rather than message, so wrapping senders don't
+// change the result.
+func failureReason(err error) string {
+	var (
+		payloadErr *payloadError
+		statusErr  *webhookStatusError
+		slackErr   *slackAPIError
+		dnsErr     *net.DNSError
+		netErr     net.Error
+		apiErr     smithy.APIError
+	)
+	switch {
+	case err == nil:
+		return db.DLQReasonUnknown
+	case errors.Is(err, ErrRecipientInvalid):
+		return db.DLQReasonInvalidRecipient
+	case errors.As(err, &payloadErr):
+		return db.DLQReasonPayloadError
+	case errors.Is(err, circuitbreaker.ErrCircuitOpen), errors.Is(err, ErrInjectedFault):
+		return db.DLQReasonProviderOutage
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return db.DLQReasonTimeout
+	case errors.As(err, &statusErr):
+		return webhookStatusReason(statusErr.statusCode)
+	case errors.As(err, &slackErr):
+		return slackErrorReason(slackErr.code)
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return db.DLQReasonInvalidRecipient
+	case errors.As(err, &netErr):
+		return db.DLQReasonProviderOutage
+	case errors.As(err, &apiErr):
+		return awsErrorReason(apiErr)
+	}
+	return db.DLQReasonUnknown
+}
+
+// webhookStatusReason classifies a rejected webhook response by status.
+func webhookStatusReason(status int) string {
+	switch {
+	case status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return db.DLQReasonTimeout
+	case status == http.StatusTooManyRequests, status >= 500:
+		return db.DLQReasonProviderOutage
+	case status == http.StatusNotFound, status == http.StatusGone:
+		return db.DLQReasonInvalidRecipient
+	case status >= 400:
+		return db.DLQReasonPayloadError
+	}
+	// A 2xx or 3xx the payload's expect block didn't allow.
+	return db.DLQReasonUnknown
+}
+
+// slackErrorReason classifies a chat.postMessage error code.
+func slackErrorReason(code string) string {
+	if reason, ok := slackPermanentErrors[code]; ok {
+		return reason
+	}
+	if code == "ratelimited" {
+		return db.DLQReasonProviderOutage
+	}
+	return db.DLQReasonUnknown
+}
+
+// awsErrorReason classifies an SES or SNS API error by its code.
+func awsErrorReason(apiErr smithy.APIError) string {
+	code := apiErr.ErrorCode()
+	switch {
+	case recipientErrorCodes[code]:
+		return db.DLQReasonInvalidRecipient
+	case apiErr.ErrorFault() == smithy.FaultServer, strings.Contains(code, "Throttl"):
+		return db.DLQReasonProviderOutage
+	}
+	return db.DLQReasonUnknown
+}
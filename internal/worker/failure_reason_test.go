@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/lalithlochan/nimbus/internal/circuitbreaker"
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"missing field", payloadErrorf("email payload missing 'to' field"), db.DLQReasonPayloadError},
+		{"wrapped payload error", fmt.Errorf("send: %w", payloadErrorf("invalid SMS payload")), db.DLQReasonPayloadError},
+		{"open circuit", fmt.Errorf("%w: ses-email sender unavailable", circuitbreaker.ErrCircuitOpen), db.DLQReasonProviderOutage},
+		{"injected fault", ErrInjectedFault, db.DLQReasonProviderOutage},
+		{"deadline", fmt.Errorf("ses send failed: %w", context.DeadlineExceeded), db.DLQReasonTimeout},
+		{"net timeout", &net.OpError{Op: "dial", Err: timeoutErr{}}, db.DLQReasonTimeout},
+		{"unknown host", &net.DNSError{Name: "hooks.example.invalid", IsNotFound: true}, db.DLQReasonInvalidRecipient},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, db.DLQReasonProviderOutage},
+		{"webhook 404", &webhookStatusError{404, errors.New("webhook returned non-2xx status: 404")}, db.DLQReasonInvalidRecipient},
+		{"webhook 422", &webhookStatusError{422, errors.New("webhook returned non-2xx status: 422")}, db.DLQReasonPayloadError},
+		{"webhook 429", &webhookStatusError{429, errors.New("webhook returned non-2xx status: 429")}, db.DLQReasonProviderOutage},
+		{"webhook 503", &webhookStatusError{503, errors.New("webhook returned non-2xx status: 503")}, db.DLQReasonProviderOutage},
+		{"webhook 504", &webhookStatusError{504, errors.New("webhook returned non-2xx status: 504")}, db.DLQReasonTimeout},
+		{"slack channel not found", &slackAPIError{"channel_not_found", errors.New("slack chat.postMessage to #gone failed: channel_not_found")}, db.DLQReasonInvalidRecipient},
+		{"slack invalid blocks", &slackAPIError{"invalid_blocks", errors.New("slack chat.postMessage to #alerts failed: invalid_blocks")}, db.DLQReasonPayloadError},
+		{"slack rate limited", &slackAPIError{"ratelimited", errors.New("slack chat.postMessage to #alerts failed: ratelimited")}, db.DLQReasonProviderOutage},
+		{"ses rejected", fmt.Errorf("ses send failed: %w", &smithy.GenericAPIError{Code: "MessageRejected"}), db.DLQReasonInvalidRecipient},
+		{"sns bad number", fmt.Errorf("sns publish failed: %w", &smithy.GenericAPIError{Code: "InvalidParameter"}), db.DLQReasonInvalidRecipient},
+		{"throttled", &smithy.GenericAPIError{Code: "Throttling"}, db.DLQReasonProviderOutage},
+		{"server fault", &smithy.GenericAPIError{Code: "InternalFailure", Fault: smithy.FaultServer}, db.DLQReasonProviderOutage},
+		{"invalidated recipient", fmt.Errorf("a@example.com: %w", ErrRecipientInvalid), db.DLQReasonInvalidRecipient},
+		{"worker crash", errors.New("stuck in processing for over 5m0s; worker presumed crashed"), db.DLQReasonUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureReason(tt.err); got != tt.want {
+				t.Errorf("failureReason(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
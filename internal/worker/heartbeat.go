@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Heartbeat is a snapshot of the poll loop's liveness. A worker whose
+// process is alive but whose loop is wedged (a hung send, a deadlock) stops
+// advancing LastPoll and LastProgress, which is what /readyz and the
+// nimbus_worker_last_poll_timestamp_seconds gauge watch for.
+type Heartbeat struct {
+	Instance      string    `json:"instance"`
+	StartedAt     time.Time `json:"started_at"`
+	LastPoll      time.Time `json:"last_poll"`
+	LastProgress  time.Time `json:"last_progress"`
+	LastBatchSize int       `json:"last_batch_size"`
+	LastBatchSecs float64   `json:"last_batch_seconds"`
+	Sent          uint64    `json:"sent"`
+	Failed        uint64    `json:"failed"`
+	Paused        bool      `json:"paused"`
+}
+
+// Stale reports whether the loop has shown no sign of life for longer than
+// maxAge. Progress inside a long batch counts, so a slow batch of webhook
+// sends doesn't look like a wedged loop.
+func (h Heartbeat) Stale(now time.Time, maxAge time.Duration) bool {
+	last := h.LastPoll
+	if h.LastProgress.After(last) {
+		last = h.LastProgress
+	}
+	if last.IsZero() {
+		last = h.StartedAt
+	}
+	return now.Sub(last) > maxAge
+}
+
+// Heartbeat returns the current heartbeat.
+func (w *Worker) Heartbeat() Heartbeat {
+	w.hbMu.Lock()
+	defer w.hbMu.Unlock()
+	return w.hb
+}
+
+// StaleAfter is the heartbeat age past which the worker counts as wedged.
+func (w *Worker) StaleAfter() time.Duration {
+	return w.config.HeartbeatStaleAfter
+}
+
+func (w *Worker) markPoll(now time.Time, paused bool) {
+	w.hbMu.Lock()
+	w.hb.LastPoll = now
+	w.hb.Paused = paused
+	w.hbMu.Unlock()
+	metrics.SetWorkerLastPoll(now)
+}
+
+func (w *Worker) markProgress(sent bool) {
+	w.hbMu.Lock()
+	w.hb.LastProgress = time.Now()
+	if sent {
+		w.hb.Sent++
+	} else {
+		w.hb.Failed++
+	}
+	w.hbMu.Unlock()
+}
+
+func (w *Worker) markBatch(size int, took time.Duration) {
+	w.hbMu.Lock()
+	w.hb.LastBatchSize = size
+	w.hb.LastBatchSecs = took.Seconds()
+	w.hbMu.Unlock()
+}
+
+// publishHeartbeat hands the heartbeat to Config.PublishHeartbeat, if set.
+// Publishing is best-effort: a Redis blip must not stall the loop.
+func (w *Worker) publishHeartbeat(ctx context.Context) {
+	if w.config.PublishHeartbeat == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusWriteTimeout)
+	defer cancel()
+	if err := w.config.PublishHeartbeat(ctx, w.Heartbeat()); err != nil {
+		w.logger.Warn("failed to publish worker heartbeat", zap.Error(err))
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestHeartbeat_Stale(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		hb       Heartbeat
+		expected bool
+	}{
+		{"recent poll", Heartbeat{LastPoll: now.Add(-10 * time.Second)}, false},
+		{"old poll", Heartbeat{LastPoll: now.Add(-5 * time.Minute)}, true},
+		{"old poll but recent progress", Heartbeat{LastPoll: now.Add(-5 * time.Minute), LastProgress: now.Add(-time.Second)}, false},
+		{"never polled, just started", Heartbeat{StartedAt: now.Add(-time.Second)}, false},
+		{"never polled, started long ago", Heartbeat{StartedAt: now.Add(-time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hb.Stale(now, time.Minute); got != tt.expected {
+				t.Errorf("expected stale=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWorker_HeartbeatTracksBatches(t *testing.T) {
+	repo := &MockRepository{notifications: []*db.Notification{{ID: uuid.New()}, {ID: uuid.New()}}}
+	var published []Heartbeat
+	w := New(repo, &MockSender{}, Config{
+		Instance: "test-1",
+		PublishHeartbeat: func(ctx context.Context, hb Heartbeat) error {
+			published = append(published, hb)
+			return errors.New("redis down")
+		},
+	}, zap.NewNop())
+
+	w.markPoll(time.Now(), false)
+	w.processBatch(context.Background())
+	w.publishHeartbeat(context.Background())
+
+	hb := w.Heartbeat()
+	if hb.Instance != "test-1" || hb.LastBatchSize != 2 || hb.Sent != 2 || hb.Failed != 0 {
+		t.Errorf("unexpected heartbeat: %+v", hb)
+	}
+	if hb.LastPoll.IsZero() || hb.LastProgress.IsZero() {
+		t.Errorf("expected poll and progress times, got %+v", hb)
+	}
+	if len(published) != 1 {
+		t.Errorf("expected one publish attempt despite the error, got %d", len(published))
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+)
+
+// Send outcomes recorded by MetricsSender.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomeTimeout = "timeout"
+)
+
+// MetricsSender wraps a provider Sender and records each Send's duration in
+// nimbus_sender_duration_seconds, labelled by provider, channel and outcome.
+// Wrap the provider itself, inside the circuit breaker, so fail-fast
+// rejections don't pull the latency distribution down.
+type MetricsSender struct {
+	inner    Sender
+	provider string
+	record   func(provider, channel, outcome string, d time.Duration)
+}
+
+// NewMetricsSender wraps a sender with latency metrics under the given
+// provider name, e.g. "ses", "sns" or "webhook".
+func NewMetricsSender(inner Sender, provider string) *MetricsSender {
+	return &MetricsSender{
+		inner:    inner,
+		provider: provider,
+		record:   metrics.RecordSenderDuration,
+	}
+}
+
+// Send times the inner sender.
+func (s *MetricsSender) Send(ctx context.Context, notif *db.Notification) error {
+	start := time.Now()
+	err := s.inner.Send(ctx, notif)
+	s.record(s.provider, notif.Channel, sendOutcome(err), time.Since(start))
+	return err
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *MetricsSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
+
+func sendOutcome(err error) string {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return OutcomeTimeout
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type stubSender struct {
+	err error
+}
+
+func (s *stubSender) Send(ctx context.Context, notif *db.Notification) error { return s.err }
+func (s *stubSender) SupportsChannel(channel string) bool                    { return channel == "email" }
+
+func TestMetricsSender_RecordsOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"success", nil, OutcomeSuccess},
+		{"provider error", errors.New("throttled"), OutcomeError},
+		{"deadline", fmt.Errorf("send: %w", context.DeadlineExceeded), OutcomeTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotProvider, gotChannel, gotOutcome string
+			s := NewMetricsSender(&stubSender{err: tt.err}, "ses")
+			s.record = func(provider, channel, outcome string, d time.Duration) {
+				gotProvider, gotChannel, gotOutcome = provider, channel, outcome
+			}
+
+			err := s.Send(context.Background(), &db.Notification{Channel: "email"})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected the inner error to pass through, got %v", err)
+			}
+			if gotProvider != "ses" || gotChannel != "email" || gotOutcome != tt.expected {
+				t.Errorf("recorded (%s, %s, %s), want (ses, email, %s)", gotProvider, gotChannel, gotOutcome, tt.expected)
+			}
+		})
+	}
+
+	if !NewMetricsSender(&stubSender{}, "ses").SupportsChannel("email") {
+		t.Error("expected SupportsChannel to delegate")
+	}
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/pkg/sender"
+	senderv1 "github.com/lalithlochan/nimbus/proto/sender/v1"
+)
+
+// describeTimeout bounds the Describe call made when a plugin is dialled.
+const describeTimeout = 10 * time.Second
+
+// PluginSender forwards sends to an external service implementing the
+// sender.v1.SenderPlugin gRPC contract, so a team can add a channel (an
+// internal chat system, say) without forking nimbus. The plugin's name is
+// used as the provider.
+type PluginSender struct {
+	name     string
+	client   senderv1.SenderPluginClient
+	channels []string
+	conn     *grpc.ClientConn
+}
+
+// DialPluginSender connects to the plugin at addr and asks it which
+// channels it delivers.
+func DialPluginSender(ctx context.Context, name, addr string, creds credentials.TransportCredentials, logger *zap.Logger) (*PluginSender, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("dial plugin %s at %s: %w", name, addr, err)
+	}
+	s, err := NewPluginSender(ctx, name, senderv1.NewSenderPluginClient(conn))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s.conn = conn
+	logger.Info("sender plugin connected",
+		zap.String("plugin", name),
+		zap.String("addr", addr),
+		zap.Strings("channels", s.channels),
+	)
+	return s, nil
+}
+
+// NewPluginSender creates a sender over an existing client, calling
+// Describe for its channels.
+func NewPluginSender(ctx context.Context, name string, client senderv1.SenderPluginClient) (*PluginSender, error) {
+	ctx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+	resp, err := client.Describe(ctx, &senderv1.DescribeRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("describe plugin %s: %w", name, err)
+	}
+	if len(resp.GetChannels()) == 0 {
+		return nil, fmt.Errorf("plugin %s delivers no channels", name)
+	}
+	return &PluginSender{name: name, client: client, channels: resp.GetChannels()}, nil
+}
+
+// Channels returns the channels the plugin delivers.
+func (s *PluginSender) Channels() []string {
+	return s.channels
+}
+
+// Send forwards notif to the plugin. Statuses meaning the request can
+// never succeed wrap sender.ErrPermanent; a deadline wraps
+// context.DeadlineExceeded so it is classified as a timeout.
+func (s *PluginSender) Send(ctx context.Context, notif *db.Notification) error {
+	resp, err := s.client.Send(ctx, &senderv1.SendRequest{
+		NotificationId: notif.ID.String(),
+		TenantId:       notif.TenantID.String(),
+		UserId:         notif.UserID.String(),
+		Channel:        notif.Channel,
+		Payload:        notif.Payload,
+		Metadata:       notif.Metadata,
+		Tags:           notif.Tags,
+		Attempt:        int32(notif.Attempt),
+		CorrelationId:  notif.CorrelationID,
+	})
+	if err != nil {
+		return s.sendError(err)
+	}
+	notif.Provider = s.name
+	notif.ProviderMessageID = resp.GetProviderMessageId()
+	return nil
+}
+
+func (s *PluginSender) sendError(err error) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return fmt.Errorf("plugin %s: %w: %s", s.name, sender.ErrPermanent, st.Message())
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("plugin %s: %w: %s", s.name, context.DeadlineExceeded, st.Message())
+	case codes.Canceled:
+		return fmt.Errorf("plugin %s: %w: %s", s.name, context.Canceled, st.Message())
+	default:
+		return fmt.Errorf("plugin %s: %s: %s", s.name, st.Code(), st.Message())
+	}
+}
+
+// SupportsChannel reports whether the plugin described channel.
+func (s *PluginSender) SupportsChannel(channel string) bool {
+	return slices.Contains(s.channels, channel)
+}
+
+// Close closes the connection opened by DialPluginSender.
+func (s *PluginSender) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/pkg/sender"
+	"github.com/lalithlochan/nimbus/pkg/sender/sendertest"
+	senderv1 "github.com/lalithlochan/nimbus/proto/sender/v1"
+)
+
+// chatPlugin is a plugin delivering the "chat" channel. A payload without
+// a "room" is refused as invalid.
+type chatPlugin struct {
+	senderv1.UnimplementedSenderPluginServer
+}
+
+func (chatPlugin) Describe(ctx context.Context, req *senderv1.DescribeRequest) (*senderv1.DescribeResponse, error) {
+	return &senderv1.DescribeResponse{Channels: []string{"chat"}}, nil
+}
+
+func (chatPlugin) Send(ctx context.Context, req *senderv1.SendRequest) (*senderv1.SendResponse, error) {
+	var payload struct{ Room string }
+	if json.Unmarshal(req.GetPayload(), &payload) != nil || payload.Room == "" {
+		return nil, status.Error(codes.InvalidArgument, "room is required")
+	}
+	if payload.Room == "down" {
+		return nil, status.Error(codes.Unavailable, "chat is down")
+	}
+	return &senderv1.SendResponse{ProviderMessageId: "msg-" + req.GetNotificationId()}, nil
+}
+
+func newTestPluginSender(t *testing.T) *PluginSender {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	senderv1.RegisterSenderPluginServer(srv, chatPlugin{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	s, err := NewPluginSender(context.Background(), "chat", senderv1.NewSenderPluginClient(conn))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestPluginSender_Conformance(t *testing.T) {
+	sendertest.Run(t, sendertest.Suite{
+		New:     func(t *testing.T) sender.Sender { return newTestPluginSender(t) },
+		Channel: "chat",
+		Valid: func(t *testing.T) *sender.Notification {
+			return &sender.Notification{Channel: "chat", Payload: json.RawMessage(`{"room":"ops"}`)}
+		},
+		Invalid: func(t *testing.T) *sender.Notification {
+			return &sender.Notification{Channel: "chat", Payload: json.RawMessage(`{}`)}
+		},
+	})
+}
+
+func TestPluginSender_Send(t *testing.T) {
+	s := newTestPluginSender(t)
+
+	notif := &db.Notification{ID: uuid.New(), Channel: "chat", Payload: json.RawMessage(`{"room":"ops"}`)}
+	if err := s.Send(context.Background(), notif); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notif.Provider != "chat" || notif.ProviderMessageID != "msg-"+notif.ID.String() {
+		t.Errorf("expected the plugin recorded as provider, got %q %q", notif.Provider, notif.ProviderMessageID)
+	}
+
+	err := s.Send(context.Background(), &db.Notification{Channel: "chat", Payload: json.RawMessage(`{}`)})
+	if !errors.Is(err, sender.ErrPermanent) {
+		t.Errorf("expected INVALID_ARGUMENT to be permanent, got %v", err)
+	}
+	err = s.Send(context.Background(), &db.Notification{Channel: "chat", Payload: json.RawMessage(`{"room":"down"}`)})
+	if err == nil || errors.Is(err, sender.ErrPermanent) {
+		t.Errorf("expected UNAVAILABLE to be retryable, got %v", err)
+	}
+
+	if !s.SupportsChannel("chat") || s.SupportsChannel("email") {
+		t.Errorf("expected only the described channel, got %v", s.Channels())
+	}
+}
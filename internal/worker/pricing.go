@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"encoding/json"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/sms"
+)
+
+// Pricing is what each provider charges us, used to estimate the cost of
+// every delivery for per-tenant chargeback. The zero value prices
+// everything at 0.
+type Pricing struct {
+	SESPerEmail    float64 // one email, regardless of recipients or size
+	SNSPerSegment  float64 // one SMS segment
+	WebhookPerCall float64 // one webhook delivery, e.g. egress
+}
+
+// Cost estimates what sending notif cost, from the provider the sender
+// recorded on it. Call it after a successful send: SMS segments are
+// counted on the message as sent, after link shortening.
+func (p Pricing) Cost(notif *db.Notification) float64 {
+	switch notif.Provider {
+	case ProviderSES:
+		return p.SESPerEmail
+	case ProviderSNS:
+		var payload SMSPayload
+		if err := json.Unmarshal(notif.Payload, &payload); err != nil {
+			return 0
+		}
+		return float64(sms.Count(payload.Message).Segments) * p.SNSPerSegment
+	case ProviderWebhook:
+		return p.WebhookPerCall
+	default:
+		// Sandbox captures and unknown providers cost nothing.
+		return 0
+	}
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	pricing := Pricing{SESPerEmail: 0.0001, SNSPerSegment: 0.01, WebhookPerCall: 0.00002}
+	sms := func(message string) json.RawMessage {
+		b, _ := json.Marshal(SMSPayload{PhoneNumber: "+15555550100", Message: message})
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		notif *db.Notification
+		want  float64
+	}{
+		{"ses email", &db.Notification{Provider: ProviderSES}, 0.0001},
+		{"one sms segment", &db.Notification{Provider: ProviderSNS, Payload: sms("hello")}, 0.01},
+		{"three sms segments", &db.Notification{Provider: ProviderSNS, Payload: sms(strings.Repeat("a", 400))}, 0.03},
+		{"webhook", &db.Notification{Provider: ProviderWebhook}, 0.00002},
+		{"sandbox capture", &db.Notification{Provider: "capture"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pricing.Cost(tt.notif); got < tt.want-1e-12 || got > tt.want+1e-12 {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/sqs"
+)
+
+// queueRetryDelay caps how long a consumer waits after a failed batch or
+// while paused, so a long poll interval, such as SQS mode's sweep, doesn't
+// stall it.
+const queueRetryDelay = 5 * time.Second
+
+// QueueSource hands out batches of queue messages announcing notifications,
+// e.g. a per-channel SQS queue subscribed to the SNS fan-out topic, or the
+// single SQS_QUEUE_URL queue. *sqs.Consumer implements it.
+type QueueSource interface {
+	ProcessBatch(ctx context.Context, handle func(context.Context, *sqs.Message) error) (sqs.BatchResult, error)
+}
+
+// ConsumeQueue runs a dedicated worker for channel fed by source, until ctx
+// is cancelled or Shutdown is called. An empty channel consumes a queue
+// carrying every channel, claiming each message's row on its own channel.
+// It complements the poll loop rather than replacing it: the database row
+// stays the source of truth, each message only prompts an immediate claim
+// of its row, and anything the queue loses, or a retry it no longer
+// announces, is still picked up by the next poll. Call it before Shutdown.
+func (w *Worker) ConsumeQueue(ctx context.Context, channel string, source QueueSource) {
+	w.consumers.Add(1)
+	defer w.consumers.Done()
+
+	logger := w.logger.With(zap.String("channel", queueChannelLabel(channel)))
+	logger.Info("channel queue consumer started")
+
+	for !w.draining() && ctx.Err() == nil {
+		if w.config.Paused != nil && w.config.Paused() {
+			w.wait(ctx, min(w.config.PollInterval, queueRetryDelay))
+			continue
+		}
+		result, err := source.ProcessBatch(ctx, func(ctx context.Context, msg *sqs.Message) error {
+			return w.processQueued(ctx, channel, msg)
+		})
+		if err != nil && ctx.Err() == nil {
+			logger.Error("failed to process channel queue batch", zap.Error(err))
+			w.wait(ctx, min(w.config.PollInterval, queueRetryDelay))
+			continue
+		}
+		if result.Failed > 0 {
+			logger.Warn("channel queue batch had failures",
+				zap.Int("received", result.Received),
+				zap.Int("failed", result.Failed),
+			)
+		}
+	}
+	logger.Info("channel queue consumer stopped")
+}
+
+// processQueued claims and sends the notification msg announces, on
+// msg's own channel when channel is empty. Only a failure to claim is
+// returned, so the message is retried; send failures are already
+// rescheduled on the row by processNotification, and a row that isn't
+// claimable was sent, claimed or held elsewhere, or isn't due yet.
+func (w *Worker) processQueued(ctx context.Context, channel string, msg *sqs.Message) error {
+	if channel == "" {
+		channel = msg.Channel
+	}
+	id, err := uuid.Parse(msg.NotificationID)
+	if err != nil {
+		// Retrying can't fix the ID; drop the message.
+		w.logger.Warn("dropping queue message with invalid notification id",
+			zap.String("notification_id", msg.NotificationID),
+		)
+		return nil
+	}
+
+	notif, err := w.repo.ClaimNotification(ctx, id, channel)
+	if err != nil {
+		return err
+	}
+	if notif == nil {
+		w.logger.Debug("queued notification not claimable, skipping",
+			zap.String("notification_id", msg.NotificationID),
+		)
+		return nil
+	}
+	w.processNotificationSafely(ctx, notif)
+	return nil
+}
+
+// queueChannelLabel names the channel of a queue in logs.
+func queueChannelLabel(channel string) string {
+	if channel == "" {
+		return "all"
+	}
+	return channel
+}
+
+// wait sleeps for d, returning early on Shutdown or when ctx is done.
+func (w *Worker) wait(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-w.stop:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/sqs"
+)
+
+func TestProcessQueued(t *testing.T) {
+	pending := &db.Notification{ID: uuid.New(), Channel: db.ChannelEmail, Status: db.StatusPending}
+	repo := &MockRepository{notifications: []*db.Notification{pending}}
+	sender := &MockSender{}
+	w := New(repo, sender, Config{MaxRetries: 3}, zap.NewNop())
+	ctx := context.Background()
+
+	if err := w.processQueued(ctx, db.ChannelEmail, &sqs.Message{NotificationID: pending.ID.String()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sender.sendCalls != 1 || len(repo.updateCalls) != 1 || repo.updateCalls[0].status != db.StatusSent {
+		t.Fatalf("expected the claimed notification sent once, got %d sends, %+v", sender.sendCalls, repo.updateCalls)
+	}
+
+	// Redelivery of the same message finds nothing to claim.
+	if err := w.processQueued(ctx, db.ChannelEmail, &sqs.Message{NotificationID: pending.ID.String()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sender.sendCalls != 1 {
+		t.Errorf("expected no second send, got %d sends", sender.sendCalls)
+	}
+
+	if err := w.processQueued(ctx, db.ChannelEmail, &sqs.Message{NotificationID: "not-a-uuid"}); err != nil {
+		t.Errorf("expected an unparseable id to be dropped, got %v", err)
+	}
+
+	repo.shouldFail = true
+	if err := w.processQueued(ctx, db.ChannelEmail, &sqs.Message{NotificationID: uuid.NewString()}); err == nil {
+		t.Error("expected a claim failure to be returned for retry")
+	}
+}
+
+func TestProcessQueued_AnyChannel(t *testing.T) {
+	notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelWebhook, Status: db.StatusPending}
+	repo := &MockRepository{notifications: []*db.Notification{notif}}
+	sender := &MockSender{}
+	w := New(repo, sender, Config{MaxRetries: 3}, zap.NewNop())
+
+	// A consumer of the single queue claims the row on the message's channel.
+	msg := &sqs.Message{NotificationID: notif.ID.String(), Channel: db.ChannelWebhook}
+	if err := w.processQueued(context.Background(), "", msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sender.sendCalls != 1 {
+		t.Errorf("expected the webhook sent, got %d sends", sender.sendCalls)
+	}
+}
+
+type fakeQueue struct {
+	mu      sync.Mutex
+	batches [][]*sqs.Message
+	errs    []error
+}
+
+func (q *fakeQueue) ProcessBatch(ctx context.Context, handle func(context.Context, *sqs.Message) error) (sqs.BatchResult, error) {
+	q.mu.Lock()
+	var batch []*sqs.Message
+	if len(q.batches) > 0 {
+		batch, q.batches = q.batches[0], q.batches[1:]
+	}
+	q.mu.Unlock()
+	if batch == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	result := sqs.BatchResult{Received: len(batch)}
+	for _, m := range batch {
+		err := handle(ctx, m)
+		q.mu.Lock()
+		q.errs = append(q.errs, err)
+		q.mu.Unlock()
+		if err != nil {
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+	}
+	return result, nil
+}
+
+func TestConsumeQueue_SendsUntilShutdown(t *testing.T) {
+	notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelSMS, Status: db.StatusPending}
+	repo := &MockRepository{notifications: []*db.Notification{notif}}
+	sender := &MockSender{}
+	w := New(repo, sender, Config{MaxRetries: 3, PollInterval: time.Millisecond}, zap.NewNop())
+	queue := &fakeQueue{batches: [][]*sqs.Message{{{NotificationID: notif.ID.String()}}}}
+
+	stopped := make(chan struct{})
+	go func() {
+		w.ConsumeQueue(context.Background(), db.ChannelSMS, queue)
+		close(stopped)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		queue.mu.Lock()
+		n := len(queue.errs)
+		queue.mu.Unlock()
+		if n == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("expected Shutdown to wait for the consumer to stop")
+	}
+	if sender.sendCalls != 1 {
+		t.Errorf("expected 1 send, got %d", sender.sendCalls)
+	}
+}
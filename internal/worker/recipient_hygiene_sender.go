@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// ErrRecipientInvalid is returned for a send to a recipient a provider has
+// already reported as undeliverable. The worker dead-letters it at once
+// instead of retrying.
+var ErrRecipientInvalid = errors.New("recipient was invalidated by an earlier hard bounce or rejection")
+
+// InvalidRecipientStore is the tenant registry of undeliverable recipients.
+type InvalidRecipientStore interface {
+	IsRecipientInvalid(ctx context.Context, tenantID uuid.UUID, channel, recipient string) (bool, error)
+	InvalidateRecipient(ctx context.Context, r *db.InvalidRecipient) error
+}
+
+// RecipientHygieneSender wraps a Sender and skips email and SMS sends to
+// recipients in the tenant's invalid recipient registry. When the provider
+// rejects a recipient outright, it adds the recipient to the registry so
+// later sends are skipped too. Hard bounces are added as they arrive, by
+// the delivery events handler.
+//
+// Webhooks aren't covered: a 404 from a tenant's endpoint is more often a
+// bad deploy than a URL that will never work again.
+type RecipientHygieneSender struct {
+	inner  Sender
+	store  InvalidRecipientStore
+	logger *zap.Logger
+}
+
+// NewRecipientHygieneSender wraps a sender with the invalid recipient
+// registry.
+func NewRecipientHygieneSender(inner Sender, store InvalidRecipientStore, logger *zap.Logger) *RecipientHygieneSender {
+	return &RecipientHygieneSender{
+		inner:  inner,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Send checks the registry, then delegates to the inner sender.
+func (s *RecipientHygieneSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelEmail && notif.Channel != db.ChannelSMS {
+		return s.inner.Send(ctx, notif)
+	}
+	recipient := db.NormalizeRecipient(notif.Channel, payloadRecipient(notif))
+	if recipient == "" {
+		return s.inner.Send(ctx, notif)
+	}
+
+	invalid, err := s.store.IsRecipientInvalid(ctx, notif.TenantID, notif.Channel, recipient)
+	if err != nil {
+		// The registry only saves provider calls; an outage of it shouldn't
+		// stop delivery.
+		observ.Logger(ctx, s.logger).Warn("failed to check invalid recipients, sending anyway",
+			zap.Error(err),
+			zap.String("channel", notif.Channel),
+		)
+	}
+	if invalid {
+		metrics.RecordRecipientSuppressed(notif.Channel)
+		return fmt.Errorf("%s: %w", recipient, ErrRecipientInvalid)
+	}
+
+	sendErr := s.inner.Send(ctx, notif)
+	if sendErr == nil || failureReason(sendErr) != db.DLQReasonInvalidRecipient {
+		return sendErr
+	}
+
+	id := notif.ID
+	err = s.store.InvalidateRecipient(ctx, &db.InvalidRecipient{
+		TenantID:       notif.TenantID,
+		Channel:        notif.Channel,
+		Recipient:      recipient,
+		Reason:         db.InvalidRecipientRejected,
+		Detail:         sendErr.Error(),
+		NotificationID: &id,
+	})
+	if err != nil {
+		observ.Logger(ctx, s.logger).Warn("failed to invalidate rejected recipient",
+			zap.Error(err),
+			zap.String("channel", notif.Channel),
+		)
+	}
+	return sendErr
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *RecipientHygieneSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
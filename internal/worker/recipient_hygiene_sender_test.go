@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type fakeInvalidRecipientStore struct {
+	invalid  map[string]bool
+	checkErr error
+	added    []*db.InvalidRecipient
+}
+
+func (f *fakeInvalidRecipientStore) IsRecipientInvalid(ctx context.Context, tenantID uuid.UUID, channel, recipient string) (bool, error) {
+	if f.checkErr != nil {
+		return false, f.checkErr
+	}
+	return f.invalid[channel+":"+recipient], nil
+}
+
+func (f *fakeInvalidRecipientStore) InvalidateRecipient(ctx context.Context, r *db.InvalidRecipient) error {
+	f.added = append(f.added, r)
+	return nil
+}
+
+func hygieneNotification(channel, payload string) *db.Notification {
+	return &db.Notification{
+		ID:       uuid.New(),
+		TenantID: uuid.New(),
+		Channel:  channel,
+		Payload:  json.RawMessage(payload),
+	}
+}
+
+func TestRecipientHygieneSender_SkipsInvalidRecipient(t *testing.T) {
+	store := &fakeInvalidRecipientStore{invalid: map[string]bool{"email:bounced@example.com": true}}
+	inner := &countingSender{channel: db.ChannelEmail}
+	s := NewRecipientHygieneSender(inner, store, zap.NewNop())
+
+	err := s.Send(context.Background(), hygieneNotification(db.ChannelEmail, `{"to":"Bounced@Example.com","subject":"Hi","body":"Hi"}`))
+	if !errors.Is(err, ErrRecipientInvalid) {
+		t.Fatalf("expected ErrRecipientInvalid, got %v", err)
+	}
+	if inner.sent != 0 {
+		t.Errorf("expected no provider call, got %d", inner.sent)
+	}
+	if got := failureReason(err); got != db.DLQReasonInvalidRecipient {
+		t.Errorf("expected reason %s, got %s", db.DLQReasonInvalidRecipient, got)
+	}
+}
+
+func TestRecipientHygieneSender_InvalidatesRejectedRecipient(t *testing.T) {
+	store := &fakeInvalidRecipientStore{}
+	rejected := fmt.Errorf("sns publish failed: %w", &smithy.GenericAPIError{Code: "InvalidParameter"})
+	s := NewRecipientHygieneSender(&countingSender{channel: db.ChannelSMS, err: rejected}, store, zap.NewNop())
+	notif := hygieneNotification(db.ChannelSMS, `{"phone_number":"+15550000000","message":"Hi"}`)
+
+	if err := s.Send(context.Background(), notif); !errors.Is(err, rejected) {
+		t.Fatalf("expected the provider error, got %v", err)
+	}
+	if len(store.added) != 1 {
+		t.Fatalf("expected 1 invalidation, got %d", len(store.added))
+	}
+	got := store.added[0]
+	if got.Recipient != "+15550000000" || got.Reason != db.InvalidRecipientRejected || got.TenantID != notif.TenantID {
+		t.Errorf("unexpected invalidation %+v", got)
+	}
+	if got.NotificationID == nil || *got.NotificationID != notif.ID {
+		t.Errorf("expected notification %s, got %v", notif.ID, got.NotificationID)
+	}
+}
+
+func TestRecipientHygieneSender_PassesThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   *fakeInvalidRecipientStore
+		notif   *db.Notification
+		sendErr error
+	}{
+		{
+			name:  "webhooks aren't checked",
+			store: &fakeInvalidRecipientStore{invalid: map[string]bool{"webhook:https://hooks.example.com": true}},
+			notif: hygieneNotification(db.ChannelWebhook, `{"url":"https://hooks.example.com"}`),
+		},
+		{
+			name:  "registry outage sends anyway",
+			store: &fakeInvalidRecipientStore{checkErr: errors.New("connection refused")},
+			notif: hygieneNotification(db.ChannelEmail, `{"to":"a@example.com","subject":"Hi","body":"Hi"}`),
+		},
+		{
+			name:    "other failures don't invalidate",
+			store:   &fakeInvalidRecipientStore{},
+			notif:   hygieneNotification(db.ChannelEmail, `{"to":"a@example.com","subject":"Hi","body":"Hi"}`),
+			sendErr: &smithy.GenericAPIError{Code: "Throttling"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &countingSender{channel: tt.notif.Channel, err: tt.sendErr}
+			s := NewRecipientHygieneSender(inner, tt.store, zap.NewNop())
+
+			if err := s.Send(context.Background(), tt.notif); !errors.Is(err, tt.sendErr) {
+				t.Fatalf("expected %v, got %v", tt.sendErr, err)
+			}
+			if inner.sent != 1 {
+				t.Errorf("expected 1 provider call, got %d", inner.sent)
+			}
+			if len(tt.store.added) != 0 {
+				t.Errorf("expected no invalidation, got %+v", tt.store.added)
+			}
+		})
+	}
+}
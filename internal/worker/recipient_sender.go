@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// RecipientStore looks up tenants' recipient records.
+type RecipientStore interface {
+	GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error)
+}
+
+// RecipientSender wraps a Sender and addresses email and SMS payloads that
+// set "recipient_ref": "user" from the notification user's recipient
+// record: its email becomes "to" and its phone "phone_number". The lookup
+// happens on every attempt, so a retry or DLQ re-send goes to the address
+// the user has now, not the one they had when the notification was created.
+//
+// The reference wins over an address also given in the payload. Payloads
+// without a recipient_ref pass through unchanged.
+type RecipientSender struct {
+	inner  Sender
+	store  RecipientStore
+	logger *zap.Logger
+}
+
+// NewRecipientSender wraps a sender with recipient resolution.
+func NewRecipientSender(inner Sender, store RecipientStore, logger *zap.Logger) *RecipientSender {
+	return &RecipientSender{
+		inner:  inner,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Send resolves the recipient, if referenced, then delegates to the inner
+// sender.
+func (s *RecipientSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelEmail && notif.Channel != db.ChannelSMS {
+		return s.inner.Send(ctx, notif)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(notif.Payload, &fields); err != nil {
+		return s.inner.Send(ctx, notif)
+	}
+	raw, ok := fields["recipient_ref"]
+	if !ok {
+		return s.inner.Send(ctx, notif)
+	}
+	var ref string
+	if err := json.Unmarshal(raw, &ref); err != nil || ref != db.RecipientRefUser {
+		return payloadErrorf("recipient_ref must be %q", db.RecipientRefUser)
+	}
+
+	rc, err := s.store.GetRecipient(ctx, notif.TenantID, notif.UserID)
+	if errors.Is(err, db.ErrNoRecipient) {
+		return payloadErrorf("recipient_ref: user %s has no recipient record", notif.UserID)
+	}
+	if err != nil {
+		return fmt.Errorf("load recipient: %w", err)
+	}
+
+	field, address := "to", rc.Email
+	if notif.Channel == db.ChannelSMS {
+		field, address = "phone_number", rc.Phone
+	}
+	if address == "" {
+		return payloadErrorf("recipient_ref: user %s has no %s on record", notif.UserID, field)
+	}
+
+	fields[field], _ = json.Marshal(address)
+	delete(fields, "recipient_ref")
+	resolved, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("marshal resolved payload: %w", err)
+	}
+	notif.Payload = resolved
+
+	observ.Logger(ctx, s.logger).Debug("resolved recipient reference",
+		zap.String("channel", notif.Channel),
+	)
+
+	return s.inner.Send(ctx, notif)
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *RecipientSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
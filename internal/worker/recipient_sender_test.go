@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockRecipientStore struct {
+	recipients map[uuid.UUID]*db.Recipient
+	err        error
+}
+
+func (m *mockRecipientStore) GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*db.Recipient, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	rc, ok := m.recipients[userID]
+	if !ok || rc.TenantID != tenantID {
+		return nil, db.ErrNoRecipient
+	}
+	return rc, nil
+}
+
+func TestRecipientSender(t *testing.T) {
+	tenantID, userID, phoneless := uuid.New(), uuid.New(), uuid.New()
+	store := &mockRecipientStore{recipients: map[uuid.UUID]*db.Recipient{
+		userID:    {TenantID: tenantID, UserID: userID, Email: "new@example.com", Phone: "+15551234567"},
+		phoneless: {TenantID: tenantID, UserID: phoneless, Email: "a@example.com"},
+	}}
+
+	tests := []struct {
+		name    string
+		channel string
+		userID  uuid.UUID
+		payload string
+		want    map[string]string
+		wantErr string // DLQ reason, if the send should fail
+	}{
+		{
+			name:    "email address filled from the record",
+			channel: db.ChannelEmail,
+			userID:  userID,
+			payload: `{"recipient_ref":"user","subject":"Hi","body":"Hi"}`,
+			want:    map[string]string{"to": "new@example.com", "subject": "Hi", "body": "Hi"},
+		},
+		{
+			name:    "reference wins over a stale address",
+			channel: db.ChannelEmail,
+			userID:  userID,
+			payload: `{"recipient_ref":"user","to":"old@example.com","subject":"Hi","body":"Hi"}`,
+			want:    map[string]string{"to": "new@example.com", "subject": "Hi", "body": "Hi"},
+		},
+		{
+			name:    "phone number filled from the record",
+			channel: db.ChannelSMS,
+			userID:  userID,
+			payload: `{"recipient_ref":"user","message":"Hi"}`,
+			want:    map[string]string{"phone_number": "+15551234567", "message": "Hi"},
+		},
+		{
+			name:    "no reference passes through",
+			channel: db.ChannelEmail,
+			userID:  userID,
+			payload: `{"to":"old@example.com","subject":"Hi","body":"Hi"}`,
+			want:    map[string]string{"to": "old@example.com", "subject": "Hi", "body": "Hi"},
+		},
+		{
+			name:    "unknown reference",
+			channel: db.ChannelEmail,
+			userID:  userID,
+			payload: `{"recipient_ref":"manager","subject":"Hi","body":"Hi"}`,
+			wantErr: db.DLQReasonPayloadError,
+		},
+		{
+			name:    "no record",
+			channel: db.ChannelEmail,
+			userID:  uuid.New(),
+			payload: `{"recipient_ref":"user","subject":"Hi","body":"Hi"}`,
+			wantErr: db.DLQReasonPayloadError,
+		},
+		{
+			name:    "no phone on record",
+			channel: db.ChannelSMS,
+			userID:  phoneless,
+			payload: `{"recipient_ref":"user","message":"Hi"}`,
+			wantErr: db.DLQReasonPayloadError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingSender{}
+			s := NewRecipientSender(inner, store, zap.NewNop())
+			notif := &db.Notification{TenantID: tenantID, UserID: tt.userID, Channel: tt.channel, Payload: json.RawMessage(tt.payload)}
+
+			err := s.Send(context.Background(), notif)
+			if tt.wantErr != "" {
+				if err == nil || failureReason(err) != tt.wantErr {
+					t.Fatalf("expected a %s error, got %v", tt.wantErr, err)
+				}
+				if inner.payload != nil {
+					t.Error("expected the inner sender not to be called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got map[string]string
+			if err := json.Unmarshal(inner.payload, &got); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("expected payload %v, got %v", tt.want, got)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("expected %s=%q, got %q", k, v, got[k])
+				}
+			}
+		})
+	}
+}
+
+func TestRecipientSender_StoreErrorIsRetryable(t *testing.T) {
+	s := NewRecipientSender(&recordingSender{}, &mockRecipientStore{err: errors.New("connection refused")}, zap.NewNop())
+	notif := &db.Notification{TenantID: uuid.New(), UserID: uuid.New(), Channel: db.ChannelEmail,
+		Payload: json.RawMessage(`{"recipient_ref":"user","subject":"Hi","body":"Hi"}`)}
+
+	err := s.Send(context.Background(), notif)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if reason := failureReason(err); reason == db.DLQReasonPayloadError {
+		t.Errorf("a store outage shouldn't be classified as a payload error")
+	}
+}
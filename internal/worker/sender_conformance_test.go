@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/pkg/sender"
+	"github.com/lalithlochan/nimbus/pkg/sender/sendertest"
+)
+
+func TestWebhookSender_Conformance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sendertest.Run(t, sendertest.Suite{
+		New: func(t *testing.T) sender.Sender {
+			return NewWebhookSender(zap.NewNop(), WebhookConfig{DefaultTimeout: 5 * time.Second})
+		},
+		Channel: db.ChannelWebhook,
+		Valid: func(t *testing.T) *sender.Notification {
+			payload, _ := json.Marshal(WebhookPayload{URL: server.URL, Body: json.RawMessage(`{"event":"test"}`)})
+			return &sender.Notification{Channel: db.ChannelWebhook, Payload: payload}
+		},
+		Invalid: func(t *testing.T) *sender.Notification {
+			return &sender.Notification{Channel: db.ChannelWebhook, Payload: json.RawMessage(`{"method":"POST"}`)}
+		},
+	})
+}
+
+func TestSlackSender_Conformance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "C024BE91L", "ts": "1700000000.000100"}`))
+	}))
+	defer server.Close()
+
+	sendertest.Run(t, sendertest.Suite{
+		New: func(t *testing.T) sender.Sender {
+			return NewSlackSender(zap.NewNop(), SlackConfig{BotToken: "xoxb-test", APIURL: server.URL})
+		},
+		Channel: db.ChannelSlack,
+		Valid: func(t *testing.T) *sender.Notification {
+			return &sender.Notification{Channel: db.ChannelSlack, Payload: json.RawMessage(`{"channel":"#alerts","text":"Deploy finished"}`)}
+		},
+		Invalid: func(t *testing.T) *sender.Notification {
+			return &sender.Notification{Channel: db.ChannelSlack, Payload: json.RawMessage(`{"channel":"#alerts"}`)}
+		},
+	})
+}
+
+func TestLogSender_Conformance(t *testing.T) {
+	sendertest.Run(t, sendertest.Suite{
+		New: func(t *testing.T) sender.Sender {
+			return NewLogSender(zap.NewNop())
+		},
+		Channel: db.ChannelEmail,
+		Valid: func(t *testing.T) *sender.Notification {
+			return &sender.Notification{Channel: db.ChannelEmail, Payload: json.RawMessage(`{"to":"user@example.com"}`)}
+		},
+	})
+}
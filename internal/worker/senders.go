@@ -8,26 +8,48 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+	"github.com/lalithlochan/nimbus/pkg/sender"
+)
+
+// Provider names, used as the metrics provider label and stored with each
+// sent notification next to the provider's message ID.
+const (
+	ProviderSES     = "ses"
+	ProviderSNS     = "sns"
+	ProviderWebhook = "webhook"
+	ProviderSlack   = "slack"
+	ProviderCapture = "capture"
+	ProviderLog     = "log"
 )
 
 // Sender is the unified interface for all notification channels
-// Implementations: Email (SES), SMS (SNS), Webhooks
-type Sender interface {
-	Send(ctx context.Context, notif *db.Notification) error
-	SupportsChannel(channel string) bool
-}
+// Implementations: Email (SES), SMS (SNS), Webhooks, Slack, and sender plugins
+//
+// On success a sender sets notif.Provider, and notif.ProviderMessageID when
+// the provider returns one, so the worker can persist them. The contract is
+// spelled out, and checked by sendertest, in pkg/sender.
+type Sender = sender.Sender
 
 // EmailPayload represents the structure of an email notification
 type EmailPayload struct {
-	To      string `json:"to"`
-	Subject string `json:"subject"`
-	Body    string `json:"body"`
+	To         string `json:"to"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	HTML       string `json:"html,omitempty"`
+	TemplateID string `json:"template_id,omitempty"` // resolved by TemplateSender
 }
 
 // SMSPayload represents the structure of an SMS notification
 type SMSPayload struct {
 	PhoneNumber string `json:"phone_number"`
 	Message     string `json:"message"`
+
+	// Origination overrides. Unset fields are filled from the tenant's sms
+	// channel settings by SMSSettingsSender.
+	SenderID          string `json:"sender_id,omitempty"`
+	OriginationNumber string `json:"origination_number,omitempty"`
+	SMSType           string `json:"sms_type,omitempty"`
 }
 
 // WebhookPayload represents the structure of a webhook notification
@@ -37,6 +59,18 @@ type WebhookPayload struct {
 	Headers map[string]string `json:"headers"`     // Custom headers
 	Body    json.RawMessage   `json:"body"`        // Raw JSON body
 	Timeout int               `json:"timeout_sec"` // Timeout in seconds, default 30
+
+	// Expect, when set, replaces "any 2xx" as the test for a successful
+	// delivery. See WebhookExpectation.
+	Expect *WebhookExpectation `json:"expect,omitempty"`
+}
+
+// SlackPayload represents the structure of a Slack notification. Blocks are
+// Block Kit blocks; Text is then the fallback shown in notifications.
+type SlackPayload struct {
+	Channel string          `json:"channel,omitempty"` // e.g. "#alerts"; required with a bot token
+	Text    string          `json:"text,omitempty"`
+	Blocks  json.RawMessage `json:"blocks,omitempty"`
 }
 
 // MultiSender routes notifications to the appropriate channel sender
@@ -58,9 +92,8 @@ func NewMultiSender(logger *zap.Logger, senders ...Sender) *MultiSender {
 func (m *MultiSender) Send(ctx context.Context, notif *db.Notification) error {
 	for _, sender := range m.senders {
 		if sender.SupportsChannel(notif.Channel) {
-			m.logger.Debug("routing notification to sender",
+			observ.Logger(ctx, m.logger).Debug("routing notification to sender",
 				zap.String("channel", notif.Channel),
-				zap.String("notification_id", notif.ID.String()),
 			)
 			return sender.Send(ctx, notif)
 		}
@@ -90,16 +123,16 @@ func NewLogSender(logger *zap.Logger) *LogSender {
 }
 
 func (s *LogSender) Send(ctx context.Context, notif *db.Notification) error {
-	s.logger.Debug("logging notification",
-		zap.String("notification_id", notif.ID.String()),
+	observ.Logger(ctx, s.logger).Debug("logging notification",
 		zap.String("channel", notif.Channel),
 		zap.String("user_id", notif.UserID.String()),
 		zap.Any("payload", json.RawMessage(notif.Payload)),
 	)
+	notif.Provider = ProviderLog
 	return nil
 }
 
 func (s *LogSender) SupportsChannel(channel string) bool {
 	// LogSender supports all channels for development/testing
-	return channel == "email" || channel == "sms" || channel == "webhook"
+	return channel == "email" || channel == "sms" || channel == "webhook" || channel == "slack"
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/secretbox"
 )
 
 func TestMultiSenderRouting(t *testing.T) {
@@ -247,6 +249,178 @@ func TestWebhookSenderHTTPError(t *testing.T) {
 	}
 }
 
+func TestWebhookSenderAnnotatesResponse(t *testing.T) {
+	sender := NewWebhookSender(zap.NewNop(), WebhookConfig{DefaultTimeout: 5 * time.Second})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	payloadBytes, _ := json.Marshal(WebhookPayload{URL: server.URL, Body: json.RawMessage(`{}`)})
+	notif := &db.Notification{
+		ID:       uuid.New(),
+		TenantID: uuid.New(),
+		Channel:  db.ChannelWebhook,
+		Payload:  payloadBytes,
+	}
+
+	if err := sender.Send(context.Background(), notif); err == nil {
+		t.Fatal("Send() should have failed for 503 status")
+	}
+	if notif.Annotations["status_code"] != http.StatusServiceUnavailable {
+		t.Errorf("expected status_code 503, got %v", notif.Annotations["status_code"])
+	}
+	headers, _ := notif.Annotations["headers"].(map[string]string)
+	if headers["X-Request-Id"] != "req-123" {
+		t.Errorf("expected the request ID header, got %v", headers)
+	}
+	if _, ok := headers["Set-Cookie"]; ok {
+		t.Error("expected Set-Cookie left out of the annotations")
+	}
+}
+
+func TestWebhookSenderCorrelationHeader(t *testing.T) {
+	sender := NewWebhookSender(zap.NewNop(), WebhookConfig{DefaultTimeout: 5 * time.Second})
+
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Nimbus-Correlation-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payloadBytes, _ := json.Marshal(WebhookPayload{URL: server.URL, Body: json.RawMessage(`{}`)})
+	notif := &db.Notification{
+		ID:            uuid.New(),
+		TenantID:      uuid.New(),
+		UserID:        uuid.New(),
+		Channel:       db.ChannelWebhook,
+		Payload:       payloadBytes,
+		CorrelationID: "checkout:req-42",
+	}
+
+	if err := sender.Send(context.Background(), notif); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if got != "checkout:req-42" {
+		t.Errorf("expected X-Nimbus-Correlation-ID checkout:req-42, got %q", got)
+	}
+}
+
+func TestWebhookSenderTenantHeaders(t *testing.T) {
+	box, err := secretbox.New("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatalf("secretbox: %v", err)
+	}
+	auth, _ := box.Seal("Bearer s3cret")
+	team, _ := box.Seal("payments")
+	settings, _ := json.Marshal(db.WebhookSettings{
+		UserAgent: "AcmeBot/2.0",
+		Headers:   map[string]string{"Authorization": auth, "X-Team": team},
+	})
+
+	sender := NewWebhookSender(zap.NewNop(), WebhookConfig{DefaultTimeout: 5 * time.Second})
+	sender.SetTenantHeaders(&mockSettingsStore{settings: string(settings)}, box)
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payloadBytes, _ := json.Marshal(WebhookPayload{
+		URL:     server.URL,
+		Body:    json.RawMessage(`{}`),
+		Headers: map[string]string{"X-Team": "billing"},
+	})
+	notif := &db.Notification{
+		ID:       uuid.New(),
+		TenantID: uuid.New(),
+		UserID:   uuid.New(),
+		Channel:  db.ChannelWebhook,
+		Payload:  payloadBytes,
+	}
+
+	if err := sender.Send(context.Background(), notif); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if got.Get("User-Agent") != "AcmeBot/2.0" {
+		t.Errorf("expected the tenant user agent, got %q", got.Get("User-Agent"))
+	}
+	if got.Get("Authorization") != "Bearer s3cret" {
+		t.Errorf("expected the opened Authorization header, got %q", got.Get("Authorization"))
+	}
+	if got.Get("X-Team") != "billing" {
+		t.Errorf("expected the payload header to win, got %q", got.Get("X-Team"))
+	}
+	if strings.Contains(string(notif.Payload), "s3cret") {
+		t.Error("expected tenant headers to stay out of the stored payload")
+	}
+
+	// Without the key the delivery fails rather than going out unauthenticated.
+	sender.SetTenantHeaders(&mockSettingsStore{settings: string(settings)}, nil)
+	if err := sender.Send(context.Background(), notif); err == nil {
+		t.Error("expected an error when headers can't be opened")
+	}
+}
+
+func TestWebhookSenderExpectations(t *testing.T) {
+	sender := NewWebhookSender(zap.NewNop(), WebhookConfig{DefaultTimeout: 5 * time.Second})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"result":{"accepted":true,"ids":["a1"]},"queue":"default"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		expect  string
+		wantErr string
+	}{
+		{"no expectation", ``, ""},
+		{"status listed", `{"status":[200,202]}`, ""},
+		{"status not listed", `{"status":[200]}`, "expected one of [200]"},
+		{"json matches", `{"json":{"result.accepted":true,"result.ids.0":"a1"}}`, ""},
+		{"json differs", `{"json":{"queue":"priority"}}`, `response "queue" is "default", expected "priority"`},
+		{"json missing", `{"json":{"result.rejected":false}}`, `response has no "result.rejected"`},
+		{"bad status code", `{"status":[42]}`, "not an HTTP status code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := `{"url":"` + server.URL + `","body":{}}`
+			if tt.expect != "" {
+				payload = `{"url":"` + server.URL + `","body":{},"expect":` + tt.expect + `}`
+			}
+			notif := &db.Notification{ID: uuid.New(), TenantID: uuid.New(), Channel: db.ChannelWebhook, Payload: json.RawMessage(payload)}
+
+			err := sender.Send(context.Background(), notif)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Send() failed: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSESTagValue(t *testing.T) {
+	tests := map[string]string{
+		"3f2504e0-4f89-11d3-9a0c-0305e82c3301": "3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+		"svc.checkout:req_42":                  "svc_checkout_req_42",
+	}
+	for in, want := range tests {
+		if got := sesTagValue(in); got != want {
+			t.Errorf("sesTagValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestEmailPayloadParsing(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -4,24 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ses"
 	"github.com/aws/aws-sdk-go-v2/service/ses/types"
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
 	"go.uber.org/zap"
 )
 
 type SESSender struct {
-	client *ses.Client
-	from   string
-	logger *zap.Logger
+	client    *ses.Client
+	from      string
+	configSet string
+	settings  ChannelSettingsStore
+	logger    *zap.Logger
 }
 
 type SESConfig struct {
 	Region    string
 	FromEmail string
+
+	// ConfigurationSet is attached to sends whose tenant has none of its
+	// own, so SES publishes their delivery and engagement events.
+	ConfigurationSet string
 }
 
 // NewSESSender creates an SES-backed email sender.
@@ -33,12 +41,20 @@ func NewSESSender(ctx context.Context, cfg SESConfig, logger *zap.Logger) (*SESS
 	}
 	return &SESSender{
 		// Initialize fields
-		client: ses.NewFromConfig(awsCfg),
-		from:   cfg.FromEmail,
-		logger: logger,
+		client:    ses.NewFromConfig(awsCfg),
+		from:      cfg.FromEmail,
+		configSet: cfg.ConfigurationSet,
+		logger:    logger,
 	}, nil
 }
 
+// SetTenantIdentities sends each tenant's email from the from_address and
+// configuration set in its email channel settings, so SES signs with the
+// tenant's DKIM keys and bounces land on its MAIL FROM domain.
+func (s *SESSender) SetTenantIdentities(settings ChannelSettingsStore) {
+	s.settings = settings
+}
+
 // Send sends an email notification via AWS SES
 func (s *SESSender) Send(ctx context.Context, notif *db.Notification) error {
 	// Validate channel
@@ -49,23 +65,32 @@ func (s *SESSender) Send(ctx context.Context, notif *db.Notification) error {
 	// Parse Payload
 	var payload EmailPayload
 	if err := json.Unmarshal(notif.Payload, &payload); err != nil {
-		return fmt.Errorf("invalid email payload: %w", err)
+		return payloadErrorf("invalid email payload: %w", err)
 	}
 
 	// Validate required fields
 	if payload.To == "" {
-		return fmt.Errorf("email payload missing 'to' field")
+		return payloadErrorf("email payload missing 'to' field")
 	}
 	if payload.Subject == "" {
-		return fmt.Errorf("email payload missing 'subject' field")
+		return payloadErrorf("email payload missing 'subject' field")
+	}
+	if payload.Body == "" && payload.HTML == "" {
+		return payloadErrorf("email payload missing 'body' field")
+	}
+
+	identity, err := s.tenantIdentity(ctx, notif)
+	if err != nil {
+		return err
 	}
-	if payload.Body == "" {
-		return fmt.Errorf("email payload missing 'body' field")
+	from := s.from
+	if identity.FromAddress != "" {
+		from = identity.FromAddress
 	}
 
 	// Build SES input
 	input := &ses.SendEmailInput{
-		Source: aws.String(s.from),
+		Source: aws.String(from),
 		Destination: &types.Destination{
 			ToAddresses: []string{payload.To},
 		},
@@ -74,14 +99,37 @@ func (s *SESSender) Send(ctx context.Context, notif *db.Notification) error {
 				Data:    aws.String(payload.Subject),
 				Charset: aws.String("UTF-8"),
 			},
-			Body: &types.Body{
-				Text: &types.Content{
-					Data:    aws.String(payload.Body),
-					Charset: aws.String("UTF-8"),
-				},
-			},
+			Body: &types.Body{},
 		},
 	}
+	if payload.Body != "" {
+		input.Message.Body.Text = &types.Content{
+			Data:    aws.String(payload.Body),
+			Charset: aws.String("UTF-8"),
+		}
+	}
+	if payload.HTML != "" {
+		input.Message.Body.Html = &types.Content{
+			Data:    aws.String(payload.HTML),
+			Charset: aws.String("UTF-8"),
+		}
+	}
+	if notif.CorrelationID != "" {
+		// Tags surface in SES event publishing (bounces, complaints, opens),
+		// so provider-side events can be joined back to the notification.
+		input.Tags = []types.MessageTag{{
+			Name:  aws.String("correlation_id"),
+			Value: aws.String(sesTagValue(notif.CorrelationID)),
+		}}
+	}
+
+	configSet := s.configSet
+	if identity.ConfigurationSet != "" {
+		configSet = identity.ConfigurationSet
+	}
+	if configSet != "" {
+		input.ConfigurationSetName = aws.String(configSet)
+	}
 
 	// Send
 	result, err := s.client.SendEmail(ctx, input)
@@ -89,16 +137,59 @@ func (s *SESSender) Send(ctx context.Context, notif *db.Notification) error {
 		return fmt.Errorf("ses send failed: %w", err)
 	}
 
-	s.logger.Info("sent email via ses",
-		zap.String("notification_id", notif.ID.String()),
+	notif.Provider = ProviderSES
+	notif.ProviderMessageID = aws.ToString(result.MessageId)
+	if len(input.Tags) > 0 {
+		// Recorded so the tags SES events will carry can be matched up
+		// from the timeline.
+		tags := make(map[string]string, len(input.Tags))
+		for _, tag := range input.Tags {
+			tags[aws.ToString(tag.Name)] = aws.ToString(tag.Value)
+		}
+		notif.Annotations = map[string]any{"message_tags": tags}
+	}
+
+	observ.Logger(ctx, s.logger).Info("sent email via ses",
 		zap.String("channel", notif.Channel),
 		zap.String("to", payload.To),
-		zap.String("message_id", aws.ToString(result.MessageId)),
+		zap.String("message_id", notif.ProviderMessageID),
 	)
 
 	return nil
 }
 
+// tenantIdentity returns the tenant's email settings, or empty settings
+// when none are configured.
+func (s *SESSender) tenantIdentity(ctx context.Context, notif *db.Notification) (db.EmailSettings, error) {
+	var settings db.EmailSettings
+	if s.settings == nil {
+		return settings, nil
+	}
+	record, err := s.settings.GetChannelSettings(ctx, notif.TenantID, db.ChannelEmail)
+	if err != nil {
+		// Sending from the shared address would put the tenant's bounces
+		// on our reputation, so wait for the settings instead.
+		return settings, fmt.Errorf("load email settings: %w", err)
+	}
+	if err := json.Unmarshal(record.Settings, &settings); err != nil {
+		return settings, fmt.Errorf("decode email settings: %w", err)
+	}
+	return settings, nil
+}
+
+// sesTagValue maps a correlation ID onto the SES tag charset, which only
+// allows ASCII letters, digits, '_' and '-'. '.' and ':' become '_'.
+func sesTagValue(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, id)
+}
+
 // SupportsChannel checks if this sender supports the email channel
 func (s *SESSender) SupportsChannel(channel string) bool {
 	return channel == db.ChannelEmail
@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// SESSimulatorSuccess is the SES mailbox simulator address that accepts
+// every message and delivers none.
+const SESSimulatorSuccess = "success@simulator.amazonses.com"
+
+// OutcomeSkipped is recorded for a sampled shadow send dropped because
+// too many were already in flight.
+const OutcomeSkipped = "skipped"
+
+// ShadowConfig configures a ShadowSender.
+type ShadowConfig struct {
+	Percent float64 // share of sends mirrored, 0-100
+	// Timeout bounds each shadow send. It runs detached from the send that
+	// triggered it, so a slow shadow never delays a delivery.
+	Timeout time.Duration
+	// MaxInFlight bounds concurrent shadow sends; samples beyond it are
+	// skipped rather than queued.
+	MaxInFlight int
+}
+
+// ShadowSender wraps a provider Sender and mirrors a sample of its sends to
+// a secondary provider, in the background, so the two can be compared on
+// real traffic: acceptance in nimbus_shadow_sends_total, latency in
+// nimbus_sender_duration_seconds under the shadow's provider name. The
+// shadow gets a copy of the notification and its result is discarded.
+//
+// The shadow must never deliver: wrap it so it sends nowhere real, e.g.
+// with NewSESSimulatorSender.
+type ShadowSender struct {
+	inner  Sender
+	shadow Sender
+	cfg    ShadowConfig
+	logger *zap.Logger
+	roll   func() float64 // in [0, 100)
+
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewShadowSender mirrors cfg.Percent of inner's sends, on the channels
+// shadow supports, to shadow.
+func NewShadowSender(inner, shadow Sender, cfg ShadowConfig, logger *zap.Logger) *ShadowSender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 10
+	}
+	return &ShadowSender{
+		inner:    inner,
+		shadow:   shadow,
+		cfg:      cfg,
+		logger:   logger,
+		roll:     func() float64 { return rand.Float64() * 100 },
+		inFlight: make(chan struct{}, cfg.MaxInFlight),
+	}
+}
+
+// Send delivers notif through the inner sender and, if it is sampled,
+// mirrors it to the shadow. The result is always the inner sender's.
+func (s *ShadowSender) Send(ctx context.Context, notif *db.Notification) error {
+	if !s.shadow.SupportsChannel(notif.Channel) || s.roll() >= s.cfg.Percent {
+		return s.inner.Send(ctx, notif)
+	}
+
+	// Copy before the inner send: providers set fields on notif.
+	mirror := *notif
+	err := s.inner.Send(ctx, notif)
+	primary := sendOutcome(err)
+
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		metrics.RecordShadowSend(notif.Channel, OutcomeSkipped, primary)
+		return err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.inFlight }()
+
+		shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cfg.Timeout)
+		defer cancel()
+		shadowErr := s.shadow.Send(shadowCtx, &mirror)
+		outcome := sendOutcome(shadowErr)
+		metrics.RecordShadowSend(mirror.Channel, outcome, primary)
+		if outcome != primary {
+			observ.Logger(ctx, s.logger).Info("shadow provider disagreed with primary",
+				zap.String("channel", mirror.Channel),
+				zap.String("primary_outcome", primary),
+				zap.String("shadow_outcome", outcome),
+				zap.NamedError("shadow_error", shadowErr),
+			)
+		}
+	}()
+	return err
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *ShadowSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
+
+// Close waits for in-flight shadow sends, giving up when ctx is done.
+func (s *ShadowSender) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SESSimulatorSender readdresses every email to the SES mailbox simulator
+// before handing it to an SES sender, so a shadow exercises the provider's
+// full acceptance path without reaching the real recipient.
+type SESSimulatorSender struct {
+	inner Sender
+}
+
+// NewSESSimulatorSender wraps an SES sender so it only sends to the
+// mailbox simulator.
+func NewSESSimulatorSender(inner Sender) *SESSimulatorSender {
+	return &SESSimulatorSender{inner: inner}
+}
+
+// Send replaces the recipient and sends. It refuses payloads it can't
+// rewrite rather than risk a real delivery.
+func (s *SESSimulatorSender) Send(ctx context.Context, notif *db.Notification) error {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(notif.Payload, &payload); err != nil {
+		return fmt.Errorf("simulator: invalid email payload: %w", err)
+	}
+	to, _ := json.Marshal(SESSimulatorSuccess)
+	payload["to"] = to
+
+	rewritten, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("simulator: encode payload: %w", err)
+	}
+	mirror := *notif
+	mirror.Payload = rewritten
+	return s.inner.Send(ctx, &mirror)
+}
+
+// SupportsChannel only admits email: the simulator is SES-specific.
+func (s *SESSimulatorSender) SupportsChannel(channel string) bool {
+	return channel == "email" && s.inner.SupportsChannel(channel)
+}
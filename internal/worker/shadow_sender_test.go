@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// newTestShadow mirrors every send to shadow.
+func newTestShadow(inner, shadow Sender, cfg ShadowConfig) *ShadowSender {
+	s := NewShadowSender(inner, shadow, cfg, zap.NewNop())
+	s.roll = func() float64 { return 0 }
+	return s
+}
+
+func TestShadowSender_MirrorsSampledSends(t *testing.T) {
+	primary := &countingSender{channel: "email"}
+	shadow := &countingSender{channel: "email", err: errors.New("rejected")}
+	s := newTestShadow(primary, shadow, ShadowConfig{Percent: 100})
+
+	for i := 0; i < 3; i++ {
+		if err := s.Send(context.Background(), &db.Notification{Channel: "email"}); err != nil {
+			t.Fatalf("send %d: expected the primary's result, got %v", i, err)
+		}
+		// Wait for each mirror so countingSender is never used concurrently.
+		if err := s.Close(context.Background()); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	}
+	if primary.sent != 3 || shadow.sent != 3 {
+		t.Errorf("expected 3 sends each, got primary %d shadow %d", primary.sent, shadow.sent)
+	}
+
+	// Channels the shadow doesn't support are never mirrored.
+	_ = s.Send(context.Background(), &db.Notification{Channel: "sms"})
+	_ = s.Close(context.Background())
+	if shadow.sent != 3 {
+		t.Errorf("expected sms not mirrored, got %d shadow sends", shadow.sent)
+	}
+}
+
+func TestShadowSender_Sampling(t *testing.T) {
+	primary := &countingSender{channel: "email"}
+	shadow := &countingSender{channel: "email"}
+	s := NewShadowSender(primary, shadow, ShadowConfig{Percent: 10}, zap.NewNop())
+	s.roll = func() float64 { return 50 }
+
+	_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+	_ = s.Close(context.Background())
+	if primary.sent != 1 || shadow.sent != 0 {
+		t.Errorf("expected an unsampled send to skip the shadow, got primary %d shadow %d", primary.sent, shadow.sent)
+	}
+}
+
+func TestShadowSender_DetachedFromCaller(t *testing.T) {
+	shadow := &blockingSender{started: make(chan struct{}), release: make(chan struct{})}
+	s := newTestShadow(&countingSender{channel: "email"}, shadow, ShadowConfig{Percent: 100, MaxInFlight: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.Send(ctx, &db.Notification{Channel: "email"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	<-shadow.started
+	// The send returned while the shadow is still running; cancelling the
+	// caller's context must not cut it short.
+	cancel()
+
+	// With the one slot taken, the next sample is skipped rather than queued.
+	_ = s.Send(context.Background(), &db.Notification{Channel: "email"})
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer closeCancel()
+	if err := s.Close(closeCtx); err == nil {
+		t.Error("expected Close to time out with a shadow send in flight")
+	}
+
+	close(shadow.release)
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if shadow.ctxErr != nil {
+		t.Errorf("expected the shadow context to outlive the caller's, got %v", shadow.ctxErr)
+	}
+}
+
+func TestSESSimulatorSender(t *testing.T) {
+	inner := &recordingSender{}
+	s := NewSESSimulatorSender(inner)
+
+	notif := &db.Notification{
+		Channel: "email",
+		Payload: json.RawMessage(`{"to":"user@example.com","subject":"Hi","body":"Hello"}`),
+	}
+	if err := s.Send(context.Background(), notif); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var payload EmailPayload
+	if err := json.Unmarshal(inner.payload, &payload); err != nil {
+		t.Fatalf("decode sent payload: %v", err)
+	}
+	if payload.To != SESSimulatorSuccess || payload.Subject != "Hi" || payload.Body != "Hello" {
+		t.Errorf("expected only the recipient rewritten, got %+v", payload)
+	}
+	if string(notif.Payload) != `{"to":"user@example.com","subject":"Hi","body":"Hello"}` {
+		t.Errorf("expected the caller's payload untouched, got %s", notif.Payload)
+	}
+
+	inner.payload = nil
+	if err := s.Send(context.Background(), &db.Notification{Channel: "email", Payload: json.RawMessage(`not json`)}); err == nil {
+		t.Error("expected an undecodable payload to be refused")
+	}
+	if inner.payload != nil {
+		t.Error("expected nothing sent for an undecodable payload")
+	}
+	if s.SupportsChannel("sms") || !s.SupportsChannel("email") {
+		t.Error("expected the simulator to admit email only")
+	}
+}
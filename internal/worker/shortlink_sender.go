@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// TextShortener rewrites long URLs in message text. *shortlink.Shortener
+// implements it.
+type TextShortener interface {
+	ShortenText(ctx context.Context, notif *db.Notification, text string) (string, error)
+}
+
+// LinkShortenerSender wraps a Sender and shortens URLs in SMS messages
+// before they are sent, since every character counts against the segment
+// budget. Other channels pass through unchanged.
+type LinkShortenerSender struct {
+	inner     Sender
+	shortener TextShortener
+	logger    *zap.Logger
+}
+
+// NewLinkShortenerSender wraps a sender with SMS link shortening.
+func NewLinkShortenerSender(inner Sender, shortener TextShortener, logger *zap.Logger) *LinkShortenerSender {
+	return &LinkShortenerSender{
+		inner:     inner,
+		shortener: shortener,
+		logger:    logger,
+	}
+}
+
+// Send shortens links in the SMS message, then delegates to the inner sender.
+func (s *LinkShortenerSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelSMS {
+		return s.inner.Send(ctx, notif)
+	}
+
+	// Decode loosely so fields the shortener doesn't know about survive the
+	// rewrite untouched.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(notif.Payload, &fields); err != nil {
+		return s.inner.Send(ctx, notif)
+	}
+	var message string
+	if err := json.Unmarshal(fields["message"], &message); err != nil || message == "" {
+		return s.inner.Send(ctx, notif)
+	}
+
+	shortened, err := s.shortener.ShortenText(ctx, notif, message)
+	if err != nil {
+		return err
+	}
+	if shortened == message {
+		return s.inner.Send(ctx, notif)
+	}
+
+	fields["message"], _ = json.Marshal(shortened)
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("marshal shortened payload: %w", err)
+	}
+	notif.Payload = rewritten
+
+	return s.inner.Send(ctx, notif)
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *LinkShortenerSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
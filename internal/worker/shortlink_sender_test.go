@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// fakeShortener replaces every "https://long" with "https://s".
+type fakeShortener struct {
+	err error
+}
+
+func (f *fakeShortener) ShortenText(ctx context.Context, notif *db.Notification, text string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	return strings.ReplaceAll(text, "https://long", "https://s"), nil
+}
+
+func TestLinkShortenerSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		payload string
+		want    string
+	}{
+		{
+			name:    "sms message is shortened, other fields kept",
+			channel: db.ChannelSMS,
+			payload: `{"phone_number":"+15551234567","message":"Go to https://long","sender_id":"ACME"}`,
+			want:    `{"message":"Go to https://s","phone_number":"+15551234567","sender_id":"ACME"}`,
+		},
+		{
+			name:    "sms without links passes through",
+			channel: db.ChannelSMS,
+			payload: `{"phone_number":"+15551234567","message":"Your code is 1234"}`,
+			want:    `{"phone_number":"+15551234567","message":"Your code is 1234"}`,
+		},
+		{
+			name:    "email is untouched",
+			channel: db.ChannelEmail,
+			payload: `{"to":"a@example.com","subject":"Hi","body":"https://long"}`,
+			want:    `{"to":"a@example.com","subject":"Hi","body":"https://long"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingSender{}
+			sender := NewLinkShortenerSender(inner, &fakeShortener{}, zap.NewNop())
+
+			err := sender.Send(context.Background(), &db.Notification{
+				ID:      uuid.New(),
+				Channel: tt.channel,
+				Payload: json.RawMessage(tt.payload),
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if string(inner.payload) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, inner.payload)
+			}
+		})
+	}
+}
+
+func TestLinkShortenerSender_ShortenError(t *testing.T) {
+	inner := &recordingSender{}
+	sender := NewLinkShortenerSender(inner, &fakeShortener{err: errors.New("db down")}, zap.NewNop())
+
+	err := sender.Send(context.Background(), &db.Notification{
+		ID:      uuid.New(),
+		Channel: db.ChannelSMS,
+		Payload: json.RawMessage(`{"phone_number":"+15551234567","message":"https://long"}`),
+	})
+	if err == nil {
+		t.Fatal("expected the error to fail the send so it is retried")
+	}
+	if inner.payload != nil {
+		t.Error("expected nothing to be sent")
+	}
+}
@@ -0,0 +1,194 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+	"github.com/lalithlochan/nimbus/pkg/sender"
+)
+
+const (
+	defaultSlackAPIURL  = "https://slack.com/api"
+	defaultSlackTimeout = 10 * time.Second
+)
+
+// slackPermanentErrors are chat.postMessage error codes retrying can't fix,
+// with the DLQ reason each is filed under. Anything else, such as
+// ratelimited or a revoked token an operator can replace, is retried.
+var slackPermanentErrors = map[string]string{
+	"channel_not_found":     db.DLQReasonInvalidRecipient,
+	"not_in_channel":        db.DLQReasonInvalidRecipient,
+	"is_archived":           db.DLQReasonInvalidRecipient,
+	"invalid_blocks":        db.DLQReasonPayloadError,
+	"invalid_blocks_format": db.DLQReasonPayloadError,
+	"msg_too_long":          db.DLQReasonPayloadError,
+	"no_text":               db.DLQReasonPayloadError,
+}
+
+// SlackConfig configures the slack channel. At least one of WebhookURL and
+// BotToken must be set.
+type SlackConfig struct {
+	// WebhookURL is an incoming webhook. Its channel is fixed when the
+	// webhook is created, so a payload's channel is ignored.
+	WebhookURL string
+	// BotToken (xoxb-...) posts with chat.postMessage to the payload's
+	// channel. The bot must be a member of private channels.
+	BotToken string
+	// APIURL is the Web API base URL; it defaults to https://slack.com/api.
+	APIURL  string
+	Timeout time.Duration
+}
+
+// SlackSender posts notifications to Slack. A payload with a channel goes
+// through chat.postMessage when a bot token is configured; anything else
+// goes to the incoming webhook.
+type SlackSender struct {
+	client *http.Client
+	cfg    SlackConfig
+	logger *zap.Logger
+}
+
+// NewSlackSender creates a sender for the slack channel.
+func NewSlackSender(logger *zap.Logger, cfg SlackConfig) *SlackSender {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultSlackAPIURL
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultSlackTimeout
+	}
+
+	return &SlackSender{
+		client: &http.Client{Timeout: timeout},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Send posts a notification to Slack.
+func (s *SlackSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelSlack {
+		return fmt.Errorf("slack sender only supports slack, got: %s", notif.Channel)
+	}
+
+	var payload SlackPayload
+	if err := json.Unmarshal(notif.Payload, &payload); err != nil {
+		return payloadErrorf("invalid slack payload: %w", err)
+	}
+	if payload.Text == "" && len(payload.Blocks) == 0 {
+		return payloadErrorf("slack payload missing text or blocks")
+	}
+	if len(payload.Blocks) > 0 {
+		var blocks []json.RawMessage
+		if err := json.Unmarshal(payload.Blocks, &blocks); err != nil {
+			return payloadErrorf("slack payload blocks must be an array: %w", err)
+		}
+	}
+
+	switch {
+	case payload.Channel != "" && s.cfg.BotToken != "":
+		return s.postMessage(ctx, notif, payload)
+	case s.cfg.WebhookURL != "":
+		return s.postWebhook(ctx, notif, payload)
+	default:
+		return payloadErrorf("slack payload missing channel: %w", sender.ErrPermanent)
+	}
+}
+
+// SupportsChannel checks if this sender supports slack
+func (s *SlackSender) SupportsChannel(channel string) bool {
+	return channel == db.ChannelSlack
+}
+
+// postWebhook sends payload to the incoming webhook, which answers "ok" or
+// an error such as no_text in the body.
+func (s *SlackSender) postWebhook(ctx context.Context, notif *db.Notification, payload SlackPayload) error {
+	resp, err := s.post(ctx, s.cfg.WebhookURL, "", payload)
+	if err != nil {
+		return fmt.Errorf("slack webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &webhookStatusError{resp.StatusCode, fmt.Errorf("slack webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))}
+	}
+
+	// Incoming webhooks return no message ID.
+	notif.Provider = ProviderSlack
+
+	observ.Logger(ctx, s.logger).Info("slack message posted via webhook",
+		zap.Int("status_code", resp.StatusCode),
+	)
+	return nil
+}
+
+// postMessage sends payload with chat.postMessage. Slack answers 200 even
+// for errors, with "ok": false and an error code.
+func (s *SlackSender) postMessage(ctx context.Context, notif *db.Notification, payload SlackPayload) error {
+	resp, err := s.post(ctx, strings.TrimRight(s.cfg.APIURL, "/")+"/chat.postMessage", s.cfg.BotToken, payload)
+	if err != nil {
+		return fmt.Errorf("slack chat.postMessage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &webhookStatusError{resp.StatusCode, fmt.Errorf("slack chat.postMessage returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))}
+	}
+
+	var result struct {
+		OK      bool   `json:"ok"`
+		Error   string `json:"error"`
+		Channel string `json:"channel"`
+		TS      string `json:"ts"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("decode slack chat.postMessage response: %w", err)
+	}
+	if !result.OK {
+		cause := fmt.Errorf("slack chat.postMessage to %s failed: %s", payload.Channel, result.Error)
+		if _, ok := slackPermanentErrors[result.Error]; ok {
+			cause = fmt.Errorf("%w: %w", cause, sender.ErrPermanent)
+		}
+		return &slackAPIError{code: result.Error, err: cause}
+	}
+
+	// The message's ts, with its channel, identifies it for edits and
+	// threaded replies.
+	notif.Provider = ProviderSlack
+	notif.ProviderMessageID = result.TS
+
+	observ.Logger(ctx, s.logger).Info("slack message posted",
+		zap.String("slack_channel", result.Channel),
+		zap.String("ts", result.TS),
+	)
+	return nil
+}
+
+// post sends payload as JSON to url, with token as a bearer token if set.
+func (s *SlackSender) post(ctx context.Context, url, token string, payload SlackPayload) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, payloadErrorf("encode slack payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.client.Do(req)
+}
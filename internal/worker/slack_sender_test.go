@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/pkg/sender"
+)
+
+func slackNotification(payload string) *db.Notification {
+	return &db.Notification{ID: uuid.New(), Channel: db.ChannelSlack, Payload: json.RawMessage(payload)}
+}
+
+func TestSlackSender_PostMessage(t *testing.T) {
+	var gotAuth, gotPath string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if gotBody["channel"] == "#gone" {
+			_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "C024BE91L", "ts": "1700000000.000100"}`))
+	}))
+	defer server.Close()
+	s := NewSlackSender(zap.NewNop(), SlackConfig{BotToken: "xoxb-test", APIURL: server.URL})
+
+	notif := slackNotification(`{"channel": "#alerts", "text": "Deploy finished", "blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": "*Deploy finished*"}}]}`)
+	if err := s.Send(context.Background(), notif); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if gotAuth != "Bearer xoxb-test" || gotPath != "/chat.postMessage" {
+		t.Errorf("unexpected request: auth %q, path %q", gotAuth, gotPath)
+	}
+	if blocks, _ := gotBody["blocks"].([]any); gotBody["channel"] != "#alerts" || len(blocks) != 1 {
+		t.Errorf("unexpected body %v", gotBody)
+	}
+	if notif.Provider != ProviderSlack || notif.ProviderMessageID != "1700000000.000100" {
+		t.Errorf("expected the message ts to be recorded, got %q %q", notif.Provider, notif.ProviderMessageID)
+	}
+
+	err := s.Send(context.Background(), slackNotification(`{"channel": "#gone", "text": "hi"}`))
+	if !errors.Is(err, sender.ErrPermanent) || failureReason(err) != db.DLQReasonInvalidRecipient {
+		t.Errorf("expected a permanent invalid_recipient error, got %v", err)
+	}
+}
+
+func TestSlackSender_Webhook(t *testing.T) {
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		if r.Header.Get("Authorization") != "" {
+			t.Error("expected no token on the webhook")
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		cfg     SlackConfig
+		payload string
+	}{
+		{"webhook only", SlackConfig{WebhookURL: server.URL}, `{"channel": "#alerts", "text": "hi"}`},
+		{"no channel with both", SlackConfig{WebhookURL: server.URL, BotToken: "xoxb-test", APIURL: "http://127.0.0.1:0"}, `{"text": "hi"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = ""
+			notif := slackNotification(tt.payload)
+			if err := NewSlackSender(zap.NewNop(), tt.cfg).Send(context.Background(), notif); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if gotBody == "" || notif.Provider != ProviderSlack {
+				t.Errorf("expected the webhook to be posted, got body %q provider %q", gotBody, notif.Provider)
+			}
+		})
+	}
+}
+
+func TestSlackSender_InvalidPayload(t *testing.T) {
+	tests := map[string]struct {
+		cfg     SlackConfig
+		payload string
+	}{
+		"no text or blocks":       {SlackConfig{WebhookURL: "http://127.0.0.1:0"}, `{"channel": "#alerts"}`},
+		"blocks not an array":     {SlackConfig{WebhookURL: "http://127.0.0.1:0"}, `{"blocks": {"type": "divider"}}`},
+		"no channel for bot only": {SlackConfig{BotToken: "xoxb-test", APIURL: "http://127.0.0.1:0"}, `{"text": "hi"}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewSlackSender(zap.NewNop(), tt.cfg).Send(context.Background(), slackNotification(tt.payload))
+			if failureReason(err) != db.DLQReasonPayloadError {
+				t.Errorf("expected a payload error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// ChannelSettingsStore reads per-tenant channel settings.
+type ChannelSettingsStore interface {
+	GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error)
+}
+
+// SMSSettingsSender wraps a Sender and fills the tenant's SMS origination
+// identity (sender ID, origination number, message type) into SMS payloads
+// that don't set their own. Providers then only read the payload.
+type SMSSettingsSender struct {
+	inner  Sender
+	store  ChannelSettingsStore
+	logger *zap.Logger
+}
+
+// NewSMSSettingsSender wraps a sender with tenant SMS settings.
+func NewSMSSettingsSender(inner Sender, store ChannelSettingsStore, logger *zap.Logger) *SMSSettingsSender {
+	return &SMSSettingsSender{
+		inner:  inner,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Send applies the tenant's SMS settings, then delegates to the inner sender.
+func (s *SMSSettingsSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelSMS {
+		return s.inner.Send(ctx, notif)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(notif.Payload, &fields); err != nil {
+		return s.inner.Send(ctx, notif)
+	}
+
+	record, err := s.store.GetChannelSettings(ctx, notif.TenantID, db.ChannelSMS)
+	if err != nil {
+		// Sending from the wrong identity is worse than sending late.
+		return fmt.Errorf("load sms settings: %w", err)
+	}
+	var settings db.SMSSettings
+	if err := json.Unmarshal(record.Settings, &settings); err != nil {
+		return fmt.Errorf("decode sms settings: %w", err)
+	}
+
+	changed := false
+	for key, value := range map[string]string{
+		"sender_id":          settings.SenderID,
+		"origination_number": settings.OriginationNumber,
+		"sms_type":           settings.SMSType,
+	} {
+		if value == "" {
+			continue
+		}
+		if _, set := fields[key]; set {
+			continue
+		}
+		fields[key], _ = json.Marshal(value)
+		changed = true
+	}
+	if !changed {
+		return s.inner.Send(ctx, notif)
+	}
+
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("marshal sms payload: %w", err)
+	}
+	notif.Payload = rewritten
+
+	return s.inner.Send(ctx, notif)
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *SMSSettingsSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
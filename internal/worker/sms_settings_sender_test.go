@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockSettingsStore struct {
+	settings string
+	err      error
+}
+
+func (m *mockSettingsStore) GetChannelSettings(ctx context.Context, tenantID uuid.UUID, channel string) (*db.TenantChannelSettings, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &db.TenantChannelSettings{TenantID: tenantID, Channel: channel, Settings: json.RawMessage(m.settings)}, nil
+}
+
+func TestSMSSettingsSender(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		payload  string
+		want     SMSPayload
+	}{
+		{
+			name:     "tenant settings fill the payload",
+			settings: `{"sender_id":"ACME","origination_number":"+15550001111","sms_type":"transactional"}`,
+			payload:  `{"phone_number":"+15551234567","message":"Hi"}`,
+			want: SMSPayload{
+				PhoneNumber:       "+15551234567",
+				Message:           "Hi",
+				SenderID:          "ACME",
+				OriginationNumber: "+15550001111",
+				SMSType:           db.SMSTypeTransactional,
+			},
+		},
+		{
+			name:     "payload overrides win",
+			settings: `{"sender_id":"ACME","sms_type":"transactional"}`,
+			payload:  `{"phone_number":"+15551234567","message":"Hi","sms_type":"promotional"}`,
+			want: SMSPayload{
+				PhoneNumber: "+15551234567",
+				Message:     "Hi",
+				SenderID:    "ACME",
+				SMSType:     db.SMSTypePromotional,
+			},
+		},
+		{
+			name:     "no settings leaves the payload alone",
+			settings: `{}`,
+			payload:  `{"phone_number":"+15551234567","message":"Hi"}`,
+			want:     SMSPayload{PhoneNumber: "+15551234567", Message: "Hi"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingSender{}
+			sender := NewSMSSettingsSender(inner, &mockSettingsStore{settings: tt.settings}, zap.NewNop())
+
+			err := sender.Send(context.Background(), &db.Notification{
+				ID:       uuid.New(),
+				TenantID: uuid.New(),
+				Channel:  db.ChannelSMS,
+				Payload:  json.RawMessage(tt.payload),
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var got SMSPayload
+			if err := json.Unmarshal(inner.payload, &got); err != nil {
+				t.Fatalf("failed to decode sent payload: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSMSSettingsSender_StoreError(t *testing.T) {
+	inner := &recordingSender{}
+	sender := NewSMSSettingsSender(inner, &mockSettingsStore{err: errors.New("db down")}, zap.NewNop())
+
+	err := sender.Send(context.Background(), &db.Notification{
+		ID:      uuid.New(),
+		Channel: db.ChannelSMS,
+		Payload: json.RawMessage(`{"phone_number":"+15551234567","message":"Hi"}`),
+	})
+	if err == nil {
+		t.Fatal("expected an error so the send is retried")
+	}
+	if inner.payload != nil {
+		t.Error("expected nothing to be sent")
+	}
+}
+
+func TestSNSSMSAttributes(t *testing.T) {
+	attrs := snsSMSAttributes(SMSPayload{SenderID: "ACME", SMSType: db.SMSTypePromotional})
+	if got := *attrs["AWS.SNS.SMS.SenderID"].StringValue; got != "ACME" {
+		t.Errorf("expected sender ID ACME, got %s", got)
+	}
+	if got := *attrs["AWS.SNS.SMS.SMSType"].StringValue; got != "Promotional" {
+		t.Errorf("expected SMSType Promotional, got %s", got)
+	}
+	if _, ok := attrs["AWS.MM.SMS.OriginationNumber"]; ok {
+		t.Error("expected no origination number attribute")
+	}
+	if snsSMSAttributes(SMSPayload{}) != nil {
+		t.Error("expected nil attributes when nothing is set")
+	}
+}
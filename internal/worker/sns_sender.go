@@ -8,9 +8,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
 )
 
 // SNSSender sends SMS notifications via AWS SNS
@@ -45,21 +47,22 @@ func (s *SNSSender) Send(ctx context.Context, notif *db.Notification) error {
 	// Parse payload
 	var payload SMSPayload
 	if err := json.Unmarshal(notif.Payload, &payload); err != nil {
-		return fmt.Errorf("invalid SMS payload: %w", err)
+		return payloadErrorf("invalid SMS payload: %w", err)
 	}
 
 	// Validate required fields
 	if payload.PhoneNumber == "" {
-		return fmt.Errorf("SMS payload missing phone_number")
+		return payloadErrorf("SMS payload missing phone_number")
 	}
 	if payload.Message == "" {
-		return fmt.Errorf("SMS payload missing message")
+		return payloadErrorf("SMS payload missing message")
 	}
 
 	// Send SMS via SNS
 	input := &sns.PublishInput{
-		PhoneNumber: aws.String(payload.PhoneNumber),
-		Message:     aws.String(payload.Message),
+		PhoneNumber:       aws.String(payload.PhoneNumber),
+		Message:           aws.String(payload.Message),
+		MessageAttributes: snsSMSAttributes(payload),
 	}
 
 	result, err := s.client.Publish(ctx, input)
@@ -67,15 +70,55 @@ func (s *SNSSender) Send(ctx context.Context, notif *db.Notification) error {
 		return fmt.Errorf("sns publish failed: %w", err)
 	}
 
-	s.logger.Info("SMS sent via SNS",
-		zap.String("id", notif.ID.String()),
+	notif.Provider = ProviderSNS
+	notif.ProviderMessageID = aws.ToString(result.MessageId)
+	if len(input.MessageAttributes) > 0 {
+		attrs := make(map[string]string, len(input.MessageAttributes))
+		for name, value := range input.MessageAttributes {
+			attrs[name] = aws.ToString(value.StringValue)
+		}
+		notif.Annotations = map[string]any{"message_attributes": attrs}
+	}
+
+	observ.Logger(ctx, s.logger).Info("SMS sent via SNS",
 		zap.String("phone_number", payload.PhoneNumber),
-		zap.String("message_id", *result.MessageId),
+		zap.String("sender_id", payload.SenderID),
+		zap.String("origination_number", payload.OriginationNumber),
+		zap.String("message_id", notif.ProviderMessageID),
 	)
 
 	return nil
 }
 
+// snsSMSAttributes maps the payload's origination settings onto the SNS SMS
+// message attributes. Unset fields are left to the account defaults.
+func snsSMSAttributes(payload SMSPayload) map[string]types.MessageAttributeValue {
+	attrs := map[string]types.MessageAttributeValue{}
+	if payload.SenderID != "" {
+		attrs["AWS.SNS.SMS.SenderID"] = snsStringAttribute(payload.SenderID)
+	}
+	if payload.OriginationNumber != "" {
+		attrs["AWS.MM.SMS.OriginationNumber"] = snsStringAttribute(payload.OriginationNumber)
+	}
+	switch payload.SMSType {
+	case db.SMSTypeTransactional:
+		attrs["AWS.SNS.SMS.SMSType"] = snsStringAttribute("Transactional")
+	case db.SMSTypePromotional:
+		attrs["AWS.SNS.SMS.SMSType"] = snsStringAttribute("Promotional")
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+func snsStringAttribute(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
+}
+
 // SupportsChannel checks if this sender supports the SMS channel
 func (s *SNSSender) SupportsChannel(channel string) bool {
 	return channel == db.ChannelSMS
@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// TemplateStore looks up published email templates.
+type TemplateStore interface {
+	GetTemplate(ctx context.Context, id uuid.UUID) (*db.Template, error)
+}
+
+// TemplateSender wraps a Sender and expands email payloads that reference a
+// published template by "template_id" into a subject, HTML body and plain
+// text alternative. The HTML was compiled from MJML at publish time, so no
+// compilation happens here; only the payload's "template_data" variables
+// are filled in, as RenderTemplate describes.
+//
+// Payloads without a template_id pass through unchanged.
+type TemplateSender struct {
+	inner  Sender
+	store  TemplateStore
+	logger *zap.Logger
+}
+
+// NewTemplateSender wraps a sender with template resolution.
+func NewTemplateSender(inner Sender, store TemplateStore, logger *zap.Logger) *TemplateSender {
+	return &TemplateSender{
+		inner:  inner,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Send resolves the template, if any, then delegates to the inner sender.
+func (s *TemplateSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelEmail {
+		return s.inner.Send(ctx, notif)
+	}
+
+	var payload EmailPayload
+	if err := json.Unmarshal(notif.Payload, &payload); err != nil || payload.TemplateID == "" {
+		return s.inner.Send(ctx, notif)
+	}
+	var data struct {
+		TemplateData map[string]string `json:"template_data"`
+	}
+	if err := json.Unmarshal(notif.Payload, &data); err != nil {
+		return fmt.Errorf("invalid template_data: %w", err)
+	}
+
+	templateID, err := uuid.Parse(payload.TemplateID)
+	if err != nil {
+		return fmt.Errorf("invalid template_id: %w", err)
+	}
+
+	tmpl, err := s.store.GetTemplate(ctx, templateID)
+	if err != nil {
+		return fmt.Errorf("load template: %w", err)
+	}
+	// A template belonging to someone else is treated exactly like a missing
+	// one, so IDs can't be probed across tenants.
+	if tmpl.TenantID != notif.TenantID {
+		return fmt.Errorf("load template: template not found: %s", templateID)
+	}
+	if tmpl.Status != db.TemplateStatusPublished {
+		return fmt.Errorf("template %s is not published", templateID)
+	}
+
+	content, err := RenderTemplate(tmpl.Subject, tmpl.TextBody, tmpl.HTML, data.TemplateData)
+	if err != nil {
+		return fmt.Errorf("template %s: %w", templateID, err)
+	}
+
+	// An explicit subject on the notification overrides the template's.
+	if payload.Subject == "" {
+		payload.Subject = content.Subject
+	}
+	payload.HTML = content.HTML
+	if payload.Body == "" {
+		payload.Body = content.Text
+	}
+
+	rendered, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal rendered payload: %w", err)
+	}
+	notif.Payload = rendered
+
+	observ.Logger(ctx, s.logger).Debug("resolved email template",
+		zap.String("template_id", templateID.String()),
+	)
+
+	return s.inner.Send(ctx, notif)
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *TemplateSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
+
+// RenderedTemplate is a template with its variables filled in.
+type RenderedTemplate struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// RenderTemplate fills vars into a template's subject, text and HTML, which
+// reference them as {{.name}}. Values are HTML-escaped in the HTML only.
+// A variable the template uses but vars lacks is an error, so a send never
+// goes out with a blank where a name should be.
+//
+// The HTML is rendered as text rather than with html/template, which would
+// strip the conditional comments MJML emits for Outlook.
+func RenderTemplate(subject, text, htmlBody string, vars map[string]string) (*RenderedTemplate, error) {
+	escaped := make(map[string]string, len(vars))
+	for k, v := range vars {
+		escaped[k] = html.EscapeString(v)
+	}
+
+	var out RenderedTemplate
+	var err error
+	if out.Subject, err = renderPart("subject", subject, vars); err != nil {
+		return nil, err
+	}
+	if out.Text, err = renderPart("text", text, vars); err != nil {
+		return nil, err
+	}
+	if out.HTML, err = renderPart("html", htmlBody, escaped); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func renderPart(name, source string, vars map[string]string) (string, error) {
+	// Most templates have no variables; skip parsing them.
+	if !strings.Contains(source, "{{") {
+		return source, nil
+	}
+	t, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return b.String(), nil
+}
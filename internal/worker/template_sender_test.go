@@ -0,0 +1,173 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+type mockTemplateStore struct {
+	templates map[uuid.UUID]*db.Template
+}
+
+func (m *mockTemplateStore) GetTemplate(ctx context.Context, id uuid.UUID) (*db.Template, error) {
+	tmpl, ok := m.templates[id]
+	if !ok {
+		return nil, errors.New("template not found")
+	}
+	return tmpl, nil
+}
+
+// recordingSender keeps the payload it was handed so tests can inspect what
+// the decorator produced.
+type recordingSender struct {
+	payload json.RawMessage
+}
+
+func (r *recordingSender) Send(ctx context.Context, notif *db.Notification) error {
+	r.payload = notif.Payload
+	return nil
+}
+
+func (r *recordingSender) SupportsChannel(channel string) bool { return true }
+
+func TestTemplateSender(t *testing.T) {
+	tenantID := uuid.New()
+	published := &db.Template{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+		Subject:  "Welcome!",
+		TextBody: "Welcome aboard",
+		HTML:     "<html>Welcome aboard</html>",
+		Status:   db.TemplateStatusPublished,
+	}
+	personalized := &db.Template{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+		Subject:  "Welcome to {{.product}}",
+		TextBody: "Hi {{.name}}",
+		HTML:     "<html>Hi {{.name}}</html>",
+		Status:   db.TemplateStatusPublished,
+	}
+	draft := &db.Template{ID: uuid.New(), TenantID: tenantID, Status: db.TemplateStatusDraft}
+	foreign := &db.Template{ID: uuid.New(), TenantID: uuid.New(), HTML: "<html></html>", Status: db.TemplateStatusPublished}
+	store := &mockTemplateStore{templates: map[uuid.UUID]*db.Template{
+		published.ID:    published,
+		personalized.ID: personalized,
+		draft.ID:        draft,
+		foreign.ID:      foreign,
+	}}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    EmailPayload
+		wantErr bool
+	}{
+		{
+			name:    "no template passes through",
+			payload: `{"to":"a@example.com","subject":"Hi","body":"Hello"}`,
+			want:    EmailPayload{To: "a@example.com", Subject: "Hi", Body: "Hello"},
+		},
+		{
+			name:    "published template fills subject, html and text",
+			payload: `{"to":"a@example.com","template_id":"` + published.ID.String() + `"}`,
+			want: EmailPayload{
+				To:         "a@example.com",
+				Subject:    "Welcome!",
+				Body:       "Welcome aboard",
+				HTML:       "<html>Welcome aboard</html>",
+				TemplateID: published.ID.String(),
+			},
+		},
+		{
+			name:    "explicit subject wins",
+			payload: `{"to":"a@example.com","subject":"Custom","template_id":"` + published.ID.String() + `"}`,
+			want: EmailPayload{
+				To:         "a@example.com",
+				Subject:    "Custom",
+				Body:       "Welcome aboard",
+				HTML:       "<html>Welcome aboard</html>",
+				TemplateID: published.ID.String(),
+			},
+		},
+		{
+			name:    "template_data fills variables",
+			payload: `{"to":"a@example.com","template_id":"` + personalized.ID.String() + `","template_data":{"product":"Nimbus","name":"<Ada>"}}`,
+			want: EmailPayload{
+				To:         "a@example.com",
+				Subject:    "Welcome to Nimbus",
+				Body:       "Hi <Ada>",
+				HTML:       "<html>Hi &lt;Ada&gt;</html>",
+				TemplateID: personalized.ID.String(),
+			},
+		},
+		{"missing template_data", `{"to":"a@example.com","template_id":"` + personalized.ID.String() + `","template_data":{"product":"Nimbus"}}`, EmailPayload{}, true},
+		{"draft template", `{"to":"a@example.com","template_id":"` + draft.ID.String() + `"}`, EmailPayload{}, true},
+		{"other tenant's template", `{"to":"a@example.com","template_id":"` + foreign.ID.String() + `"}`, EmailPayload{}, true},
+		{"unknown template", `{"to":"a@example.com","template_id":"` + uuid.New().String() + `"}`, EmailPayload{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingSender{}
+			sender := NewTemplateSender(inner, store, zap.NewNop())
+
+			err := sender.Send(context.Background(), &db.Notification{
+				ID:       uuid.New(),
+				TenantID: tenantID,
+				Channel:  db.ChannelEmail,
+				Payload:  []byte(tt.payload),
+			})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if inner.payload != nil {
+					t.Error("expected nothing to be sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var got EmailPayload
+			if err := json.Unmarshal(inner.payload, &got); err != nil {
+				t.Fatalf("failed to decode sent payload: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	html := `<!--[if mso | IE]><table><tr><td><![endif]--><p title="{{.name}}">Hi {{.name}}</p>`
+	got, err := RenderTemplate("Hi {{.name}}", "No variables here", html, map[string]string{"name": `Ada "A&B"`})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if got.Subject != `Hi Ada "A&B"` || got.Text != "No variables here" {
+		t.Errorf("unexpected subject/text: %+v", got)
+	}
+	want := `<!--[if mso | IE]><table><tr><td><![endif]--><p title="Ada &#34;A&amp;B&#34;">Hi Ada &#34;A&amp;B&#34;</p>`
+	if got.HTML != want {
+		t.Errorf("expected escaped values and MSO comments kept:\n got %s\nwant %s", got.HTML, want)
+	}
+
+	if _, err := RenderTemplate("Hi {{.name}}", "", "", nil); err == nil {
+		t.Error("expected an error for a missing variable")
+	}
+	if _, err := RenderTemplate("Hi {{.name", "", "", map[string]string{"name": "Ada"}); err == nil {
+		t.Error("expected an error for a malformed template")
+	}
+}
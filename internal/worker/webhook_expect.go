@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxAssertedBody is how much of a response is read when its JSON is
+// asserted on. Larger bodies fail the JSON assertions.
+const maxAssertedBody = 64 << 10
+
+// WebhookExpectation is what a webhook payload's "expect" declares a good
+// response looks like. Without one, any 2xx is a successful delivery.
+type WebhookExpectation struct {
+	// Status lists the accepted status codes, replacing "any 2xx".
+	Status []int `json:"status,omitempty"`
+	// JSON maps dot-separated paths into the response body to the values
+	// they must equal, e.g. {"result.accepted": true}. Array elements are
+	// addressed by index: "items.0.id".
+	JSON map[string]json.RawMessage `json:"json,omitempty"`
+}
+
+// validate rejects expectations that can never pass.
+func (e *WebhookExpectation) validate() error {
+	for _, code := range e.Status {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("expect.status %d is not an HTTP status code", code)
+		}
+	}
+	for path, want := range e.JSON {
+		if path == "" {
+			return fmt.Errorf("expect.json has an empty path")
+		}
+		if !json.Valid(want) {
+			return fmt.Errorf("expect.json[%q] is not valid JSON", path)
+		}
+	}
+	return nil
+}
+
+// statusOK reports whether status is an accepted response code.
+func (e *WebhookExpectation) statusOK(status int) bool {
+	if e == nil || len(e.Status) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(e.Status, status)
+}
+
+// checkBody returns an error naming the first JSON assertion body fails.
+func (e *WebhookExpectation) checkBody(body []byte) error {
+	if e == nil || len(e.JSON) == 0 {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	paths := make([]string, 0, len(e.JSON))
+	for path := range e.JSON {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths) // report failures deterministically
+
+	for _, path := range paths {
+		var want any
+		_ = json.Unmarshal(e.JSON[path], &want) // checked by validate
+		got, ok := lookupJSONPath(doc, path)
+		if !ok {
+			return fmt.Errorf("response has no %q", path)
+		}
+		if !reflect.DeepEqual(got, want) {
+			gotJSON, _ := json.Marshal(got)
+			return fmt.Errorf("response %q is %s, expected %s", path, gotJSON, e.JSON[path])
+		}
+	}
+	return nil
+}
+
+func lookupJSONPath(doc any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			doc = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			doc = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
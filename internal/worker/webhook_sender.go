@@ -7,17 +7,22 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+	"github.com/lalithlochan/nimbus/internal/secretbox"
 )
 
 // WebhookSender sends notifications via HTTP webhooks
 type WebhookSender struct {
-	client *http.Client
-	logger *zap.Logger
+	client   *http.Client
+	settings ChannelSettingsStore
+	box      *secretbox.Box
+	logger   *zap.Logger
 }
 
 type WebhookConfig struct {
@@ -41,6 +46,14 @@ func NewWebhookSender(logger *zap.Logger, cfg WebhookConfig) *WebhookSender {
 	}
 }
 
+// SetTenantHeaders applies each tenant's webhook user_agent and header set
+// to its deliveries. box opens the sealed header values; it may be nil when
+// no tenant has headers configured.
+func (s *WebhookSender) SetTenantHeaders(settings ChannelSettingsStore, box *secretbox.Box) {
+	s.settings = settings
+	s.box = box
+}
+
 // Send sends a notification via HTTP webhook
 func (s *WebhookSender) Send(ctx context.Context, notif *db.Notification) error {
 	if notif.Channel != db.ChannelWebhook {
@@ -50,12 +63,12 @@ func (s *WebhookSender) Send(ctx context.Context, notif *db.Notification) error
 	// Parse payload
 	var payload WebhookPayload
 	if err := json.Unmarshal(notif.Payload, &payload); err != nil {
-		return fmt.Errorf("invalid webhook payload: %w", err)
+		return payloadErrorf("invalid webhook payload: %w", err)
 	}
 
 	// Validate required fields
 	if payload.URL == "" {
-		return fmt.Errorf("webhook payload missing url")
+		return payloadErrorf("webhook payload missing url")
 	}
 
 	// Set defaults
@@ -65,7 +78,13 @@ func (s *WebhookSender) Send(ctx context.Context, notif *db.Notification) error
 	}
 
 	if method != "POST" && method != "PUT" && method != "PATCH" {
-		return fmt.Errorf("webhook method not supported: %s (only POST, PUT, PATCH)", method)
+		return payloadErrorf("webhook method not supported: %s (only POST, PUT, PATCH)", method)
+	}
+
+	if payload.Expect != nil {
+		if err := payload.Expect.validate(); err != nil {
+			return payloadErrorf("invalid webhook payload: %w", err)
+		}
 	}
 
 	timeout := 30 * time.Second
@@ -79,7 +98,7 @@ func (s *WebhookSender) Send(ctx context.Context, notif *db.Notification) error
 
 	req, err := http.NewRequestWithContext(ctx, method, payload.URL, bytes.NewReader(payload.Body))
 	if err != nil {
-		return fmt.Errorf("failed to create webhook request: %w", err)
+		return payloadErrorf("failed to create webhook request: %w", err)
 	}
 
 	// Set headers
@@ -87,6 +106,15 @@ func (s *WebhookSender) Send(ctx context.Context, notif *db.Notification) error
 	req.Header.Set("User-Agent", "Nimbus/1.0.0")
 	req.Header.Set("X-Nimbus-Notification-ID", notif.ID.String())
 	req.Header.Set("X-Nimbus-Tenant-ID", notif.TenantID.String())
+	if notif.CorrelationID != "" {
+		req.Header.Set("X-Nimbus-Correlation-ID", notif.CorrelationID)
+	}
+
+	// Tenant headers are applied here rather than merged into the payload,
+	// so their secrets never reach the stored or archived notification.
+	if err := s.applyTenantHeaders(ctx, notif, req); err != nil {
+		return err
+	}
 
 	// Add custom headers from payload
 	for key, value := range payload.Headers {
@@ -99,26 +127,99 @@ func (s *WebhookSender) Send(ctx context.Context, notif *db.Notification) error
 		return fmt.Errorf("webhook request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	notif.Annotations = webhookAnnotations(resp)
 
-	// Read response body for logging/debugging
-	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	// Read response body for logging/debugging, and for assertions on it
+	limit := int64(1024)
+	if payload.Expect != nil && len(payload.Expect.JSON) > 0 {
+		limit = maxAssertedBody
+	}
+	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, limit))
+	preview := bodyBytes
+	if len(preview) > 1024 {
+		preview = preview[:1024]
+	}
 
-	// Accept 2xx status codes as success
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned non-2xx status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	// Accept 2xx status codes as success, unless the payload says otherwise
+	if !payload.Expect.statusOK(resp.StatusCode) {
+		if payload.Expect != nil && len(payload.Expect.Status) > 0 {
+			return &webhookStatusError{resp.StatusCode, fmt.Errorf("webhook returned status %d, expected one of %v, body: %s", resp.StatusCode, payload.Expect.Status, string(preview))}
+		}
+		return &webhookStatusError{resp.StatusCode, fmt.Errorf("webhook returned non-2xx status: %d, body: %s", resp.StatusCode, string(preview))}
 	}
+	if err := payload.Expect.checkBody(bodyBytes); err != nil {
+		return fmt.Errorf("webhook response assertion failed (status %d): %w, body: %s", resp.StatusCode, err, string(preview))
+	}
+
+	// Receivers have no common message ID, so only the provider is recorded.
+	notif.Provider = ProviderWebhook
 
-	s.logger.Info("webhook delivered successfully",
-		zap.String("id", notif.ID.String()),
+	observ.Logger(ctx, s.logger).Info("webhook delivered successfully",
 		zap.String("url", payload.URL),
 		zap.Int("status_code", resp.StatusCode),
-		zap.String("response_preview", string(bodyBytes)),
+		zap.String("response_preview", string(preview)),
 	)
 
 	return nil
 }
 
+// unannotatedResponseHeaders are response headers left out of annotations:
+// they carry credentials or session state, not delivery detail.
+var unannotatedResponseHeaders = map[string]bool{
+	"Set-Cookie":         true,
+	"Www-Authenticate":   true,
+	"Proxy-Authenticate": true,
+	"Authorization":      true,
+}
+
+// webhookAnnotations records the receiver's response status and headers,
+// e.g. a request ID to quote to the receiver's operators.
+func webhookAnnotations(resp *http.Response) map[string]any {
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		if !unannotatedResponseHeaders[name] {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return map[string]any{
+		"status_code": resp.StatusCode,
+		"headers":     headers,
+	}
+}
+
 // SupportsChannel checks if this sender supports webhooks
 func (s *WebhookSender) SupportsChannel(channel string) bool {
 	return channel == db.ChannelWebhook
 }
+
+// applyTenantHeaders sets the tenant's configured user agent and headers on
+// req. Payload headers are applied afterwards, so they win.
+func (s *WebhookSender) applyTenantHeaders(ctx context.Context, notif *db.Notification, req *http.Request) error {
+	if s.settings == nil {
+		return nil
+	}
+	record, err := s.settings.GetChannelSettings(ctx, notif.TenantID, db.ChannelWebhook)
+	if err != nil {
+		// A delivery without its auth headers would only be rejected.
+		return fmt.Errorf("load webhook settings: %w", err)
+	}
+	var settings db.WebhookSettings
+	if err := json.Unmarshal(record.Settings, &settings); err != nil {
+		return fmt.Errorf("decode webhook settings: %w", err)
+	}
+
+	if settings.UserAgent != "" {
+		req.Header.Set("User-Agent", settings.UserAgent)
+	}
+	if len(settings.Headers) > 0 && s.box == nil {
+		return fmt.Errorf("tenant webhook headers are configured but no key is set to open them")
+	}
+	for name, sealed := range settings.Headers {
+		value, err := s.box.Open(sealed)
+		if err != nil {
+			return fmt.Errorf("open webhook header %q: %w", name, err)
+		}
+		req.Header.Set(name, value)
+	}
+	return nil
+}
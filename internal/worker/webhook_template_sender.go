@@ -0,0 +1,234 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/observ"
+)
+
+// WebhookTemplateSender wraps a Sender and renders webhook bodies marked
+// "templated": true. Every string in the body may reference variables as
+// {{.name}}, rendered with text/template:
+//
+//	.data            the payload's "template_data" object
+//	.metadata        the notification's metadata
+//	.notification_id, .tenant_id, .user_id, .correlation_id, .channel
+//
+// Rendered values stay JSON strings, so a variable can't break the body's
+// structure, and object keys keep their order. A variable the body uses but
+// the notification lacks fails the send as a payload error rather than
+// delivering a blank.
+//
+// Rendering happens on every attempt and only in memory; the stored
+// payload keeps the template. Bodies without "templated" pass through
+// unchanged, "{{" and all.
+type WebhookTemplateSender struct {
+	inner  Sender
+	logger *zap.Logger
+}
+
+// NewWebhookTemplateSender wraps a sender with webhook body rendering.
+func NewWebhookTemplateSender(inner Sender, logger *zap.Logger) *WebhookTemplateSender {
+	return &WebhookTemplateSender{
+		inner:  inner,
+		logger: logger,
+	}
+}
+
+// Send renders the body, if templated, then delegates to the inner sender.
+func (s *WebhookTemplateSender) Send(ctx context.Context, notif *db.Notification) error {
+	if notif.Channel != db.ChannelWebhook {
+		return s.inner.Send(ctx, notif)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(notif.Payload, &fields); err != nil {
+		return s.inner.Send(ctx, notif)
+	}
+	var templated bool
+	if raw, ok := fields["templated"]; !ok || json.Unmarshal(raw, &templated) != nil || !templated {
+		return s.inner.Send(ctx, notif)
+	}
+
+	vars, err := webhookTemplateVars(notif, fields["template_data"])
+	if err != nil {
+		return err
+	}
+	body, err := renderJSONStrings(fields["body"], func(source string) (string, error) {
+		return renderWebhookString(source, vars)
+	})
+	if err != nil {
+		return payloadErrorf("webhook body template: %w", err)
+	}
+
+	fields["body"] = body
+	delete(fields, "templated")
+	delete(fields, "template_data")
+	var rendered bytes.Buffer
+	enc := json.NewEncoder(&rendered)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(fields); err != nil {
+		return fmt.Errorf("marshal rendered payload: %w", err)
+	}
+	notif.Payload = bytes.TrimSuffix(rendered.Bytes(), []byte("\n"))
+
+	observ.Logger(ctx, s.logger).Debug("rendered webhook body template")
+
+	return s.inner.Send(ctx, notif)
+}
+
+// SupportsChannel delegates to the inner sender.
+func (s *WebhookTemplateSender) SupportsChannel(channel string) bool {
+	return s.inner.SupportsChannel(channel)
+}
+
+// CheckWebhookTemplate reports whether every string in a templated webhook
+// body parses as a template, without rendering it. The validate endpoint
+// uses it to catch syntax errors before a send would.
+func CheckWebhookTemplate(body json.RawMessage) error {
+	_, err := renderJSONStrings(body, func(source string) (string, error) {
+		if strings.Contains(source, "{{") {
+			if _, err := template.New("body").Parse(source); err != nil {
+				return "", err
+			}
+		}
+		return source, nil
+	})
+	return err
+}
+
+// webhookTemplateVars builds the variables a webhook body template sees.
+func webhookTemplateVars(notif *db.Notification, templateData json.RawMessage) (map[string]any, error) {
+	data := map[string]any{}
+	if err := decodeTemplateObject(templateData, &data); err != nil {
+		return nil, payloadErrorf("template_data must be a JSON object")
+	}
+	// Metadata is validated as an object on create.
+	metadata := map[string]any{}
+	_ = decodeTemplateObject(notif.Metadata, &metadata)
+
+	return map[string]any{
+		"data":            data,
+		"metadata":        metadata,
+		"notification_id": notif.ID.String(),
+		"tenant_id":       notif.TenantID.String(),
+		"user_id":         notif.UserID.String(),
+		"correlation_id":  notif.CorrelationID,
+		"channel":         notif.Channel,
+	}, nil
+}
+
+// decodeTemplateObject decodes a JSON object, if raw isn't empty, keeping
+// numbers as written so 1234567 doesn't render as 1.234567e+06.
+func decodeTemplateObject(raw json.RawMessage, v *map[string]any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func renderWebhookString(source string, vars map[string]any) (string, error) {
+	// Most strings have no variables; skip parsing them.
+	if !strings.Contains(source, "{{") {
+		return source, nil
+	}
+	t, err := template.New("body").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// renderJSONStrings rewrites every string value in doc, at any depth, with
+// render. Object keys, numbers, booleans and nulls are copied as they are,
+// in their original order.
+func renderJSONStrings(doc json.RawMessage, render func(string) (string, error)) (json.RawMessage, error) {
+	if len(doc) == 0 {
+		return doc, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	if err := renderJSONValue(dec, &out, render); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("body must be a single JSON value")
+	}
+	return out.Bytes(), nil
+}
+
+func renderJSONValue(dec *json.Decoder, out *bytes.Buffer, render func(string) (string, error)) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		closing := byte(']')
+		if t == '{' {
+			closing = '}'
+		}
+		out.WriteByte(byte(t))
+		for first := true; dec.More(); first = false {
+			if !first {
+				out.WriteByte(',')
+			}
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				writeJSONString(out, key.(string))
+				out.WriteByte(':')
+			}
+			if err := renderJSONValue(dec, out, render); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		out.WriteByte(closing)
+	case string:
+		rendered, err := render(t)
+		if err != nil {
+			return err
+		}
+		writeJSONString(out, rendered)
+	case json.Number:
+		out.WriteString(t.String())
+	case bool:
+		fmt.Fprint(out, t)
+	case nil:
+		out.WriteString("null")
+	}
+	return nil
+}
+
+// writeJSONString writes s as a JSON string without HTML-escaping it, so
+// receivers see "<" rather than "\u003c".
+func writeJSONString(out *bytes.Buffer, s string) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	out.Truncate(out.Len() - 1) // Encode's trailing newline
+}
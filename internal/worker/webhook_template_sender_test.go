@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+func TestWebhookTemplateSender(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		name     string
+		payload  string
+		metadata string
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "variables from data, metadata and the notification",
+			payload:  `{"url":"https://hooks.example.com","templated":true,"template_data":{"order":{"id":12345678}},"body":{"text":"Order {{.data.order.id}} for {{.metadata.plan}}","user":"{{.user_id}}","count":3,"ok":true,"none":null,"list":["{{.channel}}"]}}`,
+			metadata: `{"plan":"pro"}`,
+			wantBody: `{"text":"Order 12345678 for pro","user":"` + userID.String() + `","count":3,"ok":true,"none":null,"list":["webhook"]}`,
+		},
+		{
+			name:     "rendered values can't break the JSON",
+			payload:  `{"url":"https://hooks.example.com","templated":true,"template_data":{"name":"\"},\"admin\":true,\"x\":{\"<b>"},"body":{"name":"{{.data.name}}"}}`,
+			wantBody: `{"name":"\"},\"admin\":true,\"x\":{\"<b>"}`,
+		},
+		{
+			name:     "untemplated body passes through",
+			payload:  `{"url":"https://hooks.example.com","body":{"text":"{{.data.x}}"}}`,
+			wantBody: `{"text":"{{.data.x}}"}`,
+		},
+		{
+			name:    "missing variable",
+			payload: `{"url":"https://hooks.example.com","templated":true,"body":{"text":"{{.data.missing}}"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "bad syntax",
+			payload: `{"url":"https://hooks.example.com","templated":true,"body":{"text":"{{.data.x"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingSender{}
+			s := NewWebhookTemplateSender(inner, zap.NewNop())
+			notif := &db.Notification{
+				ID:       uuid.New(),
+				UserID:   userID,
+				Channel:  db.ChannelWebhook,
+				Payload:  json.RawMessage(tt.payload),
+				Metadata: json.RawMessage(tt.metadata),
+			}
+
+			err := s.Send(context.Background(), notif)
+			if tt.wantErr {
+				if err == nil || failureReason(err) != db.DLQReasonPayloadError {
+					t.Fatalf("expected a payload error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got WebhookPayload
+			if err := json.Unmarshal(inner.payload, &got); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+			if string(got.Body) != tt.wantBody {
+				t.Errorf("expected body %s, got %s", tt.wantBody, got.Body)
+			}
+			if got.URL != "https://hooks.example.com" {
+				t.Errorf("expected the url kept, got %q", got.URL)
+			}
+		})
+	}
+}
+
+func TestCheckWebhookTemplate(t *testing.T) {
+	if err := CheckWebhookTemplate(json.RawMessage(`{"a":["{{.data.x}}"],"b":1}`)); err != nil {
+		t.Errorf("expected a valid template, got %v", err)
+	}
+	if err := CheckWebhookTemplate(json.RawMessage(`{"a":"{{if .data.x}}"}`)); err == nil {
+		t.Error("expected an unterminated action to fail")
+	}
+}
@@ -2,20 +2,49 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/google/uuid"
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/events"
+	"github.com/lalithlochan/nimbus/internal/metrics"
+	"github.com/lalithlochan/nimbus/internal/observ"
+	"github.com/lalithlochan/nimbus/pkg/sender"
 )
 
 type Repository interface {
 	// ClaimPendingNotifications atomically claims a batch (FOR UPDATE SKIP LOCKED),
 	// marking them 'processing' so no other replica can pick the same rows.
 	ClaimPendingNotifications(ctx context.Context, limit int) ([]*db.Notification, error)
+	// ClaimStuckNotifications claims rows left in 'processing' for longer
+	// than olderThan by a worker that died mid-send.
+	ClaimStuckNotifications(ctx context.Context, olderThan time.Duration, limit int) ([]*db.Notification, error)
+	// ClaimNotification claims one pending, due notification on channel, or
+	// returns nil if it isn't claimable (e.g. the poller already sent it).
+	ClaimNotification(ctx context.Context, id uuid.UUID, channel string) (*db.Notification, error)
+	// SyncChannelHolds moves pending rows on killed channels to 'held' and
+	// releases held rows whose channel has been revived.
+	SyncChannelHolds(ctx context.Context) (held, released int64, err error)
 	UpdateNotificationStatus(ctx context.Context, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error
-	MoveToDeadLetter(ctx context.Context, notif *db.Notification, lastError string) (*db.DeadLetterNotification, error)
+	// MarkNotificationSent records a successful send along with the
+	// provider's message ID and archive key the senders set on the
+	// notification and its estimated cost.
+	MarkNotificationSent(ctx context.Context, id uuid.UUID, attempt int, provider, providerMessageID, archiveKey string, cost float64) error
+	// CollapseNotification marks notif sent in place of an earlier delivery
+	// in its group within window, returning that delivery's ID, or returns
+	// nil if there is none.
+	CollapseNotification(ctx context.Context, notif *db.Notification, attempt int, window time.Duration) (*uuid.UUID, error)
+	MoveToDeadLetter(ctx context.Context, notif *db.Notification, reason, lastError string) (*db.DeadLetterNotification, error)
+	// AddNotificationAnnotation stores provider detail the sender set on a
+	// notification.
+	AddNotificationAnnotation(ctx context.Context, a *db.NotificationAnnotation) error
 }
 
 type Worker struct {
@@ -23,12 +52,88 @@ type Worker struct {
 	sender Sender
 	config Config
 	logger *zap.Logger
+
+	// Drain coordination: stop asks the poll loop to exit after the current
+	// batch, done is closed once it has.
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	running  atomic.Bool
+
+	// consumers tracks ConsumeQueue loops, which Shutdown also drains.
+	consumers sync.WaitGroup
+
+	hbMu sync.Mutex
+	hb   Heartbeat
+
+	// tuner sizes each claim when batch tuning is on; only the poll loop
+	// touches it.
+	tuner *batchTuner
 }
 
 type Config struct {
 	PollInterval time.Duration
 	BatchSize    int
 	MaxRetries   int
+
+	// MaxBatchSize, if above BatchSize, turns on batch tuning: the claim
+	// starts at BatchSize and moves between MinBatchSize (default 1) and
+	// MaxBatchSize, growing while batches finish within BatchTarget and
+	// shrinking when one overruns it or too many of its sends fail.
+	// BatchTarget defaults to PollInterval, the point past which batches
+	// start eating into the next poll.
+	MinBatchSize int
+	MaxBatchSize int
+	BatchTarget  time.Duration
+
+	// ReapStuck recovers notifications stuck in 'processing' for longer than
+	// StuckTimeout. It must be well above the longest possible send, or a
+	// slow send gets delivered twice.
+	StuckTimeout time.Duration
+
+	// Instance identifies this worker in published heartbeats.
+	Instance string
+
+	// HeartbeatStaleAfter is how long the loop may go without polling or
+	// finishing a send before Heartbeat().Stale reports it wedged.
+	HeartbeatStaleAfter time.Duration
+
+	// PublishHeartbeat, if set, is called after every poll with the current
+	// heartbeat, e.g. to store it in Redis for other replicas and dashboards.
+	PublishHeartbeat func(ctx context.Context, hb Heartbeat) error
+
+	// Paused, if set, is checked before every poll. While it returns true the
+	// worker claims nothing, so pending rows stay pending (e.g. maintenance mode).
+	Paused func() bool
+
+	// Preferences, if set, is asked first before every send. A positive
+	// delay defers the notification like Throttle (e.g. the category's
+	// quiet hours); optedOut ends it 'failed' with db.OptedOutError without
+	// sending it.
+	Preferences func(ctx context.Context, notif *db.Notification) (delay time.Duration, optedOut bool)
+
+	// Throttle, if set, is asked before every send. A positive delay puts
+	// the notification back to pending for that long without using up an
+	// attempt (e.g. a tenant throttled for poor sender reputation).
+	Throttle func(ctx context.Context, notif *db.Notification) time.Duration
+
+	// FrequencyCap, if set, is asked before every send that isn't
+	// throttled or collapsed. A positive delay defers the notification
+	// like Throttle; drop ends it 'frequency_capped' without sending it.
+	FrequencyCap func(ctx context.Context, notif *db.Notification) (delay time.Duration, drop bool)
+
+	// GroupWindow is how long after a delivery in a group (see
+	// db.Notification.GroupKey) later notifications in it are collapsed
+	// into it instead of being sent. Zero sends every notification.
+	GroupWindow time.Duration
+
+	// Pricing estimates the cost of each successful send, which is stored
+	// on the notification and added to the tenant's daily usage.
+	Pricing Pricing
+
+	// Events, if set, receives a lifecycle event for every send, failed
+	// attempt and dead-lettering.
+	Events events.Emitter
 }
 
 // New creates a worker with default config values.
@@ -43,16 +148,53 @@ func New(repo Repository, sender Sender, cfg Config, logger *zap.Logger) *Worker
 	if cfg.MaxRetries == 0 {
 		cfg.MaxRetries = 3
 	}
+	if cfg.StuckTimeout == 0 {
+		cfg.StuckTimeout = 5 * time.Minute
+	}
+	if cfg.HeartbeatStaleAfter == 0 {
+		cfg.HeartbeatStaleAfter = 2 * time.Minute
+	}
 
-	return &Worker{
+	w := &Worker{
 		repo:   repo,
 		sender: sender,
 		config: cfg,
 		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		hb:     Heartbeat{Instance: cfg.Instance, StartedAt: time.Now()},
+	}
+	if cfg.MaxBatchSize > cfg.BatchSize {
+		w.tuner = &batchTuner{
+			size:   cfg.BatchSize,
+			min:    min(max(cfg.MinBatchSize, 1), cfg.BatchSize),
+			max:    cfg.MaxBatchSize,
+			target: cfg.BatchTarget,
+		}
+		if w.tuner.target <= 0 {
+			w.tuner.target = cfg.PollInterval
+		}
 	}
+	return w
 }
 
+// statusWriteTimeout bounds the final status write after a send. That write
+// deliberately ignores cancellation of the worker context: once a provider
+// has accepted a message we must record it, or the row is reclaimed later
+// and the user gets it twice.
+const statusWriteTimeout = 5 * time.Second
+
+// frequencyCappedError is the error message of a notification dropped by
+// a frequency cap.
+const frequencyCappedError = "frequency cap reached: the user has had as many notifications on this channel as the tenant allows"
+
+// Start runs the poll loop until ctx is cancelled (hard stop) or Shutdown is
+// called (drain). Batches run synchronously inside the loop, so when the
+// stop signal is observed no batch is in flight.
 func (w *Worker) Start(ctx context.Context) {
+	w.running.Store(true)
+	defer close(w.done)
+
 	ticker := time.NewTicker(w.config.PollInterval)
 	defer ticker.Stop()
 
@@ -61,73 +203,548 @@ func (w *Worker) Start(ctx context.Context) {
 		case <-ctx.Done():
 			w.logger.Info("worker stopping")
 			return
+		case <-w.stop:
+			w.logger.Info("worker drained, stopping")
+			return
 		case <-ticker.C:
+			// select picks randomly among ready cases, so re-check the stop
+			// signal before claiming another batch.
+			if w.draining() {
+				continue
+			}
+			paused := w.config.Paused != nil && w.config.Paused()
+			w.markPoll(time.Now(), paused)
+			if paused {
+				w.logger.Debug("worker paused, skipping poll")
+				w.publishHeartbeat(ctx)
+				continue
+			}
 			w.logger.Debug("checking for notifications",
-				zap.Int("batch_size", w.config.BatchSize),
+				zap.Int("batch_size", w.batchSize()),
 			)
 			w.processBatch(ctx)
+			w.publishHeartbeat(ctx)
 		}
 	}
 }
 
+// Shutdown drains the worker: it stops claiming new notifications and waits
+// for the batch in flight, and those of any ConsumeQueue loops, to finish
+// sending and persist their statuses. It returns ctx.Err() if they do not
+// finish before ctx expires; the caller should then cancel the context
+// passed to Start to abort the sends.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	drained := make(chan struct{})
+	go func() {
+		if w.running.Load() {
+			<-w.done
+		}
+		w.consumers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) draining() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
 func (w *Worker) processBatch(ctx context.Context) {
 	// Atomically claim a batch. Each replica gets a disjoint set of rows
 	// (FOR UPDATE SKIP LOCKED), so we can scale workers horizontally without
-	// double-sending. The claim also reclaims rows stranded by crashed workers.
-	notifications, err := w.repo.ClaimPendingNotifications(ctx, w.config.BatchSize)
+	// double-sending. Rows stranded in 'processing' by crashed workers are
+	// not ours to take here; ReapStuck recovers them.
+	w.syncChannelHolds(ctx)
+
+	start := time.Now()
+	notifications, err := w.repo.ClaimPendingNotifications(ctx, w.batchSize())
 	if err != nil {
 		w.logger.Error("failed to claim pending notifications", zap.Error(err))
 		return
 	}
+	failed := 0
+	defer func() {
+		took := time.Since(start)
+		w.markBatch(len(notifications), took)
+		w.tuneBatch(len(notifications), failed, took)
+	}()
 	if len(notifications) == 0 {
 		return
 	}
 	// Loop through each notification from the list of notifications
 	for _, notif := range notifications {
-		// Process each notification
-		w.processNotification(ctx, notif)
+		// Process each notification. A panic in one must not take down the
+		// poll loop (and with it every other notification in the queue).
+		if w.processNotificationSafely(ctx, notif) {
+			failed++
+		}
 	}
+	// Whatever we claimed is sent before returning, even if Shutdown was
+	// called meanwhile. Abandoning claimed rows would strand them in
+	// 'processing' until ReapStuck recovers them.
 }
 
-func (w *Worker) processNotification(ctx context.Context, notif *db.Notification) {
+// batchSize is how many notifications the next poll claims.
+func (w *Worker) batchSize() int {
+	if w.tuner == nil {
+		return w.config.BatchSize
+	}
+	return w.tuner.size
+}
+
+// tuneBatch feeds a finished batch to the tuner, if batch tuning is on,
+// and records the size the next poll claims.
+func (w *Worker) tuneBatch(claimed, failed int, took time.Duration) {
+	if w.tuner == nil {
+		metrics.SetWorkerBatchSize(w.config.BatchSize)
+		return
+	}
+	prev := w.tuner.size
+	next := w.tuner.observe(claimed, failed, took)
+	metrics.SetWorkerBatchSize(next)
+	if next != prev {
+		w.logger.Debug("worker batch size tuned",
+			zap.Int("from", prev),
+			zap.Int("to", next),
+			zap.Int("claimed", claimed),
+			zap.Int("failed", failed),
+			zap.Duration("took", took),
+		)
+	}
+}
+
+// syncChannelHolds applies channel kill switches before the claim. The claim
+// skips killed channels on its own, so a failure here only delays moving
+// rows to 'held' and is not worth skipping the batch for.
+func (w *Worker) syncChannelHolds(ctx context.Context) {
+	held, released, err := w.repo.SyncChannelHolds(ctx)
+	if err != nil {
+		w.logger.Warn("failed to sync channel holds", zap.Error(err))
+		return
+	}
+	if held > 0 || released > 0 {
+		w.logger.Info("channel holds updated",
+			zap.Int64("held", held),
+			zap.Int64("released", released),
+		)
+	}
+}
+
+// ReapStuck recovers notifications whose worker crashed after claiming them.
+// The crash counts as a failed attempt: the row is rescheduled like any other
+// failure, or dead-lettered once out of attempts, so a notification that
+// kills its worker every time can't loop forever. It is run periodically by
+// the jobs runner, and does nothing while the worker is paused.
+func (w *Worker) ReapStuck(ctx context.Context) error {
+	if w.config.Paused != nil && w.config.Paused() {
+		return nil
+	}
+
+	stuck, err := w.repo.ClaimStuckNotifications(ctx, w.config.StuckTimeout, w.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("claim stuck notifications: %w", err)
+	}
+
+	for _, notif := range stuck {
+		ctx := observ.With(ctx, w.logger,
+			zap.String(observ.FieldNotificationID, notif.ID.String()),
+			zap.String(observ.FieldTenantID, notif.TenantID.String()),
+			zap.String(observ.FieldCorrelationID, notif.CorrelationID),
+		)
+		metrics.RecordNotificationReaped(notif.Channel)
+		observ.Logger(ctx, w.logger).Warn("reaped notification stuck in processing",
+			zap.String("channel", notif.Channel),
+			zap.Int("attempt", notif.Attempt+1),
+		)
+
+		persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+		w.handleFailure(persistCtx, notif, notif.Attempt+1,
+			fmt.Errorf("stuck in processing for over %s; worker presumed crashed", w.config.StuckTimeout))
+		cancel()
+	}
+	return nil
+}
+
+// processNotificationSafely runs processNotification with panic isolation.
+// A panicking sender is treated like a failed send: the notification is
+// scheduled for retry (or dead-lettered once out of attempts), the panic is
+// counted in nimbus_worker_panics_total, and the loop carries on. It
+// reports whether the send failed.
+func (w *Worker) processNotificationSafely(ctx context.Context, notif *db.Notification) (failed bool) {
+	// Everything logged for this notification, here and in the senders and
+	// repository, carries its IDs.
+	ctx = observ.With(ctx, w.logger,
+		zap.String(observ.FieldNotificationID, notif.ID.String()),
+		zap.String(observ.FieldTenantID, notif.TenantID.String()),
+		zap.String(observ.FieldCorrelationID, notif.CorrelationID),
+	)
+
+	var state sendState
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		metrics.RecordWorkerPanic(notif.Channel)
+		observ.Logger(ctx, w.logger).Error("recovered panic while processing notification",
+			zap.Any("panic", r),
+			zap.String("channel", notif.Channel),
+			zap.Bool("sent", state != sendPending),
+			zap.Stack("stack"),
+		)
+
+		persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+		defer cancel()
+		switch state {
+		case sendPending:
+			failed = true
+			w.markProgress(false)
+			w.handleFailure(persistCtx, notif, notif.Attempt+1, fmt.Errorf("worker panic: %v", r))
+		case sendAccepted:
+			// The provider took the message; retrying would deliver it twice.
+			// Record the send without a cost, since pricing may be what panicked.
+			failed = false
+			w.markProgress(true)
+			_ = w.repo.MarkNotificationSent(persistCtx, notif.ID, notif.Attempt+1, notif.Provider, notif.ProviderMessageID, notif.ArchiveKey, 0)
+		case sendRecorded:
+			failed = false
+		}
+	}()
+
+	return w.processNotification(ctx, notif, &state)
+}
+
+// sendState is how far processNotification got, so a panic is only retried
+// when the message hasn't left.
+type sendState int
+
+const (
+	sendPending  sendState = iota // not yet accepted by the provider
+	sendAccepted                  // accepted, not yet recorded as sent
+	sendRecorded                  // recorded as sent
+)
+
+// processNotification sends notif and records the outcome, tracking its
+// progress in state. It reports whether the send failed; an opted-out,
+// throttled, collapsed or capped notification hasn't.
+func (w *Worker) processNotification(ctx context.Context, notif *db.Notification, state *sendState) bool {
+	if w.applyPreferences(ctx, notif) {
+		return false
+	}
+	if w.deferThrottled(ctx, notif) {
+		return false
+	}
+	if w.collapseGrouped(ctx, notif) {
+		return false
+	}
+	if w.capFrequency(ctx, notif) {
+		return false
+	}
+
 	// The row was already atomically marked 'processing' by ClaimPendingNotifications,
 	// so we go straight to sending — no extra status write needed here.
 	err := w.sender.Send(ctx, notif)
 	newAttempt := notif.Attempt + 1
+	if err == nil {
+		*state = sendAccepted
+	}
+
+	persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+	defer cancel()
+	w.recordAnnotations(persistCtx, notif, newAttempt)
 
 	if err != nil {
-		w.logger.Error("failed to send notification",
+		observ.Logger(ctx, w.logger).Error("failed to send notification",
 			zap.Error(err),
-			zap.String("notification_id", notif.ID.String()),
 			zap.String("channel", notif.Channel),
 			zap.Int("attempt", newAttempt),
 		)
 
-		errMsg := err.Error()
-
-		if newAttempt >= w.config.MaxRetries {
-			// Max retries reached, move to dead letter queue
-			_, dlqErr := w.repo.MoveToDeadLetter(ctx, notif, errMsg)
-			if dlqErr != nil {
-				w.logger.Error("failed to move notification to dead letter queue",
-					zap.String("id", notif.ID.String()),
-					zap.Error(dlqErr),
-				)
-			} else {
-				w.logger.Info("notification moved to dead letter queue",
-					zap.String("id", notif.ID.String()),
-					zap.Int("attempts", newAttempt),
-				)
-			}
-		} else {
-			nextRetry := w.calculateNextRetry(newAttempt)
-			_ = w.repo.UpdateNotificationStatus(ctx, notif.ID, "pending", newAttempt, &errMsg, &nextRetry)
-		}
+		w.markProgress(false)
+		w.handleFailure(persistCtx, notif, newAttempt, err)
 	} else {
-		w.logger.Info("notification sent",
-			zap.String("id", notif.ID.String()),
+		// Recorded before anything else runs, so a panic in the work after
+		// it can't get the notification sent again.
+		cost := w.config.Pricing.Cost(notif)
+		_ = w.repo.MarkNotificationSent(persistCtx, notif.ID, newAttempt, notif.Provider, notif.ProviderMessageID, notif.ArchiveKey, cost)
+		*state = sendRecorded
+
+		w.markProgress(true)
+		metrics.RecordDeliveryCost(notif.TenantID.String(), notif.Channel, notif.Provider, cost)
+		observ.Logger(ctx, w.logger).Info("notification sent",
+			zap.String("provider", notif.Provider),
+			zap.String("provider_message_id", notif.ProviderMessageID),
+			zap.Float64("cost", cost),
+		)
+		w.recordDeliveryLatency(ctx, notif)
+
+		event := events.New(events.TypeSent, notif)
+		event.Attempt = newAttempt
+		w.emit(event)
+	}
+	return err != nil
+}
+
+// recordAnnotations stores the provider detail the sender set on notif, if
+// any, whether or not the send succeeded: a failed webhook's response is
+// often the most useful part of the timeline. Losing it doesn't change the
+// outcome, so errors are only logged.
+func (w *Worker) recordAnnotations(ctx context.Context, notif *db.Notification, attempt int) {
+	if len(notif.Annotations) == 0 {
+		return
+	}
+	data, err := json.Marshal(notif.Annotations)
+	if err == nil {
+		// Senders set Provider only on success; fall back to the channel,
+		// which is what the single-provider channels record anyway.
+		provider := notif.Provider
+		if provider == "" {
+			provider = notif.Channel
+		}
+		err = w.repo.AddNotificationAnnotation(ctx, &db.NotificationAnnotation{
+			NotificationID: notif.ID,
+			Provider:       provider,
+			Kind:           db.AnnotationKindSend,
+			Attempt:        &attempt,
+			Data:           data,
+		})
+	}
+	if err != nil {
+		observ.Logger(ctx, w.logger).Warn("failed to record provider annotations",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+		)
+	}
+}
+
+// recordDeliveryLatency records the time from creation to delivery and, for
+// notifications with an SLA, whether it was met. The repository sets the
+// stored sla_breached flag by the same rule.
+func (w *Worker) recordDeliveryLatency(ctx context.Context, notif *db.Notification) {
+	latency := time.Since(notif.CreatedAt)
+	metrics.RecordNotificationLatency(notif.Channel, latency)
+	if notif.SLASeconds == nil {
+		return
+	}
+
+	sla := time.Duration(*notif.SLASeconds) * time.Second
+	breached := latency > sla
+	metrics.RecordNotificationSLA(notif.TenantID.String(), notif.Channel, breached)
+	if breached {
+		observ.Logger(ctx, w.logger).Warn("notification delivered after its SLA",
+			zap.Duration("latency", latency),
+			zap.Duration("sla", sla),
 		)
-		_ = w.repo.UpdateNotificationStatus(ctx, notif.ID, "sent", newAttempt, nil, nil)
+	}
+}
+
+// collapseGrouped collapses notif into an earlier delivery in its group
+// within Config.GroupWindow, if there was one, instead of sending it. If
+// the lookup fails the notification is sent: a duplicate is better than a
+// lost alert.
+func (w *Worker) collapseGrouped(ctx context.Context, notif *db.Notification) bool {
+	if notif.GroupKey == "" || w.config.GroupWindow <= 0 {
+		return false
+	}
+
+	persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+	defer cancel()
+	newAttempt := notif.Attempt + 1
+	into, err := w.repo.CollapseNotification(persistCtx, notif, newAttempt, w.config.GroupWindow)
+	if err != nil {
+		observ.Logger(ctx, w.logger).Warn("failed to check notification group, sending",
+			zap.Error(err),
+		)
+		return false
+	}
+	if into == nil {
+		return false
+	}
+
+	w.markProgress(true)
+	metrics.RecordNotificationCollapsed(notif.Channel)
+	observ.Logger(ctx, w.logger).Info("notification collapsed into its group",
+		zap.String("group_key", notif.GroupKey),
+		zap.String("collapsed_into", into.String()),
+	)
+	notif.CollapsedInto = into
+	event := events.New(events.TypeCollapsed, notif)
+	event.Attempt = newAttempt
+	w.emit(event)
+	return true
+}
+
+// applyPreferences defers or drops notif if Config.Preferences says its
+// user doesn't want it now or at all. It runs before the send limits, so
+// a notification that won't be sent doesn't count against them.
+func (w *Worker) applyPreferences(ctx context.Context, notif *db.Notification) bool {
+	if w.config.Preferences == nil {
+		return false
+	}
+	delay, optedOut := w.config.Preferences(ctx, notif)
+	if !optedOut && delay <= 0 {
+		return false
+	}
+
+	persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+	defer cancel()
+	if !optedOut {
+		metrics.RecordNotificationPreference(notif.Channel, "quiet_hours")
+		observ.Logger(ctx, w.logger).Debug("category in quiet hours, deferring send",
+			zap.String("channel", notif.Channel),
+			zap.String("category", notif.Category),
+			zap.Duration("delay", delay),
+		)
+		next := time.Now().Add(delay)
+		_ = w.repo.UpdateNotificationStatus(persistCtx, notif.ID, db.StatusPending, notif.Attempt, notif.ErrorMessage, &next)
+		return true
+	}
+
+	errMsg := db.OptedOutError
+	if err := w.repo.UpdateNotificationStatus(persistCtx, notif.ID, db.StatusFailed, notif.Attempt, &errMsg, nil); err != nil {
+		observ.Logger(ctx, w.logger).Error("failed to mark notification opted out",
+			zap.Error(err),
+		)
+		return true
+	}
+
+	w.markProgress(true)
+	metrics.RecordNotificationPreference(notif.Channel, "opted_out")
+	observ.Logger(ctx, w.logger).Info("user opted out of category, notification dropped",
+		zap.String("channel", notif.Channel),
+		zap.String("category", notif.Category),
+	)
+	event := events.New(events.TypeOptedOut, notif)
+	event.Error = errMsg
+	w.emit(event)
+	return true
+}
+
+// deferThrottled puts notif back to pending if Config.Throttle says it has
+// to wait. The attempt count and last error are left untouched: a throttled
+// send hasn't failed.
+func (w *Worker) deferThrottled(ctx context.Context, notif *db.Notification) bool {
+	if w.config.Throttle == nil {
+		return false
+	}
+	delay := w.config.Throttle(ctx, notif)
+	if delay <= 0 {
+		return false
+	}
+
+	metrics.RecordNotificationThrottled(notif.Channel)
+	observ.Logger(ctx, w.logger).Debug("tenant throttled, deferring send",
+		zap.String("channel", notif.Channel),
+		zap.Duration("delay", delay),
+	)
+
+	persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+	defer cancel()
+	next := time.Now().Add(delay)
+	_ = w.repo.UpdateNotificationStatus(persistCtx, notif.ID, db.StatusPending, notif.Attempt, notif.ErrorMessage, &next)
+	return true
+}
+
+// capFrequency defers or drops notif if Config.FrequencyCap says its user
+// has had enough on the channel. Like a throttled send, a deferred one
+// keeps its attempt count.
+func (w *Worker) capFrequency(ctx context.Context, notif *db.Notification) bool {
+	if w.config.FrequencyCap == nil {
+		return false
+	}
+	delay, drop := w.config.FrequencyCap(ctx, notif)
+	if !drop && delay <= 0 {
+		return false
+	}
+
+	persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusWriteTimeout)
+	defer cancel()
+	if !drop {
+		metrics.RecordNotificationFrequencyCapped(notif.Channel, db.FrequencyCapDefer)
+		observ.Logger(ctx, w.logger).Debug("user over frequency cap, deferring send",
+			zap.String("channel", notif.Channel),
+			zap.Duration("delay", delay),
+		)
+		next := time.Now().Add(delay)
+		_ = w.repo.UpdateNotificationStatus(persistCtx, notif.ID, db.StatusPending, notif.Attempt, notif.ErrorMessage, &next)
+		return true
+	}
+
+	errMsg := frequencyCappedError
+	if err := w.repo.UpdateNotificationStatus(persistCtx, notif.ID, db.StatusFrequencyCapped, notif.Attempt, &errMsg, nil); err != nil {
+		observ.Logger(ctx, w.logger).Error("failed to mark notification frequency capped",
+			zap.Error(err),
+		)
+		return true
+	}
+
+	w.markProgress(true)
+	metrics.RecordNotificationFrequencyCapped(notif.Channel, db.FrequencyCapDrop)
+	observ.Logger(ctx, w.logger).Info("user over frequency cap, notification dropped",
+		zap.String("channel", notif.Channel),
+	)
+	event := events.New(events.TypeFrequencyCapped, notif)
+	event.Error = errMsg
+	w.emit(event)
+	return true
+}
+
+// handleFailure schedules a retry, or moves the notification to the dead
+// letter queue, classified by failureReason, once it has used up MaxRetries.
+// A send to an invalidated recipient, or one a sender marked permanent, is
+// dead-lettered at once, since no retry can succeed.
+func (w *Worker) handleFailure(ctx context.Context, notif *db.Notification, newAttempt int, sendErr error) {
+	errMsg := sendErr.Error()
+	if newAttempt >= w.config.MaxRetries || errors.Is(sendErr, ErrRecipientInvalid) || errors.Is(sendErr, sender.ErrPermanent) {
+		// Max retries reached, move to dead letter queue
+		reason := failureReason(sendErr)
+		_, dlqErr := w.repo.MoveToDeadLetter(ctx, notif, reason, errMsg)
+		if dlqErr != nil {
+			observ.Logger(ctx, w.logger).Error("failed to move notification to dead letter queue",
+				zap.Error(dlqErr),
+			)
+		} else {
+			metrics.RecordDeadLettered(notif.Channel, reason)
+			observ.Logger(ctx, w.logger).Info("notification moved to dead letter queue",
+				zap.Int("attempts", newAttempt),
+				zap.String("reason", reason),
+			)
+			if notif.SLASeconds != nil {
+				metrics.RecordNotificationSLA(notif.TenantID.String(), notif.Channel, true)
+			}
+			event := events.New(events.TypeDeadLettered, notif)
+			event.Attempt, event.Error, event.NextRetryAt = newAttempt, errMsg, nil
+			w.emit(event)
+		}
+		return
+	}
+
+	nextRetry := w.calculateNextRetry(newAttempt)
+	_ = w.repo.UpdateNotificationStatus(ctx, notif.ID, "pending", newAttempt, &errMsg, &nextRetry)
+
+	event := events.New(events.TypeFailed, notif)
+	event.Attempt, event.Error, event.NextRetryAt = newAttempt, errMsg, &nextRetry
+	w.emit(event)
+}
+
+// emit hands event to Config.Events, if set.
+func (w *Worker) emit(event events.Detail) {
+	if w.config.Events != nil {
+		w.config.Events.Emit(event)
 	}
 }
 
@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -10,12 +11,26 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/lalithlochan/nimbus/internal/db"
+	"github.com/lalithlochan/nimbus/internal/events"
 )
 
 type MockRepository struct {
 	notifications []*db.Notification
+	stuck         []*db.Notification
 	updateCalls   []updateCall
-	shouldFail    bool
+	holdSyncs     int
+	holdSyncErr   error
+
+	sentProvider          string
+	sentProviderMessageID string
+	dlqReason             string
+	shouldFail            bool
+
+	// groupLeader is what CollapseNotification collapses into, if set.
+	groupLeader *uuid.UUID
+	collapseErr error
+
+	annotations []*db.NotificationAnnotation
 }
 
 type updateCall struct {
@@ -35,6 +50,34 @@ func (m *MockRepository) ClaimPendingNotifications(ctx context.Context, limit in
 	return m.notifications, nil
 }
 
+func (m *MockRepository) ClaimStuckNotifications(ctx context.Context, olderThan time.Duration, limit int) ([]*db.Notification, error) {
+	if m.shouldFail {
+		return nil, errors.New("database error")
+	}
+	if len(m.stuck) > limit {
+		return m.stuck[:limit], nil
+	}
+	return m.stuck, nil
+}
+
+func (m *MockRepository) ClaimNotification(ctx context.Context, id uuid.UUID, channel string) (*db.Notification, error) {
+	if m.shouldFail {
+		return nil, errors.New("database error")
+	}
+	for i, n := range m.notifications {
+		if n.ID == id && n.Channel == channel {
+			m.notifications = append(m.notifications[:i:i], m.notifications[i+1:]...)
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) SyncChannelHolds(ctx context.Context) (int64, int64, error) {
+	m.holdSyncs++
+	return 0, 0, m.holdSyncErr
+}
+
 func (m *MockRepository) UpdateNotificationStatus(ctx context.Context, id uuid.UUID, status string, attempt int, errorMsg *string, nextRetryAt *time.Time) error {
 	if m.shouldFail {
 		return errors.New("database error")
@@ -43,11 +86,30 @@ func (m *MockRepository) UpdateNotificationStatus(ctx context.Context, id uuid.U
 	return nil
 }
 
-func (m *MockRepository) MoveToDeadLetter(ctx context.Context, notif *db.Notification, lastError string) (*db.DeadLetterNotification, error) {
+func (m *MockRepository) MarkNotificationSent(ctx context.Context, id uuid.UUID, attempt int, provider, providerMessageID, archiveKey string, cost float64) error {
+	if m.shouldFail {
+		return errors.New("database error")
+	}
+	m.updateCalls = append(m.updateCalls, updateCall{id, db.StatusSent, attempt, nil})
+	m.sentProvider = provider
+	m.sentProviderMessageID = providerMessageID
+	return nil
+}
+
+func (m *MockRepository) CollapseNotification(ctx context.Context, notif *db.Notification, attempt int, window time.Duration) (*uuid.UUID, error) {
+	if m.collapseErr != nil || m.groupLeader == nil {
+		return nil, m.collapseErr
+	}
+	m.updateCalls = append(m.updateCalls, updateCall{notif.ID, db.StatusSent, attempt, nil})
+	return m.groupLeader, nil
+}
+
+func (m *MockRepository) MoveToDeadLetter(ctx context.Context, notif *db.Notification, reason, lastError string) (*db.DeadLetterNotification, error) {
 	if m.shouldFail {
 		return nil, errors.New("database error")
 	}
 	m.updateCalls = append(m.updateCalls, updateCall{notif.ID, db.StatusDeadLettered, notif.Attempt + 1, &lastError})
+	m.dlqReason = reason
 	return &db.DeadLetterNotification{
 		ID:                     uuid.New(),
 		OriginalNotificationID: notif.ID,
@@ -55,16 +117,28 @@ func (m *MockRepository) MoveToDeadLetter(ctx context.Context, notif *db.Notific
 	}, nil
 }
 
+func (m *MockRepository) AddNotificationAnnotation(ctx context.Context, a *db.NotificationAnnotation) error {
+	m.annotations = append(m.annotations, a)
+	return nil
+}
+
 type MockSender struct {
-	shouldFail bool
-	sendCalls  int
+	shouldFail  bool
+	sendCalls   int
+	messageID   string
+	annotations map[string]any
 }
 
 func (m *MockSender) Send(ctx context.Context, notif *db.Notification) error {
 	m.sendCalls++
+	notif.Annotations = m.annotations
 	if m.shouldFail {
 		return errors.New("send failed")
 	}
+	if m.messageID != "" {
+		notif.Provider = ProviderSES
+		notif.ProviderMessageID = m.messageID
+	}
 	return nil
 }
 
@@ -86,7 +160,7 @@ func TestWorker_ProcessNotification_Success(t *testing.T) {
 		Attempt: 0,
 	}
 
-	w.processNotification(context.Background(), notif)
+	w.processNotificationSafely(context.Background(), notif)
 
 	if sender.sendCalls != 1 {
 		t.Errorf("expected 1 send call, got %d", sender.sendCalls)
@@ -108,6 +182,162 @@ func TestWorker_ProcessNotification_Success(t *testing.T) {
 	}
 }
 
+func TestWorker_ProcessNotification_PersistsProviderMessageID(t *testing.T) {
+	repo := &MockRepository{}
+	sender := &MockSender{messageID: "0100018c-ses-message-id"}
+
+	w := New(repo, sender, Config{MaxRetries: 3}, zap.NewNop())
+	w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Status: "pending"})
+
+	if repo.sentProvider != ProviderSES || repo.sentProviderMessageID != "0100018c-ses-message-id" {
+		t.Errorf("expected ses message id persisted, got %q/%q", repo.sentProvider, repo.sentProviderMessageID)
+	}
+}
+
+func TestWorker_ProcessNotification_RecordsAnnotations(t *testing.T) {
+	repo := &MockRepository{}
+	sender := &MockSender{shouldFail: true, annotations: map[string]any{"status_code": 503}}
+
+	w := New(repo, sender, Config{MaxRetries: 3}, zap.NewNop())
+	notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelWebhook, Status: "pending", Attempt: 1}
+	w.processNotificationSafely(context.Background(), notif)
+
+	if len(repo.annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(repo.annotations))
+	}
+	a := repo.annotations[0]
+	if a.NotificationID != notif.ID || a.Provider != db.ChannelWebhook || a.Kind != db.AnnotationKindSend {
+		t.Errorf("unexpected annotation %+v", a)
+	}
+	if a.Attempt == nil || *a.Attempt != 2 {
+		t.Errorf("expected attempt 2, got %v", a.Attempt)
+	}
+	if string(a.Data) != `{"status_code":503}` {
+		t.Errorf("unexpected data %s", a.Data)
+	}
+
+	// Nothing is recorded when the sender sets no annotations.
+	repo.annotations = nil
+	sender.annotations = nil
+	w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Status: "pending"})
+	if len(repo.annotations) != 0 {
+		t.Errorf("expected no annotations, got %d", len(repo.annotations))
+	}
+}
+
+func TestWorker_ProcessNotification_Throttled(t *testing.T) {
+	repo := &MockRepository{}
+	sender := &MockSender{}
+	lastErr := "previous failure"
+
+	w := New(repo, sender, Config{
+		MaxRetries: 3,
+		Throttle: func(ctx context.Context, notif *db.Notification) time.Duration {
+			return 30 * time.Second
+		},
+	}, zap.NewNop())
+	w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Attempt: 1, ErrorMessage: &lastErr})
+
+	if sender.sendCalls != 0 {
+		t.Errorf("expected no send while throttled, got %d", sender.sendCalls)
+	}
+	if len(repo.updateCalls) != 1 {
+		t.Fatalf("expected 1 update call, got %d", len(repo.updateCalls))
+	}
+	call := repo.updateCalls[0]
+	if call.status != db.StatusPending || call.attempt != 1 || call.errorMsg != &lastErr {
+		t.Errorf("expected pending with attempt and error unchanged, got %+v", call)
+	}
+}
+
+func TestWorker_ProcessNotification_FrequencyCapped(t *testing.T) {
+	tests := []struct {
+		name       string
+		delay      time.Duration
+		drop       bool
+		wantStatus string
+	}{
+		{name: "deferred", delay: 10 * time.Minute, wantStatus: db.StatusPending},
+		{name: "dropped", drop: true, wantStatus: db.StatusFrequencyCapped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			sender := &MockSender{}
+
+			w := New(repo, sender, Config{
+				MaxRetries: 3,
+				FrequencyCap: func(ctx context.Context, notif *db.Notification) (time.Duration, bool) {
+					return tt.delay, tt.drop
+				},
+			}, zap.NewNop())
+			failed := w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Attempt: 1})
+
+			if failed {
+				t.Error("expected a capped notification not to count as a failed send")
+			}
+			if sender.sendCalls != 0 {
+				t.Errorf("expected no send over the cap, got %d", sender.sendCalls)
+			}
+			if len(repo.updateCalls) != 1 {
+				t.Fatalf("expected 1 update call, got %d", len(repo.updateCalls))
+			}
+			call := repo.updateCalls[0]
+			if call.status != tt.wantStatus || call.attempt != 1 {
+				t.Errorf("expected %s with the attempt unchanged, got %+v", tt.wantStatus, call)
+			}
+			if (call.errorMsg != nil) != tt.drop {
+				t.Errorf("expected an error message only when dropped, got %v", call.errorMsg)
+			}
+		})
+	}
+}
+
+func TestWorker_ProcessNotification_Preferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		delay      time.Duration
+		optedOut   bool
+		wantStatus string
+	}{
+		{name: "quiet hours", delay: time.Hour, wantStatus: db.StatusPending},
+		{name: "opted out", optedOut: true, wantStatus: db.StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			sender := &MockSender{}
+
+			w := New(repo, sender, Config{
+				MaxRetries: 3,
+				Preferences: func(ctx context.Context, notif *db.Notification) (time.Duration, bool) {
+					return tt.delay, tt.optedOut
+				},
+			}, zap.NewNop())
+			failed := w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Category: "marketing", Attempt: 1})
+
+			if failed {
+				t.Error("expected a held notification not to count as a failed send")
+			}
+			if sender.sendCalls != 0 {
+				t.Errorf("expected no send, got %d", sender.sendCalls)
+			}
+			if len(repo.updateCalls) != 1 {
+				t.Fatalf("expected 1 update call, got %d", len(repo.updateCalls))
+			}
+			call := repo.updateCalls[0]
+			if call.status != tt.wantStatus || call.attempt != 1 {
+				t.Errorf("expected %s with the attempt unchanged, got %+v", tt.wantStatus, call)
+			}
+			if tt.optedOut && (call.errorMsg == nil || *call.errorMsg != db.OptedOutError) {
+				t.Errorf("expected error %q, got %v", db.OptedOutError, call.errorMsg)
+			}
+		})
+	}
+}
+
 func TestWorker_ProcessNotification_FailWithRetry(t *testing.T) {
 	notifID := uuid.New()
 	repo := &MockRepository{}
@@ -122,7 +352,7 @@ func TestWorker_ProcessNotification_FailWithRetry(t *testing.T) {
 		Attempt: 0,
 	}
 
-	w.processNotification(context.Background(), notif)
+	w.processNotificationSafely(context.Background(), notif)
 
 	// One terminal update: back to 'pending' for retry.
 	if len(repo.updateCalls) != 1 {
@@ -151,7 +381,7 @@ func TestWorker_ProcessNotification_FailMaxRetries(t *testing.T) {
 		Attempt: 2, // Already tried twice
 	}
 
-	w.processNotification(context.Background(), notif)
+	w.processNotificationSafely(context.Background(), notif)
 
 	// After the 'processing' mark moved into ClaimPendingNotifications, the only
 	// update recorded here is the DLQ move (via MoveToDeadLetter) after max retries.
@@ -161,6 +391,9 @@ func TestWorker_ProcessNotification_FailMaxRetries(t *testing.T) {
 	if repo.updateCalls[0].attempt != 3 {
 		t.Errorf("expected attempt 3, got %d", repo.updateCalls[0].attempt)
 	}
+	if repo.dlqReason != db.DLQReasonUnknown {
+		t.Errorf("expected reason %s for an unclassified error, got %s", db.DLQReasonUnknown, repo.dlqReason)
+	}
 }
 
 func TestWorker_ProcessBatch(t *testing.T) {
@@ -181,6 +414,50 @@ func TestWorker_ProcessBatch(t *testing.T) {
 	}
 }
 
+func TestWorker_ProcessBatch_TunesBatchSize(t *testing.T) {
+	var pending []*db.Notification
+	for range 20 {
+		pending = append(pending, &db.Notification{ID: uuid.New(), Status: "pending"})
+	}
+	repo := &MockRepository{notifications: pending}
+	sender := &MockSender{}
+
+	w := New(repo, sender, Config{BatchSize: 4, MaxBatchSize: 8, MaxRetries: 3}, zap.NewNop())
+	for _, want := range []int{5, 6, 7, 8, 8} {
+		w.processBatch(context.Background())
+		if got := w.batchSize(); got != want {
+			t.Fatalf("expected batch size %d after a clean full batch, got %d", want, got)
+		}
+	}
+
+	sender.shouldFail = true
+	w.processBatch(context.Background())
+	if got := w.batchSize(); got != 4 {
+		t.Errorf("expected a failing batch to halve the size to 4, got %d", got)
+	}
+}
+
+func TestWorker_ProcessBatch_SyncsChannelHolds(t *testing.T) {
+	notif := &db.Notification{ID: uuid.New(), Status: "pending", Attempt: 0}
+	repo := &MockRepository{
+		notifications: []*db.Notification{notif},
+		holdSyncErr:   errors.New("database error"),
+	}
+	sender := &MockSender{}
+
+	w := New(repo, sender, Config{BatchSize: 10, MaxRetries: 3}, zap.NewNop())
+	w.processBatch(context.Background())
+
+	if repo.holdSyncs != 1 {
+		t.Errorf("expected channel holds synced once per batch, got %d", repo.holdSyncs)
+	}
+	// The claim skips killed channels itself, so a failed sync must not
+	// block the batch.
+	if sender.sendCalls != 1 {
+		t.Errorf("expected the batch to be sent despite the sync error, got %d sends", sender.sendCalls)
+	}
+}
+
 func TestWorker_ProcessBatch_EmptyQueue(t *testing.T) {
 	repo := &MockRepository{notifications: []*db.Notification{}}
 	sender := &MockSender{}
@@ -234,6 +511,211 @@ func TestWorker_Start_GracefulShutdown(t *testing.T) {
 	}
 }
 
+func TestWorker_Start_PausedSkipsClaims(t *testing.T) {
+	repo := &MockRepository{
+		notifications: []*db.Notification{{ID: uuid.New(), Status: "pending"}},
+	}
+	sender := &MockSender{}
+	logger := zap.NewNop()
+
+	w := New(repo, sender, Config{
+		PollInterval: 10 * time.Millisecond,
+		Paused:       func() bool { return true },
+	}, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	w.Start(ctx)
+
+	if sender.sendCalls != 0 {
+		t.Errorf("expected no sends while paused, got %d", sender.sendCalls)
+	}
+}
+
+// blockingSender blocks each Send until release is closed, so tests can
+// call Shutdown while a batch is in flight.
+type blockingSender struct {
+	started chan struct{}
+	release chan struct{}
+	ctxErr  error
+}
+
+func (b *blockingSender) Send(ctx context.Context, notif *db.Notification) error {
+	close(b.started)
+	<-b.release
+	b.ctxErr = ctx.Err()
+	return nil
+}
+
+func (b *blockingSender) SupportsChannel(channel string) bool { return true }
+
+func TestWorker_Shutdown_DrainsInFlightBatch(t *testing.T) {
+	repo := &MockRepository{
+		notifications: []*db.Notification{{ID: uuid.New(), Status: "pending"}},
+	}
+	sender := &blockingSender{started: make(chan struct{}), release: make(chan struct{})}
+
+	w := New(repo, sender, Config{PollInterval: 10 * time.Millisecond}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Start(ctx)
+
+	<-sender.started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), time.Second)
+		defer drainCancel()
+		shutdownErr <- w.Shutdown(drainCtx)
+	}()
+
+	// Shutdown must wait for the in-flight send rather than return early.
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("shutdown returned before the batch finished: %v", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	close(sender.release)
+
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("expected clean drain, got %v", err)
+	}
+	if sender.ctxErr != nil {
+		t.Errorf("send context should not be cancelled during drain, got %v", sender.ctxErr)
+	}
+	if len(repo.updateCalls) == 0 || repo.updateCalls[0].status != "sent" {
+		t.Errorf("expected the drained notification to be marked sent, got %+v", repo.updateCalls)
+	}
+}
+
+func TestWorker_Shutdown_Timeout(t *testing.T) {
+	repo := &MockRepository{
+		notifications: []*db.Notification{{ID: uuid.New(), Status: "pending"}},
+	}
+	sender := &blockingSender{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(sender.release)
+
+	w := New(repo, sender, Config{PollInterval: 10 * time.Millisecond}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Start(ctx)
+
+	<-sender.started
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer drainCancel()
+
+	if err := w.Shutdown(drainCtx); err != context.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWorker_Shutdown_NotStarted(t *testing.T) {
+	w := New(&MockRepository{}, &MockSender{}, Config{}, zap.NewNop())
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected nil for a worker that never started, got %v", err)
+	}
+}
+
+type panicSender struct {
+	sendCalls int
+}
+
+func (p *panicSender) Send(ctx context.Context, notif *db.Notification) error {
+	p.sendCalls++
+	panic("nil map write in provider SDK")
+}
+
+func (p *panicSender) SupportsChannel(channel string) bool { return true }
+
+func TestWorker_ProcessBatch_RecoversFromPanic(t *testing.T) {
+	notif1 := &db.Notification{ID: uuid.New(), Status: "pending", Attempt: 0}
+	notif2 := &db.Notification{ID: uuid.New(), Status: "pending", Attempt: 0}
+
+	repo := &MockRepository{
+		notifications: []*db.Notification{notif1, notif2},
+	}
+	sender := &panicSender{}
+
+	w := New(repo, sender, Config{BatchSize: 10, MaxRetries: 3}, zap.NewNop())
+	w.processBatch(context.Background())
+
+	// Both notifications were attempted: the first panic did not abort the batch.
+	if sender.sendCalls != 2 {
+		t.Fatalf("expected 2 send calls, got %d", sender.sendCalls)
+	}
+	if len(repo.updateCalls) != 2 {
+		t.Fatalf("expected 2 update calls, got %d", len(repo.updateCalls))
+	}
+	for _, call := range repo.updateCalls {
+		if call.status != "pending" || call.attempt != 1 {
+			t.Errorf("expected retry as pending/attempt 1, got %s/%d", call.status, call.attempt)
+		}
+		if call.errorMsg == nil || *call.errorMsg != "worker panic: nil map write in provider SDK" {
+			t.Errorf("expected panic recorded as error message, got %v", call.errorMsg)
+		}
+	}
+}
+
+func TestWorker_PanicOnLastAttemptDeadLetters(t *testing.T) {
+	notif := &db.Notification{ID: uuid.New(), Status: "pending", Attempt: 2}
+	repo := &MockRepository{notifications: []*db.Notification{notif}}
+
+	w := New(repo, &panicSender{}, Config{BatchSize: 10, MaxRetries: 3}, zap.NewNop())
+	w.processBatch(context.Background())
+
+	if len(repo.updateCalls) != 1 || repo.updateCalls[0].status != db.StatusDeadLettered {
+		t.Fatalf("expected notification to be dead-lettered, got %+v", repo.updateCalls)
+	}
+}
+
+type panicEmitter struct{}
+
+func (panicEmitter) Emit(events.Detail) { panic("emitter closed") }
+
+type panicAnnotationRepo struct {
+	*MockRepository
+}
+
+func (panicAnnotationRepo) AddNotificationAnnotation(ctx context.Context, a *db.NotificationAnnotation) error {
+	panic("annotation encoder")
+}
+
+// A panic after the provider accepted the message must not retry it.
+func TestWorker_PanicAfterSendIsNotRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    func(*MockRepository) Repository
+		emitter events.Emitter
+	}{
+		{"after recording the send", func(m *MockRepository) Repository { return m }, panicEmitter{}},
+		{"before recording the send", func(m *MockRepository) Repository { return panicAnnotationRepo{m} }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			sender := &MockSender{annotations: map[string]any{"status": 202}}
+			w := New(tt.repo(repo), sender, Config{MaxRetries: 3, Events: tt.emitter}, zap.NewNop())
+
+			failed := w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Status: db.StatusProcessing})
+
+			if failed {
+				t.Error("expected a delivered notification not to count as failed")
+			}
+			if sender.sendCalls != 1 {
+				t.Fatalf("expected 1 send, got %d", sender.sendCalls)
+			}
+			if len(repo.updateCalls) != 1 || repo.updateCalls[0].status != db.StatusSent || repo.updateCalls[0].attempt != 1 {
+				t.Fatalf("expected a single sent update, got %+v", repo.updateCalls)
+			}
+		})
+	}
+}
+
 func TestNew_Defaults(t *testing.T) {
 	repo := &MockRepository{}
 	sender := &MockSender{}
@@ -250,4 +732,149 @@ func TestNew_Defaults(t *testing.T) {
 	if w.config.MaxRetries != 3 {
 		t.Errorf("expected default MaxRetries 3, got %d", w.config.MaxRetries)
 	}
+	if w.tuner != nil {
+		t.Error("expected batch tuning off by default")
+	}
+}
+
+func TestWorker_ReapStuck(t *testing.T) {
+	retryable := &db.Notification{ID: uuid.New(), Status: db.StatusProcessing, Attempt: 0, Channel: "email"}
+	exhausted := &db.Notification{ID: uuid.New(), Status: db.StatusProcessing, Attempt: 2, Channel: "sms"}
+	repo := &MockRepository{stuck: []*db.Notification{retryable, exhausted}}
+	sender := &MockSender{}
+
+	w := New(repo, sender, Config{MaxRetries: 3, BatchSize: 10}, zap.NewNop())
+	if err := w.ReapStuck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sender.sendCalls != 0 {
+		t.Errorf("expected the reaper not to send, got %d send calls", sender.sendCalls)
+	}
+	if len(repo.updateCalls) != 2 {
+		t.Fatalf("expected 2 status writes, got %d", len(repo.updateCalls))
+	}
+	if got := repo.updateCalls[0]; got.id != retryable.ID || got.status != "pending" || got.attempt != 1 {
+		t.Errorf("expected retryable row rescheduled as attempt 1, got %+v", got)
+	}
+	if got := repo.updateCalls[1]; got.id != exhausted.ID || got.status != db.StatusDeadLettered {
+		t.Errorf("expected exhausted row dead-lettered, got %+v", got)
+	}
+}
+
+func TestWorker_ReapStuck_ClaimError(t *testing.T) {
+	repo := &MockRepository{shouldFail: true}
+	w := New(repo, &MockSender{}, Config{}, zap.NewNop())
+	if err := w.ReapStuck(context.Background()); err == nil {
+		t.Error("expected the claim error to be returned")
+	}
+
+	if len(repo.updateCalls) != 0 {
+		t.Errorf("expected no status writes, got %d", len(repo.updateCalls))
+	}
+}
+
+type recordingEmitter struct {
+	events []events.Detail
+}
+
+func (r *recordingEmitter) Emit(d events.Detail) {
+	r.events = append(r.events, d)
+}
+
+func TestWorker_EmitsLifecycleEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		sendFails  bool
+		attempt    int
+		wantType   string
+		wantStatus string
+	}{
+		{"sent", false, 0, events.TypeSent, db.StatusSent},
+		{"failed with retries left", true, 0, events.TypeFailed, db.StatusPending},
+		{"out of retries", true, 2, events.TypeDeadLettered, db.StatusDeadLettered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emitter := &recordingEmitter{}
+			w := New(&MockRepository{}, &MockSender{shouldFail: tt.sendFails}, Config{MaxRetries: 3, Events: emitter}, zap.NewNop())
+
+			notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelEmail, Status: db.StatusProcessing, Attempt: tt.attempt}
+			w.processNotificationSafely(context.Background(), notif)
+
+			if len(emitter.events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(emitter.events))
+			}
+			got := emitter.events[0]
+			if got.Type != tt.wantType || got.Status != tt.wantStatus || got.Attempt != tt.attempt+1 {
+				t.Errorf("got %s/%s attempt %d, want %s/%s attempt %d",
+					got.Type, got.Status, got.Attempt, tt.wantType, tt.wantStatus, tt.attempt+1)
+			}
+			if tt.sendFails && got.Error != "send failed" {
+				t.Errorf("expected the send error on the event, got %q", got.Error)
+			}
+			if tt.wantType == events.TypeFailed && got.NextRetryAt == nil {
+				t.Error("expected next_retry_at on a failed attempt")
+			}
+		})
+	}
+}
+
+func TestWorker_InvalidRecipientDeadLettersAtOnce(t *testing.T) {
+	repo := &MockRepository{}
+	sender := &stubSender{err: fmt.Errorf("a@example.com: %w", ErrRecipientInvalid)}
+	w := New(repo, sender, Config{MaxRetries: 3}, zap.NewNop())
+
+	w.processNotificationSafely(context.Background(), &db.Notification{ID: uuid.New(), Status: "pending"})
+
+	if len(repo.updateCalls) != 1 || repo.updateCalls[0].status != db.StatusDeadLettered {
+		t.Fatalf("expected a single move to the DLQ, got %+v", repo.updateCalls)
+	}
+	if repo.dlqReason != db.DLQReasonInvalidRecipient {
+		t.Errorf("expected reason %s, got %s", db.DLQReasonInvalidRecipient, repo.dlqReason)
+	}
+}
+
+func TestWorker_CollapsesGroupedNotification(t *testing.T) {
+	leader := uuid.New()
+	tests := []struct {
+		name         string
+		groupKey     string
+		window       time.Duration
+		repo         *MockRepository
+		wantSends    int
+		wantCollapse bool
+	}{
+		{"collapsed into the group's delivery", "incident-42", time.Minute, &MockRepository{groupLeader: &leader}, 0, true},
+		{"first in its group", "incident-42", time.Minute, &MockRepository{}, 1, false},
+		{"no group key", "", time.Minute, &MockRepository{groupLeader: &leader}, 1, false},
+		{"grouping off", "incident-42", 0, &MockRepository{groupLeader: &leader}, 1, false},
+		{"lookup fails open", "incident-42", time.Minute, &MockRepository{collapseErr: errors.New("database error")}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &MockSender{}
+			emitter := &recordingEmitter{}
+			w := New(tt.repo, sender, Config{MaxRetries: 3, GroupWindow: tt.window, Events: emitter}, zap.NewNop())
+
+			notif := &db.Notification{ID: uuid.New(), Channel: db.ChannelSMS, Status: db.StatusProcessing, GroupKey: tt.groupKey}
+			w.processNotificationSafely(context.Background(), notif)
+
+			if sender.sendCalls != tt.wantSends {
+				t.Errorf("expected %d sends, got %d", tt.wantSends, sender.sendCalls)
+			}
+			if len(tt.repo.updateCalls) != 1 || tt.repo.updateCalls[0].status != db.StatusSent {
+				t.Fatalf("expected the notification marked sent once, got %+v", tt.repo.updateCalls)
+			}
+			if len(emitter.events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(emitter.events))
+			}
+			if got := emitter.events[0].Type == events.TypeCollapsed; got != tt.wantCollapse {
+				t.Errorf("expected collapsed %v, got event %s", tt.wantCollapse, emitter.events[0].Type)
+			}
+			if tt.wantCollapse && (notif.CollapsedInto == nil || *notif.CollapsedInto != leader) {
+				t.Errorf("expected collapsed_into %s, got %v", leader, notif.CollapsedInto)
+			}
+		})
+	}
 }
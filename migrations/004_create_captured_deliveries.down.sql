@@ -0,0 +1,3 @@
+-- Rollback: remove captured deliveries (sandbox test inbox)
+DROP INDEX IF EXISTS idx_captured_deliveries_tenant;
+DROP TABLE IF EXISTS captured_deliveries;
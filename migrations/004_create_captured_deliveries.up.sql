@@ -0,0 +1,29 @@
+-- Captured deliveries: the sandbox "test inbox".
+-- When SANDBOX_MODE is enabled the worker writes every message it would have
+-- sent here instead of calling SES/SNS/webhooks, so integration tests can
+-- assert on exactly what a user would have received.
+CREATE TABLE IF NOT EXISTS captured_deliveries (
+    -- Identity
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Reference to the notification that produced this delivery
+    notification_id UUID NOT NULL,
+
+    -- Multi-tenancy
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+
+    -- What would have been sent, and to whom
+    channel VARCHAR(20) NOT NULL,
+    recipient TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+
+    -- Timestamps
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_captured_channel CHECK (channel IN ('email', 'sms', 'webhook'))
+);
+
+-- Index for listing a tenant's inbox, newest first
+CREATE INDEX idx_captured_deliveries_tenant
+ON captured_deliveries(tenant_id, captured_at DESC);
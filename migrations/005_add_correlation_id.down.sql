@@ -0,0 +1,4 @@
+-- Rollback: remove correlation IDs
+DROP INDEX IF EXISTS idx_notifications_correlation;
+ALTER TABLE dead_letter_notifications DROP COLUMN IF EXISTS correlation_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS correlation_id;
@@ -0,0 +1,13 @@
+-- Correlation ID: one ID that follows a notification end-to-end
+-- (API logs → SQS message → worker logs → webhook header / SES tag).
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS correlation_id TEXT NOT NULL DEFAULT '';
+
+-- Carried over to the DLQ so a retried notification keeps the same ID.
+ALTER TABLE dead_letter_notifications
+ADD COLUMN IF NOT EXISTS correlation_id TEXT NOT NULL DEFAULT '';
+
+-- Support lookups by correlation ID when tracing an incident
+CREATE INDEX IF NOT EXISTS idx_notifications_correlation
+ON notifications(correlation_id)
+WHERE correlation_id <> '';
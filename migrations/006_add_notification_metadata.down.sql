@@ -0,0 +1,4 @@
+-- Rollback: remove metadata and tags
+DROP INDEX IF EXISTS idx_notifications_tags;
+ALTER TABLE dead_letter_notifications DROP COLUMN IF EXISTS tags, DROP COLUMN IF EXISTS metadata;
+ALTER TABLE notifications DROP COLUMN IF EXISTS tags, DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,14 @@
+-- Tenant-supplied metadata and tags, so tenants can tie notifications back
+-- to their own entities (orders, invoices, campaigns).
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Carried over to the DLQ so a retried notification keeps them.
+ALTER TABLE dead_letter_notifications
+ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- GIN index for ?tag= filtering (tag = ANY(tags) / tags @> ARRAY[tag])
+CREATE INDEX IF NOT EXISTS idx_notifications_tags
+ON notifications USING GIN (tags);
@@ -0,0 +1,2 @@
+-- Rollback: remove email templates
+DROP TABLE IF EXISTS templates;
@@ -0,0 +1,29 @@
+-- Email templates authored in MJML.
+-- The MJML source is compiled to responsive HTML once, at publish time, and
+-- the result is stored alongside it so the worker never compiles on the hot
+-- path. Editing a published template means publishing it again.
+CREATE TABLE IF NOT EXISTS templates (
+    -- Identity
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Multi-tenancy
+    tenant_id UUID NOT NULL,
+    name VARCHAR(128) NOT NULL,
+
+    -- Authored content
+    subject TEXT NOT NULL,
+    mjml_source TEXT NOT NULL,
+    text_body TEXT NOT NULL DEFAULT '',
+
+    -- Compiled output, empty until the first publish
+    html TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    published_at TIMESTAMPTZ,
+
+    -- Timestamps
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_template_status CHECK (status IN ('draft', 'published')),
+    CONSTRAINT uq_templates_tenant_name UNIQUE (tenant_id, name)
+);
@@ -0,0 +1,4 @@
+-- Rollback: remove short links and their click events
+DROP INDEX IF EXISTS idx_link_clicks_notification;
+DROP TABLE IF EXISTS link_clicks;
+DROP TABLE IF EXISTS short_links;
@@ -0,0 +1,32 @@
+-- Short links: long URLs in SMS bodies are swapped for /r/{code} at send
+-- time, since every character counts against the segment budget.
+CREATE TABLE IF NOT EXISTS short_links (
+    code VARCHAR(16) PRIMARY KEY,
+
+    -- Multi-tenancy and provenance
+    tenant_id UUID NOT NULL,
+    notification_id UUID NOT NULL,
+
+    -- Where the link redirects to
+    url TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- A retried send reuses the codes from the first attempt
+    CONSTRAINT uq_short_links_notification_url UNIQUE (notification_id, url)
+);
+
+-- One row per redirect served
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(16) NOT NULL REFERENCES short_links(code) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    notification_id UUID NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for per-notification click reporting
+CREATE INDEX idx_link_clicks_notification
+ON link_clicks(notification_id, clicked_at DESC);
@@ -0,0 +1,2 @@
+-- Rollback: remove tenant channel settings
+DROP TABLE IF EXISTS tenant_channel_settings;
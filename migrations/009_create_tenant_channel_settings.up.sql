@@ -0,0 +1,14 @@
+-- Per-tenant, per-channel delivery settings (e.g. SMS sender ID and
+-- origination number). Stored as JSONB because each channel has its own
+-- shape; the API validates it before writing.
+CREATE TABLE IF NOT EXISTS tenant_channel_settings (
+    tenant_id UUID NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    settings JSONB NOT NULL DEFAULT '{}',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, channel),
+    CONSTRAINT chk_settings_channel CHECK (channel IN ('email', 'sms', 'webhook'))
+);
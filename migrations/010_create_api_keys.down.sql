@@ -0,0 +1,2 @@
+-- Rollback: remove API keys
+DROP TABLE IF EXISTS api_keys;
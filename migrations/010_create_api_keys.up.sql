@@ -0,0 +1,31 @@
+-- Database-backed API keys for /v2, alongside the static API_AUTH_TOKENS.
+-- Only a SHA-256 of the key is stored; the plaintext is shown once, at
+-- creation. The prefix is the non-secret head of the key so it can be
+-- recognised in lists and logs.
+CREATE TABLE IF NOT EXISTS api_keys (
+    -- Identity
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Multi-tenancy
+    tenant_id UUID NOT NULL,
+    name VARCHAR(128) NOT NULL,
+
+    -- Credential
+    prefix VARCHAR(32) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Lifecycle
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    replaced_by UUID REFERENCES api_keys(id),
+
+    -- Timestamps
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_api_keys_prefix UNIQUE (prefix),
+    CONSTRAINT uq_api_keys_hash UNIQUE (key_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- Rollback: remove tenant IP allowlists
+DROP TABLE IF EXISTS tenant_ip_allowlists;
@@ -0,0 +1,9 @@
+-- Per-tenant source IP restrictions for /v2. A tenant without a row, or
+-- with an empty list, may call from anywhere.
+CREATE TABLE IF NOT EXISTS tenant_ip_allowlists (
+    tenant_id UUID PRIMARY KEY,
+    cidrs TEXT[] NOT NULL DEFAULT '{}',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
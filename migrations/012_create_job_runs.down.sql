@@ -0,0 +1,2 @@
+-- Rollback: remove background job run records
+DROP TABLE IF EXISTS job_runs;
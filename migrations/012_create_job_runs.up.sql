@@ -0,0 +1,16 @@
+-- One row per internal background job. The row doubles as the job's
+-- distributed lock (locked_by/locked_until) and its last-run record, so
+-- with several gateway replicas each job runs on one of them per interval.
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(64) PRIMARY KEY,
+
+    locked_by TEXT,
+    locked_until TIMESTAMPTZ,
+
+    last_started_at TIMESTAMPTZ,
+    last_finished_at TIMESTAMPTZ,
+    last_duration_ms BIGINT,
+    last_error TEXT,
+    last_success_at TIMESTAMPTZ,
+    run_count BIGINT NOT NULL DEFAULT 0
+);
@@ -0,0 +1,2 @@
+-- Rollback: remove the notification edit audit trail
+DROP TABLE IF EXISTS notification_edits;
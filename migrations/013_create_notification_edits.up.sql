@@ -0,0 +1,18 @@
+-- Audit trail for edits made to pending notifications through
+-- PATCH /v1/notifications/{id}. One row per edit, holding the values it
+-- replaced, so a fixed typo can be traced back to what was queued.
+CREATE TABLE IF NOT EXISTS notification_edits (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+
+    old_payload JSONB NOT NULL,
+    new_payload JSONB NOT NULL,
+    old_send_at TIMESTAMPTZ,
+    new_send_at TIMESTAMPTZ,
+    request_id TEXT NOT NULL DEFAULT '',
+
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_edits_notification
+    ON notification_edits (notification_id, edited_at);
@@ -0,0 +1,2 @@
+-- Rollback: remove tenant delivery pauses
+DROP TABLE IF EXISTS tenant_delivery_pauses;
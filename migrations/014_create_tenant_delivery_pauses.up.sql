@@ -0,0 +1,10 @@
+-- Tenants whose outbound delivery is paused. While a tenant has a row here
+-- the worker leaves its pending notifications alone; creating new ones still
+-- works, so nothing is lost and delivery picks up where it left off on
+-- resume.
+CREATE TABLE IF NOT EXISTS tenant_delivery_pauses (
+    tenant_id UUID PRIMARY KEY,
+    reason TEXT NOT NULL DEFAULT '',
+
+    paused_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,13 @@
+-- Rollback: remove channel kill switches. Held rows go back to pending so
+-- the old status constraint can be restored.
+DROP INDEX IF EXISTS idx_notifications_held;
+
+UPDATE notifications SET status = 'pending' WHERE status = 'held';
+
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS chk_status;
+
+ALTER TABLE notifications
+ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered'));
+
+DROP TABLE IF EXISTS channel_kill_switches;
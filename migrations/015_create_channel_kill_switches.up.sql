@@ -0,0 +1,24 @@
+-- Platform-wide per-channel kill switches, e.g. SMS off during an SNS
+-- billing incident. While a channel has a row here, creates still succeed
+-- but the worker moves its pending notifications to 'held' instead of
+-- sending them, and back to 'pending' once the row is deleted.
+CREATE TABLE IF NOT EXISTS channel_kill_switches (
+    channel VARCHAR(20) PRIMARY KEY,
+    reason TEXT NOT NULL DEFAULT '',
+
+    killed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_kill_switch_channel CHECK (channel IN ('email', 'sms', 'webhook'))
+);
+
+-- Add the held status to notifications
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS chk_status;
+
+ALTER TABLE notifications
+ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held'));
+
+-- Releasing held rows looks them up by channel
+CREATE INDEX IF NOT EXISTS idx_notifications_held
+ON notifications(channel)
+WHERE status = 'held';
@@ -0,0 +1,6 @@
+-- Rollback: remove provider message IDs
+DROP INDEX IF EXISTS idx_notifications_provider_message_id;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS provider_message_id,
+DROP COLUMN IF EXISTS provider;
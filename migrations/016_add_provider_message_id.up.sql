@@ -0,0 +1,10 @@
+-- Provider-side identity of a sent notification (SES/SNS MessageId), so
+-- bounce webhooks and support tickets quoting the provider's ID can be
+-- traced back to the notification.
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS provider VARCHAR(32),
+ADD COLUMN IF NOT EXISTS provider_message_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_provider_message_id
+ON notifications(provider_message_id)
+WHERE provider_message_id IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Rollback: remove delivery events
+DROP TABLE IF EXISTS delivery_events;
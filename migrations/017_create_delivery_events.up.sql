@@ -0,0 +1,30 @@
+-- Delivery, bounce and complaint events reported back by providers (SES via
+-- SNS today). notification_id and tenant_id are resolved from the
+-- provider's message ID on ingest and stay NULL when it doesn't match a
+-- notification. No foreign key: retention may delete the notification
+-- long before its events stop mattering.
+CREATE TABLE IF NOT EXISTS delivery_events (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id UUID,
+    tenant_id UUID,
+    channel VARCHAR(20) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    provider_message_id TEXT NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    bounce_type VARCHAR(20) NOT NULL DEFAULT '',
+    recipient TEXT NOT NULL DEFAULT '',
+
+    occurred_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_delivery_event_type CHECK (event_type IN ('delivery', 'bounce', 'complaint'))
+);
+
+-- SNS delivers at least once; a redelivered event must not count twice.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_events_dedupe
+ON delivery_events(provider, provider_message_id, event_type, recipient);
+
+-- The reputation guard aggregates a tenant's recent events.
+CREATE INDEX IF NOT EXISTS idx_delivery_events_tenant
+ON delivery_events(tenant_id, occurred_at)
+WHERE tenant_id IS NOT NULL;
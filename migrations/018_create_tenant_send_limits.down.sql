@@ -0,0 +1,2 @@
+-- Rollback: remove tenant send limits
+DROP TABLE IF EXISTS tenant_send_limits;
@@ -0,0 +1,18 @@
+-- Per-tenant, per-channel sending limits applied by the reputation guard.
+-- 'throttled' caps the tenant's send rate in the worker; 'paused' keeps the
+-- claim query from picking up their notifications at all. Lifting a limit
+-- sets lifted_at instead of deleting the row, and the guard only judges
+-- sends and events after it, so a lift isn't undone by the same bounces
+-- on the next run.
+CREATE TABLE IF NOT EXISTS tenant_send_limits (
+    tenant_id UUID NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+
+    since TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    lifted_at TIMESTAMPTZ,
+
+    PRIMARY KEY (tenant_id, channel),
+    CONSTRAINT chk_send_limit_state CHECK (state IN ('throttled', 'paused'))
+);
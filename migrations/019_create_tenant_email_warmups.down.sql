@@ -0,0 +1,2 @@
+-- Rollback: remove email warm-up state
+DROP TABLE IF EXISTS tenant_email_warmups;
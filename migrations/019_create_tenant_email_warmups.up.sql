@@ -0,0 +1,21 @@
+-- Email warm-up state per tenant. started_at is the tenant's first day of
+-- sending email, which picks its cap from the warm-up schedule; day and
+-- sent_today count what it has sent on the current UTC day. ended_at is
+-- set when an operator ends the warm-up early.
+CREATE TABLE IF NOT EXISTS tenant_email_warmups (
+    tenant_id UUID PRIMARY KEY,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ,
+
+    day DATE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')::date,
+    sent_today INTEGER NOT NULL DEFAULT 0
+);
+
+-- Tenants that were already sending when warm-up was introduced are not
+-- new: backdate them to their first email so the schedule doesn't cap them.
+INSERT INTO tenant_email_warmups (tenant_id, started_at)
+SELECT tenant_id, MIN(created_at)
+FROM notifications
+WHERE channel = 'email'
+GROUP BY tenant_id
+ON CONFLICT (tenant_id) DO NOTHING;
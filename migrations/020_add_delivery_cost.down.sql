@@ -0,0 +1,5 @@
+-- Rollback: remove delivery cost tracking
+DROP TABLE IF EXISTS tenant_daily_usage;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS cost;
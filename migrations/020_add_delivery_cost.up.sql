@@ -0,0 +1,16 @@
+-- Estimated provider cost of each sent notification, priced from the
+-- pricing table in config at send time, and a per-tenant, per-UTC-day
+-- rollup the usage API and chargeback read instead of scanning
+-- notifications (which retention may have deleted).
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS cost NUMERIC(14, 8);
+
+CREATE TABLE IF NOT EXISTS tenant_daily_usage (
+    tenant_id UUID NOT NULL,
+    day DATE NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    sent BIGINT NOT NULL DEFAULT 0,
+    cost NUMERIC(18, 8) NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (tenant_id, day, channel)
+);
@@ -0,0 +1,2 @@
+-- Rollback: remove tenant budgets
+DROP TABLE IF EXISTS tenant_budgets;
@@ -0,0 +1,19 @@
+-- Monthly spend and/or volume budget per tenant, checked against
+-- tenant_daily_usage for the current UTC month. alerted_month and
+-- alerted_percent record the highest threshold (80 or 100) already alerted
+-- on, so each alert goes out once a month.
+CREATE TABLE IF NOT EXISTS tenant_budgets (
+    tenant_id UUID PRIMARY KEY,
+    monthly_cost NUMERIC(18, 8) CHECK (monthly_cost > 0),
+    monthly_volume BIGINT CHECK (monthly_volume > 0),
+    alert_email TEXT NOT NULL,
+    block_at_limit BOOLEAN NOT NULL DEFAULT FALSE,
+
+    alerted_month DATE,
+    alerted_percent INTEGER NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (monthly_cost IS NOT NULL OR monthly_volume IS NOT NULL)
+);
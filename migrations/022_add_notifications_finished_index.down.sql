@@ -0,0 +1,2 @@
+-- Rollback: remove the finished notifications index
+DROP INDEX IF EXISTS idx_notifications_finished;
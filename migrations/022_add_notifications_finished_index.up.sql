@@ -0,0 +1,5 @@
+-- Recently finished notifications, for the admin overview's per-channel
+-- throughput and error rates and its top failing tenants.
+CREATE INDEX IF NOT EXISTS idx_notifications_finished
+ON notifications(updated_at)
+WHERE status IN ('sent', 'failed', 'dead_lettered');
@@ -0,0 +1,8 @@
+-- Rollback: remove row-level security on tenant data
+DROP POLICY IF EXISTS tenant_isolation ON dead_letter_notifications;
+ALTER TABLE dead_letter_notifications NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE dead_letter_notifications DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON notifications;
+ALTER TABLE notifications NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE notifications DISABLE ROW LEVEL SECURITY;
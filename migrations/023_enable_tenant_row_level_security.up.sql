@@ -0,0 +1,22 @@
+-- Row-level security on tenant data, as defense in depth behind the
+-- tenant checks in the application. A connection serving an
+-- authenticated tenant has nimbus.tenant_id set (see db.WithTenant) and
+-- only sees and writes that tenant's rows. Unset or empty means an
+-- internal caller (worker, jobs, admin) and every row is visible.
+--
+-- FORCE applies the policies to the table owner too, which is usually the
+-- role nimbus connects as. Superusers and BYPASSRLS roles still bypass
+-- them, so production should not connect as one.
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notifications FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON notifications
+    USING (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id))
+    WITH CHECK (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id));
+
+ALTER TABLE dead_letter_notifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dead_letter_notifications FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON dead_letter_notifications
+    USING (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id))
+    WITH CHECK (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id));
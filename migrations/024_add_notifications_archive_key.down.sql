@@ -0,0 +1,3 @@
+-- Rollback: drop the archive key column
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS archive_key;
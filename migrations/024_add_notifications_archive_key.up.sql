@@ -0,0 +1,4 @@
+-- S3 key of the archived, fully rendered message (final email HTML,
+-- webhook body) for sent notifications, when delivery archival is on.
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS archive_key TEXT;
@@ -0,0 +1,5 @@
+-- Rollback: remove the notification event timeline
+DROP TRIGGER IF EXISTS record_notification_transition ON notifications;
+DROP TRIGGER IF EXISTS record_notification_created ON notifications;
+DROP FUNCTION IF EXISTS record_notification_event();
+DROP TABLE IF EXISTS notification_events;
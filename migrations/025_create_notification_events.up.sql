@@ -0,0 +1,55 @@
+-- Timeline of every notification state transition. Triggers record one
+-- row when a notification is created and one whenever its status or
+-- attempt changes, whichever code path made the change, so the history
+-- can't drift from the row. notifications.status stays the current state.
+CREATE TABLE IF NOT EXISTS notification_events (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+
+    from_status VARCHAR(20), -- NULL for the creation event
+    to_status VARCHAR(20) NOT NULL,
+    attempt INT NOT NULL,
+    error_message TEXT,
+    -- tenant when made by an authenticated tenant request (nimbus.tenant_id
+    -- set, see migration 023), system for the worker, jobs and admin.
+    actor VARCHAR(20) NOT NULL,
+
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_notification
+    ON notification_events (notification_id, id);
+
+CREATE OR REPLACE FUNCTION record_notification_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO notification_events (
+        notification_id, tenant_id, from_status, to_status, attempt, error_message, actor
+    ) VALUES (
+        NEW.id, NEW.tenant_id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        NEW.status, NEW.attempt, NEW.error_message,
+        CASE WHEN COALESCE(current_setting('nimbus.tenant_id', true), '') = '' THEN 'system' ELSE 'tenant' END
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_notification_created
+AFTER INSERT ON notifications
+FOR EACH ROW
+EXECUTE FUNCTION record_notification_event();
+
+CREATE TRIGGER record_notification_transition
+AFTER UPDATE OF status, attempt ON notifications
+FOR EACH ROW
+WHEN (OLD.status IS DISTINCT FROM NEW.status OR OLD.attempt IS DISTINCT FROM NEW.attempt)
+EXECUTE FUNCTION record_notification_event();
+
+ALTER TABLE notification_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notification_events FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON notification_events
+    USING (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id))
+    WITH CHECK (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id));
@@ -0,0 +1,2 @@
+-- Rollback: remove per-tenant rate limit overrides
+DROP TABLE IF EXISTS tenant_rate_limits;
@@ -0,0 +1,14 @@
+-- Per-tenant overrides of the API rate limit, set by operators through
+-- /v1/admin/tenants/{tenantID}/rate-limit. burst replaces RATE_LIMIT_BURST
+-- for the tenant; retry_after_seconds, when set, is the Retry-After hint
+-- its 429s carry instead of the time until the window resets.
+CREATE TABLE IF NOT EXISTS tenant_rate_limits (
+    tenant_id UUID PRIMARY KEY,
+    burst INT NOT NULL DEFAULT 0,
+    retry_after_seconds INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_rate_limit_burst CHECK (burst >= 0),
+    CONSTRAINT chk_rate_limit_retry_after CHECK (retry_after_seconds IS NULL OR retry_after_seconds > 0)
+);
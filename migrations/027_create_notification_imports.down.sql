@@ -0,0 +1,2 @@
+-- Rollback: remove bulk import jobs
+DROP TABLE IF EXISTS notification_imports;
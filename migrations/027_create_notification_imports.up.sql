@@ -0,0 +1,40 @@
+-- Bulk import jobs created by POST /v1/imports. The file itself is not
+-- stored: the gateway that accepted it creates the notifications in the
+-- background and records progress here. row_errors is the per-row error
+-- report, capped by the application; failed_rows is the full count.
+CREATE TABLE IF NOT EXISTS notification_imports (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    source TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    row_errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_import_channel CHECK (channel IN ('email', 'sms', 'webhook')),
+    CONSTRAINT chk_import_format CHECK (format IN ('csv', 'jsonl')),
+    CONSTRAINT chk_import_status CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_imports_tenant
+    ON notification_imports (tenant_id, created_at DESC);
+
+CREATE TRIGGER update_notification_imports_updated_at
+BEFORE UPDATE ON notification_imports
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Same tenant isolation as the notifications it creates (see 023).
+ALTER TABLE notification_imports ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notification_imports FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON notification_imports
+    USING (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id))
+    WITH CHECK (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id));
@@ -0,0 +1,2 @@
+-- Rollback: remove tenant API settings
+DROP TABLE IF EXISTS tenant_settings;
@@ -0,0 +1,9 @@
+-- Tenant-wide API settings, set through /v1/tenants/{tenant_id}/settings.
+-- require_idempotency_key makes create reject requests without an
+-- Idempotency-Key header, for tenants that want retries to be provably safe.
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    tenant_id UUID PRIMARY KEY,
+    require_idempotency_key BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- Rollback: remove the cross-tenant user index
+DROP INDEX IF EXISTS idx_notifications_user_global;
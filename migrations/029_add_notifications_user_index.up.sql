@@ -0,0 +1,5 @@
+-- Cross-tenant lookups by user_id, for the admin user search used in
+-- incident response. idx_notifications_user leads with tenant_id and
+-- can't serve them.
+CREATE INDEX IF NOT EXISTS idx_notifications_user_global
+ON notifications(user_id, created_at DESC);
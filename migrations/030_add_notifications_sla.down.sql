@@ -0,0 +1,4 @@
+-- Rollback: remove delivery SLA tracking
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS sla_breached,
+DROP COLUMN IF EXISTS sla_seconds;
@@ -0,0 +1,6 @@
+-- Optional delivery deadline in seconds from creation, and whether it was
+-- missed. sla_breached is set once the notification is sent or
+-- dead-lettered; it stays NULL for notifications without an SLA.
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS sla_seconds INTEGER CHECK (sla_seconds > 0),
+ADD COLUMN IF NOT EXISTS sla_breached BOOLEAN;
@@ -0,0 +1,5 @@
+-- Rollback: remove DLQ reason codes
+DROP INDEX IF EXISTS idx_dlq_tenant_reason;
+
+ALTER TABLE dead_letter_notifications
+DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,17 @@
+-- Structured reason code for each DLQ entry, so entries can be filtered and
+-- retried in bulk by cause. last_error keeps the free-text detail. Existing
+-- entries are classified from last_error as well as it allows.
+ALTER TABLE dead_letter_notifications
+ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT 'unknown'
+    CHECK (reason IN ('invalid_recipient', 'provider_outage', 'timeout', 'payload_error', 'unknown'));
+
+UPDATE dead_letter_notifications
+SET reason = CASE
+    WHEN LOWER(last_error) LIKE '%circuit breaker is open%' THEN 'provider_outage'
+    WHEN LOWER(last_error) LIKE '%deadline exceeded%' OR LOWER(last_error) LIKE '%timeout%' THEN 'timeout'
+    WHEN LOWER(last_error) LIKE '%payload%' THEN 'payload_error'
+    ELSE 'unknown'
+END;
+
+CREATE INDEX IF NOT EXISTS idx_dlq_tenant_reason
+ON dead_letter_notifications (tenant_id, reason, created_at DESC);
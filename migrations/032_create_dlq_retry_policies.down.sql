@@ -0,0 +1,3 @@
+-- Rollback: remove automatic DLQ retries
+ALTER TABLE dead_letter_notifications DROP COLUMN IF EXISTS retry_count;
+DROP TABLE IF EXISTS dlq_retry_policies;
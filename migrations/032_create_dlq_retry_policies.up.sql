@@ -0,0 +1,19 @@
+-- Automatic DLQ retries. A policy retries a tenant's pending entries with
+-- one reason code once they have waited interval_seconds. retry_count on
+-- each entry counts the DLQ retries before it, so a policy stops at
+-- max_retries instead of cycling forever.
+CREATE TABLE IF NOT EXISTS dlq_retry_policies (
+    tenant_id UUID NOT NULL,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('invalid_recipient', 'provider_outage', 'timeout', 'payload_error', 'unknown')),
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds > 0),
+    max_retries INTEGER NOT NULL CHECK (max_retries > 0),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, reason)
+);
+
+ALTER TABLE dead_letter_notifications
+ADD COLUMN IF NOT EXISTS retry_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Rollback: remove recipient hygiene
+DROP TABLE IF EXISTS invalid_recipients;
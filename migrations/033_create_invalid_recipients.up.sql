@@ -0,0 +1,22 @@
+-- Recipient hygiene. Addresses a provider reported as permanently
+-- undeliverable, by a hard bounce or by rejecting them at send time, are
+-- recorded per tenant and channel. The worker skips sends to them until the
+-- tenant reinstates the address.
+CREATE TABLE IF NOT EXISTS invalid_recipients (
+    tenant_id UUID NOT NULL,
+    channel TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('hard_bounce', 'provider_rejected')),
+    detail TEXT NOT NULL DEFAULT '',
+    -- The notification whose bounce or rejection invalidated the address.
+    notification_id UUID,
+
+    invalidated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, channel, recipient)
+);
+
+-- The report lists a tenant's invalidations, newest first.
+CREATE INDEX IF NOT EXISTS idx_invalid_recipients_tenant
+ON invalid_recipients (tenant_id, invalidated_at DESC);
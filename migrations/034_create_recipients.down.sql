@@ -0,0 +1,2 @@
+-- Rollback: remove reusable recipients
+DROP TABLE IF EXISTS recipients;
@@ -0,0 +1,18 @@
+-- Reusable recipients: a tenant's contact details for each of its users.
+-- Payloads that set "recipient_ref": "user" carry no address; the worker
+-- reads it from here at send time, so a retry or re-send goes to the
+-- user's current address.
+CREATE TABLE IF NOT EXISTS recipients (
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    device_tokens TEXT[] NOT NULL DEFAULT '{}',
+    locale TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id)
+);
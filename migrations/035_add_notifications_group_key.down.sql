@@ -0,0 +1,6 @@
+-- Rollback: remove notification groups
+DROP INDEX IF EXISTS idx_notifications_group;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS collapsed_into,
+DROP COLUMN IF EXISTS group_key;
@@ -0,0 +1,13 @@
+-- Notification groups: notifications to the same user on the same channel
+-- that share a group_key collapse into one delivery within the grouping
+-- window. A collapsed notification is marked sent without going to the
+-- provider, and collapsed_into points at the notification delivered in its
+-- place.
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS group_key TEXT,
+ADD COLUMN IF NOT EXISTS collapsed_into UUID;
+
+-- The worker looks for the group's latest delivery before each send
+CREATE INDEX IF NOT EXISTS idx_notifications_group
+ON notifications (tenant_id, user_id, channel, group_key, created_at)
+WHERE group_key IS NOT NULL;
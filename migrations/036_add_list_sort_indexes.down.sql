@@ -0,0 +1,5 @@
+-- Rollback: remove list sort indexes
+DROP INDEX IF EXISTS idx_dlq_tenant_status;
+DROP INDEX IF EXISTS idx_dlq_tenant_updated;
+DROP INDEX IF EXISTS idx_notifications_tenant_status;
+DROP INDEX IF EXISTS idx_notifications_tenant_updated;
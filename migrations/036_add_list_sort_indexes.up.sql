@@ -0,0 +1,15 @@
+-- List sorting: the notification and DLQ lists can be ordered by
+-- updated_at or status as well as created_at. Status ties break on
+-- created_at. created_at is already covered by idx_notifications_tenant
+-- and idx_dlq_tenant.
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_updated
+ON notifications (tenant_id, updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_status
+ON notifications (tenant_id, status, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_dlq_tenant_updated
+ON dead_letter_notifications (tenant_id, updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_dlq_tenant_status
+ON dead_letter_notifications (tenant_id, status, created_at);
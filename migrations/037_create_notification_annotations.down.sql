@@ -0,0 +1,2 @@
+-- Rollback: remove provider annotations
+DROP TABLE IF EXISTS notification_annotations;
@@ -0,0 +1,26 @@
+-- Provider-specific detail about a notification: what a provider returned
+-- for a send (webhook response headers, the SMS attributes used, SES message
+-- tags) and the events it reported later (SES deliveries, bounces and
+-- complaints). Shown on the notification's timeline.
+CREATE TABLE IF NOT EXISTS notification_annotations (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+
+    provider VARCHAR(50) NOT NULL,
+    kind VARCHAR(30) NOT NULL,     -- send, or the delivery event type
+    attempt INT,                   -- the send attempt; NULL for events
+    data JSONB NOT NULL DEFAULT '{}',
+
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_annotations_notification
+    ON notification_annotations (notification_id, id);
+
+ALTER TABLE notification_annotations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notification_annotations FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON notification_annotations
+    USING (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id))
+    WITH CHECK (tenant_id = COALESCE(NULLIF(current_setting('nimbus.tenant_id', true), '')::uuid, tenant_id));
@@ -0,0 +1,9 @@
+-- Rollback: drop engagement events so the old type constraint can be
+-- restored.
+DELETE FROM delivery_events WHERE event_type IN ('open', 'click', 'rendering_failure');
+
+ALTER TABLE delivery_events
+DROP CONSTRAINT IF EXISTS chk_delivery_event_type;
+
+ALTER TABLE delivery_events
+ADD CONSTRAINT chk_delivery_event_type CHECK (event_type IN ('delivery', 'bounce', 'complaint'));
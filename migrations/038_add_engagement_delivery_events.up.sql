@@ -0,0 +1,8 @@
+-- Opens, clicks and rendering failures from the SES configuration set's
+-- event destination. The dedupe index keeps one of each per message and
+-- recipient, so opens and clicks count unique engagement.
+ALTER TABLE delivery_events
+DROP CONSTRAINT IF EXISTS chk_delivery_event_type;
+
+ALTER TABLE delivery_events
+ADD CONSTRAINT chk_delivery_event_type CHECK (event_type IN ('delivery', 'bounce', 'complaint', 'open', 'click', 'rendering_failure'));
@@ -0,0 +1,13 @@
+-- Rollback: remove frequency caps. Capped notifications were never sent, so
+-- they end 'failed' to fit the old status constraint.
+DROP INDEX IF EXISTS idx_notifications_user_sent;
+
+UPDATE notifications SET status = 'failed' WHERE status = 'frequency_capped';
+
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS chk_status;
+
+ALTER TABLE notifications
+ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held'));
+
+DROP TABLE IF EXISTS frequency_caps;
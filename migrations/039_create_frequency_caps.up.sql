@@ -0,0 +1,28 @@
+-- Per-tenant, per-channel frequency caps: at most max_count notifications
+-- to one user on the channel within window_seconds. The worker counts the
+-- user's sent notifications and either defers the excess until the window
+-- has room ('defer') or ends it 'frequency_capped' without sending ('drop').
+CREATE TABLE IF NOT EXISTS frequency_caps (
+    tenant_id UUID NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    max_count INTEGER NOT NULL CHECK (max_count > 0),
+    window_seconds INTEGER NOT NULL CHECK (window_seconds > 0),
+    action VARCHAR(10) NOT NULL DEFAULT 'defer',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, channel),
+    CONSTRAINT chk_frequency_cap_action CHECK (action IN ('defer', 'drop'))
+);
+
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS chk_status;
+
+ALTER TABLE notifications
+ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held', 'frequency_capped'));
+
+-- The cap counts a user's recent sends on a channel
+CREATE INDEX IF NOT EXISTS idx_notifications_user_sent
+ON notifications(tenant_id, user_id, channel, updated_at)
+WHERE status = 'sent';
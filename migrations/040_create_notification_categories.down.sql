@@ -0,0 +1,18 @@
+-- Rollback: remove notification categories. Category-specific frequency
+-- caps have no place in the old key, so they go too.
+DELETE FROM frequency_caps WHERE category <> '';
+
+ALTER TABLE frequency_caps
+DROP CONSTRAINT IF EXISTS frequency_caps_pkey,
+ADD PRIMARY KEY (tenant_id, channel);
+
+ALTER TABLE frequency_caps
+DROP COLUMN IF EXISTS category;
+
+DROP INDEX IF EXISTS idx_notifications_category;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS category;
+
+DROP TABLE IF EXISTS category_opt_outs;
+DROP TABLE IF EXISTS notification_categories;
@@ -0,0 +1,47 @@
+-- Notification categories: each tenant defines the categories (billing,
+-- security, marketing, ...) its notifications may carry. A category can
+-- set quiet hours, "HH:MM" local times in the recipient's timezone during
+-- which its notifications wait; the window may wrap past midnight.
+CREATE TABLE IF NOT EXISTS notification_categories (
+    tenant_id UUID NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, name),
+    CONSTRAINT chk_category_quiet_hours CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+-- A user's opt-outs: no notifications of the category, on one channel or,
+-- with channel '', on every channel
+CREATE TABLE IF NOT EXISTS category_opt_outs (
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    category VARCHAR(64) NOT NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id, category, channel),
+    FOREIGN KEY (tenant_id, category) REFERENCES notification_categories(tenant_id, name) ON DELETE CASCADE
+);
+
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS category VARCHAR(64);
+
+-- Category analytics group a tenant's notifications by category
+CREATE INDEX IF NOT EXISTS idx_notifications_category
+ON notifications (tenant_id, category, created_at)
+WHERE category IS NOT NULL;
+
+-- Frequency caps may apply to one category; '' caps the whole channel
+ALTER TABLE frequency_caps
+ADD COLUMN IF NOT EXISTS category VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE frequency_caps
+DROP CONSTRAINT IF EXISTS frequency_caps_pkey,
+ADD PRIMARY KEY (tenant_id, channel, category);
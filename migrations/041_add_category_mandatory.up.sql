@@ -0,0 +1,4 @@
+-- Mandatory categories, such as security alerts, reach every user: the
+-- worker ignores opt-outs and quiet hours for them.
+ALTER TABLE notification_categories
+ADD COLUMN IF NOT EXISTS mandatory BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,25 @@
+-- Slack joins the built-in channels: every table that checks its channel
+-- accepts 'slack'.
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_channel;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE dead_letter_notifications DROP CONSTRAINT IF EXISTS chk_dlq_channel;
+ALTER TABLE dead_letter_notifications
+ADD CONSTRAINT chk_dlq_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE captured_deliveries DROP CONSTRAINT IF EXISTS chk_captured_channel;
+ALTER TABLE captured_deliveries
+ADD CONSTRAINT chk_captured_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE tenant_channel_settings DROP CONSTRAINT IF EXISTS chk_settings_channel;
+ALTER TABLE tenant_channel_settings
+ADD CONSTRAINT chk_settings_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE channel_kill_switches DROP CONSTRAINT IF EXISTS chk_kill_switch_channel;
+ALTER TABLE channel_kill_switches
+ADD CONSTRAINT chk_kill_switch_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE notification_imports DROP CONSTRAINT IF EXISTS chk_import_channel;
+ALTER TABLE notification_imports
+ADD CONSTRAINT chk_import_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
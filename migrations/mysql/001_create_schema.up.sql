@@ -0,0 +1,358 @@
+-- Nimbus schema for MySQL 8.0.20 or later, equivalent to Postgres
+-- migrations 001-026 (see ../README.md). Differences from the Postgres
+-- schema:
+--   * UUIDs are CHAR(36), JSONB and TEXT[] columns are JSON, timestamps
+--     are DATETIME(6) in UTC (the gateway sets time_zone = '+00:00').
+--   * Text columns that are indexed are VARCHARs; short_links gets a
+--     generated hash of the URL to make (notification_id, url) unique.
+--   * Partial and GIN indexes become plain and multi-valued indexes.
+--   * There is no row level security (migration 023): tenant isolation
+--     rests on the tenant filters in the gateway's queries.
+--   * knowledge_base (pgvector, migration 003) is not created; the AI
+--     assistant needs Postgres.
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+
+    channel VARCHAR(20) NOT NULL,
+    payload JSON NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempt INT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    next_retry_at DATETIME(6),
+
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT ('{}'),
+    tags JSON NOT NULL DEFAULT ('[]'),
+
+    provider VARCHAR(32),
+    provider_message_id VARCHAR(255),
+    cost DECIMAL(14, 8),
+    archive_key TEXT,
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_channel CHECK (channel IN ('email', 'sms', 'webhook')),
+    CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held')),
+
+    INDEX idx_notifications_retry (status, next_retry_at, created_at),
+    INDEX idx_notifications_tenant (tenant_id, created_at),
+    INDEX idx_notifications_user (tenant_id, user_id, created_at),
+    INDEX idx_notifications_channel (channel, status),
+    INDEX idx_notifications_correlation (correlation_id),
+    INDEX idx_notifications_tags ((CAST(tags AS CHAR(64) ARRAY))),
+    INDEX idx_notifications_provider_message_id (provider_message_id),
+    INDEX idx_notifications_finished (updated_at, status)
+);
+
+CREATE TABLE IF NOT EXISTS dead_letter_notifications (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    original_notification_id CHAR(36) NOT NULL,
+
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    payload JSON NOT NULL,
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    metadata JSON NOT NULL DEFAULT ('{}'),
+    tags JSON NOT NULL DEFAULT ('[]'),
+
+    attempts INT NOT NULL,
+    last_error TEXT NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    retried_notification_id CHAR(36),
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_dlq_channel CHECK (channel IN ('email', 'sms', 'webhook')),
+    CONSTRAINT chk_dlq_status CHECK (status IN ('pending', 'retried', 'discarded')),
+
+    INDEX idx_dlq_tenant (tenant_id, created_at),
+    INDEX idx_dlq_pending (status, created_at)
+);
+
+CREATE TABLE IF NOT EXISTS captured_deliveries (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    notification_id CHAR(36) NOT NULL,
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+
+    channel VARCHAR(20) NOT NULL,
+    recipient TEXT NOT NULL,
+    payload JSON NOT NULL,
+
+    captured_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_captured_channel CHECK (channel IN ('email', 'sms', 'webhook')),
+
+    INDEX idx_captured_deliveries_tenant (tenant_id, captured_at)
+);
+
+CREATE TABLE IF NOT EXISTS templates (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    name VARCHAR(128) NOT NULL,
+
+    subject TEXT NOT NULL,
+    mjml_source MEDIUMTEXT NOT NULL,
+    text_body MEDIUMTEXT NOT NULL,
+
+    html MEDIUMTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    published_at DATETIME(6),
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_template_status CHECK (status IN ('draft', 'published')),
+    CONSTRAINT uq_templates_tenant_name UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS short_links (
+    code VARCHAR(16) NOT NULL PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    notification_id CHAR(36) NOT NULL,
+
+    url TEXT NOT NULL,
+    -- TEXT can't be part of a unique key; its hash can.
+    url_hash BINARY(32) AS (UNHEX(SHA2(url, 256))) STORED,
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT uq_short_links_notification_url UNIQUE (notification_id, url_hash)
+);
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    code VARCHAR(16) NOT NULL,
+    tenant_id CHAR(36) NOT NULL,
+    notification_id CHAR(36) NOT NULL,
+    user_agent TEXT NOT NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    clicked_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT fk_link_clicks_code FOREIGN KEY (code) REFERENCES short_links (code) ON DELETE CASCADE,
+
+    INDEX idx_link_clicks_notification (notification_id, clicked_at)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_channel_settings (
+    tenant_id CHAR(36) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    settings JSON NOT NULL DEFAULT ('{}'),
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, channel),
+    CONSTRAINT chk_settings_channel CHECK (channel IN ('email', 'sms', 'webhook'))
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    name VARCHAR(128) NOT NULL,
+
+    prefix VARCHAR(32) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes JSON NOT NULL DEFAULT ('[]'),
+
+    expires_at DATETIME(6),
+    last_used_at DATETIME(6),
+    revoked_at DATETIME(6),
+    replaced_by CHAR(36),
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT uq_api_keys_prefix UNIQUE (prefix),
+    CONSTRAINT uq_api_keys_hash UNIQUE (key_hash),
+    CONSTRAINT fk_api_keys_replaced_by FOREIGN KEY (replaced_by) REFERENCES api_keys (id),
+
+    INDEX idx_api_keys_tenant (tenant_id, created_at)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_ip_allowlists (
+    tenant_id CHAR(36) NOT NULL PRIMARY KEY,
+    cidrs JSON NOT NULL DEFAULT ('[]'),
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+
+    locked_by VARCHAR(255),
+    locked_until DATETIME(6),
+
+    last_started_at DATETIME(6),
+    last_finished_at DATETIME(6),
+    last_duration_ms BIGINT,
+    last_error TEXT,
+    last_success_at DATETIME(6),
+    run_count BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS notification_edits (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    notification_id CHAR(36) NOT NULL,
+
+    old_payload JSON NOT NULL,
+    new_payload JSON NOT NULL,
+    old_send_at DATETIME(6),
+    new_send_at DATETIME(6),
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+
+    edited_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT fk_notification_edits_notification FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE,
+
+    INDEX idx_notification_edits_notification (notification_id, edited_at)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_delivery_pauses (
+    tenant_id CHAR(36) NOT NULL PRIMARY KEY,
+    reason TEXT NOT NULL,
+
+    paused_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE TABLE IF NOT EXISTS channel_kill_switches (
+    channel VARCHAR(20) NOT NULL PRIMARY KEY,
+    reason TEXT NOT NULL,
+
+    killed_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_kill_switch_channel CHECK (channel IN ('email', 'sms', 'webhook'))
+);
+
+CREATE TABLE IF NOT EXISTS delivery_events (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    notification_id CHAR(36),
+    tenant_id CHAR(36),
+    channel VARCHAR(20) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    provider_message_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    bounce_type VARCHAR(20) NOT NULL DEFAULT '',
+    recipient VARCHAR(320) NOT NULL DEFAULT '',
+
+    occurred_at DATETIME(6) NOT NULL,
+    received_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_delivery_event_type CHECK (event_type IN ('delivery', 'bounce', 'complaint')),
+
+    UNIQUE INDEX idx_delivery_events_dedupe (provider, provider_message_id, event_type, recipient),
+    INDEX idx_delivery_events_tenant (tenant_id, occurred_at)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_send_limits (
+    tenant_id CHAR(36) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+
+    since DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    lifted_at DATETIME(6),
+
+    PRIMARY KEY (tenant_id, channel),
+    CONSTRAINT chk_send_limit_state CHECK (state IN ('throttled', 'paused'))
+);
+
+CREATE TABLE IF NOT EXISTS tenant_email_warmups (
+    tenant_id CHAR(36) NOT NULL PRIMARY KEY,
+    started_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    ended_at DATETIME(6),
+
+    day DATE NOT NULL DEFAULT (UTC_DATE()),
+    sent_today INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS tenant_daily_usage (
+    tenant_id CHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    sent BIGINT NOT NULL DEFAULT 0,
+    cost DECIMAL(18, 8) NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (tenant_id, day, channel)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_budgets (
+    tenant_id CHAR(36) NOT NULL PRIMARY KEY,
+    monthly_cost DECIMAL(18, 8),
+    monthly_volume BIGINT,
+    alert_email VARCHAR(320) NOT NULL,
+    block_at_limit BOOLEAN NOT NULL DEFAULT FALSE,
+
+    alerted_month DATE,
+    alerted_percent INT NOT NULL DEFAULT 0,
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_budget_cost CHECK (monthly_cost > 0),
+    CONSTRAINT chk_budget_volume CHECK (monthly_volume > 0),
+    CONSTRAINT chk_budget_set CHECK (monthly_cost IS NOT NULL OR monthly_volume IS NOT NULL)
+);
+
+CREATE TABLE IF NOT EXISTS notification_events (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    notification_id CHAR(36) NOT NULL,
+    tenant_id CHAR(36) NOT NULL,
+
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    attempt INT NOT NULL,
+    error_message TEXT,
+    actor VARCHAR(20) NOT NULL,
+
+    occurred_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT fk_notification_events_notification FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE,
+
+    INDEX idx_notification_events_notification (notification_id, id)
+);
+
+-- The actor is 'tenant' when the gateway set @nimbus_tenant_id on the
+-- session for an authenticated tenant request, 'system' otherwise.
+-- SYSDATE(6) rather than NOW(6): like clock_timestamp(), it's the time of
+-- the change, not of the start of the statement.
+CREATE TRIGGER record_notification_created
+AFTER INSERT ON notifications
+FOR EACH ROW
+INSERT INTO notification_events (
+    notification_id, tenant_id, from_status, to_status, attempt, error_message, actor, occurred_at
+) VALUES (
+    NEW.id, NEW.tenant_id, NULL, NEW.status, NEW.attempt, NEW.error_message,
+    IF(COALESCE(@nimbus_tenant_id, '') = '', 'system', 'tenant'), SYSDATE(6)
+);
+
+CREATE TRIGGER record_notification_transition
+AFTER UPDATE ON notifications
+FOR EACH ROW
+INSERT INTO notification_events (
+    notification_id, tenant_id, from_status, to_status, attempt, error_message, actor, occurred_at
+)
+SELECT
+    NEW.id, NEW.tenant_id, OLD.status, NEW.status, NEW.attempt, NEW.error_message,
+    IF(COALESCE(@nimbus_tenant_id, '') = '', 'system', 'tenant'), SYSDATE(6)
+FROM DUAL
+WHERE NOT (OLD.status <=> NEW.status) OR NOT (OLD.attempt <=> NEW.attempt);
+
+CREATE TABLE IF NOT EXISTS tenant_rate_limits (
+    tenant_id CHAR(36) NOT NULL PRIMARY KEY,
+    burst INT NOT NULL DEFAULT 0,
+    retry_after_seconds INT,
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT chk_rate_limit_burst CHECK (burst >= 0),
+    CONSTRAINT chk_rate_limit_retry_after CHECK (retry_after_seconds IS NULL OR retry_after_seconds > 0)
+);
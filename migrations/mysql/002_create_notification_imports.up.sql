@@ -0,0 +1,22 @@
+-- Bulk import jobs created by POST /v1/imports (Postgres 027).
+CREATE TABLE IF NOT EXISTS notification_imports (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    source TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    row_errors JSON NOT NULL,
+    error TEXT,
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+    finished_at DATETIME(6),
+
+    INDEX idx_notification_imports_tenant (tenant_id, created_at),
+    CONSTRAINT chk_import_channel CHECK (channel IN ('email', 'sms', 'webhook')),
+    CONSTRAINT chk_import_format CHECK (format IN ('csv', 'jsonl')),
+    CONSTRAINT chk_import_status CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
@@ -0,0 +1,7 @@
+-- Tenant-wide API settings (Postgres 028).
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    tenant_id CHAR(36) NOT NULL PRIMARY KEY,
+    require_idempotency_key BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
@@ -0,0 +1 @@
+DROP INDEX idx_notifications_user_global ON notifications;
@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    DROP COLUMN sla_breached,
+    DROP COLUMN sla_seconds;
@@ -0,0 +1,4 @@
+-- Delivery SLA tracking (Postgres 030).
+ALTER TABLE notifications
+    ADD COLUMN sla_seconds INT,
+    ADD COLUMN sla_breached BOOLEAN;
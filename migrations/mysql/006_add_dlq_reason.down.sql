@@ -0,0 +1,4 @@
+DROP INDEX idx_dlq_tenant_reason ON dead_letter_notifications;
+ALTER TABLE dead_letter_notifications
+    DROP CHECK chk_dlq_reason,
+    DROP COLUMN reason;
@@ -0,0 +1,17 @@
+-- Automatic DLQ retries (Postgres 032).
+CREATE TABLE IF NOT EXISTS dlq_retry_policies (
+    tenant_id CHAR(36) NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    interval_seconds INT NOT NULL,
+    max_retries INT NOT NULL,
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, reason),
+    CONSTRAINT chk_dlq_retry_reason CHECK (reason IN ('invalid_recipient', 'provider_outage', 'timeout', 'payload_error', 'unknown')),
+    CONSTRAINT chk_dlq_retry_interval CHECK (interval_seconds > 0),
+    CONSTRAINT chk_dlq_retry_max CHECK (max_retries > 0)
+);
+
+ALTER TABLE dead_letter_notifications ADD COLUMN retry_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Recipient hygiene (Postgres 033).
+CREATE TABLE IF NOT EXISTS invalid_recipients (
+    tenant_id CHAR(36) NOT NULL,
+    channel VARCHAR(32) NOT NULL,
+    recipient VARCHAR(512) NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    detail TEXT NOT NULL,
+    notification_id CHAR(36) NULL,
+
+    invalidated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, channel, recipient),
+    INDEX idx_invalid_recipients_tenant (tenant_id, invalidated_at),
+    CONSTRAINT chk_invalid_recipient_reason CHECK (reason IN ('hard_bounce', 'provider_rejected'))
+);
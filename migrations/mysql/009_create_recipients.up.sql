@@ -0,0 +1,15 @@
+-- Reusable recipients (Postgres 034).
+CREATE TABLE IF NOT EXISTS recipients (
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    email VARCHAR(320) NOT NULL DEFAULT '',
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    device_tokens JSON NOT NULL DEFAULT ('[]'),
+    locale VARCHAR(35) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, user_id)
+);
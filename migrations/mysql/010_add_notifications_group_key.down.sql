@@ -0,0 +1,4 @@
+DROP INDEX idx_notifications_group ON notifications;
+ALTER TABLE notifications
+    DROP COLUMN collapsed_into,
+    DROP COLUMN group_key;
@@ -0,0 +1,6 @@
+-- Notification groups (Postgres 035).
+ALTER TABLE notifications
+    ADD COLUMN group_key VARCHAR(128),
+    ADD COLUMN collapsed_into CHAR(36);
+
+CREATE INDEX idx_notifications_group ON notifications (tenant_id, user_id, channel, group_key, created_at);
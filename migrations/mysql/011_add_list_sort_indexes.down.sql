@@ -0,0 +1,4 @@
+DROP INDEX idx_dlq_tenant_status ON dead_letter_notifications;
+DROP INDEX idx_dlq_tenant_updated ON dead_letter_notifications;
+DROP INDEX idx_notifications_tenant_status ON notifications;
+DROP INDEX idx_notifications_tenant_updated ON notifications;
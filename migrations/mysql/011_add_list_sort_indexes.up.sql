@@ -0,0 +1,5 @@
+-- List sort indexes (Postgres 036).
+CREATE INDEX idx_notifications_tenant_updated ON notifications (tenant_id, updated_at);
+CREATE INDEX idx_notifications_tenant_status ON notifications (tenant_id, status, created_at);
+CREATE INDEX idx_dlq_tenant_updated ON dead_letter_notifications (tenant_id, updated_at);
+CREATE INDEX idx_dlq_tenant_status ON dead_letter_notifications (tenant_id, status, created_at);
@@ -0,0 +1,17 @@
+-- Provider annotations (Postgres 037).
+CREATE TABLE IF NOT EXISTS notification_annotations (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    notification_id CHAR(36) NOT NULL,
+    tenant_id CHAR(36) NOT NULL,
+
+    provider VARCHAR(50) NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    attempt INT,
+    data JSON NOT NULL DEFAULT ('{}'),
+
+    recorded_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    CONSTRAINT fk_notification_annotations_notification FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE,
+
+    INDEX idx_notification_annotations_notification (notification_id, id)
+);
@@ -0,0 +1,4 @@
+DELETE FROM delivery_events WHERE event_type IN ('open', 'click', 'rendering_failure');
+ALTER TABLE delivery_events
+    DROP CHECK chk_delivery_event_type,
+    ADD CONSTRAINT chk_delivery_event_type CHECK (event_type IN ('delivery', 'bounce', 'complaint'));
@@ -0,0 +1,4 @@
+-- Engagement delivery events (Postgres 038).
+ALTER TABLE delivery_events
+    DROP CHECK chk_delivery_event_type,
+    ADD CONSTRAINT chk_delivery_event_type CHECK (event_type IN ('delivery', 'bounce', 'complaint', 'open', 'click', 'rendering_failure'));
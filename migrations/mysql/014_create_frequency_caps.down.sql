@@ -0,0 +1,6 @@
+DROP INDEX idx_notifications_user_sent ON notifications;
+UPDATE notifications SET status = 'failed' WHERE status = 'frequency_capped';
+ALTER TABLE notifications
+    DROP CHECK chk_status,
+    ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held'));
+DROP TABLE IF EXISTS frequency_caps;
@@ -0,0 +1,22 @@
+-- Frequency caps (Postgres 039).
+CREATE TABLE IF NOT EXISTS frequency_caps (
+    tenant_id CHAR(36) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    max_count INT NOT NULL,
+    window_seconds INT NOT NULL,
+    action VARCHAR(10) NOT NULL DEFAULT 'defer',
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, channel),
+    CONSTRAINT chk_frequency_cap_count CHECK (max_count > 0),
+    CONSTRAINT chk_frequency_cap_window CHECK (window_seconds > 0),
+    CONSTRAINT chk_frequency_cap_action CHECK (action IN ('defer', 'drop'))
+);
+
+ALTER TABLE notifications
+    DROP CHECK chk_status,
+    ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_lettered', 'held', 'frequency_capped'));
+
+CREATE INDEX idx_notifications_user_sent ON notifications (tenant_id, user_id, channel, status, updated_at);
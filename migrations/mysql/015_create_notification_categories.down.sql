@@ -0,0 +1,10 @@
+DELETE FROM frequency_caps WHERE category <> '';
+ALTER TABLE frequency_caps
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (tenant_id, channel),
+    DROP COLUMN category;
+DROP INDEX idx_notifications_category ON notifications;
+ALTER TABLE notifications
+    DROP COLUMN category;
+DROP TABLE IF EXISTS category_opt_outs;
+DROP TABLE IF EXISTS notification_categories;
@@ -0,0 +1,37 @@
+-- Notification categories (Postgres 040).
+CREATE TABLE IF NOT EXISTS notification_categories (
+    tenant_id CHAR(36) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, name),
+    CONSTRAINT chk_category_quiet_hours CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS category_opt_outs (
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    category VARCHAR(64) NOT NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT '',
+
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    PRIMARY KEY (tenant_id, user_id, category, channel),
+    CONSTRAINT fk_category_opt_outs_category FOREIGN KEY (tenant_id, category)
+        REFERENCES notification_categories (tenant_id, name) ON DELETE CASCADE
+);
+
+ALTER TABLE notifications
+    ADD COLUMN category VARCHAR(64);
+
+CREATE INDEX idx_notifications_category ON notifications (tenant_id, category, created_at);
+
+ALTER TABLE frequency_caps
+    ADD COLUMN category VARCHAR(64) NOT NULL DEFAULT '',
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (tenant_id, channel, category);
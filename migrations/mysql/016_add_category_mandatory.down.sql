@@ -0,0 +1,2 @@
+ALTER TABLE notification_categories
+    DROP COLUMN mandatory;
@@ -0,0 +1,3 @@
+-- Mandatory notification categories (Postgres 041).
+ALTER TABLE notification_categories
+    ADD COLUMN mandatory BOOLEAN NOT NULL DEFAULT FALSE;
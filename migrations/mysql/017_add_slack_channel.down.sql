@@ -0,0 +1,29 @@
+DELETE FROM notification_imports WHERE channel = 'slack';
+ALTER TABLE notification_imports
+    DROP CHECK chk_import_channel,
+    ADD CONSTRAINT chk_import_channel CHECK (channel IN ('email', 'sms', 'webhook'));
+
+DELETE FROM channel_kill_switches WHERE channel = 'slack';
+ALTER TABLE channel_kill_switches
+    DROP CHECK chk_kill_switch_channel,
+    ADD CONSTRAINT chk_kill_switch_channel CHECK (channel IN ('email', 'sms', 'webhook'));
+
+DELETE FROM tenant_channel_settings WHERE channel = 'slack';
+ALTER TABLE tenant_channel_settings
+    DROP CHECK chk_settings_channel,
+    ADD CONSTRAINT chk_settings_channel CHECK (channel IN ('email', 'sms', 'webhook'));
+
+DELETE FROM captured_deliveries WHERE channel = 'slack';
+ALTER TABLE captured_deliveries
+    DROP CHECK chk_captured_channel,
+    ADD CONSTRAINT chk_captured_channel CHECK (channel IN ('email', 'sms', 'webhook'));
+
+DELETE FROM dead_letter_notifications WHERE channel = 'slack';
+ALTER TABLE dead_letter_notifications
+    DROP CHECK chk_dlq_channel,
+    ADD CONSTRAINT chk_dlq_channel CHECK (channel IN ('email', 'sms', 'webhook'));
+
+DELETE FROM notifications WHERE channel = 'slack';
+ALTER TABLE notifications
+    DROP CHECK chk_channel,
+    ADD CONSTRAINT chk_channel CHECK (channel IN ('email', 'sms', 'webhook'));
@@ -0,0 +1,24 @@
+-- Slack channel (Postgres 042).
+ALTER TABLE notifications
+    DROP CHECK chk_channel,
+    ADD CONSTRAINT chk_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE dead_letter_notifications
+    DROP CHECK chk_dlq_channel,
+    ADD CONSTRAINT chk_dlq_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE captured_deliveries
+    DROP CHECK chk_captured_channel,
+    ADD CONSTRAINT chk_captured_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE tenant_channel_settings
+    DROP CHECK chk_settings_channel,
+    ADD CONSTRAINT chk_settings_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE channel_kill_switches
+    DROP CHECK chk_kill_switch_channel,
+    ADD CONSTRAINT chk_kill_switch_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
+
+ALTER TABLE notification_imports
+    DROP CHECK chk_import_channel,
+    ADD CONSTRAINT chk_import_channel CHECK (channel IN ('email', 'sms', 'webhook', 'slack'));
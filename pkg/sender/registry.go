@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Factory builds a plugin's Sender from the settings configured for it in
+// SENDER_PLUGINS (JSON null when none were given).
+type Factory func(settings json.RawMessage, logger *zap.Logger) (Sender, error)
+
+// Plugin is an out-of-tree sender. Channels are the channels it delivers;
+// the API accepts notifications on them once the plugin is enabled. A
+// plugin that claims a built-in channel replaces the built-in sender.
+type Plugin struct {
+	Name     string
+	Channels []string
+	New      Factory
+}
+
+var (
+	mu      sync.RWMutex
+	plugins = map[string]Plugin{}
+)
+
+// Register makes a plugin available by name. Like database/sql drivers,
+// plugins call it from init; it panics on a duplicate name or a plugin
+// missing its name, channels or factory.
+func Register(p Plugin) {
+	if p.Name == "" || len(p.Channels) == 0 || p.New == nil {
+		panic(fmt.Sprintf("sender: plugin %q needs a name, channels and a factory", p.Name))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := plugins[p.Name]; dup {
+		panic(fmt.Sprintf("sender: plugin %q registered twice", p.Name))
+	}
+	plugins[p.Name] = p
+}
+
+// Lookup returns the plugin registered under name.
+func Lookup(name string) (Plugin, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := plugins[name]
+	return p, ok
+}
+
+// Registered lists the registered plugin names, sorted.
+func Registered() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Open builds the named plugin's sender and checks that it supports every
+// channel the plugin declared.
+func Open(name string, settings json.RawMessage, logger *zap.Logger) (Plugin, Sender, error) {
+	p, ok := Lookup(name)
+	if !ok {
+		return Plugin{}, nil, fmt.Errorf("sender plugin %q is not registered (have %v)", name, Registered())
+	}
+	s, err := p.New(settings, logger.With(zap.String("plugin", name)))
+	if err != nil {
+		return Plugin{}, nil, fmt.Errorf("sender plugin %q: %w", name, err)
+	}
+	for _, channel := range p.Channels {
+		if !s.SupportsChannel(channel) {
+			return Plugin{}, nil, fmt.Errorf("sender plugin %q does not support its channel %q", name, channel)
+		}
+	}
+	return p, s, nil
+}
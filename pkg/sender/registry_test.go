@@ -0,0 +1,54 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type channelSender string
+
+func (s channelSender) Send(ctx context.Context, notif *Notification) error { return nil }
+func (s channelSender) SupportsChannel(channel string) bool                 { return channel == string(s) }
+
+func TestRegistry(t *testing.T) {
+	Register(Plugin{
+		Name:     "test-chat",
+		Channels: []string{"chat"},
+		New: func(settings json.RawMessage, logger *zap.Logger) (Sender, error) {
+			var cfg struct{ Channel string }
+			if err := json.Unmarshal(settings, &cfg); err != nil {
+				return nil, err
+			}
+			if cfg.Channel == "" {
+				return nil, errors.New("channel is required")
+			}
+			return channelSender(cfg.Channel), nil
+		},
+	})
+
+	p, s, err := Open("test-chat", json.RawMessage(`{"channel":"chat"}`), zap.NewNop())
+	if err != nil || p.Name != "test-chat" || !s.SupportsChannel("chat") {
+		t.Fatalf("unexpected plugin %+v, %v, %v", p, s, err)
+	}
+	if _, _, err := Open("test-chat", json.RawMessage(`{}`), zap.NewNop()); err == nil {
+		t.Error("expected the factory's error")
+	}
+	if _, _, err := Open("test-chat", json.RawMessage(`{"channel":"other"}`), zap.NewNop()); err == nil {
+		t.Error("expected an error for a sender missing its declared channel")
+	}
+	if _, _, err := Open("missing", nil, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "test-chat") {
+		t.Errorf("expected an unknown plugin error listing test-chat, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a duplicate registration to panic")
+		}
+	}()
+	Register(Plugin{Name: "test-chat", Channels: []string{"chat"}, New: p.New})
+}
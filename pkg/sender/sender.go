@@ -0,0 +1,36 @@
+// Package sender is the public contract between the worker and a delivery
+// channel. The built-in providers (SES, SNS, webhooks) implement it, and so
+// can plugins built outside this repository: a plugin registers itself with
+// Register, usually from an init function, and is enabled by name in
+// SENDER_PLUGINS. Package sendertest checks that an implementation keeps
+// the contract.
+package sender
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lalithlochan/nimbus/internal/db"
+)
+
+// Notification is the notification being delivered. Payload holds the
+// channel-specific JSON the client sent.
+type Notification = db.Notification
+
+// Sender delivers notifications on one or more channels.
+//
+// Send must be safe for concurrent use and must return promptly once ctx
+// is done. A nil error means the provider accepted the message; the worker
+// retries any other error with backoff, except one wrapping ErrPermanent.
+// Send may set notif.Provider, notif.ProviderMessageID and
+// notif.Annotations (provider detail the worker adds to the notification's
+// timeline, even when the send fails); it must not change anything else.
+type Sender interface {
+	Send(ctx context.Context, notif *Notification) error
+	SupportsChannel(channel string) bool
+}
+
+// ErrPermanent marks a failure that retrying can't fix, such as a payload
+// the provider will never accept. The worker dead-letters the notification
+// at once.
+var ErrPermanent = errors.New("permanent send failure")
@@ -0,0 +1,185 @@
+// Package sendertest is a conformance suite for sender.Sender
+// implementations. Run it from a plugin's tests, with -race, to check the
+// plugin keeps the contract the worker relies on:
+//
+//	func TestConformance(t *testing.T) {
+//		sendertest.Run(t, sendertest.Suite{
+//			New:     func(t *testing.T) sender.Sender { return newTestSender(t) },
+//			Channel: "slack",
+//			Valid:   func(t *testing.T) *sender.Notification { return validNotification() },
+//		})
+//	}
+package sendertest
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lalithlochan/nimbus/pkg/sender"
+)
+
+// unknownChannel is a channel no sender should claim.
+const unknownChannel = "sendertest-unknown-channel"
+
+// Suite describes the sender under test.
+type Suite struct {
+	// New returns a fresh sender for each check. Point it at a fake
+	// provider; the suite sends real requests through it.
+	New func(t *testing.T) sender.Sender
+	// Channel is the channel under test.
+	Channel string
+	// Valid returns a notification on Channel the sender should deliver.
+	Valid func(t *testing.T) *sender.Notification
+	// Invalid, if set, returns a notification on Channel the sender must
+	// refuse with an error.
+	Invalid func(t *testing.T) *sender.Notification
+	// Timeout bounds how long Send may take to return once its context is
+	// done. Default 5s.
+	Timeout time.Duration
+	// Concurrency is how many sends the concurrency check runs at once.
+	// Default 16.
+	Concurrency int
+}
+
+// Run checks the sender described by s.
+func Run(t *testing.T, s Suite) {
+	t.Helper()
+	if s.New == nil || s.Channel == "" || s.Valid == nil {
+		t.Fatal("sendertest: Suite needs New, Channel and Valid")
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = 5 * time.Second
+	}
+	if s.Concurrency <= 0 {
+		s.Concurrency = 16
+	}
+
+	t.Run("SupportsChannel", s.testSupportsChannel)
+	t.Run("Delivers", s.testDelivers)
+	if s.Invalid != nil {
+		t.Run("RefusesInvalid", s.testRefusesInvalid)
+	}
+	t.Run("EmptyPayload", s.testEmptyPayload)
+	t.Run("CancelledContext", s.testCancelledContext)
+	t.Run("Concurrent", s.testConcurrent)
+}
+
+func (s Suite) testSupportsChannel(t *testing.T) {
+	snd := s.New(t)
+	if !snd.SupportsChannel(s.Channel) {
+		t.Errorf("SupportsChannel(%q) = false, want true", s.Channel)
+	}
+	if snd.SupportsChannel(unknownChannel) {
+		t.Errorf("SupportsChannel(%q) = true, want false", unknownChannel)
+	}
+	if snd.SupportsChannel("") {
+		t.Error("SupportsChannel(\"\") = true, want false")
+	}
+}
+
+// testDelivers sends a valid notification and checks that only the
+// provider fields and annotations changed.
+func (s Suite) testDelivers(t *testing.T) {
+	notif := s.notification(t, s.Valid)
+	want := *notif
+
+	if err := s.New(t).Send(context.Background(), notif); err != nil {
+		t.Fatalf("Send() = %v, want nil", err)
+	}
+	got := *notif
+	got.Provider, got.ProviderMessageID, got.Annotations = want.Provider, want.ProviderMessageID, want.Annotations
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Send() changed the notification beyond Provider, ProviderMessageID and Annotations:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func (s Suite) testRefusesInvalid(t *testing.T) {
+	if err := s.New(t).Send(context.Background(), s.notification(t, s.Invalid)); err == nil {
+		t.Error("Send() of an invalid notification = nil, want an error")
+	}
+}
+
+// testEmptyPayload checks a notification without a payload is handled,
+// accepted or refused, without a panic.
+func (s Suite) testEmptyPayload(t *testing.T) {
+	notif := s.notification(t, s.Valid)
+	notif.Payload = nil
+	_ = s.New(t).Send(context.Background(), notif)
+
+	notif.Payload = json.RawMessage(`{}`)
+	_ = s.New(t).Send(context.Background(), notif)
+}
+
+func (s Suite) testCancelledContext(t *testing.T) {
+	snd := s.New(t)
+	notif := s.notification(t, s.Valid)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = snd.Send(ctx, notif)
+	}()
+	select {
+	case <-done:
+	case <-time.After(s.Timeout):
+		t.Errorf("Send() with a cancelled context still running after %s", s.Timeout)
+	}
+}
+
+// testConcurrent sends from several goroutines on one sender; run with
+// -race to catch unsynchronized state.
+func (s Suite) testConcurrent(t *testing.T) {
+	snd := s.New(t)
+	notifs := make([]*sender.Notification, s.Concurrency)
+	for i := range notifs {
+		notifs[i] = s.notification(t, s.Valid)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, s.Concurrency)
+	for _, notif := range notifs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- snd.Send(context.Background(), notif)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent Send() = %v, want nil", err)
+		}
+	}
+}
+
+// notification calls build and fills in the identifiers the worker always
+// sets, so fixtures only need the channel and payload.
+func (s Suite) notification(t *testing.T, build func(t *testing.T) *sender.Notification) *sender.Notification {
+	t.Helper()
+	notif := build(t)
+	if notif.Channel != s.Channel {
+		t.Fatalf("sendertest: fixture channel %q, want %q", notif.Channel, s.Channel)
+	}
+	if notif.ID == uuid.Nil {
+		notif.ID = uuid.New()
+	}
+	if notif.TenantID == uuid.Nil {
+		notif.TenantID = uuid.New()
+	}
+	if notif.UserID == uuid.Nil {
+		notif.UserID = uuid.New()
+	}
+	if notif.CreatedAt.IsZero() {
+		notif.CreatedAt = time.Now()
+	}
+	return notif
+}
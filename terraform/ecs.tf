@@ -181,6 +181,17 @@ resource "aws_iam_role_policy" "ecs_task" {
           "ses:SendRawEmail"
         ]
         Resource = ["*"]
+      },
+      {
+        Effect   = "Allow"
+        Action   = ["s3:PutObject"]
+        Resource = ["${aws_s3_bucket.archive.arn}/deliveries/*"]
+      },
+      {
+        # GetObject is what the presigned download URLs are signed for.
+        Effect   = "Allow"
+        Action   = ["s3:PutObject", "s3:GetObject"]
+        Resource = ["${aws_s3_bucket.dlq_exports.arn}/dlq-exports/*"]
       }
     ]
   })
@@ -238,6 +249,9 @@ resource "aws_ecs_task_definition" "main" {
         { name = "SQS_DLQ_URL", value = aws_sqs_queue.dlq.url },
         { name = "SNS_TOPIC_ARN", value = aws_sns_topic.notifications.arn },
         { name = "SES_FROM_EMAIL", value = var.ses_from_email },
+        { name = "SES_CONFIGURATION_SET", value = aws_ses_configuration_set.main.name },
+        { name = "ARCHIVE_S3_BUCKET", value = aws_s3_bucket.archive.id },
+        { name = "DLQ_EXPORT_S3_BUCKET", value = aws_s3_bucket.dlq_exports.id },
         { name = "MIGRATIONS_DIR", value = "/app/migrations" },
       ]
 
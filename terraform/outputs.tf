@@ -38,6 +38,11 @@ output "sns_topic_arn" {
   value       = aws_sns_topic.notifications.arn
 }
 
+output "ses_events_topic_arn" {
+  description = "SNS topic the SES configuration set publishes events to"
+  value       = aws_sns_topic.ses_events.arn
+}
+
 output "ecs_cluster_name" {
   description = "ECS cluster name"
   value       = aws_ecs_cluster.main.name
@@ -57,3 +62,13 @@ output "ecs_security_group_id" {
   description = "ECS security group ID"
   value       = aws_security_group.ecs.id
 }
+
+output "archive_bucket" {
+  description = "Delivery archive S3 bucket"
+  value       = aws_s3_bucket.archive.id
+}
+
+output "dlq_export_bucket" {
+  description = "DLQ export S3 bucket"
+  value       = aws_s3_bucket.dlq_exports.id
+}
@@ -0,0 +1,105 @@
+# Delivery archive: the rendered message of every sent notification, for
+# compliance audits. Objects are keyed deliveries/<tenant_id>/<yyyy>/<mm>/<dd>/.
+resource "aws_s3_bucket" "archive" {
+  bucket = "${local.name}-delivery-archive"
+
+  tags = {
+    Name = "${local.name}-delivery-archive"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "archive" {
+  bucket = aws_s3_bucket.archive.id
+
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "archive" {
+  bucket = aws_s3_bucket.archive.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+
+# Archives are written once and read rarely: move them to cheaper storage
+# as they age, and delete them when the retention period ends.
+resource "aws_s3_bucket_lifecycle_configuration" "archive" {
+  bucket = aws_s3_bucket.archive.id
+
+  rule {
+    id     = "delivery-retention"
+    status = "Enabled"
+
+    filter {
+      prefix = "deliveries/"
+    }
+
+    transition {
+      days          = 30
+      storage_class = "STANDARD_IA"
+    }
+
+    transition {
+      days          = var.archive_glacier_after_days
+      storage_class = "GLACIER"
+    }
+
+    expiration {
+      days = var.archive_retention_days
+    }
+  }
+}
+
+# DLQ exports: JSON Lines files written by POST /v1/dlq/export and
+# downloaded through presigned URLs. Keyed dlq-exports/<tenant_id>/.
+resource "aws_s3_bucket" "dlq_exports" {
+  bucket = "${local.name}-dlq-exports"
+
+  tags = {
+    Name = "${local.name}-dlq-exports"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "dlq_exports" {
+  bucket = aws_s3_bucket.dlq_exports.id
+
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "dlq_exports" {
+  bucket = aws_s3_bucket.dlq_exports.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+
+# Exports hold payloads and are only meant for a download, so they don't
+# outlive their purpose.
+resource "aws_s3_bucket_lifecycle_configuration" "dlq_exports" {
+  bucket = aws_s3_bucket.dlq_exports.id
+
+  rule {
+    id     = "dlq-export-expiry"
+    status = "Enabled"
+
+    filter {
+      prefix = "dlq-exports/"
+    }
+
+    expiration {
+      days = var.dlq_export_retention_days
+    }
+  }
+}
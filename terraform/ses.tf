@@ -0,0 +1,25 @@
+# SES configuration set attached to every send (SES_CONFIGURATION_SET). Its
+# event destination publishes delivery and engagement events to SNS;
+# subscribe /v1/providers/ses/events?token=... to the topic to store them.
+resource "aws_ses_configuration_set" "main" {
+  name = "${local.name}-events"
+}
+
+resource "aws_sns_topic" "ses_events" {
+  name = "${local.name}-ses-events"
+
+  tags = {
+    Name = "${local.name}-ses-events"
+  }
+}
+
+resource "aws_ses_event_destination" "sns" {
+  name                   = "${local.name}-sns"
+  configuration_set_name = aws_ses_configuration_set.main.name
+  enabled                = true
+  matching_types         = ["delivery", "bounce", "complaint", "open", "click", "renderingFailure"]
+
+  sns_destination {
+    topic_arn = aws_sns_topic.ses_events.arn
+  }
+}
@@ -69,3 +69,21 @@ variable "ses_from_email" {
   description = "SES verified sender email"
   type        = string
 }
+
+variable "archive_glacier_after_days" {
+  description = "Days before archived deliveries move to Glacier"
+  type        = number
+  default     = 90
+}
+
+variable "archive_retention_days" {
+  description = "Days archived deliveries are kept before deletion"
+  type        = number
+  default     = 2555 # 7 years
+}
+
+variable "dlq_export_retention_days" {
+  description = "Days DLQ exports are kept before deletion"
+  type        = number
+  default     = 7
+}